@@ -1,27 +1,338 @@
 // Package cmd implements the command-line interface for smpc.
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Norgate-AV/smpc/internal/artifacts"
+	"github.com/Norgate-AV/smpc/internal/autorespond"
+	"github.com/Norgate-AV/smpc/internal/hints"
+	"github.com/Norgate-AV/smpc/internal/keychord"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/notify"
+	"github.com/Norgate-AV/smpc/internal/poll"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+	"github.com/Norgate-AV/smpc/internal/windowfilter"
+)
 
 // Config holds all application configuration
 type Config struct {
-	Verbose      bool
-	RecompileAll bool
-	ShowLogs     bool
+	Verbose          bool
+	WarningsAsErrors bool
+	Verbosity        int // Console verbosity from -v/-vv/-vvv (--verbose counts as -v)
+	RecompileAll     bool
+	AutoRecompileAll bool   // Retry with Recompile All when compile errors point to a database change
+	SaveFirst        bool   // Save the program in SIMPL Windows before triggering the compile
+	PreferNative     bool   // Compile via smpwin.exe command-line switches when supported
+	Backend          string // Compile trigger backend ("gui" or "dde")
+	StageLocal       bool   // Compile a copy in a local workspace and copy artifacts back
+	Sandbox          bool   // Compile a copy in a scratch directory and leave the original untouched
+	IfRunning        string // Policy for SIMPL Windows instances already running ("ignore", "kill", "attach", "abort")
+	IfInterfering    string // Policy for other Crestron tools running at startup ("ignore", "warn", "wait")
+	RunAs            string // Account to launch SIMPL Windows under ("" = current user)
+	ShowLogs         bool
+	Events           string        // Live event stream format ("" = disabled, "ndjson")
+	Redact           string        // Path/user name redaction mode ("" = disabled, "basename", "hash")
+	PprofAddr        string        // Address for the net/http/pprof endpoints ("" = disabled)
+	TraceFile        string        // Path to write a runtime execution trace ("" = disabled)
+	Reports          []string      // Report outputs as format=path (e.g. csv=results.csv)
+	JSON             bool          // Write the JSON report to stdout (--report json=-)
+	CompileKey       string        // Compile key chord override ("" = F12)
+	RecompileKey     string        // Recompile All key chord override ("" = Alt+F12)
+	AbortKey         string        // Global hotkey that aborts the run ("" = disabled)
+	Handoff          string        // Directory to send console output to when relaunched elevated
+	OutputDir        string        // Directory to copy compile outputs into ("" = disabled)
+	OutputName       string        // Template naming the subdirectory of OutputDir for this run
+	OutputVersion    string        // Value of {version} in OutputName
+	Reproducible     bool          // --verify-reproducible: compile twice in sandboxes and compare the outputs
+	NoHistory        bool          // Do not record this compile in the history file
+	Transcripts      bool          // Record the text of every dialog seen in the result
+	DialogUpdates    bool          // Rescan open dialogs and report changes to their text
+	AutoRespond      string        // Policy file answering dialogs smpc does not otherwise handle ("" = disabled)
+	Graph            bool          // Print the build order of a project directory instead of compiling it
+	NotifyEmail      string        // SMTP settings file for emailing the results ("" = disabled)
+	Hints            string        // Knowledge base extending the built-in message hints ("" = built-in only)
+	Lang             string        // Console language ("" = from the environment)
+	Timestamps       string        // Console line timestamps ("" = none, "elapsed", "abs")
+	Notify           bool          // Show a toast notification when the run finishes
+	KeepTemp         bool          // Leave the scratch files SIMPL Windows creates next to the program
+	TempPatterns     []string      // File name patterns of scratch files to remove (nil = artifacts.DefaultTempPatterns)
+	CrashRetries     int           // Times to compile again in a new instance after SIMPL Windows crashes
+	UpdatePrompts    string        // Policy for update and download prompts shown at startup ("dismiss", "ignore")
+	ShowMode         string        // How SIMPL Windows is shown ("normal", "minimized", "hidden", "offscreen")
+	MaxRuntime       time.Duration // Limit on the whole run, after which everything is force-closed (0 = none)
+	WaitForLock      time.Duration // How long to wait for another instance to finish with SIMPL Windows (0 = not at all)
+	TimeoutCurve     string        // Compile timeout by program size ("" = timeouts.DefaultSizeCurve)
+	SimplPath        string        // SIMPL Windows executable ("" = SIMPL_WINDOWS_PATH or the default install)
+	SimplWorkDir     string        // Working directory SIMPL Windows is started in ("" = smpc's)
+	SimplEnv         []string      // KEY=VALUE variables added to SIMPL Windows' environment
+
+	ParallelSimplPaths []string // Further SIMPL Windows executables compiling a project's programs concurrently (experimental)
+
+	// Log rotation settings passed to the file logger
+	LogMaxSize    int  // Megabytes before rotation
+	LogMaxBackups int  // Rotated files to retain
+	LogMaxAge     int  // Days to retain rotated files
+	LogCompress   bool // Gzip rotated files
+
+	// Answers to the prompts SIMPL Windows shows while compiling and closing
+	SavePrompt        string // "Convert/Compile" save prompt: yes, no or abort
+	CloseConfirmation string // "Confirmation" when closing: yes or no
+
+	// Adaptive polling of the window waits and the window monitor
+	PollMin time.Duration // Interval right after a change or compile trigger
+	PollMax time.Duration // Interval backed off to while nothing changes
+
+	// Windows the window monitor reports, as class=, title= or pid= rules
+	MonitorInclude []string // Report only windows matching one of these (nil = all)
+	MonitorExclude []string // Never report windows matching one of these
 }
 
 // NewConfigFromFlags creates a Config from parsed command flags
 func NewConfigFromFlags(cmd *cobra.Command) *Config {
 	// Try to get from local flags first, fall back to persistent flags
 	verbose := getBoolFlag(cmd, "verbose")
+	verbosity := getCountFlag(cmd, "verbosity")
 	recompileAll := getBoolFlag(cmd, "recompile-all")
+	saveFirst := getBoolFlag(cmd, "save-first")
+	autoRecompileAll := getBoolFlag(cmd, "auto-recompile-all")
+	warningsAsErrors := getBoolFlag(cmd, "warnings-as-errors")
+	preferNative := getBoolFlag(cmd, "prefer-native")
+	backend := getStringFlag(cmd, "backend")
+	stageLocal := getBoolFlag(cmd, "stage-local")
+	sandbox := getBoolFlag(cmd, "sandbox")
+	ifRunning := getStringFlag(cmd, "if-running")
+	ifInterfering := getStringFlag(cmd, "if-interfering")
+	runAs := getStringFlag(cmd, "runas")
 	showLogs := getBoolFlag(cmd, "logs")
+	events := getStringFlag(cmd, "events")
+	redactMode := getStringFlag(cmd, "redact")
+	pprofAddr := getStringFlag(cmd, "pprof")
+	traceFile := getStringFlag(cmd, "trace")
+	reports := getStringArrayFlag(cmd, "report")
+	jsonReport := getBoolFlag(cmd, "json")
+	compileKey := getStringFlag(cmd, "compile-key")
+	recompileKey := getStringFlag(cmd, "recompile-key")
+	abortKey := getStringFlag(cmd, "abort-key")
+	handoff := getStringFlag(cmd, "handoff")
+	outputDir := getStringFlag(cmd, "output-dir")
+	outputName := getStringFlag(cmd, "output-name")
+	outputVersion := getStringFlag(cmd, "output-version")
+	reproducible := getBoolFlag(cmd, "verify-reproducible")
+	noHistory := getBoolFlag(cmd, "no-history")
+	transcripts := getBoolFlag(cmd, "dialog-transcripts")
+	dialogUpdates := getBoolFlag(cmd, "dialog-updates")
+	autoRespond := getStringFlag(cmd, "auto-respond")
+	graph := getBoolFlag(cmd, "graph")
+	notifyEmail := getStringFlag(cmd, "notify-email")
+	notifyToast := getBoolFlag(cmd, "notify")
+	keepTemp := getBoolFlag(cmd, "keep-temp")
+	tempPatterns := getStringArrayFlag(cmd, "temp-pattern")
+	crashRetries := getIntFlag(cmd, "crash-retries")
+	updatePrompts := getStringFlag(cmd, "update-prompts")
+	showMode := getStringFlag(cmd, "show-mode")
+	maxRuntime := getDurationFlag(cmd, "max-runtime")
+	waitForLock := getDurationFlag(cmd, "wait-for-lock")
+	timeoutCurve := getStringFlag(cmd, "timeout-curve")
+	simplPath := getStringFlag(cmd, "simpl-path")
+	simplWorkDir := getStringFlag(cmd, "simpl-workdir")
+	simplEnv := getStringArrayFlag(cmd, "simpl-env")
+	parallelSimplPaths := getStringArrayFlag(cmd, "parallel-simpl-path")
+	hintsFile := getStringFlag(cmd, "hints")
+	lang := getStringFlag(cmd, "lang")
+	timestamps := getStringFlag(cmd, "timestamps")
+	logMaxSize := getIntFlag(cmd, "log-max-size")
+	logMaxBackups := getIntFlag(cmd, "log-max-backups")
+	logMaxAge := getIntFlag(cmd, "log-max-age")
+	logCompress := getBoolFlag(cmd, "log-compress")
+	savePrompt := getStringFlag(cmd, "save-prompt")
+	closeConfirmation := getStringFlag(cmd, "close-confirmation")
+	pollMin := getDurationFlag(cmd, "poll-min")
+	pollMax := getDurationFlag(cmd, "poll-max")
+	monitorInclude := getStringArrayFlag(cmd, "monitor-include")
+	monitorExclude := getStringArrayFlag(cmd, "monitor-exclude")
+
+	if verbose && verbosity < logger.VerbosityDebug {
+		verbosity = logger.VerbosityDebug
+	}
 
 	return &Config{
-		Verbose:      verbose,
-		RecompileAll: recompileAll,
-		ShowLogs:     showLogs,
+		Verbose:          verbosity >= logger.VerbosityDebug,
+		Verbosity:        verbosity,
+		RecompileAll:     recompileAll,
+		AutoRecompileAll: autoRecompileAll,
+		SaveFirst:        saveFirst,
+		WarningsAsErrors: warningsAsErrors,
+		PreferNative:     preferNative,
+		Backend:          backend,
+		StageLocal:       stageLocal,
+		Sandbox:          sandbox,
+		IfRunning:        ifRunning,
+		IfInterfering:    ifInterfering,
+		RunAs:            runAs,
+		ShowLogs:         showLogs,
+		Events:           events,
+		Redact:           redactMode,
+		PprofAddr:        pprofAddr,
+		TraceFile:        traceFile,
+		Reports:          reports,
+		JSON:             jsonReport,
+		CompileKey:       compileKey,
+		RecompileKey:     recompileKey,
+		AbortKey:         abortKey,
+		Handoff:          handoff,
+		OutputDir:        outputDir,
+		OutputName:       outputName,
+		OutputVersion:    outputVersion,
+		Reproducible:     reproducible,
+		NoHistory:        noHistory,
+		Transcripts:      transcripts,
+		DialogUpdates:    dialogUpdates,
+		AutoRespond:      autoRespond,
+		Graph:            graph,
+		NotifyEmail:      notifyEmail,
+		Hints:            hintsFile,
+		Lang:             lang,
+		Timestamps:       timestamps,
+		Notify:           notifyToast,
+		KeepTemp:         keepTemp,
+		TempPatterns:     tempPatterns,
+		CrashRetries:     crashRetries,
+		UpdatePrompts:    updatePrompts,
+		ShowMode:         showMode,
+		MaxRuntime:       maxRuntime,
+		WaitForLock:      waitForLock,
+		TimeoutCurve:     timeoutCurve,
+		SimplPath:        simplPath,
+		SimplWorkDir:     simplWorkDir,
+		SimplEnv:         simplEnv,
+
+		ParallelSimplPaths: parallelSimplPaths,
+
+		LogMaxSize:    logMaxSize,
+		LogMaxBackups: logMaxBackups,
+		LogMaxAge:     logMaxAge,
+		LogCompress:   logCompress,
+
+		SavePrompt:        savePrompt,
+		CloseConfirmation: closeConfirmation,
+
+		PollMin: pollMin,
+		PollMax: pollMax,
+
+		MonitorInclude: monitorInclude,
+		MonitorExclude: monitorExclude,
+	}
+}
+
+// Polling returns the adaptive polling settings selected by --poll-min and --poll-max
+func (c *Config) Polling() poll.Settings {
+	return poll.Settings{Min: c.PollMin, Max: c.PollMax, Factor: poll.DefaultFactor}
+}
+
+// MonitorFilter parses --monitor-include and --monitor-exclude
+func (c *Config) MonitorFilter() (*windowfilter.Filter, error) {
+	filter, err := windowfilter.New(c.MonitorInclude, c.MonitorExclude)
+	if err != nil {
+		return nil, fmt.Errorf("--monitor-include/--monitor-exclude: %w", err)
 	}
+
+	return filter, nil
+}
+
+// CompileTimeoutCurve parses --timeout-curve
+func (c *Config) CompileTimeoutCurve() (timeouts.SizeCurve, error) {
+	curve, err := timeouts.ParseSizeCurve(c.TimeoutCurve)
+	if err != nil {
+		return timeouts.SizeCurve{}, fmt.Errorf("--timeout-curve: %w", err)
+	}
+
+	return curve, nil
+}
+
+// TempCleanupPatterns returns the scratch file patterns to remove after compiling,
+// or nil with --keep-temp
+func (c *Config) TempCleanupPatterns() ([]string, error) {
+	if c.KeepTemp {
+		return nil, nil
+	}
+
+	if len(c.TempPatterns) == 0 {
+		return artifacts.DefaultTempPatterns, nil
+	}
+
+	if err := artifacts.ValidatePatterns(c.TempPatterns); err != nil {
+		return nil, fmt.Errorf("--temp-pattern: %w", err)
+	}
+
+	return c.TempPatterns, nil
+}
+
+// KeyChords parses the configured compile and recompile-all chords.
+// Unset chords are returned as zero values, meaning the F12 / Alt+F12 defaults.
+func (c *Config) KeyChords() (compileKey, recompileKey keychord.Chord, err error) {
+	if c.CompileKey != "" {
+		if compileKey, err = keychord.Parse(c.CompileKey); err != nil {
+			return keychord.Chord{}, keychord.Chord{}, fmt.Errorf("--compile-key: %w", err)
+		}
+	}
+
+	if c.RecompileKey != "" {
+		if recompileKey, err = keychord.Parse(c.RecompileKey); err != nil {
+			return keychord.Chord{}, keychord.Chord{}, fmt.Errorf("--recompile-key: %w", err)
+		}
+	}
+
+	return compileKey, recompileKey, nil
+}
+
+// AbortChord parses the --abort-key hotkey; an empty value disables it
+func (c *Config) AbortChord() (keychord.Chord, error) {
+	if c.AbortKey == "" {
+		return keychord.Chord{}, nil
+	}
+
+	chord, err := keychord.Parse(c.AbortKey)
+	if err != nil {
+		return keychord.Chord{}, fmt.Errorf("--abort-key: %w", err)
+	}
+
+	return chord, nil
+}
+
+// AutoRespondPolicy loads the --auto-respond policy file, returning nil when none is set
+func (c *Config) AutoRespondPolicy() (*autorespond.Policy, error) {
+	if c.AutoRespond == "" {
+		return nil, nil
+	}
+
+	return autorespond.Load(c.AutoRespond)
+}
+
+// EmailSettings loads the --notify-email settings file, returning nil when none is set
+func (c *Config) EmailSettings() (*notify.Email, error) {
+	if c.NotifyEmail == "" {
+		return nil, nil
+	}
+
+	return notify.LoadEmail(c.NotifyEmail)
+}
+
+// HintsKB returns the built-in message hints extended by the --hints file, if set
+func (c *Config) HintsKB() (*hints.KB, error) {
+	if c.Hints == "" {
+		return hints.Builtin(), nil
+	}
+
+	extra, err := hints.Load(c.Hints)
+	if err != nil {
+		return nil, err
+	}
+
+	return hints.Builtin().Extend(extra), nil
 }
 
 // getBoolFlag retrieves a boolean flag, checking both local and persistent flags
@@ -34,3 +345,58 @@ func getBoolFlag(cmd *cobra.Command, name string) bool {
 
 	return val
 }
+
+// getStringFlag retrieves a string flag, checking both local and persistent flags
+func getStringFlag(cmd *cobra.Command, name string) string {
+	val, err := cmd.Flags().GetString(name)
+	if err != nil {
+		// Try persistent flags if not found in local flags
+		val, _ = cmd.PersistentFlags().GetString(name)
+	}
+
+	return val
+}
+
+// getCountFlag retrieves a count flag, checking both local and persistent flags
+func getCountFlag(cmd *cobra.Command, name string) int {
+	val, err := cmd.Flags().GetCount(name)
+	if err != nil {
+		// Try persistent flags if not found in local flags
+		val, _ = cmd.PersistentFlags().GetCount(name)
+	}
+
+	return val
+}
+
+// getIntFlag retrieves an int flag, checking both local and persistent flags
+func getIntFlag(cmd *cobra.Command, name string) int {
+	val, err := cmd.Flags().GetInt(name)
+	if err != nil {
+		// Try persistent flags if not found in local flags
+		val, _ = cmd.PersistentFlags().GetInt(name)
+	}
+
+	return val
+}
+
+// getStringArrayFlag retrieves a repeatable string flag, checking both local and persistent flags
+func getStringArrayFlag(cmd *cobra.Command, name string) []string {
+	val, err := cmd.Flags().GetStringArray(name)
+	if err != nil {
+		// Try persistent flags if not found in local flags
+		val, _ = cmd.PersistentFlags().GetStringArray(name)
+	}
+
+	return val
+}
+
+// getDurationFlag gets a duration flag value, checking both local and persistent flags
+func getDurationFlag(cmd *cobra.Command, name string) time.Duration {
+	val, err := cmd.Flags().GetDuration(name)
+	if err != nil {
+		// Try persistent flags if not found in local flags
+		val, _ = cmd.PersistentFlags().GetDuration(name)
+	}
+
+	return val
+}
@@ -0,0 +1,456 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/Norgate-AV/smpc/internal/artifacts"
+	"github.com/Norgate-AV/smpc/internal/compiler"
+	"github.com/Norgate-AV/smpc/internal/eventstream"
+	"github.com/Norgate-AV/smpc/internal/i18n"
+	"github.com/Norgate-AV/smpc/internal/keychord"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/redact"
+	"github.com/Norgate-AV/smpc/internal/report"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+	"github.com/Norgate-AV/smpc/internal/userconfig"
+	"github.com/Norgate-AV/smpc/internal/windowfilter"
+)
+
+// configCmd groups actions on the persistent config file
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the config file holding defaults for smpc's flags",
+	Long: `The config file holds defaults for smpc's flags, keyed by the flag name in
+camelCase: simplPath for --simpl-path. Flags given on the command line take
+precedence. The file is config.json beside the log, or the file named by
+SMPC_CONFIG.`,
+	Args: cobra.NoArgs,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:          "get <key>",
+	Short:        "Print the value of a config key",
+	Args:         cobra.ExactArgs(1),
+	RunE:         runConfigGet,
+	SilenceUsage: true,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>...",
+	Short: "Set a config key, checking the value as the flag would",
+	Example: `  smpc config set simplPath "D:\Crestron\Simpl\smpwin.exe"
+  smpc config set ifRunning kill
+  smpc config set simplEnv CRESTRON_LIB=C:\modules SITE=lobby`,
+	Args:         cobra.MinimumNArgs(2),
+	RunE:         runConfigSet,
+	SilenceUsage: true,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:          "unset <key>",
+	Short:        "Remove a config key, restoring the flag's built-in default",
+	Args:         cobra.ExactArgs(1),
+	RunE:         runConfigUnset,
+	SilenceUsage: true,
+}
+
+var configListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the config keys that are set",
+	Args:         cobra.NoArgs,
+	RunE:         runConfigList,
+	SilenceUsage: true,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:          "edit",
+	Short:        "Open the config file in $EDITOR (default notepad) and check it afterwards",
+	Args:         cobra.NoArgs,
+	RunE:         runConfigEdit,
+	SilenceUsage: true,
+}
+
+func init() {
+	configListCmd.Flags().Bool("all", false, "list every key, with the built-in default for those not set")
+
+	configCmd.AddCommand(configGetCmd, configSetCmd, configUnsetCmd, configListCmd, configEditCmd)
+	RootCmd.AddCommand(configCmd)
+
+	// Set here rather than on RootCmd, which applyConfigFile refers to
+	RootCmd.PersistentPreRunE = applyConfigFile
+}
+
+// noConfigFlag is given to the smpc processes smpc starts itself. They are passed
+// the settings this process resolved, which the config file must not override.
+const noConfigFlag = "no-config"
+
+// notConfigurable are the flags that only make sense for a single run
+var notConfigurable = map[string]bool{
+	"handoff":             true,
+	noConfigFlag:          true,
+	"logs":                true,
+	"graph":               true,
+	"verify-reproducible": true,
+}
+
+// configValidators check the values of flags with more to them than their type
+var configValidators = map[string]func(string) error{
+	"backend":            compiler.ValidateBackend,
+	"save-prompt":        compiler.ValidateSavePrompt,
+	"close-confirmation": compiler.ValidateCloseConfirmation,
+	"if-running":         validateIfRunning,
+	"if-interfering":     validateIfInterfering,
+	"update-prompts":     validateUpdatePrompts,
+	"show-mode":          validateShowMode,
+	"events":             eventstream.ValidateFormat,
+	"timestamps":         logger.ValidateTimestamps,
+	"redact": func(v string) error {
+		_, err := redact.ParseMode(v)
+		return err
+	},
+	"report": func(v string) error {
+		_, err := report.ParseSpec(v)
+		return err
+	},
+	"compile-key":   validateChord,
+	"recompile-key": validateChord,
+	"abort-key":     validateChord,
+	"temp-pattern": func(v string) error {
+		return artifacts.ValidatePatterns([]string{v})
+	},
+	"timeout-curve": func(v string) error {
+		_, err := timeouts.ParseSizeCurve(v)
+		return err
+	},
+	"lang": func(v string) error {
+		_, err := i18n.Resolve(v, func(string) string { return "" })
+		return err
+	},
+	"monitor-include": func(v string) error {
+		return windowfilter.Validate([]string{v})
+	},
+	"monitor-exclude": func(v string) error {
+		return windowfilter.Validate([]string{v})
+	},
+	"simpl-env": func(v string) error {
+		if key, _, ok := strings.Cut(v, "="); !ok || key == "" {
+			return fmt.Errorf("invalid variable %q (expected KEY=VALUE)", v)
+		}
+
+		return nil
+	},
+	"simpl-path": func(v string) error {
+		if info, err := os.Stat(v); err != nil || info.IsDir() {
+			return fmt.Errorf("%s is not a file", v)
+		}
+
+		return nil
+	},
+	"simpl-workdir": func(v string) error {
+		if info, err := os.Stat(v); err != nil || !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", v)
+		}
+
+		return nil
+	},
+}
+
+// validateChord accepts "" (the default keys) or a key chord
+func validateChord(v string) error {
+	if v == "" {
+		return nil
+	}
+
+	_, err := keychord.Parse(v)
+	return err
+}
+
+// configPath returns the config file: SMPC_CONFIG if set, else config.json beside the log
+func configPath() string {
+	if path := os.Getenv(userconfig.PathEnv); path != "" {
+		return path
+	}
+
+	return filepath.Join(filepath.Dir(logger.GetLogPath(logger.LoggerOptions{})), userconfig.FileName)
+}
+
+// configFlag returns the flag a config key sets
+func configFlag(key string) (*pflag.Flag, error) {
+	name := userconfig.Flag(key)
+
+	f := RootCmd.PersistentFlags().Lookup(name)
+	if f == nil || notConfigurable[name] || userconfig.Key(name) != key {
+		return nil, fmt.Errorf("unknown config key %q (see 'smpc config list --all')", key)
+	}
+
+	return f, nil
+}
+
+// checkConfigValue returns an error if values cannot be given to the flag for key
+func checkConfigValue(key string, values []string) error {
+	f, err := configFlag(key)
+	if err != nil {
+		return err
+	}
+
+	repeatable := f.Value.Type() == "stringArray"
+	if !repeatable && len(values) != 1 {
+		return fmt.Errorf("%s takes a single value", key)
+	}
+
+	for _, v := range values {
+		if err := checkFlagValue(f, v); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// checkFlagValue checks v against the flag's type and any validator for it
+func checkFlagValue(f *pflag.Flag, v string) error {
+	var err error
+
+	switch f.Value.Type() {
+	case "bool":
+		_, err = strconv.ParseBool(v)
+	case "int":
+		_, err = strconv.Atoi(v)
+	case "count":
+		if n, convErr := strconv.Atoi(v); convErr != nil || n < 0 {
+			err = fmt.Errorf("expected a count, got %q", v)
+		}
+	case "duration":
+		_, err = time.ParseDuration(v)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s value %q", f.Value.Type(), v)
+	}
+
+	if validate, ok := configValidators[f.Name]; ok {
+		return validate(v)
+	}
+
+	return nil
+}
+
+// fromConfigFile are the flags applyConfigFile set, for 'smpc env'
+var fromConfigFile = map[string]bool{}
+
+// configFileArgs are the flags applyConfigFile set, as command line arguments
+var configFileArgs []string
+
+// applyConfigFile sets the flags not given on the command line from the config
+// file. The config command itself skips it, so a broken file can still be fixed, as
+// do the processes started with --no-config.
+func applyConfigFile(cmd *cobra.Command, _ []string) error {
+	fromConfigFile = map[string]bool{}
+	configFileArgs = nil
+
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == configCmd {
+			return nil
+		}
+	}
+
+	if skip, _ := RootCmd.PersistentFlags().GetBool(noConfigFlag); skip {
+		return nil
+	}
+
+	file, err := userconfig.Load(configPath())
+	if err != nil {
+		return err
+	}
+
+	for _, key := range file.Keys() {
+		values, _ := file.Get(key)
+
+		if err := checkConfigValue(key, values); err != nil {
+			return fmt.Errorf("config file %s: %w", file.Path, err)
+		}
+
+		name := userconfig.Flag(key)
+
+		// Skip flags given on the command line, and local flags of the same name
+		f := cmd.Flags().Lookup(name)
+		if f == nil || f.Changed || f != RootCmd.PersistentFlags().Lookup(name) {
+			continue
+		}
+
+		for _, v := range values {
+			if err := cmd.Flags().Set(name, v); err != nil {
+				return fmt.Errorf("config file %s: %s: %w", file.Path, key, err)
+			}
+
+			configFileArgs = append(configFileArgs, "--"+name+"="+v)
+		}
+
+		fromConfigFile[name] = true
+	}
+
+	return nil
+}
+
+// inheritedConfigArgs returns --no-config and the flags that give a relaunch of this
+// process, with the same command line, the values it took from the config file
+func inheritedConfigArgs() []string {
+	return append([]string{"--" + noConfigFlag}, configFileArgs...)
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	if _, err := configFlag(args[0]); err != nil {
+		return err
+	}
+
+	file, err := userconfig.Load(configPath())
+	if err != nil {
+		return err
+	}
+
+	values, ok := file.Get(args[0])
+	if !ok {
+		return fmt.Errorf("%s is not set", args[0])
+	}
+
+	for _, v := range values {
+		fmt.Fprintln(cmd.OutOrStdout(), v)
+	}
+
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, values := args[0], args[1:]
+
+	if err := checkConfigValue(key, values); err != nil {
+		return err
+	}
+
+	file, err := userconfig.Load(configPath())
+	if err != nil {
+		return err
+	}
+
+	if f, _ := configFlag(key); f.Value.Type() == "stringArray" {
+		file.Values[key] = values
+	} else {
+		file.Values[key] = values[0]
+	}
+
+	return file.Save()
+}
+
+func runConfigUnset(_ *cobra.Command, args []string) error {
+	if _, err := configFlag(args[0]); err != nil {
+		return err
+	}
+
+	file, err := userconfig.Load(configPath())
+	if err != nil {
+		return err
+	}
+
+	if _, ok := file.Values[args[0]]; !ok {
+		return nil
+	}
+
+	delete(file.Values, args[0])
+	return file.Save()
+}
+
+func runConfigList(cmd *cobra.Command, _ []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+
+	file, err := userconfig.Load(configPath())
+	if err != nil {
+		return err
+	}
+
+	listConfig(cmd.OutOrStdout(), file, all)
+	return nil
+}
+
+// listConfig prints key=value for each key set, one line per value of repeatable
+// flags. With all, unset keys are listed too, with their built-in defaults.
+func listConfig(w io.Writer, file *userconfig.File, all bool) {
+	if !all {
+		for _, key := range file.Keys() {
+			values, _ := file.Get(key)
+			for _, v := range values {
+				fmt.Fprintf(w, "%s=%s\n", key, v)
+			}
+		}
+
+		return
+	}
+
+	RootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if notConfigurable[f.Name] {
+			return
+		}
+
+		key := userconfig.Key(f.Name)
+
+		values, ok := file.Get(key)
+		if !ok {
+			fmt.Fprintf(w, "%s=%s (default)\n", key, strings.Trim(f.DefValue, "[]"))
+			return
+		}
+
+		for _, v := range values {
+			fmt.Fprintf(w, "%s=%s\n", key, v)
+		}
+	})
+}
+
+func runConfigEdit(cmd *cobra.Command, _ []string) error {
+	file, err := userconfig.Load(configPath())
+	if err != nil {
+		return err
+	}
+
+	// Give the editor a file to open
+	if _, err := os.Stat(file.Path); os.IsNotExist(err) {
+		if err := file.Save(); err != nil {
+			return err
+		}
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"notepad.exe"}
+	}
+
+	edit := exec.Command(editor[0], append(editor[1:], file.Path)...)
+	edit.Stdin, edit.Stdout, edit.Stderr = os.Stdin, cmd.OutOrStdout(), cmd.ErrOrStderr()
+
+	if err := edit.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+
+	edited, err := userconfig.Load(file.Path)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range edited.Keys() {
+		values, _ := edited.Get(key)
+
+		if err := checkConfigValue(key, values); err != nil {
+			return fmt.Errorf("config file %s: %w", edited.Path, err)
+		}
+	}
+
+	return nil
+}
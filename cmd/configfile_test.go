@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/userconfig"
+)
+
+// TestCheckConfigValue checks values against the flag's type and validator
+func TestCheckConfigValue(t *testing.T) {
+	assert.NoError(t, checkConfigValue("ifRunning", []string{"kill"}))
+	assert.NoError(t, checkConfigValue("crashRetries", []string{"2"}))
+	assert.NoError(t, checkConfigValue("maxRuntime", []string{"30m"}))
+	assert.NoError(t, checkConfigValue("simplEnv", []string{"A=1", "B=2"}))
+	assert.NoError(t, checkConfigValue("simplPath", []string{"configfile.go"}))
+
+	assert.ErrorContains(t, checkConfigValue("ifRunning", []string{"sometimes"}), "unsupported --if-running")
+	assert.ErrorContains(t, checkConfigValue("ifRunning", []string{"kill", "abort"}), "single value")
+	assert.ErrorContains(t, checkConfigValue("crashRetries", []string{"two"}), "invalid int")
+	assert.ErrorContains(t, checkConfigValue("maxRuntime", []string{"soon"}), "invalid duration")
+	assert.ErrorContains(t, checkConfigValue("simplEnv", []string{"A"}), "KEY=VALUE")
+	assert.ErrorContains(t, checkConfigValue("simplPath", []string{filepath.Join(t.TempDir(), "smpwin.exe")}), "not a file")
+	assert.ErrorContains(t, checkConfigValue("handoff", []string{"x"}), "unknown config key")
+	assert.ErrorContains(t, checkConfigValue("if-running", []string{"kill"}), "unknown config key")
+}
+
+// TestConfigCommands sets, gets, lists and unsets keys in the file named by SMPC_CONFIG
+func TestConfigCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "smpc", "config.json")
+	t.Setenv(userconfig.PathEnv, path)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	require.NoError(t, runConfigSet(cmd, []string{"ifRunning", "kill"}))
+	require.NoError(t, runConfigSet(cmd, []string{"simplEnv", "A=1", "B=2"}))
+	assert.Error(t, runConfigSet(cmd, []string{"ifRunning", "sometimes"}))
+
+	require.NoError(t, runConfigGet(cmd, []string{"ifRunning"}))
+	assert.Equal(t, "kill\n", out.String())
+
+	out.Reset()
+	listConfig(&out, mustLoadConfig(t, path), false)
+	assert.Equal(t, "ifRunning=kill\nsimplEnv=A=1\nsimplEnv=B=2\n", out.String())
+
+	out.Reset()
+	listConfig(&out, mustLoadConfig(t, path), true)
+	assert.Contains(t, out.String(), "ifInterfering=warn (default)\n")
+	assert.NotContains(t, out.String(), "handoff")
+
+	require.NoError(t, runConfigUnset(cmd, []string{"ifRunning"}))
+	assert.ErrorContains(t, runConfigGet(cmd, []string{"ifRunning"}), "not set")
+}
+
+// TestApplyConfigFile fills in flags not given on the command line
+func TestApplyConfigFile(t *testing.T) {
+	defer resetFlags()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(userconfig.PathEnv, path)
+
+	file := &userconfig.File{Path: path, Values: map[string]any{"ifRunning": "kill", "ifInterfering": "wait"}}
+	require.NoError(t, file.Save())
+
+	// resetFlags leaves the flags marked as given
+	resetFlags()
+	RootCmd.PersistentFlags().Lookup("if-running").Changed = false
+	require.NoError(t, RootCmd.ParseFlags([]string{"--if-interfering", "ignore"}))
+	require.NoError(t, applyConfigFile(RootCmd, nil))
+
+	ifRunning, _ := RootCmd.PersistentFlags().GetString("if-running")
+	ifInterfering, _ := RootCmd.PersistentFlags().GetString("if-interfering")
+	assert.Equal(t, "kill", ifRunning)
+	assert.Equal(t, "ignore", ifInterfering, "the command line takes precedence")
+	assert.Equal(t, []string{"--no-config", "--if-running=kill"}, inheritedConfigArgs())
+
+	file.Values["ifRunning"] = "sometimes"
+	require.NoError(t, file.Save())
+	assert.ErrorContains(t, applyConfigFile(RootCmd, nil), "config file "+path)
+
+	// The config commands still work, so the file can be fixed
+	assert.NoError(t, applyConfigFile(configSetCmd, nil))
+}
+
+// TestApplyConfigFile_NoConfig leaves the flags of a process smpc started alone
+func TestApplyConfigFile_NoConfig(t *testing.T) {
+	defer resetFlags()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(userconfig.PathEnv, path)
+
+	file := &userconfig.File{Path: path, Values: map[string]any{"ifRunning": "kill", "json": true}}
+	require.NoError(t, file.Save())
+
+	resetFlags()
+	RootCmd.PersistentFlags().Lookup("if-running").Changed = false
+	RootCmd.PersistentFlags().Lookup("json").Changed = false
+	require.NoError(t, RootCmd.ParseFlags([]string{"--no-config"}))
+	require.NoError(t, applyConfigFile(RootCmd, nil))
+
+	ifRunning, _ := RootCmd.PersistentFlags().GetString("if-running")
+	jsonOut, _ := RootCmd.PersistentFlags().GetBool("json")
+	assert.NotEqual(t, "kill", ifRunning)
+	assert.False(t, jsonOut)
+	assert.Equal(t, []string{"--no-config"}, inheritedConfigArgs())
+}
+
+func mustLoadConfig(t *testing.T, path string) *userconfig.File {
+	t.Helper()
+
+	file, err := userconfig.Load(path)
+	require.NoError(t, err)
+
+	return file
+}
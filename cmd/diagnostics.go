@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime/trace"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+// startDiagnostics enables the --pprof endpoint and --trace capture requested in cfg.
+// The returned stop function shuts both down and must be called before exit.
+func startDiagnostics(cfg *Config, log logger.LoggerInterface) (stop func(), err error) {
+	var stops []func()
+
+	stop = func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+
+	if cfg.PprofAddr != "" {
+		stopPprof, err := startPprofServer(cfg.PprofAddr, log)
+		if err != nil {
+			return stop, err
+		}
+
+		stops = append(stops, stopPprof)
+	}
+
+	if cfg.TraceFile != "" {
+		stopTrace, err := startTrace(cfg.TraceFile, log)
+		if err != nil {
+			stop()
+			return func() {}, err
+		}
+
+		stops = append(stops, stopTrace)
+	}
+
+	return stop, nil
+}
+
+// startPprofServer serves the net/http/pprof endpoints on addr using a private mux,
+// so nothing else is exposed
+func startPprofServer(addr string, log logger.LoggerInterface) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start pprof server on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Warn("pprof server stopped", slog.Any("error", err))
+		}
+	}()
+
+	log.Info("Profiling endpoints available", slog.String("url", fmt.Sprintf("http://%s/debug/pprof/", listener.Addr())))
+
+	return func() {
+		_ = server.Close()
+	}, nil
+}
+
+// startTrace captures a runtime execution trace to path until stopped
+func startTrace(path string, log logger.LoggerInterface) (func(), error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace file: %w", err)
+	}
+
+	if err := trace.Start(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to start execution trace: %w", err)
+	}
+
+	log.Debug("Execution trace started", slog.String("path", path))
+
+	return func() {
+		trace.Stop()
+
+		if err := f.Close(); err != nil {
+			log.Warn("Failed to close trace file", slog.Any("error", err))
+			return
+		}
+
+		log.Info("Execution trace written", slog.String("path", path))
+	}, nil
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+// TestStartDiagnostics_Disabled tests that nothing is started without flags
+func TestStartDiagnostics_Disabled(t *testing.T) {
+	stop, err := startDiagnostics(&Config{}, logger.NewNoOpLogger())
+	require.NoError(t, err)
+	assert.NotPanics(t, stop)
+}
+
+// TestStartDiagnostics_Trace tests that the execution trace is written on stop
+func TestStartDiagnostics_Trace(t *testing.T) {
+	tracePath := filepath.Join(t.TempDir(), "smpc.trace")
+
+	stop, err := startDiagnostics(&Config{TraceFile: tracePath}, logger.NewNoOpLogger())
+	require.NoError(t, err)
+	stop()
+
+	info, err := os.Stat(tracePath)
+	require.NoError(t, err)
+	assert.Positive(t, info.Size(), "Trace file should contain data")
+}
+
+// TestStartDiagnostics_PprofInvalidAddress tests that a bad --pprof address is reported
+func TestStartDiagnostics_PprofInvalidAddress(t *testing.T) {
+	_, err := startDiagnostics(&Config{PprofAddr: "not-an-address"}, logger.NewNoOpLogger())
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/Norgate-AV/smpc/internal/i18n"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+	"github.com/Norgate-AV/smpc/internal/userconfig"
+)
+
+// Where a setting shown by 'smpc env' comes from
+const (
+	sourceFlag       = "flag"
+	sourceConfigFile = "config file"
+	sourceDefault    = "default"
+	sourceEnvPrefix  = "env " // Followed by the variable name
+)
+
+// envCmd prints the settings a run with the same flags would use
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Print the effective settings and where each comes from",
+	Long: `Print the settings a run with the same flags would use, after applying the
+config file, environment variables and built-in defaults, with the source of
+each: flag, config file, env <VARIABLE> or default.`,
+	Example: `  smpc env
+  smpc env --if-running kill`,
+	Args:         cobra.NoArgs,
+	RunE:         runEnv,
+	SilenceUsage: true,
+}
+
+func init() {
+	RootCmd.AddCommand(envCmd)
+}
+
+// setting is one line of 'smpc env'
+type setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+func runEnv(cmd *cobra.Command, _ []string) error {
+	return writeSettings(cmd.OutOrStdout(), effectiveSettings(RootCmd.PersistentFlags(), fromConfigFile, os.Getenv))
+}
+
+// effectiveSettings resolves the config file and log paths, then every configurable
+// flag, in the order the flags sort
+func effectiveSettings(flags *pflag.FlagSet, fromFile map[string]bool, getenv func(string) string) []setting {
+	config := setting{Key: "configFile", Value: configPath(), Source: sourceDefault}
+	if getenv(userconfig.PathEnv) != "" {
+		config.Source = sourceEnvPrefix + userconfig.PathEnv
+	}
+
+	settings := []setting{
+		config,
+		{Key: "logFile", Value: logger.GetLogPath(logger.LoggerOptions{}), Source: sourceDefault},
+	}
+
+	flags.VisitAll(func(f *pflag.Flag) {
+		if notConfigurable[f.Name] || f.Hidden {
+			return
+		}
+
+		settings = append(settings, resolveSetting(f, fromFile, getenv))
+	})
+
+	return settings
+}
+
+// resolveSetting returns the flag's value and source. Flags left at their default
+// show the value the run falls back to, which may come from the environment.
+func resolveSetting(f *pflag.Flag, fromFile map[string]bool, getenv func(string) string) setting {
+	s := setting{Key: userconfig.Key(f.Name), Value: flagValue(f), Source: sourceDefault}
+
+	switch {
+	case fromFile[f.Name]:
+		s.Source = sourceConfigFile
+		return s
+	case f.Changed:
+		s.Source = sourceFlag
+		return s
+	}
+
+	switch f.Name {
+	case "simpl-path":
+		s.Value = simpl.DefaultSimplWindowsPath
+		if v := getenv(simpl.SimplWindowsPathEnv); v != "" {
+			s.Value, s.Source = v, sourceEnvPrefix+simpl.SimplWindowsPathEnv
+		}
+	case "lang":
+		s.Value, _ = i18n.Resolve("", getenv)
+		for _, name := range i18n.LocaleEnv {
+			if getenv(name) != "" {
+				s.Source = sourceEnvPrefix + name
+				break
+			}
+		}
+	case "timeout-curve":
+		s.Value = timeouts.DefaultSizeCurve.String()
+	}
+
+	return s
+}
+
+// flagValue returns the flag's value, with repeatable flags comma-separated
+func flagValue(f *pflag.Flag) string {
+	if slice, ok := f.Value.(pflag.SliceValue); ok {
+		return strings.Join(slice.GetSlice(), ",")
+	}
+
+	return f.Value.String()
+}
+
+func writeSettings(out io.Writer, settings []setting) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+
+	for _, s := range settings {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, s.Value, s.Source)
+	}
+
+	return w.Flush()
+}
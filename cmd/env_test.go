@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+)
+
+// TestEffectiveSettings attributes each value to the flag, config file, environment or default
+func TestEffectiveSettings(t *testing.T) {
+	flags := pflag.NewFlagSet("smpc", pflag.ContinueOnError)
+	flags.String("if-running", "ignore", "")
+	flags.String("if-interfering", "warn", "")
+	flags.String("simpl-path", "", "")
+	flags.StringArray("simpl-env", nil, "")
+	flags.String("lang", "", "")
+	flags.String("timeout-curve", "", "")
+	flags.Bool("graph", false, "")
+
+	require.NoError(t, flags.Parse([]string{"--if-running", "kill", "--simpl-env", "A=1", "--simpl-env", "B=2"}))
+
+	env := map[string]string{"SIMPL_WINDOWS_PATH": `D:\Simpl\smpwin.exe`, "LANG": "de_DE.UTF-8"}
+	getenv := func(name string) string { return env[name] }
+
+	settings := effectiveSettings(flags, map[string]bool{"simpl-env": true}, getenv)
+
+	byKey := map[string]setting{}
+	for _, s := range settings {
+		byKey[s.Key] = s
+	}
+
+	assert.Equal(t, "configFile", settings[0].Key)
+	assert.Equal(t, setting{"ifRunning", "kill", sourceFlag}, byKey["ifRunning"])
+	assert.Equal(t, setting{"ifInterfering", "warn", sourceDefault}, byKey["ifInterfering"])
+	assert.Equal(t, setting{"simplEnv", "A=1,B=2", sourceConfigFile}, byKey["simplEnv"])
+	assert.Equal(t, setting{"simplPath", `D:\Simpl\smpwin.exe`, "env SIMPL_WINDOWS_PATH"}, byKey["simplPath"])
+	assert.Equal(t, setting{"lang", "de", "env LANG"}, byKey["lang"])
+	assert.Equal(t, timeouts.DefaultSizeCurve.String(), byKey["timeoutCurve"].Value)
+	assert.NotContains(t, byKey, "graph")
+
+	var buf bytes.Buffer
+	require.NoError(t, writeSettings(&buf, settings))
+	assert.Regexp(t, `(?m)^ifRunning +kill +flag$`, buf.String())
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// relatedFileTypes explains the Crestron files most often passed in place of a program
+var relatedFileTypes = map[string]string{
+	".umc": "is a user module; compile a program that uses it, or pass the project directory",
+	".usp": "is a SIMPL+ module; compile a program that uses it, or pass the project directory",
+	".ush": "is a SIMPL+ header; compile a program that uses it, or pass the project directory",
+	".lpz": "is a compiled 3-Series program; pass the .smw it was compiled from",
+	".cpz": "is a compiled 4-Series program; pass the .smw it was compiled from",
+	".zip": "is an archive; extract it and pass the .smw program inside",
+	".vtp": "is a VT Pro-e project; smpc only compiles SIMPL Windows programs",
+}
+
+// checkProgramFile returns an error unless path names a SIMPL Windows program. The
+// extension is matched case-insensitively, and related Crestron files get a hint.
+func checkProgramFile(path string) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".smw" {
+		return nil
+	}
+
+	if hint, ok := relatedFileTypes[ext]; ok {
+		return fmt.Errorf("file must have .smw extension: %s %s", filepath.Base(path), hint)
+	}
+
+	return fmt.Errorf("file must have .smw extension: %s", filepath.Base(path))
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckProgramFile(t *testing.T) {
+	t.Parallel()
+
+	for _, file := range []string{"Lobby.smw", "Lobby.SMW", `C:\Jobs\Lobby.Smw`} {
+		assert.NoError(t, checkProgramFile(file), file)
+	}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "Lobby.umc", want: "Lobby.umc is a user module"},
+		{file: "Volume.USP", want: "Volume.USP is a SIMPL+ module"},
+		{file: "Lobby.lpz", want: "compiled 3-Series program"},
+		{file: "Lobby.cpz", want: "compiled 4-Series program"},
+		{file: "Lobby.zip", want: "extract it and pass the .smw program inside"},
+		{file: "Panel.vtp", want: "VT Pro-e project"},
+		{file: "notes.txt", want: "file must have .smw extension: notes.txt"},
+	}
+
+	for _, tt := range tests {
+		err := checkProgramFile(tt.file)
+		if assert.Error(t, err, tt.file) {
+			assert.Contains(t, err.Error(), "file must have .smw extension")
+			assert.Contains(t, err.Error(), tt.want)
+		}
+	}
+}
+
+func TestValidateArgs_UppercaseExtension(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, validateArgs(&cobra.Command{}, []string{"test.SMW"}))
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/guilock"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+const (
+	// guiLockEnv is set, to the holder's PID, for the child smpc processes an instance
+	// holding the GUI lock runs on its behalf
+	guiLockEnv = "SMPC_GUI_LOCK_HELD"
+
+	// guiLockReportInterval is how often a waiting instance reports who holds the lock
+	guiLockReportInterval = 15 * time.Second
+)
+
+// acquireGUILock takes the machine-wide lock that lets only one smpc instance drive
+// SIMPL Windows at a time, waiting up to --wait-for-lock while another instance
+// holds it and reporting which one that is. The returned function releases it.
+func acquireGUILock(cfg *Config, program string, log logger.LoggerInterface) (release func(), err error) {
+	if inheritedGUILock() {
+		log.Debug("GUI lock is held by the parent smpc process")
+		return func() {}, nil
+	}
+
+	path := guiLockHolderPath()
+	deadline := time.Now().Add(cfg.WaitForLock)
+
+	lock, err := windows.AcquireMutex(guilock.MutexName, 0)
+
+	for errors.Is(err, windows.ErrMutexTimeout) {
+		holder := describeGUILockHolder(path)
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			if cfg.WaitForLock > 0 {
+				return nil, fmt.Errorf("%w after waiting %s: %s", guilock.ErrLocked, cfg.WaitForLock, holder)
+			}
+
+			return nil, fmt.Errorf("%w: %s (use --wait-for-lock to wait for it)", guilock.ErrLocked, holder)
+		}
+
+		log.Info("Waiting for another smpc instance to finish with SIMPL Windows",
+			slog.String("holder", holder),
+			slog.Duration("remaining", remaining.Round(time.Second)),
+		)
+
+		lock, err = windows.AcquireMutex(guilock.MutexName, min(remaining, guiLockReportInterval))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire the GUI lock: %w", err)
+	}
+
+	if lock.Abandoned {
+		log.Warn("The previous holder of the GUI lock exited without releasing it")
+	}
+
+	pid := uint32(os.Getpid())
+
+	holder := guilock.Holder{
+		Pid:     pid,
+		User:    currentUser(),
+		Origin:  guiLockOrigin(parentExe(), cfg.Handoff != ""),
+		Program: program,
+		Since:   time.Now(),
+	}
+
+	if err := guilock.Write(path, holder); err != nil {
+		log.Debug("Could not record the GUI lock holder", slog.Any("error", err))
+	}
+
+	log.Debug("GUI lock acquired", slog.String("origin", holder.Origin))
+
+	return func() {
+		_ = guilock.Clear(path, pid)
+		lock.Release()
+	}, nil
+}
+
+// shareGUILock lets child run while this instance holds the GUI lock
+func shareGUILock(child *exec.Cmd) {
+	child.Env = append(os.Environ(), guiLockEnv+"="+strconv.Itoa(os.Getpid()))
+}
+
+// inheritedGUILock reports whether this process was started by a parent holding the
+// GUI lock for it
+func inheritedGUILock() bool {
+	return os.Getenv(guiLockEnv) == strconv.Itoa(os.Getppid())
+}
+
+// guiLockHolderPath returns the machine-wide file the lock holder records itself in
+func guiLockHolderPath() string {
+	dir := os.Getenv("ProgramData")
+	if dir == "" {
+		dir = `C:\ProgramData`
+	}
+
+	return filepath.Join(dir, "smpc", guilock.FileName)
+}
+
+// describeGUILockHolder says which instance holds the lock, as far as is known
+func describeGUILockHolder(path string) string {
+	holder, err := guilock.Read(path)
+	if err != nil {
+		return "an instance that did not record itself"
+	}
+
+	if p, err := windows.LookupProcess(holder.Pid); err != nil || p.Created.After(holder.Since) {
+		return fmt.Sprintf("an unknown instance (the last recorded holder, PID %d, has exited)", holder.Pid)
+	}
+
+	return holder.String()
+}
+
+// guiLockOrigin classifies how this instance was started from its parent's executable.
+// An elevated relaunch is a child of the smpc it was relaunched from, but was started
+// from the command line; any other smpc child of smpc is a compile service job.
+func guiLockOrigin(parentExe string, relaunched bool) string {
+	switch strings.ToLower(parentExe) {
+	case "smpc.exe":
+		if relaunched {
+			return guilock.OriginCommandLine
+		}
+
+		return guilock.OriginService
+	case "svchost.exe", "taskhostw.exe", "taskeng.exe":
+		return guilock.OriginScheduledTask
+	default:
+		return guilock.OriginCommandLine
+	}
+}
+
+// parentExe returns the executable name of this process's parent, or "" if unknown
+func parentExe() string {
+	p, err := windows.LookupProcess(uint32(os.Getppid()))
+	if err != nil {
+		return ""
+	}
+
+	return p.ExeFile
+}
+
+// currentUser returns the DOMAIN\user this process runs as
+func currentUser() string {
+	user := os.Getenv("USERNAME")
+	if domain := os.Getenv("USERDOMAIN"); domain != "" && user != "" {
+		return domain + `\` + user
+	}
+
+	return user
+}
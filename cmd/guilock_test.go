@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/guilock"
+)
+
+func TestGUILockOrigin(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, guilock.OriginCommandLine, guiLockOrigin("powershell.exe", false))
+	assert.Equal(t, guilock.OriginCommandLine, guiLockOrigin("smpc.exe", true), "elevated relaunch")
+	assert.Equal(t, guilock.OriginService, guiLockOrigin("SMPC.EXE", false))
+	assert.Equal(t, guilock.OriginScheduledTask, guiLockOrigin("svchost.exe", false))
+	assert.Equal(t, guilock.OriginCommandLine, guiLockOrigin("", false))
+}
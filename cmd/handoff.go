@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// Files in the handoff directory that an elevated instance writes its console output to
+const (
+	handoffStdout = "stdout"
+	handoffStderr = "stderr"
+
+	handoffPollInterval = 100 * time.Millisecond
+)
+
+// relaunchElevated starts an elevated instance that writes its console output to a
+// handoff directory, relays that output to this console as it arrives, and returns the
+// elevated instance's exit code so callers such as CI see the real result
+func relaunchElevated() (int, error) {
+	dir, err := os.MkdirTemp("", "smpc-handoff-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create handoff directory: %w", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	stdout, err := createHandoffFile(dir, handoffStdout)
+	if err != nil {
+		return 0, err
+	}
+
+	defer stdout.Close()
+
+	stderr, err := createHandoffFile(dir, handoffStderr)
+	if err != nil {
+		return 0, err
+	}
+
+	defer stderr.Close()
+
+	relay := func() {
+		_, _ = io.Copy(os.Stdout, stdout)
+		_, _ = io.Copy(os.Stderr, stderr)
+	}
+
+	// The elevated instance may run as another user, with another config file
+	args := append([]string{"--handoff", dir}, inheritedConfigArgs()...)
+
+	return windows.RelaunchAsAdminAndWait(args, handoffPollInterval, relay)
+}
+
+// createHandoffFile creates an empty handoff file and opens it for reading
+func createHandoffFile(dir, name string) (*os.File, error) {
+	path := filepath.Join(dir, name)
+
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to create handoff file: %w", err)
+	}
+
+	return os.Open(path)
+}
+
+// redirectToHandoff sends this (elevated) instance's console output to the handoff
+// directory created by the instance that relaunched it
+func redirectToHandoff(dir string) error {
+	stdout, err := os.OpenFile(filepath.Join(dir, handoffStdout), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open handoff output: %w", err)
+	}
+
+	stderr, err := os.OpenFile(filepath.Join(dir, handoffStderr), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		stdout.Close()
+		return fmt.Errorf("failed to open handoff output: %w", err)
+	}
+
+	os.Stdout = stdout
+	os.Stderr = stderr
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedirectToHandoff tests that an elevated instance's output lands in the handoff files
+func TestRedirectToHandoff(t *testing.T) {
+	dir := t.TempDir()
+
+	stdoutReader, err := createHandoffFile(dir, handoffStdout)
+	require.NoError(t, err)
+	defer stdoutReader.Close()
+
+	stderrReader, err := createHandoffFile(dir, handoffStderr)
+	require.NoError(t, err)
+	defer stderrReader.Close()
+
+	origStdout, origStderr := os.Stdout, os.Stderr
+	defer func() {
+		os.Stdout.Close()
+		os.Stderr.Close()
+		os.Stdout, os.Stderr = origStdout, origStderr
+	}()
+
+	require.NoError(t, redirectToHandoff(dir))
+
+	_, _ = os.Stdout.WriteString("compiled\n")
+	_, _ = os.Stderr.WriteString("warning\n")
+
+	out, err := os.ReadFile(filepath.Join(dir, handoffStdout))
+	require.NoError(t, err)
+	assert.Equal(t, "compiled\n", string(out))
+
+	errOut, err := os.ReadFile(filepath.Join(dir, handoffStderr))
+	require.NoError(t, err)
+	assert.Equal(t, "warning\n", string(errOut))
+}
+
+// TestRedirectToHandoff_MissingDir tests that a missing handoff directory is reported
+func TestRedirectToHandoff_MissingDir(t *testing.T) {
+	t.Parallel()
+
+	err := redirectToHandoff(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "handoff")
+}
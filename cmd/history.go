@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Norgate-AV/smpc/internal/history"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/redact"
+	"github.com/Norgate-AV/smpc/internal/report"
+)
+
+// historyCmd groups actions on the compile history
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Inspect the history of compiles run on this machine",
+	Args:  cobra.NoArgs,
+}
+
+// historyReportCmd summarizes the compile history
+var historyReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report compile durations, warning trends and failure rates per program",
+	Long: `Summarize recent compiles per program: run count, failure rate, average and
+longest duration, how the warning count moved over the period, and a per-day
+breakdown. Every compile is recorded unless --no-history is set.`,
+	Example: `  smpc history report --since 30d
+  smpc history report --since 2w --format html --output history.html`,
+	Args:         cobra.NoArgs,
+	RunE:         runHistoryReport,
+	SilenceUsage: true,
+}
+
+func init() {
+	historyReportCmd.Flags().String("since", "30d", "how far back to report, e.g. 30d, 2w or 12h")
+	historyReportCmd.Flags().String("format", history.FormatMarkdown, "report format: markdown or html")
+	historyReportCmd.Flags().String("output", "-", "file to write the report to, or - for stdout")
+
+	historyCmd.AddCommand(historyReportCmd)
+	RootCmd.AddCommand(historyCmd)
+}
+
+// historyPath returns the history file, kept beside the log
+func historyPath() string {
+	return filepath.Join(filepath.Dir(logger.GetLogPath(logger.LoggerOptions{})), history.FileName)
+}
+
+// recordHistory appends the results of a run to the history file, redacted like the
+// log. Failures are logged but never change the exit status of the compile.
+func recordHistory(results []report.FileResult, now time.Time, redactor *redact.Redactor, log logger.LoggerInterface) {
+	entries := make([]history.Entry, 0, len(results))
+
+	for _, r := range redactResults(results, redactor) {
+		var commit string
+		if r.Git != nil {
+			commit = r.Git.Commit
+		}
+
+		entries = append(entries, history.Entry{
+			Time:     now,
+			File:     r.File,
+			Status:   r.Status,
+			Errors:   r.Errors,
+			Warnings: r.Warnings,
+			Notices:  r.Notices,
+			Seconds:  r.Duration.Seconds(),
+			Message:  r.Message,
+			Commit:   commit,
+		})
+	}
+
+	if err := history.Append(historyPath(), entries...); err != nil {
+		log.Warn("Failed to record compile history", slog.Any("error", err))
+	}
+}
+
+func runHistoryReport(cmd *cobra.Command, _ []string) error {
+	since, _ := cmd.Flags().GetString("since")
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+
+	if output == "-" {
+		return writeHistoryReport(cmd.OutOrStdout(), historyPath(), since, format, time.Now())
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	if err := writeHistoryReport(f, historyPath(), since, format, time.Now()); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %s\n", output)
+	return nil
+}
+
+// writeHistoryReport renders the compiles recorded in path within the since period
+func writeHistoryReport(w io.Writer, path, since, format string, now time.Time) error {
+	period, err := history.ParseSince(since)
+	if err != nil {
+		return err
+	}
+
+	start := now.Add(-period)
+
+	entries, err := history.Load(path, start)
+	if err != nil {
+		return err
+	}
+
+	return history.Write(w, history.Summarize(entries, start, now), format)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/history"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/report"
+)
+
+// TestRecordHistory appends results to the history beside the log and reports on them
+func TestRecordHistory(t *testing.T) {
+	t.Setenv("LOCALAPPDATA", t.TempDir())
+
+	now := time.Now()
+	recordHistory([]report.FileResult{
+		{File: `C:\jobs\lobby.smw`, Status: report.StatusPassed, Warnings: 2, Duration: 3 * time.Second},
+	}, now, nil, logger.NewNoOpLogger())
+
+	entries, err := history.Load(historyPath(), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, 2, entries[0].Warnings)
+	assert.Equal(t, 3*time.Second, entries[0].Duration())
+
+	var buf bytes.Buffer
+	require.NoError(t, writeHistoryReport(&buf, historyPath(), "7d", history.FormatMarkdown, now.Add(time.Minute)))
+	assert.Contains(t, buf.String(), `C:\jobs\lobby.smw | 1 | 0%`)
+
+	assert.Error(t, writeHistoryReport(&buf, historyPath(), "soon", history.FormatMarkdown, now))
+}
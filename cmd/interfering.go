@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/clock"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+)
+
+// --if-interfering policies for other Crestron tools running at startup
+const (
+	ifInterferingIgnore = "ignore" // Launch without checking
+	ifInterferingWarn   = "warn"   // Log them and launch anyway
+	ifInterferingWait   = "wait"   // Wait for them to exit before launching
+)
+
+// validateIfInterfering returns an error if mode is not a supported --if-interfering policy
+func validateIfInterfering(mode string) error {
+	switch mode {
+	case "", ifInterferingIgnore, ifInterferingWarn, ifInterferingWait:
+		return nil
+	default:
+		return fmt.Errorf("unsupported --if-interfering %q (supported: %s, %s, %s)",
+			mode, ifInterferingIgnore, ifInterferingWarn, ifInterferingWait)
+	}
+}
+
+// handleInterferingApps applies the --if-interfering policy to Crestron tools that can make
+// SIMPL Windows hang at launch. Waiting gives up after timeout and launches anyway.
+func handleInterferingApps(
+	mode string,
+	find func() ([]simpl.InterferingApp, error),
+	timeout time.Duration,
+	clk clock.Clock,
+	log logger.LoggerInterface,
+) {
+	if mode == ifInterferingIgnore {
+		return
+	}
+
+	apps, err := find()
+	if err != nil {
+		log.Warn("Could not check for other Crestron tools", slog.Any("error", err))
+		return
+	}
+
+	if len(apps) == 0 {
+		return
+	}
+
+	for _, app := range apps {
+		log.Warn("Another Crestron tool is running and may stop SIMPL Windows from starting",
+			slog.String("app", app.Name),
+			slog.Uint64("pid", uint64(app.Pid)),
+			slog.Any("windows", app.Windows),
+		)
+	}
+
+	if mode != ifInterferingWait {
+		log.Info("Launching anyway; close them or use --if-interfering wait if SIMPL Windows hangs")
+		return
+	}
+
+	log.Info("Waiting for other Crestron tools to exit", slog.Duration("timeout", timeout))
+	deadline := clk.Now().Add(timeout)
+
+	for clk.Now().Before(deadline) {
+		clk.Sleep(timeouts.MaxPollingInterval)
+
+		if apps, err = find(); err == nil && len(apps) == 0 {
+			log.Info("Other Crestron tools have exited")
+			return
+		}
+	}
+
+	log.Warn("Other Crestron tools are still running, launching anyway", slog.Duration("waited", timeout))
+}
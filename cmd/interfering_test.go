@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+	"github.com/Norgate-AV/smpc/internal/testutil"
+)
+
+func TestHandleInterferingApps(t *testing.T) {
+	t.Parallel()
+
+	vtpro := []simpl.InterferingApp{{Pid: 200, Name: "VT Pro-e"}}
+
+	t.Run("ignore does not look", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		handleInterferingApps(ifInterferingIgnore, func() ([]simpl.InterferingApp, error) {
+			calls++
+			return vtpro, nil
+		}, time.Minute, testutil.NewFakeClock(), logger.NewNoOpLogger())
+
+		assert.Zero(t, calls)
+	})
+
+	t.Run("warn does not wait", func(t *testing.T) {
+		t.Parallel()
+
+		clk := testutil.NewFakeClock()
+		start := clk.Now()
+
+		handleInterferingApps(ifInterferingWarn, func() ([]simpl.InterferingApp, error) {
+			return vtpro, nil
+		}, time.Minute, clk, logger.NewNoOpLogger())
+
+		assert.Zero(t, clk.Since(start))
+	})
+
+	t.Run("wait until they exit", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		handleInterferingApps(ifInterferingWait, func() ([]simpl.InterferingApp, error) {
+			calls++
+			if calls > 2 {
+				return nil, nil
+			}
+
+			return vtpro, nil
+		}, time.Minute, testutil.NewFakeClock(), logger.NewNoOpLogger())
+
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("wait gives up", func(t *testing.T) {
+		t.Parallel()
+
+		clk := testutil.NewFakeClock()
+		start := clk.Now()
+
+		handleInterferingApps(ifInterferingWait, func() ([]simpl.InterferingApp, error) {
+			return vtpro, nil
+		}, time.Minute, clk, logger.NewNoOpLogger())
+
+		assert.GreaterOrEqual(t, clk.Since(start), time.Minute)
+	})
+
+	t.Run("lookup errors are not fatal", func(t *testing.T) {
+		t.Parallel()
+
+		handleInterferingApps(ifInterferingWait, func() ([]simpl.InterferingApp, error) {
+			return nil, errors.New("access denied")
+		}, time.Minute, testutil.NewFakeClock(), logger.NewNoOpLogger())
+	})
+}
+
+func TestValidateIfInterfering(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []string{"", "ignore", "warn", "wait"} {
+		assert.NoError(t, validateIfInterfering(mode))
+	}
+
+	assert.Error(t, validateIfInterfering("kill"))
+}
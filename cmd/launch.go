@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// --show-mode values for the SIMPL Windows main window
+const (
+	showModeNormal    = "normal"    // Shown and focused as usual
+	showModeMinimized = "minimized" // Minimized without taking focus
+	showModeHidden    = "hidden"    // Hidden once it is ready
+	showModeOffscreen = "offscreen" // Moved beyond the edge of the screen once it is ready
+)
+
+// simplLaunch is the working directory, extra environment and show mode SIMPL Windows
+// is started with, from --simpl-workdir, --simpl-env and --show-mode
+type simplLaunch struct {
+	Dir  string   // Working directory ("" = smpc's own)
+	Env  []string // KEY=VALUE pairs added to the inherited environment
+	Show string   // How the main window is shown ("" = normal)
+}
+
+// SimplLaunch checks --simpl-workdir, --simpl-env and --show-mode. The working
+// directory must exist and is made absolute.
+func (c *Config) SimplLaunch() (simplLaunch, error) {
+	launch := simplLaunch{Env: c.SimplEnv}
+
+	if err := validateShowMode(c.ShowMode); err != nil {
+		return simplLaunch{}, err
+	}
+
+	if c.ShowMode != showModeNormal {
+		launch.Show = c.ShowMode
+	}
+
+	for _, kv := range c.SimplEnv {
+		if key, _, ok := strings.Cut(kv, "="); !ok || key == "" {
+			return simplLaunch{}, fmt.Errorf("--simpl-env: invalid variable %q (expected KEY=VALUE)", kv)
+		}
+	}
+
+	// The other account gets its own profile's environment, which smpc cannot extend
+	if len(c.SimplEnv) > 0 && c.RunAs != "" {
+		return simplLaunch{}, fmt.Errorf("--simpl-env cannot be used with --runas")
+	}
+
+	if c.SimplWorkDir == "" {
+		return launch, nil
+	}
+
+	dir, err := filepath.Abs(c.SimplWorkDir)
+	if err != nil {
+		return simplLaunch{}, fmt.Errorf("--simpl-workdir: %w", err)
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return simplLaunch{}, fmt.Errorf("--simpl-workdir: %s is not a directory", dir)
+	}
+
+	launch.Dir = dir
+	return launch, nil
+}
+
+// validateShowMode returns an error if mode is not a supported --show-mode
+func validateShowMode(mode string) error {
+	switch mode {
+	case "", showModeNormal, showModeMinimized, showModeHidden, showModeOffscreen:
+		return nil
+	default:
+		return fmt.Errorf("unsupported --show-mode %q (supported: %s, %s, %s, %s)",
+			mode, showModeNormal, showModeMinimized, showModeHidden, showModeOffscreen)
+	}
+}
+
+// applySimplPath makes --simpl-path, as an absolute path, the SIMPL Windows
+// executable for the rest of the run
+func applySimplPath(cfg *Config) error {
+	if cfg.SimplPath == "" {
+		return nil
+	}
+
+	path, err := filepath.Abs(cfg.SimplPath)
+	if err != nil {
+		return fmt.Errorf("--simpl-path: %w", err)
+	}
+
+	simpl.SetSimplWindowsPath(path)
+	return nil
+}
+
+// launchArgs returns the --simpl-path, --simpl-workdir, --simpl-env, --show-mode,
+// --monitor-include and --monitor-exclude arguments for a child smpc
+func launchArgs(cfg *Config) []string {
+	var args []string
+
+	if cfg.SimplPath != "" {
+		args = append(args, "--simpl-path", cfg.SimplPath)
+	}
+
+	if cfg.SimplWorkDir != "" {
+		args = append(args, "--simpl-workdir", cfg.SimplWorkDir)
+	}
+
+	for _, kv := range cfg.SimplEnv {
+		args = append(args, "--simpl-env", kv)
+	}
+
+	if cfg.ShowMode != "" && cfg.ShowMode != showModeNormal {
+		args = append(args, "--show-mode", cfg.ShowMode)
+	}
+
+	for _, rule := range cfg.MonitorInclude {
+		args = append(args, "--monitor-include", rule)
+	}
+
+	for _, rule := range cfg.MonitorExclude {
+		args = append(args, "--monitor-exclude", rule)
+	}
+
+	return args
+}
+
+// background reports whether SIMPL Windows is kept out of the user's way, in which
+// case it is driven with window messages instead of focus and keystrokes
+func (l simplLaunch) background() bool {
+	return l.Show != ""
+}
+
+// showCommand returns how SIMPL Windows shows its first window. Out of the way, it
+// never takes focus; a hidden window starts minimized, because the main window is
+// only found once it is visible.
+func (l simplLaunch) showCommand() int {
+	switch l.Show {
+	case showModeMinimized, showModeHidden:
+		return windows.SW_SHOWMINNOACTIVE
+	case showModeOffscreen:
+		return windows.SW_SHOWNOACTIVATE
+	default:
+		return windows.SW_SHOWNORMAL
+	}
+}
+
+// place applies --show-mode to the main window once it is ready. SIMPL Windows can
+// restore its last position instead of honouring the show command, so minimizing is
+// repeated here too.
+func (l simplLaunch) place(hwnd uintptr, log logger.LoggerInterface) {
+	switch l.Show {
+	case showModeMinimized:
+		windows.ShowWindow(hwnd, windows.SW_SHOWMINNOACTIVE)
+	case showModeHidden:
+		windows.ShowWindow(hwnd, windows.SW_HIDE)
+	case showModeOffscreen:
+		if err := windows.MoveOffScreen(hwnd); err != nil {
+			log.Warn("Failed to move SIMPL Windows off-screen", slog.Any("error", err))
+			return
+		}
+	default:
+		return
+	}
+
+	log.Debug("Applied show mode to SIMPL Windows", slog.String("mode", l.Show), slog.Uint64("hwnd", uint64(hwnd)))
+}
+
+// withEnv sets the extra variables while start launches SIMPL Windows, which
+// inherits them, then restores smpc's own environment
+func (l simplLaunch) withEnv(start func() error) error {
+	type saved struct {
+		key, value string
+		set        bool
+	}
+
+	restore := make([]saved, 0, len(l.Env))
+
+	defer func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			if s := restore[i]; s.set {
+				_ = os.Setenv(s.key, s.value)
+			} else {
+				_ = os.Unsetenv(s.key)
+			}
+		}
+	}()
+
+	for _, kv := range l.Env {
+		key, value, _ := strings.Cut(kv, "=")
+
+		old, set := os.LookupEnv(key)
+		restore = append(restore, saved{key, old, set})
+
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("--simpl-env: %w", err)
+		}
+	}
+
+	return start()
+}
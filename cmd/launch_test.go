@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+func TestConfig_SimplLaunch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	launch, err := (&Config{SimplWorkDir: dir, SimplEnv: []string{"CRESTRON_LIB=C:\\lib", "EMPTY="}}).SimplLaunch()
+	require.NoError(t, err)
+	assert.Equal(t, simplLaunch{Dir: dir, Env: []string{"CRESTRON_LIB=C:\\lib", "EMPTY="}}, launch)
+
+	launch, err = (&Config{ShowMode: showModeOffscreen}).SimplLaunch()
+	require.NoError(t, err)
+	assert.True(t, launch.background())
+	assert.Equal(t, windows.SW_SHOWNOACTIVATE, launch.showCommand())
+
+	launch, err = (&Config{ShowMode: showModeNormal}).SimplLaunch()
+	require.NoError(t, err)
+	assert.False(t, launch.background())
+	assert.Equal(t, windows.SW_SHOWNORMAL, launch.showCommand())
+
+	for name, cfg := range map[string]*Config{
+		"missing dir":    {SimplWorkDir: filepath.Join(dir, "missing")},
+		"no equals":      {SimplEnv: []string{"CRESTRON_LIB"}},
+		"no key":         {SimplEnv: []string{"=value"}},
+		"env with runas": {SimplEnv: []string{"A=1"}, RunAs: `CORP\builder`},
+		"show mode":      {ShowMode: "maximized"},
+	} {
+		_, err := cfg.SimplLaunch()
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLaunchArgs(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, launchArgs(&Config{}))
+	assert.Equal(t,
+		[]string{"--simpl-path", `E:\Simpl\smpwin.exe`, "--simpl-workdir", `C:\jobs`, "--simpl-env", "A=1", "--simpl-env", "B=2", "--show-mode", "hidden"},
+		launchArgs(&Config{SimplPath: `E:\Simpl\smpwin.exe`, SimplWorkDir: `C:\jobs`, SimplEnv: []string{"A=1", "B=2"}, ShowMode: "hidden"}),
+	)
+	assert.Equal(t,
+		[]string{"--monitor-include", "class=#32770", "--monitor-exclude", "title=^Inbox"},
+		launchArgs(&Config{MonitorInclude: []string{"class=#32770"}, MonitorExclude: []string{"title=^Inbox"}}),
+	)
+}
+
+// Not parallel: changes the process environment
+func TestSimplLaunch_WithEnv(t *testing.T) {
+	t.Setenv("SMPC_TEST_SET", "before")
+	require.NoError(t, os.Unsetenv("SMPC_TEST_UNSET"))
+
+	launch := simplLaunch{Env: []string{"SMPC_TEST_SET=during", "SMPC_TEST_UNSET=during"}}
+
+	err := launch.withEnv(func() error {
+		assert.Equal(t, "during", os.Getenv("SMPC_TEST_SET"))
+		assert.Equal(t, "during", os.Getenv("SMPC_TEST_UNSET"))
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "before", os.Getenv("SMPC_TEST_SET"))
+	_, set := os.LookupEnv("SMPC_TEST_UNSET")
+	assert.False(t, set)
+}
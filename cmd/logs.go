@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+// logsCmd groups log file maintenance actions
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Manage smpc log files",
+	Args:  cobra.NoArgs,
+}
+
+// logsPruneCmd deletes rotated log files on demand
+var logsPruneCmd = &cobra.Command{
+	Use:          "prune",
+	Short:        "Delete rotated log files, keeping the current log",
+	Args:         cobra.NoArgs,
+	RunE:         runLogsPrune,
+	SilenceUsage: true,
+}
+
+func init() {
+	logsPruneCmd.Flags().Int("keep", 0, "number of most recent rotated log files to keep")
+
+	logsCmd.AddCommand(logsPruneCmd)
+	RootCmd.AddCommand(logsCmd)
+}
+
+// runLogsPrune removes rotated logs from the log directory
+func runLogsPrune(cmd *cobra.Command, _ []string) error {
+	keep, _ := cmd.Flags().GetInt("keep")
+	opts := logger.LoggerOptions{}
+
+	removed, err := logger.PruneRotatedLogs(opts, keep)
+	for _, f := range removed {
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", f)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Pruned %d rotated log file(s) from %s\n",
+		len(removed), filepath.Dir(logger.GetLogPath(opts)))
+
+	return nil
+}
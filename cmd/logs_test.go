@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLogsPrune removes rotated logs but keeps the active log and --keep newest backups
+func TestLogsPrune(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("LOCALAPPDATA", tmpDir)
+
+	logDir := filepath.Join(tmpDir, "smpc")
+	require.NoError(t, os.MkdirAll(logDir, 0o755))
+
+	for _, name := range []string{
+		"smpc.log",
+		"smpc-2025-01-01T10-00-00.000.log.gz",
+		"smpc-2025-01-02T10-00-00.000.log.gz",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(logDir, name), []byte("log"), 0o644))
+	}
+
+	var out bytes.Buffer
+	RootCmd.SetOut(&out)
+	RootCmd.SetArgs([]string{"logs", "prune", "--keep", "1"})
+	t.Cleanup(func() {
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs(nil)
+		_ = logsPruneCmd.Flags().Set("keep", "0")
+	})
+
+	require.NoError(t, RootCmd.Execute())
+
+	assert.Contains(t, out.String(), "Pruned 1 rotated log file(s)")
+	assert.FileExists(t, filepath.Join(logDir, "smpc.log"))
+	assert.FileExists(t, filepath.Join(logDir, "smpc-2025-01-02T10-00-00.000.log.gz"))
+	assert.NoFileExists(t, filepath.Join(logDir, "smpc-2025-01-01T10-00-00.000.log.gz"))
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"log/slog"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// exitCodeMaxRuntime is the exit code when --max-runtime expires, as GNU timeout uses
+const exitCodeMaxRuntime = 124
+
+// maxRuntime enforces --max-runtime: when the limit expires it runs the registered
+// cleanups, which force-close whatever the run has started, and exits
+type maxRuntime struct {
+	limit    time.Duration
+	log      logger.LoggerInterface
+	exitFunc func(int)
+	timer    *time.Timer // nil when there is no limit
+
+	mu       sync.Mutex
+	nextID   int
+	cleanups map[int]func()
+}
+
+// startMaxRuntime starts the clock on the run. A limit of 0 never expires.
+func startMaxRuntime(limit time.Duration, log logger.LoggerInterface, exitFunc func(int)) *maxRuntime {
+	m := &maxRuntime{limit: limit, log: log, exitFunc: exitFunc, cleanups: make(map[int]func())}
+
+	if limit > 0 {
+		m.timer = time.AfterFunc(limit, m.expire)
+	}
+
+	return m
+}
+
+// onExpiry registers cleanup to run if the limit expires, until remove is called
+func (m *maxRuntime) onExpiry(cleanup func()) (remove func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	id := m.nextID
+	m.cleanups[id] = cleanup
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		delete(m.cleanups, id)
+	}
+}
+
+// stop cancels the limit once the run has finished
+func (m *maxRuntime) stop() {
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+}
+
+func (m *maxRuntime) expire() {
+	m.log.Error("Maximum runtime exceeded, force-closing everything", slog.Duration("limit", m.limit))
+
+	m.mu.Lock()
+	cleanups := make([]func(), 0, len(m.cleanups))
+	for _, cleanup := range m.cleanups {
+		cleanups = append(cleanups, cleanup)
+	}
+	m.mu.Unlock()
+
+	for _, cleanup := range cleanups {
+		cleanup()
+	}
+
+	m.exitFunc(exitCodeMaxRuntime)
+}
+
+// runChild runs a child smpc, terminating it and the SIMPL Windows it started if the
+// limit expires first
+func (m *maxRuntime) runChild(child *exec.Cmd) error {
+	if err := child.Start(); err != nil {
+		return err
+	}
+
+	remove := m.onExpiry(func() {
+		if _, err := windows.TerminateProcessTree(uint32(child.Process.Pid)); err != nil {
+			m.log.Warn("Failed to terminate child smpc", slog.Int("pid", child.Process.Pid), slog.Any("error", err))
+		}
+	})
+	defer remove()
+
+	return child.Wait()
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+func TestMaxRuntime_ExpiryRunsCleanupsAndExits(t *testing.T) {
+	t.Parallel()
+
+	exited := make(chan int, 1)
+	m := startMaxRuntime(time.Hour, logger.NewNoOpLogger(), func(code int) { exited <- code })
+	m.stop()
+
+	var cleaned, removed bool
+	m.onExpiry(func() { cleaned = true })
+	m.onExpiry(func() { removed = true })()
+
+	m.expire()
+
+	assert.Equal(t, exitCodeMaxRuntime, <-exited)
+	assert.True(t, cleaned)
+	assert.False(t, removed, "removed cleanups should not run")
+}
+
+func TestMaxRuntime_NoLimitNeverExpires(t *testing.T) {
+	t.Parallel()
+
+	m := startMaxRuntime(0, logger.NewNoOpLogger(), func(int) { t.Fatal("should not exit") })
+	defer m.stop()
+
+	assert.Nil(t, m.timer)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/compiler"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+)
+
+// probeNativeCompile returns the installed SIMPL Windows' native compile capabilities when
+// --prefer-native is set, reporting whether a non-GUI compile path is available.
+func probeNativeCompile(cfg *Config, log logger.LoggerInterface) (simpl.NativeCapabilities, bool) {
+	if !cfg.PreferNative {
+		return simpl.NativeCapabilities{}, false
+	}
+
+	caps, err := simpl.ProbeNativeCapabilities(simpl.GetSimplWindowsPath())
+	if err != nil {
+		log.Warn("Could not probe SIMPL Windows for native compile support, using GUI automation", slog.Any("error", err))
+		return caps, false
+	}
+
+	if !caps.CommandLine() {
+		log.Info("No native compile support in this SIMPL Windows version, using GUI automation",
+			slog.String("version", caps.Version))
+		return caps, false
+	}
+
+	log.Info("Using native command-line compile", slog.String("version", caps.Version))
+	return caps, true
+}
+
+// runNativeCompilation compiles absPath by running smpwin.exe with its command-line
+// compile switches and parsing what it prints, without any GUI automation.
+func runNativeCompilation(
+	absPath string,
+	caps simpl.NativeCapabilities,
+	cfg *Config,
+	timeout time.Duration,
+	log logger.LoggerInterface,
+) (*compiler.CompileResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	args := caps.CompileArgs(absPath, cfg.RecompileAll)
+	log.Debug("Running native compile", slog.String("path", simpl.GetSimplWindowsPath()), slog.Any("args", args))
+
+	output, runErr := exec.CommandContext(ctx, simpl.GetSimplWindowsPath(), args...).CombinedOutput()
+	result := compiler.ParseCompilerOutput(string(output))
+
+	if cfg.WarningsAsErrors && result.Warnings > 0 {
+		log.Info("Treating warnings as errors", slog.Int("warnings", result.Warnings))
+		result.PromoteWarnings()
+	}
+
+	if runErr != nil && !result.HasErrors {
+		// The process failed without reporting compiler errors - surface the failure itself
+		err := fmt.Errorf("native compile failed: %w", runErr)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("native compile timed out after %s: %w", timeout, compiler.ErrCompileTimeout)
+		}
+
+		result.Errors = 1
+		result.HasErrors = true
+		result.ErrorMessages = append(result.ErrorMessages, err.Error())
+
+		return result, err
+	}
+
+	if result.HasErrors {
+		return result, compiler.ErrCompileErrors{Count: result.Errors}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+func TestProbeNativeCompile_Disabled(t *testing.T) {
+	t.Parallel()
+
+	_, ok := probeNativeCompile(&Config{}, logger.NewNoOpLogger())
+	assert.False(t, ok, "GUI automation is used unless --prefer-native is set")
+}
+
+func TestProbeNativeCompile_UnreadableExecutable(t *testing.T) {
+	// Cannot use t.Parallel() - modifies environment variables
+	t.Setenv("SIMPL_WINDOWS_PATH", t.TempDir()+`\missing\smpwin.exe`)
+
+	_, ok := probeNativeCompile(&Config{PreferNative: true}, logger.NewNoOpLogger())
+	assert.False(t, ok, "falls back to GUI automation when the version cannot be read")
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/notify"
+	"github.com/Norgate-AV/smpc/internal/redact"
+	"github.com/Norgate-AV/smpc/internal/report"
+)
+
+// sendNotifications shows a toast when --notify is set and emails the results when
+// --notify-email is set. Like reports, notification failures are logged but never
+// change the exit status.
+func sendNotifications(cfg *Config, email *notify.Email, results []report.FileResult, redactor *redact.Redactor, log logger.LoggerInterface) {
+	if cfg.Notify {
+		// Shown only on this desktop, so the paths are not redacted
+		if err := notify.Toast(results); err != nil {
+			log.Warn("Failed to show toast notification", slog.Any("error", err))
+		}
+	}
+
+	if email == nil {
+		return
+	}
+
+	if err := email.Send(redactResults(results, redactor), os.Getenv); err != nil {
+		log.Error("Failed to send email notification", slog.Any("error", err))
+		return
+	}
+
+	log.Info("Email notification sent", slog.Any("to", email.To))
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/artifacts"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/workspace"
+)
+
+// resolveOutputDir returns the directory --output-dir and --output-name send this run's
+// outputs to, or "" when --output-dir is not set
+func resolveOutputDir(cfg *Config, programPath string, now time.Time) (string, error) {
+	if cfg.OutputDir == "" {
+		return "", nil
+	}
+
+	name, err := artifacts.RenderName(cfg.OutputName, artifacts.Vars{
+		Program: strings.TrimSuffix(filepath.Base(programPath), filepath.Ext(programPath)),
+		Version: cfg.OutputVersion,
+		Time:    now,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := filepath.Abs(filepath.Join(cfg.OutputDir, name))
+	if err != nil {
+		return "", fmt.Errorf("error resolving output directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// watchOutputs records the compile directory when outputDir is set. finish copies the
+// files the compile created or changed there into outputDir if it succeeded.
+func watchOutputs(outputDir, compilePath string, log logger.LoggerInterface) (finish func(success bool), err error) {
+	if outputDir == "" {
+		return func(bool) {}, nil
+	}
+
+	snapshot, err := workspace.TakeSnapshot(filepath.Dir(compilePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record program directory for --output-dir: %w", err)
+	}
+
+	return func(success bool) {
+		if !success {
+			log.Debug("Compilation did not succeed, not collecting outputs")
+			return
+		}
+
+		changed, err := snapshot.Changed()
+		if err != nil {
+			log.Error("Failed to find compile outputs", slog.Any("error", err))
+			return
+		}
+
+		copied, err := artifacts.Collect(snapshot.Dir, changed, outputDir)
+		if err != nil {
+			log.Error("Failed to copy compile outputs", slog.String("dir", outputDir), slog.Any("error", err))
+		}
+
+		log.Info("Collected compile outputs", slog.String("dir", outputDir), slog.Int("count", len(copied)))
+
+		for _, path := range copied {
+			log.Debug("Collected output", slog.String("path", path))
+		}
+	}, nil
+}
+
+// watchTempFiles records the compile directory unless patterns is empty (--keep-temp).
+// finish removes the files the compile created or changed there whose names match
+// patterns and reports each one; files that were already there are never removed.
+func watchTempFiles(patterns []string, compilePath string, log logger.LoggerInterface) (finish func(), err error) {
+	if len(patterns) == 0 {
+		return func() {}, nil
+	}
+
+	snapshot, err := workspace.TakeSnapshot(filepath.Dir(compilePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record program directory for temp file cleanup: %w", err)
+	}
+
+	return func() {
+		changed, err := snapshot.Changed()
+		if err != nil {
+			log.Error("Failed to find temporary files", slog.Any("error", err))
+			return
+		}
+
+		removed, err := artifacts.RemoveTemp(changed, patterns)
+		if err != nil {
+			log.Warn("Failed to remove temporary files", slog.Any("error", err))
+		}
+
+		for _, path := range removed {
+			log.Info("Removed temporary file", slog.String("path", path))
+		}
+
+		log.Debug("Temporary file cleanup finished", slog.Int("removed", len(removed)))
+	}, nil
+}
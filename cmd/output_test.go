@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+func TestResolveOutputDir(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 9, 14, 5, 7, 0, time.UTC)
+	out := t.TempDir()
+
+	dir, err := resolveOutputDir(&Config{}, `C:\p\Lobby.smw`, now)
+	require.NoError(t, err)
+	assert.Empty(t, dir, "disabled without --output-dir")
+
+	dir, err = resolveOutputDir(&Config{OutputDir: out, OutputName: "{program}-{version}-{timestamp}", OutputVersion: "42"}, "Lobby.smw", now)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(out, "Lobby-42-20260309-140507"), dir)
+
+	_, err = resolveOutputDir(&Config{OutputDir: out, OutputName: "{program}-{version}"}, "Lobby.smw", now)
+	assert.ErrorContains(t, err, "no version")
+}
+
+func TestWatchOutputs_CopiesChangedFilesOnSuccess(t *testing.T) {
+	t.Parallel()
+
+	src := t.TempDir()
+	program := filepath.Join(src, "Lobby.smw")
+	require.NoError(t, os.WriteFile(program, []byte("program"), 0o644))
+
+	out := filepath.Join(t.TempDir(), "Lobby")
+
+	finish, err := watchOutputs(out, program, logger.NewNoOpLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "SPlsWork"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "Lobby.lpz"), []byte("lpz"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "SPlsWork", "Helper.dll"), []byte("dll"), 0o644))
+
+	finish(true)
+
+	assert.FileExists(t, filepath.Join(out, "Lobby.lpz"))
+	assert.FileExists(t, filepath.Join(out, "SPlsWork", "Helper.dll"))
+	assert.NoFileExists(t, filepath.Join(out, "Lobby.smw"), "unchanged sources are not outputs")
+
+	failed := filepath.Join(t.TempDir(), "failed")
+
+	finish, err = watchOutputs(failed, program, logger.NewNoOpLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(src, "Lobby.sig"), []byte("sig"), 0o644))
+	finish(false)
+
+	assert.NoDirExists(t, failed)
+}
+
+func TestWatchTempFiles_RemovesOnlyNewScratchFiles(t *testing.T) {
+	t.Parallel()
+
+	src := t.TempDir()
+	program := filepath.Join(src, "Lobby.smw")
+	existing := filepath.Join(src, "notes.tmp")
+	require.NoError(t, os.WriteFile(program, []byte("program"), 0o644))
+	require.NoError(t, os.WriteFile(existing, []byte("mine"), 0o644))
+
+	finish, err := watchTempFiles([]string{"*.tmp"}, program, logger.NewNoOpLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(src, "Lobby.lpz"), []byte("lpz"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "Lobby.tmp"), []byte("scratch"), 0o644))
+
+	finish()
+
+	assert.NoFileExists(t, filepath.Join(src, "Lobby.tmp"))
+	assert.FileExists(t, filepath.Join(src, "Lobby.lpz"))
+	assert.FileExists(t, existing, "files that were already there are kept")
+}
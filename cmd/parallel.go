@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Norgate-AV/smpc/internal/compiler"
+	"github.com/Norgate-AV/smpc/internal/report"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+)
+
+// validateParallelSimplPaths checks --parallel-simpl-path: it only applies to project
+// directories, and each installation must exist and be used only once
+func validateParallelSimplPaths(cfg *Config, target string) error {
+	if len(cfg.ParallelSimplPaths) == 0 {
+		return nil
+	}
+
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return fmt.Errorf("--parallel-simpl-path only applies to project directories")
+	}
+
+	// Two instances cannot both answer as the one SIMPL Windows DDE service
+	if cfg.Backend == compiler.BackendDDE {
+		return fmt.Errorf("--parallel-simpl-path cannot be used with --backend %s", compiler.BackendDDE)
+	}
+
+	seen := map[string]bool{strings.ToLower(simpl.GetSimplWindowsPath()): true}
+
+	for _, p := range cfg.ParallelSimplPaths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return fmt.Errorf("--parallel-simpl-path: %w", err)
+		}
+
+		if info, err := os.Stat(abs); err != nil || info.IsDir() {
+			return fmt.Errorf("--parallel-simpl-path %s: SIMPL Windows executable not found", p)
+		}
+
+		key := strings.ToLower(abs)
+		if seen[key] {
+			return fmt.Errorf("--parallel-simpl-path %s: installation is already in use", p)
+		}
+
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// simplInstallations returns the configuration each concurrent compile of a project
+// uses: the primary installation first, then one per --parallel-simpl-path. With
+// more than one, SIMPL Windows is kept off-screen unless --show-mode says otherwise,
+// so the instances never take focus, or keystrokes, from one another.
+func simplInstallations(cfg *Config) []*Config {
+	installs := []*Config{cfg}
+	if len(cfg.ParallelSimplPaths) == 0 {
+		return installs
+	}
+
+	isolated := *cfg
+	if isolated.ShowMode == "" || isolated.ShowMode == showModeNormal {
+		isolated.ShowMode = showModeOffscreen
+	}
+
+	// Named explicitly, so it can be told apart from the others in the log
+	primary := isolated
+	primary.SimplPath = simpl.GetSimplWindowsPath()
+	installs[0] = &primary
+
+	for _, p := range cfg.ParallelSimplPaths {
+		worker := isolated
+		worker.SimplPath = p
+		installs = append(installs, &worker)
+	}
+
+	return installs
+}
+
+// compileConcurrently compiles programs, none of which depend on another, with up to
+// workers at a time. compile is told which worker runs it, so each worker can use its
+// own SIMPL Windows installation. Results are in the order of programs.
+func compileConcurrently(programs []string, workers int, compile func(worker int, program string) report.FileResult) []report.FileResult {
+	results := make([]report.FileResult, len(programs))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := range min(workers, len(programs)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range next {
+				results[i] = compile(w, programs[i])
+			}
+		}()
+	}
+
+	for i := range programs {
+		next <- i
+	}
+
+	close(next)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/compiler"
+	"github.com/Norgate-AV/smpc/internal/report"
+)
+
+func TestCompileConcurrently(t *testing.T) {
+	t.Parallel()
+
+	programs := []string{"a.smw", "b.smw", "c.smw", "d.smw", "e.smw"}
+
+	var (
+		mu      sync.Mutex
+		running int
+		peak    int
+		workers = map[int]bool{}
+	)
+
+	results := compileConcurrently(programs, 2, func(worker int, program string) report.FileResult {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		workers[worker] = true
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		return report.FileResult{File: program, Status: report.StatusPassed}
+	})
+
+	require.Len(t, results, len(programs))
+	for i, r := range results {
+		assert.Equal(t, programs[i], r.File, "results keep the order of the programs")
+	}
+
+	assert.LessOrEqual(t, peak, 2)
+	assert.Subset(t, []int{0, 1}, slices.Collect(maps.Keys(workers)))
+}
+
+func TestCompileConcurrently_Empty(t *testing.T) {
+	t.Parallel()
+
+	results := compileConcurrently(nil, 2, func(int, string) report.FileResult {
+		t.Fatal("nothing to compile")
+		return report.FileResult{}
+	})
+
+	assert.Empty(t, results)
+}
+
+func TestSimplInstallations(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{ShowMode: showModeNormal}
+	assert.Equal(t, []*Config{cfg}, simplInstallations(cfg), "a single installation is used as configured")
+
+	cfg = &Config{ShowMode: showModeNormal, ParallelSimplPaths: []string{`D:\Simpl 4.1\smpwin.exe`}}
+	installs := simplInstallations(cfg)
+
+	require.Len(t, installs, 2)
+	assert.NotEmpty(t, installs[0].SimplPath)
+	assert.Equal(t, `D:\Simpl 4.1\smpwin.exe`, installs[1].SimplPath)
+
+	for _, c := range installs {
+		assert.Equal(t, showModeOffscreen, c.ShowMode, "concurrent instances never take focus")
+	}
+
+	cfg = &Config{ShowMode: showModeHidden, ParallelSimplPaths: []string{`D:\Simpl 4.1\smpwin.exe`}}
+	assert.Equal(t, showModeHidden, simplInstallations(cfg)[1].ShowMode)
+}
+
+func TestValidateParallelSimplPaths(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	assert.NoError(t, validateParallelSimplPaths(&Config{}, "program.smw"))
+
+	err := validateParallelSimplPaths(&Config{ParallelSimplPaths: []string{"parallel.go"}}, "parallel.go")
+	assert.ErrorContains(t, err, "only applies to project directories")
+
+	err = validateParallelSimplPaths(&Config{Backend: compiler.BackendDDE, ParallelSimplPaths: []string{"parallel.go"}}, dir)
+	assert.ErrorContains(t, err, "--backend dde")
+
+	err = validateParallelSimplPaths(&Config{ParallelSimplPaths: []string{"missing.exe"}}, dir)
+	assert.ErrorContains(t, err, "not found")
+
+	err = validateParallelSimplPaths(&Config{ParallelSimplPaths: []string{"parallel.go", "PARALLEL.GO"}}, dir)
+	assert.ErrorContains(t, err, "already in use")
+
+	assert.NoError(t, validateParallelSimplPaths(&Config{ParallelSimplPaths: []string{"parallel.go"}}, dir))
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Norgate-AV/smpc/internal/smw"
+	"github.com/Norgate-AV/smpc/internal/validate"
+)
+
+// precheckCmd runs the quick checks that would stop a compile, without SIMPL Windows
+var precheckCmd = &cobra.Command{
+	Use:   "precheck <file.smw>...",
+	Short: "Quickly check programs for problems that would stop a compile",
+	Long: `Check programs without compiling them or starting SIMPL Windows:
+
+  - the file opens and is a well-formed SIMPL Windows program
+  - no symbols are incomplete (which would show the Incomplete Symbols dialog)
+  - every user module (.umc) and SIMPL+ module (.usp) it uses can be found
+
+Modules are looked up in the program's directory, then each --module-dir, then the
+SIMPL Windows user module libraries under Documents\Crestron\SIMPL. The checks only
+read files, so they suit a git pre-commit hook.`,
+	Example: `  smpc precheck Lobby.smw
+  smpc precheck --module-dir ..\modules Lobby.smw Boardroom.smw`,
+	Args:         cobra.MinimumNArgs(1),
+	RunE:         runPrecheck,
+	SilenceUsage: true,
+}
+
+func init() {
+	precheckCmd.Flags().StringArray("module-dir", nil, "additional directory to search for modules; repeatable")
+
+	RootCmd.AddCommand(precheckCmd)
+}
+
+// moduleLibraries are SIMPL Windows' default user module folders, relative to the
+// user profile
+var moduleLibraries = []string{
+	`Documents\Crestron\SIMPL\Usrmacro`,
+	`Documents\Crestron\SIMPL\UserSPls`,
+}
+
+// moduleSearchDirs returns where SIMPL Windows would look for a program's modules
+func moduleSearchDirs(programDir string, extra []string, getenv func(string) string) []string {
+	dirs := append([]string{programDir}, extra...)
+
+	if profile := getenv("USERPROFILE"); profile != "" {
+		for _, lib := range moduleLibraries {
+			dirs = append(dirs, filepath.Join(profile, lib))
+		}
+	}
+
+	return dirs
+}
+
+func runPrecheck(cmd *cobra.Command, args []string) error {
+	extra, _ := cmd.Flags().GetStringArray("module-dir")
+
+	start := time.Now()
+	problems := 0
+
+	for _, path := range args {
+		problems += precheckProgram(cmd.OutOrStdout(), path, extra, os.Getenv)
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("precheck found %d problem(s)", problems)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Precheck passed for %d program(s) in %s\n", len(args), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// precheckProgram prints each problem found in the program at path and returns how
+// many there were
+func precheckProgram(w io.Writer, path string, extraDirs []string, getenv func(string) string) int {
+	name := filepath.Base(path)
+
+	if err := checkProgramFile(path); err != nil {
+		fmt.Fprintf(w, "%s: %v\n", name, err)
+		return 1
+	}
+
+	program, err := smw.Open(path)
+	if errors.Is(err, smw.ErrNotProgram) {
+		fmt.Fprintf(w, "%s: %v\n", name, err)
+		return 1
+	}
+
+	if err != nil {
+		fmt.Fprintf(w, "%s: cannot read program: %v\n", name, err)
+		return 1
+	}
+
+	diagnostics := validate.Static(program, moduleSearchDirs(filepath.Dir(path), extraDirs, getenv))
+	_ = validate.WriteText(w, []validate.Result{validate.NewResult(path, diagnostics)})
+
+	return len(diagnostics)
+}
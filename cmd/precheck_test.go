@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "../test/integration/fixtures"
+
+func TestModuleSearchDirs(t *testing.T) {
+	t.Parallel()
+
+	getenv := func(string) string { return `C:\Users\tech` }
+
+	assert.Equal(t, []string{
+		`C:\jobs`,
+		`C:\modules`,
+		filepath.Join(`C:\Users\tech`, `Documents\Crestron\SIMPL\Usrmacro`),
+		filepath.Join(`C:\Users\tech`, `Documents\Crestron\SIMPL\UserSPls`),
+	}, moduleSearchDirs(`C:\jobs`, []string{`C:\modules`}, getenv))
+}
+
+func TestPrecheckProgram(t *testing.T) {
+	t.Parallel()
+
+	noProfile := func(string) string { return "" }
+
+	var out bytes.Buffer
+	assert.Zero(t, precheckProgram(&out, filepath.Join(fixtureDir, "simple.smw"), nil, noProfile))
+	assert.Empty(t, out.String())
+
+	// The SIMPL+ module sits beside the fixture
+	assert.Zero(t, precheckProgram(&out, filepath.Join(fixtureDir, "error.smw"), nil, noProfile))
+
+	assert.Equal(t, 1, precheckProgram(&out, filepath.Join(fixtureDir, "incomplete.smw"), nil, noProfile))
+	assert.Contains(t, out.String(), `incomplete.smw: incomplete symbol "Packet Transmission"`)
+}
+
+func TestPrecheckProgram_MissingModuleAndInvalidFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	program, err := os.ReadFile(filepath.Join(fixtureDir, "error.smw"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "copy.smw"), program, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.smw"), []byte("not a program"), 0o644))
+
+	noProfile := func(string) string { return "" }
+
+	var out bytes.Buffer
+	assert.Equal(t, 1, precheckProgram(&out, filepath.Join(dir, "copy.smw"), nil, noProfile))
+	assert.Contains(t, out.String(), "copy.smw: module error.usp not found")
+
+	// Found once its directory is searched
+	out.Reset()
+	assert.Zero(t, precheckProgram(&out, filepath.Join(dir, "copy.smw"), []string{fixtureDir}, noProfile))
+
+	assert.Equal(t, 1, precheckProgram(&out, filepath.Join(dir, "notes.smw"), nil, noProfile))
+	assert.Contains(t, out.String(), "notes.smw: not a SIMPL Windows program")
+
+	assert.Equal(t, 1, precheckProgram(&out, filepath.Join(dir, "missing.smw"), nil, noProfile))
+	assert.Contains(t, out.String(), "missing.smw: cannot read program")
+}
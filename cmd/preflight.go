@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// minFreeSpace is the free space needed on each drive a compile writes to. SIMPL
+// Windows' intermediate files can run to hundreds of megabytes for a large program.
+const minFreeSpace = 512 << 20
+
+// preflightChecks are the checks made before SIMPL Windows is launched; replaced in tests
+type preflightChecks struct {
+	freeSpace func(dir string) (uint64, error)
+	canWrite  func(dir string) error
+}
+
+var defaultPreflightChecks = preflightChecks{
+	freeSpace: windows.DiskFreeSpace,
+	canWrite:  checkWritable,
+}
+
+// preflight fails early when the compile cannot write its outputs: too little free
+// space on the drive holding the program or the temp directory, or no permission to
+// write in the program folder. SIMPL Windows would otherwise stop mid-compile with an
+// unhelpful dialog. The program folder is not written to in --sandbox mode.
+func preflight(absPath string, sandbox bool, checks preflightChecks, log logger.LoggerInterface) error {
+	programDir := filepath.Dir(absPath)
+
+	if !sandbox {
+		if err := checks.canWrite(programDir); err != nil {
+			return fmt.Errorf("cannot write to the program folder %s, where the compile outputs go: %w; "+
+				"check that you have write permission and that it is not read-only, or use --sandbox", programDir, err)
+		}
+
+		if err := checkFreeSpace(checks, programDir, "program folder"); err != nil {
+			return err
+		}
+	}
+
+	if err := checkFreeSpace(checks, os.TempDir(), "temp directory"); err != nil {
+		return err
+	}
+
+	log.Debug("Preflight checks passed", slog.String("dir", programDir))
+	return nil
+}
+
+func checkFreeSpace(checks preflightChecks, dir, what string) error {
+	free, err := checks.freeSpace(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check free space for the %s: %w", what, err)
+	}
+
+	if free < minFreeSpace {
+		return fmt.Errorf("only %d MB free on the drive holding the %s %s; at least %d MB is needed to compile, free up some space and try again",
+			free>>20, what, dir, minFreeSpace>>20)
+	}
+
+	return nil
+}
+
+// checkWritable creates and removes a file in dir
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".smpc-preflight-*")
+	if err != nil {
+		return err
+	}
+
+	name := f.Name()
+	_ = f.Close()
+
+	return os.Remove(name)
+}
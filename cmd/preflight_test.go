@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+func TestPreflight(t *testing.T) {
+	t.Parallel()
+
+	program := filepath.Join(t.TempDir(), "program.smw")
+	plenty := func(string) (uint64, error) { return minFreeSpace, nil }
+
+	t.Run("passes with space and permission", func(t *testing.T) {
+		t.Parallel()
+
+		checks := preflightChecks{freeSpace: plenty, canWrite: checkWritable}
+		require.NoError(t, preflight(program, false, checks, logger.NewNoOpLogger()))
+	})
+
+	t.Run("fails when the program folder is read-only", func(t *testing.T) {
+		t.Parallel()
+
+		checks := preflightChecks{freeSpace: plenty, canWrite: func(string) error { return errors.New("access is denied") }}
+		err := preflight(program, false, checks, logger.NewNoOpLogger())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot write to the program folder")
+		assert.Contains(t, err.Error(), "access is denied")
+	})
+
+	t.Run("sandbox does not need the program folder", func(t *testing.T) {
+		t.Parallel()
+
+		checks := preflightChecks{freeSpace: plenty, canWrite: func(string) error { return errors.New("access is denied") }}
+		require.NoError(t, preflight(program, true, checks, logger.NewNoOpLogger()))
+	})
+
+	t.Run("fails when a drive is nearly full", func(t *testing.T) {
+		t.Parallel()
+
+		checks := preflightChecks{
+			freeSpace: func(string) (uint64, error) { return 100 << 20, nil },
+			canWrite:  checkWritable,
+		}
+		err := preflight(program, false, checks, logger.NewNoOpLogger())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only 100 MB free")
+	})
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/buildorder"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/report"
+)
+
+// projectArgs returns the smpc arguments for compiling one program of a project, or
+// one compile service job, with the settings in cfg rather than the config file
+func projectArgs(cfg *Config, program string) []string {
+	args := []string{"--" + noConfigFlag, "--backend", cfg.Backend}
+
+	switches := []struct {
+		flag string
+		set  bool
+	}{
+		{"--recompile-all", cfg.RecompileAll},
+		{"--auto-recompile-all", cfg.AutoRecompileAll},
+		{"--save-first", cfg.SaveFirst},
+		{"--prefer-native", cfg.PreferNative},
+		{"--no-history", cfg.NoHistory},
+		{"--keep-temp", cfg.KeepTemp},
+	}
+
+	for _, sw := range switches {
+		if sw.set {
+			args = append(args, sw.flag)
+		}
+	}
+
+	if cfg.CompileKey != "" {
+		args = append(args, "--compile-key", cfg.CompileKey)
+	}
+
+	if cfg.RecompileKey != "" {
+		args = append(args, "--recompile-key", cfg.RecompileKey)
+	}
+
+	if cfg.CrashRetries > 0 {
+		args = append(args, "--crash-retries", strconv.Itoa(cfg.CrashRetries))
+	}
+
+	if cfg.UpdatePrompts != "" && cfg.UpdatePrompts != updatePromptsDismiss {
+		args = append(args, "--update-prompts", cfg.UpdatePrompts)
+	}
+
+	// The children's output is interleaved with ours, so it is stamped the same way
+	if cfg.Timestamps != "" {
+		args = append(args, "--timestamps="+cfg.Timestamps)
+	}
+
+	for _, pattern := range cfg.TempPatterns {
+		args = append(args, "--temp-pattern", pattern)
+	}
+
+	args = append(args, launchArgs(cfg)...)
+
+	return append(args, program)
+}
+
+// buildProject compiles the programs under dir in dependency order and returns a
+// result per program, or with --graph only prints the order. Modules are listed so
+// the order can be checked, but SIMPL Windows compiles them as part of each program
+// that uses them. Each program is compiled by a child smpc process; with
+// --parallel-simpl-path, programs of the same level run concurrently, one per
+// SIMPL Windows installation.
+func buildProject(cfg *Config, dir string, deadline *maxRuntime, log logger.LoggerInterface) ([]report.FileResult, error) {
+	nodes, err := buildorder.Scan(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	levels, err := buildorder.Order(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Graph {
+		return nil, buildorder.WriteGraph(consoleOutput(cfg), dir, levels)
+	}
+
+	// Elevate once here so the programs do not each prompt
+	if err := ensureElevated(log); err != nil {
+		return nil, err
+	}
+
+	// Held for the whole build and shared with each program's smpc, so no other
+	// instance compiles in between
+	release, err := acquireGUILock(cfg, dir, log)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	installs := simplInstallations(cfg)
+	if len(installs) > 1 {
+		log.Warn("Compiling programs concurrently is experimental", slog.Int("installations", len(installs)))
+	}
+
+	var results []report.FileResult
+
+	for _, level := range levels {
+		var programs []string
+
+		for _, n := range level {
+			if n.Kind != buildorder.KindProgram {
+				log.Debug("Module is compiled with the programs that use it", slog.String("path", n.Path))
+				continue
+			}
+
+			programs = append(programs, n.Path)
+		}
+
+		// Programs of one level do not depend on each other, so they can run side by side
+		results = append(results, compileConcurrently(programs, len(installs), func(worker int, program string) report.FileResult {
+			install := installs[worker]
+
+			attrs := []any{slog.String("path", program)}
+			if len(installs) > 1 {
+				attrs = append(attrs, slog.String("simpl", install.SimplPath))
+			}
+
+			log.Info("Compiling project program", attrs...)
+
+			child := exec.Command(exe, projectArgs(install, program)...)
+			child.Stdout = consoleOutput(cfg)
+			child.Stderr = os.Stderr
+			shareGUILock(child)
+
+			start := time.Now()
+			err := deadline.runChild(child)
+
+			if err != nil {
+				log.Error("Project program failed", slog.String("path", program), slog.Any("error", err))
+			}
+
+			return newFileResult(program, nil, err, time.Since(start))
+		})...)
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no .smw programs found in %s", dir)
+	}
+
+	if t := report.Summarize(results); t.Failed > 0 {
+		return results, fmt.Errorf("%d of %d programs failed to compile", t.Failed, t.Files)
+	}
+
+	log.Info("Project compiled", slog.Int("programs", len(results)))
+	return results, nil
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectArgs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		[]string{"--no-config", "--backend", "gui", `C:\jobs\Lobby.smw`},
+		projectArgs(&Config{Backend: "gui"}, `C:\jobs\Lobby.smw`),
+	)
+
+	assert.Equal(t,
+		[]string{"--no-config", "--backend", "gui", "--recompile-all", "--no-history", "--recompile-key", "ctrl+f12", `C:\jobs\Lobby.smw`},
+		projectArgs(&Config{Backend: "gui", RecompileAll: true, NoHistory: true, RecompileKey: "ctrl+f12"}, `C:\jobs\Lobby.smw`),
+	)
+
+	assert.Equal(t,
+		[]string{"--no-config", "--backend", "gui", "--keep-temp", "--crash-retries", "2", "--update-prompts", "ignore", "--timestamps=abs", `C:\jobs\Lobby.smw`},
+		projectArgs(&Config{Backend: "gui", KeepTemp: true, CrashRetries: 2, UpdatePrompts: "ignore", Timestamps: "abs"}, `C:\jobs\Lobby.smw`),
+	)
+}
+
+func TestValidateArgs_ProjectDirectory(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, validateArgs(&cobra.Command{}, []string{t.TempDir()}))
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Norgate-AV/smpc/internal/server"
+)
+
+const (
+	remotePollInterval = 2 * time.Second  // How often remote compile progress is checked
+	remoteTimeout      = 10 * time.Minute // Per request, allowing for large uploads
+)
+
+// remoteCmd groups actions against a remote smpc server
+var remoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Compile on a remote smpc server",
+	Args:  cobra.NoArgs,
+}
+
+// remoteCompileCmd uploads a program to a server and compiles it there
+var remoteCompileCmd = &cobra.Command{
+	Use:          "compile <file>",
+	Short:        "Upload a program to an smpc server, compile it there and download the outputs",
+	Args:         cobra.ExactArgs(1),
+	RunE:         runRemoteCompile,
+	SilenceUsage: true,
+}
+
+func init() {
+	remoteCompileCmd.Flags().String("server", "", "server address (host:port, or an http:// or https:// URL)")
+	remoteCompileCmd.Flags().StringArray("include", nil, "extra folder to upload, e.g. shared modules; repeatable")
+	remoteCompileCmd.Flags().String("output", "", "directory to download compile outputs to (default the program's directory)")
+	remoteCompileCmd.Flags().String("priority", "", "job priority: low, normal or high")
+	remoteCompileCmd.Flags().String("ca-cert", "", "PEM certificate to trust for an https server with a private certificate")
+	_ = remoteCompileCmd.MarkFlagRequired("server")
+
+	remoteCmd.AddCommand(remoteCompileCmd)
+	RootCmd.AddCommand(remoteCmd)
+}
+
+func runRemoteCompile(cmd *cobra.Command, args []string) error {
+	serverAddr, _ := cmd.Flags().GetString("server")
+	include, _ := cmd.Flags().GetStringArray("include")
+	output, _ := cmd.Flags().GetString("output")
+	priority, _ := cmd.Flags().GetString("priority")
+	caCert, _ := cmd.Flags().GetString("ca-cert")
+
+	program, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("error resolving file path: %w", err)
+	}
+
+	if err := checkProgramFile(program); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(program); err != nil {
+		return fmt.Errorf("file does not exist: %s", program)
+	}
+
+	if output == "" {
+		output = filepath.Dir(program)
+	}
+
+	httpClient, err := remoteHTTPClient(caCert)
+	if err != nil {
+		return err
+	}
+
+	client := server.NewClient(remoteBaseURL(serverAddr), os.Getenv(apiTokenEnv), httpClient)
+	out := cmd.OutOrStdout()
+
+	var archive bytes.Buffer
+
+	name, err := server.WriteArchive(&archive, program, include)
+	if err != nil {
+		return err
+	}
+
+	job, err := client.Upload(&archive, server.JobRequest{
+		File:             name,
+		RecompileAll:     getBoolFlag(cmd, "recompile-all"),
+		WarningsAsErrors: getBoolFlag(cmd, "warnings-as-errors"),
+		Priority:         priority,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to submit job: %w", err)
+	}
+
+	fmt.Fprintf(out, "Submitted %s as job %s\n", filepath.Base(program), job.ID)
+
+	job, err = waitForRemoteJob(client, job, remotePollInterval, func(job server.Job) {
+		fmt.Fprintf(out, "Job %s: %s\n", job.ID, job.State)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(out, job.Output)
+
+	written, err := downloadArtifacts(client, job.ID, output, name)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range written {
+		fmt.Fprintf(out, "Downloaded %s\n", path)
+	}
+
+	if job.State != server.StateSucceeded {
+		if job.Error != "" {
+			return fmt.Errorf("remote compile failed: %s", job.Error)
+		}
+
+		return fmt.Errorf("remote compile failed with exit code %d", job.ExitCode)
+	}
+
+	return nil
+}
+
+// remoteBaseURL turns a host:port into an http:// URL, leaving full URLs unchanged
+func remoteBaseURL(addr string) string {
+	if strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://") {
+		return addr
+	}
+
+	return "http://" + addr
+}
+
+// remoteHTTPClient returns a client that also trusts the certificate in caCert, if set
+func remoteHTTPClient(caCert string) (*http.Client, error) {
+	if caCert == "" {
+		return &http.Client{Timeout: remoteTimeout}, nil
+	}
+
+	pem, err := os.ReadFile(caCert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates found in " + caCert)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	return &http.Client{Timeout: remoteTimeout, Transport: transport}, nil
+}
+
+// waitForRemoteJob polls until job finishes, calling progress on each state change
+func waitForRemoteJob(client *server.Client, job server.Job, interval time.Duration, progress func(server.Job)) (server.Job, error) {
+	state := job.State
+	progress(job)
+
+	for job.State != server.StateSucceeded && job.State != server.StateFailed {
+		time.Sleep(interval)
+
+		var err error
+
+		job, err = client.Job(job.ID)
+		if err != nil {
+			return job, fmt.Errorf("failed to get job status: %w", err)
+		}
+
+		if job.State != state {
+			state = job.State
+			progress(job)
+		}
+	}
+
+	return job, nil
+}
+
+// downloadArtifacts extracts a job's outputs into dir. The uploaded program itself is
+// skipped so the local copy is never replaced by the server's.
+func downloadArtifacts(client *server.Client, id, dir, program string) ([]string, error) {
+	var buf bytes.Buffer
+
+	if err := client.Artifacts(id, &buf); err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact archive: %w", err)
+	}
+
+	files := zr.File[:0]
+	for _, f := range zr.File {
+		if f.Name != program {
+			files = append(files, f)
+		}
+	}
+
+	zr.File = files
+
+	return server.ExtractArtifacts(zr, dir)
+}
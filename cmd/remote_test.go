@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/server"
+)
+
+func TestRemoteBaseURL(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "http://build01:8765", remoteBaseURL("build01:8765"))
+	assert.Equal(t, "https://build01:8765", remoteBaseURL("https://build01:8765"))
+}
+
+func TestWaitForRemoteJob_ReportsStateChanges(t *testing.T) {
+	t.Parallel()
+
+	var polls atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		state := server.StateRunning
+		if polls.Add(1) >= 3 {
+			state = server.StateSucceeded
+		}
+
+		_ = json.NewEncoder(w).Encode(server.Job{ID: "1", State: state})
+	}))
+	defer ts.Close()
+
+	var states []string
+
+	job, err := waitForRemoteJob(server.NewClient(ts.URL, "", ts.Client()), server.Job{ID: "1", State: server.StateQueued}, 0, func(job server.Job) {
+		states = append(states, job.State)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, server.StateSucceeded, job.State)
+	assert.Equal(t, []string{server.StateQueued, server.StateRunning, server.StateSucceeded}, states)
+}
+
+func TestDownloadArtifacts_SkipsProgram(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		zw := zip.NewWriter(w)
+		for _, name := range []string{"Lobby.smw", "Lobby.lpz", "SPlsWork/Helper.dll"} {
+			f, _ := zw.Create(name)
+			_, _ = f.Write([]byte(name))
+		}
+
+		_ = zw.Close()
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	program := filepath.Join(dir, "Lobby.smw")
+	require.NoError(t, os.WriteFile(program, []byte("local"), 0o644))
+
+	written, err := downloadArtifacts(server.NewClient(ts.URL, "", ts.Client()), "1", dir, "Lobby.smw")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(dir, "Lobby.lpz"), filepath.Join(dir, "SPlsWork", "Helper.dll")}, written)
+
+	data, err := os.ReadFile(program)
+	require.NoError(t, err)
+	assert.Equal(t, "local", string(data))
+}
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/compiler"
+	"github.com/Norgate-AV/smpc/internal/gitinfo"
+	"github.com/Norgate-AV/smpc/internal/hints"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/redact"
+	"github.com/Norgate-AV/smpc/internal/report"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+)
+
+// newFileResult converts the outcome of compiling one program into a report row.
+// result may be nil when the run failed before compilation finished.
+func newFileResult(path string, result *compiler.CompileResult, err error, duration time.Duration) report.FileResult {
+	fr := report.FileResult{
+		File:     path,
+		Status:   report.StatusPassed,
+		Duration: duration,
+	}
+
+	if result != nil {
+		fr.Errors = result.Errors
+		fr.Warnings = result.Warnings
+		fr.Notices = result.Notices
+		fr.Messages = reportMessages(result)
+	}
+
+	if err != nil {
+		fr.Status = report.StatusFailed
+		fr.Message = err.Error()
+	}
+
+	return fr
+}
+
+// reportMessages lists the compiler messages of result with their severities
+func reportMessages(result *compiler.CompileResult) []report.Message {
+	var messages []report.Message
+
+	for _, group := range []struct {
+		severity string
+		texts    []string
+	}{
+		{report.SeverityError, result.ErrorMessages},
+		{report.SeverityWarning, result.WarningMessages},
+		{report.SeverityNotice, result.NoticeMessages},
+	} {
+		for _, text := range group.texts {
+			messages = append(messages, report.Message{Severity: group.severity, Text: text})
+		}
+	}
+
+	return messages
+}
+
+// annotateHints sets the hint of every message kb has one for
+func annotateHints(results []report.FileResult, kb *hints.KB) {
+	for _, r := range results {
+		for i, m := range r.Messages {
+			if h, ok := kb.Lookup(m.Text); ok {
+				r.Messages[i].Hint = h.String()
+			}
+		}
+	}
+}
+
+// sourceRevision returns the git revision of the repository containing the program,
+// or nil when it is not in one
+func sourceRevision(absPath string, log logger.LoggerInterface) *gitinfo.Info {
+	info, ok := gitinfo.Lookup(filepath.Dir(absPath), gitinfo.RunGit)
+	if !ok {
+		log.Debug("Program is not in a git repository")
+		return nil
+	}
+
+	log.Info("Source revision",
+		slog.String("commit", info.Commit),
+		slog.String("branch", info.Branch),
+		slog.Bool("dirty", info.Dirty),
+	)
+
+	return &info
+}
+
+// startedData is the payload of the started lifecycle event
+func startedData(absPath string, cfg *Config, revision *gitinfo.Info, toolchain simpl.Toolchain) map[string]any {
+	data := map[string]any{
+		"file":         absPath,
+		"recompileAll": cfg.RecompileAll,
+	}
+
+	if revision != nil {
+		data["git"] = revision
+	}
+
+	if toolchain != (simpl.Toolchain{}) {
+		data["toolchain"] = toolchain
+	}
+
+	return data
+}
+
+// consoleOutput returns where human-readable output goes. stdout is reserved for
+// machine-readable output when there is any: the --events stream or a report
+// written to stdout, which includes --json.
+func consoleOutput(cfg *Config) io.Writer {
+	if cfg.Events != "" {
+		return os.Stderr
+	}
+
+	for _, v := range cfg.ReportValues() {
+		if spec, err := report.ParseSpec(v); err == nil && spec.Path == report.StdoutPath {
+			return os.Stderr
+		}
+	}
+
+	return os.Stdout
+}
+
+// ReportValues returns the --report values, adding a JSON report to stdout for --json
+func (c *Config) ReportValues() []string {
+	if !c.JSON {
+		return c.Reports
+	}
+
+	return append(slices.Clone(c.Reports), "json="+report.StdoutPath)
+}
+
+// printSummaryTable prints the batch summary table. A single-file run already
+// reports its result through the log, so the table is only shown for batches.
+func printSummaryTable(w io.Writer, results []report.FileResult) {
+	if len(results) < 2 {
+		return
+	}
+
+	_, _ = io.WriteString(w, "\n")
+	_ = report.WriteTable(w, results)
+}
+
+// parseReportSpecs validates every --report value before any work starts. Only one
+// machine-readable output can go to stdout, counting the --events stream.
+func parseReportSpecs(values []string, events string) ([]report.Spec, error) {
+	specs := make([]report.Spec, 0, len(values))
+
+	toStdout := 0
+	if events != "" {
+		toStdout++
+	}
+
+	for _, v := range values {
+		spec, err := report.ParseSpec(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if spec.Path == report.StdoutPath {
+			toStdout++
+		}
+
+		specs = append(specs, spec)
+	}
+
+	if toStdout > 1 {
+		return nil, fmt.Errorf("only one of --events, --json and --report <format>=%s can write to stdout", report.StdoutPath)
+	}
+
+	return specs, nil
+}
+
+// writeReports writes each requested report, redacting paths and messages if enabled.
+// Report failures are logged but never change the exit status of the compile.
+func writeReports(specs []report.Spec, results []report.FileResult, redactor *redact.Redactor, log logger.LoggerInterface) {
+	if len(specs) == 0 {
+		return
+	}
+
+	results = redactResults(results, redactor)
+
+	for _, spec := range specs {
+		if err := report.WriteFile(spec, results); err != nil {
+			log.Error("Failed to write report", slog.String("format", spec.Format), slog.Any("error", err))
+			continue
+		}
+
+		log.Info("Report written", slog.String("format", spec.Format), slog.String("path", spec.Path))
+	}
+}
+
+// redactResults returns results with paths and messages redacted, or results itself
+// when redaction is disabled
+func redactResults(results []report.FileResult, redactor *redact.Redactor) []report.FileResult {
+	if redactor == nil {
+		return results
+	}
+
+	redacted := make([]report.FileResult, len(results))
+	for i, r := range results {
+		r.File = redactor.String(r.File)
+		r.Message = redactor.String(r.Message)
+
+		if r.Messages != nil {
+			messages := make([]report.Message, len(r.Messages))
+			for j, m := range r.Messages {
+				messages[j] = report.Message{Severity: m.Severity, Text: redactor.String(m.Text), Hint: m.Hint}
+			}
+
+			r.Messages = messages
+		}
+
+		redacted[i] = r
+	}
+
+	return redacted
+}
+
+// redactTranscripts redacts the dialog titles and control text the stream would
+// otherwise emit verbatim, since it only redacts top-level strings
+func redactTranscripts(transcripts []compiler.DialogTranscript, redactor *redact.Redactor) []compiler.DialogTranscript {
+	if redactor == nil {
+		return transcripts
+	}
+
+	redacted := make([]compiler.DialogTranscript, len(transcripts))
+	for i, t := range transcripts {
+		t.Title = redactor.String(t.Title)
+
+		controls := make([]compiler.ControlTranscript, len(t.Controls))
+		for j, c := range t.Controls {
+			c.Text = redactor.String(c.Text)
+
+			if c.Items != nil {
+				items := make([]string, len(c.Items))
+				for k, item := range c.Items {
+					items[k] = redactor.String(item)
+				}
+
+				c.Items = items
+			}
+
+			controls[j] = c
+		}
+
+		t.Controls = controls
+		redacted[i] = t
+	}
+
+	return redacted
+}
+
+// redactAudit redacts the window titles and details of the audit trail, which can
+// name the program being compiled
+func redactAudit(entries []compiler.AuditEntry, redactor *redact.Redactor) []compiler.AuditEntry {
+	if redactor == nil {
+		return entries
+	}
+
+	redacted := make([]compiler.AuditEntry, len(entries))
+	for i, e := range entries {
+		e.Title = redactor.String(e.Title)
+		e.Detail = redactor.String(e.Detail)
+		redacted[i] = e
+	}
+
+	return redacted
+}
+
+// redactSkippedDialogs redacts the titles of the skipped dialogs, which can name the
+// program being compiled
+func redactSkippedDialogs(dialogs []compiler.SkippedDialog, redactor *redact.Redactor) []compiler.SkippedDialog {
+	if redactor == nil {
+		return dialogs
+	}
+
+	redacted := make([]compiler.SkippedDialog, len(dialogs))
+	for i, d := range dialogs {
+		d.Title = redactor.String(d.Title)
+		redacted[i] = d
+	}
+
+	return redacted
+}
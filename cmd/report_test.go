@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/compiler"
+	"github.com/Norgate-AV/smpc/internal/gitinfo"
+	"github.com/Norgate-AV/smpc/internal/hints"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/redact"
+	"github.com/Norgate-AV/smpc/internal/report"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+)
+
+// TestNewFileResult tests conversion of compile outcomes to report rows
+func TestNewFileResult(t *testing.T) {
+	t.Parallel()
+
+	passed := newFileResult(`C:\jobs\lobby.smw`, &compiler.CompileResult{Warnings: 2}, nil, time.Second)
+	assert.Equal(t, report.StatusPassed, passed.Status)
+	assert.Equal(t, 2, passed.Warnings)
+
+	failed := newFileResult(`C:\jobs\theater.smw`, &compiler.CompileResult{
+		Errors:          3,
+		ErrorMessages:   []string{"ERROR (LGSPLS1700) Line 5: Undefined symbol 'foo'"},
+		WarningMessages: []string{"WARNING (LGCMCVT102) ** Signal foo has no driving source"},
+	}, errors.New("compilation failed with 3 error(s)"), time.Second)
+	assert.Equal(t, report.StatusFailed, failed.Status)
+	assert.Equal(t, 3, failed.Errors)
+	assert.Equal(t, "compilation failed with 3 error(s)", failed.Message)
+	assert.Equal(t, []report.Message{
+		{Severity: report.SeverityError, Text: "ERROR (LGSPLS1700) Line 5: Undefined symbol 'foo'"},
+		{Severity: report.SeverityWarning, Text: "WARNING (LGCMCVT102) ** Signal foo has no driving source"},
+	}, failed.Messages)
+
+	launchFailed := newFileResult(`C:\jobs\lobby.smw`, nil, errors.New("error opening file"), 0)
+	assert.Equal(t, report.StatusFailed, launchFailed.Status)
+	assert.Zero(t, launchFailed.Errors)
+}
+
+// TestPrintSummaryTable_SingleFileSkipped tests that single-file runs don't print a table
+func TestPrintSummaryTable_SingleFileSkipped(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	printSummaryTable(&buf, []report.FileResult{{File: "a.smw", Status: report.StatusPassed}})
+	assert.Empty(t, buf.String())
+
+	printSummaryTable(&buf, []report.FileResult{
+		{File: "a.smw", Status: report.StatusPassed},
+		{File: "b.smw", Status: report.StatusFailed},
+	})
+	assert.Contains(t, buf.String(), "TOTAL (1 passed, 1 failed)")
+}
+
+// TestWriteReports_Redacted tests that reports honour --redact
+func TestWriteReports_Redacted(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "results.csv")
+	specs, err := parseReportSpecs([]string{"csv=" + path}, "")
+	require.NoError(t, err)
+
+	results := []report.FileResult{{File: `C:\Users\jsmith\jobs\lobby.smw`, Status: report.StatusPassed}}
+	writeReports(specs, results, redact.NewWithUsers(redact.ModeBasename, "jsmith"), logger.NewNoOpLogger())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "lobby.smw")
+	assert.NotContains(t, string(data), "jsmith")
+	assert.Equal(t, `C:\Users\jsmith\jobs\lobby.smw`, results[0].File, "Caller's results must not be modified")
+}
+
+// TestRedactTranscripts tests that nested dialog text is redacted without
+// modifying the caller's transcripts
+func TestRedactTranscripts(t *testing.T) {
+	t.Parallel()
+
+	transcripts := []compiler.DialogTranscript{{
+		Title: "Compile Complete",
+		Hwnd:  0x2222,
+		Controls: []compiler.ControlTranscript{
+			{Class: "Edit", Text: `Opening C:\Users\jsmith\jobs\lobby.smw`},
+			{Class: "ListBox", Items: []string{`C:\Users\jsmith\jobs\lobby.umc`}},
+		},
+	}}
+
+	redacted := redactTranscripts(transcripts, redact.NewWithUsers(redact.ModeBasename, "jsmith"))
+
+	require.Len(t, redacted, 1)
+	assert.Equal(t, "Compile Complete", redacted[0].Title)
+	assert.NotContains(t, redacted[0].Controls[0].Text, "jsmith")
+	assert.NotContains(t, redacted[0].Controls[1].Items[0], "jsmith")
+	assert.Contains(t, transcripts[0].Controls[1].Items[0], "jsmith", "Caller's transcripts must not be modified")
+}
+
+func TestRedactAudit(t *testing.T) {
+	t.Parallel()
+
+	entries := []compiler.AuditEntry{{
+		Action: compiler.AuditWindowClose,
+		Hwnd:   0x9999,
+		Title:  `SIMPL Windows - [C:\Users\jsmith\jobs\lobby.smw]`,
+	}}
+
+	redacted := redactAudit(entries, redact.NewWithUsers(redact.ModeBasename, "jsmith"))
+
+	require.Len(t, redacted, 1)
+	assert.NotContains(t, redacted[0].Title, "jsmith")
+	assert.Equal(t, compiler.AuditWindowClose, redacted[0].Action)
+	assert.Contains(t, entries[0].Title, "jsmith", "Caller's entries must not be modified")
+}
+
+// TestParseReportSpecs_Invalid tests that bad --report values fail up front
+func TestParseReportSpecs_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseReportSpecs([]string{"csv=ok.csv", "html=out.html"}, "")
+	assert.Error(t, err)
+}
+
+func TestParseReportSpecs_OneOutputOnStdout(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseReportSpecs([]string{"csv=ok.csv", "tap=-"}, "")
+	assert.NoError(t, err)
+
+	_, err = parseReportSpecs([]string{"tap=-", "json=-"}, "")
+	assert.Error(t, err)
+
+	_, err = parseReportSpecs([]string{"json=-"}, "ndjson")
+	assert.Error(t, err)
+}
+
+func TestConsoleOutput(t *testing.T) {
+	t.Parallel()
+
+	assert.Same(t, os.Stdout, consoleOutput(&Config{Reports: []string{"csv=results.csv"}}))
+	assert.Same(t, os.Stderr, consoleOutput(&Config{Events: "ndjson"}))
+	assert.Same(t, os.Stderr, consoleOutput(&Config{Reports: []string{"tap=-"}}))
+	assert.Same(t, os.Stderr, consoleOutput(&Config{JSON: true}))
+}
+
+func TestConfig_ReportValues(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"csv=results.csv"}, (&Config{Reports: []string{"csv=results.csv"}}).ReportValues())
+	assert.Equal(t, []string{"csv=results.csv", "json=-"}, (&Config{Reports: []string{"csv=results.csv"}, JSON: true}).ReportValues())
+}
+
+func TestStartedData(t *testing.T) {
+	t.Parallel()
+
+	data := startedData(`C:\jobs\lobby.smw`, &Config{RecompileAll: true}, nil, simpl.Toolchain{})
+	assert.Equal(t, map[string]any{"file": `C:\jobs\lobby.smw`, "recompileAll": true}, data)
+
+	revision := &gitinfo.Info{Commit: "1a2b3c", Branch: "main"}
+	toolchain := simpl.Toolchain{SimplWindows: "4.1400.5.0", DeviceDatabase: "200.05.001.00"}
+	data = startedData(`C:\jobs\lobby.smw`, &Config{}, revision, toolchain)
+	assert.Same(t, revision, data["git"])
+	assert.Equal(t, toolchain, data["toolchain"])
+}
+
+func TestAnnotateHints(t *testing.T) {
+	t.Parallel()
+
+	results := []report.FileResult{{Messages: []report.Message{
+		{Severity: report.SeverityWarning, Text: "WARNING (LGCMCVT102) ** Signal foo has no driving source"},
+		{Severity: report.SeverityNotice, Text: "NOTICE Something unknown"},
+	}}}
+
+	annotateHints(results, hints.Builtin())
+	assert.Contains(t, results[0].Messages[0].Hint, "hint: a signal is used as an input")
+	assert.Empty(t, results[0].Messages[1].Hint)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"text/tabwriter"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/reproducible"
+	"github.com/Norgate-AV/smpc/internal/workspace"
+)
+
+// reproducibleBuilds is how many times --verify-reproducible compiles the program
+const reproducibleBuilds = 2
+
+// reproducibleArgs returns the smpc arguments for one build of --verify-reproducible.
+// Recompile All is forced so neither build reuses earlier outputs, and the builds are
+// kept out of the compile history.
+func reproducibleArgs(cfg *Config, program string) []string {
+	args := []string{"--" + noConfigFlag, "--recompile-all", "--no-history", "--backend", cfg.Backend}
+
+	if cfg.PreferNative {
+		args = append(args, "--prefer-native")
+	}
+
+	if cfg.CompileKey != "" {
+		args = append(args, "--compile-key", cfg.CompileKey)
+	}
+
+	if cfg.RecompileKey != "" {
+		args = append(args, "--recompile-key", cfg.RecompileKey)
+	}
+
+	if cfg.Timestamps != "" {
+		args = append(args, "--timestamps="+cfg.Timestamps)
+	}
+
+	args = append(args, launchArgs(cfg)...)
+
+	return append(args, program)
+}
+
+// verifyReproducible compiles separate copies of the program twice and compares the
+// outputs. Each build runs as a child smpc process in its own sandbox. The sandboxes
+// are removed if the outputs match and kept for inspection if they do not.
+func verifyReproducible(cfg *Config, absPath string, deadline *maxRuntime, log logger.LoggerInterface) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	builds := make([]*workspace.Workspace, 0, reproducibleBuilds)
+	outputs := make([][]string, 0, reproducibleBuilds)
+
+	keep := false
+	defer func() {
+		for _, ws := range builds {
+			if keep {
+				log.Info("Kept build for inspection", slog.String("dir", ws.Dir))
+				continue
+			}
+
+			removeWorkspace(ws, log)
+		}
+	}()
+
+	for i := 1; i <= reproducibleBuilds; i++ {
+		ws, err := workspace.New(absPath)
+		if err != nil {
+			return fmt.Errorf("failed to create build %d: %w", i, err)
+		}
+
+		builds = append(builds, ws)
+		log.Info("Starting reproducibility build", slog.Int("build", i), slog.String("dir", ws.Dir))
+
+		child := exec.Command(exe, reproducibleArgs(cfg, ws.Program)...)
+		child.Stdout = consoleOutput(cfg)
+		child.Stderr = os.Stderr
+		shareGUILock(child)
+
+		if err := deadline.runChild(child); err != nil {
+			keep = true
+			return fmt.Errorf("build %d failed: %w", i, err)
+		}
+
+		files, err := ws.Artifacts()
+		if err != nil {
+			return err
+		}
+
+		outputs = append(outputs, files)
+	}
+
+	result, err := reproducible.Compare(builds[0].Dir, outputs[0], builds[1].Dir, outputs[1])
+	if err != nil {
+		return fmt.Errorf("failed to compare builds: %w", err)
+	}
+
+	writeReproducibilityTable(consoleOutput(cfg), result)
+
+	for _, f := range result.Files {
+		log.Debug("Compared output",
+			slog.String("path", f.Path),
+			slog.String("status", f.Status),
+			slog.String("hashA", f.HashA),
+			slog.String("hashB", f.HashB),
+		)
+	}
+
+	if !result.Reproducible() {
+		keep = true
+		return fmt.Errorf("compilation is not reproducible")
+	}
+
+	log.Info("Compilation is reproducible", slog.Int("outputs", len(result.Files)))
+	return nil
+}
+
+// writeReproducibilityTable prints each output's comparison status
+func writeReproducibilityTable(w io.Writer, result reproducible.Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "\nOUTPUT\tSTATUS\tSHA-256")
+
+	for _, f := range result.Files {
+		hash := f.HashA
+		if hash == "" {
+			hash = f.HashB
+		}
+
+		if f.Status == reproducible.StatusDifferent || f.Status == reproducible.StatusEquivalent {
+			hash = shortHash(f.HashA) + " / " + shortHash(f.HashB)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Path, f.Status, hash)
+	}
+
+	_ = tw.Flush()
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+
+	return hash
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/reproducible"
+)
+
+func TestReproducibleArgs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		[]string{"--no-config", "--recompile-all", "--no-history", "--backend", "gui", `C:\tmp\Lobby.smw`},
+		reproducibleArgs(&Config{Backend: "gui"}, `C:\tmp\Lobby.smw`),
+	)
+
+	assert.Equal(t,
+		[]string{"--no-config", "--recompile-all", "--no-history", "--backend", "dde", "--prefer-native", "--compile-key", "ctrl+f9", `C:\tmp\Lobby.smw`},
+		reproducibleArgs(&Config{Backend: "dde", PreferNative: true, CompileKey: "ctrl+f9"}, `C:\tmp\Lobby.smw`),
+	)
+}
+
+func TestWriteReproducibilityTable(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	writeReproducibilityTable(&buf, reproducible.Result{Files: []reproducible.FileDiff{
+		{Path: "Lobby.lpz", Status: reproducible.StatusEquivalent, HashA: "aaaaaaaaaaaaaaaa", HashB: "bbbbbbbbbbbbbbbb"},
+		{Path: "Lobby.sig", Status: reproducible.StatusIdentical, HashA: "cccc", HashB: "cccc"},
+		{Path: "Lobby.log", Status: reproducible.StatusMissing, HashB: "dddd"},
+	}})
+
+	out := buf.String()
+	assert.Contains(t, out, "aaaaaaaaaaaa / bbbbbbbbbbbb")
+	assert.Regexp(t, `Lobby\.sig\s+identical\s+cccc`, out)
+	assert.Regexp(t, `Lobby\.log\s+missing\s+dddd`, out)
+}
@@ -1,19 +1,34 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime/debug"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Norgate-AV/smpc/internal/artifacts"
+	"github.com/Norgate-AV/smpc/internal/autorespond"
+	"github.com/Norgate-AV/smpc/internal/clock"
 	"github.com/Norgate-AV/smpc/internal/compiler"
+	"github.com/Norgate-AV/smpc/internal/eventstream"
+	"github.com/Norgate-AV/smpc/internal/guilock"
+	"github.com/Norgate-AV/smpc/internal/hints"
+	"github.com/Norgate-AV/smpc/internal/i18n"
+	"github.com/Norgate-AV/smpc/internal/interfaces"
+	"github.com/Norgate-AV/smpc/internal/keychord"
 	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/redact"
+	"github.com/Norgate-AV/smpc/internal/report"
 	"github.com/Norgate-AV/smpc/internal/simpl"
 	"github.com/Norgate-AV/smpc/internal/timeouts"
 	"github.com/Norgate-AV/smpc/internal/version"
@@ -38,11 +53,22 @@ type CompilationParams struct {
 	PidPtr   *uint32
 	Config   *Config
 	Logger   logger.LoggerInterface
+	Events   interfaces.EventSource
+
+	CompileKey      keychord.Chord
+	RecompileAllKey keychord.Chord
+	OnTrigger       func() // Called as soon as the compile has been triggered
+	MessageInput    bool   // Drive SIMPL Windows with window messages only (--show-mode)
+
+	AutoRespond *autorespond.Policy // Answers for dialogs not otherwise handled (nil = none)
+	Hints       *hints.KB           // Explanations logged under matching compiler messages
+	Timeout     time.Duration       // Compilation timeout (0 = the default)
+	Exited      <-chan int          // Receives SIMPL Windows' exit code if it exits (nil = not watched)
 }
 
 // RootCmd is the root command for the smpc CLI application.
 var RootCmd = &cobra.Command{
-	Use:          "smpc <file-path>",
+	Use:          "smpc <file-path|project-dir>",
 	Short:        "smpc - Automate compilation of .smw files",
 	Version:      version.GetVersion(),
 	Args:         validateArgs,
@@ -55,9 +81,72 @@ func init() {
 	RootCmd.SetVersionTemplate(`{{printf "%s\n" .Version}}`)
 
 	// Add flags
-	RootCmd.PersistentFlags().BoolP("verbose", "V", false, "enable verbose output")
+	RootCmd.PersistentFlags().BoolP("verbose", "V", false, "enable verbose output (same as -v)")
+	RootCmd.PersistentFlags().CountP("verbosity", "v", "increase console verbosity (-v debug, -vv window monitor detail, -vvv Win32 call tracing)")
 	RootCmd.PersistentFlags().BoolP("recompile-all", "r", false, "trigger Recompile All (Alt+F12) instead of Compile (F12)")
+	RootCmd.PersistentFlags().String("compile-key", "", "key chord that triggers Compile in SIMPL Windows (default f12)")
+	RootCmd.PersistentFlags().String("recompile-key", "", "key chord that triggers Recompile All in SIMPL Windows (default alt+f12)")
+	RootCmd.PersistentFlags().String("backend", compiler.BackendGUI, "how compilation is triggered: gui (keystrokes) or dde (experimental)")
+	RootCmd.PersistentFlags().Bool("stage-local", false, "compile a copy in a local temp workspace and copy artifacts back (for programs on network shares)")
+	RootCmd.PersistentFlags().Bool("sandbox", false, "compile a copy in a scratch directory, leaving the original untouched, and report the artifacts there")
+	RootCmd.PersistentFlags().Bool("prefer-native", false, "compile without GUI automation when the installed SIMPL Windows supports it")
+	RootCmd.PersistentFlags().String("if-running", ifRunningIgnore, "what to do with SIMPL Windows instances already running at startup: ignore, kill, attach or abort")
+	RootCmd.PersistentFlags().String("if-interfering", ifInterferingWarn, "what to do when VT Pro-e, Toolbox or D3 Pro are running at startup: ignore, warn or wait")
+	RootCmd.PersistentFlags().String("simpl-path", "", "SIMPL Windows executable to use (default: SIMPL_WINDOWS_PATH, else the standard install)")
+	RootCmd.PersistentFlags().String("show-mode", showModeNormal, "how to show SIMPL Windows so compiles disturb the desktop less: normal, minimized, hidden or offscreen")
+	RootCmd.PersistentFlags().String("simpl-workdir", "", "working directory to start SIMPL Windows in (default: smpc's)")
+	RootCmd.PersistentFlags().StringArray("simpl-env", nil, "KEY=VALUE environment variable to start SIMPL Windows with; repeatable")
+	RootCmd.PersistentFlags().StringArray("parallel-simpl-path", nil, "experimental: another SIMPL Windows executable to compile a project's programs with concurrently, one program per installation; repeatable")
+	RootCmd.PersistentFlags().String("runas", "", "launch SIMPL Windows as another account (DOMAIN\\user); password from "+runAsPasswordEnv+" or Credential Manager")
+	RootCmd.PersistentFlags().Bool("keep-temp", false, "keep the scratch files SIMPL Windows leaves next to the program")
+	RootCmd.PersistentFlags().StringArray("temp-pattern", nil, "file name pattern of scratch files to remove after compiling, replacing the defaults; repeatable")
+	RootCmd.PersistentFlags().Int("crash-retries", 0, "compile again in a new SIMPL Windows instance up to this many times if SIMPL Windows crashes")
+	RootCmd.PersistentFlags().String("update-prompts", updatePromptsDismiss, "what to do with update and download prompts shown at startup: dismiss or ignore")
+	RootCmd.PersistentFlags().Duration("wait-for-lock", 0, "how long to wait for another smpc instance to finish with SIMPL Windows before giving up (0 = fail at once)")
+	RootCmd.PersistentFlags().Duration("max-runtime", 0, "force-close SIMPL Windows and exit with code 124 if the whole run takes longer than this (0 = no limit)")
+	RootCmd.PersistentFlags().String("abort-key", "ctrl+alt+q", "global hotkey that aborts a running compile (\"\" to disable)")
+	RootCmd.PersistentFlags().Bool("auto-recompile-all", false, "retry once with Recompile All when compile errors point to a signal database change")
+	RootCmd.PersistentFlags().Bool("save-first", false, "save the program in SIMPL Windows (Ctrl+S) and wait for the save before compiling")
+	RootCmd.PersistentFlags().String("save-prompt", compiler.AnswerYes, "answer to the save prompt shown when compiling a modified program: yes, no or abort")
+	RootCmd.PersistentFlags().String("close-confirmation", compiler.AnswerNo, "answer to the save confirmation shown when SIMPL Windows is closed: yes or no")
+	RootCmd.PersistentFlags().Bool("warnings-as-errors", false, "treat compiler warnings as errors in counts, messages, reports and exit code")
 	RootCmd.PersistentFlags().BoolP("logs", "l", false, "print the current log file to stdout and exit")
+	RootCmd.PersistentFlags().Int("log-max-size", logger.DefaultLogMaxSize, "maximum log file size in megabytes before rotation")
+	RootCmd.PersistentFlags().Int("log-max-backups", logger.DefaultLogMaxBackups, "number of rotated log files to keep")
+	RootCmd.PersistentFlags().Int("log-max-age", logger.DefaultLogMaxAge, "maximum days to keep rotated log files")
+	RootCmd.PersistentFlags().Bool("log-compress", true, "gzip rotated log files")
+	RootCmd.PersistentFlags().Duration("poll-min", timeouts.StatePollingInterval, "window polling interval right after a change or compile trigger")
+	RootCmd.PersistentFlags().String("timeout-curve", "", "compile timeout by program size as base=5m,per-mb=22.5s,max=1h (omitted keys keep these defaults)")
+	RootCmd.PersistentFlags().Duration("poll-max", timeouts.MaxPollingInterval, "longest window polling interval to back off to while nothing changes")
+	RootCmd.PersistentFlags().StringArray("monitor-include", nil, "only report windows matching class=<name>, title=<regex> or pid=<id,...> to the window monitor; repeatable")
+	RootCmd.PersistentFlags().StringArray("monitor-exclude", nil, "never report windows matching class=<name>, title=<regex> or pid=<id,...> to the window monitor; repeatable")
+	RootCmd.PersistentFlags().String("timestamps", "", "prefix console lines with the time elapsed since start, or with --timestamps=abs the time of day as well")
+	RootCmd.PersistentFlags().Lookup("timestamps").NoOptDefVal = logger.TimestampsElapsed
+	RootCmd.PersistentFlags().String("lang", "", "console language: en, de or fr (default from LC_ALL, LC_MESSAGES or LANG, else en)")
+	RootCmd.PersistentFlags().String("redact", "", "redact user names and file paths from logs and events (basename or hash)")
+	RootCmd.PersistentFlags().StringArray("report", nil, "write per-file results as <format>=<path> (supported: codequality, csv, json, pdf, tap; \"-\" for stdout); repeatable")
+	RootCmd.PersistentFlags().Bool("json", false, "write the JSON report to stdout, with all other output on stderr (same as --report json=-)")
+	RootCmd.PersistentFlags().Bool("notify", false, "show a Windows toast notification with the result when the run finishes")
+	RootCmd.PersistentFlags().String("notify-email", "", "JSON file of SMTP settings for emailing the results and JSON report when the run finishes")
+	RootCmd.PersistentFlags().String("pprof", "", "serve Go profiling endpoints on this address while running (e.g. localhost:6060)")
+	RootCmd.PersistentFlags().String("trace", "", "write a Go runtime execution trace to this file")
+	RootCmd.PersistentFlags().String("output-dir", "", "copy the files the compile creates or changes into a subdirectory of this directory")
+	RootCmd.PersistentFlags().String("output-name", artifacts.DefaultTemplate, "subdirectory of --output-dir; may use {program}, {version}, {timestamp}, {date} and {time}")
+	RootCmd.PersistentFlags().String("output-version", "", "value of {version} in --output-name, e.g. a CI build number")
+	RootCmd.PersistentFlags().Bool("verify-reproducible", false, "compile two sandboxed copies with Recompile All and compare the outputs")
+	RootCmd.PersistentFlags().Bool("graph", false, "with a project directory, print the order its modules and programs would be built in and exit")
+	RootCmd.PersistentFlags().String("hints", "", "JSON knowledge base of extra compiler message hints, taking precedence over the built-in ones")
+	RootCmd.PersistentFlags().String("auto-respond", "", "JSON policy file mapping dialog title patterns to a button to click or key to press")
+	RootCmd.PersistentFlags().Bool("dialog-updates", false, "re-read open dialogs on every poll and report changes to their text as window_updated events")
+	RootCmd.PersistentFlags().Bool("dialog-transcripts", false, "record the title and control text of every dialog seen and include them in --events output")
+	RootCmd.PersistentFlags().Bool("no-history", false, "do not record this compile in the history used by 'smpc history report'")
+	RootCmd.PersistentFlags().String("events", "", "stream lifecycle and window events to stdout as they happen (supported: ndjson)")
+
+	// Set by the non-elevated instance when it relaunches as administrator
+	RootCmd.PersistentFlags().String("handoff", "", "directory to write console output to for the instance that relaunched this one")
+	_ = RootCmd.PersistentFlags().MarkHidden("handoff")
+	RootCmd.PersistentFlags().Bool(noConfigFlag, false, "ignore the config file; set for the smpc processes smpc starts itself")
+	_ = RootCmd.PersistentFlags().MarkHidden(noConfigFlag)
 }
 
 // validateArgs validates that a .smw file argument is provided (if any args given)
@@ -72,11 +161,12 @@ func validateArgs(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if filepath.Ext(args[0]) != ".smw" {
-		return fmt.Errorf("file must have .smw extension")
+	// A project directory is built in dependency order
+	if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+		return nil
 	}
 
-	return nil
+	return checkProgramFile(args[0])
 }
 
 // handleLogsFlag processes the --logs flag and exits if needed
@@ -101,11 +191,35 @@ func handleLogsFlag(cfg *Config, exitFunc func(int)) error {
 }
 
 // initializeLogger creates a logger and logs startup information
-func initializeLogger(cfg *Config) (logger.LoggerInterface, error) {
-	log, err := logger.NewLogger(logger.LoggerOptions{
-		Verbose:  cfg.Verbose,
-		Compress: true,
-	})
+func initializeLogger(cfg *Config, redactor *redact.Redactor) (logger.LoggerInterface, error) {
+	// Keep stdout clean for the event stream; human-readable output moves to stderr
+	return initializeLoggerTo(cfg, redactor, consoleOutput(cfg))
+}
+
+// initializeLoggerTo creates a logger whose console output goes to console
+func initializeLoggerTo(cfg *Config, redactor *redact.Redactor, console io.Writer) (logger.LoggerInterface, error) {
+	lang, err := i18n.Resolve(cfg.Lang, os.Getenv)
+	if err != nil {
+		return nil, fmt.Errorf("--lang: %w", err)
+	}
+
+	if err := logger.ValidateTimestamps(cfg.Timestamps); err != nil {
+		return nil, fmt.Errorf("--timestamps: %w", err)
+	}
+
+	opts := logger.LoggerOptions{
+		Verbosity:     cfg.Verbosity,
+		MaxSize:       cfg.LogMaxSize,
+		MaxBackups:    cfg.LogMaxBackups,
+		MaxAge:        cfg.LogMaxAge,
+		Compress:      cfg.LogCompress,
+		Redactor:      redactor,
+		ConsoleWriter: console,
+		Lang:          lang,
+		Timestamps:    cfg.Timestamps,
+	}
+
+	log, err := logger.NewLogger(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create logger: %w", err)
 	}
@@ -115,14 +229,14 @@ func initializeLogger(cfg *Config) (logger.LoggerInterface, error) {
 
 // ensureElevated checks for admin privileges and relaunches if needed
 func ensureElevated(log logger.LoggerInterface) error {
-	return ensureElevatedWithDeps(log, windows.IsElevated, windows.RelaunchAsAdmin, os.Exit)
+	return ensureElevatedWithDeps(log, windows.IsElevated, relaunchElevated, os.Exit)
 }
 
 // ensureElevatedWithDeps is the testable version with injected dependencies
 func ensureElevatedWithDeps(
 	log logger.LoggerInterface,
 	isElevated func() bool,
-	relaunchAsAdmin func() error,
+	relaunchAsAdmin func() (int, error),
 	exitFunc func(int),
 ) error {
 	log.Debug("Checking elevation status")
@@ -130,15 +244,19 @@ func ensureElevatedWithDeps(
 		log.Info("This program requires administrator privileges")
 		log.Info("Relaunching as administrator")
 
-		if err := relaunchAsAdmin(); err != nil {
-			log.Error("RelaunchAsAdmin failed", slog.Any("error", err))
+		// Release the log file so the elevated instance can write and rotate it. Nothing
+		// is logged after this: writing would reopen the file under the elevated instance.
+		log.Close()
+
+		exitCode, err := relaunchAsAdmin()
+		if err != nil {
+			// Reported on stderr by cobra
 			return fmt.Errorf("error relaunching as admin: %w", err)
 		}
 
-		// Exit this instance, the elevated one will continue
-		log.Debug("Relaunched successfully, exiting non-elevated instance")
-		log.Close()
-		exitFunc(0)
+		// The elevated instance did the work and logged it; exit with its result
+		exitFunc(exitCode)
+		return nil // Won't actually reach here due to exitFunc
 	}
 
 	log.Debug("Running with administrator privileges")
@@ -161,29 +279,89 @@ func validateAndResolvePath(filePath string, log logger.LoggerInterface) (string
 	return absPath, nil
 }
 
-// launchSIMPLWindows launches SIMPL, starts monitoring with the PID, and returns cleanup function
-func launchSIMPLWindows(simplClient *simpl.Client, absPath string, log logger.LoggerInterface) (hwnd uintptr, pid uint32, cleanup func(), err error) {
+// launchSIMPLWindows launches SIMPL, starts monitoring with the PID, and returns cleanup function.
+// When runAs is set, SIMPL Windows is started under that account instead of the current user.
+// launch sets its working directory and extra environment.
+// The process is returned when its handle is available (it is not for --runas launches);
+// cleanup stops the monitor, confirms the process has exited and releases the handle.
+func launchSIMPLWindows(
+	ctx context.Context,
+	simplClient *simpl.Client,
+	absPath string,
+	launch simplLaunch,
+	runAs *runAsAccount,
+	log logger.LoggerInterface,
+) (process *windows.Process, pid uint32, cleanup func(), err error) {
 	// Open the file with SIMPL Windows application using elevated privileges
-	// SW_SHOWNORMAL = 1
-	log.Debug("Launching SIMPL Windows with file", slog.String("path", absPath))
-	pid, err = windows.ShellExecuteEx(0, "open", simpl.GetSimplWindowsPath(), absPath, "", 1, log)
-	if err != nil {
-		log.Error("ShellExecuteEx failed", slog.Any("error", err))
-		return 0, 0, nil, fmt.Errorf("error opening file: %w", err)
+	launchPath, shortened := windows.LaunchPath(absPath)
+	if shortened {
+		log.Debug("Using short path for long program path", slog.String("path", absPath), slog.String("short", launchPath))
+	}
+
+	log.Debug("Launching SIMPL Windows with file",
+		slog.String("path", launchPath),
+		slog.String("workdir", launch.Dir),
+		slog.Int("extraEnv", len(launch.Env)),
+	)
+
+	if runAs != nil {
+		pid, err = launchAsAccount(runAs, simpl.GetSimplWindowsPath(), syscall.EscapeArg(launchPath), launch.Dir, launch.showCommand(), log)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+	} else {
+		// Keep the process handle so readiness can be detected with WaitForInputIdle
+		err = launch.withEnv(func() error {
+			process, err = windows.ShellExecuteExProcess(0, "open", simpl.GetSimplWindowsPath(), syscall.EscapeArg(launchPath), launch.Dir, launch.showCommand())
+			return err
+		})
+		if err != nil {
+			log.Error("ShellExecuteEx failed", slog.Any("error", err))
+			return nil, 0, nil, fmt.Errorf("error opening file: %w", err)
+		}
+
+		pid = process.Pid
 	}
 
 	log.Info("SIMPL Windows process started", slog.Uint64("pid", uint64(pid)))
 
 	// Start background window monitor with the exact PID we just launched
-	stopMonitor := simplClient.StartMonitoring(pid)
+	stopMonitor := simplClient.StartMonitoring(ctx, pid)
 	log.Debug("Background window monitor started")
 
 	// Return cleanup function that stops monitor
 	cleanup = func() {
 		stopMonitor()
+
+		if process != nil {
+			confirmExit(simplClient, process, log)
+			process.Close()
+		}
 	}
 
-	return 0, pid, cleanup, nil
+	return process, pid, cleanup, nil
+}
+
+// confirmExit waits for the SIMPL Windows process to exit once it has been closed,
+// terminating it if it is still running
+func confirmExit(simplClient *simpl.Client, process *windows.Process, log logger.LoggerInterface) {
+	code, exited, err := process.WaitForExit(timeouts.ProcessExitTimeout)
+	if err != nil {
+		log.Debug("Could not wait for SIMPL Windows to exit", slog.Any("error", err))
+		return
+	}
+
+	if !exited {
+		log.Warn("SIMPL Windows is still running after cleanup, terminating it", slog.Uint64("pid", uint64(process.Pid)))
+		simplClient.ForceCleanup(0, process.Pid)
+
+		if code, exited, err = process.WaitForExit(timeouts.ProcessExitTimeout); err != nil || !exited {
+			log.Warn("SIMPL Windows did not exit", slog.Uint64("pid", uint64(process.Pid)))
+			return
+		}
+	}
+
+	log.Debug("SIMPL Windows exited", slog.Uint64("pid", uint64(process.Pid)), slog.String("exitCode", fmt.Sprintf("0x%X", uint32(code))))
 }
 
 // setupSignalHandlers configures console control and interrupt signal handlers
@@ -196,11 +374,7 @@ func setupSignalHandlers(ctx *ExecutionContext) {
 			slog.Uint64("code", uint64(ctrlType)),
 		)
 
-		ctx.log.Info("Cleaning up after console control event")
-		ctx.simplClient.ForceCleanup(ctx.simplHwnd, ctx.simplPid)
-		ctx.log.Debug("Cleanup completed, exiting")
-
-		ctx.exitFunc(130)
+		ctx.abort("Cleaning up after console control event")
 		return 1
 	})
 
@@ -211,38 +385,120 @@ func setupSignalHandlers(ctx *ExecutionContext) {
 	go func() {
 		sig := <-sigChan
 		ctx.log.Debug("Received signal", slog.Any("signal", sig))
-		ctx.log.Info("Interrupt signal received, starting cleanup")
+		ctx.abort("Interrupt signal received, starting cleanup")
+	}()
+}
 
+// registerAbortHotkey lets an operator abort the run from any window with the
+// --abort-key chord. Registration failures are logged and otherwise ignored.
+func registerAbortHotkey(ctx *ExecutionContext, chord keychord.Chord) (stop func()) {
+	if chord.IsZero() {
+		return func() {}
+	}
+
+	stop, err := windows.RegisterGlobalHotkey(chord, func() {
+		ctx.abort("Abort hotkey pressed, starting cleanup")
+	})
+	if err != nil {
+		ctx.log.Warn("Abort hotkey unavailable", slog.String("chord", chord.String()), slog.Any("error", err))
+		return func() {}
+	}
+
+	ctx.log.Info(fmt.Sprintf("Press %s to abort", strings.ToUpper(chord.String())))
+	return stop
+}
+
+// preventSleep keeps the system awake until the returned function is called, so
+// aggressive power settings cannot suspend a compile and cause bogus timeouts.
+// Failures are logged and otherwise ignored.
+func preventSleep(log logger.LoggerInterface) (restore func()) {
+	restore, err := windows.PreventSleep()
+	if err != nil {
+		log.Warn("Could not prevent system sleep", slog.Any("error", err))
+		return func() {}
+	}
+
+	log.Debug("System sleep prevented while running")
+	return restore
+}
+
+// setInstance records the SIMPL Windows instance the current attempt is using
+func (ctx *ExecutionContext) setInstance(simplClient *simpl.Client, pid uint32) {
+	ctx.simplClient = simplClient
+	ctx.simplPid = pid
+	ctx.simplHwnd = 0
+}
+
+// forceCleanup closes the current SIMPL Windows instance, if one has been launched
+func (ctx *ExecutionContext) forceCleanup() {
+	if ctx.simplClient != nil {
 		ctx.simplClient.ForceCleanup(ctx.simplHwnd, ctx.simplPid)
+	}
+}
 
-		ctx.log.Debug("Cleanup completed, exiting")
-		ctx.exitFunc(130)
-	}()
+// abort closes SIMPL Windows and exits with the interrupted exit code
+func (ctx *ExecutionContext) abort(reason string) {
+	ctx.log.Info(reason)
+	ctx.forceCleanup()
+
+	ctx.log.Debug("Cleanup completed, exiting")
+	ctx.exitFunc(130)
 }
 
-// waitForWindowReady waits for SIMPL window to appear and become responsive
-func waitForWindowReady(simplClient *simpl.Client, pid uint32, log logger.LoggerInterface) (uintptr, error) {
+// waitForWindowReady waits for SIMPL window to appear and become ready for input,
+// recording the time spent in timing. With the process handle, readiness comes from
+// WaitForInputIdle; without it, or if that fails, from WM_NULL polling and a settling delay.
+// If runCtx is canceled first, SIMPL Windows is terminated and the cause is returned.
+func waitForWindowReady(
+	runCtx context.Context,
+	simplClient *simpl.Client,
+	process *windows.Process,
+	pid uint32,
+	log logger.LoggerInterface,
+	timing *compiler.TimingBreakdown,
+) (uintptr, error) {
 	log.Info("Waiting for SIMPL Windows to fully launch...")
+	start := time.Now()
+
+	hwnd, found := simplClient.WaitForAppearContext(runCtx, pid, timeouts.WindowAppearTimeout)
+	if !found && runCtx.Err() != nil {
+		simplClient.ForceCleanup(0, pid)
+		return 0, context.Cause(runCtx)
+	}
 
-	hwnd, found := simplClient.WaitForAppear(pid, timeouts.WindowAppearTimeout)
 	if !found {
 		log.Error("Timeout waiting for window to appear after 3 minutes")
 		log.Info("Forcing SIMPL Windows to terminate due to timeout")
 		simplClient.ForceCleanup(0, pid)
-		return 0, fmt.Errorf("timed out waiting for SIMPL Windows window to appear after 3 minutes")
+		return 0, fmt.Errorf("timed out after %s: %w", timeouts.WindowAppearTimeout, compiler.ErrWindowNotFound)
 	}
 
 	log.Debug("Window appeared", slog.Uint64("hwnd", uint64(hwnd)))
 
+	if process != nil {
+		err := process.WaitForInputIdle(timeouts.WindowReadyTimeout)
+		if err == nil {
+			timing.WindowAppear = time.Since(start)
+			log.Debug("SIMPL Windows is idle and ready for input")
+			return hwnd, nil
+		}
+
+		log.Debug("WaitForInputIdle failed, falling back to polling", slog.Any("error", err))
+	}
+
 	// Wait for the window to be fully ready and responsive
 	if !simplClient.WaitForReady(hwnd, timeouts.WindowReadyTimeout) {
 		log.Error("Window not responding properly")
 		return 0, fmt.Errorf("window appeared but is not responding properly")
 	}
 
+	timing.WindowAppear = time.Since(start)
+
 	// Small extra delay to allow UI to finish settling
 	log.Info("Waiting a few extra seconds for UI to settle...")
+	start = time.Now()
 	time.Sleep(timeouts.UISettlingDelay)
+	timing.UISettle = time.Since(start)
 
 	return hwnd, nil
 }
@@ -252,20 +508,87 @@ func runCompilation(params CompilationParams) (*compiler.CompileResult, error) {
 	comp := compiler.NewCompiler(params.Logger)
 
 	result, err := comp.Compile(compiler.CompileOptions{
-		FilePath:     params.FilePath,
-		RecompileAll: params.Config.RecompileAll,
-		Hwnd:         params.Hwnd,
-		SimplPid:     params.Pid,
-		SimplPidPtr:  params.PidPtr,
+		FilePath:           params.FilePath,
+		RecompileAll:       params.Config.RecompileAll,
+		WarningsAsErrors:   params.Config.WarningsAsErrors,
+		CompileKey:         params.CompileKey,
+		RecompileAllKey:    params.RecompileAllKey,
+		Backend:            params.Config.Backend,
+		MessageInput:       params.MessageInput,
+		Hwnd:               params.Hwnd,
+		SimplPid:           params.Pid,
+		SimplPidPtr:        params.PidPtr,
+		Events:             params.Events,
+		SaveFirst:          params.Config.SaveFirst,
+		AutoRespond:        params.AutoRespond,
+		AutoRecompileAll:   params.Config.AutoRecompileAll,
+		Hints:              params.Hints,
+		CompilationTimeout: params.Timeout,
+		Exited:             params.Exited,
+
+		SavePrompt:         params.Config.SavePrompt,
+		CloseConfirmation:  params.Config.CloseConfirmation,
+		CaptureTranscripts: params.Config.Transcripts,
+		OnStageChange: func(from, to compiler.Stage) {
+			if to == compiler.StageTriggered && params.OnTrigger != nil {
+				params.OnTrigger()
+			}
+		},
 	})
 	if err != nil {
+		// Keep the partial result (counts, messages) for reporting
 		params.Logger.Error("Compilation failed", slog.Any("error", err))
-		return nil, err
+		return result, err
 	}
 
 	return result, nil
 }
 
+// newEventStream returns the live event stream selected by --events, or nil when disabled
+func newEventStream(cfg *Config, redactor *redact.Redactor) *eventstream.Stream {
+	if cfg.Events == "" {
+		return nil
+	}
+
+	return eventstream.NewStream(os.Stdout).WithRedactor(redactor)
+}
+
+// streamWindowEvents forwards every window event seen by the monitor, and every
+// dialog update, to the stream and returns a function that stops forwarding
+func streamWindowEvents(stream *eventstream.Stream, simplClient *simpl.Client) func() {
+	if stream == nil {
+		return func() {}
+	}
+
+	stopEvents := simplClient.Events().Observe(func(ev windows.WindowEvent) {
+		stream.Window(windowInfo(ev))
+	})
+
+	stopUpdates := simplClient.Updates().Observe(func(ev windows.WindowEvent) {
+		stream.WindowUpdated(windowInfo(ev))
+	})
+
+	return func() {
+		stopEvents()
+		stopUpdates()
+	}
+}
+
+// windowInfo converts a monitor event for the event stream
+func windowInfo(ev windows.WindowEvent) eventstream.WindowInfo {
+	return eventstream.WindowInfo{
+		Title:   ev.Title,
+		Class:   ev.Class,
+		Hwnd:    ev.Hwnd,
+		Pid:     ev.Pid,
+		Parent:  ev.Parent,
+		Kind:    ev.Kind(),
+		Rect:    [4]int32{ev.Rect.Left, ev.Rect.Top, ev.Rect.Right, ev.Rect.Bottom},
+		Buttons: ev.Buttons,
+		Texts:   ev.Texts,
+	}
+}
+
 // displayCompilationResults shows the compilation summary to the user
 func displayCompilationResults(result *compiler.CompileResult, log logger.LoggerInterface) {
 	log.Info("Compilation complete",
@@ -274,12 +597,67 @@ func displayCompilationResults(result *compiler.CompileResult, log logger.Logger
 		slog.Int("notices", result.Notices),
 		slog.String("compileTime", fmt.Sprintf("%.2fs", result.CompileTime)),
 	)
+
+	if result.Mode == compiler.ModeRecompileAllRetry {
+		log.Info("Result is from Recompile All, retried after a database change")
+	}
+
+	log.Debug("Timing breakdown", result.Timing.LogAttrs()...)
+
+	for _, d := range result.Timing.Dialogs {
+		log.Debug("Dialog timing",
+			slog.String("title", d.Title),
+			slog.String("after", d.At.Round(time.Millisecond).String()),
+		)
+	}
+
+	if len(result.Stats) > 0 {
+		log.Debug("Compile statistics", slog.Any("stats", result.Stats))
+	}
+
+	for _, t := range result.DialogTranscripts {
+		log.Debug("Dialog transcript",
+			slog.String("title", t.Title),
+			slog.String("hwnd", fmt.Sprintf("0x%X", t.Hwnd)),
+			slog.Any("controls", t.Controls),
+		)
+	}
+}
+
+// timingData converts a timing breakdown to milliseconds for the event stream
+func timingData(t compiler.TimingBreakdown) map[string]any {
+	dialogs := make([]map[string]any, 0, len(t.Dialogs))
+	for _, d := range t.Dialogs {
+		dialogs = append(dialogs, map[string]any{
+			"title": d.Title,
+			"atMs":  d.At.Milliseconds(),
+		})
+	}
+
+	return map[string]any{
+		"launchMs":               t.Launch.Milliseconds(),
+		"windowAppearMs":         t.WindowAppear.Milliseconds(),
+		"uiSettleMs":             t.UISettle.Milliseconds(),
+		"keystrokeToCompilingMs": t.KeystrokeToCompiling.Milliseconds(),
+		"compileMs":              t.Compile.Milliseconds(),
+		"dialogHandlingMs":       t.DialogHandling.Milliseconds(),
+		"cleanupMs":              t.Cleanup.Milliseconds(),
+		"totalMs":                t.Total().Milliseconds(),
+		"dialogs":                dialogs,
+	}
 }
 
 // Execute runs the provided command with the given arguments.
-func Execute(cmd *cobra.Command, args []string) error {
+func Execute(cmd *cobra.Command, args []string) (err error) {
 	cfg := NewConfigFromFlags(cmd)
 
+	// An elevated relaunch sends its output back to the console of the instance that started it
+	if cfg.Handoff != "" {
+		if err := redirectToHandoff(cfg.Handoff); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+		}
+	}
+
 	if err := handleLogsFlag(cfg, os.Exit); err != nil {
 		return err
 	}
@@ -288,7 +666,141 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("file path required")
 	}
 
-	log, err := initializeLogger(cfg)
+	if err := eventstream.ValidateFormat(cfg.Events); err != nil {
+		return err
+	}
+
+	redactMode, err := redact.ParseMode(cfg.Redact)
+	if err != nil {
+		return err
+	}
+
+	reportSpecs, err := parseReportSpecs(cfg.ReportValues(), cfg.Events)
+	if err != nil {
+		return err
+	}
+
+	compileKey, recompileKey, err := cfg.KeyChords()
+	if err != nil {
+		return err
+	}
+
+	abortKey, err := cfg.AbortChord()
+	if err != nil {
+		return err
+	}
+
+	autoRespond, err := cfg.AutoRespondPolicy()
+	if err != nil {
+		return err
+	}
+
+	email, err := cfg.EmailSettings()
+	if err != nil {
+		return err
+	}
+
+	kb, err := cfg.HintsKB()
+	if err != nil {
+		return err
+	}
+
+	launch, err := cfg.SimplLaunch()
+	if err != nil {
+		return err
+	}
+
+	if err := compiler.ValidateBackend(cfg.Backend); err != nil {
+		return err
+	}
+
+	if err := cfg.Polling().Validate(); err != nil {
+		return err
+	}
+
+	monitorFilter, err := cfg.MonitorFilter()
+	if err != nil {
+		return err
+	}
+
+	timeoutCurve, err := cfg.CompileTimeoutCurve()
+	if err != nil {
+		return err
+	}
+
+	tempPatterns, err := cfg.TempCleanupPatterns()
+	if err != nil {
+		return err
+	}
+
+	if cfg.MaxRuntime < 0 {
+		return fmt.Errorf("--max-runtime must not be negative")
+	}
+
+	if cfg.WaitForLock < 0 {
+		return fmt.Errorf("--wait-for-lock must not be negative")
+	}
+
+	if cfg.CrashRetries < 0 {
+		return fmt.Errorf("--crash-retries must not be negative")
+	}
+
+	if err := compiler.ValidateSavePrompt(cfg.SavePrompt); err != nil {
+		return err
+	}
+
+	if err := compiler.ValidateCloseConfirmation(cfg.CloseConfirmation); err != nil {
+		return err
+	}
+
+	if cfg.StageLocal && cfg.Sandbox {
+		return fmt.Errorf("--stage-local and --sandbox cannot be used together")
+	}
+
+	if err := validateIfRunning(cfg.IfRunning); err != nil {
+		return err
+	}
+
+	if err := validateIfInterfering(cfg.IfInterfering); err != nil {
+		return err
+	}
+
+	if err := validateUpdatePrompts(cfg.UpdatePrompts); err != nil {
+		return err
+	}
+
+	if cfg.RunAs != "" {
+		if _, _, err := parseRunAs(cfg.RunAs); err != nil {
+			return err
+		}
+	}
+
+	if cfg.IfRunning == ifRunningAttach && (cfg.StageLocal || cfg.Sandbox) {
+		return fmt.Errorf("--if-running attach cannot be used with --stage-local or --sandbox")
+	}
+
+	if cfg.Reproducible && (cfg.StageLocal || cfg.Sandbox || cfg.OutputDir != "" || cfg.IfRunning == ifRunningAttach) {
+		return fmt.Errorf("--verify-reproducible cannot be used with --stage-local, --sandbox, --output-dir or --if-running attach")
+	}
+
+	outputDir, err := resolveOutputDir(cfg, args[0], time.Now())
+	if err != nil {
+		return err
+	}
+
+	// Register the program path up front so it is hidden even if it contains spaces
+	redactor := redact.New(redactMode)
+	redactor.AddPath(args[0])
+
+	if abs, err := filepath.Abs(args[0]); err == nil {
+		redactor.AddPath(abs)
+	}
+
+	if outputDir != "" {
+		redactor.AddPath(outputDir)
+	}
+
+	log, err := initializeLogger(cfg, redactor)
 	if err != nil {
 		return err
 	}
@@ -298,9 +810,61 @@ func Execute(cmd *cobra.Command, args []string) error {
 	log.Debug("Starting smpc", slog.Any("args", args))
 	log.Debug("Flags set",
 		slog.Bool("verbose", cfg.Verbose),
+		slog.Int("verbosity", cfg.Verbosity),
 		slog.Bool("recompileAll", cfg.RecompileAll),
+		slog.Bool("autoRecompileAll", cfg.AutoRecompileAll),
+		slog.Bool("warningsAsErrors", cfg.WarningsAsErrors),
+		slog.Bool("preferNative", cfg.PreferNative),
+		slog.String("backend", cfg.Backend),
+		slog.Bool("stageLocal", cfg.StageLocal),
+		slog.Bool("sandbox", cfg.Sandbox),
+		slog.Bool("saveFirst", cfg.SaveFirst),
+		slog.String("savePrompt", cfg.SavePrompt),
+		slog.String("closeConfirmation", cfg.CloseConfirmation),
+		slog.String("ifRunning", cfg.IfRunning),
+		slog.String("ifInterfering", cfg.IfInterfering),
+		slog.String("runAs", cfg.RunAs),
+		slog.String("outputDir", outputDir),
+		slog.Bool("verifyReproducible", cfg.Reproducible),
+		slog.String("events", cfg.Events),
+		slog.String("redact", cfg.Redact),
 	)
 
+	// A non-elevated instance hands the whole run to its elevated relaunch, which
+	// enforces the limit and can clean up what it started. Diagnostics are left to the
+	// relaunch too: this instance exits without stopping them, and the relaunch would
+	// find the --pprof address taken and the --trace file already open.
+	elevated := windows.IsElevated()
+
+	maxRuntimeLimit := cfg.MaxRuntime
+	if !elevated {
+		maxRuntimeLimit = 0
+	}
+
+	deadline := startMaxRuntime(maxRuntimeLimit, log, os.Exit)
+	defer deadline.stop()
+
+	stopDiagnostics := func() {}
+	if elevated {
+		stopDiagnostics, err = startDiagnostics(cfg, log)
+		if err != nil {
+			return err
+		}
+	}
+
+	defer stopDiagnostics()
+
+	stream := newEventStream(cfg, redactor)
+	defer func() {
+		data := map[string]any{"success": err == nil}
+		if err != nil {
+			data["error"] = err.Error()
+			data["reason"] = failureReason(err)
+		}
+
+		stream.Lifecycle(eventstream.EventExited, data)
+	}()
+
 	// Recover from panics and log them
 	defer func() {
 		if r := recover(); r != nil {
@@ -314,6 +878,10 @@ func Execute(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
+	if err := applySimplPath(cfg); err != nil {
+		return err
+	}
+
 	// Validate SIMPL Windows installation before checking elevation
 	if err := simpl.ValidateSimplWindowsInstallation(); err != nil {
 		log.Error("SIMPL Windows installation check failed", slog.Any("error", err))
@@ -322,63 +890,385 @@ func Execute(cmd *cobra.Command, args []string) error {
 
 	log.Debug("SIMPL Windows installation validated", slog.String("path", simpl.GetSimplWindowsPath()))
 
+	toolchain := simpl.DetectToolchain()
+	log.Info("Crestron toolchain", toolchain.LogAttrs()...)
+
 	// Validate file path before requesting elevation
 	absPath, err := validateAndResolvePath(args[0], log)
 	if err != nil {
 		return err
 	}
 
-	if err := ensureElevated(log); err != nil {
+	if err := validateParallelSimplPaths(cfg, absPath); err != nil {
 		return err
 	}
 
-	simplClient := simpl.NewClient(log)
-	_, pid, cleanup, err := launchSIMPLWindows(simplClient, absPath, log)
+	defer preventSleep(log)()
+
+	if info, err := os.Stat(absPath); err == nil && info.IsDir() {
+		results, err := buildProject(cfg, absPath, deadline, log)
+		if len(results) > 0 {
+			printSummaryTable(consoleOutput(cfg), results)
+			writeReports(reportSpecs, results, redactor, log)
+			sendNotifications(cfg, email, results, redactor, log)
+		}
+
+		return err
+	}
+
+	if cfg.Reproducible {
+		// Elevate once here so the builds do not each prompt
+		if err := ensureElevated(log); err != nil {
+			return err
+		}
+
+		// Held for both builds, so no other instance compiles in between
+		release, err := acquireGUILock(cfg, absPath, log)
+		if err != nil {
+			return err
+		}
+
+		defer release()
+
+		return verifyReproducible(cfg, absPath, deadline, log)
+	}
+
+	if err := preflight(absPath, cfg.Sandbox, defaultPreflightChecks, log); err != nil {
+		return err
+	}
+
+	caps, native := probeNativeCompile(cfg, log)
+
+	// Runs after SIMPL Windows has been closed by the deferred cleanups below
+	compilePath, finishStaging, err := prepareProgram(cfg, absPath, native, func() error { return ensureElevated(log) }, redactor, log)
 	if err != nil {
 		return err
 	}
 
-	defer cleanup()
+	defer func() { finishStaging(err == nil) }()
 
-	// Create execution context to hold state for signal handlers
-	ctx := &ExecutionContext{
-		simplPid:    pid,
-		log:         log,
-		simplClient: simplClient,
-		exitFunc:    os.Exit,
+	// Runs after SIMPL Windows has closed and before the workspace is removed
+	finishOutputs, err := watchOutputs(outputDir, compilePath, log)
+	if err != nil {
+		return err
 	}
 
-	setupSignalHandlers(ctx)
+	defer func() { finishOutputs(err == nil) }()
 
-	hwnd, err := waitForWindowReady(simplClient, pid, log)
+	// Runs before the outputs are collected, so scratch files are not among them
+	finishTemp, err := watchTempFiles(tempPatterns, compilePath, log)
 	if err != nil {
 		return err
 	}
 
-	// Store hwnd in context for signal handlers and cleanup
-	ctx.simplHwnd = hwnd
-	log.Debug("Stored hwnd in execution context", slog.Uint64("hwnd", uint64(hwnd)))
+	defer finishTemp()
 
-	defer simplClient.Cleanup(hwnd, pid)
+	var result *compiler.CompileResult
 
-	result, err := runCompilation(CompilationParams{
-		FilePath: absPath,
-		Hwnd:     hwnd,
-		Pid:      pid,
-		PidPtr:   &ctx.simplPid,
-		Config:   cfg,
-		Logger:   log,
-	})
+	// Taken before compiling so the outputs cannot affect the dirty flag
+	revision := sourceRevision(absPath, log)
+
+	runStart := time.Now()
+	defer func() {
+		results := []report.FileResult{newFileResult(absPath, result, err, time.Since(runStart))}
+		results[0].Git = revision
+		annotateHints(results, kb)
+		printSummaryTable(consoleOutput(cfg), results)
+		writeReports(reportSpecs, results, redactor, log)
+		sendNotifications(cfg, email, results, redactor, log)
+
+		if !cfg.NoHistory {
+			recordHistory(results, time.Now(), redactor, log)
+		}
+	}()
+
+	compileTimeout := scaledCompileTimeout(timeoutCurve, compilePath, log)
+
+	if native {
+		started := startedData(absPath, cfg, revision, toolchain)
+		started["native"] = true
+		stream.Lifecycle(eventstream.EventStarted, started)
+		stream.Lifecycle(eventstream.EventCompileStarted, nil)
+
+		result, err = runNativeCompilation(compilePath, caps, cfg, compileTimeout, log)
+		if err != nil {
+			log.Error("Compilation failed", slog.Any("error", err))
+		}
+
+		if result == nil {
+			return err
+		}
+
+		return finishCompilation(result, err, compilePath, runStart, stream, redactor, log)
+	}
+
+	// Taken before looking for running instances, which may belong to the holder
+	releaseLock, err := acquireGUILock(cfg, absPath, log)
 	if err != nil {
 		return err
 	}
 
+	defer releaseLock()
+
+	stream.Lifecycle(eventstream.EventStarted, startedData(absPath, cfg, revision, toolchain))
+
+	runAs, err := resolveRunAs(cfg.RunAs, os.Getenv, windows.ReadGenericCredential)
+	if err != nil {
+		return err
+	}
+
+	attachPid, err := checkRunningInstances(cfg.IfRunning, compilePath, simpl.NewClient(log), log)
+	if err != nil {
+		return err
+	}
+
+	if attachPid == 0 {
+		handleInterferingApps(cfg.IfInterfering, simpl.FindInterferingApps, timeouts.InterferingAppsTimeout, clock.New(), log)
+	}
+
+	// Execution context holding the running instance for the signal handlers, updated
+	// by each attempt
+	ctx := &ExecutionContext{
+		log:      log,
+		exitFunc: os.Exit,
+	}
+
+	setupSignalHandlers(ctx)
+
+	defer deadline.onExpiry(ctx.forceCleanup)()
+
+	stopAbortHotkey := registerAbortHotkey(ctx, abortKey)
+	defer stopAbortHotkey()
+
+	// launchAndCompile runs one SIMPL Windows instance, attached to or launched, through
+	// the compile. SIMPL Windows has been closed by the time it returns.
+	launchAndCompile := func(attachPid uint32) (*compiler.CompileResult, error) {
+		var timing compiler.TimingBreakdown
+
+		// A new client per instance, so events from a crashed one are not replayed
+		simplClient := simpl.NewClient(log)
+		simplClient.SetPolling(cfg.Polling())
+		simplClient.SetMonitorFilter(monitorFilter)
+		simplClient.SetDialogUpdates(cfg.DialogUpdates)
+
+		launchStart := time.Now()
+		pid, cleanup := attachPid, func() {}
+
+		var (
+			process *windows.Process
+			err     error
+		)
+
+		if attachPid != 0 {
+			cleanup = simplClient.StartMonitoring(cmd.Context(), attachPid)
+		} else if process, pid, cleanup, err = launchSIMPLWindows(cmd.Context(), simplClient, compilePath, launch, runAs, log); err != nil {
+			return nil, err
+		}
+
+		timing.Launch = time.Since(launchStart)
+
+		defer cleanup()
+
+		// Notice SIMPL Windows exiting mid-compile at once, rather than by dialog timeouts
+		var exited <-chan int
+		if process != nil {
+			var stopWatch func()
+			exited, stopWatch = process.Watch(cmd.Context(), timeouts.StatePollingInterval)
+			defer stopWatch()
+		}
+
+		stream.Lifecycle(eventstream.EventSimplLaunched, map[string]any{"pid": pid})
+
+		stopStreaming := streamWindowEvents(stream, simplClient)
+		defer stopStreaming()
+
+		ctx.setInstance(simplClient, pid)
+
+		// Licensing dialogs shown before the main window stop the launch through launchCtx
+		launchCtx, stopLaunch := context.WithCancelCause(cmd.Context())
+		defer stopLaunch(nil)
+
+		api := windows.NewWindowsAPI(log)
+		startup := newStartupDialogs(api, api, api, autoRespond, cfg.UpdatePrompts, stopLaunch, log)
+		stopStartup := startup.watch(simplClient.Events().SubscribeWithHistory(windows.DefaultSubscriptionBuffer))
+
+		hwnd, err := waitForWindowReady(launchCtx, simplClient, process, pid, log, &timing)
+		stopStartup()
+
+		if err == nil && launchCtx.Err() != nil {
+			simplClient.ForceCleanup(hwnd, pid)
+			err = context.Cause(launchCtx)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// An instance attached to is the user's own, so it is left where it is
+		if attachPid == 0 {
+			launch.place(hwnd, log)
+		}
+
+		// Store hwnd in context for signal handlers and cleanup
+		ctx.simplHwnd = hwnd
+		log.Debug("Stored hwnd in execution context", slog.Uint64("hwnd", uint64(hwnd)))
+
+		defer simplClient.Cleanup(hwnd, pid)
+
+		stream.Lifecycle(eventstream.EventWindowReady, map[string]any{"hwnd": fmt.Sprintf("0x%X", hwnd)})
+		stream.Lifecycle(eventstream.EventCompileStarted, nil)
+
+		result, err := runCompilation(CompilationParams{
+			FilePath: compilePath,
+			Hwnd:     hwnd,
+			Pid:      pid,
+			PidPtr:   &ctx.simplPid,
+			Config:   cfg,
+			Logger:   log,
+			Events:   simplClient.Events(),
+
+			// Dialogs are about to appear, so stop any backed-off polling
+			OnTrigger: simplClient.PollFast,
+
+			CompileKey:      compileKey,
+			RecompileAllKey: recompileKey,
+			MessageInput:    launch.background() && attachPid == 0,
+			AutoRespond:     autoRespond,
+			Hints:           kb,
+			Timeout:         compileTimeout,
+			Exited:          exited,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		// Compile measures its own phases; add the launch phases measured here
+		result.Timing.Launch = timing.Launch
+		result.Timing.WindowAppear = timing.WindowAppear
+		result.Timing.UISettle = timing.UISettle
+
+		return result, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err = launchAndCompile(attachPid)
+		if !errors.Is(err, compiler.ErrCompilerCrashed) || attempt > cfg.CrashRetries {
+			break
+		}
+
+		log.Warn("SIMPL Windows crashed, retrying in a new instance", slog.Int("retry", attempt), slog.Int("retries", cfg.CrashRetries))
+
+		// The crashed instance is gone, so later attempts always launch their own
+		attachPid = 0
+	}
+
+	if result == nil {
+		return err
+	}
+
+	return finishCompilation(result, err, compilePath, runStart, stream, redactor, log)
+}
+
+// failureReason classifies err for the event stream, so consumers can branch on the
+// kind of failure instead of matching the message
+func failureReason(err error) string {
+	var compileErrs compiler.ErrCompileErrors
+
+	switch {
+	case errors.As(err, &compileErrs):
+		return "compile_errors"
+	case errors.Is(err, compiler.ErrIncompleteSymbols):
+		return "incomplete_symbols"
+	case errors.Is(err, compiler.ErrSaveFailed):
+		return "save_failed"
+	case errors.Is(err, compiler.ErrSavePromptAborted):
+		return "save_prompt_aborted"
+	case errors.Is(err, compiler.ErrCompileTimeout):
+		return "timeout"
+	case errors.Is(err, compiler.ErrForegroundLost):
+		return "foreground_lost"
+	case errors.Is(err, compiler.ErrWindowNotFound):
+		return "window_not_found"
+	case errors.Is(err, compiler.ErrCancelled):
+		return "cancelled"
+	case errors.Is(err, compiler.ErrMissingArtifacts):
+		return "missing_artifacts"
+	case errors.Is(err, compiler.ErrCompilerCrashed):
+		return "compiler_crashed"
+	case errors.Is(err, compiler.ErrNotLicensed):
+		return "not_licensed"
+	case errors.Is(err, guilock.ErrLocked):
+		return "locked"
+	default:
+		return "error"
+	}
+}
+
+// finishCompilation publishes and displays the results of a compilation that ran to
+// completion, including one that failed with compile errors, then checks that a
+// successful compile actually wrote the compiled program. Any other compileErr is
+// returned as is: its result only records the failure.
+func finishCompilation(
+	result *compiler.CompileResult,
+	compileErr error,
+	compilePath string,
+	compileStart time.Time,
+	stream *eventstream.Stream,
+	redactor *redact.Redactor,
+	log logger.LoggerInterface,
+) error {
+	var compileErrs compiler.ErrCompileErrors
+	if compileErr != nil && !errors.As(compileErr, &compileErrs) {
+		return compileErr
+	}
+
+	data := map[string]any{
+		"errors":      result.Errors,
+		"warnings":    result.Warnings,
+		"notices":     result.Notices,
+		"compileTime": result.CompileTime,
+		"timing":      timingData(result.Timing),
+	}
+
+	if len(result.Stats) > 0 {
+		data["stats"] = result.Stats
+	}
+
+	if result.Mode != "" {
+		data["mode"] = result.Mode
+	}
+
+	if result.DialogTranscripts != nil {
+		data["dialogTranscripts"] = redactTranscripts(result.DialogTranscripts, redactor)
+	}
+
+	if len(result.Audit) > 0 {
+		data["audit"] = redactAudit(result.Audit, redactor)
+	}
+
+	if len(result.SkippedDialogs) > 0 {
+		data["skippedDialogs"] = redactSkippedDialogs(result.SkippedDialogs, redactor)
+	}
+
+	stream.Lifecycle(eventstream.EventCompileDone, data)
+
 	displayCompilationResults(result, log)
 
+	if compileErr != nil {
+		return compileErr
+	}
+
 	if result.HasErrors {
 		log.Error("Compilation failed with errors")
-		return fmt.Errorf("compilation failed with %d error(s)", result.Errors)
+		return compiler.ErrCompileErrors{Count: result.Errors}
+	}
+
+	output, err := compiler.VerifyOutputs(compilePath, compileStart)
+	if err != nil {
+		log.Error("Compilation reported success but produced no output", slog.Any("error", err))
+		return err
 	}
 
+	log.Debug("Verified compiled program", slog.String("path", output))
 	return nil
 }
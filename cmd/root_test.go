@@ -2,15 +2,22 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/Norgate-AV/smpc/internal/compiler"
+	"github.com/Norgate-AV/smpc/internal/eventstream"
 	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/poll"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
 	"github.com/Norgate-AV/smpc/internal/version"
 )
 
@@ -20,6 +27,54 @@ func resetFlags() {
 	_ = RootCmd.Flags().Set("verbose", "false")
 	_ = RootCmd.Flags().Set("recompile-all", "false")
 	_ = RootCmd.Flags().Set("logs", "false")
+	_ = RootCmd.Flags().Set("warnings-as-errors", "false")
+	_ = RootCmd.Flags().Set("compile-key", "")
+	_ = RootCmd.Flags().Set("prefer-native", "false")
+	_ = RootCmd.Flags().Set("backend", "gui")
+	_ = RootCmd.Flags().Set("stage-local", "false")
+	_ = RootCmd.Flags().Set("sandbox", "false")
+	_ = RootCmd.Flags().Set("if-running", "ignore")
+	_ = RootCmd.Flags().Set("if-interfering", "warn")
+	_ = RootCmd.Flags().Set("runas", "")
+	_ = RootCmd.Flags().Set("recompile-key", "")
+	_ = RootCmd.Flags().Set("abort-key", "ctrl+alt+q")
+	_ = RootCmd.Flags().Set("handoff", "")
+	_ = RootCmd.Flags().Set("events", "")
+	_ = RootCmd.Flags().Set("output-dir", "")
+	_ = RootCmd.Flags().Set("output-name", "{program}")
+	_ = RootCmd.Flags().Set("output-version", "")
+	_ = RootCmd.Flags().Set("verify-reproducible", "false")
+	_ = RootCmd.Flags().Set("no-history", "false")
+	_ = RootCmd.Flags().Set("dialog-transcripts", "false")
+	_ = RootCmd.Flags().Set("dialog-updates", "false")
+	_ = RootCmd.Flags().Set("save-first", "false")
+	_ = RootCmd.Flags().Set("auto-recompile-all", "false")
+	_ = RootCmd.Flags().Set("auto-respond", "")
+	_ = RootCmd.Flags().Set("graph", "false")
+	_ = RootCmd.Flags().Set("notify-email", "")
+	_ = RootCmd.Flags().Set("hints", "")
+	_ = RootCmd.Flags().Set("lang", "")
+	_ = RootCmd.Flags().Set("timestamps", "")
+	_ = RootCmd.Flags().Set("notify", "false")
+	_ = RootCmd.Flags().Set("simpl-path", "")
+	_ = RootCmd.Flags().Set("timeout-curve", "")
+	_ = RootCmd.Flags().Set("max-runtime", "0s")
+	_ = RootCmd.Flags().Set("wait-for-lock", "0s")
+	_ = RootCmd.Flags().Set("keep-temp", "false")
+	_ = RootCmd.Flags().Set("crash-retries", "0")
+	_ = RootCmd.Flags().Set("update-prompts", "dismiss")
+	_ = RootCmd.Flags().Set("show-mode", "normal")
+	_ = RootCmd.Flags().Set("json", "false")
+	_ = RootCmd.Flags().Set("simpl-workdir", "")
+	_ = RootCmd.Flags().Set("save-prompt", compiler.AnswerYes)
+	_ = RootCmd.Flags().Set("close-confirmation", compiler.AnswerNo)
+	_ = RootCmd.Flags().Set("no-config", "false")
+	_ = RootCmd.Flags().Set("poll-min", "100ms")
+	_ = RootCmd.Flags().Set("poll-max", "2s")
+	_ = RootCmd.Flags().Set("redact", "")
+	_ = RootCmd.Flags().Set("pprof", "")
+	_ = RootCmd.Flags().Set("trace", "")
+	_ = RootCmd.Flags().Set("verbosity", "0")
 }
 
 // TestValidateArgs_ValidFile tests argument validation with valid .smw file
@@ -60,11 +115,6 @@ func TestValidateArgs_InvalidExtension(t *testing.T) {
 			file:      "test",
 			expectErr: "file must have .smw extension",
 		},
-		{
-			name:      "wrong case extension",
-			file:      "test.SMW",
-			expectErr: "file must have .smw extension",
-		},
 		{
 			name:      "similar extension",
 			file:      "test.smw2",
@@ -321,6 +371,77 @@ func TestRootCmd_Flags(t *testing.T) {
 	}
 }
 
+// TestNewConfigFromFlags_Verbosity tests the -v/-vv/-vvv and --verbose mapping
+func TestNewConfigFromFlags_Verbosity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		args              []string
+		expectedVerbosity int
+		expectedVerbose   bool
+	}{
+		{name: "default", args: []string{}, expectedVerbosity: 0, expectedVerbose: false},
+		{name: "single v", args: []string{"-v"}, expectedVerbosity: 1, expectedVerbose: true},
+		{name: "double v", args: []string{"-vv"}, expectedVerbosity: 2, expectedVerbose: true},
+		{name: "triple v", args: []string{"-vvv"}, expectedVerbosity: 3, expectedVerbose: true},
+		{name: "verbose flag", args: []string{"--verbose"}, expectedVerbosity: 1, expectedVerbose: true},
+		{name: "verbose with vv", args: []string{"-V", "-vv"}, expectedVerbosity: 2, expectedVerbose: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &cobra.Command{Use: "test"}
+			cmd.PersistentFlags().BoolP("verbose", "V", false, "enable verbose output")
+			cmd.PersistentFlags().CountP("verbosity", "v", "increase console verbosity")
+
+			err := cmd.ParseFlags(tt.args)
+			assert.NoError(t, err, "Flag parsing should not error")
+
+			cfg := NewConfigFromFlags(cmd)
+			assert.Equal(t, tt.expectedVerbosity, cfg.Verbosity)
+			assert.Equal(t, tt.expectedVerbose, cfg.Verbose)
+		})
+	}
+}
+
+// TestConfig_Polling tests the --poll-min/--poll-max mapping to adaptive polling settings
+func TestConfig_Polling(t *testing.T) {
+	t.Parallel()
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.PersistentFlags().Duration("poll-min", timeouts.StatePollingInterval, "")
+	cmd.PersistentFlags().Duration("poll-max", timeouts.MaxPollingInterval, "")
+
+	assert.NoError(t, cmd.ParseFlags([]string{"--poll-max", "5s"}))
+
+	polling := NewConfigFromFlags(cmd).Polling()
+	assert.Equal(t, poll.Settings{Min: timeouts.StatePollingInterval, Max: 5 * time.Second, Factor: poll.DefaultFactor}, polling)
+	assert.NoError(t, polling.Validate())
+
+	assert.Error(t, (&Config{PollMin: time.Second, PollMax: time.Millisecond}).Polling().Validate())
+}
+
+// TestConfig_KeyChords tests parsing of the --compile-key/--recompile-key overrides
+func TestConfig_KeyChords(t *testing.T) {
+	t.Parallel()
+
+	compileKey, recompileKey, err := (&Config{}).KeyChords()
+	assert.NoError(t, err)
+	assert.True(t, compileKey.IsZero(), "unset compile key should fall back to the default")
+	assert.True(t, recompileKey.IsZero(), "unset recompile key should fall back to the default")
+
+	compileKey, recompileKey, err = (&Config{CompileKey: "Ctrl+F12", RecompileKey: "ctrl+alt+f12"}).KeyChords()
+	assert.NoError(t, err)
+	assert.Equal(t, "ctrl+f12", compileKey.String())
+	assert.Equal(t, "ctrl+alt+f12", recompileKey.String())
+
+	_, _, err = (&Config{RecompileKey: "ctrl+nope"}).KeyChords()
+	assert.ErrorContains(t, err, "--recompile-key")
+}
+
 // TestRootCmd_InvalidFlag tests behavior with unknown flags
 func TestRootCmd_InvalidFlag(t *testing.T) {
 	resetFlags()
@@ -399,6 +520,39 @@ func TestExecutionContext_ExitFuncInjectable(t *testing.T) {
 	assert.Equal(t, 130, exitCode, "Exit code should be 130")
 }
 
+// TestExecutionContext_AbortExitsWithInterruptCode tests the shared abort path used by
+// signals, console events and the abort hotkey
+func TestExecutionContext_AbortExitsWithInterruptCode(t *testing.T) {
+	t.Parallel()
+
+	var exitCode int
+	ctx := &ExecutionContext{
+		log:         logger.NewNoOpLogger(),
+		simplClient: simpl.NewClient(logger.NewNoOpLogger()),
+		exitFunc:    func(code int) { exitCode = code },
+	}
+
+	ctx.abort("test abort")
+
+	assert.Equal(t, 130, exitCode)
+}
+
+// TestConfig_AbortChord tests parsing of the --abort-key hotkey
+func TestConfig_AbortChord(t *testing.T) {
+	t.Parallel()
+
+	chord, err := (&Config{}).AbortChord()
+	assert.NoError(t, err)
+	assert.True(t, chord.IsZero(), "empty --abort-key disables the hotkey")
+
+	chord, err = (&Config{AbortKey: "ctrl+alt+q"}).AbortChord()
+	assert.NoError(t, err)
+	assert.Equal(t, "ctrl+alt+q", chord.String())
+
+	_, err = (&Config{AbortKey: "hyper+q"}).AbortChord()
+	assert.ErrorContains(t, err, "--abort-key")
+}
+
 // TestExecutionContext_DefaultExitFunc tests that exitFunc defaults to os.Exit
 func TestExecutionContext_DefaultExitFunc(t *testing.T) {
 	t.Parallel()
@@ -450,6 +604,25 @@ func TestValidateAndResolvePath_MissingFile(t *testing.T) {
 	assert.Contains(t, err.Error(), nonExistentFile, "Error should include file path")
 }
 
+// TestValidateAndResolvePath_UnicodeAndLongPath tests non-ASCII names and paths beyond MAX_PATH
+func TestValidateAndResolvePath_UnicodeAndLongPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for len(dir) < 300 {
+		dir = filepath.Join(dir, "Ordner mit Ümlauten – 日本")
+	}
+
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	testFile := filepath.Join(dir, "Prögrämm ☃.smw")
+	assert.NoError(t, os.WriteFile(testFile, []byte("test"), 0o644), "Should create test file")
+
+	absPath, err := validateAndResolvePath(testFile, logger.NewNoOpLogger())
+
+	assert.NoError(t, err, "Should accept unicode and long paths")
+	assert.Equal(t, testFile, absPath)
+}
+
 // TestValidateAndResolvePath_RelativePath tests resolving a relative path
 func TestValidateAndResolvePath_RelativePath(t *testing.T) {
 	t.Parallel()
@@ -512,9 +685,9 @@ func TestEnsureElevated_AlreadyElevated(t *testing.T) {
 	relaunchCalled := false
 
 	isElevated := func() bool { return true }
-	relaunchAsAdmin := func() error {
+	relaunchAsAdmin := func() (int, error) {
 		relaunchCalled = true
-		return nil
+		return 0, nil
 	}
 	exitFunc := func(code int) {
 		exitCalled = true
@@ -537,9 +710,9 @@ func TestEnsureElevated_NotElevated_SuccessfulRelaunch(t *testing.T) {
 	relaunchCalled := false
 
 	isElevated := func() bool { return false }
-	relaunchAsAdmin := func() error {
+	relaunchAsAdmin := func() (int, error) {
 		relaunchCalled = true
-		return nil
+		return 0, nil
 	}
 	exitFunc := func(code int) {
 		exitCode = code
@@ -555,6 +728,23 @@ func TestEnsureElevated_NotElevated_SuccessfulRelaunch(t *testing.T) {
 	assert.Equal(t, 0, exitCode, "Should exit with code 0 after successful relaunch")
 }
 
+// TestEnsureElevated_NotElevated_PropagatesExitCode tests that the elevated instance's
+// exit code becomes this instance's exit code
+func TestEnsureElevated_NotElevated_PropagatesExitCode(t *testing.T) {
+	t.Parallel()
+
+	exitCode := -1
+
+	isElevated := func() bool { return false }
+	relaunchAsAdmin := func() (int, error) { return 1, nil }
+	exitFunc := func(code int) { exitCode = code }
+
+	err := ensureElevatedWithDeps(logger.NewNoOpLogger(), isElevated, relaunchAsAdmin, exitFunc)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, exitCode, "Should exit with the elevated instance's exit code")
+}
+
 // TestEnsureElevated_NotElevated_RelaunchFails tests relaunch failure handling
 func TestEnsureElevated_NotElevated_RelaunchFails(t *testing.T) {
 	t.Parallel()
@@ -565,9 +755,9 @@ func TestEnsureElevated_NotElevated_RelaunchFails(t *testing.T) {
 	relaunchErr := fmt.Errorf("failed to relaunch")
 
 	isElevated := func() bool { return false }
-	relaunchAsAdmin := func() error {
+	relaunchAsAdmin := func() (int, error) {
 		relaunchCalled = true
-		return relaunchErr
+		return 0, relaunchErr
 	}
 	exitFunc := func(code int) {
 		exitCalled = true
@@ -581,3 +771,108 @@ func TestEnsureElevated_NotElevated_RelaunchFails(t *testing.T) {
 	assert.Contains(t, err.Error(), "error relaunching as admin", "Error should mention relaunch failure")
 	assert.ErrorIs(t, err, relaunchErr, "Should wrap the relaunch error")
 }
+
+// closeTrackingLogger counts Close calls and the messages logged after the first
+type closeTrackingLogger struct {
+	*logger.NoOpLogger
+
+	closes     int
+	afterClose int
+}
+
+func (l *closeTrackingLogger) Close() { l.closes++ }
+
+func (l *closeTrackingLogger) Debug(msg string, args ...any) { l.logged() }
+func (l *closeTrackingLogger) Info(msg string, args ...any)  { l.logged() }
+func (l *closeTrackingLogger) Error(msg string, args ...any) { l.logged() }
+
+func (l *closeTrackingLogger) logged() {
+	if l.closes > 0 {
+		l.afterClose++
+	}
+}
+
+// TestEnsureElevated_NotElevated_ClosesLogOnce tests that the log is released once
+// before relaunching and not written to afterwards, whatever the relaunch outcome
+func TestEnsureElevated_NotElevated_ClosesLogOnce(t *testing.T) {
+	t.Parallel()
+
+	for _, relaunchErr := range []error{nil, fmt.Errorf("failed to relaunch")} {
+		log := &closeTrackingLogger{NoOpLogger: logger.NewNoOpLogger()}
+
+		isElevated := func() bool { return false }
+		relaunchAsAdmin := func() (int, error) {
+			assert.Equal(t, 1, log.closes, "Should release the log before relaunching")
+			return 0, relaunchErr
+		}
+
+		_ = ensureElevatedWithDeps(log, isElevated, relaunchAsAdmin, func(int) {})
+
+		assert.Equal(t, 1, log.closes, "Should close the log once")
+		assert.Zero(t, log.afterClose, "Should not log after closing")
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{compiler.ErrCompileErrors{Count: 2}, "compile_errors"},
+		{compiler.ErrIncompleteSymbols, "incomplete_symbols"},
+		{compiler.ErrSavePromptAborted, "save_prompt_aborted"},
+		{fmt.Errorf("SIMPL Windows stopped responding while saving: %w", compiler.ErrSaveFailed), "save_failed"},
+		{fmt.Errorf("native compile timed out: %w", compiler.ErrCompileTimeout), "timeout"},
+		{fmt.Errorf("wrong window in foreground: %w", compiler.ErrForegroundLost), "foreground_lost"},
+		{fmt.Errorf("timed out: %w", compiler.ErrWindowNotFound), "window_not_found"},
+		{compiler.ErrCancelled, "cancelled"},
+		{fmt.Errorf("no .lpz file: %w", compiler.ErrMissingArtifacts), "missing_artifacts"},
+		{compiler.ErrCompilerCrashed, "compiler_crashed"},
+		{fmt.Errorf("%w: \"Registration\"", compiler.ErrNotLicensed), "not_licensed"},
+		{errors.New("file does not exist"), "error"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, failureReason(tt.err), tt.err.Error())
+	}
+}
+
+func TestFinishCompilation_PublishesFailedCompiles(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	result := &compiler.CompileResult{
+		Errors:        1,
+		HasErrors:     true,
+		ErrorMessages: []string{"ERROR (LGSPLS1000) Missing symbol"},
+	}
+
+	err := finishCompilation(result, compiler.ErrCompileErrors{Count: 1}, filepath.Join(t.TempDir(), "program.smw"),
+		time.Now(), eventstream.NewStream(&out), nil, logger.NewNoOpLogger())
+
+	assert.ErrorAs(t, err, &compiler.ErrCompileErrors{})
+	assert.Contains(t, out.String(), `"compile_finished"`)
+	assert.Contains(t, out.String(), `"errors":1`)
+}
+
+func TestFinishCompilation_SkipsUnfinishedCompiles(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	// The compiler records a timeout as one error, but the compile never finished
+	result := &compiler.CompileResult{
+		Errors:        1,
+		HasErrors:     true,
+		ErrorMessages: []string{compiler.ErrCompileTimeout.Error()},
+	}
+
+	err := finishCompilation(result, compiler.ErrCompileTimeout, filepath.Join(t.TempDir(), "program.smw"),
+		time.Now(), eventstream.NewStream(&out), nil, logger.NewNoOpLogger())
+
+	assert.ErrorIs(t, err, compiler.ErrCompileTimeout)
+	assert.NotContains(t, out.String(), `"compile_finished"`)
+}
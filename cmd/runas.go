@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+const (
+	// runAsPasswordEnv holds the --runas password; it takes precedence over Credential Manager
+	runAsPasswordEnv = "SMPC_RUNAS_PASSWORD"
+
+	// runAsCredentialPrefix prefixes the --runas account to form the Credential Manager target
+	runAsCredentialPrefix = "smpc:"
+)
+
+// runAsAccount is the account SIMPL Windows is launched under with --runas
+type runAsAccount struct {
+	Domain   string
+	User     string
+	Password string
+}
+
+// String returns the account as given to --runas, without the password
+func (a *runAsAccount) String() string {
+	if a.Domain == "" {
+		return a.User
+	}
+
+	return a.Domain + `\` + a.User
+}
+
+// parseRunAs splits a DOMAIN\user, .\user, user@domain or bare user name
+func parseRunAs(spec string) (domain, user string, err error) {
+	domain, user, found := strings.Cut(spec, `\`)
+	if !found {
+		domain, user = "", spec
+	}
+
+	if user == "" || strings.Contains(user, `\`) {
+		return "", "", fmt.Errorf("--runas: invalid account %q (expected DOMAIN\\user)", spec)
+	}
+
+	return domain, user, nil
+}
+
+// resolveRunAs builds the --runas account, reading the password from SMPC_RUNAS_PASSWORD
+// or, failing that, the Credential Manager generic credential "smpc:<account>"
+func resolveRunAs(
+	spec string,
+	getenv func(string) string,
+	readCredential func(target string) (string, error),
+) (*runAsAccount, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	domain, user, err := parseRunAs(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &runAsAccount{Domain: domain, User: user}
+
+	if password := getenv(runAsPasswordEnv); password != "" {
+		account.Password = password
+		return account, nil
+	}
+
+	password, err := readCredential(runAsCredentialPrefix + spec)
+	if err != nil {
+		return nil, fmt.Errorf("--runas: no password in %s and %w", runAsPasswordEnv, err)
+	}
+
+	account.Password = password
+	return account, nil
+}
+
+// launchAsAccount starts SIMPL Windows under the --runas account
+func launchAsAccount(account *runAsAccount, exe, args, cwd string, showCmd int, log logger.LoggerInterface) (uint32, error) {
+	log.Info("Launching SIMPL Windows as another user", slog.String("user", account.String()))
+
+	pid, err := windows.CreateProcessWithLogon(account.Domain, account.User, account.Password, exe, args, cwd, showCmd)
+	if err != nil {
+		return 0, fmt.Errorf("failed to launch SIMPL Windows as %s: %w", account, err)
+	}
+
+	return pid, nil
+}
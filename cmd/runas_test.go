@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRunAs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec       string
+		wantDomain string
+		wantUser   string
+		wantErr    bool
+	}{
+		{spec: `CORP\builder`, wantDomain: "CORP", wantUser: "builder"},
+		{spec: `.\builder`, wantDomain: ".", wantUser: "builder"},
+		{spec: `builder@corp.example`, wantUser: "builder@corp.example"},
+		{spec: `builder`, wantUser: "builder"},
+		{spec: `CORP\`, wantErr: true},
+		{spec: `CORP\x\y`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		domain, user, err := parseRunAs(tt.spec)
+		if tt.wantErr {
+			assert.Error(t, err, tt.spec)
+			continue
+		}
+
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.wantDomain, domain, tt.spec)
+		assert.Equal(t, tt.wantUser, user, tt.spec)
+	}
+}
+
+func TestResolveRunAs(t *testing.T) {
+	t.Parallel()
+
+	noEnv := func(string) string { return "" }
+	withEnv := func(name string) string {
+		if name == runAsPasswordEnv {
+			return "from-env"
+		}
+
+		return ""
+	}
+
+	var requested string
+	credentials := func(target string) (string, error) {
+		requested = target
+		return "from-store", nil
+	}
+
+	account, err := resolveRunAs("", noEnv, credentials)
+	assert.NoError(t, err)
+	assert.Nil(t, account, "--runas not set")
+
+	account, err = resolveRunAs(`CORP\builder`, withEnv, credentials)
+	require.NoError(t, err)
+	assert.Equal(t, &runAsAccount{Domain: "CORP", User: "builder", Password: "from-env"}, account)
+	assert.Empty(t, requested, "environment takes precedence over Credential Manager")
+
+	account, err = resolveRunAs(`CORP\builder`, noEnv, credentials)
+	require.NoError(t, err)
+	assert.Equal(t, "from-store", account.Password)
+	assert.Equal(t, `smpc:CORP\builder`, requested)
+	assert.Equal(t, `CORP\builder`, account.String())
+
+	_, err = resolveRunAs(`CORP\builder`, noEnv, func(string) (string, error) {
+		return "", errors.New("credential not found")
+	})
+	assert.ErrorContains(t, err, runAsPasswordEnv)
+}
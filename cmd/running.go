@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+)
+
+// --if-running policies for SIMPL Windows instances that were already running at startup
+const (
+	ifRunningIgnore = "ignore" // Log them and launch a new instance anyway
+	ifRunningKill   = "kill"   // Terminate them before launching
+	ifRunningAttach = "attach" // Compile in the instance that has the program open
+	ifRunningAbort  = "abort"  // Exit without compiling
+)
+
+// validateIfRunning returns an error if mode is not a supported --if-running policy
+func validateIfRunning(mode string) error {
+	switch mode {
+	case "", ifRunningIgnore, ifRunningKill, ifRunningAttach, ifRunningAbort:
+		return nil
+	default:
+		return fmt.Errorf("unsupported --if-running %q (supported: %s, %s, %s, %s)",
+			mode, ifRunningIgnore, ifRunningKill, ifRunningAttach, ifRunningAbort)
+	}
+}
+
+// handleRunningInstances applies the --if-running policy to SIMPL Windows instances
+// that were running before smpc started, since their dialogs are indistinguishable from
+// ours by title alone. It returns the PID to attach to, or 0 to launch a new instance.
+// Instances in other users' sessions are only reported: their dialogs are on another
+// desktop, and smpc can neither terminate nor attach to them.
+func handleRunningInstances(
+	mode string,
+	programPath string,
+	all []simpl.Instance,
+	terminate func(pid uint32),
+	log logger.LoggerInterface,
+) (uint32, error) {
+	var instances []simpl.Instance
+
+	for _, inst := range all {
+		if !inst.OtherSession {
+			instances = append(instances, inst)
+			continue
+		}
+
+		log.Info("SIMPL Windows is running in another user's session; it does not affect this compile",
+			slog.Uint64("pid", uint64(inst.Pid)),
+			slog.Uint64("session", uint64(inst.Session)),
+		)
+	}
+
+	if len(instances) == 0 {
+		if mode == ifRunningAttach && len(all) > 0 {
+			return 0, fmt.Errorf("SIMPL Windows is only running in other users' sessions, which smpc cannot attach to")
+		}
+
+		return 0, nil
+	}
+
+	for _, inst := range instances {
+		log.Warn("SIMPL Windows is already running",
+			slog.Uint64("pid", uint64(inst.Pid)),
+			slog.Any("programs", inst.Programs),
+		)
+	}
+
+	switch mode {
+	case ifRunningKill:
+		for _, inst := range instances {
+			log.Info("Terminating running SIMPL Windows", slog.Uint64("pid", uint64(inst.Pid)))
+			terminate(inst.Pid)
+		}
+
+		return 0, nil
+
+	case ifRunningAttach:
+		for _, inst := range instances {
+			if inst.HasProgram(programPath) {
+				log.Info("Attaching to running SIMPL Windows", slog.Uint64("pid", uint64(inst.Pid)))
+				return inst.Pid, nil
+			}
+		}
+
+		return 0, fmt.Errorf("no running SIMPL Windows instance has %s open", programPath)
+
+	case ifRunningAbort:
+		return 0, fmt.Errorf("%d SIMPL Windows instance(s) already running; close them or use --if-running kill", len(instances))
+
+	default:
+		log.Info("Launching a new instance anyway; use --if-running to kill, attach or abort")
+		return 0, nil
+	}
+}
+
+// checkRunningInstances finds SIMPL Windows instances already running and applies the
+// --if-running policy to them. Instances that are killed have their dialogs closed too.
+func checkRunningInstances(mode, programPath string, simplClient *simpl.Client, log logger.LoggerInterface) (uint32, error) {
+	instances, err := simpl.FindInstances()
+	if err != nil {
+		log.Warn("Could not check for running SIMPL Windows instances", slog.Any("error", err))
+		return 0, nil
+	}
+
+	return handleRunningInstances(mode, programPath, instances, func(pid uint32) {
+		simplClient.ForceCleanup(0, pid)
+	}, log)
+}
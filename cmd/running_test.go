@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+)
+
+func TestHandleRunningInstances(t *testing.T) {
+	t.Parallel()
+
+	instances := []simpl.Instance{
+		{Pid: 100, Programs: []string{"SIMPL Windows - [Lobby.smw]"}},
+		{Pid: 200, Programs: []string{"SIMPL Windows - [Boardroom.smw]"}},
+	}
+
+	tests := []struct {
+		name       string
+		mode       string
+		program    string
+		wantPid    uint32
+		wantKilled []uint32
+		wantErr    string
+	}{
+		{name: "ignore", mode: "", program: `C:\p\Lobby.smw`},
+		{name: "kill", mode: ifRunningKill, program: `C:\p\Lobby.smw`, wantKilled: []uint32{100, 200}},
+		{name: "attach", mode: ifRunningAttach, program: `C:\p\Boardroom.smw`, wantPid: 200},
+		{name: "attach without match", mode: ifRunningAttach, program: `C:\p\Other.smw`, wantErr: "no running SIMPL Windows instance"},
+		{name: "abort", mode: ifRunningAbort, program: `C:\p\Lobby.smw`, wantErr: "2 SIMPL Windows instance(s) already running"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var killed []uint32
+			pid, err := handleRunningInstances(tt.mode, tt.program, instances, func(pid uint32) {
+				killed = append(killed, pid)
+			}, logger.NewNoOpLogger())
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantPid, pid)
+			assert.Equal(t, tt.wantKilled, killed)
+		})
+	}
+}
+
+func TestHandleRunningInstances_NoneRunning(t *testing.T) {
+	t.Parallel()
+
+	pid, err := handleRunningInstances(ifRunningAbort, `C:\p\Lobby.smw`, nil, nil, logger.NewNoOpLogger())
+	assert.NoError(t, err)
+	assert.Zero(t, pid)
+}
+
+func TestHandleRunningInstances_OtherSession(t *testing.T) {
+	t.Parallel()
+
+	instances := []simpl.Instance{{Pid: 100, Session: 3, OtherSession: true}}
+
+	for _, mode := range []string{ifRunningKill, ifRunningAbort} {
+		pid, err := handleRunningInstances(mode, `C:\p\Lobby.smw`, instances, func(uint32) {
+			t.Fatal("instances in other sessions cannot be terminated")
+		}, logger.NewNoOpLogger())
+		assert.NoError(t, err, mode)
+		assert.Zero(t, pid)
+	}
+
+	_, err := handleRunningInstances(ifRunningAttach, `C:\p\Lobby.smw`, instances, nil, logger.NewNoOpLogger())
+	assert.ErrorContains(t, err, "other users' sessions")
+}
+
+func TestValidateIfRunning(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []string{"", "ignore", "kill", "attach", "abort"} {
+		assert.NoError(t, validateIfRunning(mode))
+	}
+
+	assert.Error(t, validateIfRunning("reuse"))
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Norgate-AV/smpc/internal/schedule"
+)
+
+// schtasksRunner runs schtasks.exe with the given arguments and returns its combined output
+type schtasksRunner func(args ...string) ([]byte, error)
+
+func runSchtasks(args ...string) ([]byte, error) {
+	return exec.Command("schtasks.exe", args...).CombinedOutput()
+}
+
+// scheduleCmd groups Windows Task Scheduler actions
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage scheduled compiles in Windows Task Scheduler",
+	Args:  cobra.NoArgs,
+}
+
+// scheduleInstallCmd creates or replaces a scheduled compile
+var scheduleInstallCmd = &cobra.Command{
+	Use:          "install",
+	Short:        "Create or replace a scheduled task that compiles programs",
+	Args:         cobra.NoArgs,
+	RunE:         runScheduleInstall,
+	SilenceUsage: true,
+}
+
+// scheduleRemoveCmd deletes a scheduled compile
+var scheduleRemoveCmd = &cobra.Command{
+	Use:          "remove",
+	Short:        "Delete a scheduled compile",
+	Args:         cobra.NoArgs,
+	RunE:         runScheduleRemove,
+	SilenceUsage: true,
+}
+
+// scheduleListCmd lists scheduled compiles
+var scheduleListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List scheduled compiles",
+	Args:         cobra.NoArgs,
+	RunE:         runScheduleList,
+	SilenceUsage: true,
+}
+
+func init() {
+	scheduleInstallCmd.Flags().String("name", "nightly", "task name (created in the Task Scheduler \\smpc\\ folder)")
+	scheduleInstallCmd.Flags().String("cron", "", "when to run, as \"minute hour * * day-of-week\" (e.g. \"30 2 * * *\" or \"0 22 * * mon-fri\")")
+	scheduleInstallCmd.Flags().StringArray("files", nil, "program to compile; repeatable, compiled in order")
+	scheduleInstallCmd.Flags().StringArray("arg", nil, "extra smpc argument for every compile (e.g. --recompile-all); repeatable")
+	_ = scheduleInstallCmd.MarkFlagRequired("cron")
+	_ = scheduleInstallCmd.MarkFlagRequired("files")
+
+	scheduleRemoveCmd.Flags().String("name", "nightly", "task name to delete")
+
+	scheduleCmd.AddCommand(scheduleInstallCmd, scheduleRemoveCmd, scheduleListCmd)
+	RootCmd.AddCommand(scheduleCmd)
+}
+
+func runScheduleInstall(cmd *cobra.Command, _ []string) error {
+	name, _ := cmd.Flags().GetString("name")
+	cronExpr, _ := cmd.Flags().GetString("cron")
+	files, _ := cmd.Flags().GetStringArray("files")
+	extraArgs, _ := cmd.Flags().GetStringArray("arg")
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	task, err := newScheduledTask(exe, cronExpr, files, extraArgs, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return installSchedule(cmd.OutOrStdout(), name, task, runSchtasks)
+}
+
+func runScheduleRemove(cmd *cobra.Command, _ []string) error {
+	name, _ := cmd.Flags().GetString("name")
+
+	if out, err := runSchtasks("/Delete", "/TN", schedule.Folder+name, "/F"); err != nil {
+		return fmt.Errorf("failed to delete scheduled task %s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed scheduled task %s%s\n", schedule.Folder, name)
+	return nil
+}
+
+func runScheduleList(cmd *cobra.Command, _ []string) error {
+	return listSchedules(cmd.OutOrStdout(), runSchtasks)
+}
+
+// newScheduledTask builds a task that runs smpc once per program, in order
+func newScheduledTask(exe, cronExpr string, files, extraArgs []string, now time.Time) (schedule.Task, error) {
+	cron, err := schedule.ParseCron(cronExpr)
+	if err != nil {
+		return schedule.Task{}, err
+	}
+
+	task := schedule.Task{
+		Description: "Scheduled SIMPL Windows compile by smpc",
+		Cron:        cron,
+		Start:       now,
+	}
+
+	for _, file := range files {
+		if err := checkProgramFile(file); err != nil {
+			return schedule.Task{}, err
+		}
+
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return schedule.Task{}, fmt.Errorf("error resolving file path: %w", err)
+		}
+
+		if _, err := os.Stat(abs); err != nil {
+			return schedule.Task{}, fmt.Errorf("file does not exist: %s", abs)
+		}
+
+		args := make([]string, 0, len(extraArgs)+1)
+		for _, arg := range extraArgs {
+			args = append(args, syscall.EscapeArg(arg))
+		}
+
+		args = append(args, syscall.EscapeArg(abs))
+
+		task.Actions = append(task.Actions, schedule.Action{
+			Command:          exe,
+			Arguments:        strings.Join(args, " "),
+			WorkingDirectory: filepath.Dir(abs),
+		})
+	}
+
+	return task, nil
+}
+
+// installSchedule registers task with Task Scheduler, replacing any task with the same name
+func installSchedule(out io.Writer, name string, task schedule.Task, run schtasksRunner) error {
+	doc, err := task.XML()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp("", "smpc-task-*.xml")
+	if err != nil {
+		return fmt.Errorf("failed to write task definition: %w", err)
+	}
+
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(schedule.EncodeUTF16(doc))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write task definition: %w", err)
+	}
+
+	if output, err := run("/Create", "/TN", schedule.Folder+name, "/XML", f.Name(), "/F"); err != nil {
+		return fmt.Errorf("failed to create scheduled task %s: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+
+	fmt.Fprintf(out, "Installed scheduled task %s%s with %d compile(s)\n", schedule.Folder, name, len(task.Actions))
+	return nil
+}
+
+// listSchedules prints the tasks in the smpc Task Scheduler folder
+func listSchedules(out io.Writer, run schtasksRunner) error {
+	output, err := run("/Query", "/TN", schedule.Folder, "/FO", "CSV", "/NH")
+	if err != nil {
+		// schtasks fails when the folder does not exist yet
+		fmt.Fprintln(out, "No scheduled compiles")
+		return nil
+	}
+
+	records, err := csv.NewReader(strings.NewReader(string(output))).ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to parse schtasks output: %w", err)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tNEXT RUN\tSTATUS")
+
+	for _, r := range records {
+		if len(r) < 3 {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", strings.TrimPrefix(r[0], schedule.Folder), r[1], r[2])
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScheduledTask(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	program := filepath.Join(dir, "My Program.smw")
+	require.NoError(t, os.WriteFile(program, []byte("test"), 0o644))
+
+	task, err := newScheduledTask(`C:\Tools\smpc.exe`, "30 2 * * *", []string{program}, []string{"--recompile-all"}, time.Now())
+	require.NoError(t, err)
+
+	require.Len(t, task.Actions, 1)
+	assert.Equal(t, `C:\Tools\smpc.exe`, task.Actions[0].Command)
+	assert.Equal(t, `--recompile-all "`+program+`"`, task.Actions[0].Arguments)
+	assert.Equal(t, dir, task.Actions[0].WorkingDirectory)
+
+	_, err = newScheduledTask(`C:\Tools\smpc.exe`, "30 2 * * *", []string{filepath.Join(dir, "missing.smw")}, nil, time.Now())
+	assert.ErrorContains(t, err, "does not exist")
+
+	_, err = newScheduledTask(`C:\Tools\smpc.exe`, "every night", []string{program}, nil, time.Now())
+	assert.ErrorContains(t, err, "cron")
+}
+
+func TestInstallSchedule(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	program := filepath.Join(dir, "Lobby.smw")
+	require.NoError(t, os.WriteFile(program, []byte("test"), 0o644))
+
+	task, err := newScheduledTask(`C:\Tools\smpc.exe`, "0 22 * * mon-fri", []string{program}, nil, time.Now())
+	require.NoError(t, err)
+
+	var (
+		gotArgs []string
+		gotXML  string
+	)
+
+	run := func(args ...string) ([]byte, error) {
+		gotArgs = args
+
+		// Read the definition before installSchedule removes it
+		data, err := os.ReadFile(args[4])
+		require.NoError(t, err)
+
+		units := make([]uint16, 0, len(data)/2)
+		for i := 2; i+1 < len(data); i += 2 {
+			units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+		}
+
+		gotXML = string(utf16.Decode(units))
+		return []byte("SUCCESS"), nil
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, installSchedule(&out, "nightly", task, run))
+
+	assert.Equal(t, []string{"/Create", "/TN", `\smpc\nightly`, "/XML", gotArgs[4], "/F"}, gotArgs)
+	assert.Contains(t, gotXML, `encoding="UTF-16"`)
+	assert.Contains(t, gotXML, "<Friday></Friday>")
+	assert.NoFileExists(t, gotArgs[4])
+	assert.Contains(t, out.String(), `Installed scheduled task \smpc\nightly with 1 compile(s)`)
+}
+
+func TestListSchedules(t *testing.T) {
+	t.Parallel()
+
+	run := func(args ...string) ([]byte, error) {
+		return []byte("\"\\smpc\\nightly\",\"04/03/2025 02:30:00\",\"Ready\"\r\n"), nil
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, listSchedules(&out, run))
+
+	assert.Contains(t, out.String(), "NAME")
+	assert.Contains(t, out.String(), "nightly")
+	assert.Contains(t, out.String(), "Ready")
+}
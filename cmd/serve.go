@@ -0,0 +1,600 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Norgate-AV/smpc/internal/clock"
+	"github.com/Norgate-AV/smpc/internal/eventstream"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/redact"
+	"github.com/Norgate-AV/smpc/internal/server"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+const (
+	serviceName        = "smpc"
+	defaultServeListen = "127.0.0.1:8765"
+	apiTokenEnv        = "SMPC_API_TOKEN"
+)
+
+// serveOptions are the flags shared by serve and service install
+type serveOptions struct {
+	Listen    string
+	QueueFile string
+	Preempt   bool
+	TokenFile string
+	TLSCert   string
+	TLSKey    string
+
+	// ArtifactRetention is how long uploaded jobs' outputs are kept; 0 keeps them
+	ArtifactRetention time.Duration
+}
+
+// addServeFlags registers the serveOptions flags on cmd
+func addServeFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.String("listen", defaultServeListen, "address to accept job requests on")
+	flags.String("queue-file", "", "file the job queue is persisted to (default jobs.json beside the log file)")
+	flags.Bool("preempt", false, "abort and requeue a running job when a higher priority job is submitted")
+	flags.String("token-file", "", "file of API tokens, one per line; requests must send one as a Bearer token (also "+apiTokenEnv+")")
+	flags.String("tls-cert", "", "PEM certificate file; serve HTTPS instead of HTTP (requires --tls-key)")
+	flags.String("tls-key", "", "PEM private key file for --tls-cert")
+	flags.Duration("artifact-retention", server.DefaultArtifactRetention, "how long to keep uploaded jobs' outputs after they finish (0 = until the job is forgotten)")
+}
+
+// serveOptionsFromFlags reads the serveOptions flags from cmd
+func serveOptionsFromFlags(cmd *cobra.Command) (serveOptions, error) {
+	var opts serveOptions
+
+	opts.Listen, _ = cmd.Flags().GetString("listen")
+	opts.QueueFile, _ = cmd.Flags().GetString("queue-file")
+	opts.Preempt, _ = cmd.Flags().GetBool("preempt")
+	opts.TokenFile, _ = cmd.Flags().GetString("token-file")
+	opts.TLSCert, _ = cmd.Flags().GetString("tls-cert")
+	opts.TLSKey, _ = cmd.Flags().GetString("tls-key")
+	opts.ArtifactRetention, _ = cmd.Flags().GetDuration("artifact-retention")
+
+	if (opts.TLSCert == "") != (opts.TLSKey == "") {
+		return opts, errors.New("--tls-cert and --tls-key must be used together")
+	}
+
+	if opts.ArtifactRetention < 0 {
+		return opts, errors.New("--artifact-retention must not be negative")
+	}
+
+	return opts, nil
+}
+
+// args returns the serve arguments that reproduce opts, with file paths made absolute
+// so they still resolve when the Service Control Manager starts smpc
+func (o serveOptions) args() ([]string, error) {
+	args := []string{"--listen", o.Listen}
+
+	for _, f := range []struct {
+		name, path string
+	}{
+		{"--queue-file", o.QueueFile},
+		{"--token-file", o.TokenFile},
+		{"--tls-cert", o.TLSCert},
+		{"--tls-key", o.TLSKey},
+	} {
+		if f.path == "" {
+			continue
+		}
+
+		abs, err := filepath.Abs(f.path)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving %s: %w", f.name, err)
+		}
+
+		args = append(args, f.name, abs)
+	}
+
+	if o.Preempt {
+		args = append(args, "--preempt")
+	}
+
+	if o.ArtifactRetention != server.DefaultArtifactRetention {
+		args = append(args, "--artifact-retention", o.ArtifactRetention.String())
+	}
+
+	return args, nil
+}
+
+// apiTokens returns the tokens the API requires, or nil if it is unauthenticated
+func (o serveOptions) apiTokens(getenv func(string) string) (*server.Tokens, error) {
+	if o.TokenFile != "" {
+		return server.LoadTokens(o.TokenFile)
+	}
+
+	if token := getenv(apiTokenEnv); token != "" {
+		return server.NewTokens(token), nil
+	}
+
+	return nil, nil
+}
+
+// isLoopback reports whether the listen address only accepts local connections
+func isLoopback(listen string) bool {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serveCmd runs the compile job server
+var serveCmd = &cobra.Command{
+	Use:          "serve",
+	Short:        "Accept compile jobs over HTTP and run them one at a time",
+	Args:         cobra.NoArgs,
+	RunE:         runServe,
+	SilenceUsage: true,
+}
+
+// serviceCmd groups Windows service installation actions
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Install or remove the smpc compile service",
+	Args:  cobra.NoArgs,
+}
+
+// serviceInstallCmd registers smpc serve as a Windows service
+var serviceInstallCmd = &cobra.Command{
+	Use:          "install",
+	Short:        "Register the compile server as an automatic-start Windows service",
+	Args:         cobra.NoArgs,
+	RunE:         runServiceInstall,
+	SilenceUsage: true,
+}
+
+// serviceRemoveCmd deletes the Windows service
+var serviceRemoveCmd = &cobra.Command{
+	Use:          "remove",
+	Short:        "Stop and delete the compile service",
+	Args:         cobra.NoArgs,
+	RunE:         runServiceRemove,
+	SilenceUsage: true,
+}
+
+func init() {
+	addServeFlags(serveCmd)
+	serveCmd.Flags().Bool("service", false, "run under the Service Control Manager, launching compiles in the console user's session")
+	_ = serveCmd.Flags().MarkHidden("service")
+
+	addServeFlags(serviceInstallCmd)
+
+	serviceCmd.AddCommand(serviceInstallCmd, serviceRemoveCmd)
+	RootCmd.AddCommand(serveCmd, serviceCmd)
+}
+
+func runServe(cmd *cobra.Command, _ []string) error {
+	asService, _ := cmd.Flags().GetBool("service")
+
+	opts, err := serveOptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg := NewConfigFromFlags(cmd)
+
+	redactMode, err := redact.ParseMode(cfg.Redact)
+	if err != nil {
+		return err
+	}
+
+	log, err := initializeLogger(cfg, redact.New(redactMode))
+	if err != nil {
+		return err
+	}
+
+	defer log.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	if opts.QueueFile == "" {
+		opts.QueueFile = filepath.Join(filepath.Dir(logger.GetLogPath(logger.LoggerOptions{})), "jobs.json")
+	}
+
+	serve := func(ctx context.Context, start compileStarter) error {
+		return serveJobs(ctx, opts, newCompileRunner(exe, cfg, start), log)
+	}
+
+	if !asService {
+		if !windows.IsElevated() {
+			log.Warn("Not running as administrator; each job will prompt for elevation")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		return serve(ctx, startLocalCompile)
+	}
+
+	err = windows.RunService(serviceName, func(stop <-chan struct{}) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-stop
+			cancel()
+		}()
+
+		return serve(ctx, startSessionCompile)
+	})
+	if errors.Is(err, windows.ErrNotService) {
+		return fmt.Errorf("--service is only for use by the Service Control Manager; run \"smpc service install\" instead")
+	}
+
+	return err
+}
+
+// serveJobs serves the job API and runs jobs until ctx is cancelled, resuming any jobs
+// left in the queue file by a previous run
+func serveJobs(ctx context.Context, opts serveOptions, runner server.Runner, log logger.LoggerInterface) error {
+	srv, err := server.NewServerWithDeps(runner, log, clock.New(), server.NewFileStore(opts.QueueFile))
+	if err != nil {
+		return err
+	}
+
+	srv.SetPreemption(opts.Preempt)
+	srv.SetUploadDir(filepath.Join(filepath.Dir(opts.QueueFile), "uploads"))
+	srv.SetArtifactRetention(opts.ArtifactRetention)
+
+	handler := srv.Handler()
+
+	tokens, err := opts.apiTokens(os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	if tokens != nil {
+		handler = server.RequireToken(tokens, handler)
+		log.Info("API token authentication enabled", slog.Int("tokens", tokens.Len()))
+	} else if !isLoopback(opts.Listen) {
+		log.Warn("Job API is reachable from other machines without authentication; use --token-file",
+			slog.String("listen", opts.Listen),
+		)
+	}
+
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	scheme := "http"
+
+	if opts.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.TLSCert, opts.TLSKey)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+
+		httpServer.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+		scheme = "https"
+	}
+
+	listener, err := net.Listen("tcp", opts.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", opts.Listen, err)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if httpServer.TLSConfig != nil {
+			serveErr <- httpServer.ServeTLS(listener, "", "")
+			return
+		}
+
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	log.Info("Accepting compile jobs", slog.String("url", fmt.Sprintf("%s://%s/api/v1/jobs", scheme, listener.Addr())))
+
+	// Jobs run on this goroutine; a running compile finishes before shutdown
+	srv.Run(ctx)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_ = httpServer.Shutdown(shutdownCtx)
+
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("job server stopped: %w", err)
+	}
+
+	log.Info("Job server stopped")
+	return nil
+}
+
+// jobLockWait is how long a job waits for another smpc instance, such as one started
+// from the command line, to finish with SIMPL Windows
+const jobLockWait = time.Hour
+
+// compileArgs returns the smpc arguments that run req, streaming its events to stdout.
+// The job takes the server's settings, not the config file, with req's on top.
+func compileArgs(cfg *Config, req server.JobRequest) []string {
+	job := *cfg
+	job.RecompileAll = req.RecompileAll
+
+	args := []string{"--events", eventstream.FormatNDJSON, "--wait-for-lock", jobLockWait.String()}
+
+	if req.WarningsAsErrors {
+		args = append(args, "--warnings-as-errors")
+	}
+
+	return append(args, projectArgs(&job, req.File)...)
+}
+
+// compileStarter runs smpc with args, sending its stdout to events and stderr to out, and
+// returns its exit code. If ctx is cancelled it terminates smpc and SIMPL Windows.
+type compileStarter func(ctx context.Context, exe string, args []string, events, out *os.File) (int, error)
+
+// newCompileRunner returns a Runner that compiles each job in a child smpc process
+// with the settings in cfg
+func newCompileRunner(exe string, cfg *Config, start compileStarter) server.Runner {
+	return server.RunnerFunc(func(ctx context.Context, req server.JobRequest) (server.Result, error) {
+		out, err := os.CreateTemp("", "smpc-job-*.log")
+		if err != nil {
+			return server.Result{}, fmt.Errorf("failed to create job output file: %w", err)
+		}
+
+		defer os.Remove(out.Name())
+		defer out.Close()
+
+		events, err := os.CreateTemp("", "smpc-job-*.ndjson")
+		if err != nil {
+			return server.Result{}, fmt.Errorf("failed to create job events file: %w", err)
+		}
+
+		defer os.Remove(events.Name())
+		defer events.Close()
+
+		stopEvents := followFile(events.Name(), eventPollInterval, lineReader(func(line []byte) {
+			var ev eventstream.Event
+			if json.Unmarshal(line, &ev) == nil {
+				server.ReportEvent(ctx, ev)
+			}
+		}))
+
+		stopProgress := func() {}
+		if req.Callbacks != nil && req.Callbacks.Progress != "" {
+			stopProgress = followFile(out.Name(), outputProgressInterval, func(output []byte) {
+				server.ReportProgress(ctx, string(output))
+			})
+		}
+
+		exitCode, err := start(ctx, exe, compileArgs(cfg, req), events, out)
+
+		stopEvents()
+		stopProgress()
+
+		if err != nil {
+			return server.Result{}, err
+		}
+
+		output, err := os.ReadFile(out.Name())
+		if err != nil {
+			return server.Result{}, fmt.Errorf("failed to read job output: %w", err)
+		}
+
+		return server.Result{ExitCode: exitCode, Output: string(output)}, nil
+	})
+}
+
+const (
+	// outputProgressInterval is how often new compile output is sent to a job's progress callback
+	outputProgressInterval = 5 * time.Second
+
+	// eventPollInterval is how often a compile's events file is checked for new events
+	eventPollInterval = 250 * time.Millisecond
+)
+
+// followFile passes the data written to path since the last check to onData every
+// interval, until the returned stop is called. stop passes any data not yet seen and
+// waits for following to end.
+func followFile(path string, interval time.Duration, onData func([]byte)) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	var seen int
+
+	report := func() {
+		data, err := os.ReadFile(path)
+		if err != nil || len(data) <= seen {
+			return
+		}
+
+		onData(data[seen:])
+		seen = len(data)
+	}
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				report()
+			case <-done:
+				report()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// lineReader returns an onData for followFile that calls onLine with each complete line,
+// holding back a trailing partial line until the rest of it is written
+func lineReader(onLine func([]byte)) func([]byte) {
+	var partial []byte
+
+	return func(data []byte) {
+		partial = append(partial, data...)
+
+		for {
+			i := bytes.IndexByte(partial, '\n')
+			if i < 0 {
+				return
+			}
+
+			if line := bytes.TrimSpace(partial[:i]); len(line) > 0 {
+				onLine(line)
+			}
+
+			partial = partial[i+1:]
+		}
+	}
+}
+
+// startLocalCompile runs smpc as a child of this process
+func startLocalCompile(ctx context.Context, exe string, args []string, events, out *os.File) (int, error) {
+	child := exec.CommandContext(ctx, exe, args...)
+	child.Stdout = events
+	child.Stderr = out
+
+	// SIMPL Windows is a child of smpc, so it must go too
+	child.Cancel = func() error {
+		_, err := windows.TerminateProcessTree(uint32(child.Process.Pid))
+		return err
+	}
+
+	err := child.Run()
+
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to run smpc: %w", err)
+	}
+
+	return 0, nil
+}
+
+// startSessionCompile runs smpc on the console user's desktop, since a service's own
+// session has no desktop SIMPL Windows can be automated on
+func startSessionCompile(ctx context.Context, exe string, args []string, events, out *os.File) (int, error) {
+	cmdLine := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{exe}, args...) {
+		cmdLine = append(cmdLine, syscall.EscapeArg(arg))
+	}
+
+	process, err := windows.StartInActiveSession(strings.Join(cmdLine, " "), filepath.Dir(exe),
+		syscall.Handle(events.Fd()), syscall.Handle(out.Fd()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to start smpc in the interactive session: %w", err)
+	}
+
+	defer process.Close()
+
+	exited := make(chan struct{})
+	defer close(exited)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_, _ = windows.TerminateProcessTree(process.Pid)
+		case <-exited:
+		}
+	}()
+
+	exitCode, err := process.Wait()
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	return exitCode, err
+}
+
+// serviceBinPath returns the command line the Service Control Manager runs
+func serviceBinPath(exe string, serveArgs []string) string {
+	parts := []string{syscall.EscapeArg(exe), "serve", "--service"}
+	for _, arg := range serveArgs {
+		parts = append(parts, syscall.EscapeArg(arg))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func runServiceInstall(cmd *cobra.Command, _ []string) error {
+	opts, err := serveOptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	serveArgs, err := opts.args()
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	args := []string{
+		"create", serviceName,
+		"binPath=", serviceBinPath(exe, serveArgs),
+		"start=", "auto",
+		"DisplayName=", "smpc compile service",
+	}
+
+	if out, err := exec.Command("sc.exe", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create service: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Installed service %s listening on %s; start it with \"sc.exe start %s\"\n", serviceName, opts.Listen, serviceName)
+	return nil
+}
+
+func runServiceRemove(cmd *cobra.Command, _ []string) error {
+	_ = exec.Command("sc.exe", "stop", serviceName).Run()
+
+	if out, err := exec.Command("sc.exe", "delete", serviceName).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete service: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed service %s\n", serviceName)
+	return nil
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/server"
+)
+
+func TestCompileArgs(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Backend: "gui"}
+
+	assert.Equal(t,
+		[]string{"--events", "ndjson", "--wait-for-lock", "1h0m0s", "--no-config", "--backend", "gui", `C:\p.smw`},
+		compileArgs(cfg, server.JobRequest{File: `C:\p.smw`}),
+	)
+	assert.Equal(t,
+		[]string{"--events", "ndjson", "--wait-for-lock", "1h0m0s", "--warnings-as-errors", "--no-config", "--backend", "gui", "--recompile-all", `C:\p.smw`},
+		compileArgs(cfg, server.JobRequest{File: `C:\p.smw`, RecompileAll: true, WarningsAsErrors: true}),
+	)
+
+	// The server's own settings are passed on, rather than read from the config file
+	assert.Equal(t,
+		[]string{"--events", "ndjson", "--wait-for-lock", "1h0m0s", "--no-config", "--backend", "gui", "--simpl-path", `D:\Simpl\smpwin.exe`, `C:\p.smw`},
+		compileArgs(&Config{Backend: "gui", RecompileAll: true, SimplPath: `D:\Simpl\smpwin.exe`}, server.JobRequest{File: `C:\p.smw`}),
+	)
+}
+
+func TestNewCompileRunner_CapturesOutput(t *testing.T) {
+	t.Parallel()
+
+	var gotExe string
+	var gotArgs []string
+
+	runner := newCompileRunner(`C:\Tools\smpc.exe`, &Config{Backend: "gui"}, func(_ context.Context, exe string, args []string, events, out *os.File) (int, error) {
+		gotExe, gotArgs = exe, args
+		if _, err := events.WriteString(`{"type":"lifecycle","event":"compile_finished"}` + "\n"); err != nil {
+			return 0, err
+		}
+
+		_, err := out.WriteString("Compile complete\n")
+		return 1, err
+	})
+
+	result, err := runner.Run(context.Background(), server.JobRequest{File: `C:\p.smw`, RecompileAll: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, `C:\Tools\smpc.exe`, gotExe)
+	assert.Equal(t, []string{"--events", "ndjson", "--wait-for-lock", "1h0m0s", "--no-config", "--backend", "gui", "--recompile-all", `C:\p.smw`}, gotArgs)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Equal(t, "Compile complete\n", result.Output)
+}
+
+func TestLineReader(t *testing.T) {
+	t.Parallel()
+
+	var lines []string
+
+	read := lineReader(func(line []byte) { lines = append(lines, string(line)) })
+	read([]byte("{\"event\":\"started\"}\n{\"event\""))
+	assert.Equal(t, []string{`{"event":"started"}`}, lines, "holds back the partial line")
+
+	read([]byte(":\"exited\"}\r\n\n"))
+	assert.Equal(t, []string{`{"event":"started"}`, `{"event":"exited"}`}, lines)
+}
+
+func TestServiceBinPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		`"C:\Program Files\smpc\smpc.exe" serve --service --listen 127.0.0.1:8765 --token-file "C:\Program Data\tokens"`,
+		serviceBinPath(`C:\Program Files\smpc\smpc.exe`, []string{"--listen", "127.0.0.1:8765", "--token-file", `C:\Program Data\tokens`}),
+	)
+}
+
+func TestServeOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := serveOptions{Listen: "0.0.0.0:8765", Preempt: true, ArtifactRetention: server.DefaultArtifactRetention}
+
+	args, err := opts.args()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--listen", "0.0.0.0:8765", "--preempt"}, args)
+
+	opts.ArtifactRetention = 0
+
+	args, err = opts.args()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--listen", "0.0.0.0:8765", "--preempt", "--artifact-retention", "0s"}, args)
+
+	tokens, err := opts.apiTokens(func(string) string { return "" })
+	require.NoError(t, err)
+	assert.Nil(t, tokens)
+
+	tokens, err = opts.apiTokens(func(key string) string {
+		if key == apiTokenEnv {
+			return "ci-token"
+		}
+
+		return ""
+	})
+	require.NoError(t, err)
+	assert.True(t, tokens.Valid("ci-token"))
+}
+
+func TestIsLoopback(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isLoopback("127.0.0.1:8765"))
+	assert.True(t, isLoopback("localhost:8765"))
+	assert.True(t, isLoopback("[::1]:8765"))
+	assert.False(t, isLoopback("0.0.0.0:8765"))
+	assert.False(t, isLoopback(":8765"))
+	assert.False(t, isLoopback("build01:8765"))
+}
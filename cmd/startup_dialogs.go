@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Norgate-AV/smpc/internal/autorespond"
+	"github.com/Norgate-AV/smpc/internal/compiler"
+	"github.com/Norgate-AV/smpc/internal/interfaces"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// --update-prompts policies for update and download prompts shown at startup
+const (
+	updatePromptsDismiss = "dismiss" // Decline them so the launch carries on
+	updatePromptsIgnore  = "ignore"  // Leave them for someone to answer
+)
+
+// dismissCaptions are the buttons, without mnemonics, that decline an update prompt
+var dismissCaptions = []string{"no", "not now", "later", "remind me later", "skip", "cancel", "close"}
+
+// validateUpdatePrompts returns an error if mode is not a supported --update-prompts policy
+func validateUpdatePrompts(mode string) error {
+	switch mode {
+	case "", updatePromptsDismiss, updatePromptsIgnore:
+		return nil
+	default:
+		return fmt.Errorf("unsupported --update-prompts %q (supported: %s, %s)",
+			mode, updatePromptsDismiss, updatePromptsIgnore)
+	}
+}
+
+// startupDialogs handles the dialogs SIMPL Windows and its Crestron components can
+// show before the main window, which would otherwise hold up the launch until it
+// timed out. Dialogs the --auto-respond policy answers are dismissed. Otherwise
+// licensing dialogs stop the launch with compiler.ErrNotLicensed, and update prompts
+// are declined according to --update-prompts.
+type startupDialogs struct {
+	windowMgr     interfaces.WindowManager
+	controlReader interfaces.ControlReader
+	keyboard      interfaces.KeyboardInjector
+	policy        *autorespond.Policy
+	updatePrompts string
+	stopLaunch    context.CancelCauseFunc
+	log           logger.LoggerInterface
+
+	mu   sync.Mutex
+	seen map[uintptr]bool
+}
+
+func newStartupDialogs(
+	windowMgr interfaces.WindowManager,
+	controlReader interfaces.ControlReader,
+	keyboard interfaces.KeyboardInjector,
+	policy *autorespond.Policy,
+	updatePrompts string,
+	stopLaunch context.CancelCauseFunc,
+	log logger.LoggerInterface,
+) *startupDialogs {
+	return &startupDialogs{
+		windowMgr:     windowMgr,
+		controlReader: controlReader,
+		keyboard:      keyboard,
+		policy:        policy,
+		updatePrompts: updatePrompts,
+		stopLaunch:    stopLaunch,
+		log:           log,
+		seen:          make(map[uintptr]bool),
+	}
+}
+
+// watch handles events from sub on its own goroutine until the returned function is called
+func (w *startupDialogs) watch(sub *windows.Subscription) (stop func()) {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for ev := range sub.C {
+			w.handle(ev)
+		}
+	}()
+
+	return func() {
+		sub.Unsubscribe()
+		<-done
+	}
+}
+
+// handle answers or reports ev's window if it is a licensing dialog or update prompt
+// not seen before
+func (w *startupDialogs) handle(ev windows.WindowEvent) {
+	if ev.Class != windows.DialogClass {
+		return
+	}
+
+	w.mu.Lock()
+	seen := w.seen[ev.Hwnd]
+	w.seen[ev.Hwnd] = true
+	w.mu.Unlock()
+
+	if seen {
+		return
+	}
+
+	childInfos := w.windowMgr.CollectChildInfos(ev.Hwnd)
+
+	var texts []string
+
+	for _, ci := range childInfos {
+		if text := strings.TrimSpace(ci.Text); text != "" && ci.ClassName != "Button" {
+			texts = append(texts, text)
+		}
+	}
+
+	switch {
+	case simpl.IsLicenseDialog(ev.Title, texts):
+		w.handleLicense(ev, texts)
+	case simpl.IsUpdatePrompt(ev.Title, texts):
+		w.handleUpdate(ev, texts, childInfos)
+	}
+}
+
+func (w *startupDialogs) handleLicense(ev windows.WindowEvent, texts []string) {
+	text := strings.Join(texts, " ")
+	w.log.Warn("SIMPL Windows is showing a licensing dialog", slog.String("title", ev.Title), slog.String("text", text))
+
+	if rule, ok := w.policy.MatchDialog(compiler.AutoRespondDialog(ev)); ok && w.answer(ev.Hwnd, rule) {
+		w.log.Info("Dismissed licensing dialog using the auto-respond policy", slog.String("title", ev.Title))
+		return
+	}
+
+	w.stopLaunch(fmt.Errorf("%w: %q: %s", compiler.ErrNotLicensed, ev.Title, text))
+}
+
+func (w *startupDialogs) handleUpdate(ev windows.WindowEvent, texts []string, childInfos []windows.ChildInfo) {
+	text := strings.Join(texts, " ")
+
+	if rule, ok := w.policy.MatchDialog(compiler.AutoRespondDialog(ev)); ok && w.answer(ev.Hwnd, rule) {
+		w.log.Info("Suppressed update prompt using the auto-respond policy", slog.String("title", ev.Title), slog.String("text", text))
+		return
+	}
+
+	if w.updatePrompts == updatePromptsIgnore {
+		w.log.Warn("Update prompt left open; the launch may stall until it is answered",
+			slog.String("title", ev.Title),
+			slog.String("text", text),
+		)
+		return
+	}
+
+	for _, ci := range childInfos {
+		caption := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(ci.Text), "&", ""))
+
+		if ci.ClassName == "Button" && slices.Contains(dismissCaptions, caption) &&
+			w.controlReader.FindAndClickButton(ev.Hwnd, ci.Text) {
+			w.log.Info("Suppressed update prompt",
+				slog.String("title", ev.Title),
+				slog.String("text", text),
+				slog.String("button", ci.Text),
+			)
+			return
+		}
+	}
+
+	// No button to decline with, so close it as its title bar's X would
+	w.windowMgr.CloseWindow(ev.Hwnd, ev.Title)
+	w.log.Info("Suppressed update prompt by closing it", slog.String("title", ev.Title), slog.String("text", text))
+}
+
+// answer clicks the rule's button or presses its key in the dialog
+func (w *startupDialogs) answer(hwnd uintptr, rule *autorespond.Rule) bool {
+	if rule.Button != "" {
+		return w.controlReader.FindAndClickButton(hwnd, rule.Button)
+	}
+
+	_ = w.windowMgr.SetForeground(hwnd)
+
+	if !w.keyboard.SendChordWithSendInput(rule.KeyChord()) {
+		w.keyboard.SendChord(rule.KeyChord())
+	}
+
+	return true
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/autorespond"
+	"github.com/Norgate-AV/smpc/internal/compiler"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/testutil"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+func TestStartupDialogs(t *testing.T) {
+	t.Parallel()
+
+	registration := windows.WindowEvent{Hwnd: 0x10, Title: "Software Registration", Class: windows.DialogClass}
+
+	t.Run("stops the launch when no rule answers", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, stop := context.WithCancelCause(context.Background())
+		win := testutil.NewMockWindowManager().WithChildInfos(windows.ChildInfo{ClassName: "Static", Text: "Please register SIMPL Windows."})
+
+		w := newStartupDialogs(win, testutil.NewMockControlReader(), testutil.NewMockKeyboardInjector(), nil, updatePromptsDismiss, stop, logger.NewNoOpLogger())
+		w.handle(registration)
+
+		require.ErrorIs(t, context.Cause(ctx), compiler.ErrNotLicensed)
+		assert.Contains(t, context.Cause(ctx).Error(), "Please register SIMPL Windows.")
+	})
+
+	t.Run("dismisses dialogs the policy answers", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := autorespond.Parse([]byte(`{"autoRespond": [{"title": "Registration", "button": "Later"}]}`))
+		require.NoError(t, err)
+
+		ctx, stop := context.WithCancelCause(context.Background())
+		ctrl := testutil.NewMockControlReader().WithFindAndClickButtonResult(true)
+
+		w := newStartupDialogs(testutil.NewMockWindowManager(), ctrl, testutil.NewMockKeyboardInjector(), policy, updatePromptsDismiss, stop, logger.NewNoOpLogger())
+		w.handle(registration)
+
+		assert.NoError(t, ctx.Err())
+		assert.Equal(t, []string{"Later"}, ctrl.FindButtonCalls)
+	})
+
+	t.Run("ignores other dialogs", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, stop := context.WithCancelCause(context.Background())
+
+		w := newStartupDialogs(testutil.NewMockWindowManager(), testutil.NewMockControlReader(), testutil.NewMockKeyboardInjector(), nil, updatePromptsDismiss, stop, logger.NewNoOpLogger())
+		w.handle(windows.WindowEvent{Hwnd: 0x20, Title: "Operation Complete", Class: windows.DialogClass})
+
+		assert.NoError(t, ctx.Err())
+	})
+
+	update := windows.WindowEvent{Hwnd: 0x30, Title: "Crestron Master Installer", Class: windows.DialogClass}
+
+	t.Run("declines update prompts", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, stop := context.WithCancelCause(context.Background())
+		win := testutil.NewMockWindowManager().WithChildInfos(
+			windows.ChildInfo{ClassName: "Static", Text: "Updates are available. Download them now?"},
+			windows.ChildInfo{ClassName: "Button", Text: "&Yes"},
+			windows.ChildInfo{ClassName: "Button", Text: "&No"},
+		)
+		ctrl := testutil.NewMockControlReader().WithFindAndClickButtonResult(true)
+
+		w := newStartupDialogs(win, ctrl, testutil.NewMockKeyboardInjector(), nil, updatePromptsDismiss, stop, logger.NewNoOpLogger())
+		w.handle(update)
+
+		assert.NoError(t, ctx.Err())
+		assert.Equal(t, []string{"&No"}, ctrl.FindButtonCalls)
+		assert.Empty(t, win.CloseWindowCalls)
+	})
+
+	t.Run("closes update prompts without a button to decline with", func(t *testing.T) {
+		t.Parallel()
+
+		_, stop := context.WithCancelCause(context.Background())
+		win := testutil.NewMockWindowManager().WithChildInfos(windows.ChildInfo{ClassName: "Button", Text: "Install"})
+
+		w := newStartupDialogs(win, testutil.NewMockControlReader(), testutil.NewMockKeyboardInjector(), nil, updatePromptsDismiss, stop, logger.NewNoOpLogger())
+		w.handle(update)
+
+		assert.Equal(t, []testutil.CloseWindowCall{{Hwnd: 0x30, Title: "Crestron Master Installer"}}, win.CloseWindowCalls)
+	})
+
+	t.Run("leaves update prompts open when ignoring them", func(t *testing.T) {
+		t.Parallel()
+
+		_, stop := context.WithCancelCause(context.Background())
+		win := testutil.NewMockWindowManager().WithChildInfos(windows.ChildInfo{ClassName: "Button", Text: "&No"})
+		ctrl := testutil.NewMockControlReader()
+
+		w := newStartupDialogs(win, ctrl, testutil.NewMockKeyboardInjector(), nil, updatePromptsIgnore, stop, logger.NewNoOpLogger())
+		w.handle(update)
+
+		assert.Empty(t, ctrl.FindButtonCalls)
+		assert.Empty(t, win.CloseWindowCalls)
+	})
+}
+
+func TestValidateUpdatePrompts(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []string{"", updatePromptsDismiss, updatePromptsIgnore} {
+		assert.NoError(t, validateUpdatePrompts(mode))
+	}
+
+	assert.Error(t, validateUpdatePrompts("install"))
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+)
+
+// scaledCompileTimeout returns the compilation timeout the curve gives the program at
+// path, logging it with the size it was computed from
+func scaledCompileTimeout(curve timeouts.SizeCurve, path string, log logger.LoggerInterface) time.Duration {
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+
+	timeout := curve.Timeout(size)
+	log.Info("Compilation timeout", slog.Duration("timeout", timeout), slog.Int64("programBytes", size))
+
+	return timeout
+}
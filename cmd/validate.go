@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Norgate-AV/smpc/internal/compiler"
+	"github.com/Norgate-AV/smpc/internal/interfaces"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/poll"
+	"github.com/Norgate-AV/smpc/internal/redact"
+	"github.com/Norgate-AV/smpc/internal/simpl"
+	"github.com/Norgate-AV/smpc/internal/smw"
+	"github.com/Norgate-AV/smpc/internal/validate"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// Output formats for validate
+const (
+	validateFormatText = "text"
+	validateFormatJSON = "json"
+)
+
+// validateSettle is how long to keep watching for dialogs once SIMPL Windows is ready
+const validateSettle = 3 * time.Second
+
+// validateCmd opens a program in SIMPL Windows and reports what it complains about
+var validateCmd = &cobra.Command{
+	Use:   "validate <file.smw>",
+	Short: "Open a program in SIMPL Windows and report diagnostics without compiling",
+	Long: `Check the program file for incomplete symbols and missing modules, then open it
+in SIMPL Windows and capture the dialogs it shows while loading (such as modules it
+cannot resolve). SIMPL Windows is closed without compiling or saving.
+
+Diagnostics are written to stdout as text or, with --format json, as a JSON array
+for PR gates; progress goes to stderr. The exit code is 1 if any diagnostic is an
+error.`,
+	Example: `  smpc validate Lobby.smw
+  smpc validate --format json Lobby.smw > diagnostics.json`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runValidate,
+	SilenceUsage: true,
+}
+
+func init() {
+	validateCmd.Flags().String("format", validateFormatText, "diagnostics format: text or json")
+	validateCmd.Flags().StringArray("module-dir", nil, "additional directory to search for modules; repeatable")
+
+	RootCmd.AddCommand(validateCmd)
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	cfg := NewConfigFromFlags(cmd)
+
+	// An elevated relaunch sends its output back to the console of the instance that started it
+	if cfg.Handoff != "" {
+		if err := redirectToHandoff(cfg.Handoff); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+		}
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	if format != validateFormatText && format != validateFormatJSON {
+		return fmt.Errorf("unsupported --format %q (supported: %s, %s)", format, validateFormatText, validateFormatJSON)
+	}
+
+	extraDirs, _ := cmd.Flags().GetStringArray("module-dir")
+
+	log, err := initializeLoggerTo(cfg, redact.New(redact.ModeNone), os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	defer log.Close()
+
+	if err := applySimplPath(cfg); err != nil {
+		return err
+	}
+
+	if err := simpl.ValidateSimplWindowsInstallation(); err != nil {
+		return err
+	}
+
+	absPath, err := validateAndResolvePath(args[0], log)
+	if err != nil {
+		return err
+	}
+
+	program, err := smw.Open(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to read program: %w", err)
+	}
+
+	diagnostics := validate.Static(program, moduleSearchDirs(filepath.Dir(absPath), extraDirs, os.Getenv))
+
+	if err := ensureElevated(log); err != nil {
+		return err
+	}
+
+	if err := cfg.Polling().Validate(); err != nil {
+		return err
+	}
+
+	launch, err := cfg.SimplLaunch()
+	if err != nil {
+		return err
+	}
+
+	opened, err := openForValidation(cmd.Context(), absPath, cfg.Polling(), launch, log)
+	if err != nil {
+		return err
+	}
+
+	result := validate.NewResult(absPath, append(diagnostics, opened...))
+
+	if format == validateFormatJSON {
+		err = validate.WriteJSON(os.Stdout, []validate.Result{result})
+	} else {
+		err = validate.WriteText(os.Stdout, []validate.Result{result})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !result.Valid {
+		return fmt.Errorf("validation found problems in %s", filepath.Base(absPath))
+	}
+
+	log.Info("Validation passed", slog.Int("diagnostics", len(result.Diagnostics)))
+	return nil
+}
+
+// openForValidation opens the program in SIMPL Windows, records every dialog shown
+// while it loads, and closes SIMPL Windows without compiling
+func openForValidation(runCtx context.Context, absPath string, polling poll.Settings, launch simplLaunch, log logger.LoggerInterface) ([]validate.Diagnostic, error) {
+	simplClient := simpl.NewClient(log)
+	simplClient.SetPolling(polling)
+
+	process, pid, stopMonitor, err := launchSIMPLWindows(runCtx, simplClient, absPath, launch, nil, log)
+	if err != nil {
+		return nil, err
+	}
+
+	defer stopMonitor()
+
+	ctx := &ExecutionContext{simplPid: pid, log: log, simplClient: simplClient, exitFunc: os.Exit}
+	setupSignalHandlers(ctx)
+
+	// Dialogs are closed as they appear, since a modal dialog can stop the program loading
+	collector := newDialogCollector(windows.NewWindowsAPI(log), log)
+	stopCollecting := collector.watch(simplClient.Events().SubscribeWithHistory(windows.DefaultSubscriptionBuffer))
+
+	var timing compiler.TimingBreakdown
+
+	hwnd, err := waitForWindowReady(runCtx, simplClient, process, pid, log, &timing)
+	if err != nil {
+		stopCollecting()
+		return nil, err
+	}
+
+	ctx.simplHwnd = hwnd
+	launch.place(hwnd, log)
+
+	log.Info("Program opened, watching for further dialogs...")
+	time.Sleep(validateSettle)
+	stopCollecting()
+
+	simplClient.ForceCleanup(hwnd, pid)
+
+	return collector.diagnostics(), nil
+}
+
+// dialogCollector turns the dialogs SIMPL Windows shows into diagnostics
+type dialogCollector struct {
+	windowMgr interfaces.WindowManager
+	log       logger.LoggerInterface
+
+	mu    sync.Mutex
+	seen  map[uintptr]bool
+	found []validate.Diagnostic
+}
+
+func newDialogCollector(windowMgr interfaces.WindowManager, log logger.LoggerInterface) *dialogCollector {
+	return &dialogCollector{windowMgr: windowMgr, log: log, seen: make(map[uintptr]bool)}
+}
+
+// watch handles events from sub on its own goroutine until the returned function is called
+func (c *dialogCollector) watch(sub *windows.Subscription) (stop func()) {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for ev := range sub.C {
+			c.handle(ev)
+		}
+	}()
+
+	return func() {
+		sub.Unsubscribe()
+		<-done
+	}
+}
+
+// handle records and closes ev's window if it is a dialog not seen before
+func (c *dialogCollector) handle(ev windows.WindowEvent) {
+	if ev.Class != windows.DialogClass {
+		return
+	}
+
+	c.mu.Lock()
+	seen := c.seen[ev.Hwnd]
+	c.seen[ev.Hwnd] = true
+	c.mu.Unlock()
+
+	if seen {
+		return
+	}
+
+	var texts []string
+
+	for _, ci := range c.windowMgr.CollectChildInfos(ev.Hwnd) {
+		if ci.ClassName == "Button" {
+			continue
+		}
+
+		texts = append(texts, ci.Text)
+		texts = append(texts, ci.Items...)
+	}
+
+	d := validate.Dialog(ev.Title, texts)
+	c.log.Info("SIMPL Windows reported a problem", slog.String("code", d.Code), slog.String("message", d.Message))
+
+	c.windowMgr.CloseWindow(ev.Hwnd, ev.Title)
+
+	c.mu.Lock()
+	c.found = append(c.found, d)
+	c.mu.Unlock()
+}
+
+func (c *dialogCollector) diagnostics() []validate.Diagnostic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]validate.Diagnostic(nil), c.found...)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/testutil"
+	"github.com/Norgate-AV/smpc/internal/validate"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// TestDialogCollector records and closes each dialog once, ignoring other windows
+func TestDialogCollector(t *testing.T) {
+	t.Parallel()
+
+	windowMgr := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x10,
+			windows.ChildInfo{ClassName: "Static", Text: "Unable to locate module 'Lights.umc'"},
+			windows.ChildInfo{ClassName: "Button", Text: "OK"},
+		)
+
+	collector := newDialogCollector(windowMgr, logger.NewNoOpLogger())
+
+	collector.handle(windows.WindowEvent{Hwnd: 0x01, Title: "SIMPL Windows - [Lobby.smw]", Class: "AfxFrameOrView"})
+	collector.handle(windows.WindowEvent{Hwnd: 0x10, Title: "SIMPL Windows", Class: windows.DialogClass})
+	collector.handle(windows.WindowEvent{Hwnd: 0x10, Title: "SIMPL Windows", Class: windows.DialogClass})
+
+	diagnostics := collector.diagnostics()
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, validate.CodeUnresolvedModule, diagnostics[0].Code)
+	assert.Equal(t, "Lights.umc", diagnostics[0].Symbol)
+	assert.NotContains(t, diagnostics[0].Message, "OK")
+
+	require.Len(t, windowMgr.CloseWindowCalls, 1)
+	assert.Equal(t, uintptr(0x10), windowMgr.CloseWindowCalls[0].Hwnd)
+}
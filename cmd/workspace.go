@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/redact"
+	"github.com/Norgate-AV/smpc/internal/windows"
+	"github.com/Norgate-AV/smpc/internal/workspace"
+)
+
+// prepareProgram elevates for GUI automation, which a native compile does not need,
+// and then stages the program. A non-elevated instance exits once its elevated
+// relaunch has finished, without running its deferred cleanups, so nothing may be
+// staged before elevate returns.
+func prepareProgram(
+	cfg *Config,
+	absPath string,
+	native bool,
+	elevate func() error,
+	redactor *redact.Redactor,
+	log logger.LoggerInterface,
+) (compilePath string, finish func(success bool), err error) {
+	if !native {
+		if err := elevate(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return stageProgram(cfg, absPath, redactor, log)
+}
+
+// stageProgram copies the program into a workspace when --stage-local or --sandbox is set
+// and returns the path to compile. finish must be called with the compile outcome: for
+// --stage-local it copies artifacts back on success and removes the workspace; for
+// --sandbox it reports the artifacts and leaves the workspace in place.
+func stageProgram(cfg *Config, absPath string, redactor *redact.Redactor, log logger.LoggerInterface) (compilePath string, finish func(success bool), err error) {
+	if cfg.Sandbox {
+		return sandboxProgram(absPath, log)
+	}
+
+	if !cfg.StageLocal {
+		if windows.IsNetworkPath(absPath) {
+			log.Info("Program is on a network path; consider --stage-local if SIMPL Windows has trouble opening it")
+		}
+
+		return absPath, func(bool) {}, nil
+	}
+
+	ws, err := workspace.New(absPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to stage program locally: %w", err)
+	}
+
+	redactor.AddPath(ws.Dir)
+	log.Info("Staged program in local workspace", slog.String("dir", ws.Dir))
+
+	finish = func(success bool) {
+		defer removeWorkspace(ws, log)
+
+		if !success {
+			log.Debug("Compilation did not succeed, not copying artifacts back")
+			return
+		}
+
+		copied, err := ws.CopyBack()
+		if err != nil {
+			log.Error("Failed to copy artifacts back from workspace", slog.Any("error", err))
+		}
+
+		log.Info("Copied artifacts back", slog.Int("count", len(copied)))
+
+		for _, path := range copied {
+			log.Debug("Copied artifact", slog.String("path", path))
+		}
+	}
+
+	return ws.Program, finish, nil
+}
+
+// sandboxProgram compiles a copy so the original (and its folder) is never modified,
+// e.g. by an auto-confirmed save prompt. The sandbox outlives the run, so only the
+// instance that compiles may create it (see prepareProgram).
+func sandboxProgram(absPath string, log logger.LoggerInterface) (string, func(bool), error) {
+	ws, err := workspace.New(absPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create sandbox: %w", err)
+	}
+
+	log.Info("Compiling in sandbox", slog.String("dir", ws.Dir))
+
+	finish := func(bool) {
+		artifacts, err := ws.Artifacts()
+		if err != nil {
+			log.Error("Failed to list sandbox artifacts", slog.Any("error", err))
+			return
+		}
+
+		log.Info("Sandbox artifacts", slog.String("dir", ws.Dir), slog.Int("count", len(artifacts)))
+
+		for _, path := range artifacts {
+			log.Info("Artifact", slog.String("path", path))
+		}
+	}
+
+	return ws.Program, finish, nil
+}
+
+func removeWorkspace(ws *workspace.Workspace, log logger.LoggerInterface) {
+	if err := ws.Remove(); err != nil {
+		log.Warn("Failed to remove workspace", slog.String("dir", ws.Dir), slog.Any("error", err))
+	}
+}
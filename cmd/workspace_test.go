@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/redact"
+)
+
+// TestPrepareProgram_ElevatesBeforeStaging tests that an instance that may hand the
+// run to an elevated relaunch, and exit without cleaning up, stages nothing first
+func TestPrepareProgram_ElevatesBeforeStaging(t *testing.T) {
+	program := filepath.Join(t.TempDir(), "program.smw")
+	require.NoError(t, os.WriteFile(program, []byte("program"), 0o644))
+
+	// Workspaces are created in the temporary directory
+	tmp := t.TempDir()
+	t.Setenv("TMP", tmp)
+	t.Setenv("TEMP", tmp)
+
+	handedOff := errors.New("handed off to the elevated instance")
+
+	for _, cfg := range []*Config{{StageLocal: true}, {Sandbox: true}} {
+		elevate := func() error {
+			entries, err := os.ReadDir(tmp)
+			require.NoError(t, err)
+			assert.Empty(t, entries, "nothing may be staged before elevating")
+
+			return handedOff
+		}
+
+		_, _, err := prepareProgram(cfg, program, false, elevate, redact.New(redact.ModeNone), logger.NewNoOpLogger())
+		require.ErrorIs(t, err, handedOff)
+
+		entries, err := os.ReadDir(tmp)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	}
+
+	// A native compile needs no elevation
+	path, finish, err := prepareProgram(&Config{StageLocal: true}, program, true, func() error {
+		t.Fatal("native compiles must not elevate")
+		return nil
+	}, redact.New(redact.ModeNone), logger.NewNoOpLogger())
+	require.NoError(t, err)
+	assert.NotEqual(t, program, path)
+
+	finish(false)
+}
+
+func TestStageProgram_Disabled(t *testing.T) {
+	t.Parallel()
+
+	program := filepath.Join(t.TempDir(), "program.smw")
+
+	path, finish, err := stageProgram(&Config{}, program, redact.New(redact.ModeNone), logger.NewNoOpLogger())
+	require.NoError(t, err)
+	assert.Equal(t, program, path, "programs compile in place unless --stage-local is set")
+
+	finish(true)
+}
+
+func TestStageProgram_CopiesArtifactsBack(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	program := filepath.Join(dir, "program.smw")
+	require.NoError(t, os.WriteFile(program, []byte("program"), 0o644))
+
+	path, finish, err := stageProgram(&Config{StageLocal: true}, program, redact.New(redact.ModeNone), logger.NewNoOpLogger())
+	require.NoError(t, err)
+	assert.NotEqual(t, program, path)
+	assert.FileExists(t, path)
+
+	// Simulate the compiler writing an output next to the staged program
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "program.lpz"), []byte("lpz"), 0o644))
+
+	finish(true)
+
+	assert.FileExists(t, filepath.Join(dir, "program.lpz"))
+	assert.NoDirExists(t, filepath.Dir(path), "workspace is removed")
+}
+
+func TestStageProgram_FailedCompileLeavesSourceUntouched(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	program := filepath.Join(dir, "program.smw")
+	require.NoError(t, os.WriteFile(program, []byte("program"), 0o644))
+
+	path, finish, err := stageProgram(&Config{StageLocal: true}, program, redact.New(redact.ModeNone), logger.NewNoOpLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "program.lpz"), []byte("partial"), 0o644))
+
+	finish(false)
+
+	assert.NoFileExists(t, filepath.Join(dir, "program.lpz"))
+	assert.NoDirExists(t, filepath.Dir(path))
+}
+
+func TestStageProgram_SandboxNeverTouchesOriginal(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	program := filepath.Join(dir, "program.smw")
+	require.NoError(t, os.WriteFile(program, []byte("program"), 0o644))
+
+	path, finish, err := stageProgram(&Config{Sandbox: true}, program, redact.New(redact.ModeNone), logger.NewNoOpLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(filepath.Dir(path)) })
+
+	// An auto-confirmed save prompt rewrites the compiled copy
+	require.NoError(t, os.WriteFile(path, []byte("program (saved)"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "program.lpz"), []byte("lpz"), 0o644))
+
+	finish(true)
+
+	data, err := os.ReadFile(program)
+	require.NoError(t, err)
+	assert.Equal(t, "program", string(data), "the original is never modified")
+	assert.NoFileExists(t, filepath.Join(dir, "program.lpz"), "artifacts stay in the sandbox")
+	assert.FileExists(t, filepath.Join(filepath.Dir(path), "program.lpz"))
+}
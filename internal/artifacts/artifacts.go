@@ -0,0 +1,97 @@
+// Package artifacts collects compile outputs into a destination directory named from
+// a template, so CI runs can keep source directories clean.
+package artifacts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/fileutil"
+)
+
+// DefaultTemplate names the output directory after the program
+const DefaultTemplate = "{program}"
+
+// Vars are the values available to a name template
+type Vars struct {
+	Program string    // Program file name without extension
+	Version string    // User-supplied version, e.g. a CI build number
+	Time    time.Time // When the compile ran
+}
+
+var placeholder = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// unsafeChars are replaced in substituted values so they cannot change the path
+var unsafeChars = strings.NewReplacer(`\`, "_", "/", "_", ":", "_", "*", "_", "?", "_", `"`, "_", "<", "_", ">", "_", "|", "_")
+
+// RenderName expands a template such as "{program}-{version}-{timestamp}". Supported
+// placeholders are {program}, {version}, {timestamp} (20060102-150405), {date}
+// (2006-01-02) and {time} (150405), all in UTC. The result must be a relative path.
+func RenderName(template string, vars Vars) (string, error) {
+	values := map[string]string{
+		"program":   vars.Program,
+		"version":   vars.Version,
+		"timestamp": vars.Time.UTC().Format("20060102-150405"),
+		"date":      vars.Time.UTC().Format("2006-01-02"),
+		"time":      vars.Time.UTC().Format("150405"),
+	}
+
+	var unknown []string
+
+	name := placeholder.ReplaceAllStringFunc(template, func(match string) string {
+		key := match[1 : len(match)-1]
+
+		value, ok := values[key]
+		if !ok {
+			unknown = append(unknown, match)
+			return match
+		}
+
+		return unsafeChars.Replace(value)
+	})
+
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown placeholder %s in output name %q", strings.Join(unknown, ", "), template)
+	}
+
+	if vars.Version == "" && strings.Contains(template, "{version}") {
+		return "", fmt.Errorf("output name %q uses {version} but no version was given", template)
+	}
+
+	name = filepath.Clean(filepath.FromSlash(name))
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("output name %q must be a relative path inside the output directory", template)
+	}
+
+	return name, nil
+}
+
+// Collect copies each file in files, which must be inside srcDir, to the same relative
+// location under destDir, and returns the destination paths
+func Collect(srcDir string, files []string, destDir string) ([]string, error) {
+	copied := make([]string, 0, len(files))
+
+	for _, src := range files {
+		rel, err := filepath.Rel(srcDir, src)
+		if err != nil || !filepath.IsLocal(rel) {
+			return copied, fmt.Errorf("%s is not inside %s", src, srcDir)
+		}
+
+		dst := filepath.Join(destDir, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return copied, fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+		}
+
+		if err := fileutil.CopyFile(src, dst); err != nil {
+			return copied, fmt.Errorf("failed to copy %s: %w", rel, err)
+		}
+
+		copied = append(copied, dst)
+	}
+
+	return copied, nil
+}
@@ -0,0 +1,102 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderName(t *testing.T) {
+	t.Parallel()
+
+	vars := Vars{
+		Program: "Lobby",
+		Version: "1.4/rc1",
+		Time:    time.Date(2026, 3, 9, 14, 5, 7, 0, time.UTC),
+	}
+
+	tests := []struct {
+		template string
+		want     string
+		wantErr  string
+	}{
+		{template: DefaultTemplate, want: "Lobby"},
+		{template: "{program}-{version}-{timestamp}", want: "Lobby-1.4_rc1-20260309-140507"},
+		{template: "{date}/{program}_{time}", want: filepath.Join("2026-03-09", "Lobby_140507")},
+		{template: "{program}-{build}", wantErr: "unknown placeholder {build}"},
+		{template: "../{program}", wantErr: "relative path"},
+		{template: ".", want: "."},
+	}
+
+	for _, tt := range tests {
+		got, err := RenderName(tt.template, vars)
+		if tt.wantErr != "" {
+			assert.ErrorContains(t, err, tt.wantErr, tt.template)
+			continue
+		}
+
+		require.NoError(t, err, tt.template)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := RenderName("{program}-{version}", Vars{Program: "Lobby"})
+	assert.ErrorContains(t, err, "no version")
+}
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "SPlsWork"), 0o755))
+
+	files := []string{filepath.Join(src, "Lobby.lpz"), filepath.Join(src, "SPlsWork", "Helper.dll")}
+	for _, f := range files {
+		require.NoError(t, os.WriteFile(f, []byte(filepath.Base(f)), 0o644))
+	}
+
+	dest := filepath.Join(t.TempDir(), "Lobby")
+
+	copied, err := Collect(src, files, dest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dest, "Lobby.lpz"), filepath.Join(dest, "SPlsWork", "Helper.dll")}, copied)
+
+	data, err := os.ReadFile(filepath.Join(dest, "SPlsWork", "Helper.dll"))
+	require.NoError(t, err)
+	assert.Equal(t, "Helper.dll", string(data))
+
+	_, err = Collect(src, []string{filepath.Join(t.TempDir(), "elsewhere.lpz")}, dest)
+	assert.ErrorContains(t, err, "is not inside")
+}
+
+func TestRemoveTemp(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	files := []string{filepath.Join(dir, "Lobby.lpz"), filepath.Join(dir, "Lobby.TMP"), filepath.Join(dir, "scratch.$$$")}
+	for _, f := range files {
+		require.NoError(t, os.WriteFile(f, nil, 0o644))
+	}
+
+	removed, err := RemoveTemp(files, DefaultTempPatterns)
+	require.NoError(t, err)
+	assert.Equal(t, files[1:], removed)
+
+	assert.FileExists(t, files[0])
+	assert.NoFileExists(t, files[1])
+
+	_, err = RemoveTemp([]string{filepath.Join(dir, "gone.tmp")}, DefaultTempPatterns)
+	assert.ErrorContains(t, err, "failed to remove")
+}
+
+func TestValidatePatterns(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidatePatterns(DefaultTempPatterns))
+	assert.ErrorContains(t, ValidatePatterns([]string{"[a-"}), "invalid temp pattern")
+	assert.ErrorContains(t, ValidatePatterns([]string{`SPlsWork\*.tmp`}), "not paths")
+}
@@ -0,0 +1,60 @@
+package artifacts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultTempPatterns match the scratch files SIMPL Windows leaves next to a program
+var DefaultTempPatterns = []string{"*.tmp", "*.$$$"}
+
+// ValidatePatterns checks that each pattern is a valid file name pattern
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, `\/`) {
+			return fmt.Errorf("temp pattern %q must match file names, not paths", pattern)
+		}
+
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid temp pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// RemoveTemp deletes the files whose names (case-insensitively) match any of patterns
+// and returns the paths removed. It keeps going after a failure and returns the first error.
+func RemoveTemp(files, patterns []string) (removed []string, err error) {
+	for _, file := range files {
+		if !matchesAny(filepath.Base(file), patterns) {
+			continue
+		}
+
+		if rmErr := os.Remove(file); rmErr != nil {
+			if err == nil {
+				err = fmt.Errorf("failed to remove %s: %w", file, rmErr)
+			}
+
+			continue
+		}
+
+		removed = append(removed, file)
+	}
+
+	return removed, err
+}
+
+func matchesAny(name string, patterns []string) bool {
+	name = strings.ToLower(name)
+
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+
+	return false
+}
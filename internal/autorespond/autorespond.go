@@ -0,0 +1,156 @@
+// Package autorespond loads policies that answer dialogs smpc does not otherwise
+// handle, such as site-specific add-in prompts, by clicking a button or pressing a key.
+package autorespond
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Norgate-AV/smpc/internal/keychord"
+)
+
+// Rule answers dialogs whose title matches Title and that meet its other conditions,
+// if set. Exactly one of Button and Key is set.
+type Rule struct {
+	Title  string `json:"title"`            // Regular expression matched against the dialog title
+	Button string `json:"button,omitempty"` // Caption of the button to click, e.g. "&OK"
+	Key    string `json:"key,omitempty"`    // Key chord to press, e.g. "enter" or "alt+n"
+
+	Class     string `json:"class,omitempty"`     // Window class the dialog must have, e.g. "#32770"
+	Modal     *bool  `json:"modal,omitempty"`     // Whether the dialog must, or must not, be modal
+	HasButton string `json:"hasButton,omitempty"` // Caption of a button the dialog must have
+
+	title *regexp.Regexp
+	key   keychord.Chord
+}
+
+// Policy is the contents of a policy file
+type Policy struct {
+	AutoRespond []Rule `json:"autoRespond"`
+}
+
+// Load reads and validates the policy file at path
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auto-respond policy: %w", err)
+	}
+
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auto-respond policy %s: %w", path, err)
+	}
+
+	return p, nil
+}
+
+// Parse decodes and validates a policy from JSON
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+
+	for i := range p.AutoRespond {
+		if err := p.AutoRespond[i].compile(); err != nil {
+			return nil, fmt.Errorf("autoRespond[%d]: %w", i, err)
+		}
+	}
+
+	return &p, nil
+}
+
+// compile validates r and prepares its title pattern and key chord
+func (r *Rule) compile() error {
+	if r.Title == "" {
+		return fmt.Errorf("missing title")
+	}
+
+	title, err := regexp.Compile(r.Title)
+	if err != nil {
+		return fmt.Errorf("invalid title pattern: %w", err)
+	}
+
+	r.title = title
+
+	switch {
+	case r.Button != "" && r.Key != "":
+		return fmt.Errorf("rule for %q sets both button and key", r.Title)
+	case r.Button == "" && r.Key == "":
+		return fmt.Errorf("rule for %q sets neither button nor key", r.Title)
+	case r.Key != "":
+		if r.key, err = keychord.Parse(r.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Dialog is what a rule is matched against
+type Dialog struct {
+	Title   string
+	Class   string
+	Modal   bool
+	Buttons []string // Button captions, as written (e.g. "&OK")
+}
+
+// Match returns the first rule matching a dialog known only by its title, so rules
+// with other conditions never match. A nil policy matches nothing.
+func (p *Policy) Match(title string) (*Rule, bool) {
+	return p.MatchDialog(Dialog{Title: title})
+}
+
+// MatchDialog returns the first rule that matches d. A nil policy matches nothing.
+func (p *Policy) MatchDialog(d Dialog) (*Rule, bool) {
+	if p == nil {
+		return nil, false
+	}
+
+	for i := range p.AutoRespond {
+		if p.AutoRespond[i].matches(d) {
+			return &p.AutoRespond[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func (r *Rule) matches(d Dialog) bool {
+	if !r.title.MatchString(d.Title) {
+		return false
+	}
+
+	if r.Class != "" && !strings.EqualFold(r.Class, d.Class) {
+		return false
+	}
+
+	if r.Modal != nil && *r.Modal != d.Modal {
+		return false
+	}
+
+	if r.HasButton == "" {
+		return true
+	}
+
+	for _, b := range d.Buttons {
+		if caption(b) == caption(r.HasButton) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// caption normalizes a button caption for comparison: "&Later" matches "later"
+func caption(s string) string {
+	return strings.ToLower(strings.TrimSpace(strings.ReplaceAll(s, "&", "")))
+}
+
+// KeyChord returns the parsed Key, or a zero chord for button rules
+func (r *Rule) KeyChord() keychord.Chord {
+	return r.key
+}
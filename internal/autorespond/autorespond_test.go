@@ -0,0 +1,109 @@
+package autorespond
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	p, err := Parse([]byte(`{
+		"autoRespond": [
+			{"title": "^Crestron Add-in", "button": "&OK"},
+			{"title": "(?i)license", "key": "alt+n"}
+		]
+	}`))
+	require.NoError(t, err)
+	require.Len(t, p.AutoRespond, 2)
+
+	rule, ok := p.Match("Crestron Add-in Update")
+	assert.True(t, ok)
+	assert.Equal(t, "&OK", rule.Button)
+	assert.True(t, rule.KeyChord().IsZero())
+
+	rule, ok = p.Match("LICENSE expiring")
+	assert.True(t, ok)
+	assert.Equal(t, "alt+n", rule.KeyChord().String())
+
+	_, ok = p.Match("Compile Complete")
+	assert.False(t, ok)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, doc := range []string{
+		`{"autoRespond": [{"button": "&OK"}]}`,
+		`{"autoRespond": [{"title": "(", "button": "&OK"}]}`,
+		`{"autoRespond": [{"title": "x"}]}`,
+		`{"autoRespond": [{"title": "x", "button": "&OK", "key": "enter"}]}`,
+		`{"autoRespond": [{"title": "x", "key": "hyper+q"}]}`,
+		`not json`,
+	} {
+		_, err := Parse([]byte(doc))
+		assert.Error(t, err, doc)
+	}
+}
+
+func TestMatch_FirstRuleWins(t *testing.T) {
+	t.Parallel()
+
+	p, err := Parse([]byte(`{"autoRespond": [{"title": "Update", "button": "&Later"}, {"title": ".*", "key": "esc"}]}`))
+	require.NoError(t, err)
+
+	rule, ok := p.Match("Update available")
+	assert.True(t, ok)
+	assert.Equal(t, "&Later", rule.Button)
+}
+
+func TestMatchDialog_Conditions(t *testing.T) {
+	t.Parallel()
+
+	p, err := Parse([]byte(`{"autoRespond": [
+		{"title": "Update", "modal": true, "hasButton": "Later", "button": "&Later"},
+		{"title": "Update", "class": "#32770", "key": "esc"}
+	]}`))
+	require.NoError(t, err)
+
+	rule, ok := p.MatchDialog(Dialog{Title: "Update", Class: "#32770", Modal: true, Buttons: []string{"&Now", "&Later"}})
+	require.True(t, ok)
+	assert.Equal(t, "&Later", rule.Button)
+
+	rule, ok = p.MatchDialog(Dialog{Title: "Update", Class: "#32770", Buttons: []string{"&Later"}})
+	require.True(t, ok, "not modal, so only the second rule applies")
+	assert.Equal(t, "esc", rule.Key)
+
+	_, ok = p.MatchDialog(Dialog{Title: "Update", Class: "TSplash", Modal: true, Buttons: []string{"OK"}})
+	assert.False(t, ok)
+
+	_, ok = p.Match("Update")
+	assert.False(t, ok, "a title alone does not meet the other conditions")
+}
+
+func TestMatch_NilPolicy(t *testing.T) {
+	t.Parallel()
+
+	var p *Policy
+
+	_, ok := p.Match("anything")
+	assert.False(t, ok)
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"autoRespond": [{"title": "x", "button": "&OK"}]}`), 0o644))
+
+	p, err := Load(path)
+	require.NoError(t, err)
+	assert.Len(t, p.AutoRespond, 1)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+// Package buildorder works out the order to build the programs and modules of a
+// project directory, so modules are built before the programs that use them.
+package buildorder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Norgate-AV/smpc/internal/smw"
+)
+
+// Kind is the type of file a node builds
+type Kind string
+
+const (
+	KindProgram    Kind = "program"     // .smw
+	KindUserModule Kind = "user-module" // .umc
+	KindSimplPlus  Kind = "simpl+"      // .usp
+)
+
+// ErrCycle is returned by Order when modules depend on each other in a loop
+var ErrCycle = errors.New("dependency cycle")
+
+// Node is a file in the project and the project files it depends on
+type Node struct {
+	Path string
+	Kind Kind
+	Deps []string // Paths of the nodes that must be built first
+}
+
+// kindOf returns the kind of file path is, or false if it is not buildable
+func kindOf(path string) (Kind, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".smw":
+		return KindProgram, true
+	case smw.ExtUserModule:
+		return KindUserModule, true
+	case smw.ExtSimplPlus:
+		return KindSimplPlus, true
+	default:
+		return "", false
+	}
+}
+
+// Scan finds the programs and modules under dir and the modules each one uses.
+// Modules that are not in dir (such as those in the user module libraries) are
+// not part of the graph.
+func Scan(dir string) ([]Node, error) {
+	var nodes []Node
+
+	byName := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if kind, ok := kindOf(path); ok && d.Type().IsRegular() {
+			nodes = append(nodes, Node{Path: path, Kind: kind})
+			byName[strings.ToLower(d.Name())] = path
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	for i, n := range nodes {
+		if n.Kind == KindSimplPlus {
+			continue
+		}
+
+		program, err := smw.Open(n.Path)
+		if n.Kind == KindUserModule && errors.Is(err, smw.ErrNotProgram) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", n.Path, err)
+		}
+
+		for _, module := range program.Modules() {
+			if path, ok := byName[strings.ToLower(module)]; ok && path != n.Path {
+				nodes[i].Deps = append(nodes[i].Deps, path)
+			}
+		}
+	}
+
+	return nodes, nil
+}
+
+// Order groups nodes into levels: every node's dependencies are in earlier levels,
+// so the nodes of a level can be built in any order. Within a level, modules come
+// before programs and paths are sorted.
+func Order(nodes []Node) ([][]Node, error) {
+	pending := make(map[string]int, len(nodes))
+	dependents := make(map[string][]string)
+	byPath := make(map[string]Node, len(nodes))
+
+	for _, n := range nodes {
+		byPath[n.Path] = n
+		pending[n.Path] = len(n.Deps)
+
+		for _, dep := range n.Deps {
+			dependents[dep] = append(dependents[dep], n.Path)
+		}
+	}
+
+	var (
+		levels [][]Node
+		ready  []Node
+	)
+
+	for _, n := range nodes {
+		if pending[n.Path] == 0 {
+			ready = append(ready, n)
+		}
+	}
+
+	done := 0
+
+	for len(ready) > 0 {
+		sortLevel(ready)
+		levels = append(levels, ready)
+		done += len(ready)
+
+		var next []Node
+
+		for _, n := range ready {
+			for _, path := range dependents[n.Path] {
+				if pending[path]--; pending[path] == 0 {
+					next = append(next, byPath[path])
+				}
+			}
+		}
+
+		ready = next
+	}
+
+	if done < len(nodes) {
+		var stuck []string
+
+		for path, count := range pending {
+			if count > 0 {
+				stuck = append(stuck, filepath.Base(path))
+			}
+		}
+
+		sort.Strings(stuck)
+		return nil, fmt.Errorf("%w between %s", ErrCycle, strings.Join(stuck, ", "))
+	}
+
+	return levels, nil
+}
+
+func sortLevel(level []Node) {
+	sort.Slice(level, func(i, j int) bool {
+		if pi, pj := level[i].Kind == KindProgram, level[j].Kind == KindProgram; pi != pj {
+			return pj
+		}
+
+		return strings.ToLower(level[i].Path) < strings.ToLower(level[j].Path)
+	})
+}
+
+// WriteGraph prints the build order, one line per node with its dependencies.
+// Paths are shown relative to dir.
+func WriteGraph(w io.Writer, dir string, levels [][]Node) error {
+	rel := func(path string) string {
+		if r, err := filepath.Rel(dir, path); err == nil {
+			return r
+		}
+
+		return path
+	}
+
+	for i, level := range levels {
+		if _, err := fmt.Fprintf(w, "Level %d\n", i+1); err != nil {
+			return err
+		}
+
+		for _, n := range level {
+			line := fmt.Sprintf("  %s (%s)", rel(n.Path), n.Kind)
+
+			if len(n.Deps) > 0 {
+				deps := make([]string, len(n.Deps))
+				for j, dep := range n.Deps {
+					deps[j] = rel(dep)
+				}
+
+				line += " <- " + strings.Join(deps, ", ")
+			}
+
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package buildorder
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// program returns a minimal SIMPL Windows file using modules
+func program(modules ...string) string {
+	var b strings.Builder
+	b.WriteString("[\nVersion=1\n]\n[\nObjTp=FSgntr\nSgntr=SimplWindow\n]\n")
+
+	for i, m := range modules {
+		b.WriteString("[\nObjTp=Sm\nH=" + string(rune('1'+i)) + "\nNm=" + m + "\n]\n")
+	}
+
+	return b.String()
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	return dir
+}
+
+func TestScanAndOrder(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"Lobby.smw":               program("Volume.umc", "Library.umc"),
+		"Boardroom.smw":           program("Logic.usp"),
+		"modules/Volume.umc":      program("logic.usp"),
+		"modules/Logic.usp":       "// SIMPL+\n",
+		"notes.txt":               "ignored",
+		"modules/Unrelated.smw.b": "ignored",
+	})
+
+	nodes, err := Scan(dir)
+	require.NoError(t, err)
+	require.Len(t, nodes, 4)
+
+	levels, err := Order(nodes)
+	require.NoError(t, err)
+
+	names := func(level []Node) []string {
+		var out []string
+		for _, n := range level {
+			out = append(out, filepath.Base(n.Path))
+		}
+
+		return out
+	}
+
+	require.Len(t, levels, 3)
+	assert.Equal(t, []string{"Logic.usp"}, names(levels[0]))
+	assert.Equal(t, []string{"Volume.umc", "Boardroom.smw"}, names(levels[1]), "modules come before programs in a level")
+	assert.Equal(t, []string{"Lobby.smw"}, names(levels[2]), "Library.umc is not in the project")
+
+	var graph bytes.Buffer
+	require.NoError(t, WriteGraph(&graph, dir, levels))
+	assert.Contains(t, graph.String(), "Level 1\n  "+filepath.Join("modules", "Logic.usp")+" (simpl+)\n")
+	assert.Contains(t, graph.String(), "  Lobby.smw (program) <- "+filepath.Join("modules", "Volume.umc")+"\n")
+}
+
+func TestOrderCycle(t *testing.T) {
+	t.Parallel()
+
+	nodes := []Node{
+		{Path: "a.umc", Kind: KindUserModule, Deps: []string{"b.umc"}},
+		{Path: "b.umc", Kind: KindUserModule, Deps: []string{"a.umc"}},
+		{Path: "c.smw", Kind: KindProgram},
+	}
+
+	_, err := Order(nodes)
+	assert.ErrorIs(t, err, ErrCycle)
+	assert.ErrorContains(t, err, "a.umc, b.umc")
+}
+
+func TestScanInvalidProgram(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"Lobby.smw": "not a program\n",
+		"Other.umc": "not a program either\n",
+	})
+
+	_, err := Scan(dir)
+	assert.ErrorContains(t, err, "Lobby.smw")
+}
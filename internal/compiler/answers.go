@@ -0,0 +1,77 @@
+package compiler
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Norgate-AV/smpc/internal/keychord"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+)
+
+// Answers to the prompts Compile responds to on the user's behalf
+const (
+	AnswerYes   = "yes"
+	AnswerNo    = "no"
+	AnswerAbort = "abort" // Save prompt only: stop instead of compiling
+)
+
+// ValidateSavePrompt returns an error if answer is not a supported answer to the
+// "Convert/Compile" save prompt
+func ValidateSavePrompt(answer string) error {
+	switch answer {
+	case "", AnswerYes, AnswerNo, AnswerAbort:
+		return nil
+	default:
+		return fmt.Errorf("unsupported save prompt answer %q (supported: %s, %s, %s)", answer, AnswerYes, AnswerNo, AnswerAbort)
+	}
+}
+
+// ValidateCloseConfirmation returns an error if answer is not a supported answer to the
+// "Confirmation" prompt shown when SIMPL Windows is closed
+func ValidateCloseConfirmation(answer string) error {
+	switch answer {
+	case "", AnswerYes, AnswerNo:
+		return nil
+	default:
+		return fmt.Errorf("unsupported close confirmation answer %q (supported: %s, %s)", answer, AnswerYes, AnswerNo)
+	}
+}
+
+// answerButton returns the caption of the button for a yes/no answer
+func answerButton(answer string) string {
+	if answer == AnswerYes {
+		return "&Yes"
+	}
+
+	return "&No"
+}
+
+// declineSavePrompt answers "No" to the "Convert/Compile" save prompt, falling back to
+// the button's mnemonic key if it cannot be clicked
+func (c *Compiler) declineSavePrompt(hwnd uintptr) {
+	if c.controlReader.FindAndClickButton(hwnd, answerButton(AnswerNo)) {
+		return
+	}
+
+	c.log.Warn("Could not find 'No' button on save prompt, sending its mnemonic", slog.Uint64("hwnd", uint64(hwnd)))
+	c.keyboard.SendChord(keychord.MustParse("n"))
+}
+
+// confirmDialog presses a dialog's default button. With MessageInput it clicks the
+// first of captions found instead, falling back to focusing the dialog and pressing
+// Enter only when none is.
+func (c *Compiler) confirmDialog(opts CompileOptions, hwnd uintptr, captions ...string) {
+	if opts.MessageInput {
+		for _, caption := range captions {
+			if c.controlReader.FindAndClickButton(hwnd, caption) {
+				return
+			}
+		}
+
+		c.log.Warn("Could not click the dialog's button, pressing Enter", slog.Uint64("hwnd", uint64(hwnd)))
+	}
+
+	_ = c.windowMgr.SetForeground(hwnd)
+	c.clock.Sleep(timeouts.DialogResponseDelay)
+	c.keyboard.SendEnter()
+}
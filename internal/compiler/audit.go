@@ -0,0 +1,239 @@
+package compiler
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/clock"
+	"github.com/Norgate-AV/smpc/internal/interfaces"
+	"github.com/Norgate-AV/smpc/internal/keychord"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// Kinds of AuditEntry
+const (
+	AuditKeystroke   = "keystroke"    // A key chord was sent
+	AuditButtonClick = "button_click" // A dialog button was clicked
+	AuditWindowClose = "window_close" // WM_CLOSE was sent to a window
+	AuditForeground  = "foreground"   // A window was brought to the foreground
+	AuditMenuItem    = "menu_item"    // A menu command was invoked
+	AuditDDE         = "dde"          // A DDE command was executed
+	AuditMessage     = "message"      // A window message was sent or posted
+)
+
+// AuditEntry is one automated action taken against the SIMPL Windows GUI
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`           // One of the Audit kinds
+	Hwnd   uintptr   `json:"hwnd,omitempty"`   // Target window; for keystrokes without one, the window last brought to the foreground
+	Title  string    `json:"title,omitempty"`  // Title of the target window when known, or the DDE service and topic
+	Detail string    `json:"detail,omitempty"` // Key chord, button caption or ID, menu path, DDE command or window message
+	OK     bool      `json:"ok"`               // Whether the action reported success
+}
+
+// auditRecorder collects the actions taken during a compile, logging each one
+type auditRecorder struct {
+	log   logger.LoggerInterface
+	clock clock.Clock
+
+	mu         sync.Mutex
+	titles     map[uintptr]string // Titles of the windows seen, for entries that only know the hwnd
+	foreground uintptr            // Target of keystrokes sent to whichever window has focus
+	entries    []AuditEntry
+}
+
+func newAuditRecorder(log logger.LoggerInterface, clk clock.Clock) *auditRecorder {
+	return &auditRecorder{log: log, clock: clk, titles: make(map[uintptr]string)}
+}
+
+// reset starts a new audit trail for a compile of mainHwnd
+func (r *auditRecorder) reset(mainHwnd uintptr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.titles = make(map[uintptr]string)
+	r.foreground = mainHwnd
+	r.entries = nil
+
+	if mainHwnd != 0 {
+		r.titles[mainHwnd] = "SIMPL Windows"
+	}
+}
+
+// see remembers ev's title for later entries about its window
+func (r *auditRecorder) see(ev windows.WindowEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.titles[ev.Hwnd]; !ok || ev.Title != "" {
+		r.titles[ev.Hwnd] = ev.Title
+	}
+}
+
+// record adds an entry for action against hwnd; keystrokes without one go to the
+// foreground window
+func (r *auditRecorder) record(action string, hwnd uintptr, title, detail string, ok bool) {
+	r.mu.Lock()
+
+	if hwnd == 0 && action == AuditKeystroke {
+		hwnd = r.foreground
+	}
+
+	if title == "" {
+		title = r.titles[hwnd]
+	}
+
+	if action == AuditForeground && ok {
+		r.foreground = hwnd
+	}
+
+	entry := AuditEntry{Time: r.clock.Now(), Action: action, Hwnd: hwnd, Title: title, Detail: detail, OK: ok}
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+
+	r.log.Debug("GUI action",
+		slog.String("action", action),
+		slog.String("hwnd", fmt.Sprintf("0x%X", hwnd)),
+		slog.String("title", title),
+		slog.String("detail", detail),
+		slog.Bool("ok", ok),
+	)
+}
+
+// trail returns a copy of the entries recorded so far
+func (r *auditRecorder) trail() []AuditEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]AuditEntry{}, r.entries...)
+}
+
+// auditedWindowManager records the window operations that act on the GUI
+type auditedWindowManager struct {
+	interfaces.WindowManager
+	audit *auditRecorder
+}
+
+func (w auditedWindowManager) CloseWindow(hwnd uintptr, title string) {
+	w.WindowManager.CloseWindow(hwnd, title)
+	w.audit.record(AuditWindowClose, hwnd, "", "", true)
+}
+
+func (w auditedWindowManager) SetForeground(hwnd uintptr) bool {
+	ok := w.WindowManager.SetForeground(hwnd)
+	w.audit.record(AuditForeground, hwnd, "", "", ok)
+	return ok
+}
+
+func (w auditedWindowManager) InvokeMenuItem(hwnd uintptr, path ...string) bool {
+	ok := w.WindowManager.InvokeMenuItem(hwnd, path...)
+	w.audit.record(AuditMenuItem, hwnd, "", strings.Join(path, " > "), ok)
+	return ok
+}
+
+func (w auditedWindowManager) SendMessage(hwnd uintptr, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	result, ok := w.WindowManager.SendMessage(hwnd, msg, wParam, lParam)
+	w.audit.record(AuditMessage, hwnd, "", messageDetail("SendMessage", msg, wParam, lParam), ok)
+	return result, ok
+}
+
+func (w auditedWindowManager) PostMessage(hwnd uintptr, msg uint32, wParam, lParam uintptr) bool {
+	ok := w.WindowManager.PostMessage(hwnd, msg, wParam, lParam)
+	w.audit.record(AuditMessage, hwnd, "", messageDetail("PostMessage", msg, wParam, lParam), ok)
+	return ok
+}
+
+func (w auditedWindowManager) ClickButtonById(parentHwnd uintptr, id int) bool {
+	ok := w.WindowManager.ClickButtonById(parentHwnd, id)
+	w.audit.record(AuditButtonClick, parentHwnd, "", fmt.Sprintf("id %d", id), ok)
+	return ok
+}
+
+// messageDetail describes a window message for the audit trail
+func messageDetail(how string, msg uint32, wParam, lParam uintptr) string {
+	return fmt.Sprintf("%s 0x%04X (wParam 0x%X, lParam 0x%X)", how, msg, wParam, lParam)
+}
+
+// auditedKeyboard records every keystroke sent
+type auditedKeyboard struct {
+	interfaces.KeyboardInjector
+	audit *auditRecorder
+}
+
+func (k auditedKeyboard) SendF12() {
+	k.KeyboardInjector.SendF12()
+	k.audit.record(AuditKeystroke, 0, "", "f12", true)
+}
+
+func (k auditedKeyboard) SendAltF12() {
+	k.KeyboardInjector.SendAltF12()
+	k.audit.record(AuditKeystroke, 0, "", "alt+f12", true)
+}
+
+func (k auditedKeyboard) SendEnter() {
+	k.KeyboardInjector.SendEnter()
+	k.audit.record(AuditKeystroke, 0, "", "enter", true)
+}
+
+func (k auditedKeyboard) SendF12ToWindow(hwnd uintptr) bool {
+	ok := k.KeyboardInjector.SendF12ToWindow(hwnd)
+	k.audit.record(AuditKeystroke, hwnd, "", "f12 (window message)", ok)
+	return ok
+}
+
+func (k auditedKeyboard) SendAltF12ToWindow(hwnd uintptr) bool {
+	ok := k.KeyboardInjector.SendAltF12ToWindow(hwnd)
+	k.audit.record(AuditKeystroke, hwnd, "", "alt+f12 (window message)", ok)
+	return ok
+}
+
+func (k auditedKeyboard) SendF12WithSendInput() bool {
+	ok := k.KeyboardInjector.SendF12WithSendInput()
+	k.audit.record(AuditKeystroke, 0, "", "f12 (SendInput)", ok)
+	return ok
+}
+
+func (k auditedKeyboard) SendAltF12WithSendInput() bool {
+	ok := k.KeyboardInjector.SendAltF12WithSendInput()
+	k.audit.record(AuditKeystroke, 0, "", "alt+f12 (SendInput)", ok)
+	return ok
+}
+
+func (k auditedKeyboard) SendChordWithSendInput(chord keychord.Chord) bool {
+	ok := k.KeyboardInjector.SendChordWithSendInput(chord)
+	k.audit.record(AuditKeystroke, 0, "", chord.String()+" (SendInput)", ok)
+	return ok
+}
+
+func (k auditedKeyboard) SendChord(chord keychord.Chord) {
+	k.KeyboardInjector.SendChord(chord)
+	k.audit.record(AuditKeystroke, 0, "", chord.String(), true)
+}
+
+// auditedControlReader records button clicks
+type auditedControlReader struct {
+	interfaces.ControlReader
+	audit *auditRecorder
+}
+
+func (c auditedControlReader) FindAndClickButton(parentHwnd uintptr, buttonText string) bool {
+	ok := c.ControlReader.FindAndClickButton(parentHwnd, buttonText)
+	c.audit.record(AuditButtonClick, parentHwnd, "", buttonText, ok)
+	return ok
+}
+
+// auditedDDE records DDE commands
+type auditedDDE struct {
+	interfaces.DDEClient
+	audit *auditRecorder
+}
+
+func (d auditedDDE) DDEExecute(service, topic, command string) error {
+	err := d.DDEClient.DDEExecute(service, topic, command)
+	d.audit.record(AuditDDE, 0, service+"|"+topic, command, err == nil)
+	return err
+}
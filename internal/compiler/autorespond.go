@@ -0,0 +1,60 @@
+package compiler
+
+import (
+	"log/slog"
+
+	"github.com/Norgate-AV/smpc/internal/autorespond"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// autoRespond answers a dialog not otherwise handled using the first matching rule of
+// CompileOptions.AutoRespond. Each dialog is answered once, and the SIMPL Windows main
+// window never is; it returns whether the dialog was answered.
+func (c *Compiler) autoRespond(opts CompileOptions, ev windows.WindowEvent) bool {
+	if ev.Hwnd == opts.Hwnd || c.responded[ev.Hwnd] {
+		return false
+	}
+
+	rule, ok := opts.AutoRespond.MatchDialog(AutoRespondDialog(ev))
+	if !ok {
+		return false
+	}
+
+	c.responded[ev.Hwnd] = true
+
+	if rule.Button != "" {
+		c.log.Info("Auto-responding to dialog", slog.String("title", ev.Title), slog.String("button", rule.Button))
+
+		if !c.controlReader.FindAndClickButton(ev.Hwnd, rule.Button) {
+			c.log.Warn("Could not find auto-response button", slog.String("title", ev.Title), slog.String("button", rule.Button))
+			return false
+		}
+
+		c.clock.Sleep(timeouts.DialogResponseDelay)
+		return true
+	}
+
+	chord := rule.KeyChord()
+	c.log.Info("Auto-responding to dialog", slog.String("title", ev.Title), slog.String("key", chord.String()))
+
+	_ = c.windowMgr.SetForeground(ev.Hwnd)
+	c.clock.Sleep(timeouts.DialogResponseDelay)
+
+	if !c.keyboard.SendChordWithSendInput(chord) {
+		c.keyboard.SendChord(chord)
+	}
+
+	c.clock.Sleep(timeouts.DialogResponseDelay)
+	return true
+}
+
+// AutoRespondDialog returns what auto-respond rules are matched against for ev
+func AutoRespondDialog(ev windows.WindowEvent) autorespond.Dialog {
+	return autorespond.Dialog{
+		Title:   ev.Title,
+		Class:   ev.Class,
+		Modal:   ev.Modal,
+		Buttons: ev.Buttons,
+	}
+}
@@ -2,12 +2,18 @@
 package compiler
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Norgate-AV/smpc/internal/autorespond"
+	"github.com/Norgate-AV/smpc/internal/clock"
+	"github.com/Norgate-AV/smpc/internal/hints"
 	"github.com/Norgate-AV/smpc/internal/interfaces"
+	"github.com/Norgate-AV/smpc/internal/keychord"
 	"github.com/Norgate-AV/smpc/internal/logger"
 	"github.com/Norgate-AV/smpc/internal/simpl"
 	"github.com/Norgate-AV/smpc/internal/timeouts"
@@ -33,14 +39,30 @@ const (
 
 // CompileResult holds the results of a compilation
 type CompileResult struct {
-	Warnings        int
-	Notices         int
-	Errors          int
-	CompileTime     float64
-	ErrorMessages   []string
-	WarningMessages []string
-	NoticeMessages  []string
-	HasErrors       bool
+	Warnings          int
+	Notices           int
+	Errors            int
+	CompileTime       float64
+	ErrorMessages     []string
+	WarningMessages   []string
+	NoticeMessages    []string
+	HasErrors         bool
+	Timing            TimingBreakdown
+	Stats             map[string]float64 // Every "Name: value" statistic shown in "Compile Complete", keyed by name
+	DialogTranscripts []DialogTranscript // Every dialog seen, when CompileOptions.CaptureTranscripts is set
+	Audit             []AuditEntry       // Every keystroke, click and close sent to the GUI, in order
+	SkippedDialogs    []SkippedDialog    // Dialogs seen but dismissed unread or left open, in order
+	Mode              string             // How the result was produced (ModeCompile, ModeRecompileAll or ModeRecompileAllRetry)
+}
+
+// PromoteWarnings reclassifies all warnings as errors, for --warnings-as-errors.
+// Messages keep their original text but move to ErrorMessages.
+func (r *CompileResult) PromoteWarnings() {
+	r.Errors += r.Warnings
+	r.ErrorMessages = append(r.ErrorMessages, r.WarningMessages...)
+	r.Warnings = 0
+	r.WarningMessages = nil
+	r.HasErrors = r.Errors > 0 || len(r.ErrorMessages) > 0
 }
 
 // CompileOptions holds options for the compilation
@@ -48,10 +70,28 @@ type CompileOptions struct {
 	FilePath                      string
 	RecompileAll                  bool
 	Hwnd                          uintptr
-	SimplPid                      uint32        // Known PID from ShellExecuteEx (preferred over searching)
-	SimplPidPtr                   *uint32       // Pointer to store PID for signal handlers
-	SkipPreCompilationDialogCheck bool          // For testing - skip the pre-compilation dialog check
-	CompilationTimeout            time.Duration // Override default timeout (0 = use default 5 minutes)
+	SimplPid                      uint32                 // Known PID from ShellExecuteEx (preferred over searching)
+	SimplPidPtr                   *uint32                // Pointer to store PID for signal handlers
+	SkipPreCompilationDialogCheck bool                   // For testing - skip the pre-compilation dialog check
+	CompilationTimeout            time.Duration          // Override default timeout (0 = use default 5 minutes)
+	WarningsAsErrors              bool                   // Reclassify warnings as errors in counts, messages and exit status
+	CompileKey                    keychord.Chord         // Compile accelerator (zero = F12)
+	RecompileAllKey               keychord.Chord         // Recompile All accelerator (zero = Alt+F12)
+	Backend                       string                 // How compilation is triggered (BackendGUI or BackendDDE; "" = GUI)
+	MessageInput                  bool                   // Drive SIMPL Windows with window messages only, never focusing it (it is minimized, hidden or off-screen)
+	Events                        interfaces.EventSource // Window events from the background monitor (nil disables dialog handling)
+	CaptureTranscripts            bool                   // Record the text of every dialog in CompileResult.DialogTranscripts
+	SaveFirst                     bool                   // Save the program (Ctrl+S or File > Save) before triggering the compile
+	SavePrompt                    string                 // Answer to the "Convert/Compile" save prompt (AnswerYes, AnswerNo or AnswerAbort; "" = yes)
+	CloseConfirmation             string                 // Answer to the "Confirmation" prompt when closing (AnswerYes or AnswerNo; "" = no)
+	AutoRespond                   *autorespond.Policy    // Answers for dialogs not otherwise handled (nil = leave them alone)
+	AutoRecompileAll              bool                   // Retry once with Recompile All when errors point to a database change
+	Hints                         *hints.KB              // Explanations logged under matching messages (nil = none)
+	Exited                        <-chan int             // Receives the exit code if SIMPL Windows exits (nil = not watched)
+
+	// Compile state machine hooks (see Stage)
+	StageTimeouts map[Stage]time.Duration // Per-stage timeout overrides; 0 disables a stage's timeout (see defaultStageTimeouts)
+	OnStageChange func(from, to Stage)    // Optional; called on the compiling goroutine as each stage begins
 }
 
 // CompileDependencies holds all external dependencies for testing
@@ -60,6 +100,8 @@ type CompileDependencies struct {
 	WindowMgr     interfaces.WindowManager
 	Keyboard      interfaces.KeyboardInjector
 	ControlReader interfaces.ControlReader
+	DDE           interfaces.DDEClient // Optional; required only for BackendDDE
+	Clock         clock.Clock          // Optional; defaults to the system clock
 }
 
 // Compiler orchestrates the compilation process with injected dependencies
@@ -69,42 +111,140 @@ type Compiler struct {
 	windowMgr     interfaces.WindowManager
 	keyboard      interfaces.KeyboardInjector
 	controlReader interfaces.ControlReader
+	dde           interfaces.DDEClient
+	clock         clock.Clock
+
+	cancelled  chan struct{} // Closed by Cancel
+	cancelOnce sync.Once
+
+	transcripts *transcriptRecorder // Set by Compile when CaptureTranscripts is requested
+	audit       *auditRecorder      // Every action taken through the dependencies above
+	skipped     *skipRecorder       // Dialogs seen but not acted on
+	responded   map[uintptr]bool    // Dialogs already answered by the AutoRespond policy
+
+	stage         Stage // Current stage of Compile
+	onStageChange func(from, to Stage)
 }
 
 // NewCompiler creates a new Compiler with the provided logger and default dependencies
 func NewCompiler(log logger.LoggerInterface) *Compiler {
 	windowsAPI := windows.NewWindowsAPI(log)
-	simplAPI := simpl.SimplProcessAPI{}
+	simplAPI := simpl.NewSimplProcessAPI(log)
 
-	return &Compiler{
+	return (&Compiler{
 		log:           log,
 		processMgr:    simplAPI,
 		windowMgr:     windowsAPI,
 		keyboard:      windowsAPI,
 		controlReader: windowsAPI,
-	}
+		dde:           windowsAPI,
+		clock:         clock.New(),
+		cancelled:     make(chan struct{}),
+	}).audited()
 }
 
 // NewCompilerWithDeps creates a new Compiler with custom dependencies for testing
 func NewCompilerWithDeps(log logger.LoggerInterface, deps *CompileDependencies) *Compiler {
-	return &Compiler{
+	clk := deps.Clock
+	if clk == nil {
+		clk = clock.New()
+	}
+
+	return (&Compiler{
 		log:           log,
 		processMgr:    deps.ProcessMgr,
 		windowMgr:     deps.WindowMgr,
 		keyboard:      deps.Keyboard,
 		controlReader: deps.ControlReader,
+		dde:           deps.DDE,
+		clock:         clk,
+		cancelled:     make(chan struct{}),
+	}).audited()
+}
+
+// audited wraps the dependencies that act on the GUI, so every action they take is
+// recorded in the audit trail, and sets up the record of dialogs not acted on
+func (c *Compiler) audited() *Compiler {
+	c.audit = newAuditRecorder(c.log, c.clock)
+	c.skipped = newSkipRecorder(c.log, c.clock)
+
+	if c.windowMgr != nil {
+		c.windowMgr = auditedWindowManager{WindowManager: c.windowMgr, audit: c.audit}
+	}
+
+	if c.keyboard != nil {
+		c.keyboard = auditedKeyboard{KeyboardInjector: c.keyboard, audit: c.audit}
+	}
+
+	if c.controlReader != nil {
+		c.controlReader = auditedControlReader{ControlReader: c.controlReader, audit: c.audit}
+	}
+
+	if c.dde != nil {
+		c.dde = auditedDDE{DDEClient: c.dde, audit: c.audit}
+	}
+
+	return c
+}
+
+// Cancel stops an in-progress Compile: dialog waiting ends, the compile is dismissed if
+// possible and SIMPL Windows is closed, and Compile returns ErrCancelled. Cancel is safe
+// to call from any goroutine and more than once; a cancelled Compiler cannot be reused.
+func (c *Compiler) Cancel() {
+	c.cancelOnce.Do(func() {
+		c.log.Info("Cancelling compilation")
+		close(c.cancelled)
+	})
+}
+
+// isCancelled reports whether Cancel has been called
+func (c *Compiler) isCancelled() bool {
+	select {
+	case <-c.cancelled:
+		return true
+	default:
+		return false
 	}
 }
 
 // Compile orchestrates the compilation process for a SIMPL Windows file
 // This includes:
 // - Handling pre-compilation dialogs
+// - Saving the program, when requested
 // - Triggering the compile
 // - Monitoring compilation progress
 // - Parsing results
 // - Closing dialogs
-func (c *Compiler) Compile(opts CompileOptions) (*CompileResult, error) {
-	result := &CompileResult{}
+func (c *Compiler) Compile(opts CompileOptions) (result *CompileResult, err error) {
+	c.stage = StageIdle
+	c.onStageChange = opts.OnStageChange
+	c.responded = make(map[uintptr]bool)
+	c.audit.reset(opts.Hwnd)
+	c.skipped.reset(opts.Hwnd)
+
+	defer func() {
+		if result != nil && result.Mode == "" {
+			result.Mode = modeFor(opts)
+		}
+
+		if result != nil {
+			result.Audit = c.audit.trail()
+			result.SkippedDialogs = c.skipped.list()
+		}
+	}()
+
+	if opts.CaptureTranscripts {
+		c.transcripts = newTranscriptRecorder(opts.Hwnd)
+
+		// Attach whatever was seen, however Compile ends
+		defer func() {
+			if result != nil {
+				result.DialogTranscripts = c.recordedTranscripts()
+			}
+		}()
+	}
+
+	result = &CompileResult{}
 
 	// Use the exact PID from ShellExecuteEx - no searching, no guessing
 	pid := opts.SimplPid
@@ -118,303 +258,648 @@ func (c *Compiler) Compile(opts CompileOptions) (*CompileResult, error) {
 		}
 	}
 
-	// Confirm elevation before sending keystrokes
-	if c.windowMgr.IsElevated() {
-		c.log.Debug("Process is elevated, proceeding with keystroke injection")
-	} else {
-		c.log.Warn("Process is NOT elevated, keystroke injection may fail")
+	if opts.Backend == BackendDDE {
+		// DDE commands do not need keyboard focus; the window is only focused
+		// if the command fails and the keystroke fallback is used
+		c.log.Debug("Using DDE backend - skipping foreground checks")
+	} else if opts.MessageInput {
+		c.log.Debug("Using window messages only - skipping foreground checks")
+	} else if result, err := c.focusSimplWindow(opts, pid); err != nil {
+		return result, err
 	}
 
-	// Bring window to foreground and send compile keystroke
-	c.log.Debug("Bringing window to foreground")
-	focusSuccess := c.windowMgr.SetForeground(opts.Hwnd)
-	if !focusSuccess {
-		c.log.Warn("SetForeground failed on first attempt, retrying...")
-		time.Sleep(500 * time.Millisecond)
+	// Subscribe to window events, including any already published, so dialogs that
+	// appeared while SIMPL Windows was starting up are still seen
+	var events <-chan windows.WindowEvent
+	if pid != 0 {
+		if opts.Events != nil {
+			sub := opts.Events.SubscribeWithHistory(windows.DefaultSubscriptionBuffer)
+			defer sub.Unsubscribe()
 
-		focusSuccess = c.windowMgr.SetForeground(opts.Hwnd)
-		if !focusSuccess {
-			c.log.Error("Failed to bring window to foreground after retry")
-			return &CompileResult{
-				Errors:        1,
-				HasErrors:     true,
-				ErrorMessages: []string{"Failed to bring SIMPL Windows to foreground - cannot send keystrokes"},
-			}, fmt.Errorf("failed to bring SIMPL Windows to foreground - cannot send keystrokes")
+			events = sub.C
+		} else {
+			c.log.Warn("No window event source provided - dialog monitoring will be disabled")
 		}
 	}
 
-	time.Sleep(timeouts.FocusVerificationDelay)
-
-	// Verify the window is in the foreground before sending keystrokes
-	c.log.Debug("Verifying foreground window")
-	verified := c.windowMgr.VerifyForegroundWindow(opts.Hwnd, pid)
-	if !verified {
-		c.log.Error("Could not verify correct window is in foreground")
-		return &CompileResult{
-			Errors:        1,
-			HasErrors:     true,
-			ErrorMessages: []string{"Wrong window in foreground - cannot safely send keystrokes"},
-		}, fmt.Errorf("wrong window in foreground - cannot safely send keystrokes")
-	}
-
 	// Handle any pre-compilation dialogs (like "Operation Complete") that may be blocking
 	// Skip this in test mode since tests send all events upfront
-	if pid != 0 && !opts.SkipPreCompilationDialogCheck {
-		if err := c.handlePreCompilationDialogs(); err != nil {
+	var preDialogTime time.Duration
+	if events != nil && !opts.SkipPreCompilationDialogCheck {
+		start := c.clock.Now()
+		if err := c.handlePreCompilationDialogs(opts, events); err != nil && !errors.Is(err, ErrCancelled) {
 			c.log.Warn("Error handling pre-compilation dialogs", slog.Any("error", err))
 		}
+
+		preDialogTime = c.clock.Since(start)
 	}
 
-	var success bool
-	if opts.RecompileAll {
-		// Try SendInput first (modern API, atomic operation)
-		success = c.keyboard.SendAltF12WithSendInput()
-		if !success {
-			c.log.Warn("SendAltF12WithSendInput failed, falling back to keybd_event")
-			c.keyboard.SendAltF12()
-		} else {
-			c.log.Debug("SendAltF12WithSendInput succeeded")
-		}
-	} else {
-		// Try SendInput first (modern API, atomic operation)
-		success = c.keyboard.SendF12WithSendInput()
-		if !success {
-			c.log.Warn("SendF12WithSendInput failed, falling back to keybd_event")
-			c.keyboard.SendF12()
-		} else {
-			c.log.Debug("SendF12WithSendInput succeeded")
+	if opts.SaveFirst {
+		if err := c.saveProgram(opts); errors.Is(err, ErrCancelled) {
+			return result, c.cleanupCancelled(opts)
+		} else if err != nil {
+			return result, err
 		}
 	}
 
+	if c.isCancelled() {
+		return result, c.cleanupCancelled(opts)
+	}
+
+	keystrokeAt := c.clock.Now()
+
+	// Try SendInput first (modern API, atomic operation), falling back to keybd_event
+	// (or DDE first, with the keystrokes as a fallback, on the DDE backend)
+	strategy := c.triggerCompile(opts, firstTriggerStrategy(opts))
+	c.log.Debug("Compile triggered", slog.String("strategy", strategy.String()))
+	c.enterStage(StageTriggered)
+
 	c.log.Debug("Starting compile monitoring")
 
-	// Only attempt dialog handling if we have a valid PID
+	// Only attempt dialog handling if we have a valid PID and event source
 	var compileCompleteHwnd uintptr
 
-	if pid != 0 {
+	if events != nil {
 		// Use event-driven dialog handling
 		var err error
 		var eventResult *CompileResult
-		compileCompleteHwnd, eventResult, err = c.handleCompilationEvents(opts)
+		compileCompleteHwnd, eventResult, err = c.handleCompilationEvents(opts, events, keystrokeAt, strategy)
+		if errors.Is(err, ErrCancelled) {
+			return eventResult, c.cleanupCancelled(opts)
+		}
+
 		if err != nil {
 			// Return the result even on error so caller can see what happened
 			return eventResult, err
 		}
 
+		if needsRecompileAll(opts, eventResult) {
+			compileCompleteHwnd, eventResult, err = c.retryWithRecompileAll(opts, events, eventResult, compileCompleteHwnd)
+			if errors.Is(err, ErrCancelled) {
+				return eventResult, c.cleanupCancelled(opts)
+			}
+
+			if err != nil {
+				return eventResult, err
+			}
+		}
+
 		// Copy event result into our result
 		result = eventResult
 	}
 
+	result.Timing.DialogHandling += preDialogTime
+
 	// Close dialogs and handle post-compilation events
+	c.enterStage(StageClosing)
 	c.log.Debug("Closing dialogs and SIMPL Windows...")
+	cleanupStart := c.clock.Now()
 
 	// First, close the "Compile Complete" dialog if it's still open
 	if compileCompleteHwnd != 0 {
 		c.windowMgr.CloseWindow(compileCompleteHwnd, "Compile Complete dialog")
-		time.Sleep(timeouts.StabilityCheckInterval)
+		c.clock.Sleep(timeouts.StabilityCheckInterval)
 	}
 
 	// Close main window and handle any confirmation dialogs via events
-	if opts.Hwnd != 0 {
-		c.windowMgr.CloseWindow(opts.Hwnd, "SIMPL Windows")
-
-		// Handle confirmation dialog that may appear when closing
-		if pid != 0 {
-			if err := c.handlePostCompilationEvents(); err != nil {
-				// Return the result we have so far, even if cleanup failed
-				return result, err
-			}
-		}
-
-		time.Sleep(timeouts.CleanupDelay)
+	if err := c.closeSimplWindows(opts, events != nil); err != nil {
+		// Return the result we have so far, even if cleanup failed
+		return result, err
 	}
 
+	result.Timing.Cleanup = c.clock.Since(cleanupStart)
+
 	if result.HasErrors {
-		return result, fmt.Errorf("compilation failed with %d error(s)", result.Errors)
+		return result, ErrCompileErrors{Count: result.Errors}
 	}
 
 	return result, nil
 }
 
-// handleCompilationEvents uses an event-driven approach to respond to dialogs as they appear
-func (c *Compiler) handleCompilationEvents(opts CompileOptions) (uintptr, *CompileResult, error) {
+// closeSimplWindows closes the SIMPL Windows main window, answering the save prompt
+// that may appear with opts.CloseConfirmation when watchDialogs is set
+func (c *Compiler) closeSimplWindows(opts CompileOptions, watchDialogs bool) error {
+	if opts.Hwnd == 0 {
+		return nil
+	}
+
+	// Give the post-compilation handler its own subscription, taken before the close
+	// is requested, so it sees every dialog raised by closing regardless of what the
+	// compilation loop left unread
+	var closing *windows.Subscription
+	if watchDialogs && opts.Events != nil {
+		closing = opts.Events.Subscribe(windows.DefaultSubscriptionBuffer)
+		defer closing.Unsubscribe()
+	}
+
+	c.windowMgr.CloseWindow(opts.Hwnd, "SIMPL Windows")
+
+	// Handle confirmation dialog that may appear when closing
+	if closing != nil {
+		if err := c.handlePostCompilationEvents(closing.C, opts.CloseConfirmation); err != nil {
+			return err
+		}
+	}
+
+	c.clock.Sleep(timeouts.CleanupDelay)
+	return nil
+}
+
+// cleanupCancelled closes SIMPL Windows after Cancel and returns ErrCancelled
+func (c *Compiler) cleanupCancelled(opts CompileOptions) error {
+	c.log.Debug("Compilation cancelled, closing SIMPL Windows")
+	c.enterStage(StageClosing)
+
+	if err := c.closeSimplWindows(opts, opts.SimplPid != 0); err != nil {
+		c.log.Warn("Error closing SIMPL Windows after cancel", slog.Any("error", err))
+	}
+
+	return ErrCancelled
+}
+
+// focusSimplWindow brings SIMPL Windows to the foreground and verifies it is safe to send keystrokes
+func (c *Compiler) focusSimplWindow(opts CompileOptions, pid uint32) (*CompileResult, error) {
+	// Confirm elevation before sending keystrokes
+	if c.windowMgr.IsElevated() {
+		c.log.Debug("Process is elevated, proceeding with keystroke injection")
+	} else {
+		c.log.Warn("Process is NOT elevated, keystroke injection may fail")
+	}
+
+	// Bring window to foreground and send compile keystroke
+	c.log.Debug("Bringing window to foreground")
+	focusSuccess := c.windowMgr.SetForeground(opts.Hwnd)
+	if !focusSuccess {
+		c.log.Warn("SetForeground failed on first attempt, retrying...")
+		c.clock.Sleep(500 * time.Millisecond)
+
+		focusSuccess = c.windowMgr.SetForeground(opts.Hwnd)
+		if !focusSuccess {
+			c.log.Error("Failed to bring window to foreground after retry")
+			return &CompileResult{
+				Errors:        1,
+				HasErrors:     true,
+				ErrorMessages: []string{"Failed to bring SIMPL Windows to foreground - cannot send keystrokes"},
+			}, fmt.Errorf("failed to bring SIMPL Windows to foreground - cannot send keystrokes: %w", ErrForegroundLost)
+		}
+	}
+
+	c.clock.Sleep(timeouts.FocusVerificationDelay)
+
+	// Verify the window is in the foreground before sending keystrokes
+	c.log.Debug("Verifying foreground window")
+	verified := c.windowMgr.VerifyForegroundWindow(opts.Hwnd, pid)
+	if !verified {
+		c.log.Error("Could not verify correct window is in foreground")
+		return &CompileResult{
+			Errors:        1,
+			HasErrors:     true,
+			ErrorMessages: []string{"Wrong window in foreground - cannot safely send keystrokes"},
+		}, fmt.Errorf("wrong window in foreground - cannot safely send keystrokes: %w", ErrForegroundLost)
+	}
+
+	return nil, nil
+}
+
+// compileRun is what handleCompilationEvents tracks while driving one compile
+type compileRun struct {
+	opts        CompileOptions
+	keystrokeAt time.Time
+	strategy    triggerStrategy
+	result      *CompileResult
+
+	deadline   time.Time   // When the compilation timeout expires
+	timeout    clock.Timer // Compilation timeout; nil once "Compile Complete" has been seen
+	stageTimer clock.Timer // Timeout of the current stage; nil when it has none
+
+	compilingHwnd       uintptr
+	compilingAt         time.Time
+	compileCompleteHwnd uintptr
+	programCompHwnd     uintptr
+}
+
+// timeoutC returns the channel of t, or nil (never ready) when there is no timer
+func timeoutC(t clock.Timer) <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+
+	return t.C()
+}
+
+// handleCompilationEvents drives the compile from StageTriggered until its results have
+// been collected, responding to dialogs as they appear.
+// keystrokeAt is when the compile keystroke was sent, used for the timing breakdown
+// and the time each dialog appeared.
+// Each time StageTriggered times out without a response, the trigger is retried with
+// the strategies after strategy (keybd_event, then the Project menu).
+func (c *Compiler) handleCompilationEvents(
+	opts CompileOptions,
+	events <-chan windows.WindowEvent,
+	keystrokeAt time.Time,
+	strategy triggerStrategy,
+) (uintptr, *CompileResult, error) {
 	// Maximum time to wait for compilation to complete
 	// Use custom timeout if specified, otherwise use default 5 minutes
 	compilationTimeout := timeouts.CompilationCompleteTimeout
 	if opts.CompilationTimeout > 0 {
 		compilationTimeout = opts.CompilationTimeout
 	}
-	timeout := time.NewTimer(compilationTimeout)
-	defer timeout.Stop()
 
-	result := &CompileResult{}
+	run := &compileRun{
+		opts:        opts,
+		keystrokeAt: keystrokeAt,
+		strategy:    strategy,
+		result:      &CompileResult{},
+		deadline:    c.clock.Now().Add(compilationTimeout),
+		timeout:     c.clock.NewTimer(compilationTimeout),
+	}
+
+	defer func() {
+		c.stopTimer(&run.timeout)
+		c.stopTimer(&run.stageTimer)
+	}()
 
-	// Track what we've seen and what we're waiting for
-	var (
-		compilingDetected       bool
-		compileCompleteDetected bool
-		compileCompleteHwnd     uintptr
-		programCompHwnd         uintptr
-	)
+	c.startStageTimer(run)
 
 	c.log.Debug("Entering event-driven dialog monitoring loop")
 
 	// Event loop - respond to dialogs as they appear in real-time
 	for {
 		select {
-		case ev := <-windows.MonitorCh:
+		case ev := <-events:
 			c.log.Debug("Received window event",
 				slog.String("title", ev.Title),
 				slog.Uint64("hwnd", uint64(ev.Hwnd)),
 			)
 
-			// Handle each dialog type as it appears
-			switch ev.Title {
-			case dialogIncompleteSymbols:
-				// Fatal error - compilation cannot proceed
-				c.log.Error("Incomplete Symbols detected", slog.String("title", ev.Title))
-				c.log.Info("The program contains incomplete symbols and cannot be compiled.")
-				c.log.Info("Please fix the incomplete symbols in SIMPL Windows before attempting to compile.")
-
-				// Extract error details
-				childInfos := c.windowMgr.CollectChildInfos(ev.Hwnd)
-				for _, ci := range childInfos {
-					if ci.ClassName == "Edit" && len(ci.Text) > 50 {
-						c.log.Info("Details", slog.String("text", ci.Text))
-						break
-					}
-				}
+			c.observe(ev)
 
-				// Close the dialog before returning
-				c.windowMgr.CloseWindow(ev.Hwnd, "Incomplete Symbols dialog")
+			if ev.Hwnd != opts.Hwnd {
+				at := c.clock.Since(keystrokeAt)
+				if run.result.Timing.recordDialog(ev.Title, ev.Hwnd, at) {
+					c.log.Debug("Dialog appeared",
+						slog.String("title", ev.Title),
+						slog.String("after", at.Round(time.Millisecond).String()),
+					)
+				}
+			}
 
+			if done, err := c.handleCompileEvent(run, ev); err != nil {
 				// Return the SIMPL Windows hwnd so test cleanup can close it properly
-				// Return a result indicating compilation failed
-				return opts.Hwnd, &CompileResult{
-					Errors:    1,
-					HasErrors: true,
-					ErrorMessages: []string{
-						"Incomplete Symbols: The program contains incomplete symbols and cannot be compiled",
-					},
-				}, fmt.Errorf("program contains incomplete symbols and cannot be compiled")
-
-			case dialogConvertCompile:
-				// Save prompt - auto-confirm
-				c.log.Debug("Handling 'Convert/Compile' dialog")
-				_ = c.windowMgr.SetForeground(ev.Hwnd)
-				time.Sleep(timeouts.DialogResponseDelay)
-				c.keyboard.SendEnter()
-				c.log.Info("Auto-confirmed save prompt")
-
-			case dialogCommentedOutSymbols:
-				// Confirmation dialog - auto-confirm
-				c.log.Debug("Handling 'Commented out Symbols and/or Devices' dialog")
-				_ = c.windowMgr.SetForeground(ev.Hwnd)
-				time.Sleep(timeouts.DialogResponseDelay)
-				c.keyboard.SendEnter()
-				c.log.Info("Auto-confirmed commented symbols dialog")
-
-			case dialogCompiling:
-				// Compilation in progress
-				if !compilingDetected {
-					c.log.Debug("Detected 'Compiling...' dialog")
-
-					if opts.RecompileAll {
-						c.log.Info("Compiling program... (Recompile All)")
-					} else {
-						c.log.Info("Compiling program...")
-					}
-
-					compilingDetected = true
-				}
+				return opts.Hwnd, run.result, err
+			} else if done {
+				return run.compileCompleteHwnd, run.result, nil
+			}
 
-			case dialogCompileComplete:
-				// Compilation finished - parse results
-				if !compileCompleteDetected {
-					c.log.Debug("Detected 'Compile Complete' dialog - parsing results")
-					compileCompleteHwnd = ev.Hwnd
-
-					// Parse statistics from dialog
-					childInfos := c.windowMgr.CollectChildInfos(ev.Hwnd)
-					for _, ci := range childInfos {
-						text := strings.ReplaceAll(ci.Text, "\r\n", "\n")
-						lines := strings.Split(text, "\n")
-
-						for _, line := range lines {
-							line = strings.TrimSpace(line)
-							if line == "" {
-								continue
-							}
-
-							if n, ok := ParseStatLine(line, "Program Warnings"); ok {
-								result.Warnings = n
-							}
-
-							if n, ok := ParseStatLine(line, "Program Notices"); ok {
-								result.Notices = n
-							}
-
-							if n, ok := ParseStatLine(line, "Program Errors"); ok {
-								result.Errors = n
-							}
-
-							if secs, ok := ParseCompileTimeLine(line); ok {
-								result.CompileTime = secs
-							}
-						}
-					}
-
-					compileCompleteDetected = true
-				}
+		case <-timeoutC(run.stageTimer):
+			run.stageTimer = nil
+
+			if done, err := c.handleStageTimeout(run); err != nil {
+				return opts.Hwnd, run.result, err
+			} else if done {
+				return run.compileCompleteHwnd, run.result, nil
+			}
 
-			case dialogProgramCompilation:
-				// Detailed error/warning/notice messages
-				if programCompHwnd == 0 {
-					c.log.Debug("Detected 'Program Compilation' dialog")
-					c.log.Info("Gathering details...")
-					programCompHwnd = ev.Hwnd
+		case code := <-opts.Exited:
+			run.result = c.handleExit(code, run.result.Timing)
+			return opts.Hwnd, run.result, fmt.Errorf("%w: exited unexpectedly with code 0x%X", ErrCompilerCrashed, uint32(code))
+
+		case <-c.cancelled:
+			// Stop the compile if it has started, then leave cleanup to the caller
+			if run.compilingHwnd != 0 && run.compileCompleteHwnd == 0 {
+				c.log.Debug("Dismissing 'Compiling...' dialog")
+				if !c.controlReader.FindAndClickButton(run.compilingHwnd, "Cancel") {
+					c.windowMgr.CloseWindow(run.compilingHwnd, "Compiling dialog")
 				}
+			}
 
-			case dialogOperationComplete:
-				// Sometimes appears - close it
-				c.log.Debug("Detected 'Operation Complete' dialog - closing")
-				c.windowMgr.CloseWindow(ev.Hwnd, ev.Title)
-				time.Sleep(timeouts.WindowMessageDelay)
+			if run.compileCompleteHwnd != 0 {
+				c.windowMgr.CloseWindow(run.compileCompleteHwnd, "Compile Complete dialog")
 			}
 
-			// If we have both "Compile Complete" and (optionally) "Program Compilation", we're done
-			if compileCompleteDetected {
-				// If there are warnings/notices/errors, wait briefly for Program Compilation dialog
-				if (result.Warnings > 0 || result.Notices > 0 || result.Errors > 0) && programCompHwnd == 0 {
-					time.Sleep(500 * time.Millisecond)
-					continue
-				}
+			return opts.Hwnd, run.result, ErrCancelled
 
-				// Parse detailed messages if we have the Program Compilation dialog
-				if programCompHwnd != 0 {
-					result.WarningMessages, result.NoticeMessages, result.ErrorMessages = c.parseDetailedMessages(programCompHwnd)
+		case <-timeoutC(run.timeout):
+			c.log.Error("Compilation timeout", slog.Duration("timeout", compilationTimeout))
+			return opts.Hwnd, timeoutResult(fmt.Sprintf("Compilation timeout: did not detect 'Compile Complete' dialog within %s", compilationTimeout)),
+				fmt.Errorf("%w: did not detect 'Compile Complete' dialog within %s", ErrCompileTimeout, compilationTimeout)
+		}
+	}
+}
 
-					// Log the messages
-					c.logCompilationMessages(result.ErrorMessages, result.WarningMessages, result.NoticeMessages)
-				}
+// handleCompileEvent responds to one dialog, moving the compile to the stage it shows.
+// It returns true once the results are complete.
+func (c *Compiler) handleCompileEvent(run *compileRun, ev windows.WindowEvent) (bool, error) {
+	result := run.result
+
+	if ev.CrashReport {
+		run.result = c.handleCrash(ev, result.Timing)
+		return false, ErrCompilerCrashed
+	}
+
+	switch ev.Title {
+	case dialogIncompleteSymbols:
+		// Fatal error - compilation cannot proceed
+		c.log.Error("Incomplete Symbols detected", slog.String("title", ev.Title))
+		c.log.Info("The program contains incomplete symbols and cannot be compiled.")
+		c.log.Info("Please fix the incomplete symbols in SIMPL Windows before attempting to compile.")
+
+		// Extract error details
+		childInfos := c.windowMgr.CollectChildInfos(ev.Hwnd)
+		for _, ci := range childInfos {
+			if ci.ClassName == "Edit" && len(ci.Text) > 50 {
+				c.log.Info("Details", slog.String("text", ci.Text))
+				break
+			}
+		}
+
+		// Close the dialog before returning
+		c.windowMgr.CloseWindow(ev.Hwnd, "Incomplete Symbols dialog")
+
+		run.result = &CompileResult{
+			Errors:    1,
+			HasErrors: true,
+			ErrorMessages: []string{
+				"Incomplete Symbols: The program contains incomplete symbols and cannot be compiled",
+			},
+			Timing: result.Timing,
+		}
+
+		return false, ErrIncompleteSymbols
+
+	case dialogConvertCompile:
+		// Save prompt - answered as configured by SavePrompt
+		c.advanceStage(run, StageSavePrompts)
+		c.log.Debug("Handling 'Convert/Compile' dialog", slog.String("answer", run.opts.SavePrompt))
+
+		if run.opts.SavePrompt == AnswerAbort {
+			c.log.Info("Save prompt shown; aborting as configured")
+			c.windowMgr.CloseWindow(ev.Hwnd, "Convert/Compile dialog")
+
+			run.result = &CompileResult{
+				Errors:        1,
+				HasErrors:     true,
+				ErrorMessages: []string{"Save prompt shown: the program has unsaved changes"},
+				Timing:        result.Timing,
+			}
+
+			return false, ErrSavePromptAborted
+		}
+
+		start := c.clock.Now()
+
+		if run.opts.SavePrompt == AnswerNo {
+			_ = c.windowMgr.SetForeground(ev.Hwnd)
+			c.clock.Sleep(timeouts.DialogResponseDelay)
+			c.declineSavePrompt(ev.Hwnd)
+			c.log.Info("Declined save prompt")
+		} else {
+			c.confirmDialog(run.opts, ev.Hwnd, answerButton(AnswerYes))
+			c.log.Info("Auto-confirmed save prompt")
+		}
+
+		result.Timing.DialogHandling += c.clock.Since(start)
+
+	case dialogCommentedOutSymbols:
+		// Confirmation dialog - auto-confirm
+		c.advanceStage(run, StageSavePrompts)
+		c.log.Debug("Handling 'Commented out Symbols and/or Devices' dialog")
+		start := c.clock.Now()
+		c.confirmDialog(run.opts, ev.Hwnd, answerButton(AnswerYes), "OK")
+		result.Timing.DialogHandling += c.clock.Since(start)
+		c.log.Info("Auto-confirmed commented symbols dialog")
+
+	case dialogCompiling:
+		// Compilation in progress
+		if c.advanceStage(run, StageCompiling) {
+			c.log.Debug("Detected 'Compiling...' dialog")
 
-				// Set HasErrors flag
-				result.HasErrors = result.Errors > 0 || len(result.ErrorMessages) > 0
+			if run.opts.RecompileAll {
+				c.log.Info("Compiling program... (Recompile All)")
+			} else {
+				c.log.Info("Compiling program...")
+			}
+
+			run.compilingHwnd = ev.Hwnd
+			run.compilingAt = c.clock.Now()
+			result.Timing.KeystrokeToCompiling = run.compilingAt.Sub(run.keystrokeAt)
+		}
+
+	case dialogCompileComplete:
+		// Compilation finished - parse results
+		if run.compileCompleteHwnd == 0 {
+			c.log.Debug("Detected 'Compile Complete' dialog - parsing results")
+			run.compileCompleteHwnd = ev.Hwnd
 
-				// Compilation complete
-				return compileCompleteHwnd, result, nil
+			// Small programs can finish before "Compiling..." is ever seen
+			if run.compilingAt.IsZero() {
+				run.compilingAt = run.keystrokeAt
 			}
 
-		case <-timeout.C:
-			c.log.Error("Compilation timeout: did not complete within 5 minutes")
-			return opts.Hwnd, &CompileResult{
-				Errors:    1,
-				HasErrors: true,
-				ErrorMessages: []string{
-					"Compilation timeout: did not detect 'Compile Complete' dialog within 5 minutes",
-				},
-			}, fmt.Errorf("compilation timeout: did not detect 'Compile Complete' dialog within 5 minutes")
+			result.Timing.Compile = c.clock.Since(run.compilingAt)
+
+			c.parseCompileComplete(result, ev.Hwnd)
+
+			// The compile is over; only the detailed messages remain
+			c.stopTimer(&run.timeout)
+			c.advanceStage(run, StageResultsParsing)
+		}
+
+	case dialogProgramCompilation:
+		// Detailed error/warning/notice messages
+		if run.programCompHwnd == 0 {
+			c.log.Debug("Detected 'Program Compilation' dialog")
+			c.log.Info("Gathering details...")
+			run.programCompHwnd = ev.Hwnd
+
+			// The messages can be listed before "Compile Complete" is seen
+			c.advanceStage(run, StageCompiling)
+		}
+
+	case dialogOperationComplete:
+		// Sometimes appears - close it
+		c.log.Debug("Detected 'Operation Complete' dialog - closing")
+		c.skipped.record(ev, SkipDismissed, c.stage)
+		start := c.clock.Now()
+		c.windowMgr.CloseWindow(ev.Hwnd, ev.Title)
+		c.clock.Sleep(timeouts.WindowMessageDelay)
+		result.Timing.DialogHandling += c.clock.Since(start)
+
+	default:
+		start := c.clock.Now()
+		if c.autoRespond(run.opts, ev) {
+			result.Timing.DialogHandling += c.clock.Since(start)
+		} else {
+			c.skipped.record(ev, SkipUnrecognised, c.stage)
+		}
+	}
+
+	if c.stage != StageResultsParsing {
+		return false, nil
+	}
+
+	// If there are warnings/notices/errors, wait for the Program Compilation dialog listing them
+	if (result.Warnings > 0 || result.Notices > 0 || result.Errors > 0) && run.programCompHwnd == 0 {
+		return false, nil
+	}
+
+	c.finishResults(run)
+
+	return true, nil
+}
+
+// handleStageTimeout acts on the current stage running out of time. It returns true
+// once the results are complete.
+func (c *Compiler) handleStageTimeout(run *compileRun) (bool, error) {
+	timeout := stageTimeout(run.opts, c.stage)
+
+	switch c.stage {
+	case StageTriggered:
+		// Until SIMPL Windows responds, the trigger may have been swallowed
+		c.log.Warn("No response to compile trigger, retrying",
+			slog.String("previous", run.strategy.String()),
+			slog.Duration("waited", timeout))
+
+		if !run.opts.MessageInput {
+			_ = c.windowMgr.SetForeground(run.opts.Hwnd)
 		}
+
+		run.strategy = c.triggerCompile(run.opts, run.strategy+1)
+		c.log.Info("Retried compile trigger", slog.String("strategy", run.strategy.String()))
+
+		c.startStageTimer(run)
+
+		return false, nil
+
+	case StageResultsParsing:
+		c.log.Warn("'Program Compilation' dialog did not appear, reporting counts only",
+			slog.Duration("waited", timeout))
+
+		c.finishResults(run)
+
+		return true, nil
+
+	default:
+		c.log.Error("Compile stage timed out", slog.String("stage", c.stage.String()), slog.Duration("timeout", timeout))
+		run.result = timeoutResult(fmt.Sprintf("Compilation timeout: stage %s did not finish within %s", c.stage, timeout))
+
+		return false, fmt.Errorf("%w: stage %s did not finish within %s", ErrCompileTimeout, c.stage, timeout)
+	}
+}
+
+// advanceStage moves the compile to stage and restarts the stage timeout. It returns
+// false if the compile was already at or past stage.
+func (c *Compiler) advanceStage(run *compileRun, stage Stage) bool {
+	if !c.enterStage(stage) {
+		return false
+	}
+
+	c.startStageTimer(run)
+
+	return true
+}
+
+// startStageTimer (re)starts the timeout of the current stage. No timer is needed when
+// the stage has no timeout, or the compilation timeout would expire first.
+func (c *Compiler) startStageTimer(run *compileRun) {
+	c.stopTimer(&run.stageTimer)
+
+	d := stageTimeout(run.opts, c.stage)
+	if d <= 0 {
+		return
+	}
+
+	// The trigger cannot be retried once the Project menu has been used
+	if c.stage == StageTriggered && run.strategy >= triggerMenu {
+		return
+	}
+
+	if run.timeout != nil && !c.clock.Now().Add(d).Before(run.deadline) {
+		return
+	}
+
+	run.stageTimer = c.clock.NewTimer(d)
+}
+
+// stopTimer stops *t, if set, and clears it
+func (c *Compiler) stopTimer(t *clock.Timer) {
+	if *t != nil {
+		(*t).Stop()
+		*t = nil
+	}
+}
+
+// parseCompileComplete reads the counts and statistics shown by the "Compile Complete" dialog
+func (c *Compiler) parseCompileComplete(result *CompileResult, hwnd uintptr) {
+	childInfos := c.windowMgr.CollectChildInfos(hwnd)
+	for _, ci := range childInfos {
+		text := strings.ReplaceAll(ci.Text, "\r\n", "\n")
+		lines := strings.Split(text, "\n")
+
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+
+			result.Stats = addStatistic(result.Stats, line)
+
+			if n, ok := ParseStatLine(line, "Program Warnings"); ok {
+				result.Warnings = n
+			}
+
+			if n, ok := ParseStatLine(line, "Program Notices"); ok {
+				result.Notices = n
+			}
+
+			if n, ok := ParseStatLine(line, "Program Errors"); ok {
+				result.Errors = n
+			}
+
+			if secs, ok := ParseCompileTimeLine(line); ok {
+				result.CompileTime = secs
+			}
+		}
+	}
+}
+
+// finishResults adds the detailed messages, if they were listed, and final flags to the result
+func (c *Compiler) finishResults(run *compileRun) {
+	result := run.result
+
+	// Parse detailed messages if we have the Program Compilation dialog
+	if run.programCompHwnd != 0 {
+		result.WarningMessages, result.NoticeMessages, result.ErrorMessages = c.parseDetailedMessages(run.programCompHwnd)
+	}
+
+	if run.opts.WarningsAsErrors && result.Warnings > 0 {
+		c.log.Info("Treating warnings as errors", slog.Int("warnings", result.Warnings))
+		result.PromoteWarnings()
+	}
+
+	// Log the messages
+	if run.programCompHwnd != 0 {
+		c.logCompilationMessages(result.ErrorMessages, result.WarningMessages, result.NoticeMessages, run.opts.Hints)
+	}
+
+	// Set HasErrors flag
+	result.HasErrors = result.Errors > 0 || len(result.ErrorMessages) > 0
+}
+
+// logHint logs the hint for msg, indented under it, if kb has one
+func (c *Compiler) logHint(kb *hints.KB, msg string) {
+	if h, ok := kb.Lookup(msg); ok {
+		c.log.Info("     "+h.String(), slog.String("hint", h.Explanation), slog.String("fix", h.Fix))
+	}
+}
+
+// timeoutResult is the result reported when the compile runs out of time
+func timeoutResult(message string) *CompileResult {
+	return &CompileResult{
+		Errors:        1,
+		HasErrors:     true,
+		ErrorMessages: []string{message},
 	}
 }
 
@@ -422,7 +907,7 @@ func (c *Compiler) handleCompilationEvents(opts CompileOptions) (uintptr, *Compi
 func (c *Compiler) parseDetailedMessages(hwnd uintptr) (warnings, notices, errors []string) {
 	childInfos := c.windowMgr.CollectChildInfos(hwnd)
 
-	var lastType string // Track the type of the last message: "ERROR", "WARNING", or "NOTICE"
+	var msgs messageCollector
 
 	// Extract messages from ListBox
 	for _, ci := range childInfos {
@@ -431,47 +916,16 @@ func (c *Compiler) parseDetailedMessages(hwnd uintptr) (warnings, notices, error
 		}
 
 		for _, line := range ci.Items {
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
-
-			lineUpper := strings.ToUpper(line)
-			switch {
-			case strings.HasPrefix(lineUpper, "ERROR\t") || strings.HasPrefix(lineUpper, "ERROR "):
-				errors = append(errors, line)
-				lastType = msgTypeError
-			case strings.HasPrefix(lineUpper, "WARNING\t") || strings.HasPrefix(lineUpper, "WARNING "):
-				warnings = append(warnings, line)
-				lastType = msgTypeWarning
-			case strings.HasPrefix(lineUpper, "NOTICE\t") || strings.HasPrefix(lineUpper, "NOTICE "):
-				notices = append(notices, line)
-				lastType = msgTypeNotice
-			default:
-				// Continuation of previous message - append to the last type that was seen
-				switch lastType {
-				case msgTypeError:
-					if len(errors) > 0 {
-						errors[len(errors)-1] += " " + line
-					}
-				case msgTypeWarning:
-					if len(warnings) > 0 {
-						warnings[len(warnings)-1] += " " + line
-					}
-				case msgTypeNotice:
-					if len(notices) > 0 {
-						notices[len(notices)-1] += " " + line
-					}
-				}
-			}
+			msgs.add(line)
 		}
 	}
 
-	return warnings, notices, errors
+	return msgs.warnings, msgs.notices, msgs.errors
 }
 
-// logCompilationMessages logs error/warning/notice messages with proper formatting
-func (c *Compiler) logCompilationMessages(errorMsgs, warningMsgs, noticeMsgs []string) {
+// logCompilationMessages logs error/warning/notice messages with proper formatting,
+// each followed by its hint from kb when one matches
+func (c *Compiler) logCompilationMessages(errorMsgs, warningMsgs, noticeMsgs []string, kb *hints.KB) {
 	if len(errorMsgs) > 0 {
 		c.log.Info("")
 		c.log.Info("Error messages:")
@@ -481,6 +935,7 @@ func (c *Compiler) logCompilationMessages(errorMsgs, warningMsgs, noticeMsgs []s
 				slog.String("type", "error"),
 				slog.String("message", msg),
 			)
+			c.logHint(kb, msg)
 		}
 	}
 
@@ -493,6 +948,7 @@ func (c *Compiler) logCompilationMessages(errorMsgs, warningMsgs, noticeMsgs []s
 				slog.String("type", "warning"),
 				slog.String("message", msg),
 			)
+			c.logHint(kb, msg)
 		}
 	}
 
@@ -505,6 +961,7 @@ func (c *Compiler) logCompilationMessages(errorMsgs, warningMsgs, noticeMsgs []s
 				slog.String("type", "notice"),
 				slog.String("message", msg),
 			)
+			c.logHint(kb, msg)
 		}
 	}
 
@@ -516,68 +973,86 @@ func (c *Compiler) logCompilationMessages(errorMsgs, warningMsgs, noticeMsgs []s
 
 // handlePreCompilationDialogs checks for and dismisses dialogs that may block compilation
 // This includes "Operation Complete" dialog that can appear during SIMPL Windows startup
-func (c *Compiler) handlePreCompilationDialogs() error {
+func (c *Compiler) handlePreCompilationDialogs(opts CompileOptions, events <-chan windows.WindowEvent) error {
 	// Short timeout - check if there are any dialogs already present
-	timeout := time.NewTimer(timeouts.WindowMessageDelay)
+	timeout := c.clock.NewTimer(timeouts.WindowMessageDelay)
 	defer timeout.Stop()
 
 	for {
 		select {
-		case ev := <-windows.MonitorCh:
+		case ev := <-events:
 			c.log.Debug("Received pre-compilation event",
 				slog.String("title", ev.Title),
 				slog.Uint64("hwnd", uint64(ev.Hwnd)))
 
+			c.observe(ev)
+
 			// Handle dialogs that may block compilation
 			switch ev.Title {
 			case dialogOperationComplete:
 				c.log.Debug("Detected 'Operation Complete' dialog - closing")
 				c.log.Info("Handling pre-compilation 'Operation Complete' dialog")
+				c.skipped.record(ev, SkipDismissed, c.stage)
 				c.windowMgr.CloseWindow(ev.Hwnd, dialogOperationComplete)
-				time.Sleep(timeouts.WindowMessageDelay)
+				c.clock.Sleep(timeouts.WindowMessageDelay)
 
 			default:
-				// Log but don't handle other dialogs here
-				c.log.Trace("Ignoring pre-compilation dialog", slog.String("title", ev.Title))
+				// Log but don't handle other dialogs here, unless the policy answers them
+				if !c.autoRespond(opts, ev) {
+					c.log.Trace("Ignoring pre-compilation dialog", slog.String("title", ev.Title))
+					c.skipped.record(ev, SkipUnrecognised, c.stage)
+				}
 			}
 
-		case <-timeout.C:
+		case <-c.cancelled:
+			return ErrCancelled
+
+		case <-timeout.C():
 			// Timeout is fine - no blocking dialogs present
 			return nil
 		}
 	}
 }
 
-// handlePostCompilationEvents waits for and handles any post-compilation dialogs (like Confirmation)
-func (c *Compiler) handlePostCompilationEvents() error {
+// handlePostCompilationEvents waits for and handles any post-compilation dialogs (like Confirmation),
+// answering the Confirmation with answer ("" = no)
+func (c *Compiler) handlePostCompilationEvents(events <-chan windows.WindowEvent, answer string) error {
 	// Short timeout - if no confirmation dialog appears, that's fine
-	timeout := time.NewTimer(timeouts.DialogConfirmationTimeout)
+	timeout := c.clock.NewTimer(timeouts.DialogConfirmationTimeout)
 	defer timeout.Stop()
 
-	select {
-	case ev := <-windows.MonitorCh:
-		c.log.Debug("Received post-compilation event",
-			slog.String("title", ev.Title),
-			slog.Uint64("hwnd", uint64(ev.Hwnd)))
-
-		// Only handle Confirmation dialog here
-		if ev.Title == dialogConfirmation {
-			c.log.Debug("Detected 'Confirmation' dialog - clicking No")
+	for {
+		select {
+		case ev := <-events:
+			c.log.Debug("Received post-compilation event",
+				slog.String("title", ev.Title),
+				slog.Uint64("hwnd", uint64(ev.Hwnd)))
+
+			c.observe(ev)
+
+			// Only handle Confirmation dialog here; keep waiting past anything else
+			if ev.Title != dialogConfirmation {
+				continue
+			}
+
+			button := answerButton(answer)
+			c.log.Debug("Detected 'Confirmation' dialog", slog.String("button", button))
 			c.log.Info("Handling confirmation dialog")
 
-			if c.controlReader.FindAndClickButton(ev.Hwnd, "&No") {
-				c.log.Debug("Successfully clicked 'No' button")
-				time.Sleep(timeouts.WindowMessageDelay)
+			if c.controlReader.FindAndClickButton(ev.Hwnd, button) {
+				c.log.Debug("Successfully clicked confirmation button", slog.String("button", button))
+				c.clock.Sleep(timeouts.WindowMessageDelay)
 			} else {
-				c.log.Warn("Could not find 'No' button, trying to close dialog")
+				c.log.Warn("Could not find confirmation button, trying to close dialog", slog.String("button", button))
 				c.windowMgr.CloseWindow(ev.Hwnd, "Confirmation dialog")
-				time.Sleep(timeouts.WindowMessageDelay)
+				c.clock.Sleep(timeouts.WindowMessageDelay)
 			}
-		}
 
-	case <-timeout.C:
-		// Timeout is fine - dialog may not appear
-	}
+			return nil
 
-	return nil
+		case <-timeout.C():
+			// Timeout is fine - dialog may not appear
+			return nil
+		}
+	}
 }
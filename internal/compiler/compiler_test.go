@@ -1,20 +1,26 @@
 package compiler
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/Norgate-AV/smpc/internal/autorespond"
+	"github.com/Norgate-AV/smpc/internal/keychord"
 	"github.com/Norgate-AV/smpc/internal/logger"
 	"github.com/Norgate-AV/smpc/internal/testutil"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
 	"github.com/Norgate-AV/smpc/internal/windows"
 )
 
 func TestCompiler_SuccessfulCompilation(t *testing.T) {
-	// Setup monitor channel for event-driven testing
-	testutil.SetupMonitorChannel()
-	defer testutil.CleanupMonitorChannel()
+	// Setup event bus for event-driven testing
+	events := windows.NewEventBus()
 
 	mockWin := testutil.NewMockWindowManager().
 		WithChildInfosForHwnd(0x2222, // Compile Complete dialog
@@ -40,12 +46,13 @@ func TestCompiler_SuccessfulCompilation(t *testing.T) {
 		RecompileAll:                  false,
 		SimplPid:                      1234,
 		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
 	}
 
 	// Send dialog events that will appear during compilation
 	// IMPORTANT: Must send BEFORE calling Compile() because handlePreCompilationDialogs
 	// checks the channel first
-	testutil.SendEventsToMonitor(
+	testutil.SendEventsToMonitor(events,
 		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
 		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
 	)
@@ -76,9 +83,203 @@ func TestCompiler_SuccessfulCompilation(t *testing.T) {
 	assert.Equal(t, "SIMPL Windows", mockWin.CloseWindowCalls[1].Title)
 }
 
+// TestCompiler_Statistics captures every statistic in the Compile Complete dialog
+func TestCompiler_Statistics(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Static", Text: "Statistics"},
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n" +
+				"Total Signals: 1,530\r\nSymbols: 212\r\nCompile Time: 1.23 seconds\r\n"},
+		)
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: testutil.NewMockControlReader(),
+	})
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	result, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]float64{
+		"Program Errors":   0,
+		"Program Warnings": 0,
+		"Program Notices":  0,
+		"Total Signals":    1530,
+		"Symbols":          212,
+		"Compile Time":     1.23,
+	}, result.Stats)
+}
+
+// TestCompiler_DialogTranscripts records each dialog once, only when requested
+func TestCompiler_DialogTranscripts(t *testing.T) {
+	for _, capture := range []bool{false, true} {
+		events := windows.NewEventBus()
+
+		mockWin := testutil.NewMockWindowManager().
+			WithChildInfosForHwnd(0x2222,
+				windows.ChildInfo{ClassName: "Static", Text: "Statistics"},
+				windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nCompile Time: 1.23 seconds\r\n"},
+			)
+
+		compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+			ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+			WindowMgr:     mockWin,
+			Keyboard:      testutil.NewMockKeyboardInjector(),
+			ControlReader: testutil.NewMockControlReader(),
+		})
+
+		testutil.SendEventsToMonitor(events,
+			windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling...", Class: "#32770"},
+			windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling...", Class: "#32770"},
+			windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete", Class: "#32770"},
+		)
+
+		result, err := compiler.Compile(CompileOptions{
+			Hwnd:                          0x9999,
+			SimplPid:                      1234,
+			SkipPreCompilationDialogCheck: true,
+			Events:                        events,
+			CaptureTranscripts:            capture,
+		})
+		assert.NoError(t, err)
+
+		if !capture {
+			assert.Nil(t, result.DialogTranscripts)
+			continue
+		}
+
+		assert.Equal(t, []DialogTranscript{
+			{Title: "Compiling...", Class: "#32770", Hwnd: 0x1111, Controls: []ControlTranscript{}},
+			{Title: "Compile Complete", Class: "#32770", Hwnd: 0x2222, Controls: []ControlTranscript{
+				{Class: "Static", Text: "Statistics"},
+				{Class: "Edit", Text: "Program Errors: 0\r\nCompile Time: 1.23 seconds\r\n"},
+			}},
+		}, result.DialogTranscripts)
+	}
+}
+
+func TestCompiler_AuditTrail(t *testing.T) {
+	events := windows.NewEventBus()
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     testutil.NewMockWindowManager(),
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: testutil.NewMockControlReader(),
+	})
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	result, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+	})
+	require.NoError(t, err)
+
+	// Times are checked for presence only
+	actions := make([]AuditEntry, len(result.Audit))
+	for i, e := range result.Audit {
+		assert.False(t, e.Time.IsZero())
+		e.Time = time.Time{}
+		actions[i] = e
+	}
+
+	assert.Contains(t, actions, AuditEntry{Action: AuditKeystroke, Hwnd: 0x9999, Title: "SIMPL Windows", Detail: "f12 (SendInput)", OK: true})
+	assert.Contains(t, actions, AuditEntry{Action: AuditWindowClose, Hwnd: 0x2222, Title: "Compile Complete", OK: true})
+	assert.Equal(t, AuditEntry{Action: AuditWindowClose, Hwnd: 0x9999, Title: "SIMPL Windows", OK: true}, actions[len(actions)-1])
+}
+
+func TestAuditedWindowManager_Messages(t *testing.T) {
+	mockWin := testutil.NewMockWindowManager()
+	mockWin.SendMessageResult = 42
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		WindowMgr: mockWin,
+		Clock:     testutil.NewFakeClock(),
+	})
+
+	result, ok := compiler.windowMgr.SendMessage(0x4444, 0x000E, 0, 0)
+	assert.True(t, ok)
+	assert.Equal(t, uintptr(42), result)
+	assert.True(t, compiler.windowMgr.PostMessage(0x4444, 0x0010, 0, 0))
+	assert.True(t, compiler.windowMgr.ClickButtonById(0x4444, 2))
+
+	assert.Equal(t, []testutil.MessageCall{{Hwnd: 0x4444, Msg: 0x000E}}, mockWin.SendMessageCalls)
+	assert.Equal(t, []testutil.ClickButtonByIdCall{{ParentHwnd: 0x4444, ID: 2}}, mockWin.ClickButtonByIdCalls)
+
+	details := make([]string, 0, 3)
+	for _, e := range compiler.audit.trail() {
+		details = append(details, e.Action+": "+e.Detail)
+	}
+
+	assert.Equal(t, []string{
+		"message: SendMessage 0x000E (wParam 0x0, lParam 0x0)",
+		"message: PostMessage 0x0010 (wParam 0x0, lParam 0x0)",
+		"button_click: id 2",
+	}, details)
+}
+
+func TestCompiler_SkippedDialogs(t *testing.T) {
+	events := windows.NewEventBus()
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     testutil.NewMockWindowManager(),
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         testutil.NewFakeClock(),
+	})
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x9999, Title: "SIMPL Windows"}, // The main window is not a dialog
+		windows.WindowEvent{Hwnd: 0x3333, Title: "Operation Complete"},
+		windows.WindowEvent{Hwnd: 0x4444, Title: "Unknown Prompt", Class: "#32770", Modal: true},
+		windows.WindowEvent{Hwnd: 0x4444, Title: "Unknown Prompt"}, // Recorded once
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	result, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+	})
+	require.NoError(t, err)
+
+	skipped := make([]SkippedDialog, len(result.SkippedDialogs))
+	for i, d := range result.SkippedDialogs {
+		d.Time = time.Time{}
+		skipped[i] = d
+	}
+
+	assert.Equal(t, []SkippedDialog{
+		{Title: "Operation Complete", Hwnd: 0x3333, Reason: SkipDismissed, Stage: "triggered"},
+		{Title: "Unknown Prompt", Class: "#32770", Hwnd: 0x4444, Modal: true, Reason: SkipUnrecognised, Stage: "triggered"},
+	}, skipped)
+}
+
 func TestCompiler_RecompileAll(t *testing.T) {
-	testutil.SetupMonitorChannel()
-	defer testutil.CleanupMonitorChannel()
+	events := windows.NewEventBus()
 
 	mockWin := testutil.NewMockWindowManager().
 		WithChildInfosForHwnd(0x2222,
@@ -104,9 +305,10 @@ func TestCompiler_RecompileAll(t *testing.T) {
 		RecompileAll:                  true, // Trigger Alt+F12 instead of F12
 		SimplPid:                      1234,
 		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
 	}
 
-	testutil.SendEventsToMonitor(
+	testutil.SendEventsToMonitor(events,
 		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
 		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
 	)
@@ -123,9 +325,56 @@ func TestCompiler_RecompileAll(t *testing.T) {
 	assert.False(t, mockKbd.SendAltF12Called) // Old method should not be called when SendInput succeeds
 }
 
+func TestCompiler_CustomCompileKey(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Errors: 0\r\nWarnings: 0\r\nNotices: 0\r\n"},
+		)
+
+	mockKbd := testutil.NewMockKeyboardInjector()
+	mockCtrl := testutil.NewMockControlReader()
+	mockProc := testutil.NewMockProcessManager().WithPid(1234)
+
+	log := logger.NewNoOpLogger()
+	deps := &CompileDependencies{
+		ProcessMgr:    mockProc,
+		WindowMgr:     mockWin,
+		Keyboard:      mockKbd,
+		ControlReader: mockCtrl,
+	}
+
+	compiler := NewCompilerWithDeps(log, deps)
+
+	opts := CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		CompileKey:                    keychord.MustParse("ctrl+f12"),
+	}
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	result, err := compiler.Compile(opts)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+
+	// The configured chord replaces the default F12
+	assert.Len(t, mockKbd.SendChordWithSendInputCalls, 1)
+	assert.Equal(t, "ctrl+f12", mockKbd.SendChordWithSendInputCalls[0].String())
+	assert.False(t, mockKbd.SendF12WithSendInputCalled)
+	assert.False(t, mockKbd.SendAltF12WithSendInputCalled)
+	assert.Empty(t, mockKbd.SendChordCalls)
+}
+
 func TestCompiler_WithWarnings(t *testing.T) {
-	testutil.SetupMonitorChannel()
-	defer testutil.CleanupMonitorChannel()
+	events := windows.NewEventBus()
 
 	mockWin := testutil.NewMockWindowManager().
 		WithChildInfosForHwnd(0x2222, // Compile Complete dialog
@@ -157,9 +406,10 @@ func TestCompiler_WithWarnings(t *testing.T) {
 		Hwnd:                          0x9999,
 		SimplPid:                      1234,
 		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
 	}
 
-	testutil.SendEventsToMonitor(
+	testutil.SendEventsToMonitor(events,
 		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
 		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
 		windows.WindowEvent{Hwnd: 0x3333, Title: "Program Compilation"},
@@ -179,8 +429,7 @@ func TestCompiler_WithWarnings(t *testing.T) {
 }
 
 func TestCompiler_WithErrors(t *testing.T) {
-	testutil.SetupMonitorChannel()
-	defer testutil.CleanupMonitorChannel()
+	events := windows.NewEventBus()
 
 	mockWin := testutil.NewMockWindowManager().
 		WithChildInfosForHwnd(0x2222, // Compile Complete dialog
@@ -212,9 +461,10 @@ func TestCompiler_WithErrors(t *testing.T) {
 		Hwnd:                          0x9999,
 		SimplPid:                      1234,
 		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
 	}
 
-	testutil.SendEventsToMonitor(
+	testutil.SendEventsToMonitor(events,
 		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
 		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
 		windows.WindowEvent{Hwnd: 0x3333, Title: "Program Compilation"},
@@ -225,6 +475,12 @@ func TestCompiler_WithErrors(t *testing.T) {
 	// Compile returns an error when there are compile errors
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "compilation failed")
+
+	var compileErrs ErrCompileErrors
+	if assert.ErrorAs(t, err, &compileErrs) {
+		assert.Equal(t, 3, compileErrs.Count)
+	}
+
 	assert.NotNil(t, result)
 	assert.True(t, result.HasErrors)
 	assert.Equal(t, 3, result.Errors)
@@ -234,8 +490,7 @@ func TestCompiler_WithErrors(t *testing.T) {
 }
 
 func TestCompiler_IncompleteSymbols(t *testing.T) {
-	testutil.SetupMonitorChannel()
-	defer testutil.CleanupMonitorChannel()
+	events := windows.NewEventBus()
 
 	mockWin := testutil.NewMockWindowManager().
 		WithChildInfos(
@@ -260,9 +515,10 @@ func TestCompiler_IncompleteSymbols(t *testing.T) {
 		Hwnd:                          0x9999,
 		SimplPid:                      1234,
 		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
 	}
 
-	testutil.SendEventsToMonitor(
+	testutil.SendEventsToMonitor(events,
 		windows.WindowEvent{Hwnd: 0x2222, Title: "Incomplete Symbols"},
 	)
 
@@ -271,20 +527,88 @@ func TestCompiler_IncompleteSymbols(t *testing.T) {
 	assert.Error(t, err)
 	assert.NotNil(t, result)
 	assert.Contains(t, err.Error(), "incomplete symbols")
+	assert.ErrorIs(t, err, ErrIncompleteSymbols)
 	assert.True(t, result.HasErrors)
 	assert.Equal(t, 1, result.Errors)
 	assert.Len(t, result.ErrorMessages, 1)
 }
 
+func TestCompiler_Crash(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfos(
+			windows.ChildInfo{ClassName: "Static", Text: "SIMPL Windows has stopped working"},
+			windows.ChildInfo{ClassName: "Button", Text: "Close program"},
+		)
+
+	log := logger.NewNoOpLogger()
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: testutil.NewMockControlReader(),
+	}
+
+	compiler := NewCompilerWithDeps(log, deps)
+
+	opts := CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+	}
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x2222, Title: "SIMPL Windows", Pid: 5678, CrashReport: true},
+	)
+
+	result, err := compiler.Compile(opts)
+
+	assert.ErrorIs(t, err, ErrCompilerCrashed)
+	require.NotNil(t, result)
+	assert.True(t, result.HasErrors)
+	assert.Equal(t, []string{"SIMPL Windows crashed: SIMPL Windows has stopped working"}, result.ErrorMessages)
+	assert.Contains(t, mockWin.CloseWindowCalls, testutil.CloseWindowCall{Hwnd: 0x2222, Title: "crash report dialog"})
+}
+
+func TestCompiler_ProcessExited(t *testing.T) {
+	exited := make(chan int, 1)
+	exited <- 0xC0000005
+
+	log := logger.NewNoOpLogger()
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     testutil.NewMockWindowManager(),
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: testutil.NewMockControlReader(),
+	}
+
+	compiler := NewCompilerWithDeps(log, deps)
+
+	result, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        windows.NewEventBus(),
+		Exited:                        exited,
+	})
+
+	assert.ErrorIs(t, err, ErrCompilerCrashed)
+	require.NotNil(t, result)
+	assert.True(t, result.HasErrors)
+	assert.Equal(t, []string{"SIMPL Windows exited unexpectedly with code 0xC0000005"}, result.ErrorMessages)
+}
+
 func TestCompiler_CompileDialogTimeout(t *testing.T) {
-	testutil.SetupMonitorChannel()
-	defer testutil.CleanupMonitorChannel()
+	events := windows.NewEventBus()
 
 	mockWin := testutil.NewMockWindowManager()
 
 	mockKbd := testutil.NewMockKeyboardInjector()
 	mockCtrl := testutil.NewMockControlReader()
 	mockProc := testutil.NewMockProcessManager().WithPid(1234)
+	clk := testutil.NewFakeClock()
 
 	log := logger.NewNoOpLogger()
 	deps := &CompileDependencies{
@@ -292,6 +616,7 @@ func TestCompiler_CompileDialogTimeout(t *testing.T) {
 		WindowMgr:     mockWin,
 		Keyboard:      mockKbd,
 		ControlReader: mockCtrl,
+		Clock:         clk,
 	}
 
 	compiler := NewCompilerWithDeps(log, deps)
@@ -300,24 +625,192 @@ func TestCompiler_CompileDialogTimeout(t *testing.T) {
 		Hwnd:                          0x9999,
 		SimplPid:                      1234,
 		SkipPreCompilationDialogCheck: true,
-		CompilationTimeout:            1 * time.Second, // Fast timeout for testing
+		Events:                        events,
 	}
 
 	// Don't send any events to trigger timeout
 
-	result, err := compiler.Compile(opts)
+	var (
+		result *CompileResult
+		err    error
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		result, err = compiler.Compile(opts)
+	}()
+
+	// Wait for the compile-complete timer, then jump past the default 5 minute timeout
+	clk.WaitForTimers(1)
+	clk.Advance(timeouts.CompilationCompleteTimeout)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Compile did not return after the fake clock passed the timeout")
+	}
 
 	assert.Error(t, err)
 	assert.NotNil(t, result)
 	assert.Contains(t, err.Error(), "Compile Complete")
+	assert.ErrorIs(t, err, ErrCompileTimeout)
 	assert.True(t, result.HasErrors)
 	assert.Equal(t, 1, result.Errors)
 	assert.Len(t, result.ErrorMessages, 1)
 }
 
+func TestCompiler_CustomCompilationTimeout(t *testing.T) {
+	events := windows.NewEventBus()
+
+	clk := testutil.NewFakeClock()
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     testutil.NewMockWindowManager(),
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         clk,
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), deps)
+
+	opts := CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		CompilationTimeout:            1 * time.Second,
+	}
+
+	var err error
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err = compiler.Compile(opts)
+	}()
+
+	clk.WaitForTimers(1)
+
+	// Advancing just short of the custom timeout must not fire it
+	clk.Advance(999 * time.Millisecond)
+	assert.Equal(t, 1, clk.PendingTimers())
+
+	clk.Advance(1 * time.Millisecond)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Compile did not return after the custom timeout elapsed")
+	}
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout")
+	assert.ErrorIs(t, err, ErrCompileTimeout)
+}
+
+func TestCompiler_ForegroundLost(t *testing.T) {
+	mockKbd := testutil.NewMockKeyboardInjector()
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     testutil.NewMockWindowManager().WithSetForegroundResult(false),
+		Keyboard:      mockKbd,
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         testutil.NewFakeClock(),
+	})
+
+	result, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        windows.NewEventBus(),
+	})
+
+	assert.ErrorIs(t, err, ErrForegroundLost)
+	assert.True(t, result.HasErrors)
+	assert.False(t, mockKbd.SendF12WithSendInputCalled, "No keystrokes without focus")
+}
+
+func TestCompiler_SaveFirst(t *testing.T) {
+	events := windows.NewEventBus()
+
+	program := filepath.Join(t.TempDir(), "program.smw")
+	assert.NoError(t, os.WriteFile(program, []byte("program"), 0o644))
+
+	old := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(program, old, old))
+
+	// Saving rewrites the file; compiling only happens afterwards
+	var order []string
+
+	mockKbd := testutil.NewMockKeyboardInjector()
+	mockKbd.OnSendChordWithSendInput = func(chord keychord.Chord) {
+		order = append(order, chord.String())
+
+		now := time.Now()
+		_ = os.Chtimes(program, now, now)
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr: testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr: testutil.NewMockWindowManager().WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		),
+		Keyboard:      mockKbd,
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         testutil.NewFakeClock(),
+	})
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	_, err := compiler.Compile(CompileOptions{
+		FilePath:                      program,
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		SaveFirst:                     true,
+		CompileKey:                    keychord.MustParse("f12"),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ctrl+s", "f12"}, order)
+}
+
+func TestCompiler_SaveFirstFailed(t *testing.T) {
+	mockKbd := testutil.NewMockKeyboardInjector()
+	mockKbd.SendInputResult = false
+
+	mockWin := testutil.NewMockWindowManager()
+	mockWin.InvokeMenuItemResult = false
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      mockKbd,
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         testutil.NewFakeClock(),
+	})
+
+	_, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        windows.NewEventBus(),
+		SaveFirst:                     true,
+	})
+
+	assert.ErrorIs(t, err, ErrSaveFailed)
+	assert.Equal(t, [][]string{saveMenuPath}, mockWin.InvokeMenuItemCalls, "File > Save is tried when Ctrl+S cannot be sent")
+	assert.False(t, mockKbd.SendF12WithSendInputCalled, "No compile without a save")
+}
+
 func TestCompiler_NoPid(t *testing.T) {
-	testutil.SetupMonitorChannel()
-	defer testutil.CleanupMonitorChannel()
+	events := windows.NewEventBus()
 
 	// When PID is 0, dialog monitoring should be skipped but compilation should still proceed
 	mockWin := testutil.NewMockWindowManager().
@@ -343,10 +836,11 @@ func TestCompiler_NoPid(t *testing.T) {
 		Hwnd:                          0x9999,
 		SimplPid:                      0, // No PID available
 		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
 	}
 
 	// PID=0 means no monitoring, so don't send events
-	testutil.SendEventsToMonitor(
+	testutil.SendEventsToMonitor(events,
 		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
 		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
 	)
@@ -362,8 +856,7 @@ func TestCompiler_NoPid(t *testing.T) {
 }
 
 func TestCompiler_WithSavePrompts(t *testing.T) {
-	testutil.SetupMonitorChannel()
-	defer testutil.CleanupMonitorChannel()
+	events := windows.NewEventBus()
 
 	mockWin := testutil.NewMockWindowManager().
 		WithChildInfos(
@@ -388,9 +881,10 @@ func TestCompiler_WithSavePrompts(t *testing.T) {
 		Hwnd:                          0x9999,
 		SimplPid:                      1234,
 		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
 	}
 
-	testutil.SendEventsToMonitor(
+	testutil.SendEventsToMonitor(events,
 		windows.WindowEvent{Hwnd: 0x2222, Title: "Convert/Compile"},
 		windows.WindowEvent{Hwnd: 0x6666, Title: "Commented Out Symbols"},
 		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
@@ -406,3 +900,888 @@ func TestCompiler_WithSavePrompts(t *testing.T) {
 	// Verify Enter was sent twice (for save prompts)
 	assert.True(t, mockKbd.SendEnterCalled)
 }
+
+func TestCompiler_SavePromptNo(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockKbd := testutil.NewMockKeyboardInjector()
+	mockCtrl := testutil.NewMockControlReader()
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr: testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr: testutil.NewMockWindowManager().WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		),
+		Keyboard:      mockKbd,
+		ControlReader: mockCtrl,
+		Clock:         testutil.NewFakeClock(),
+	})
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x3333, Title: "Convert/Compile"},
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	_, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		SavePrompt:                    AnswerNo,
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, mockKbd.SendEnterCalled, "The save prompt must not be confirmed")
+	assert.Contains(t, mockCtrl.FindAndClickButtonCalls, testutil.FindAndClickButtonCall{ParentHwnd: 0x3333, ButtonText: "&No"})
+}
+
+func TestCompiler_SavePromptAbort(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockKbd := testutil.NewMockKeyboardInjector()
+	mockWin := testutil.NewMockWindowManager()
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      mockKbd,
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         testutil.NewFakeClock(),
+	})
+
+	testutil.SendEventsToMonitor(events, windows.WindowEvent{Hwnd: 0x3333, Title: "Convert/Compile"})
+
+	result, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		SavePrompt:                    AnswerAbort,
+	})
+
+	assert.ErrorIs(t, err, ErrSavePromptAborted)
+	assert.True(t, result.HasErrors)
+	assert.False(t, mockKbd.SendEnterCalled, "The save prompt must not be confirmed")
+	assert.Contains(t, mockWin.CloseWindowCalls, testutil.CloseWindowCall{Hwnd: 0x3333, Title: "Convert/Compile dialog"})
+}
+
+func TestCompiler_CloseConfirmationYes(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		).
+		WithOnCloseWindow(func(hwnd uintptr, title string) {
+			if hwnd == 0x9999 {
+				events.Publish(windows.WindowEvent{Hwnd: 0x5555, Title: "Confirmation"})
+			}
+		})
+
+	mockCtrl := testutil.NewMockControlReader()
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: mockCtrl,
+	})
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	_, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		CloseConfirmation:             AnswerYes,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []testutil.FindAndClickButtonCall{{ParentHwnd: 0x5555, ButtonText: "&Yes"}}, mockCtrl.FindAndClickButtonCalls)
+}
+
+func TestCompiler_AutoRespond(t *testing.T) {
+	events := windows.NewEventBus()
+
+	policy, err := autorespond.Parse([]byte(`{"autoRespond": [
+		{"title": "^Add-in", "button": "&Continue"},
+		{"title": "(?i)update available", "key": "alt+l"}
+	]}`))
+	assert.NoError(t, err)
+
+	mockKbd := testutil.NewMockKeyboardInjector()
+	mockCtrl := testutil.NewMockControlReader()
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr: testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr: testutil.NewMockWindowManager().WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		),
+		Keyboard:      mockKbd,
+		ControlReader: mockCtrl,
+		Clock:         testutil.NewFakeClock(),
+	})
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x9999, Title: "Add-in host"}, // The main window is never answered
+		windows.WindowEvent{Hwnd: 0x4444, Title: "Add-in Loader"},
+		windows.WindowEvent{Hwnd: 0x4444, Title: "Add-in Loader"}, // Answered once
+		windows.WindowEvent{Hwnd: 0x5555, Title: "Update Available"},
+		windows.WindowEvent{Hwnd: 0x6666, Title: "Unknown Prompt"},
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	_, err = compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		AutoRespond:                   policy,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []testutil.FindAndClickButtonCall{{ParentHwnd: 0x4444, ButtonText: "&Continue"}}, mockCtrl.FindAndClickButtonCalls)
+
+	var chords []string
+	for _, chord := range mockKbd.SendChordWithSendInputCalls {
+		chords = append(chords, chord.String())
+	}
+
+	assert.Contains(t, chords, "alt+l")
+}
+
+func TestCompiler_AutoRecompileAll(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222, // First Compile Complete dialog
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 1\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		).
+		WithChildInfosForHwnd(0x3333, // Program Compilation dialog
+			windows.ChildInfo{ClassName: "ListBox", Items: []string{
+				"ERROR      (LGCMCVT000) Device database has changed, use Recompile All",
+			}},
+		).
+		WithChildInfosForHwnd(0x5555, // Compile Complete dialog of the retry
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		)
+
+	// The retry is triggered with the Recompile All chord
+	mockKbd := testutil.NewMockKeyboardInjector()
+	mockKbd.OnSendChordWithSendInput = func(chord keychord.Chord) {
+		events.Publish(windows.WindowEvent{Hwnd: 0x4444, Title: "Compiling..."})
+		events.Publish(windows.WindowEvent{Hwnd: 0x5555, Title: "Compile Complete"})
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      mockKbd,
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         testutil.NewFakeClock(),
+	})
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+		windows.WindowEvent{Hwnd: 0x3333, Title: "Program Compilation"},
+	)
+
+	result, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		AutoRecompileAll:              true,
+		RecompileAllKey:               keychord.MustParse("alt+f12"),
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, result.HasErrors)
+	assert.Equal(t, ModeRecompileAllRetry, result.Mode)
+	assert.True(t, mockKbd.SendF12WithSendInputCalled, "The first attempt is a normal compile")
+	assert.Len(t, mockKbd.SendChordWithSendInputCalls, 1)
+	assert.Contains(t, mockWin.CloseWindowCalls, testutil.CloseWindowCall{Hwnd: 0x2222, Title: "Compile Complete dialog"})
+	assert.Len(t, result.Timing.Dialogs, 5, "Dialogs of both attempts are recorded")
+}
+
+func TestNeedsRecompileAll(t *testing.T) {
+	failed := &CompileResult{HasErrors: true, ErrorMessages: []string{"ERROR (LGCMCVT000) Signal database out of date"}}
+	other := &CompileResult{HasErrors: true, ErrorMessages: []string{"ERROR (LGSPLS1700) Undefined symbol 'foo'"}}
+
+	assert.True(t, needsRecompileAll(CompileOptions{AutoRecompileAll: true}, failed))
+	assert.False(t, needsRecompileAll(CompileOptions{}, failed), "Only when requested")
+	assert.False(t, needsRecompileAll(CompileOptions{AutoRecompileAll: true, RecompileAll: true}, failed), "Already a Recompile All")
+	assert.False(t, needsRecompileAll(CompileOptions{AutoRecompileAll: true}, other))
+	assert.False(t, needsRecompileAll(CompileOptions{AutoRecompileAll: true}, &CompileResult{}))
+}
+
+func TestCompiler_ConfirmationOnCloseUsesOwnSubscription(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		).
+		WithOnCloseWindow(func(hwnd uintptr, title string) {
+			if hwnd != 0x9999 {
+				return
+			}
+
+			// Closing SIMPL Windows raises an unrelated window first, then the save prompt
+			events.Publish(windows.WindowEvent{Hwnd: 0x4444, Title: "SIMPL Windows"})
+			events.Publish(windows.WindowEvent{Hwnd: 0x5555, Title: "Confirmation"})
+		})
+
+	mockKbd := testutil.NewMockKeyboardInjector()
+	mockCtrl := testutil.NewMockControlReader()
+	mockProc := testutil.NewMockProcessManager().WithPid(1234)
+
+	deps := &CompileDependencies{
+		ProcessMgr:    mockProc,
+		WindowMgr:     mockWin,
+		Keyboard:      mockKbd,
+		ControlReader: mockCtrl,
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), deps)
+
+	opts := CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+	}
+
+	// Stale events left unread by the compilation loop must not reach the close handler
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+	)
+
+	// An external observer sees every event without taking any from the compiler
+	observed := windows.NewEventBus()
+	stop := events.Observe(func(ev windows.WindowEvent) { observed.Publish(ev) })
+
+	result, err := compiler.Compile(opts)
+	stop()
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+
+	assert.Len(t, mockCtrl.FindAndClickButtonCalls, 1)
+	assert.Equal(t, uintptr(0x5555), mockCtrl.FindAndClickButtonCalls[0].ParentHwnd)
+	assert.Equal(t, "&No", mockCtrl.FindAndClickButtonCalls[0].ButtonText)
+
+	_, sawConfirmation := observed.FindRecent(func(ev windows.WindowEvent) bool { return ev.Title == "Confirmation" })
+	assert.True(t, sawConfirmation)
+	assert.Equal(t, 0, events.SubscriberCount(), "Compiler should release its subscriptions")
+}
+
+func TestCompiler_TimingBreakdown(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		).
+		WithOnCloseWindow(func(hwnd uintptr, title string) {
+			if hwnd == 0x9999 {
+				events.Publish(windows.WindowEvent{Hwnd: 0x5555, Title: "Confirmation"})
+			}
+		})
+
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         testutil.NewFakeClock(),
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), deps)
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x3333, Title: "Convert/Compile"},
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	result, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+	})
+	assert.NoError(t, err)
+
+	// The fake clock only advances through the compiler's own sleeps, so every phase is exact
+	assert.Equal(t, timeouts.DialogResponseDelay, result.Timing.KeystrokeToCompiling, "Save prompt delays the Compiling dialog")
+	assert.Equal(t, timeouts.DialogResponseDelay, result.Timing.DialogHandling)
+	assert.Equal(t, time.Duration(0), result.Timing.Compile)
+	assert.Equal(t, []DialogTiming{
+		{Title: "Convert/Compile", Hwnd: 0x3333, At: 0},
+		{Title: "Compiling...", Hwnd: 0x1111, At: timeouts.DialogResponseDelay},
+		{Title: "Compile Complete", Hwnd: 0x2222, At: timeouts.DialogResponseDelay},
+	}, result.Timing.Dialogs, "Each dialog is timed from the keystroke")
+	assert.Equal(t,
+		timeouts.StabilityCheckInterval+timeouts.WindowMessageDelay+timeouts.CleanupDelay,
+		result.Timing.Cleanup,
+		"Cleanup covers closing dialogs, the confirmation and the final delay",
+	)
+}
+
+func TestCompiler_StageChanges(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		)
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         testutil.NewFakeClock(),
+	})
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x3333, Title: "Convert/Compile"},
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	var changes [][2]Stage
+
+	_, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		OnStageChange: func(from, to Stage) {
+			changes = append(changes, [2]Stage{from, to})
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, [][2]Stage{
+		{StageIdle, StageTriggered},
+		{StageTriggered, StageSavePrompts},
+		{StageSavePrompts, StageCompiling},
+		{StageCompiling, StageResultsParsing},
+		{StageResultsParsing, StageClosing},
+	}, changes)
+}
+
+// TestCompiler_ResultsParsingTimeout tests that a missing "Program Compilation" dialog
+// no longer holds the compile until the compilation timeout
+func TestCompiler_ResultsParsingTimeout(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 2\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		)
+
+	clk := testutil.NewFakeClock()
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         clk,
+	})
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	parsing := make(chan struct{})
+
+	var (
+		result *CompileResult
+		err    error
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		result, err = compiler.Compile(CompileOptions{
+			Hwnd:                          0x9999,
+			SimplPid:                      1234,
+			SkipPreCompilationDialogCheck: true,
+			Events:                        events,
+			OnStageChange: func(from, to Stage) {
+				if to == StageResultsParsing {
+					close(parsing)
+				}
+			},
+		})
+	}()
+
+	<-parsing
+
+	// Only the results timer remains once "Compile Complete" has been seen
+	assert.Eventually(t, func() bool { return clk.PendingTimers() == 1 }, 5*time.Second, time.Millisecond)
+	clk.Advance(timeouts.ProgramCompilationTimeout)
+
+	// Cleanup waits for a possible save confirmation when closing SIMPL Windows
+	clk.WaitForTimers(1)
+	clk.Advance(timeouts.DialogConfirmationTimeout)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Compile did not return after the results timeout")
+	}
+
+	var compileErrs ErrCompileErrors
+	assert.ErrorAs(t, err, &compileErrs)
+	assert.Equal(t, 2, result.Errors)
+	assert.Empty(t, result.ErrorMessages, "No messages without the Program Compilation dialog")
+}
+
+func TestCompiler_StageTimeout(t *testing.T) {
+	events := windows.NewEventBus()
+
+	clk := testutil.NewFakeClock()
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     testutil.NewMockWindowManager(),
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         clk,
+	})
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+	)
+
+	compiling := make(chan struct{})
+
+	var err error
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err = compiler.Compile(CompileOptions{
+			Hwnd:                          0x9999,
+			SimplPid:                      1234,
+			SkipPreCompilationDialogCheck: true,
+			Events:                        events,
+			// No trigger retries, so only the Compiling timer is added
+			StageTimeouts: map[Stage]time.Duration{StageTriggered: 0, StageCompiling: time.Minute},
+			OnStageChange: func(from, to Stage) {
+				if to == StageCompiling {
+					close(compiling)
+				}
+			},
+		})
+	}()
+
+	<-compiling
+
+	// The compilation timeout and the Compiling stage timeout
+	assert.Eventually(t, func() bool { return clk.PendingTimers() == 2 }, 5*time.Second, time.Millisecond)
+	clk.Advance(time.Minute)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Compile did not return after the stage timeout")
+	}
+
+	assert.ErrorIs(t, err, ErrCompileTimeout)
+	assert.ErrorContains(t, err, "compiling")
+}
+
+func TestCompiler_WarningsAsErrors(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222, // Compile Complete dialog
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 2\r\nProgram Notices: 1\r\n"},
+		).
+		WithChildInfosForHwnd(0x3333, // Program Compilation dialog
+			windows.ChildInfo{ClassName: "ListBox", Items: []string{
+				"WARNING    (LGCMCVT102) ** Signal foo has no driving source",
+				"WARNING    (LGCMCVT102) ** Signal bar has no driving source",
+				"NOTICE     (LGCMCVT103) ** Signal baz has no destination",
+			}},
+		)
+
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: testutil.NewMockControlReader(),
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), deps)
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+		windows.WindowEvent{Hwnd: 0x3333, Title: "Program Compilation"},
+	)
+
+	result, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		WarningsAsErrors:              true,
+		Events:                        events,
+	})
+
+	assert.Error(t, err, "Warnings should fail the compile")
+	assert.Contains(t, err.Error(), "2 error(s)")
+	assert.True(t, result.HasErrors)
+	assert.Equal(t, 2, result.Errors)
+	assert.Equal(t, 0, result.Warnings)
+	assert.Equal(t, 1, result.Notices)
+	assert.Len(t, result.ErrorMessages, 2)
+	assert.Empty(t, result.WarningMessages)
+}
+
+func TestCompiler_TriggerFallsBackToMenu(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		).
+		WithOnInvokeMenuItem(func(path []string) {
+			// Only the menu command gets through; keystrokes were swallowed
+			events.Publish(windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."})
+			events.Publish(windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"})
+		}).
+		WithOnCloseWindow(func(hwnd uintptr, title string) {
+			if hwnd == 0x9999 {
+				events.Publish(windows.WindowEvent{Hwnd: 0x5555, Title: "Confirmation"})
+			}
+		})
+
+	mockKbd := testutil.NewMockKeyboardInjector()
+	clk := testutil.NewFakeClock()
+
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      mockKbd,
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         clk,
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), deps)
+
+	var (
+		result *CompileResult
+		err    error
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		result, err = compiler.Compile(CompileOptions{
+			Hwnd:                          0x9999,
+			SimplPid:                      1234,
+			SkipPreCompilationDialogCheck: true,
+			Events:                        events,
+		})
+	}()
+
+	// SendInput gets no response, so keybd_event is tried next
+	clk.WaitForTimers(2)
+	clk.Advance(timeouts.CompileTriggerTimeout)
+
+	// keybd_event gets no response either, so the Project menu is driven
+	clk.WaitForTimers(2)
+	clk.Advance(timeouts.CompileTriggerTimeout)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Compile did not return after the menu fallback")
+	}
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+
+	assert.True(t, mockKbd.SendF12WithSendInputCalled)
+	assert.True(t, mockKbd.SendF12Called)
+	assert.Equal(t, [][]string{{"Project", "Convert/Compile"}}, mockWin.InvokeMenuItemCalls)
+}
+
+func TestCompiler_TriggerNotRetriedOnceAcknowledged(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		)
+
+	mockKbd := testutil.NewMockKeyboardInjector()
+
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      mockKbd,
+		ControlReader: testutil.NewMockControlReader(),
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), deps)
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	_, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		RecompileAll:                  true,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, mockKbd.SendAltF12WithSendInputCalled)
+	assert.False(t, mockKbd.SendAltF12Called)
+	assert.Empty(t, mockWin.InvokeMenuItemCalls)
+}
+
+func TestCompiler_DDEBackend(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		)
+
+	mockKbd := testutil.NewMockKeyboardInjector()
+	mockDDE := testutil.NewMockDDEClient().WithOnExecute(func(command string) {
+		events.Publish(windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."})
+		events.Publish(windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"})
+	})
+
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      mockKbd,
+		ControlReader: testutil.NewMockControlReader(),
+		DDE:           mockDDE,
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), deps)
+
+	_, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		Backend:                       BackendDDE,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []testutil.DDEExecuteCall{{Service: "SMPWIN", Topic: "System", Command: "[Compile]"}}, mockDDE.ExecuteCalls)
+	assert.Empty(t, mockWin.SetForegroundCalls, "DDE does not need keyboard focus")
+	assert.False(t, mockKbd.SendF12WithSendInputCalled)
+}
+
+func TestCompiler_MessageInput(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		).
+		WithOnInvokeMenuItem(func(path []string) {
+			events.Publish(windows.WindowEvent{Hwnd: 0x3333, Title: "Convert/Compile"})
+			events.Publish(windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."})
+			events.Publish(windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"})
+		})
+
+	mockKbd := testutil.NewMockKeyboardInjector()
+	mockCtrl := testutil.NewMockControlReader().WithFindAndClickButtonResult(true)
+
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      mockKbd,
+		ControlReader: mockCtrl,
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), deps)
+
+	_, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		MessageInput:                  true,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, [][]string{compileMenuPath}, mockWin.InvokeMenuItemCalls)
+	assert.Equal(t, []string{"&Yes"}, mockCtrl.FindButtonCalls, "the save prompt is answered by clicking")
+	assert.Empty(t, mockWin.SetForegroundCalls, "SIMPL Windows is never focused")
+	assert.False(t, mockKbd.SendF12WithSendInputCalled)
+	assert.False(t, mockKbd.SendEnterCalled)
+}
+
+func TestCompiler_DDEBackendFallsBackToKeystrokes(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager().
+		WithChildInfosForHwnd(0x2222,
+			windows.ChildInfo{ClassName: "Edit", Text: "Program Errors: 0\r\nProgram Warnings: 0\r\nProgram Notices: 0\r\n"},
+		)
+
+	mockKbd := testutil.NewMockKeyboardInjector()
+	mockDDE := testutil.NewMockDDEClient().WithExecuteErr(errors.New("no DDE server"))
+
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      mockKbd,
+		ControlReader: testutil.NewMockControlReader(),
+		DDE:           mockDDE,
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), deps)
+
+	testutil.SendEventsToMonitor(events,
+		windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."},
+		windows.WindowEvent{Hwnd: 0x2222, Title: "Compile Complete"},
+	)
+
+	_, err := compiler.Compile(CompileOptions{
+		Hwnd:                          0x9999,
+		RecompileAll:                  true,
+		SimplPid:                      1234,
+		SkipPreCompilationDialogCheck: true,
+		Events:                        events,
+		Backend:                       BackendDDE,
+	})
+	assert.NoError(t, err)
+
+	assert.Len(t, mockDDE.ExecuteCalls, 1)
+	assert.Equal(t, "[RecompileAll]", mockDDE.ExecuteCalls[0].Command)
+	assert.Equal(t, []uintptr{0x9999}, mockWin.SetForegroundCalls, "window is focused before falling back to keystrokes")
+	assert.True(t, mockKbd.SendAltF12WithSendInputCalled)
+}
+
+func TestCompiler_CancelDuringCompile(t *testing.T) {
+	events := windows.NewEventBus()
+
+	mockWin := testutil.NewMockWindowManager()
+	mockCtrl := testutil.NewMockControlReader()
+	clk := testutil.NewFakeClock()
+
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager().WithPid(1234),
+		WindowMgr:     mockWin,
+		Keyboard:      testutil.NewMockKeyboardInjector(),
+		ControlReader: mockCtrl,
+		Clock:         clk,
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), deps)
+
+	var (
+		result *CompileResult
+		err    error
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		result, err = compiler.Compile(CompileOptions{
+			Hwnd:                          0x9999,
+			SimplPid:                      1234,
+			SkipPreCompilationDialogCheck: true,
+			Events:                        events,
+		})
+	}()
+
+	// Compilation and trigger-retry timers are pending once the loop is waiting
+	clk.WaitForTimers(2)
+	events.Publish(windows.WindowEvent{Hwnd: 0x1111, Title: "Compiling..."})
+
+	// "Compiling..." acknowledges the trigger and stops the retry timer
+	assert.Eventually(t, func() bool { return clk.PendingTimers() == 1 }, 5*time.Second, time.Millisecond)
+
+	compiler.Cancel()
+	compiler.Cancel() // Safe to call more than once
+
+	// Cleanup waits for a possible save confirmation when closing SIMPL Windows
+	clk.WaitForTimers(2)
+	clk.Advance(timeouts.DialogConfirmationTimeout)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Compile did not return after Cancel")
+	}
+
+	assert.ErrorIs(t, err, ErrCancelled)
+	assert.NotNil(t, result)
+	assert.False(t, result.HasErrors)
+
+	// The compile was dismissed and SIMPL Windows closed
+	assert.Equal(t, []testutil.FindAndClickButtonCall{{ParentHwnd: 0x1111, ButtonText: "Cancel"}}, mockCtrl.FindAndClickButtonCalls)
+	assert.Len(t, mockWin.CloseWindowCalls, 1)
+	assert.Equal(t, uintptr(0x9999), mockWin.CloseWindowCalls[0].Hwnd)
+}
+
+func TestCompiler_CancelBeforeCompile(t *testing.T) {
+	mockWin := testutil.NewMockWindowManager()
+	mockKbd := testutil.NewMockKeyboardInjector()
+
+	deps := &CompileDependencies{
+		ProcessMgr:    testutil.NewMockProcessManager(),
+		WindowMgr:     mockWin,
+		Keyboard:      mockKbd,
+		ControlReader: testutil.NewMockControlReader(),
+		Clock:         testutil.NewFakeClock(),
+	}
+
+	compiler := NewCompilerWithDeps(logger.NewNoOpLogger(), deps)
+	compiler.Cancel()
+
+	_, err := compiler.Compile(CompileOptions{Hwnd: 0x9999})
+	assert.ErrorIs(t, err, ErrCancelled)
+
+	// No compile is triggered, but SIMPL Windows is still closed
+	assert.False(t, mockKbd.SendF12WithSendInputCalled)
+	assert.Len(t, mockWin.CloseWindowCalls, 1)
+	assert.Equal(t, uintptr(0x9999), mockWin.CloseWindowCalls[0].Hwnd)
+}
+
+func TestValidateDialogAnswers(t *testing.T) {
+	for _, answer := range []string{"", AnswerYes, AnswerNo, AnswerAbort} {
+		assert.NoError(t, ValidateSavePrompt(answer))
+	}
+
+	assert.NoError(t, ValidateCloseConfirmation(AnswerYes))
+	assert.NoError(t, ValidateCloseConfirmation(AnswerNo))
+	assert.Error(t, ValidateCloseConfirmation(AnswerAbort), "closing cannot be aborted")
+	assert.ErrorContains(t, ValidateSavePrompt("maybe"), "unsupported save prompt answer")
+}
+
+func TestValidateBackend(t *testing.T) {
+	assert.NoError(t, ValidateBackend(""))
+	assert.NoError(t, ValidateBackend(BackendGUI))
+	assert.NoError(t, ValidateBackend(BackendDDE))
+	assert.ErrorContains(t, ValidateBackend("com"), "unsupported backend")
+}
@@ -0,0 +1,50 @@
+package compiler
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// handleCrash responds to a Windows Error Reporting dialog for SIMPL Windows, such as
+// "SIMPL Windows has stopped working". The dialog's text is kept as the error, then the
+// dialog is closed, which lets WER finish and end the crashed process.
+func (c *Compiler) handleCrash(ev windows.WindowEvent, timing TimingBreakdown) *CompileResult {
+	var lines []string
+
+	for _, ci := range c.windowMgr.CollectChildInfos(ev.Hwnd) {
+		if text := strings.TrimSpace(ci.Text); text != "" && ci.ClassName != "Button" {
+			lines = append(lines, text)
+		}
+	}
+
+	details := ev.Title
+	if len(lines) > 0 {
+		details = strings.Join(lines, " ")
+	}
+
+	c.log.Error("SIMPL Windows crashed", slog.String("title", ev.Title), slog.String("details", details))
+	c.windowMgr.CloseWindow(ev.Hwnd, "crash report dialog")
+
+	return &CompileResult{
+		Errors:        1,
+		HasErrors:     true,
+		ErrorMessages: []string{"SIMPL Windows crashed: " + details},
+		Timing:        timing,
+	}
+}
+
+// handleExit responds to SIMPL Windows exiting during the compile without a crash
+// dialog, e.g. when it was killed or crashed with error reporting disabled
+func (c *Compiler) handleExit(code int, timing TimingBreakdown) *CompileResult {
+	c.log.Error("SIMPL Windows exited during the compile", slog.String("exitCode", fmt.Sprintf("0x%X", uint32(code))))
+
+	return &CompileResult{
+		Errors:        1,
+		HasErrors:     true,
+		ErrorMessages: []string{fmt.Sprintf("SIMPL Windows exited unexpectedly with code 0x%X", uint32(code))},
+		Timing:        timing,
+	}
+}
@@ -0,0 +1,55 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by Compile and the launch steps around it. They are wrapped with
+// details, so match them with errors.Is rather than by message.
+var (
+	// ErrCancelled is returned by Compile when the compilation was stopped by Cancel
+	ErrCancelled = errors.New("compilation cancelled")
+
+	// ErrWindowNotFound means the SIMPL Windows main window never appeared
+	ErrWindowNotFound = errors.New("SIMPL Windows window not found")
+
+	// ErrForegroundLost means SIMPL Windows could not be brought to, or kept in, the
+	// foreground, so keystrokes could not be sent safely
+	ErrForegroundLost = errors.New("SIMPL Windows is not in the foreground")
+
+	// ErrCompileTimeout means the compile did not finish within the timeout
+	ErrCompileTimeout = errors.New("compilation timeout")
+
+	// ErrSaveFailed means the program could not be saved before compiling (CompileOptions.SaveFirst)
+	ErrSaveFailed = errors.New("failed to save program before compiling")
+
+	// ErrSavePromptAborted means the program had unsaved changes and CompileOptions.SavePrompt
+	// was AnswerAbort
+	ErrSavePromptAborted = errors.New("compile aborted at the save prompt")
+
+	// ErrIncompleteSymbols means SIMPL Windows refused to compile a program with incomplete symbols
+	ErrIncompleteSymbols = errors.New("program contains incomplete symbols and cannot be compiled")
+
+	// ErrCompilerCrashed means SIMPL Windows crashed during the compile: Windows Error
+	// Reporting showed a crash dialog, or the process exited (CompileOptions.Exited)
+	ErrCompilerCrashed = errors.New("SIMPL Windows crashed")
+
+	// ErrNotLicensed means SIMPL Windows showed a licensing or registration dialog while
+	// starting that no auto-respond rule answered
+	ErrNotLicensed = errors.New("SIMPL Windows is not licensed or registered")
+
+	// ErrMissingArtifacts means the compile reported success but the compiled program
+	// was not written, typically because the program folder is read-only (VerifyOutputs)
+	ErrMissingArtifacts = errors.New("compiled program was not written")
+)
+
+// ErrCompileErrors is returned when the compile finished but reported errors
+// (including warnings promoted by WarningsAsErrors). Match it with errors.As.
+type ErrCompileErrors struct {
+	Count int
+}
+
+func (e ErrCompileErrors) Error() string {
+	return fmt.Sprintf("compilation failed with %d error(s)", e.Count)
+}
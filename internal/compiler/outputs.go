@@ -0,0 +1,47 @@
+package compiler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// compiledExtensions are the compiled program files SIMPL Windows writes next to the
+// program; which one depends on the target control system
+var compiledExtensions = []string{".lpz", ".cpz", ".spz"}
+
+// mtimeResolution allows for file systems that store modification times coarsely (FAT
+// rounds them to 2 seconds)
+const mtimeResolution = 2 * time.Second
+
+// VerifyOutputs checks that compiling programPath wrote a compiled program next to it
+// at or after since, and returns its path. SIMPL Windows reports a successful compile
+// even when it could not write the output, for example to a read-only folder.
+func VerifyOutputs(programPath string, since time.Time) (string, error) {
+	base := strings.TrimSuffix(programPath, filepath.Ext(programPath))
+	since = since.Add(-mtimeResolution)
+
+	var stale []string
+
+	for _, ext := range compiledExtensions {
+		info, err := os.Stat(base + ext)
+		if err != nil {
+			continue
+		}
+
+		if !info.ModTime().Before(since) {
+			return base + ext, nil
+		}
+
+		stale = append(stale, filepath.Base(base+ext))
+	}
+
+	if len(stale) > 0 {
+		return "", fmt.Errorf("%w: %s was not updated by this compile", ErrMissingArtifacts, strings.Join(stale, ", "))
+	}
+
+	return "", fmt.Errorf("%w: no %s file was written next to %s", ErrMissingArtifacts,
+		strings.Join(compiledExtensions, ", "), filepath.Base(programPath))
+}
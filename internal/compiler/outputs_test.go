@@ -0,0 +1,39 @@
+package compiler_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/compiler"
+)
+
+func TestVerifyOutputs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	program := filepath.Join(dir, "Lobby.smw")
+	output := filepath.Join(dir, "Lobby.lpz")
+
+	_, err := compiler.VerifyOutputs(program, time.Now())
+	require.ErrorIs(t, err, compiler.ErrMissingArtifacts)
+	assert.Contains(t, err.Error(), "no .lpz, .cpz, .spz file")
+
+	require.NoError(t, os.WriteFile(output, []byte("lpz"), 0o644))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(output, old, old))
+
+	_, err = compiler.VerifyOutputs(program, time.Now())
+	require.ErrorIs(t, err, compiler.ErrMissingArtifacts)
+	assert.Contains(t, err.Error(), "Lobby.lpz was not updated")
+
+	require.NoError(t, os.Chtimes(output, time.Now(), time.Now()))
+
+	got, err := compiler.VerifyOutputs(program, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, output, got)
+}
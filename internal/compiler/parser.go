@@ -3,6 +3,8 @@ package compiler
 import (
 	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
 )
 
 // ParseStatLine parses a line like "Program Warnings: 1" and returns (1, true) if matched, else (0, false).
@@ -40,3 +42,150 @@ func ParseCompileTimeLine(line string) (float64, bool) {
 
 	return secs, true
 }
+
+// statisticPattern matches a "Name: value [unit]" line, e.g. "Program Warnings: 1",
+// "Compile Time: 0.23 seconds" or "Signals Used: 1,024". Names start with a letter and
+// may contain spaces and simple punctuation but no parentheses, which keeps compiler
+// messages such as "ERROR (LGSPLS1004) ..." out.
+var statisticPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9 ./%#-]*?)\s*:\s*([-+]?[0-9][0-9,]*(?:\.[0-9]+)?)(?:\s*[A-Za-z%]+)?\s*$`)
+
+// ParseStatistic parses any "Name: value" statistic line from the "Compile Complete"
+// dialog, returning the name as shown and the value with thousands separators removed
+func ParseStatistic(line string) (string, float64, bool) {
+	matches := statisticPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if matches == nil {
+		return "", 0, false
+	}
+
+	value, err := strconv.ParseFloat(strings.ReplaceAll(matches[2], ",", ""), 64)
+	if err != nil {
+		return "", 0, false
+	}
+
+	return matches[1], value, true
+}
+
+// addStatistic records line in stats if it is a statistic, creating the map as needed
+func addStatistic(stats map[string]float64, line string) map[string]float64 {
+	name, value, ok := ParseStatistic(line)
+	if !ok {
+		return stats
+	}
+
+	if stats == nil {
+		stats = make(map[string]float64)
+	}
+
+	stats[name] = value
+	return stats
+}
+
+// messageCollector groups compiler message lines by type, folding continuation
+// lines into the message that precedes them
+type messageCollector struct {
+	errors, warnings, notices []string
+	lastType                  string // Type of the last message: "ERROR", "WARNING", or "NOTICE"
+}
+
+// add classifies a single line, ignoring blanks and orphaned continuations
+func (m *messageCollector) add(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+
+	lineUpper := strings.ToUpper(line)
+	switch {
+	case strings.HasPrefix(lineUpper, "ERROR\t") || strings.HasPrefix(lineUpper, "ERROR "):
+		m.errors = append(m.errors, line)
+		m.lastType = msgTypeError
+	case strings.HasPrefix(lineUpper, "WARNING\t") || strings.HasPrefix(lineUpper, "WARNING "):
+		m.warnings = append(m.warnings, line)
+		m.lastType = msgTypeWarning
+	case strings.HasPrefix(lineUpper, "NOTICE\t") || strings.HasPrefix(lineUpper, "NOTICE "):
+		m.notices = append(m.notices, line)
+		m.lastType = msgTypeNotice
+	default:
+		// Continuation of previous message - append to the last type that was seen
+		var msgs []string
+		switch m.lastType {
+		case msgTypeError:
+			msgs = m.errors
+		case msgTypeWarning:
+			msgs = m.warnings
+		case msgTypeNotice:
+			msgs = m.notices
+		}
+
+		if len(msgs) > 0 {
+			msgs[len(msgs)-1] += " " + line
+		}
+	}
+}
+
+// isMessageLine reports whether line starts a compiler message
+func isMessageLine(line string) bool {
+	upper := strings.ToUpper(line)
+
+	for _, prefix := range []string{msgTypeError, msgTypeWarning, msgTypeNotice} {
+		if strings.HasPrefix(upper, prefix+"\t") || strings.HasPrefix(upper, prefix+" ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseCompilerOutput parses text written by a non-GUI compile: the statistics shown
+// in the "Compile Complete" dialog and the messages listed in "Program Compilation".
+func ParseCompilerOutput(text string) *CompileResult {
+	result := &CompileResult{}
+
+	var msgs messageCollector
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+
+		if !isMessageLine(line) {
+			result.Stats = addStatistic(result.Stats, line)
+		}
+
+		if n, ok := ParseStatLine(line, "Program Errors"); ok {
+			result.Errors = n
+			msgs.lastType = ""
+			continue
+		}
+
+		if n, ok := ParseStatLine(line, "Program Warnings"); ok {
+			result.Warnings = n
+			msgs.lastType = ""
+			continue
+		}
+
+		if n, ok := ParseStatLine(line, "Program Notices"); ok {
+			result.Notices = n
+			msgs.lastType = ""
+			continue
+		}
+
+		if secs, ok := ParseCompileTimeLine(line); ok {
+			result.CompileTime = secs
+			msgs.lastType = ""
+			continue
+		}
+
+		msgs.add(line)
+	}
+
+	result.ErrorMessages = msgs.errors
+	result.WarningMessages = msgs.warnings
+	result.NoticeMessages = msgs.notices
+
+	// Fall back to the message counts when no statistics were printed
+	result.Errors = max(result.Errors, len(msgs.errors))
+	result.Warnings = max(result.Warnings, len(msgs.warnings))
+	result.Notices = max(result.Notices, len(msgs.notices))
+	result.HasErrors = result.Errors > 0
+
+	return result
+}
@@ -423,3 +423,70 @@ func TestParseCompileTimeLine(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCompilerOutput(t *testing.T) {
+	output := "Program Errors: 1\r\n" +
+		"Program Warnings: 1\r\n" +
+		"Program Notices: 0\r\n" +
+		"Compile Time: 2.50 seconds\r\n" +
+		"\r\n" +
+		"ERROR      (LGSPLS1700) Line 5: Undefined symbol 'foo'\r\n" +
+		"   in module 'Main'\r\n" +
+		"WARNING    (LGCMCVT102) ** Signal bar has no driving source\r\n"
+
+	result := ParseCompilerOutput(output)
+
+	assert.Equal(t, 1, result.Errors)
+	assert.Equal(t, 1, result.Warnings)
+	assert.Equal(t, 0, result.Notices)
+	assert.InDelta(t, 2.5, result.CompileTime, 0.001)
+	assert.True(t, result.HasErrors)
+	assert.Equal(t, []string{"ERROR      (LGSPLS1700) Line 5: Undefined symbol 'foo' in module 'Main'"}, result.ErrorMessages)
+	assert.Len(t, result.WarningMessages, 1)
+	assert.Empty(t, result.NoticeMessages)
+}
+
+func TestParseCompilerOutput_CountsMessagesWithoutStatistics(t *testing.T) {
+	result := ParseCompilerOutput("NOTICE     (LGCMCVT103) ** Signal baz has no destination\n")
+
+	assert.Equal(t, 1, result.Notices)
+	assert.False(t, result.HasErrors)
+}
+
+func TestParseStatistic(t *testing.T) {
+	tests := []struct {
+		line  string
+		name  string
+		value float64
+		ok    bool
+	}{
+		{line: "Program Warnings: 1", name: "Program Warnings", value: 1, ok: true},
+		{line: "Compile Time: 0.23 seconds", name: "Compile Time", value: 0.23, ok: true},
+		{line: "  Signals Used  :  1,024  ", name: "Signals Used", value: 1024, ok: true},
+		{line: "Estimated Memory: 12.5 KB", name: "Estimated Memory", value: 12.5, ok: true},
+		{line: "Memory Used: 45%", name: "Memory Used", value: 45, ok: true},
+		{line: "Compile Complete"},
+		{line: "Total Symbols: 12 of 100"},
+		{line: "ERROR      (LGSPLS1700) Line 5: 12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			name, value, ok := ParseStatistic(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.name, name)
+			assert.InDelta(t, tt.value, value, 0.001)
+		})
+	}
+}
+
+func TestParseCompilerOutput_Stats(t *testing.T) {
+	result := ParseCompilerOutput("Program Errors: 0\r\nSignals Used: 1,024\r\nCompile Time: 2.50 seconds\r\n" +
+		"WARNING    (LGCMCVT102) Line: 3\r\n")
+
+	assert.Equal(t, map[string]float64{
+		"Program Errors": 0,
+		"Signals Used":   1024,
+		"Compile Time":   2.5,
+	}, result.Stats)
+}
@@ -0,0 +1,94 @@
+package compiler
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// Modes record how a CompileResult was produced
+const (
+	ModeCompile           = "compile"             // Convert/Compile (F12)
+	ModeRecompileAll      = "recompile-all"       // Recompile All (Alt+F12), as requested
+	ModeRecompileAllRetry = "recompile-all-retry" // Convert/Compile failed after a database change and was retried with Recompile All
+)
+
+// recompileAllHints are phrases in compile errors showing that the signal database has
+// changed since the program's last full compile, which only Recompile All resolves
+var recompileAllHints = []string{"recompile all", "database"}
+
+// needsRecompileAll reports whether a failed compile should be retried with Recompile All
+func needsRecompileAll(opts CompileOptions, result *CompileResult) bool {
+	if !opts.AutoRecompileAll || opts.RecompileAll || !result.HasErrors {
+		return false
+	}
+
+	for _, msg := range result.ErrorMessages {
+		lower := strings.ToLower(msg)
+
+		for _, hint := range recompileAllHints {
+			if strings.Contains(lower, hint) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// retryWithRecompileAll dismisses the results of the failed compile first and compiles again
+// in the same SIMPL Windows instance with Recompile All. The retry goes through the stages
+// again from StageIdle, and the time spent on the first attempt is added to its timing.
+func (c *Compiler) retryWithRecompileAll(
+	opts CompileOptions,
+	events <-chan windows.WindowEvent,
+	first *CompileResult,
+	compileCompleteHwnd uintptr,
+) (uintptr, *CompileResult, error) {
+	c.log.Info("Compile errors point to a database change, retrying with Recompile All")
+
+	if compileCompleteHwnd != 0 {
+		c.windowMgr.CloseWindow(compileCompleteHwnd, "Compile Complete dialog")
+		c.clock.Sleep(timeouts.StabilityCheckInterval)
+	}
+
+	opts.RecompileAll = true
+	c.stage = StageIdle
+
+	if opts.Backend != BackendDDE && !opts.MessageInput {
+		_ = c.windowMgr.SetForeground(opts.Hwnd)
+		c.clock.Sleep(timeouts.FocusVerificationDelay)
+	}
+
+	keystrokeAt := c.clock.Now()
+	strategy := c.triggerCompile(opts, firstTriggerStrategy(opts))
+	c.log.Debug("Recompile All triggered", slog.String("strategy", strategy.String()))
+	c.enterStage(StageTriggered)
+
+	hwnd, result, err := c.handleCompilationEvents(opts, events, keystrokeAt, strategy)
+	if result != nil {
+		result.Mode = ModeRecompileAllRetry
+		result.Timing.addAttempt(first.Timing)
+	}
+
+	return hwnd, result, err
+}
+
+// addAttempt adds the compile phases of an earlier attempt to t
+func (t *TimingBreakdown) addAttempt(earlier TimingBreakdown) {
+	t.KeystrokeToCompiling += earlier.KeystrokeToCompiling
+	t.Compile += earlier.Compile
+	t.DialogHandling += earlier.DialogHandling
+	t.Dialogs = append(append([]DialogTiming{}, earlier.Dialogs...), t.Dialogs...)
+}
+
+// modeFor returns the mode of a compile that was not retried
+func modeFor(opts CompileOptions) string {
+	if opts.RecompileAll {
+		return ModeRecompileAll
+	}
+
+	return ModeCompile
+}
@@ -163,3 +163,23 @@ func TestCompileOptions_SimplPidPtr(t *testing.T) {
 	*opts.SimplPidPtr = 12345
 	assert.Equal(t, uint32(12345), pid)
 }
+
+func TestCompileResult_PromoteWarnings(t *testing.T) {
+	result := compiler.CompileResult{
+		Errors:          1,
+		Warnings:        2,
+		Notices:         1,
+		ErrorMessages:   []string{"ERROR      (LGSPLS1700) Line 5: Undefined symbol 'foo'"},
+		WarningMessages: []string{"WARNING    (LGCMCVT102) ** Signal foo", "WARNING    (LGCMCVT102) ** Signal bar"},
+		NoticeMessages:  []string{"NOTICE     (LGCMCVT103) ** Signal baz"},
+	}
+
+	result.PromoteWarnings()
+
+	assert.Equal(t, 3, result.Errors)
+	assert.Equal(t, 0, result.Warnings)
+	assert.Equal(t, 1, result.Notices, "Notices are not affected")
+	assert.Len(t, result.ErrorMessages, 3)
+	assert.Empty(t, result.WarningMessages)
+	assert.True(t, result.HasErrors)
+}
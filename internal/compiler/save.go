@@ -0,0 +1,65 @@
+package compiler
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/keychord"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+)
+
+// saveChord and saveMenuPath are the ways saveProgram asks SIMPL Windows to save
+var (
+	saveChord    = keychord.MustParse("ctrl+s")
+	saveMenuPath = []string{"File", "Save"}
+)
+
+// saveProgram saves the open program before compiling (CompileOptions.SaveFirst), using
+// Ctrl+S or, if that cannot be sent, on the DDE backend or with MessageInput, the File > Save
+// menu command.
+// The save is complete when the program file is rewritten and SIMPL Windows responds again;
+// a file that is not rewritten (e.g. a program without unsaved changes) is only logged.
+func (c *Compiler) saveProgram(opts CompileOptions) error {
+	c.log.Info("Saving program before compiling")
+	start := c.clock.Now()
+	before := modTime(opts.FilePath)
+
+	sent := opts.Backend != BackendDDE && !opts.MessageInput && c.keyboard.SendChordWithSendInput(saveChord)
+	if !sent && (opts.Hwnd == 0 || !c.windowMgr.InvokeMenuItem(opts.Hwnd, saveMenuPath...)) {
+		return ErrSaveFailed
+	}
+
+	deadline := start.Add(timeouts.SaveTimeout)
+	for !modTime(opts.FilePath).After(before) {
+		if c.isCancelled() {
+			return ErrCancelled
+		}
+
+		if !c.clock.Now().Before(deadline) {
+			c.log.Warn("Program file was not rewritten; it may have had no unsaved changes",
+				slog.Duration("waited", timeouts.SaveTimeout))
+			break
+		}
+
+		c.clock.Sleep(timeouts.StatePollingInterval)
+	}
+
+	if opts.Hwnd != 0 && !c.processMgr.WaitForReady(opts.Hwnd, timeouts.WindowReadyTimeout) {
+		return fmt.Errorf("SIMPL Windows stopped responding while saving: %w", ErrSaveFailed)
+	}
+
+	c.log.Debug("Program saved", slog.Duration("elapsed", c.clock.Since(start)))
+	return nil
+}
+
+// modTime returns the modification time of path, or the zero time if it cannot be read
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
@@ -0,0 +1,94 @@
+package compiler
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/clock"
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// Reasons a dialog was skipped
+const (
+	SkipDismissed    = "dismissed"    // A known, harmless dialog closed without reading it
+	SkipUnrecognised = "unrecognised" // No handler or auto-respond rule matched, so it was left open
+)
+
+// SkippedDialog is a dialog the automation saw but chose not to act on
+type SkippedDialog struct {
+	Time   time.Time `json:"time"`
+	Title  string    `json:"title"`
+	Class  string    `json:"class,omitempty"`
+	Hwnd   uintptr   `json:"hwnd"`
+	Modal  bool      `json:"modal,omitempty"`
+	Reason string    `json:"reason"` // One of the Skip reasons
+	Stage  string    `json:"stage"`  // Stage of the compile when it appeared
+}
+
+// skipRecorder collects the dialogs skipped during a compile, each once
+type skipRecorder struct {
+	log   logger.LoggerInterface
+	clock clock.Clock
+
+	mu       sync.Mutex
+	mainHwnd uintptr // SIMPL Windows itself, which is not a dialog
+	seen     map[uintptr]bool
+	dialogs  []SkippedDialog
+}
+
+func newSkipRecorder(log logger.LoggerInterface, clk clock.Clock) *skipRecorder {
+	return &skipRecorder{log: log, clock: clk, seen: make(map[uintptr]bool)}
+}
+
+// reset starts a new list for a compile of mainHwnd
+func (r *skipRecorder) reset(mainHwnd uintptr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mainHwnd = mainHwnd
+	r.seen = make(map[uintptr]bool)
+	r.dialogs = nil
+}
+
+// record notes that ev's dialog was skipped for reason during stage, unless it is
+// SIMPL Windows itself or was already noted
+func (r *skipRecorder) record(ev windows.WindowEvent, reason string, stage Stage) {
+	r.mu.Lock()
+
+	if ev.Hwnd == 0 || ev.Hwnd == r.mainHwnd || r.seen[ev.Hwnd] {
+		r.mu.Unlock()
+		return
+	}
+
+	r.seen[ev.Hwnd] = true
+
+	dialog := SkippedDialog{
+		Time:   r.clock.Now(),
+		Title:  ev.Title,
+		Class:  ev.Class,
+		Hwnd:   ev.Hwnd,
+		Modal:  ev.Modal,
+		Reason: reason,
+		Stage:  stage.String(),
+	}
+	r.dialogs = append(r.dialogs, dialog)
+	r.mu.Unlock()
+
+	r.log.Debug("Skipped dialog",
+		slog.String("title", ev.Title),
+		slog.String("hwnd", fmt.Sprintf("0x%X", ev.Hwnd)),
+		slog.String("reason", reason),
+		slog.String("stage", stage.String()),
+	)
+}
+
+// list returns a copy of the dialogs recorded so far
+func (r *skipRecorder) list() []SkippedDialog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]SkippedDialog{}, r.dialogs...)
+}
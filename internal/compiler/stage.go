@@ -0,0 +1,77 @@
+package compiler
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+)
+
+// Stage is a step of a compile. Compile only moves forward through the stages,
+// although a run that fails or is cancelled may skip some.
+type Stage int
+
+const (
+	StageIdle           Stage = iota // Before the compile has been triggered
+	StageTriggered                   // Trigger sent, waiting for SIMPL Windows to respond
+	StageSavePrompts                 // Answering the save and commented-out symbol prompts
+	StageCompiling                   // "Compiling..." is showing
+	StageResultsParsing              // "Compile Complete" seen, collecting counts and messages
+	StageClosing                     // Closing dialogs and SIMPL Windows
+)
+
+func (s Stage) String() string {
+	switch s {
+	case StageIdle:
+		return "idle"
+	case StageTriggered:
+		return "triggered"
+	case StageSavePrompts:
+		return "save-prompts"
+	case StageCompiling:
+		return "compiling"
+	case StageResultsParsing:
+		return "results-parsing"
+	case StageClosing:
+		return "closing"
+	default:
+		return "unknown"
+	}
+}
+
+// defaultStageTimeouts apply to stages without an override in CompileOptions.StageTimeouts.
+// When the Triggered timeout expires the next trigger strategy is tried; when the
+// ResultsParsing timeout expires the result is returned without detailed messages.
+// Any other stage given a timeout fails the compile with ErrCompileTimeout.
+var defaultStageTimeouts = map[Stage]time.Duration{
+	StageTriggered:      timeouts.CompileTriggerTimeout,
+	StageResultsParsing: timeouts.ProgramCompilationTimeout,
+}
+
+// stageTimeout returns the timeout for stage, or 0 if it has none
+func stageTimeout(opts CompileOptions, stage Stage) time.Duration {
+	if d, ok := opts.StageTimeouts[stage]; ok {
+		return d
+	}
+
+	return defaultStageTimeouts[stage]
+}
+
+// enterStage moves the compile to stage, reporting the change to OnStageChange.
+// Moving to the current or an earlier stage does nothing; it returns whether the stage changed.
+func (c *Compiler) enterStage(stage Stage) bool {
+	if stage <= c.stage {
+		return false
+	}
+
+	from := c.stage
+	c.stage = stage
+
+	c.log.Debug("Compile stage changed", slog.String("from", from.String()), slog.String("to", stage.String()))
+
+	if c.onStageChange != nil {
+		c.onStageChange(from, stage)
+	}
+
+	return true
+}
@@ -0,0 +1,31 @@
+package compiler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+)
+
+func TestStage_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "idle", StageIdle.String())
+	assert.Equal(t, "save-prompts", StageSavePrompts.String())
+	assert.Equal(t, "results-parsing", StageResultsParsing.String())
+	assert.Equal(t, "unknown", Stage(99).String())
+}
+
+func TestStageTimeout(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, timeouts.CompileTriggerTimeout, stageTimeout(CompileOptions{}, StageTriggered))
+	assert.Equal(t, time.Duration(0), stageTimeout(CompileOptions{}, StageCompiling), "Compiling is bounded by the compilation timeout")
+
+	opts := CompileOptions{StageTimeouts: map[Stage]time.Duration{StageTriggered: 0, StageCompiling: time.Minute}}
+	assert.Equal(t, time.Duration(0), stageTimeout(opts, StageTriggered), "An override of 0 disables the timeout")
+	assert.Equal(t, time.Minute, stageTimeout(opts, StageCompiling))
+	assert.Equal(t, timeouts.ProgramCompilationTimeout, stageTimeout(opts, StageResultsParsing))
+}
@@ -0,0 +1,66 @@
+package compiler
+
+import (
+	"log/slog"
+	"time"
+)
+
+// TimingBreakdown records how long each phase of a run took, so the fixed
+// delays that dominate a compile can be identified and tuned.
+// Launch, WindowAppear and UISettle are filled in by the caller that launches
+// SIMPL Windows; the remaining phases are measured by Compile.
+type TimingBreakdown struct {
+	Launch               time.Duration // ShellExecuteEx until the process was started
+	WindowAppear         time.Duration // Waiting for the main window to appear and respond
+	UISettle             time.Duration // Fixed delay for the UI to settle before compiling
+	KeystrokeToCompiling time.Duration // Compile keystroke until "Compiling..." appeared
+	Compile              time.Duration // "Compiling..." until "Compile Complete"
+	DialogHandling       time.Duration // Responding to prompts (save, commented-out symbols, Operation Complete)
+	Cleanup              time.Duration // Closing dialogs and SIMPL Windows, including the save confirmation
+
+	Dialogs []DialogTiming // Each dialog seen while compiling, in order of appearance
+}
+
+// DialogTiming records when a dialog first appeared, so per-dialog timeouts can be
+// tuned from real runs
+type DialogTiming struct {
+	Title string
+	Hwnd  uintptr
+	At    time.Duration // Since the compile keystroke
+}
+
+// recordDialog notes the first appearance of a dialog, returning false if it was already seen
+func (t *TimingBreakdown) recordDialog(title string, hwnd uintptr, at time.Duration) bool {
+	for _, d := range t.Dialogs {
+		if d.Hwnd == hwnd {
+			return false
+		}
+	}
+
+	t.Dialogs = append(t.Dialogs, DialogTiming{Title: title, Hwnd: hwnd, At: at})
+
+	return true
+}
+
+// Total returns the sum of all recorded phases
+func (t TimingBreakdown) Total() time.Duration {
+	return t.Launch + t.WindowAppear + t.UISettle + t.KeystrokeToCompiling + t.Compile + t.DialogHandling + t.Cleanup
+}
+
+// LogAttrs returns the breakdown as log attributes, rounded to milliseconds
+func (t TimingBreakdown) LogAttrs() []any {
+	ms := func(d time.Duration) string {
+		return d.Round(time.Millisecond).String()
+	}
+
+	return []any{
+		slog.String("launch", ms(t.Launch)),
+		slog.String("windowAppear", ms(t.WindowAppear)),
+		slog.String("uiSettle", ms(t.UISettle)),
+		slog.String("keystrokeToCompiling", ms(t.KeystrokeToCompiling)),
+		slog.String("compile", ms(t.Compile)),
+		slog.String("dialogHandling", ms(t.DialogHandling)),
+		slog.String("cleanup", ms(t.Cleanup)),
+		slog.String("total", ms(t.Total())),
+	}
+}
@@ -0,0 +1,40 @@
+package compiler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimingBreakdown_Total(t *testing.T) {
+	t.Parallel()
+
+	timing := TimingBreakdown{
+		Launch:               100 * time.Millisecond,
+		WindowAppear:         20 * time.Second,
+		UISettle:             5 * time.Second,
+		KeystrokeToCompiling: 300 * time.Millisecond,
+		Compile:              12 * time.Second,
+		DialogHandling:       600 * time.Millisecond,
+		Cleanup:              2 * time.Second,
+	}
+
+	assert.Equal(t, 40*time.Second, timing.Total())
+	assert.Len(t, timing.LogAttrs(), 8)
+}
+
+func TestTimingBreakdown_RecordDialog(t *testing.T) {
+	t.Parallel()
+
+	var timing TimingBreakdown
+
+	assert.True(t, timing.recordDialog("Compiling...", 0x1111, time.Second))
+	assert.False(t, timing.recordDialog("Compiling...", 0x1111, 2*time.Second), "Repeat events keep the first time")
+	assert.True(t, timing.recordDialog("Compile Complete", 0x2222, 3*time.Second))
+
+	assert.Equal(t, []DialogTiming{
+		{Title: "Compiling...", Hwnd: 0x1111, At: time.Second},
+		{Title: "Compile Complete", Hwnd: 0x2222, At: 3 * time.Second},
+	}, timing.Dialogs)
+}
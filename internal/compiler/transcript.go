@@ -0,0 +1,82 @@
+package compiler
+
+import (
+	"sync"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// ControlTranscript is the text of one child control of a dialog
+type ControlTranscript struct {
+	Class string   `json:"class"`
+	Text  string   `json:"text,omitempty"`
+	Items []string `json:"items,omitempty"` // List box entries
+}
+
+// DialogTranscript is everything a dialog showed when it was first seen
+type DialogTranscript struct {
+	Title    string              `json:"title"`
+	Class    string              `json:"class,omitempty"`
+	Hwnd     uintptr             `json:"hwnd"`
+	Controls []ControlTranscript `json:"controls"`
+}
+
+// transcriptRecorder collects a transcript of each dialog the first time it is seen
+type transcriptRecorder struct {
+	mainHwnd uintptr // SIMPL Windows itself, which is not a dialog
+
+	mu          sync.Mutex
+	seen        map[uintptr]bool
+	transcripts []DialogTranscript
+}
+
+func newTranscriptRecorder(mainHwnd uintptr) *transcriptRecorder {
+	return &transcriptRecorder{mainHwnd: mainHwnd, seen: make(map[uintptr]bool)}
+}
+
+// observe notes a window event for the transcripts and the audit trail
+func (c *Compiler) observe(ev windows.WindowEvent) {
+	c.audit.see(ev)
+	c.transcribe(ev)
+}
+
+// transcribe records ev's dialog, reading its controls before any handler closes it.
+// It does nothing unless transcripts were requested.
+func (c *Compiler) transcribe(ev windows.WindowEvent) {
+	r := c.transcripts
+	if r == nil || ev.Hwnd == 0 || ev.Hwnd == r.mainHwnd {
+		return
+	}
+
+	r.mu.Lock()
+	seen := r.seen[ev.Hwnd]
+	r.seen[ev.Hwnd] = true
+	r.mu.Unlock()
+
+	if seen {
+		return
+	}
+
+	transcript := DialogTranscript{Title: ev.Title, Class: ev.Class, Hwnd: ev.Hwnd, Controls: []ControlTranscript{}}
+
+	for _, ci := range c.windowMgr.CollectChildInfos(ev.Hwnd) {
+		transcript.Controls = append(transcript.Controls, ControlTranscript{Class: ci.ClassName, Text: ci.Text, Items: ci.Items})
+	}
+
+	r.mu.Lock()
+	r.transcripts = append(r.transcripts, transcript)
+	r.mu.Unlock()
+}
+
+// recordedTranscripts returns the transcripts recorded so far, or nil when disabled
+func (c *Compiler) recordedTranscripts() []DialogTranscript {
+	r := c.transcripts
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]DialogTranscript{}, r.transcripts...)
+}
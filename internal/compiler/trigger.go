@@ -0,0 +1,171 @@
+package compiler
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Backends select how compilation is triggered
+const (
+	BackendGUI = "gui" // Keystrokes (and menu fallback) sent to the focused SIMPL Windows window
+	BackendDDE = "dde" // Experimental: DDE execute commands, falling back to the GUI
+)
+
+// DDE conversation used by BackendDDE. SIMPL Windows' DDE interface is undocumented;
+// these follow the [Command] convention of classic DDE servers and may need adjusting.
+const (
+	ddeService             = "SMPWIN"
+	ddeTopic               = "System"
+	ddeCompileCommand      = "[Compile]"
+	ddeRecompileAllCommand = "[RecompileAll]"
+)
+
+// ValidateBackend returns an error if backend is not a supported backend name
+func ValidateBackend(backend string) error {
+	switch backend {
+	case "", BackendGUI, BackendDDE:
+		return nil
+	default:
+		return fmt.Errorf("unsupported backend %q (supported: %s, %s)", backend, BackendGUI, BackendDDE)
+	}
+}
+
+// triggerStrategy is a way of asking SIMPL Windows to start compiling
+type triggerStrategy int
+
+const (
+	triggerDDE        triggerStrategy = iota // DDE execute command (BackendDDE only)
+	triggerSendInput                         // Accelerator keystroke via SendInput
+	triggerKeybdEvent                        // Accelerator keystroke via keybd_event
+	triggerMenu                              // WM_COMMAND for the Project menu item
+)
+
+// Project menu items equivalent to the compile accelerators
+var (
+	compileMenuPath      = []string{"Project", "Convert/Compile"}
+	recompileAllMenuPath = []string{"Project", "Recompile All"}
+)
+
+func (s triggerStrategy) String() string {
+	switch s {
+	case triggerDDE:
+		return "DDE"
+	case triggerSendInput:
+		return "SendInput"
+	case triggerKeybdEvent:
+		return "keybd_event"
+	case triggerMenu:
+		return "menu"
+	default:
+		return "unknown"
+	}
+}
+
+// firstTriggerStrategy returns the strategy a compile starts with for the selected backend
+func firstTriggerStrategy(opts CompileOptions) triggerStrategy {
+	if opts.Backend == BackendDDE {
+		return triggerDDE
+	}
+
+	if opts.MessageInput {
+		return triggerMenu
+	}
+
+	return triggerSendInput
+}
+
+// triggerCompile starts compilation using the first strategy that reports success,
+// beginning at from. It returns the strategy that was used. With MessageInput the
+// keystroke strategies, which need focus, are skipped.
+func (c *Compiler) triggerCompile(opts CompileOptions, from triggerStrategy) triggerStrategy {
+	for s := from; s < triggerMenu; s++ {
+		if opts.MessageInput && s != triggerDDE {
+			break
+		}
+
+		if c.sendTrigger(opts, s) {
+			return s
+		}
+
+		c.log.Warn("Compile trigger failed, trying next strategy", slog.String("strategy", s.String()))
+
+		if s == triggerDDE && !opts.MessageInput {
+			// The DDE backend skipped focusing; keystrokes need it
+			_ = c.windowMgr.SetForeground(opts.Hwnd)
+		}
+	}
+
+	c.sendTrigger(opts, triggerMenu)
+	return triggerMenu
+}
+
+// sendTrigger performs a single trigger strategy and reports whether it claimed success
+func (c *Compiler) sendTrigger(opts CompileOptions, s triggerStrategy) bool {
+	// A configured chord replaces the default F12 / Alt+F12 accelerators
+	chord := opts.CompileKey
+	if opts.RecompileAll {
+		chord = opts.RecompileAllKey
+	}
+
+	switch s {
+	case triggerDDE:
+		if c.dde == nil {
+			c.log.Warn("No DDE client available")
+			return false
+		}
+
+		command := ddeCompileCommand
+		if opts.RecompileAll {
+			command = ddeRecompileAllCommand
+		}
+
+		if err := c.dde.DDEExecute(ddeService, ddeTopic, command); err != nil {
+			c.log.Warn("DDE compile command failed", slog.String("command", command), slog.Any("error", err))
+			return false
+		}
+
+		return true
+
+	case triggerSendInput:
+		switch {
+		case !chord.IsZero():
+			return c.keyboard.SendChordWithSendInput(chord)
+		case opts.RecompileAll:
+			return c.keyboard.SendAltF12WithSendInput()
+		default:
+			return c.keyboard.SendF12WithSendInput()
+		}
+
+	case triggerKeybdEvent:
+		// keybd_event cannot report failure; only the absence of a response reveals it
+		switch {
+		case !chord.IsZero():
+			c.keyboard.SendChord(chord)
+		case opts.RecompileAll:
+			c.keyboard.SendAltF12()
+		default:
+			c.keyboard.SendF12()
+		}
+
+		return true
+
+	case triggerMenu:
+		if opts.Hwnd == 0 {
+			return false
+		}
+
+		path := compileMenuPath
+		if opts.RecompileAll {
+			path = recompileAllMenuPath
+		}
+
+		if !c.windowMgr.InvokeMenuItem(opts.Hwnd, path...) {
+			c.log.Warn("Could not invoke compile menu item", slog.Any("path", path))
+			return false
+		}
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,185 @@
+// Package eventstream writes machine-readable progress events (one JSON object
+// per line) so orchestration tools can follow a compile as it happens.
+package eventstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/clock"
+	"github.com/Norgate-AV/smpc/internal/redact"
+)
+
+const (
+	// FormatNDJSON is the only supported stream format: newline-delimited JSON
+	FormatNDJSON = "ndjson"
+
+	// TypeLifecycle marks events describing smpc's own progress (launch, compile, exit)
+	TypeLifecycle = "lifecycle"
+
+	// TypeWindow marks events reported by the background window monitor
+	TypeWindow = "window"
+)
+
+// Lifecycle event names
+const (
+	EventStarted        = "started"
+	EventSimplLaunched  = "simpl_launched"
+	EventWindowReady    = "window_ready"
+	EventCompileStarted = "compile_started"
+	EventCompileDone    = "compile_finished"
+	EventExited         = "exited"
+)
+
+// Window event names
+const (
+	EventWindowAppeared = "window_appeared"
+	EventWindowUpdated  = "window_updated"
+)
+
+// Event is a single line in the stream
+type Event struct {
+	Time  time.Time      `json:"time"`
+	Type  string         `json:"type"`
+	Event string         `json:"event"`
+	Data  map[string]any `json:"data,omitempty"`
+}
+
+// Stream serializes events to a writer. A nil *Stream is valid and discards
+// everything, so callers do not need to check whether streaming is enabled.
+type Stream struct {
+	mu       sync.Mutex
+	enc      *json.Encoder
+	clock    clock.Clock
+	redactor *redact.Redactor
+}
+
+// ValidateFormat returns an error for any format other than "" (disabled) or ndjson
+func ValidateFormat(format string) error {
+	switch format {
+	case "", FormatNDJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported events format %q (supported: %s)", format, FormatNDJSON)
+	}
+}
+
+// NewStream creates a stream writing NDJSON to w using the system clock
+func NewStream(w io.Writer) *Stream {
+	return NewStreamWithClock(w, clock.New())
+}
+
+// NewStreamWithClock creates a stream with an injected clock for timestamps
+func NewStreamWithClock(w io.Writer, clk clock.Clock) *Stream {
+	return &Stream{
+		enc:   json.NewEncoder(w),
+		clock: clk,
+	}
+}
+
+// WithRedactor strips user names and paths from string values before they are written
+func (s *Stream) WithRedactor(r *redact.Redactor) *Stream {
+	if s != nil {
+		s.redactor = r
+	}
+
+	return s
+}
+
+// Emit writes one event. Write errors are ignored: losing a progress line
+// must never fail the compile itself.
+func (s *Stream) Emit(eventType, name string, data map[string]any) {
+	if s == nil {
+		return
+	}
+
+	if s.redactor != nil && data != nil {
+		redacted := make(map[string]any, len(data))
+		for k, v := range data {
+			switch val := v.(type) {
+			case string:
+				v = s.redactor.String(val)
+			case []string:
+				strs := make([]string, len(val))
+				for i, str := range val {
+					strs[i] = s.redactor.String(str)
+				}
+
+				v = strs
+			}
+
+			redacted[k] = v
+		}
+
+		data = redacted
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.enc.Encode(Event{
+		Time:  s.clock.Now().UTC(),
+		Type:  eventType,
+		Event: name,
+		Data:  data,
+	})
+}
+
+// Lifecycle writes a lifecycle event
+func (s *Stream) Lifecycle(name string, data map[string]any) {
+	s.Emit(TypeLifecycle, name, data)
+}
+
+// WindowInfo describes a window seen by the monitor
+type WindowInfo struct {
+	Title   string
+	Class   string
+	Hwnd    uintptr
+	Pid     uint32
+	Parent  uintptr  // Owner window (0 = none)
+	Kind    string   // modal, dialog or window
+	Rect    [4]int32 // Left, top, right and bottom in screen coordinates
+	Buttons []string // Button captions
+	Texts   []string // Child control text (updates only)
+}
+
+// Window writes a window event for a dialog or window seen by the monitor
+func (s *Stream) Window(w WindowInfo) {
+	s.window(EventWindowAppeared, w)
+}
+
+// WindowUpdated writes a window event for an open dialog whose title or text changed
+func (s *Stream) WindowUpdated(w WindowInfo) {
+	s.window(EventWindowUpdated, w)
+}
+
+func (s *Stream) window(name string, w WindowInfo) {
+	data := map[string]any{
+		"title": w.Title,
+		"class": w.Class,
+		"hwnd":  fmt.Sprintf("0x%X", w.Hwnd),
+		"pid":   w.Pid,
+		"rect":  w.Rect,
+	}
+
+	if w.Kind != "" {
+		data["kind"] = w.Kind
+	}
+
+	if w.Parent != 0 {
+		data["parent"] = fmt.Sprintf("0x%X", w.Parent)
+	}
+
+	if len(w.Buttons) > 0 {
+		data["buttons"] = w.Buttons
+	}
+
+	if len(w.Texts) > 0 {
+		data["texts"] = w.Texts
+	}
+
+	s.Emit(TypeWindow, name, data)
+}
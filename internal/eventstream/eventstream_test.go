@@ -0,0 +1,102 @@
+package eventstream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/redact"
+	"github.com/Norgate-AV/smpc/internal/testutil"
+)
+
+func TestValidateFormat(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, ValidateFormat(""))
+	assert.NoError(t, ValidateFormat("ndjson"))
+	assert.Error(t, ValidateFormat("json"))
+}
+
+func TestStream_WritesOneJSONObjectPerLine(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	clk := testutil.NewFakeClock()
+	s := NewStreamWithClock(&buf, clk)
+
+	s.Lifecycle(EventStarted, map[string]any{"file": "C:\\test.smw"})
+	clk.Advance(2 * time.Second)
+	s.Window(WindowInfo{
+		Title: "Compiling...", Class: "#32770", Hwnd: 0x1A2B, Pid: 1234,
+		Parent: 0x10, Kind: "modal", Rect: [4]int32{10, 20, 310, 120}, Buttons: []string{"&OK"},
+	})
+
+	var events []Event
+
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var ev Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev), "Every line should be valid JSON")
+		events = append(events, ev)
+	}
+
+	require.Len(t, events, 2)
+
+	assert.Equal(t, TypeLifecycle, events[0].Type)
+	assert.Equal(t, EventStarted, events[0].Event)
+	assert.Equal(t, "C:\\test.smw", events[0].Data["file"])
+	assert.Equal(t, "2025-01-01T00:00:00Z", events[0].Time.Format(time.RFC3339))
+
+	assert.Equal(t, TypeWindow, events[1].Type)
+	assert.Equal(t, "Compiling...", events[1].Data["title"])
+	assert.Equal(t, "0x1A2B", events[1].Data["hwnd"])
+	assert.InDelta(t, 1234, events[1].Data["pid"], 0)
+	assert.Equal(t, "0x10", events[1].Data["parent"])
+	assert.Equal(t, "modal", events[1].Data["kind"])
+	assert.Equal(t, []any{10.0, 20.0, 310.0, 120.0}, events[1].Data["rect"])
+	assert.Equal(t, []any{"&OK"}, events[1].Data["buttons"])
+	assert.Equal(t, 2*time.Second, events[1].Time.Sub(events[0].Time))
+}
+
+func TestStream_WindowUpdated(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	s := NewStream(&buf)
+
+	s.WindowUpdated(WindowInfo{Title: "Compiling...", Class: "#32770", Hwnd: 0x1A2B, Pid: 1234, Texts: []string{"Compiling Lobby.smw", "42%"}})
+
+	var ev Event
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &ev))
+	assert.Equal(t, TypeWindow, ev.Type)
+	assert.Equal(t, EventWindowUpdated, ev.Event)
+	assert.Equal(t, []any{"Compiling Lobby.smw", "42%"}, ev.Data["texts"])
+}
+
+func TestStream_NilIsNoOp(t *testing.T) {
+	t.Parallel()
+
+	var s *Stream
+	assert.NotPanics(t, func() {
+		s.Lifecycle(EventExited, nil)
+		s.Window(WindowInfo{Title: "SIMPL Windows", Hwnd: 1, Pid: 1})
+	})
+}
+
+func TestStream_WithRedactor(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	s := NewStream(&buf).WithRedactor(redact.NewWithUsers(redact.ModeBasename, "jsmith"))
+
+	s.Window(WindowInfo{Title: `SIMPL Windows - [C:\Users\jsmith\program.smw]`, Class: "Afx", Hwnd: 1, Pid: 1})
+	s.WindowUpdated(WindowInfo{Title: "Compiling...", Hwnd: 1, Pid: 1, Texts: []string{`Compiling C:\Users\jsmith\program.smw`}})
+
+	assert.NotContains(t, buf.String(), "jsmith")
+	assert.Contains(t, buf.String(), "program.smw")
+}
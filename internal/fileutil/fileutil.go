@@ -0,0 +1,40 @@
+// Package fileutil holds file helpers shared by the packages that copy programs and
+// their outputs around.
+package fileutil
+
+import (
+	"io"
+	"os"
+)
+
+// CopyFile copies src to dst, preserving the modification time. dst keeps src's
+// permissions but is always writable by its owner, so a later copy can replace it.
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm()|0o200)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
@@ -0,0 +1,46 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "Lobby.smw")
+	dst := filepath.Join(dir, "copy", "Lobby.smw")
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, os.WriteFile(src, []byte("program"), 0o444))
+	require.NoError(t, os.Chtimes(src, modTime, modTime))
+	require.NoError(t, os.MkdirAll(filepath.Dir(dst), 0o755))
+
+	require.NoError(t, CopyFile(src, dst))
+
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "program", string(data))
+
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(modTime), "keeps the modification time")
+
+	// A read-only source must not leave a copy that cannot be replaced
+	require.NoError(t, CopyFile(src, dst))
+}
+
+func TestCopyFile_MissingSource(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	err := CopyFile(filepath.Join(dir, "missing.smw"), filepath.Join(dir, "copy.smw"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
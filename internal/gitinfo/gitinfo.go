@@ -0,0 +1,94 @@
+// Package gitinfo identifies the git revision a program is compiled from, so results
+// can be traced back to source.
+package gitinfo
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"strings"
+)
+
+// Info describes the state of the repository containing a program
+type Info struct {
+	Commit string `json:"commit"`           // Full hash of HEAD; empty before the first commit
+	Branch string `json:"branch,omitempty"` // Checked-out branch; empty when HEAD is detached
+	Dirty  bool   `json:"dirty"`            // Tracked files have uncommitted changes
+}
+
+// ShortCommit returns the abbreviated commit hash
+func (i Info) ShortCommit() string {
+	if len(i.Commit) > 12 {
+		return i.Commit[:12]
+	}
+
+	return i.Commit
+}
+
+// String formats the revision like "main@1a2b3c4d5e6f (dirty)"
+func (i Info) String() string {
+	s := i.ShortCommit()
+	if s == "" {
+		s = "(no commits)"
+	}
+
+	if i.Branch != "" {
+		s = i.Branch + "@" + s
+	}
+
+	if i.Dirty {
+		s += " (dirty)"
+	}
+
+	return s
+}
+
+// Runner runs git in dir and returns its standard output
+type Runner func(dir string, args ...string) ([]byte, error)
+
+// RunGit runs the git executable on PATH
+func RunGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	return cmd.Output()
+}
+
+// Lookup returns the revision of the repository containing dir. ok is false when dir
+// is not in a git work tree or git is not installed.
+func Lookup(dir string, run Runner) (info Info, ok bool) {
+	// Untracked files are ignored: compiler outputs are rarely committed and would
+	// otherwise mark every compile dirty
+	out, err := run(dir, "status", "--porcelain=v2", "--branch", "--untracked-files=no")
+	if err != nil {
+		return Info{}, false
+	}
+
+	return parseStatus(out), true
+}
+
+// parseStatus reads the output of git status --porcelain=v2 --branch
+func parseStatus(out []byte) Info {
+	var info Info
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "# branch.oid "):
+			if oid := strings.TrimPrefix(line, "# branch.oid "); oid != "(initial)" {
+				info.Commit = oid
+			}
+		case strings.HasPrefix(line, "# branch.head "):
+			if head := strings.TrimPrefix(line, "# branch.head "); head != "(detached)" {
+				info.Branch = head
+			}
+		case strings.HasPrefix(line, "#"), line == "":
+		default:
+			info.Dirty = true
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,73 @@
+package gitinfo
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatus(t *testing.T) {
+	t.Parallel()
+
+	clean := parseStatus([]byte("# branch.oid 1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b\n# branch.head main\n# branch.upstream origin/main\n# branch.ab +0 -0\n"))
+	assert.Equal(t, Info{Commit: "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b", Branch: "main"}, clean)
+	assert.Equal(t, "main@1a2b3c4d5e6f", clean.String())
+
+	dirty := parseStatus([]byte("# branch.oid 1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b\n# branch.head (detached)\n1 .M N... 100644 100644 100644 aaa bbb Lobby.smw\n"))
+	assert.Equal(t, Info{Commit: "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b", Dirty: true}, dirty)
+	assert.Equal(t, "1a2b3c4d5e6f (dirty)", dirty.String())
+
+	initial := parseStatus([]byte("# branch.oid (initial)\n# branch.head main\n"))
+	assert.Equal(t, Info{Branch: "main"}, initial)
+	assert.Equal(t, "main@(no commits)", initial.String())
+}
+
+func TestLookupOutsideRepo(t *testing.T) {
+	t.Parallel()
+
+	_, ok := Lookup(t.TempDir(), func(string, ...string) ([]byte, error) {
+		return nil, errors.New("fatal: not a git repository")
+	})
+	assert.False(t, ok)
+}
+
+func TestLookupWithGit(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	git("init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Lobby.smw"), []byte("v1"), 0o644))
+	git("add", "Lobby.smw")
+	git("commit", "-q", "-m", "initial")
+
+	// Untracked outputs do not make the tree dirty
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Lobby.lpz"), []byte("out"), 0o644))
+
+	info, ok := Lookup(dir, RunGit)
+	require.True(t, ok)
+	assert.Len(t, info.Commit, 40)
+	assert.Equal(t, "main", info.Branch)
+	assert.False(t, info.Dirty)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Lobby.smw"), []byte("v2"), 0o644))
+
+	info, ok = Lookup(dir, RunGit)
+	require.True(t, ok)
+	assert.True(t, info.Dirty)
+}
@@ -0,0 +1,101 @@
+// Package guilock describes the machine-wide lock that lets only one smpc instance
+// drive SIMPL Windows at a time. The lock itself is a named mutex; the instance
+// holding it also records itself in a file, so an instance that finds the lock taken
+// can say who has it.
+package guilock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// MutexName is the named mutex shared by every session on the machine
+	MutexName = `Global\smpc-simpl-windows`
+
+	// FileName is the name of the holder file in the machine-wide smpc data directory
+	FileName = "gui-lock.json"
+)
+
+// Ways a holder was started
+const (
+	OriginCommandLine   = "command line"
+	OriginService       = "compile service"
+	OriginScheduledTask = "scheduled task"
+)
+
+// ErrLocked is returned when another instance kept the lock for longer than this one
+// was prepared to wait
+var ErrLocked = errors.New("SIMPL Windows is in use by another smpc instance")
+
+// Holder is the smpc instance holding the lock
+type Holder struct {
+	Pid     uint32    `json:"pid"`
+	User    string    `json:"user,omitempty"`
+	Origin  string    `json:"origin,omitempty"` // One of the Origin values
+	Program string    `json:"program"`          // What it is compiling
+	Since   time.Time `json:"since"`
+}
+
+// String describes the holder for log and error messages
+func (h Holder) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "PID %d", h.Pid)
+
+	if h.Origin != "" {
+		fmt.Fprintf(&b, " (%s)", h.Origin)
+	}
+
+	if h.User != "" {
+		fmt.Fprintf(&b, " run by %s", h.User)
+	}
+
+	fmt.Fprintf(&b, ", compiling %s since %s", h.Program, h.Since.Local().Format(time.DateTime))
+
+	return b.String()
+}
+
+// Write records h as the holder in the file at path
+func Write(path string, h Holder) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Read returns the holder recorded in the file at path
+func Read(path string) (Holder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Holder{}, err
+	}
+
+	var h Holder
+	if err := json.Unmarshal(data, &h); err != nil {
+		return Holder{}, fmt.Errorf("invalid lock holder file %s: %w", path, err)
+	}
+
+	return h, nil
+}
+
+// Clear removes the file at path if it still records pid as the holder
+func Clear(path string, pid uint32) error {
+	h, err := Read(path)
+	if err != nil || h.Pid != pid {
+		return nil
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,55 @@
+package guilock
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReadClear(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "smpc", FileName)
+
+	h := Holder{
+		Pid:     1234,
+		User:    `CORP\jo`,
+		Origin:  OriginScheduledTask,
+		Program: `C:\p\Lobby.smw`,
+		Since:   time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC),
+	}
+
+	require.NoError(t, Write(path, h))
+
+	got, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, h, got)
+
+	require.NoError(t, Clear(path, 99))
+	assert.FileExists(t, path, "a newer holder's record is left alone")
+
+	require.NoError(t, Clear(path, 1234))
+	assert.NoFileExists(t, path)
+
+	_, err = Read(path)
+	assert.Error(t, err)
+}
+
+func TestHolderString(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2025, 1, 1, 9, 30, 0, 0, time.Local)
+
+	assert.Equal(t,
+		`PID 1234 (compile service) run by CORP\jo, compiling C:\p\Lobby.smw since 2025-01-01 09:30:00`,
+		Holder{Pid: 1234, User: `CORP\jo`, Origin: OriginService, Program: `C:\p\Lobby.smw`, Since: since}.String(),
+	)
+
+	assert.Equal(t,
+		`PID 7, compiling C:\p\Lobby.smw since 2025-01-01 09:30:00`,
+		Holder{Pid: 7, Program: `C:\p\Lobby.smw`, Since: since}.String(),
+	)
+}
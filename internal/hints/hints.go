@@ -0,0 +1,118 @@
+// Package hints annotates compiler messages with explanations and suggested fixes
+// from a knowledge base of common SIMPL Windows and SIMPL+ errors and warnings.
+package hints
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+//go:embed builtin.json
+var builtin []byte
+
+// Hint explains the messages matching Pattern
+type Hint struct {
+	Pattern     string `json:"pattern"`       // Regular expression matched against the message
+	Explanation string `json:"explanation"`   // What the message means
+	Fix         string `json:"fix,omitempty"` // What usually resolves it
+
+	pattern *regexp.Regexp
+}
+
+// String formats the hint as appended to a message, e.g. "hint: ...; fix: ..."
+func (h Hint) String() string {
+	if h.Fix == "" {
+		return "hint: " + h.Explanation
+	}
+
+	return "hint: " + h.Explanation + "; fix: " + h.Fix
+}
+
+// KB is a knowledge base of hints. The first hint matching a message wins.
+type KB struct {
+	Hints []Hint `json:"hints"`
+}
+
+// Builtin returns the knowledge base shipped with smpc
+func Builtin() *KB {
+	kb, err := Parse(builtin)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in hints: %v", err))
+	}
+
+	return kb
+}
+
+// Load reads and validates the knowledge base file at path
+func Load(path string) (*KB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hints: %w", err)
+	}
+
+	kb, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hints %s: %w", path, err)
+	}
+
+	return kb, nil
+}
+
+// Parse decodes and validates a knowledge base from JSON
+func Parse(data []byte) (*KB, error) {
+	var kb KB
+	if err := json.Unmarshal(data, &kb); err != nil {
+		return nil, err
+	}
+
+	for i := range kb.Hints {
+		h := &kb.Hints[i]
+
+		if h.Pattern == "" || h.Explanation == "" {
+			return nil, fmt.Errorf("hints[%d]: pattern and explanation are required", i)
+		}
+
+		pattern, err := regexp.Compile(h.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("hints[%d]: invalid pattern: %w", i, err)
+		}
+
+		h.pattern = pattern
+	}
+
+	return &kb, nil
+}
+
+// Extend returns a knowledge base with the hints of other ahead of kb's, so they take
+// precedence. Either may be nil.
+func (kb *KB) Extend(other *KB) *KB {
+	merged := &KB{}
+
+	if other != nil {
+		merged.Hints = append(merged.Hints, other.Hints...)
+	}
+
+	if kb != nil {
+		merged.Hints = append(merged.Hints, kb.Hints...)
+	}
+
+	return merged
+}
+
+// Lookup returns the first hint matching msg. It is safe to call on a nil KB.
+func (kb *KB) Lookup(msg string) (Hint, bool) {
+	if kb == nil {
+		return Hint{}, false
+	}
+
+	for _, h := range kb.Hints {
+		if h.pattern.MatchString(msg) {
+			return h, true
+		}
+	}
+
+	return Hint{}, false
+}
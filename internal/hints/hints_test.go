@@ -0,0 +1,73 @@
+package hints
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltin(t *testing.T) {
+	t.Parallel()
+
+	kb := Builtin()
+	require.NotEmpty(t, kb.Hints)
+
+	for msg, want := range map[string]string{
+		"WARNING    (LGCMCVT102) ** Signal foo has no driving source": "no symbol drives it",
+		"NOTICE     (LGCMCVT103) ** Signal baz has no destination":    "nothing uses it",
+		"ERROR      (LGSPLS1700) Line 5: Undefined symbol 'foo'":      "not declared",
+		"ERROR      (LGCMCVT247) Line 15: Type mismatch":              "incompatible types",
+		"ERROR      (LGCMCVT101) Line 25: Missing semicolon":          "not terminated",
+		"ERROR Signal database has changed, use Recompile All":        "--recompile-all",
+		"ERROR Signal [out] has more than one driving source":         "more than one output",
+		"ERROR User module Volume.umc could not be found":             "smpc precheck",
+	} {
+		h, ok := kb.Lookup(msg)
+		if assert.True(t, ok, msg) {
+			assert.Contains(t, h.String(), want, msg)
+		}
+	}
+
+	_, ok := kb.Lookup("Program Warnings: 1")
+	assert.False(t, ok)
+}
+
+func TestExtend(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "hints.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"hints":[{"pattern":"no driving source","explanation":"site rule"}]}`), 0o644))
+
+	site, err := Load(path)
+	require.NoError(t, err)
+
+	kb := Builtin().Extend(site)
+
+	h, ok := kb.Lookup("Signal foo has no driving source")
+	require.True(t, ok)
+	assert.Equal(t, "hint: site rule", h.String(), "extension hints take precedence")
+
+	_, ok = kb.Lookup("Type mismatch")
+	assert.True(t, ok, "built-in hints still apply")
+
+	var none *KB
+	_, ok = none.Lookup("Type mismatch")
+	assert.False(t, ok)
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Parallel()
+
+	for name, input := range map[string]string{
+		"json":        `{`,
+		"no pattern":  `{"hints":[{"explanation":"x"}]}`,
+		"no text":     `{"hints":[{"pattern":"x"}]}`,
+		"bad pattern": `{"hints":[{"pattern":"(","explanation":"x"}]}`,
+	} {
+		_, err := Parse([]byte(input))
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,126 @@
+// Package history records the outcome of every compile in a local append-only file
+// and summarizes it into trend reports.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileName is the name of the history file in the smpc data directory
+const FileName = "history.jsonl"
+
+// Entry is one recorded compile
+type Entry struct {
+	Time     time.Time `json:"time"`
+	File     string    `json:"file"`
+	Status   string    `json:"status"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+	Notices  int       `json:"notices"`
+	Seconds  float64   `json:"seconds"`
+	Message  string    `json:"message,omitempty"`
+	Commit   string    `json:"commit,omitempty"` // Git commit the program was compiled from
+}
+
+// Duration returns how long the compile took
+func (e Entry) Duration() time.Duration {
+	return time.Duration(e.Seconds * float64(time.Second))
+}
+
+// Append adds entries to the history file at path, creating it if needed
+func Append(path string, entries ...Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+
+	defer f.Close()
+
+	// One write per batch keeps lines whole when several instances append at once
+	var buf strings.Builder
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if _, err := f.WriteString(buf.String()); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the entries recorded at or after since. A missing file is an empty
+// history; unreadable lines, e.g. from an interrupted write, are skipped.
+func Load(path string, since time.Time) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+
+	defer f.Close()
+
+	var entries []Entry
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+
+		if !e.Time.Before(since) {
+			entries = append(entries, e)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ParseSince parses a look-back period such as "30d", "2w" or "12h"
+func ParseSince(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count <= 0 {
+				return 0, fmt.Errorf("invalid period %q", s)
+			}
+
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q (use e.g. 30d, 2w or 12h)", s)
+	}
+
+	return d, nil
+}
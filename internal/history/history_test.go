@@ -0,0 +1,70 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", FileName)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, Append(path, Entry{Time: now.Add(-48 * time.Hour), File: "old.smw", Status: "passed"}))
+	require.NoError(t, Append(path,
+		Entry{Time: now, File: "a.smw", Status: "passed", Warnings: 3, Seconds: 1.5},
+		Entry{Time: now, File: "b.smw", Status: "failed", Message: "boom"},
+	))
+
+	entries, err := Load(path, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a.smw", entries[0].File)
+	assert.Equal(t, 1500*time.Millisecond, entries[0].Duration())
+	assert.Equal(t, "boom", entries[1].Message)
+}
+
+func TestLoadSkipsMalformedLines(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte("{\"file\":\"a.smw\",\"time\":\"2025-01-01T00:00:00Z\"}\n{\"file\":\"trunc\n"), 0o644))
+
+	entries, err := Load(path, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "a.smw", entries[0].File)
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Parallel()
+
+	entries, err := Load(filepath.Join(t.TempDir(), FileName), time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestParseSince(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	} {
+		got, err := ParseSince(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "d", "-3d", "0d", "soon"} {
+		_, err := ParseSince(in)
+		assert.Error(t, err, in)
+	}
+}
@@ -0,0 +1,138 @@
+package history
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// Report formats
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Write renders report in format
+func Write(w io.Writer, report Report, format string) error {
+	switch format {
+	case FormatMarkdown:
+		return WriteMarkdown(w, report)
+	case FormatHTML:
+		return WriteHTML(w, report)
+	default:
+		return fmt.Errorf("unsupported report format %q (supported: %s, %s)", format, FormatMarkdown, FormatHTML)
+	}
+}
+
+// WriteMarkdown renders report as Markdown tables
+func WriteMarkdown(w io.Writer, report Report) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Compile History\n\n%s to %s\n\n", report.Since.Format(time.DateOnly), report.Generated.Format(time.DateOnly))
+
+	if len(report.Programs) == 0 {
+		b.WriteString("No compiles recorded in this period.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	b.WriteString("| Program | Runs | Failure rate | Avg duration | Max duration | Warnings | Last result |\n")
+	b.WriteString("| --- | ---: | ---: | ---: | ---: | --- | --- |\n")
+
+	for _, t := range report.Programs {
+		fmt.Fprintf(&b, "| %s | %d | %s | %s | %s | %s | %s |\n",
+			markdownEscape(t.File), t.Runs, percent(t.FailureRate()), seconds(t.AvgDuration), seconds(t.MaxDuration),
+			warningTrend(t), t.LastStatus)
+	}
+
+	for _, t := range report.Programs {
+		fmt.Fprintf(&b, "\n## %s\n\n", markdownEscape(t.File))
+		b.WriteString("| Date | Runs | Failures | Avg duration | Avg warnings |\n")
+		b.WriteString("| --- | ---: | ---: | ---: | ---: |\n")
+
+		for _, d := range t.Days {
+			fmt.Fprintf(&b, "| %s | %d | %d | %s | %.1f |\n",
+				d.Date.Format(time.DateOnly), d.Runs, d.Failures, seconds(d.AvgDuration), d.AvgWarnings)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var htmlTemplate = template.Must(template.New("history").Funcs(template.FuncMap{
+	"date":     func(t time.Time) string { return t.Format(time.DateOnly) },
+	"percent":  percent,
+	"seconds":  seconds,
+	"warnings": warningTrend,
+	"bar": func(d, max time.Duration) string {
+		if max <= 0 {
+			return "0"
+		}
+
+		return fmt.Sprintf("%.0f", float64(d)/float64(max)*100)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Compile History</title>
+<style>
+body { font-family: Segoe UI, sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.bar { background: #4a90d9; height: 10px; }
+.failed { color: #c0392b; }
+</style>
+</head>
+<body>
+<h1>Compile History</h1>
+<p>{{date .Since}} to {{date .Generated}}</p>
+{{if not .Programs}}<p>No compiles recorded in this period.</p>{{else}}
+<table>
+<tr><th>Program</th><th>Runs</th><th>Failure rate</th><th>Avg duration</th><th>Max duration</th><th>Warnings</th><th>Last result</th></tr>
+{{range .Programs}}<tr><td>{{.File}}</td><td>{{.Runs}}</td><td>{{percent .FailureRate}}</td><td>{{seconds .AvgDuration}}</td><td>{{seconds .MaxDuration}}</td><td>{{warnings .}}</td><td{{if ne .LastStatus "passed"}} class="failed"{{end}}>{{.LastStatus}}</td></tr>
+{{end}}</table>
+{{range .Programs}}{{$max := .MaxDuration}}
+<h2>{{.File}}</h2>
+<table>
+<tr><th>Date</th><th>Runs</th><th>Failures</th><th>Avg duration</th><th></th><th>Avg warnings</th></tr>
+{{range .Days}}<tr><td>{{date .Date}}</td><td>{{.Runs}}</td><td{{if .Failures}} class="failed"{{end}}>{{.Failures}}</td><td>{{seconds .AvgDuration}}</td><td style="width:200px"><div class="bar" style="width:{{bar .AvgDuration $max}}%"></div></td><td>{{printf "%.1f" .AvgWarnings}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML renders report as a standalone HTML page
+func WriteHTML(w io.Writer, report Report) error {
+	return htmlTemplate.Execute(w, report)
+}
+
+func percent(f float64) string {
+	return fmt.Sprintf("%.0f%%", f*100)
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// warningTrend describes the warning count at the start and end of the period
+func warningTrend(t Trend) string {
+	switch change := t.WarningChange(); {
+	case change > 0:
+		return fmt.Sprintf("%d → %d (+%d)", t.FirstWarnings, t.LastWarnings, change)
+	case change < 0:
+		return fmt.Sprintf("%d → %d (%d)", t.FirstWarnings, t.LastWarnings, change)
+	default:
+		return fmt.Sprintf("%d", t.LastWarnings)
+	}
+}
+
+// markdownEscape keeps table cells intact
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,121 @@
+package history
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// statusPassed matches report.StatusPassed
+const statusPassed = "passed"
+
+// Day summarizes one program's compiles on one (UTC) day
+type Day struct {
+	Date        time.Time
+	Runs        int
+	Failures    int
+	AvgDuration time.Duration
+	AvgWarnings float64
+}
+
+// Trend summarizes one program's compiles over the report period
+type Trend struct {
+	File          string
+	Runs          int
+	Failures      int
+	AvgDuration   time.Duration
+	MaxDuration   time.Duration
+	FirstWarnings int // Warnings in the earliest compile of the period
+	LastWarnings  int // Warnings in the latest compile of the period
+	LastStatus    string
+	LastRun       time.Time
+	Days          []Day // Oldest first
+}
+
+// FailureRate returns the fraction of compiles that failed
+func (t Trend) FailureRate() float64 {
+	if t.Runs == 0 {
+		return 0
+	}
+
+	return float64(t.Failures) / float64(t.Runs)
+}
+
+// WarningChange returns how the warning count moved over the period
+func (t Trend) WarningChange() int {
+	return t.LastWarnings - t.FirstWarnings
+}
+
+// Report is a trend report over a period
+type Report struct {
+	Since     time.Time
+	Generated time.Time
+	Programs  []Trend // Ordered by file name
+}
+
+// Summarize builds a report from entries. Paths are grouped case-insensitively, as on
+// Windows.
+func Summarize(entries []Entry, since, now time.Time) Report {
+	groups := make(map[string][]Entry)
+
+	for _, e := range entries {
+		key := strings.ToLower(filepath.Clean(e.File))
+		groups[key] = append(groups[key], e)
+	}
+
+	report := Report{Since: since, Generated: now}
+
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Time.Before(group[j].Time) })
+		report.Programs = append(report.Programs, trend(group))
+	}
+
+	sort.Slice(report.Programs, func(i, j int) bool {
+		return strings.ToLower(report.Programs[i].File) < strings.ToLower(report.Programs[j].File)
+	})
+
+	return report
+}
+
+// trend summarizes one program's entries, which must be in time order
+func trend(entries []Entry) Trend {
+	first, last := entries[0], entries[len(entries)-1]
+
+	t := Trend{
+		File:          last.File,
+		Runs:          len(entries),
+		FirstWarnings: first.Warnings,
+		LastWarnings:  last.Warnings,
+		LastStatus:    last.Status,
+		LastRun:       last.Time,
+	}
+
+	var total time.Duration
+
+	for _, e := range entries {
+		total += e.Duration()
+		t.MaxDuration = max(t.MaxDuration, e.Duration())
+
+		if e.Status != statusPassed {
+			t.Failures++
+		}
+
+		date := e.Time.UTC().Truncate(24 * time.Hour)
+		if len(t.Days) == 0 || !t.Days[len(t.Days)-1].Date.Equal(date) {
+			t.Days = append(t.Days, Day{Date: date})
+		}
+
+		day := &t.Days[len(t.Days)-1]
+		day.AvgDuration = (day.AvgDuration*time.Duration(day.Runs) + e.Duration()) / time.Duration(day.Runs+1)
+		day.AvgWarnings = (day.AvgWarnings*float64(day.Runs) + float64(e.Warnings)) / float64(day.Runs+1)
+		day.Runs++
+
+		if e.Status != statusPassed {
+			day.Failures++
+		}
+	}
+
+	t.AvgDuration = total / time.Duration(len(entries))
+	return t
+}
@@ -0,0 +1,83 @@
+package history
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleReport() Report {
+	day := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	entries := []Entry{
+		{Time: day.Add(24 * time.Hour), File: `C:\jobs\Lobby.smw`, Status: "failed", Warnings: 4, Seconds: 30},
+		{Time: day, File: `C:\jobs\lobby.smw`, Status: "passed", Warnings: 2, Seconds: 10},
+		{Time: day.Add(time.Hour), File: `C:\jobs\Lobby.smw`, Status: "passed", Warnings: 2, Seconds: 20},
+		{Time: day, File: `C:\jobs\Boardroom.smw`, Status: "passed", Warnings: 1, Seconds: 5},
+	}
+
+	return Summarize(entries, day.Add(-24*time.Hour), day.Add(48*time.Hour))
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	report := sampleReport()
+	require.Len(t, report.Programs, 2)
+
+	boardroom, lobby := report.Programs[0], report.Programs[1]
+	assert.Equal(t, `C:\jobs\Boardroom.smw`, boardroom.File)
+	assert.Equal(t, 1, boardroom.Runs)
+	assert.Zero(t, boardroom.FailureRate())
+
+	assert.Equal(t, `C:\jobs\Lobby.smw`, lobby.File)
+	assert.Equal(t, 3, lobby.Runs)
+	assert.Equal(t, 1, lobby.Failures)
+	assert.InDelta(t, 1.0/3, lobby.FailureRate(), 0.001)
+	assert.Equal(t, 20*time.Second, lobby.AvgDuration)
+	assert.Equal(t, 30*time.Second, lobby.MaxDuration)
+	assert.Equal(t, 2, lobby.WarningChange())
+	assert.Equal(t, "failed", lobby.LastStatus)
+
+	require.Len(t, lobby.Days, 2)
+	assert.Equal(t, 2, lobby.Days[0].Runs)
+	assert.Equal(t, 15*time.Second, lobby.Days[0].AvgDuration)
+	assert.Equal(t, 1, lobby.Days[1].Failures)
+	assert.InDelta(t, 4.0, lobby.Days[1].AvgWarnings, 0.001)
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, sampleReport(), FormatMarkdown))
+
+	out := buf.String()
+	assert.Contains(t, out, "2025-02-28 to 2025-03-03")
+	assert.Contains(t, out, `| C:\jobs\Lobby.smw | 3 | 33% | 20.0s | 30.0s | 2 → 4 (+2) | failed |`)
+	assert.Contains(t, out, "| 2025-03-01 | 2 | 0 | 15.0s | 2.0 |")
+}
+
+func TestWriteHTML(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, Report{Programs: []Trend{{File: "<script>.smw", Runs: 1, LastStatus: "passed"}}}, FormatHTML))
+
+	out := buf.String()
+	assert.Contains(t, out, "&lt;script&gt;.smw")
+	assert.NotContains(t, out, "<script>")
+}
+
+func TestWriteEmptyAndUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, Report{}))
+	assert.Contains(t, buf.String(), "No compiles recorded")
+
+	assert.Error(t, Write(&buf, Report{}, "pdf"))
+}
@@ -0,0 +1,109 @@
+// Package i18n translates smpc's own console messages. Messages are looked up by
+// their English text, so anything without a translation is shown in English.
+// SIMPL Windows' messages and the log file are never translated.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// English is the language of the messages in the source, which needs no catalog
+const English = "en"
+
+//go:embed catalogs/*.json
+var catalogFiles embed.FS
+
+// catalogs maps each language to its translations, keyed by English message
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	entries, err := catalogFiles.ReadDir("catalogs")
+	if err != nil {
+		panic(err)
+	}
+
+	loaded := make(map[string]map[string]string, len(entries))
+
+	for _, e := range entries {
+		data, err := catalogFiles.ReadFile("catalogs/" + e.Name())
+		if err != nil {
+			panic(err)
+		}
+
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("invalid catalog %s: %v", e.Name(), err))
+		}
+
+		loaded[strings.TrimSuffix(e.Name(), ".json")] = catalog
+	}
+
+	return loaded
+}
+
+// Languages returns the supported languages, sorted
+func Languages() []string {
+	langs := []string{English}
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+
+	sort.Strings(langs)
+	return langs
+}
+
+// LocaleEnv are the environment variables the console language is taken from, in order
+var LocaleEnv = []string{"LC_ALL", "LC_MESSAGES", "LANG"}
+
+// Resolve picks the console language: flag if set, which must be supported, otherwise
+// the first of LC_ALL, LC_MESSAGES and LANG that is set, falling back to English when
+// that language is not supported. Values such as "de_DE.UTF-8" select "de".
+func Resolve(flag string, getenv func(string) string) (string, error) {
+	if flag != "" {
+		lang := normalize(flag)
+		if !supported(lang) {
+			return "", fmt.Errorf("unsupported language %q (supported: %s)", flag, strings.Join(Languages(), ", "))
+		}
+
+		return lang, nil
+	}
+
+	for _, name := range LocaleEnv {
+		if v := getenv(name); v != "" {
+			if lang := normalize(v); supported(lang) {
+				return lang, nil
+			}
+
+			return English, nil
+		}
+	}
+
+	return English, nil
+}
+
+// normalize reduces a locale such as "fr_CA.UTF-8" or "de-AT" to its language
+func normalize(locale string) string {
+	lang, _, _ := strings.Cut(locale, ".")
+	lang, _, _ = strings.Cut(lang, "_")
+	lang, _, _ = strings.Cut(lang, "-")
+
+	return strings.ToLower(strings.TrimSpace(lang))
+}
+
+func supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok || lang == English
+}
+
+// Translate returns msg in lang, or msg unchanged when there is no translation
+func Translate(lang, msg string) string {
+	if t, ok := catalogs[lang][msg]; ok {
+		return t
+	}
+
+	return msg
+}
@@ -0,0 +1,63 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguages(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"de", "en", "fr"}, Languages())
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	t.Parallel()
+
+	for lang, catalog := range catalogs {
+		for other, otherCatalog := range catalogs {
+			for msg := range otherCatalog {
+				assert.Contains(t, catalog, msg, "%s has a translation %s lacks", other, lang)
+			}
+		}
+
+		for msg, translated := range catalog {
+			assert.NotEmpty(t, translated, "%s: %q", lang, msg)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string { return vars[name] }
+	}
+
+	lang, err := Resolve("DE", env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "de", lang)
+
+	_, err = Resolve("xx", env(nil))
+	assert.ErrorContains(t, err, "supported: de, en, fr")
+
+	lang, _ = Resolve("", env(map[string]string{"LANG": "fr_CA.UTF-8"}))
+	assert.Equal(t, "fr", lang)
+
+	lang, _ = Resolve("", env(map[string]string{"LC_ALL": "C", "LANG": "de_DE.UTF-8"}))
+	assert.Equal(t, English, lang, "LC_ALL takes precedence even when unsupported")
+
+	lang, _ = Resolve("", env(nil))
+	assert.Equal(t, English, lang)
+}
+
+func TestTranslate(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Kompilierung abgeschlossen", Translate("de", "Compilation complete"))
+	assert.Equal(t, "Compilation terminée", Translate("fr", "Compilation complete"))
+	assert.Equal(t, "Compilation complete", Translate(English, "Compilation complete"))
+	assert.Equal(t, "Something new", Translate("de", "Something new"))
+}
@@ -4,6 +4,7 @@ package interfaces
 import (
 	"time"
 
+	"github.com/Norgate-AV/smpc/internal/keychord"
 	"github.com/Norgate-AV/smpc/internal/windows"
 )
 
@@ -15,6 +16,10 @@ type WindowManager interface {
 	IsElevated() bool
 	CollectChildInfos(hwnd uintptr) []windows.ChildInfo
 	WaitOnMonitor(timeout time.Duration, matchers ...func(windows.WindowEvent) bool) (windows.WindowEvent, bool)
+	InvokeMenuItem(hwnd uintptr, path ...string) bool
+	SendMessage(hwnd uintptr, msg uint32, wParam, lParam uintptr) (uintptr, bool)
+	PostMessage(hwnd uintptr, msg uint32, wParam, lParam uintptr) bool
+	ClickButtonById(parentHwnd uintptr, id int) bool
 }
 
 // KeyboardInjector handles keyboard input
@@ -26,12 +31,28 @@ type KeyboardInjector interface {
 	SendAltF12ToWindow(hwnd uintptr) bool
 	SendF12WithSendInput() bool
 	SendAltF12WithSendInput() bool
+	SendChordWithSendInput(chord keychord.Chord) bool
+	SendChord(chord keychord.Chord)
+}
+
+// DDEClient sends commands to DDE servers
+type DDEClient interface {
+	DDEExecute(service, topic, command string) error
 }
 
 // ProcessManager handles SIMPL process operations
 type ProcessManager interface {
 	FindWindow(targetPid uint32, debug bool) (uintptr, string)
 	WaitForReady(hwnd uintptr, timeout time.Duration) bool
+	Process(pid uint32) (windows.ProcessEntry, error)
+	Processes(match func(windows.ProcessEntry) bool) ([]windows.ProcessEntry, error)
+	Terminate(p windows.ProcessEntry) error
+}
+
+// EventSource provides subscriptions to window events published by a background monitor
+type EventSource interface {
+	Subscribe(buffer int) *windows.Subscription
+	SubscribeWithHistory(buffer int) *windows.Subscription
 }
 
 // ControlReader reads window controls
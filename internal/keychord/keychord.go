@@ -0,0 +1,192 @@
+// Package keychord parses key chords such as "ctrl+f12" into Windows
+// virtual-key codes for the keyboard injector.
+package keychord
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Virtual-key codes for modifiers
+const (
+	VKShift   uint16 = 0x10
+	VKControl uint16 = 0x11
+	VKMenu    uint16 = 0x12 // Alt
+	VKLWin    uint16 = 0x5B
+)
+
+// modifiers maps modifier names to virtual-key codes
+var modifiers = map[string]uint16{
+	"ctrl":    VKControl,
+	"control": VKControl,
+	"alt":     VKMenu,
+	"shift":   VKShift,
+	"win":     VKLWin,
+}
+
+// namedKeys maps non-alphanumeric key names to virtual-key codes
+var namedKeys = map[string]uint16{
+	"enter":    0x0D,
+	"return":   0x0D,
+	"tab":      0x09,
+	"esc":      0x1B,
+	"escape":   0x1B,
+	"space":    0x20,
+	"pageup":   0x21,
+	"pagedown": 0x22,
+	"end":      0x23,
+	"home":     0x24,
+	"left":     0x25,
+	"up":       0x26,
+	"right":    0x27,
+	"down":     0x28,
+	"insert":   0x2D,
+	"delete":   0x2E,
+}
+
+// scanCodes maps layout-independent keys to their set 1 scan codes, with 0xE0 in the
+// high byte for extended keys. Letters and digits are left out: their physical
+// position differs between layouts, so they are sent by virtual-key code.
+var scanCodes = map[uint16]uint16{
+	VKShift:   0x2A,
+	VKControl: 0x1D,
+	VKMenu:    0x38,
+	VKLWin:    0xE05B,
+
+	0x0D: 0x1C, // Enter
+	0x09: 0x0F, // Tab
+	0x1B: 0x01, // Esc
+	0x20: 0x39, // Space
+
+	0x21: 0xE049, // Page Up
+	0x22: 0xE051, // Page Down
+	0x23: 0xE04F, // End
+	0x24: 0xE047, // Home
+	0x25: 0xE04B, // Left
+	0x26: 0xE048, // Up
+	0x27: 0xE04D, // Right
+	0x28: 0xE050, // Down
+	0x2D: 0xE052, // Insert
+	0x2E: 0xE053, // Delete
+}
+
+func init() {
+	// F1-F10 are contiguous, F11-F12 and F13-F23 form their own runs, and F24 stands alone
+	for n := uint16(0); n < 10; n++ {
+		scanCodes[0x70+n] = 0x3B + n
+	}
+
+	scanCodes[0x7A] = 0x57
+	scanCodes[0x7B] = 0x58
+
+	for n := uint16(0); n < 11; n++ {
+		scanCodes[0x7C+n] = 0x64 + n
+	}
+
+	scanCodes[0x87] = 0x76
+}
+
+// Chord is a key pressed while holding zero or more modifiers
+type Chord struct {
+	Modifiers []uint16 // Pressed in order, released in reverse
+	Key       uint16
+	name      string
+}
+
+// Parse parses a chord such as "f12", "alt+f12" or "ctrl+shift+b" (case-insensitive)
+func Parse(s string) (Chord, error) {
+	parts := strings.Split(strings.ToLower(s), "+")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	if parts[len(parts)-1] == "" {
+		return Chord{}, fmt.Errorf("invalid key chord %q: missing key", s)
+	}
+
+	chord := Chord{name: strings.Join(parts, "+")}
+	seen := make(map[uint16]bool)
+
+	for _, p := range parts[:len(parts)-1] {
+		vk, ok := modifiers[p]
+		if !ok {
+			return Chord{}, fmt.Errorf("invalid key chord %q: unknown modifier %q", s, p)
+		}
+
+		if seen[vk] {
+			return Chord{}, fmt.Errorf("invalid key chord %q: duplicate modifier %q", s, p)
+		}
+
+		seen[vk] = true
+		chord.Modifiers = append(chord.Modifiers, vk)
+	}
+
+	key, err := keyCode(parts[len(parts)-1])
+	if err != nil {
+		return Chord{}, fmt.Errorf("invalid key chord %q: %w", s, err)
+	}
+
+	chord.Key = key
+	return chord, nil
+}
+
+// MustParse is Parse for chords known to be valid at compile time
+func MustParse(s string) Chord {
+	c, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// IsZero reports whether the chord is unset
+func (c Chord) IsZero() bool {
+	return c.Key == 0
+}
+
+// String returns the normalized chord text, e.g. "ctrl+f12"
+func (c Chord) String() string {
+	return c.name
+}
+
+// IsExtended reports whether vk must be sent with KEYEVENTF_EXTENDEDKEY
+func IsExtended(vk uint16) bool {
+	return scanCodes[vk]&0xFF00 == 0xE000
+}
+
+// ScanCode returns the scan code for vk without the extended prefix, which is the same
+// whatever keyboard layout is active. It returns false for keys that depend on the layout.
+func ScanCode(vk uint16) (uint16, bool) {
+	code, ok := scanCodes[vk]
+	return code & 0xFF, ok
+}
+
+// keyCode resolves a single key name to its virtual-key code
+func keyCode(name string) (uint16, error) {
+	if vk, ok := namedKeys[name]; ok {
+		return vk, nil
+	}
+
+	// Function keys F1-F24 are contiguous from VK_F1 (0x70)
+	if len(name) >= 2 && name[0] == 'f' {
+		var n int
+		if _, err := fmt.Sscanf(name[1:], "%d", &n); err == nil && fmt.Sprint(n) == name[1:] && n >= 1 && n <= 24 {
+			return uint16(0x70 + n - 1), nil
+		}
+	}
+
+	// Letters and digits map to their ASCII upper-case codes
+	if len(name) == 1 {
+		ch := name[0]
+
+		switch {
+		case ch >= 'a' && ch <= 'z':
+			return uint16(ch - 'a' + 'A'), nil
+		case ch >= '0' && ch <= '9':
+			return uint16(ch), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown key %q", name)
+}
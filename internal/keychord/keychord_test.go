@@ -0,0 +1,90 @@
+package keychord
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input     string
+		modifiers []uint16
+		key       uint16
+		name      string
+	}{
+		{input: "f12", key: 0x7B, name: "f12"},
+		{input: "Alt+F12", modifiers: []uint16{VKMenu}, key: 0x7B, name: "alt+f12"},
+		{input: "ctrl+f12", modifiers: []uint16{VKControl}, key: 0x7B, name: "ctrl+f12"},
+		{input: " ctrl + shift + b ", modifiers: []uint16{VKControl, VKShift}, key: 'B', name: "ctrl+shift+b"},
+		{input: "f1", key: 0x70, name: "f1"},
+		{input: "win+9", modifiers: []uint16{VKLWin}, key: '9', name: "win+9"},
+		{input: "alt+enter", modifiers: []uint16{VKMenu}, key: 0x0D, name: "alt+enter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			chord, err := Parse(tt.input)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.modifiers, chord.Modifiers)
+			assert.Equal(t, tt.key, chord.Key)
+			assert.Equal(t, tt.name, chord.String())
+			assert.False(t, chord.IsZero())
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"", "ctrl+", "hyper+f12", "ctrl+ctrl+f12", "f25", "f0", "f012", "ctrl+alt"} {
+		_, err := Parse(input)
+		assert.Error(t, err, "Parse(%q) should fail", input)
+	}
+}
+
+func TestIsExtended(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsExtended(MustParse("delete").Key))
+	assert.False(t, IsExtended(MustParse("f12").Key))
+	assert.False(t, IsExtended(VKMenu), "left Alt is not extended; right Alt is AltGr on some layouts")
+}
+
+func TestScanCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key  string
+		code uint16
+	}{
+		{"f1", 0x3B},
+		{"f10", 0x44},
+		{"f11", 0x57},
+		{"f12", 0x58},
+		{"f13", 0x64},
+		{"f23", 0x6E},
+		{"f24", 0x76},
+		{"enter", 0x1C},
+		{"delete", 0x53},
+	}
+
+	for _, tt := range tests {
+		code, ok := ScanCode(MustParse(tt.key).Key)
+		assert.True(t, ok, tt.key)
+		assert.Equal(t, tt.code, code, tt.key)
+	}
+
+	code, ok := ScanCode(VKMenu)
+	assert.True(t, ok)
+	assert.Equal(t, uint16(0x38), code)
+
+	_, ok = ScanCode(MustParse("a").Key)
+	assert.False(t, ok, "letters depend on the keyboard layout")
+}
@@ -8,9 +8,13 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/fatih/color"
 	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/Norgate-AV/smpc/internal/i18n"
+	"github.com/Norgate-AV/smpc/internal/redact"
 )
 
 const (
@@ -23,13 +27,43 @@ const (
 	// DefaultLogMaxAge is the default maximum number of days to retain old log files
 	DefaultLogMaxAge = 28
 
-	// LevelTrace is a custom log level below Debug, only logged to file
+	// LevelDetail is a custom log level below Debug for window monitor detail
+	LevelDetail = slog.LevelDebug - 2
+
+	// LevelTrace is a custom log level below Detail for Win32 call tracing and
+	// window/child enumeration; always logged to file, console only at VerbosityTrace
 	LevelTrace = slog.LevelDebug - 4
 )
 
+// Console verbosity levels, selected with -v, -vv and -vvv
+const (
+	VerbosityNormal = 0 // Info and above
+	VerbosityDebug  = 1 // + Debug
+	VerbosityDetail = 2 // + window monitor detail
+	VerbosityTrace  = 3 // + Win32 call tracing and child enumeration
+)
+
+// Console timestamp modes, selected with --timestamps
+const (
+	TimestampsNone     = ""        // No prefix
+	TimestampsElapsed  = "elapsed" // Time since the run started
+	TimestampsAbsolute = "abs"     // Time of day, then time since the run started
+)
+
+// ValidateTimestamps returns an error if mode is not a supported console timestamp mode
+func ValidateTimestamps(mode string) error {
+	switch mode {
+	case TimestampsNone, TimestampsElapsed, TimestampsAbsolute:
+		return nil
+	default:
+		return fmt.Errorf("unsupported timestamps %q (supported: %s, %s)", mode, TimestampsElapsed, TimestampsAbsolute)
+	}
+}
+
 // LoggerInterface defines the logging methods
 type LoggerInterface interface {
-	Trace(msg string, args ...any) // Only logs to file, never to console
+	Trace(msg string, args ...any)  // Console only at VerbosityTrace
+	Detail(msg string, args ...any) // Console only at VerbosityDetail and above
 	Debug(msg string, args ...any)
 	Info(msg string, args ...any)
 	Warn(msg string, args ...any)
@@ -40,12 +74,19 @@ type LoggerInterface interface {
 
 // LoggerOptions configures the logger
 type LoggerOptions struct {
-	Verbose    bool
+	Verbose    bool   // Equivalent to Verbosity = VerbosityDebug
+	Verbosity  int    // Console verbosity (VerbosityNormal..VerbosityTrace)
 	LogDir     string // If empty, uses %LOCALAPPDATA%\smpc
-	MaxSize    int    // Max size in megabytes before rotation (default: 10)
+	MaxSize    int    // Max size in megabytes before rotation (default: 2)
 	MaxBackups int    // Max number of old log files to keep (default: 3)
 	MaxAge     int    // Max days to keep old log files (default: 28)
 	Compress   bool   // Whether to compress rotated logs (default: true)
+
+	ConsoleWriter io.Writer        // Console output destination (default: os.Stdout)
+	Redactor      *redact.Redactor // Strips user names and paths from file and console output (nil = off)
+	Lang          string           // Console language (see i18n.Resolve; "" = English); the file stays in English
+	Timestamps    string           // Console line prefix (TimestampsNone, TimestampsElapsed or TimestampsAbsolute)
+	Start         time.Time        // When the run started, for elapsed timestamps (zero = when the logger is created)
 }
 
 // GetLogPath returns the path where logs will be written based on options
@@ -135,18 +176,45 @@ func NewLogger(opts LoggerOptions) (*Logger, error) {
 	fileLogger := slog.New(slog.NewTextHandler(lumberjackLogger, &slog.HandlerOptions{
 		Level: LevelTrace, // Set to LevelTrace to capture all levels including Trace
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
-			// Replace "DEBUG-4" with "TRACE" in the level attribute
-			if a.Key == slog.LevelKey && a.Value.Any().(slog.Level) == LevelTrace {
-				a.Value = slog.StringValue("TRACE")
+			// Replace "DEBUG-4"/"DEBUG-2" with "TRACE"/"DETAIL" in the level attribute
+			if a.Key == slog.LevelKey {
+				switch a.Value.Any().(slog.Level) {
+				case LevelTrace:
+					a.Value = slog.StringValue("TRACE")
+				case LevelDetail:
+					a.Value = slog.StringValue("DETAIL")
+				}
+
+				return a
 			}
-			return a
+
+			return redactAttr(opts.Redactor, a)
 		},
 	}))
 
-	// Console logger: clean output without timestamps
+	// Console logger: clean output, without timestamps unless asked for
+	consoleWriter := opts.ConsoleWriter
+	if consoleWriter == nil {
+		consoleWriter = os.Stdout
+	}
+
+	verbosity := opts.Verbosity
+	if opts.Verbose && verbosity < VerbosityDebug {
+		verbosity = VerbosityDebug
+	}
+
+	start := opts.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+
 	consoleHandler := &ConsoleHandler{
-		writer:  os.Stdout,
-		verbose: opts.Verbose,
+		writer:     consoleWriter,
+		minLevel:   ConsoleLevel(verbosity),
+		redactor:   opts.Redactor,
+		lang:       opts.Lang,
+		timestamps: opts.Timestamps,
+		start:      start,
 	}
 
 	consoleLogger := slog.New(consoleHandler)
@@ -176,9 +244,16 @@ func (l *Logger) GetLogPath() string {
 	return l.logPath
 }
 
-// Trace logs a trace message (file only, never to console)
+// Trace logs a Win32 call tracing message (console only at VerbosityTrace)
 func (l *Logger) Trace(msg string, args ...any) {
 	l.file.Log(context.Background(), LevelTrace, msg, args...)
+	l.console.Log(context.Background(), LevelTrace, msg, args...)
+}
+
+// Detail logs a window monitor detail message (console only at VerbosityDetail and above)
+func (l *Logger) Detail(msg string, args ...any) {
+	l.file.Log(context.Background(), LevelDetail, msg, args...)
+	l.console.Log(context.Background(), LevelDetail, msg, args...)
 }
 
 // Debug logs a debug message
@@ -205,23 +280,32 @@ func (l *Logger) Error(msg string, args ...any) {
 	l.console.Error(msg, args...)
 }
 
+// ConsoleLevel returns the lowest level shown on the console for a verbosity
+func ConsoleLevel(verbosity int) slog.Level {
+	switch {
+	case verbosity >= VerbosityTrace:
+		return LevelTrace
+	case verbosity == VerbosityDetail:
+		return LevelDetail
+	case verbosity == VerbosityDebug:
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
+
 // ConsoleHandler is a simple handler that outputs clean messages to console
 type ConsoleHandler struct {
-	writer  io.Writer
-	verbose bool
+	writer     io.Writer
+	minLevel   slog.Level
+	redactor   *redact.Redactor
+	lang       string
+	timestamps string
+	start      time.Time
 }
 
 func (h *ConsoleHandler) Enabled(_ context.Context, level slog.Level) bool {
-	// Trace level never goes to console
-	if level == LevelTrace {
-		return false
-	}
-
-	if !h.verbose && level == slog.LevelDebug {
-		return false
-	}
-
-	return true
+	return level >= h.minLevel
 }
 
 func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
@@ -238,13 +322,20 @@ func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
 	case slog.LevelDebug:
 		prefix = "VERBOSE: "
 		colorFunc = color.New(color.FgCyan)
+	case LevelDetail:
+		prefix = "DETAIL: "
+		colorFunc = color.New(color.FgBlue)
+	case LevelTrace:
+		prefix = "TRACE: "
+		colorFunc = color.New(color.FgHiBlack)
 	}
 
 	// Build the message with attributes
 	// For Info level, include attributes UNLESS the message is an enumerated list item
 	// (which starts with spaces and a number like "  1. ERROR...")
 	// For other levels (DEBUG/VERBOSE, WARN, ERROR), always include attributes
-	msg := r.Message
+	msg := i18n.Translate(h.lang, r.Message)
+	prefix = i18n.Translate(h.lang, prefix)
 
 	// Determine if we should include attributes
 	includeAttrs := r.NumAttrs() > 0
@@ -257,6 +348,7 @@ func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
 		attrs := make([]string, 0, r.NumAttrs())
 
 		r.Attrs(func(a slog.Attr) bool {
+			a = redactAttr(h.redactor, a)
 			attrs = append(attrs, fmt.Sprintf("%s=%v", a.Key, a.Value))
 			return true
 		})
@@ -266,6 +358,9 @@ func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
 		}
 	}
 
+	msg = h.redactor.String(msg)
+	prefix = h.timestamp(r.Time) + prefix
+
 	// Apply color if set, otherwise plain output
 	if colorFunc != nil {
 		if _, err := colorFunc.Fprintf(h.writer, "%s%s\n", prefix, msg); err != nil {
@@ -282,6 +377,51 @@ func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
 	return nil
 }
 
+// timestamp returns the --timestamps prefix for a record logged at t
+func (h *ConsoleHandler) timestamp(t time.Time) string {
+	if h.timestamps == TimestampsNone {
+		return ""
+	}
+
+	elapsed := max(t.Sub(h.start), 0)
+	ms := elapsed.Milliseconds()
+	stamp := fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3_600_000, ms/60_000%60, ms/1000%60, ms%1000)
+
+	if h.timestamps == TimestampsAbsolute {
+		stamp = t.Format("15:04:05.000") + " " + stamp
+	}
+
+	return "[" + stamp + "] "
+}
+
+// redactAttr rewrites string-like attribute values (including the message) through the redactor
+func redactAttr(r *redact.Redactor, a slog.Attr) slog.Attr {
+	if r == nil {
+		return a
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(r.String(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			a.Value = slog.StringValue(r.String(v.Error()))
+		case fmt.Stringer:
+			a.Value = slog.StringValue(r.String(v.String()))
+		case []string:
+			redacted := make([]string, len(v))
+			for i, s := range v {
+				redacted[i] = r.String(s)
+			}
+
+			a.Value = slog.AnyValue(redacted)
+		}
+	}
+
+	return a
+}
+
 // isEnumeratedMessage checks if a message is an enumerated list item
 // (e.g., "  1. ERROR...", "  2. WARNING...")
 func isEnumeratedMessage(msg string) bool {
@@ -317,13 +457,14 @@ func (h *ConsoleHandler) WithGroup(_ string) slog.Handler {
 // NoOpLogger is a logger that does nothing - useful for tests
 type NoOpLogger struct{}
 
-func (n *NoOpLogger) Trace(msg string, args ...any) {}
-func (n *NoOpLogger) Debug(msg string, args ...any) {}
-func (n *NoOpLogger) Info(msg string, args ...any)  {}
-func (n *NoOpLogger) Warn(msg string, args ...any)  {}
-func (n *NoOpLogger) Error(msg string, args ...any) {}
-func (n *NoOpLogger) Close()                        {}
-func (n *NoOpLogger) GetLogPath() string            { return "" }
+func (n *NoOpLogger) Trace(msg string, args ...any)  {}
+func (n *NoOpLogger) Detail(msg string, args ...any) {}
+func (n *NoOpLogger) Debug(msg string, args ...any)  {}
+func (n *NoOpLogger) Info(msg string, args ...any)   {}
+func (n *NoOpLogger) Warn(msg string, args ...any)   {}
+func (n *NoOpLogger) Error(msg string, args ...any)  {}
+func (n *NoOpLogger) Close()                         {}
+func (n *NoOpLogger) GetLogPath() string             { return "" }
 
 // NewNoOpLogger creates a new no-op logger for testing
 func NewNoOpLogger() *NoOpLogger {
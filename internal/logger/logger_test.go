@@ -1,14 +1,19 @@
 package logger_test
 
 import (
+	"bytes"
 	"log/slog"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/redact"
 )
 
 func TestNewLogger_DefaultOptions(t *testing.T) {
@@ -83,6 +88,144 @@ func TestNewLogger_NonVerbose(t *testing.T) {
 	assert.NotNil(t, log)
 }
 
+func TestNewLogger_CustomConsoleWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	log, err := logger.NewLogger(logger.LoggerOptions{
+		LogDir:        t.TempDir(),
+		ConsoleWriter: &buf,
+	})
+	require.NoError(t, err)
+	defer log.Close()
+
+	log.Info("redirected message")
+
+	assert.Contains(t, buf.String(), "redirected message")
+}
+
+func TestNewLogger_VerbosityLevels(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        logger.LoggerOptions
+		wantVerbose bool
+		wantDetail  bool
+		wantTrace   bool
+	}{
+		{name: "normal", opts: logger.LoggerOptions{}},
+		{name: "verbose flag", opts: logger.LoggerOptions{Verbose: true}, wantVerbose: true},
+		{name: "-v", opts: logger.LoggerOptions{Verbosity: logger.VerbosityDebug}, wantVerbose: true},
+		{name: "-vv", opts: logger.LoggerOptions{Verbosity: logger.VerbosityDetail}, wantVerbose: true, wantDetail: true},
+		{name: "-vvv", opts: logger.LoggerOptions{Verbosity: logger.VerbosityTrace}, wantVerbose: true, wantDetail: true, wantTrace: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			tt.opts.LogDir = t.TempDir()
+			tt.opts.ConsoleWriter = &buf
+
+			log, err := logger.NewLogger(tt.opts)
+			require.NoError(t, err)
+			defer log.Close()
+
+			log.Info("info line")
+			log.Debug("debug line")
+			log.Detail("detail line")
+			log.Trace("trace line")
+
+			out := buf.String()
+			assert.Contains(t, out, "info line")
+			assert.Equal(t, tt.wantVerbose, strings.Contains(out, "debug line"))
+			assert.Equal(t, tt.wantDetail, strings.Contains(out, "detail line"))
+			assert.Equal(t, tt.wantTrace, strings.Contains(out, "trace line"))
+		})
+	}
+}
+
+func TestNewLogger_Redaction(t *testing.T) {
+	var buf bytes.Buffer
+
+	tmpDir := t.TempDir()
+	log, err := logger.NewLogger(logger.LoggerOptions{
+		LogDir:        tmpDir,
+		ConsoleWriter: &buf,
+		Redactor:      redact.NewWithUsers(redact.ModeBasename, "jsmith"),
+	})
+	require.NoError(t, err)
+
+	log.Info(`Opening C:\Users\jsmith\Projects\program.smw`, slog.String("path", `C:\Users\jsmith\Projects\program.smw`))
+	log.Close()
+
+	fileContents, err := os.ReadFile(log.GetLogPath())
+	require.NoError(t, err)
+
+	for name, out := range map[string]string{"console": buf.String(), "file": string(fileContents)} {
+		assert.NotContains(t, out, "jsmith", "%s output should not contain the user name", name)
+		assert.Contains(t, out, "program.smw", "%s output should keep the file name", name)
+	}
+}
+
+func TestNewLogger_Lang(t *testing.T) {
+	var buf bytes.Buffer
+
+	log, err := logger.NewLogger(logger.LoggerOptions{
+		LogDir:        t.TempDir(),
+		ConsoleWriter: &buf,
+		Lang:          "de",
+	})
+	require.NoError(t, err)
+
+	log.Info("Compilation complete", slog.Int("errors", 0))
+	log.Error("Compilation failed")
+	log.Close()
+
+	assert.Contains(t, buf.String(), "Kompilierung abgeschlossen errors=0")
+	assert.Contains(t, buf.String(), "FEHLER: Kompilierung fehlgeschlagen")
+
+	fileContents, err := os.ReadFile(log.GetLogPath())
+	require.NoError(t, err)
+	assert.Contains(t, string(fileContents), "Compilation complete", "the log file stays in English")
+}
+
+func TestNewLogger_Timestamps(t *testing.T) {
+	start := time.Now().Add(-62 * time.Second)
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{logger.TimestampsNone, `^WARNING: slow step\n$`},
+		{logger.TimestampsElapsed, `^\[00:01:0[23]\.\d{3}\] WARNING: slow step\n$`},
+		{logger.TimestampsAbsolute, `^\[\d{2}:\d{2}:\d{2}\.\d{3} 00:01:0[23]\.\d{3}\] WARNING: slow step\n$`},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+
+		log, err := logger.NewLogger(logger.LoggerOptions{
+			LogDir:        t.TempDir(),
+			ConsoleWriter: &buf,
+			Timestamps:    tt.mode,
+			Start:         start,
+		})
+		require.NoError(t, err)
+
+		log.Warn("slow step")
+		log.Close()
+
+		assert.Regexp(t, tt.want, buf.String(), tt.mode)
+	}
+}
+
+func TestValidateTimestamps(t *testing.T) {
+	for _, mode := range []string{logger.TimestampsNone, logger.TimestampsElapsed, logger.TimestampsAbsolute} {
+		assert.NoError(t, logger.ValidateTimestamps(mode))
+	}
+
+	assert.Error(t, logger.ValidateTimestamps("utc"))
+}
+
 func TestNewLogger_FallbackToUserProfile(t *testing.T) {
 	// Clear LOCALAPPDATA and set USERPROFILE
 	tmpDir := t.TempDir()
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RotatedLogFiles returns the rotated (backup) log files next to the current log,
+// oldest first. The active log file is never included.
+func RotatedLogFiles(opts LoggerOptions) ([]string, error) {
+	logPath := GetLogPath(opts)
+	dir := filepath.Dir(logPath)
+	ext := filepath.Ext(logPath)
+	prefix := strings.TrimSuffix(filepath.Base(logPath), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read log directory %s: %w", dir, err)
+	}
+
+	var files []string
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		// Backups are named <name>-<timestamp><ext>, optionally compressed to .gz
+		if !strings.HasSuffix(name, ext) && !strings.HasSuffix(name, ext+".gz") {
+			continue
+		}
+
+		files = append(files, filepath.Join(dir, name))
+	}
+
+	// The timestamp format sorts lexically in chronological order
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// PruneRotatedLogs deletes rotated log files, keeping the newest keep backups.
+// It returns the paths that were removed.
+func PruneRotatedLogs(opts LoggerOptions, keep int) ([]string, error) {
+	files, err := RotatedLogFiles(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if keep < 0 {
+		keep = 0
+	}
+
+	if len(files) <= keep {
+		return nil, nil
+	}
+
+	var removed []string
+
+	for _, f := range files[:len(files)-keep] {
+		if err := os.Remove(f); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", f, err)
+		}
+
+		removed = append(removed, f)
+	}
+
+	return removed, nil
+}
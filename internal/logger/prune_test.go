@@ -0,0 +1,70 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+// writeLogFiles creates empty files with the given names in dir
+func writeLogFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("log"), 0o644))
+	}
+}
+
+func TestRotatedLogFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeLogFiles(t, tmpDir,
+		"smpc.log",
+		"smpc-2025-01-02T10-00-00.000.log.gz",
+		"smpc-2025-01-01T10-00-00.000.log",
+		"other.log",
+	)
+
+	files, err := logger.RotatedLogFiles(logger.LoggerOptions{LogDir: tmpDir})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join(tmpDir, "smpc-2025-01-01T10-00-00.000.log"),
+		filepath.Join(tmpDir, "smpc-2025-01-02T10-00-00.000.log.gz"),
+	}, files, "Only backups should be listed, oldest first")
+}
+
+func TestRotatedLogFiles_MissingDirectory(t *testing.T) {
+	files, err := logger.RotatedLogFiles(logger.LoggerOptions{LogDir: filepath.Join(t.TempDir(), "missing")})
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestPruneRotatedLogs(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeLogFiles(t, tmpDir,
+		"smpc.log",
+		"smpc-2025-01-01T10-00-00.000.log.gz",
+		"smpc-2025-01-02T10-00-00.000.log.gz",
+		"smpc-2025-01-03T10-00-00.000.log.gz",
+	)
+
+	opts := logger.LoggerOptions{LogDir: tmpDir}
+
+	removed, err := logger.PruneRotatedLogs(opts, 1)
+	require.NoError(t, err)
+	assert.Len(t, removed, 2)
+
+	assert.FileExists(t, filepath.Join(tmpDir, "smpc.log"), "Active log must never be pruned")
+	assert.FileExists(t, filepath.Join(tmpDir, "smpc-2025-01-03T10-00-00.000.log.gz"), "Newest backup should be kept")
+	assert.NoFileExists(t, filepath.Join(tmpDir, "smpc-2025-01-01T10-00-00.000.log.gz"))
+
+	removed, err = logger.PruneRotatedLogs(opts, 0)
+	require.NoError(t, err)
+	assert.Len(t, removed, 1)
+	assert.FileExists(t, filepath.Join(tmpDir, "smpc.log"))
+}
@@ -0,0 +1,199 @@
+// Package notify sends run results to people, for teams without chat-ops integrations.
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/report"
+)
+
+// DefaultPasswordEnv holds the SMTP password when the settings do not name another variable
+const DefaultPasswordEnv = "SMPC_SMTP_PASSWORD"
+
+// Email is the contents of a --notify-email settings file
+type Email struct {
+	Server      string   `json:"server"`                // host:port of the SMTP server
+	From        string   `json:"from"`                  // Sender address
+	To          []string `json:"to"`                    // Recipient addresses
+	Username    string   `json:"username,omitempty"`    // Enables PLAIN authentication when set
+	PasswordEnv string   `json:"passwordEnv,omitempty"` // Variable holding the password (default SMPC_SMTP_PASSWORD)
+	OnlyFailed  bool     `json:"onlyFailed,omitempty"`  // Only send when a file failed
+}
+
+// sendMail delivers a message; replaced in tests
+var sendMail = smtp.SendMail
+
+// LoadEmail reads and validates the email settings file at path
+func LoadEmail(path string) (*Email, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email settings: %w", err)
+	}
+
+	var e Email
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("invalid email settings %s: %w", path, err)
+	}
+
+	if err := e.validate(); err != nil {
+		return nil, fmt.Errorf("invalid email settings %s: %w", path, err)
+	}
+
+	return &e, nil
+}
+
+func (e *Email) validate() error {
+	if _, _, err := net.SplitHostPort(e.Server); err != nil {
+		return fmt.Errorf("server must be host:port: %w", err)
+	}
+
+	if e.From == "" {
+		return fmt.Errorf("missing from")
+	}
+
+	if len(e.To) == 0 {
+		return fmt.Errorf("missing to")
+	}
+
+	return nil
+}
+
+// Send emails the summary of results, with the JSON report attached. getenv supplies
+// the password. Nothing is sent when OnlyFailed is set and every file passed.
+func (e *Email) Send(results []report.FileResult, getenv func(string) string) error {
+	if e.OnlyFailed && report.Summarize(results).Failed == 0 {
+		return nil
+	}
+
+	msg, err := e.Message(results, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+
+	if e.Username != "" {
+		env := e.PasswordEnv
+		if env == "" {
+			env = DefaultPasswordEnv
+		}
+
+		host, _, _ := net.SplitHostPort(e.Server)
+		auth = smtp.PlainAuth("", e.Username, getenv(env), host)
+	}
+
+	if err := sendMail(e.Server, auth, e.From, e.To, msg); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// Subject summarizes the run in one line, e.g. "smpc: 1 of 3 failed"
+func Subject(results []report.FileResult) string {
+	t := report.Summarize(results)
+
+	switch {
+	case len(results) == 1:
+		return fmt.Sprintf("smpc: %s %s", report.DisplayName(results[0].File), results[0].Status)
+	case t.Failed > 0:
+		return fmt.Sprintf("smpc: %d of %d failed", t.Failed, t.Files)
+	default:
+		return fmt.Sprintf("smpc: all %d passed", t.Files)
+	}
+}
+
+// Message builds the MIME message: the summary table as text and the JSON report
+// as an attachment
+func (e *Email) Message(results []report.FileResult, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+
+	mw := multipart.NewWriter(&body)
+
+	textPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Program names and messages may hold any UTF-8
+	text := quotedprintable.NewWriter(textPart)
+
+	if err := report.WriteTable(text, results); err != nil {
+		return nil, err
+	}
+
+	for _, r := range results {
+		if r.Message != "" {
+			fmt.Fprintf(text, "\n%s: %s", report.DisplayName(r.File), r.Message)
+		}
+	}
+
+	if err := text.Close(); err != nil {
+		return nil, err
+	}
+
+	var attachment bytes.Buffer
+	if err := report.WriteJSON(&attachment, results); err != nil {
+		return nil, err
+	}
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"application/json"},
+		"Content-Disposition":       {`attachment; filename="smpc-report.json"`},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := part.Write([]byte(wrapBase64(attachment.Bytes()))); err != nil {
+		return nil, err
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", Subject(results)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// wrapBase64 encodes data in lines of 76 characters, as MIME requires
+func wrapBase64(data []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var b strings.Builder
+
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+
+	b.WriteString(encoded + "\r\n")
+
+	return b.String()
+}
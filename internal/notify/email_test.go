@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/report"
+)
+
+var results = []report.FileResult{
+	{File: `C:\jobs\lobby.smw`, Status: report.StatusPassed, Duration: 42 * time.Second},
+	{File: `C:\jobs\theater.smw`, Status: report.StatusFailed, Errors: 3, Message: "compilation failed with 3 error(s)"},
+}
+
+func TestLoadEmail(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "email.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"server":"smtp.example.com:587","from":"ci@example.com","to":["av@example.com"]}`), 0o644))
+
+	e, err := LoadEmail(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"av@example.com"}, e.To)
+
+	for name, content := range map[string]string{
+		"no port": `{"server":"smtp.example.com","from":"a@b","to":["c@d"]}`,
+		"no from": `{"server":"smtp.example.com:25","to":["c@d"]}`,
+		"no to":   `{"server":"smtp.example.com:25","from":"a@b"}`,
+		"invalid": `{`,
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		_, err := LoadEmail(path)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestSubject(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "smpc: lobby.smw passed", Subject(results[:1]))
+	assert.Equal(t, "smpc: 1 of 2 failed", Subject(results))
+	assert.Equal(t, "smpc: all 2 passed", Subject([]report.FileResult{results[0], results[0]}))
+}
+
+func TestMessage(t *testing.T) {
+	t.Parallel()
+
+	e := &Email{Server: "smtp.example.com:25", From: "ci@example.com", To: []string{"a@example.com", "b@example.com"}}
+
+	raw, err := e.Message(results, time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com, b@example.com", msg.Header.Get("To"))
+	assert.Equal(t, "smpc: 1 of 2 failed", msg.Header.Get("Subject"))
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	text, err := mr.NextPart()
+	require.NoError(t, err)
+
+	body, _ := io.ReadAll(text)
+	assert.Contains(t, string(body), "theater.smw: compilation failed with 3 error(s)")
+
+	attachment, err := mr.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "smpc-report.json", attachment.FileName())
+
+	encoded, _ := io.ReadAll(attachment)
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.ReplaceAll(encoded, []byte("\r\n"), nil)))
+	require.NoError(t, err)
+	assert.Contains(t, string(decoded), `"failed": 1`)
+}
+
+func TestMessage_NonASCII(t *testing.T) {
+	t.Parallel()
+
+	e := &Email{Server: "smtp.example.com:25", From: "ci@example.com", To: []string{"a@example.com"}}
+	failed := []report.FileResult{{File: `C:\jobs\Café Zürich.smw`, Status: report.StatusFailed, Message: "compilation failed"}}
+
+	raw, err := e.Message(failed, time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(raw), "Content-Transfer-Encoding: quoted-printable")
+
+	headers, _, _ := bytes.Cut(raw, []byte("\r\n\r\n"))
+	for _, c := range headers {
+		require.Less(t, c, byte(0x80), "headers must be ASCII")
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "smpc: Café Zürich.smw failed", subject)
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+
+	text, err := multipart.NewReader(msg.Body, params["boundary"]).NextPart()
+	require.NoError(t, err)
+
+	body, _ := io.ReadAll(text)
+	assert.Contains(t, string(body), "Café Zürich.smw: compilation failed")
+}
+
+// Not parallel: replaces sendMail
+func TestSend(t *testing.T) {
+	var (
+		gotAuth smtp.Auth
+		gotTo   []string
+		calls   int
+	)
+
+	sendMail = func(_ string, auth smtp.Auth, _ string, to []string, _ []byte) error {
+		calls++
+		gotAuth, gotTo = auth, to
+		return nil
+	}
+	defer func() { sendMail = smtp.SendMail }()
+
+	getenv := func(name string) string {
+		if name == DefaultPasswordEnv {
+			return "secret"
+		}
+
+		return ""
+	}
+
+	e := &Email{Server: "smtp.example.com:587", From: "ci@example.com", To: []string{"av@example.com"}, Username: "ci"}
+	require.NoError(t, e.Send(results, getenv))
+	assert.Equal(t, 1, calls)
+	assert.NotNil(t, gotAuth)
+	assert.Equal(t, []string{"av@example.com"}, gotTo)
+
+	e.OnlyFailed = true
+	require.NoError(t, e.Send(results[:1], getenv))
+	assert.Equal(t, 1, calls, "nothing is sent when every file passed")
+
+	sendMail = func(string, smtp.Auth, string, []string, []byte) error { return errors.New("refused") }
+	assert.ErrorContains(t, e.Send(results, getenv), "failed to send email: refused")
+}
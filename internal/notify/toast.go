@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/report"
+)
+
+// toastAppID is the application the toast is shown as. Unpackaged programs cannot
+// raise toasts under their own name without registering a shortcut, so smpc borrows
+// Windows PowerShell's, which is registered on every install.
+const toastAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// toastScript shows the toast XML passed in SMPC_TOAST_XML through the WinRT
+// notification API. Passing it in the environment avoids quoting it for the command line.
+const toastScript = `$ErrorActionPreference = 'Stop'
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml($env:SMPC_TOAST_XML)
+$toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($env:SMPC_TOAST_APP_ID).Show($toast)`
+
+// runPowerShell runs script with the extra environment variables; replaced in tests
+var runPowerShell = func(script string, env []string) error {
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", script)
+	cmd.Env = append(os.Environ(), env...)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// Toast raises a Windows toast notification saying whether the run passed, for
+// engineers who have switched to other work while it compiled
+func Toast(results []report.FileResult) error {
+	if err := runPowerShell(toastScript, []string{
+		"SMPC_TOAST_XML=" + ToastXML(results),
+		"SMPC_TOAST_APP_ID=" + toastAppID,
+	}); err != nil {
+		return fmt.Errorf("failed to show toast notification: %w", err)
+	}
+
+	return nil
+}
+
+// ToastXML builds the toast: the subject as its title, then the message counts and duration
+func ToastXML(results []report.FileResult) string {
+	t := report.Summarize(results)
+
+	body := fmt.Sprintf("%d error(s), %d warning(s) in %s", t.Errors, t.Warnings, t.Duration.Round(100*time.Millisecond))
+	if len(results) == 1 && results[0].Message != "" {
+		body += "\n" + results[0].Message
+	}
+
+	var b bytes.Buffer
+
+	b.WriteString(`<toast><visual><binding template="ToastGeneric"><text>`)
+	_ = xml.EscapeText(&b, []byte(Subject(results)))
+	b.WriteString(`</text><text>`)
+	_ = xml.EscapeText(&b, []byte(body))
+	b.WriteString(`</text></binding></visual></toast>`)
+
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/report"
+)
+
+func TestToastXML(t *testing.T) {
+	t.Parallel()
+
+	var toast struct {
+		Texts []string `xml:"visual>binding>text"`
+	}
+
+	require.NoError(t, xml.Unmarshal([]byte(ToastXML(results[1:])), &toast))
+	assert.Equal(t, []string{
+		"smpc: theater.smw failed",
+		"3 error(s), 0 warning(s) in 0s\ncompilation failed with 3 error(s)",
+	}, toast.Texts)
+
+	// File names are escaped
+	tricky := []report.FileResult{{File: `C:\jobs\<a&b>.smw`, Status: report.StatusPassed}}
+	toast.Texts = nil
+	require.NoError(t, xml.Unmarshal([]byte(ToastXML(tricky)), &toast))
+	assert.Equal(t, "smpc: <a&b>.smw passed", toast.Texts[0])
+}
+
+// Not parallel: replaces runPowerShell
+func TestToast(t *testing.T) {
+	defer func(orig func(string, []string) error) { runPowerShell = orig }(runPowerShell)
+
+	var env []string
+
+	runPowerShell = func(script string, e []string) error {
+		env = e
+		return nil
+	}
+
+	require.NoError(t, Toast(results))
+	assert.Contains(t, env, "SMPC_TOAST_XML="+ToastXML(results))
+	assert.Contains(t, env, "SMPC_TOAST_APP_ID="+toastAppID)
+
+	runPowerShell = func(string, []string) error { return errors.New("exit status 1") }
+
+	err := Toast(results)
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "failed to show toast notification"))
+}
@@ -0,0 +1,78 @@
+// Package poll provides adaptive polling intervals: fast while something is
+// happening, backing off exponentially through quiet periods.
+package poll
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+)
+
+// DefaultFactor is how much the interval grows after each quiet poll
+const DefaultFactor = 1.5
+
+// Settings configures an adaptive interval
+type Settings struct {
+	Min    time.Duration // First interval, and the interval after a reset
+	Max    time.Duration // Longest interval backed off to
+	Factor float64       // Growth after each quiet poll (1 = fixed interval)
+}
+
+// DefaultSettings polls every timeouts.StatePollingInterval after a reset, backing off
+// to timeouts.MaxPollingInterval
+func DefaultSettings() Settings {
+	return Settings{
+		Min:    timeouts.StatePollingInterval,
+		Max:    timeouts.MaxPollingInterval,
+		Factor: DefaultFactor,
+	}
+}
+
+// Validate returns an error if the settings cannot produce a usable interval
+func (s Settings) Validate() error {
+	switch {
+	case s.Min <= 0:
+		return fmt.Errorf("minimum polling interval must be positive, got %s", s.Min)
+	case s.Max < s.Min:
+		return fmt.Errorf("maximum polling interval %s is shorter than the minimum %s", s.Max, s.Min)
+	case s.Factor < 1:
+		return fmt.Errorf("polling backoff factor must be at least 1, got %g", s.Factor)
+	default:
+		return nil
+	}
+}
+
+// Interval is an adaptive delay between polls. It is safe for concurrent use, so
+// one goroutine can Reset the interval another is polling with.
+type Interval struct {
+	settings Settings
+
+	mu   sync.Mutex
+	next time.Duration
+}
+
+// NewInterval creates an interval starting at s.Min
+func NewInterval(s Settings) *Interval {
+	return &Interval{settings: s, next: s.Min}
+}
+
+// Next returns the delay before the next poll and backs off the one after it
+func (i *Interval) Next() time.Duration {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	d := i.next
+	i.next = min(time.Duration(float64(i.next)*i.settings.Factor), i.settings.Max)
+
+	return d
+}
+
+// Reset returns to polling at the minimum interval, e.g. after a trigger or a change
+func (i *Interval) Reset() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.next = i.settings.Min
+}
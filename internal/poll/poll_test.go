@@ -0,0 +1,49 @@
+package poll
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterval_BacksOffAndResets(t *testing.T) {
+	t.Parallel()
+
+	interval := NewInterval(Settings{Min: 100 * time.Millisecond, Max: time.Second, Factor: 2})
+
+	var got []time.Duration
+	for range 6 {
+		got = append(got, interval.Next())
+	}
+
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}, got)
+
+	interval.Reset()
+	assert.Equal(t, 100*time.Millisecond, interval.Next())
+}
+
+func TestInterval_FixedFactor(t *testing.T) {
+	t.Parallel()
+
+	interval := NewInterval(Settings{Min: 500 * time.Millisecond, Max: 500 * time.Millisecond, Factor: 1})
+
+	assert.Equal(t, 500*time.Millisecond, interval.Next())
+	assert.Equal(t, 500*time.Millisecond, interval.Next())
+}
+
+func TestSettings_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, DefaultSettings().Validate())
+	assert.ErrorContains(t, Settings{Max: time.Second, Factor: 2}.Validate(), "must be positive")
+	assert.ErrorContains(t, Settings{Min: time.Second, Max: time.Millisecond, Factor: 2}.Validate(), "shorter than the minimum")
+	assert.ErrorContains(t, Settings{Min: time.Second, Max: time.Second, Factor: 0.5}.Validate(), "at least 1")
+}
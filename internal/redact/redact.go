@@ -0,0 +1,178 @@
+// Package redact removes user names and file system paths from text so logs
+// and reports can be shared without exposing project or user details.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode selects how paths are rewritten
+type Mode string
+
+const (
+	// ModeNone leaves text untouched
+	ModeNone Mode = ""
+
+	// ModeBasename keeps only the final path element (C:\Users\bob\x.smw -> x.smw)
+	ModeBasename Mode = "basename"
+
+	// ModeHash replaces the whole path with a short stable hash (-> <path:1a2b3c4d>)
+	ModeHash Mode = "hash"
+
+	// userPlaceholder replaces the current user name wherever it appears
+	userPlaceholder = "<user>"
+)
+
+// pathPattern matches drive-letter and UNC paths up to the first whitespace or delimiter.
+// Paths containing spaces are only fully redacted when registered with AddPath.
+var pathPattern = regexp.MustCompile(`(?:[A-Za-z]:|\\\\[^\\\s"'<>|\[\]]+)\\[^\s"'<>|\[\]]*`)
+
+// Redactor rewrites text according to its mode. A nil *Redactor returns text unchanged.
+type Redactor struct {
+	mode  Mode
+	mu    sync.RWMutex
+	paths []string // Known paths, longest first, replaced before the pattern fallback
+	users []string
+}
+
+// ParseMode validates a --redact value
+func ParseMode(s string) (Mode, error) {
+	switch Mode(strings.ToLower(s)) {
+	case ModeNone:
+		return ModeNone, nil
+	case ModeBasename:
+		return ModeBasename, nil
+	case ModeHash:
+		return ModeHash, nil
+	default:
+		return ModeNone, fmt.Errorf("unsupported redaction mode %q (supported: %s, %s)", s, ModeBasename, ModeHash)
+	}
+}
+
+// New creates a Redactor for the current user. It returns nil for ModeNone.
+func New(mode Mode) *Redactor {
+	return NewWithUsers(mode, currentUsers()...)
+}
+
+// NewWithUsers creates a Redactor that hides the given user names
+func NewWithUsers(mode Mode, users ...string) *Redactor {
+	if mode == ModeNone {
+		return nil
+	}
+
+	r := &Redactor{mode: mode}
+	for _, u := range users {
+		if u != "" {
+			r.users = append(r.users, u)
+		}
+	}
+
+	return r
+}
+
+// AddPath registers a path that must be redacted even if it contains spaces
+func (r *Redactor) AddPath(path string) {
+	if r == nil || path == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.paths = append(r.paths, path)
+	sort.Slice(r.paths, func(i, j int) bool { return len(r.paths[i]) > len(r.paths[j]) })
+}
+
+// String redacts paths and user names in s
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+
+	r.mu.RLock()
+	for _, p := range r.paths {
+		s = replaceFold(s, p, r.path(p))
+	}
+	r.mu.RUnlock()
+
+	s = pathPattern.ReplaceAllStringFunc(s, r.path)
+
+	for _, u := range r.users {
+		s = replaceFold(s, u, userPlaceholder)
+	}
+
+	return s
+}
+
+// path rewrites a single path according to the mode
+func (r *Redactor) path(p string) string {
+	switch r.mode {
+	case ModeHash:
+		sum := sha256.Sum256([]byte(strings.ToLower(p)))
+		return "<path:" + hex.EncodeToString(sum[:4]) + ">"
+	default:
+		p = strings.TrimRight(p, `\/`)
+		if i := strings.LastIndexAny(p, `\/`); i >= 0 {
+			return p[i+1:]
+		}
+
+		return filepath.Base(p)
+	}
+}
+
+// replaceFold replaces every case-insensitive occurrence of old in s
+// (Windows paths and account names are case-insensitive). Runes are compared with
+// strings.EqualFold rather than by lowering s, which can change its length.
+func replaceFold(s, old, replacement string) string {
+	if old == "" {
+		return s
+	}
+
+	runes := utf8.RuneCountInString(old)
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); {
+		if n := prefixLen(s[i:], runes); n > 0 && strings.EqualFold(s[i:i+n], old) {
+			b.WriteString(replacement)
+			i += n
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(s[i:])
+		b.WriteString(s[i : i+size])
+		i += size
+	}
+
+	return b.String()
+}
+
+// prefixLen returns the length in bytes of the first runes runes of s, or 0 if s
+// is shorter than that
+func prefixLen(s string, runes int) int {
+	n := 0
+	for ; runes > 0; runes-- {
+		if n >= len(s) {
+			return 0
+		}
+
+		_, size := utf8.DecodeRuneInString(s[n:])
+		n += size
+	}
+
+	return n
+}
+
+// currentUsers returns the names that identify the current account
+func currentUsers() []string {
+	return []string{os.Getenv("USERNAME"), os.Getenv("USER")}
+}
@@ -0,0 +1,96 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    Mode
+		wantErr bool
+	}{
+		{input: "", want: ModeNone},
+		{input: "basename", want: ModeBasename},
+		{input: "HASH", want: ModeHash},
+		{input: "full", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseMode(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRedactor_Basename(t *testing.T) {
+	t.Parallel()
+
+	r := NewWithUsers(ModeBasename, "jsmith")
+
+	assert.Equal(t,
+		"Processing file path=program.smw",
+		r.String(`Processing file path=C:\Users\jsmith\Projects\program.smw`),
+	)
+	assert.Equal(t, "Opened program.smw on share", r.String(`Opened \\fileserver\jobs\program.smw on share`))
+	assert.Equal(t, "Logged in as <user>", r.String("Logged in as JSmith"))
+}
+
+func TestRedactor_KnownPathWithSpaces(t *testing.T) {
+	t.Parallel()
+
+	r := NewWithUsers(ModeBasename, "jsmith")
+	r.AddPath(`C:\Users\jsmith\Client Projects\Board Room.smw`)
+
+	assert.Equal(t,
+		"SIMPL Windows - [Board Room.smw]",
+		r.String(`SIMPL Windows - [c:\users\jsmith\client projects\board room.smw]`),
+	)
+}
+
+func TestRedactor_NonASCII(t *testing.T) {
+	t.Parallel()
+
+	// Lowering İ or the Kelvin sign changes their length in bytes
+	r := NewWithUsers(ModeBasename, "jsmith", "kåre")
+
+	assert.Equal(t, "İİ <user> \u212a <user>", r.String("İİ JSmith \u212a KÅRE"))
+	assert.Equal(t, "İstanbul <user>", r.String("İstanbul \u212aåre"))
+	assert.Equal(t, "ş<user>ş", r.String("şjsmithş"))
+}
+
+func TestRedactor_Hash(t *testing.T) {
+	t.Parallel()
+
+	r := NewWithUsers(ModeHash)
+
+	first := r.String(`C:\Projects\secret.smw`)
+	second := r.String(`c:\projects\SECRET.smw`)
+
+	assert.Regexp(t, `^<path:[0-9a-f]{8}>$`, first)
+	assert.Equal(t, first, second, "Hashes should be stable and case-insensitive")
+	assert.NotContains(t, first, "secret")
+}
+
+func TestRedactor_NilIsPassthrough(t *testing.T) {
+	t.Parallel()
+
+	r := New(ModeNone)
+	assert.Nil(t, r)
+	assert.Equal(t, `C:\a\b.smw`, r.String(`C:\a\b.smw`))
+	assert.NotPanics(t, func() { r.AddPath(`C:\a`) })
+}
@@ -0,0 +1,128 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// codeQualitySeverities maps message severities to Code Climate severities
+var codeQualitySeverities = map[string]string{
+	SeverityError:   "critical",
+	SeverityWarning: "minor",
+	SeverityNotice:  "info",
+}
+
+var (
+	// messageCode matches the check code of a message, e.g. "(LGCMCVT102)"
+	messageCode = regexp.MustCompile(`\(([A-Z]+[0-9]+)\)`)
+
+	// messageLine matches the line number some messages give, e.g. "Line 15:"
+	messageLine = regexp.MustCompile(`(?i)\bline\s+([0-9]+)`)
+)
+
+// workingDir is what Code Quality paths are made relative to; replaced in tests
+var workingDir = os.Getwd
+
+// codeQualityIssue is one entry of a Code Climate report, the format GitLab's Code
+// Quality widget reads
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+	Content     *codeQualityContent `json:"content,omitempty"`
+}
+
+// codeQualityContent carries the message's hint, shown when the issue is expanded
+type codeQualityContent struct {
+	Body string `json:"body"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+// WriteCodeQuality writes the compiler messages as a GitLab Code Quality (Code Climate)
+// report, such as gl-code-quality-report.json. Paths are relative to the working
+// directory, which in CI is the repository root. A file that failed without compiler
+// messages is reported as a single blocker issue.
+func WriteCodeQuality(w io.Writer, results []FileResult) error {
+	issues := []codeQualityIssue{}
+	seen := make(map[string]int)
+
+	add := func(r FileResult, severity, check, text, hint string) {
+		path := relativePath(r.File)
+
+		line := 1
+		if m := messageLine.FindStringSubmatch(text); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+
+		// Identical messages in one file still need distinct fingerprints
+		key := path + "\x00" + severity + "\x00" + text
+		seen[key]++
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", key, seen[key])))
+
+		issues = append(issues, codeQualityIssue{
+			Description: text,
+			CheckName:   check,
+			Fingerprint: hex.EncodeToString(sum[:16]),
+			Severity:    severity,
+			Location:    codeQualityLocation{Path: path, Lines: codeQualityLines{Begin: line}},
+		})
+
+		if hint != "" {
+			issues[len(issues)-1].Content = &codeQualityContent{Body: hint}
+		}
+	}
+
+	for _, r := range results {
+		for _, m := range r.Messages {
+			check := "simpl-" + m.Severity
+			if c := messageCode.FindStringSubmatch(m.Text); c != nil {
+				check = c[1]
+			}
+
+			add(r, codeQualitySeverities[m.Severity], check, strings.Join(strings.Fields(m.Text), " "), m.Hint)
+		}
+
+		if r.Status != StatusPassed && len(r.Messages) == 0 && r.Message != "" {
+			add(r, "blocker", "smpc-failure", r.Message, "")
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(issues)
+}
+
+// relativePath returns path relative to the working directory with forward slashes,
+// or path itself when it is outside it
+func relativePath(path string) string {
+	wd, err := workingDir()
+	if err != nil || !filepath.IsAbs(path) {
+		return filepath.ToSlash(path)
+	}
+
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Not parallel: replaces workingDir
+func TestWriteCodeQuality(t *testing.T) {
+	root := t.TempDir()
+	workingDir = func() (string, error) { return root, nil }
+	defer func() { workingDir = os.Getwd }()
+
+	results := []FileResult{
+		{
+			File:   filepath.Join(root, "programs", "lobby.smw"),
+			Status: StatusFailed,
+			Messages: []Message{
+				{Severity: SeverityError, Text: "ERROR      (LGSPLS1700) Line 5: Undefined symbol 'foo'"},
+				{Severity: SeverityWarning, Text: "WARNING    (LGCMCVT102) ** Signal foo has no driving source", Hint: "hint: connect it"},
+				{Severity: SeverityWarning, Text: "WARNING    (LGCMCVT102) ** Signal foo has no driving source"},
+				{Severity: SeverityNotice, Text: "Compiler notice without a code"},
+			},
+		},
+		{File: filepath.Join(root, "theater.smw"), Status: StatusFailed, Message: "timed out waiting for SIMPL Windows"},
+		{File: filepath.Join(root, "boardroom.smw"), Status: StatusPassed},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCodeQuality(&buf, results))
+
+	var issues []codeQualityIssue
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &issues))
+	require.Len(t, issues, 5)
+
+	assert.Equal(t, codeQualityIssue{
+		Description: "ERROR (LGSPLS1700) Line 5: Undefined symbol 'foo'",
+		CheckName:   "LGSPLS1700",
+		Fingerprint: issues[0].Fingerprint,
+		Severity:    "critical",
+		Location:    codeQualityLocation{Path: "programs/lobby.smw", Lines: codeQualityLines{Begin: 5}},
+	}, issues[0])
+
+	assert.Equal(t, "minor", issues[1].Severity)
+	assert.Equal(t, &codeQualityContent{Body: "hint: connect it"}, issues[1].Content)
+	assert.Nil(t, issues[2].Content)
+	assert.Equal(t, 1, issues[1].Location.Lines.Begin)
+	assert.NotEqual(t, issues[1].Fingerprint, issues[2].Fingerprint, "repeated messages get distinct fingerprints")
+
+	assert.Equal(t, "simpl-notice", issues[3].CheckName)
+	assert.Equal(t, "info", issues[3].Severity)
+
+	assert.Equal(t, "blocker", issues[4].Severity)
+	assert.Equal(t, "theater.smw", issues[4].Location.Path)
+	assert.Equal(t, "timed out waiting for SIMPL Windows", issues[4].Description)
+}
+
+func TestWriteCodeQualityEmpty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCodeQuality(&buf, []FileResult{{File: "lobby.smw", Status: StatusPassed}}))
+	assert.JSONEq(t, "[]", buf.String(), "GitLab expects an array even without issues")
+}
@@ -0,0 +1,45 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// csvHeader is the first row of a CSV report
+var csvHeader = []string{"file", "status", "errors", "warnings", "notices", "duration_seconds", "message", "commit", "branch", "dirty"}
+
+// WriteCSV writes one row per file, suitable for spreadsheets
+func WriteCSV(w io.Writer, results []FileResult) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		row := []string{
+			r.File,
+			r.Status,
+			strconv.Itoa(r.Errors),
+			strconv.Itoa(r.Warnings),
+			strconv.Itoa(r.Notices),
+			strconv.FormatFloat(r.Duration.Seconds(), 'f', 2, 64),
+			r.Message,
+			"",
+			"",
+			"",
+		}
+
+		if r.Git != nil {
+			row[7], row[8], row[9] = r.Git.Commit, r.Git.Branch, strconv.FormatBool(r.Git.Dirty)
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
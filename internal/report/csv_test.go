@@ -0,0 +1,41 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/gitinfo"
+)
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, sampleResults))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, []string{"file", "status", "errors", "warnings", "notices", "duration_seconds", "message", "commit", "branch", "dirty"}, records[0])
+	assert.Equal(t, []string{`C:\jobs\lobby.smw`, "passed", "0", "2", "1", "42.30", "", "", "", ""}, records[1])
+	assert.Equal(t, []string{`C:\jobs\theater.smw`, "failed", "3", "0", "0", "15.00", "compilation failed with 3 error(s)", "", "", ""}, records[2])
+}
+
+func TestWriteCSVGit(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, []FileResult{
+		{File: "lobby.smw", Status: StatusPassed, Git: &gitinfo.Info{Commit: "1a2b3c", Branch: "main", Dirty: true}},
+	}))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"1a2b3c", "main", "true"}, records[1][7:])
+}
@@ -0,0 +1,82 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/Norgate-AV/smpc/internal/gitinfo"
+)
+
+// jsonReport is the document written by WriteJSON
+type jsonReport struct {
+	Totals  jsonTotals   `json:"totals"`
+	Results []jsonResult `json:"results"`
+}
+
+type jsonTotals struct {
+	Files           int     `json:"files"`
+	Passed          int     `json:"passed"`
+	Failed          int     `json:"failed"`
+	Errors          int     `json:"errors"`
+	Warnings        int     `json:"warnings"`
+	Notices         int     `json:"notices"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+type jsonResult struct {
+	File            string        `json:"file"`
+	Status          string        `json:"status"`
+	Errors          int           `json:"errors"`
+	Warnings        int           `json:"warnings"`
+	Notices         int           `json:"notices"`
+	DurationSeconds float64       `json:"duration_seconds"`
+	Message         string        `json:"message,omitempty"`
+	Git             *gitinfo.Info `json:"git,omitempty"`
+	Messages        []jsonMessage `json:"messages,omitempty"`
+}
+
+type jsonMessage struct {
+	Severity string `json:"severity"`
+	Text     string `json:"text"`
+	Hint     string `json:"hint,omitempty"`
+}
+
+// WriteJSON writes the totals and one object per file, using the CSV column names as keys
+func WriteJSON(w io.Writer, results []FileResult) error {
+	t := Summarize(results)
+
+	doc := jsonReport{
+		Totals: jsonTotals{
+			Files:           t.Files,
+			Passed:          t.Passed,
+			Failed:          t.Failed,
+			Errors:          t.Errors,
+			Warnings:        t.Warnings,
+			Notices:         t.Notices,
+			DurationSeconds: t.Duration.Seconds(),
+		},
+		Results: make([]jsonResult, len(results)),
+	}
+
+	for i, r := range results {
+		doc.Results[i] = jsonResult{
+			File:            r.File,
+			Status:          r.Status,
+			Errors:          r.Errors,
+			Warnings:        r.Warnings,
+			Notices:         r.Notices,
+			DurationSeconds: r.Duration.Seconds(),
+			Message:         r.Message,
+			Git:             r.Git,
+		}
+
+		for _, m := range r.Messages {
+			doc.Results[i].Messages = append(doc.Results[i].Messages, jsonMessage(m))
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(doc)
+}
@@ -0,0 +1,45 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/gitinfo"
+)
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	results := append([]FileResult{}, sampleResults...)
+	results[0].Git = &gitinfo.Info{Commit: "1a2b3c", Branch: "main"}
+	results[1].Messages = []Message{{Severity: SeverityError, Text: "ERROR Type mismatch", Hint: "hint: convert explicitly"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, results))
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	totals := doc["totals"].(map[string]any)
+	assert.InDelta(t, 2, totals["files"], 0)
+	assert.InDelta(t, 1, totals["failed"], 0)
+	assert.InDelta(t, 57.3, totals["duration_seconds"], 0.001)
+
+	files := doc["results"].([]any)
+	require.Len(t, files, 2)
+
+	lobby := files[0].(map[string]any)
+	assert.Equal(t, `C:\jobs\lobby.smw`, lobby["file"])
+	assert.NotContains(t, lobby, "message")
+	assert.Equal(t, map[string]any{"commit": "1a2b3c", "branch": "main", "dirty": false}, lobby["git"])
+
+	theater := files[1].(map[string]any)
+	assert.Equal(t, "compilation failed with 3 error(s)", theater["message"])
+	assert.NotContains(t, theater, "git")
+	assert.Equal(t, []any{map[string]any{"severity": "error", "text": "ERROR Type mismatch", "hint": "hint: convert explicitly"}}, theater["messages"])
+	assert.NotContains(t, lobby, "messages")
+}
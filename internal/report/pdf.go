@@ -0,0 +1,219 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Page layout of the PDF report, in points (A4 portrait)
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+	pdfLineHeight = 14
+	pdfFontSize   = 10
+
+	// Longest file name or message shown before it is shortened
+	pdfMaxFileChars    = 44
+	pdfMaxMessageChars = 95
+)
+
+// pdfColumns are the x positions of the results table columns
+var pdfColumns = []struct {
+	title string
+	x     int
+}{
+	{"File", pdfMargin},
+	{"Status", 300},
+	{"Errors", 350},
+	{"Warnings", 395},
+	{"Notices", 450},
+	{"Duration", 500},
+}
+
+// pdfNow is when the report says it was generated; replaced in tests
+var pdfNow = time.Now
+
+// WritePDF writes a printable build report with the results table, failure details
+// and sign-off lines. It uses the standard Helvetica fonts, so characters outside
+// printable ASCII are shown as "?".
+func WritePDF(w io.Writer, results []FileResult) error {
+	doc := &pdfDocument{}
+	doc.newPage()
+
+	doc.text(pdfMargin, "F2", 16, "smpc build report")
+	doc.line += 6
+	doc.text(pdfMargin, "F1", pdfFontSize, "Generated "+pdfNow().Format("2006-01-02 15:04:05 MST"))
+
+	t := Summarize(results)
+	doc.text(pdfMargin, "F1", pdfFontSize, fmt.Sprintf(
+		"%d file(s): %d passed, %d failed, %d error(s), %d warning(s), %d notice(s) in %s",
+		t.Files, t.Passed, t.Failed, t.Errors, t.Warnings, t.Notices, formatDuration(t.Duration)))
+	doc.line += pdfLineHeight
+
+	doc.tableHeader()
+
+	for _, r := range results {
+		doc.ensureRoom(1)
+		doc.row(
+			shorten(DisplayName(r.File), pdfMaxFileChars), r.Status,
+			fmt.Sprint(r.Errors), fmt.Sprint(r.Warnings), fmt.Sprint(r.Notices), formatDuration(r.Duration),
+		)
+
+		if r.Git != nil {
+			dirty := ""
+			if r.Git.Dirty {
+				dirty = " (uncommitted changes)"
+			}
+
+			doc.text(pdfMargin+10, "F1", pdfFontSize-2, fmt.Sprintf("%s @ %s%s", r.Git.Branch, r.Git.Commit, dirty))
+		}
+
+		if r.Message != "" {
+			doc.text(pdfMargin+10, "F1", pdfFontSize-2, shorten(r.Message, pdfMaxMessageChars))
+		}
+	}
+
+	doc.ensureRoom(6)
+	doc.line += 2 * pdfLineHeight
+
+	for _, label := range []string{"Approved by", "Signature", "Date"} {
+		doc.text(pdfMargin, "F1", pdfFontSize, label+": ______________________________")
+		doc.line += pdfLineHeight / 2
+	}
+
+	return doc.write(w)
+}
+
+// shorten truncates s to n characters, marking the cut with "..."
+func shorten(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n-3] + "..."
+}
+
+// pdfDocument accumulates the content streams of the report's pages
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	line  int // Distance of the next line below the top margin
+}
+
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.line = 0
+}
+
+// ensureRoom starts a new page, repeating the table header, unless n more lines fit
+func (d *pdfDocument) ensureRoom(n int) {
+	if pdfMargin+d.line+n*pdfLineHeight <= pdfPageHeight-pdfMargin {
+		return
+	}
+
+	d.newPage()
+	d.tableHeader()
+}
+
+func (d *pdfDocument) tableHeader() {
+	for _, c := range pdfColumns {
+		d.textAt(c.x, "F2", pdfFontSize, c.title)
+	}
+
+	d.line += pdfLineHeight
+}
+
+func (d *pdfDocument) row(cells ...string) {
+	for i, c := range pdfColumns {
+		d.textAt(c.x, "F1", pdfFontSize, cells[i])
+	}
+
+	d.line += pdfLineHeight
+}
+
+// text writes one line and moves down
+func (d *pdfDocument) text(x int, font string, size int, s string) {
+	d.ensureRoomFor(size)
+	d.textAt(x, font, size, s)
+	d.line += max(size+4, pdfLineHeight)
+}
+
+func (d *pdfDocument) ensureRoomFor(size int) {
+	if pdfMargin+d.line+size > pdfPageHeight-pdfMargin {
+		d.newPage()
+	}
+}
+
+// textAt writes s on the current line without moving down
+func (d *pdfDocument) textAt(x int, font string, size int, s string) {
+	y := pdfPageHeight - pdfMargin - d.line - size
+	fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, x, y, pdfEscape(s))
+}
+
+// pdfEscape makes s safe inside a PDF literal string
+func pdfEscape(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < ' ' || r > '~':
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// write serializes the document: catalog, page tree, fonts, then a page and content
+// stream per page, followed by the cross-reference table
+func (d *pdfDocument) write(w io.Writer) error {
+	var (
+		out     bytes.Buffer
+		offsets []int
+	)
+
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	const firstPage = 5 // Objects 1-4 are the catalog, page tree and fonts
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, content := range d.pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := out.WriteTo(w)
+	return err
+}
@@ -0,0 +1,64 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Not parallel: replaces pdfNow
+func TestWritePDF(t *testing.T) {
+	pdfNow = func() time.Time { return time.Date(2025, 1, 2, 10, 30, 0, 0, time.UTC) }
+	defer func() { pdfNow = time.Now }()
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, sampleResults))
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(out, "%%EOF\n"))
+	assert.Contains(t, out, "(Generated 2025-01-02 10:30:00 UTC) Tj")
+	assert.Contains(t, out, "(theater.smw) Tj")
+	assert.Contains(t, out, "(compilation failed with 3 error\\(s\\)) Tj")
+	assert.Contains(t, out, "(Approved by: ")
+
+	// Every xref entry must point at its object
+	xref, err := strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(out)[1])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(out[xref:], "xref\n"))
+
+	for i, m := range regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(out[xref:], -1) {
+		off, _ := strconv.Atoi(m[1])
+		assert.True(t, strings.HasPrefix(out[off:], fmt.Sprintf("%d 0 obj", i+1)), "object %d", i+1)
+	}
+}
+
+func TestWritePDFPages(t *testing.T) {
+	t.Parallel()
+
+	results := make([]FileResult, 120)
+	for i := range results {
+		results[i] = FileResult{File: fmt.Sprintf("program%03d.smw", i), Status: StatusPassed}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, results))
+
+	out := buf.String()
+	assert.Contains(t, out, "/Count 3 ")
+	assert.Equal(t, 3, strings.Count(out, "(File) Tj"), "the table header is repeated on each page")
+}
+
+func TestPDFEscape(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `a\(b\) c\\d ?`, pdfEscape("a(b) c\\d é"))
+	assert.Equal(t, "abc...", shorten("abcdefgh", 6))
+}
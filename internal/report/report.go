@@ -0,0 +1,108 @@
+// Package report renders per-file compilation results for humans and tools.
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/gitinfo"
+)
+
+// Status values for a FileResult
+const (
+	StatusPassed = "passed"
+	StatusFailed = "failed"
+)
+
+// FileResult is the outcome of compiling a single program
+type FileResult struct {
+	File     string
+	Status   string
+	Errors   int
+	Warnings int
+	Notices  int
+	Duration time.Duration
+	Message  string        // Failure reason when the compile did not complete
+	Git      *gitinfo.Info // Source revision, when the program is in a git repository
+	Messages []Message     // Compiler messages, in the order errors, warnings, notices
+}
+
+// Severity values for a Message
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNotice  = "notice"
+)
+
+// Message is one compiler message, e.g. "WARNING (LGCMCVT102) ** Signal foo has no driving source"
+type Message struct {
+	Severity string
+	Text     string
+	Hint     string // Explanation from the hints knowledge base, e.g. "hint: ...; fix: ..."
+}
+
+// Totals aggregates a set of results
+type Totals struct {
+	Files    int
+	Passed   int
+	Failed   int
+	Errors   int
+	Warnings int
+	Notices  int
+	Duration time.Duration
+}
+
+// Summarize computes the totals for results
+func Summarize(results []FileResult) Totals {
+	t := Totals{Files: len(results)}
+
+	for _, r := range results {
+		if r.Status == StatusPassed {
+			t.Passed++
+		} else {
+			t.Failed++
+		}
+
+		t.Errors += r.Errors
+		t.Warnings += r.Warnings
+		t.Notices += r.Notices
+		t.Duration += r.Duration
+	}
+
+	return t
+}
+
+// WriteTable writes an aligned summary table with a totals row
+func WriteTable(w io.Writer, results []FileResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "FILE\tSTATUS\tERRORS\tWARNINGS\tNOTICES\tDURATION")
+
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\n",
+			DisplayName(r.File), r.Status, r.Errors, r.Warnings, r.Notices, formatDuration(r.Duration))
+	}
+
+	t := Summarize(results)
+	fmt.Fprintf(tw, "TOTAL (%d passed, %d failed)\t\t%d\t%d\t%d\t%s\n",
+		t.Passed, t.Failed, t.Errors, t.Warnings, t.Notices, formatDuration(t.Duration))
+
+	return tw.Flush()
+}
+
+// formatDuration renders a duration with one decimal second, e.g. "42.3s"
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// DisplayName returns the file name without its directory, for either separator
+func DisplayName(path string) string {
+	if i := strings.LastIndexAny(path, `\/`); i >= 0 {
+		return path[i+1:]
+	}
+
+	return path
+}
@@ -0,0 +1,51 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleResults is shared by the report format tests
+var sampleResults = []FileResult{
+	{File: `C:\jobs\lobby.smw`, Status: StatusPassed, Warnings: 2, Notices: 1, Duration: 42300 * time.Millisecond},
+	{File: `C:\jobs\theater.smw`, Status: StatusFailed, Errors: 3, Duration: 15 * time.Second, Message: "compilation failed with 3 error(s)"},
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	totals := Summarize(sampleResults)
+
+	assert.Equal(t, Totals{
+		Files:    2,
+		Passed:   1,
+		Failed:   1,
+		Errors:   3,
+		Warnings: 2,
+		Notices:  1,
+		Duration: 57300 * time.Millisecond,
+	}, totals)
+}
+
+func TestWriteTable(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTable(&buf, sampleResults))
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 4, "Header, one row per file and a totals row")
+
+	assert.Regexp(t, `^FILE\s+STATUS\s+ERRORS\s+WARNINGS\s+NOTICES\s+DURATION$`, lines[0])
+	assert.Regexp(t, `^lobby\.smw\s+passed\s+0\s+2\s+1\s+42\.3s$`, lines[1])
+	assert.Regexp(t, `^theater\.smw\s+failed\s+3\s+0\s+0\s+15\.0s$`, lines[2])
+	assert.Regexp(t, `^TOTAL \(1 passed, 1 failed\)\s+3\s+2\s+1\s+57\.3s$`, lines[3])
+
+	// Columns must line up
+	assert.Equal(t, strings.Index(lines[0], "STATUS"), strings.Index(lines[1], "passed"))
+}
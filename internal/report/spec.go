@@ -0,0 +1,78 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// StdoutPath is the --report path that writes to standard output
+const StdoutPath = "-"
+
+// writers maps each --report format to its renderer
+var writers = map[string]func(io.Writer, []FileResult) error{
+	"codequality": WriteCodeQuality,
+	"csv":         WriteCSV,
+	"json":        WriteJSON,
+	"pdf":         WritePDF,
+	"tap":         WriteTAP,
+}
+
+// Spec is a parsed --report value of the form format=path
+type Spec struct {
+	Format string
+	Path   string
+}
+
+// ParseSpec parses a --report value such as "csv=results.csv"
+func ParseSpec(s string) (Spec, error) {
+	format, path, ok := strings.Cut(s, "=")
+	format = strings.ToLower(strings.TrimSpace(format))
+
+	if !ok || path == "" {
+		return Spec{}, fmt.Errorf("invalid report %q: expected <format>=<path>", s)
+	}
+
+	if _, known := writers[format]; !known {
+		return Spec{}, fmt.Errorf("unsupported report format %q (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+
+	return Spec{Format: format, Path: path}, nil
+}
+
+// Formats returns the supported report formats, sorted
+func Formats() []string {
+	formats := make([]string, 0, len(writers))
+	for f := range writers {
+		formats = append(formats, f)
+	}
+
+	sort.Strings(formats)
+	return formats
+}
+
+// WriteFile renders results in the spec's format to its path ("-" writes to stdout)
+func WriteFile(spec Spec, results []FileResult) error {
+	write, ok := writers[spec.Format]
+	if !ok {
+		return fmt.Errorf("unsupported report format %q", spec.Format)
+	}
+
+	if spec.Path == StdoutPath {
+		return write(os.Stdout, results)
+	}
+
+	f, err := os.Create(spec.Path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s report: %w", spec.Format, err)
+	}
+
+	if err := write(f, results); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write %s report: %w", spec.Format, err)
+	}
+
+	return f.Close()
+}
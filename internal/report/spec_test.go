@@ -0,0 +1,34 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSpec(t *testing.T) {
+	t.Parallel()
+
+	spec, err := ParseSpec(`CSV=C:\reports\results.csv`)
+	require.NoError(t, err)
+	assert.Equal(t, Spec{Format: "csv", Path: `C:\reports\results.csv`}, spec)
+
+	for _, invalid := range []string{"csv", "csv=", "=out.csv", "xml=out.xml"} {
+		_, err := ParseSpec(invalid)
+		assert.Error(t, err, "ParseSpec(%q) should fail", invalid)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "results.csv")
+	require.NoError(t, WriteFile(Spec{Format: "csv", Path: path}, sampleResults))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "lobby.smw")
+}
@@ -0,0 +1,50 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteTAP writes a TAP version 13 stream with one test point per file.
+// Failed files carry a YAML diagnostic block with the failure details.
+func WriteTAP(w io.Writer, results []FileResult) error {
+	if _, err := fmt.Fprintf(w, "TAP version 13\n1..%d\n", len(results)); err != nil {
+		return err
+	}
+
+	for i, r := range results {
+		status := "ok"
+		if r.Status != StatusPassed {
+			status = "not ok"
+		}
+
+		if _, err := fmt.Fprintf(w, "%s %d - %s\n", status, i+1, DisplayName(r.File)); err != nil {
+			return err
+		}
+
+		if r.Status == StatusPassed {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w,
+			"  ---\n  message: %s\n  file: %s\n  errors: %d\n  warnings: %d\n  notices: %d\n  duration_ms: %d\n",
+			strconv.Quote(r.Message), strconv.Quote(r.File), r.Errors, r.Warnings, r.Notices, r.Duration.Milliseconds(),
+		); err != nil {
+			return err
+		}
+
+		if r.Git != nil {
+			if _, err := fmt.Fprintf(w, "  commit: %s\n  branch: %s\n  dirty: %t\n",
+				strconv.Quote(r.Git.Commit), strconv.Quote(r.Git.Branch), r.Git.Dirty); err != nil {
+				return err
+			}
+		}
+
+		if _, err := io.WriteString(w, "  ...\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
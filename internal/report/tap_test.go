@@ -0,0 +1,45 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/gitinfo"
+)
+
+func TestWriteTAP(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTAP(&buf, sampleResults))
+
+	expected := `TAP version 13
+1..2
+ok 1 - lobby.smw
+not ok 2 - theater.smw
+  ---
+  message: "compilation failed with 3 error(s)"
+  file: "C:\\jobs\\theater.smw"
+  errors: 3
+  warnings: 0
+  notices: 0
+  duration_ms: 15000
+  ...
+`
+
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteTAPGit(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTAP(&buf, []FileResult{
+		{File: "theater.smw", Status: StatusFailed, Git: &gitinfo.Info{Commit: "1a2b3c", Branch: "main"}},
+	}))
+
+	assert.Contains(t, buf.String(), "  duration_ms: 0\n  commit: \"1a2b3c\"\n  branch: \"main\"\n  dirty: false\n  ...\n")
+}
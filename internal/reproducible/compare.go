@@ -0,0 +1,203 @@
+// Package reproducible compares the outputs of two compiles of the same program to
+// tell whether the toolchain produces identical results.
+package reproducible
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// Comparison outcomes for one output file
+const (
+	StatusIdentical  = "identical"  // Byte-for-byte the same
+	StatusEquivalent = "equivalent" // Differs only in embedded timestamps
+	StatusDifferent  = "different"
+	StatusMissing    = "missing" // Produced by only one of the builds
+)
+
+// FileDiff is the comparison of one output between two builds
+type FileDiff struct {
+	Path   string // Relative to the build directory
+	Status string
+	HashA  string // SHA-256 in the first build ("" if missing)
+	HashB  string // SHA-256 in the second build ("" if missing)
+}
+
+// Result is the comparison of two builds
+type Result struct {
+	Files []FileDiff
+}
+
+// Reproducible reports whether every output is identical or equivalent
+func (r Result) Reproducible() bool {
+	for _, f := range r.Files {
+		if f.Status == StatusDifferent || f.Status == StatusMissing {
+			return false
+		}
+	}
+
+	return true
+}
+
+// timestampPatterns match date and time text a compiler may embed in its output
+var timestampPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:?\d{2})?`),
+	regexp.MustCompile(`\d{1,2}/\d{1,2}/\d{2,4}`),
+	regexp.MustCompile(`\d{1,2}:\d{2}(:\d{2})?( ?[AaPp][Mm])?`),
+	regexp.MustCompile(`(Mon|Tue|Wed|Thu|Fri|Sat|Sun)[a-z]*,? +(Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)[a-z]* +\d{1,2}`),
+}
+
+// Compare compares the files produced by two builds. filesA and filesB are paths
+// inside dirA and dirB.
+func Compare(dirA string, filesA []string, dirB string, filesB []string) (Result, error) {
+	relA, err := relativeSet(dirA, filesA)
+	if err != nil {
+		return Result{}, err
+	}
+
+	relB, err := relativeSet(dirB, filesB)
+	if err != nil {
+		return Result{}, err
+	}
+
+	paths := make(map[string]bool, len(relA))
+	for rel := range relA {
+		paths[rel] = true
+	}
+
+	for rel := range relB {
+		paths[rel] = true
+	}
+
+	var result Result
+
+	for rel := range paths {
+		diff := FileDiff{Path: filepath.ToSlash(rel)}
+
+		var a, b []byte
+
+		if relA[rel] {
+			if a, err = os.ReadFile(filepath.Join(dirA, rel)); err != nil {
+				return Result{}, err
+			}
+
+			diff.HashA = hash(a)
+		}
+
+		if relB[rel] {
+			if b, err = os.ReadFile(filepath.Join(dirB, rel)); err != nil {
+				return Result{}, err
+			}
+
+			diff.HashB = hash(b)
+		}
+
+		switch {
+		case !relA[rel] || !relB[rel]:
+			diff.Status = StatusMissing
+		case diff.HashA == diff.HashB:
+			diff.Status = StatusIdentical
+		case equivalent(a, b):
+			diff.Status = StatusEquivalent
+		default:
+			diff.Status = StatusDifferent
+		}
+
+		result.Files = append(result.Files, diff)
+	}
+
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].Path < result.Files[j].Path
+	})
+
+	return result, nil
+}
+
+// relativeSet converts paths inside dir to a set of relative paths
+func relativeSet(dir string, files []string) (map[string]bool, error) {
+	set := make(map[string]bool, len(files))
+
+	for _, f := range files {
+		rel, err := filepath.Rel(dir, f)
+		if err != nil || !filepath.IsLocal(rel) {
+			return nil, fmt.Errorf("%s is not inside %s", f, dir)
+		}
+
+		set[rel] = true
+	}
+
+	return set, nil
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// equivalent reports whether a and b differ only in embedded timestamps: zip archives
+// (such as .lpz files) are compared entry by entry, ignoring entry dates, and other
+// files with date and time text masked out
+func equivalent(a, b []byte) bool {
+	if entriesA, ok := zipEntries(a); ok {
+		entriesB, ok := zipEntries(b)
+		if !ok || len(entriesA) != len(entriesB) {
+			return false
+		}
+
+		for name, dataA := range entriesA {
+			dataB, ok := entriesB[name]
+			if !ok || (!bytes.Equal(dataA, dataB) && !equivalent(dataA, dataB)) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return bytes.Equal(maskTimestamps(a), maskTimestamps(b))
+}
+
+// zipEntries returns the contents of each entry if data is a zip archive
+func zipEntries(data []byte) (map[string][]byte, bool) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, false
+	}
+
+	entries := make(map[string][]byte, len(zr.File))
+
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, false
+		}
+
+		content, err := io.ReadAll(rc)
+		rc.Close()
+
+		if err != nil {
+			return nil, false
+		}
+
+		entries[f.Name] = content
+	}
+
+	return entries, true
+}
+
+// maskTimestamps replaces date and time text with a fixed marker
+func maskTimestamps(data []byte) []byte {
+	for _, re := range timestampPatterns {
+		data = re.ReplaceAll(data, []byte("<timestamp>"))
+	}
+
+	return data
+}
@@ -0,0 +1,108 @@
+package reproducible
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBuild writes files (relative path -> content) into a new directory
+func writeBuild(t *testing.T, files map[string][]byte) (string, []string) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	var paths []string
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, content, 0o644))
+		paths = append(paths, path)
+	}
+
+	return dir, paths
+}
+
+// lpz builds a zip archive whose entries carry the given modification time
+func lpz(t *testing.T, modified time.Time, entries map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		require.NoError(t, err)
+
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	first := time.Date(2026, 3, 9, 14, 5, 7, 0, time.UTC)
+	second := first.Add(3 * time.Minute)
+
+	dirA, filesA := writeBuild(t, map[string][]byte{
+		"Lobby.lpz":           lpz(t, first, map[string]string{"Lobby.bin": "code", "Lobby.inf": "Built 3/9/2026 2:05:07 PM"}),
+		"Lobby.sig":           []byte("signals"),
+		"SPlsWork/Helper.dll": []byte("dll v1"),
+		"Lobby.log":           []byte("only in first"),
+	})
+
+	dirB, filesB := writeBuild(t, map[string][]byte{
+		"Lobby.lpz":           lpz(t, second, map[string]string{"Lobby.bin": "code", "Lobby.inf": "Built 3/9/2026 2:08:07 PM"}),
+		"Lobby.sig":           []byte("signals"),
+		"SPlsWork/Helper.dll": []byte("dll v2"),
+	})
+
+	result, err := Compare(dirA, filesA, dirB, filesB)
+	require.NoError(t, err)
+
+	statuses := map[string]string{}
+	for _, f := range result.Files {
+		statuses[f.Path] = f.Status
+	}
+
+	assert.Equal(t, map[string]string{
+		"Lobby.log":           StatusMissing,
+		"Lobby.lpz":           StatusEquivalent,
+		"Lobby.sig":           StatusIdentical,
+		"SPlsWork/Helper.dll": StatusDifferent,
+	}, statuses)
+
+	assert.Equal(t, "Lobby.log", result.Files[0].Path, "sorted by path")
+	assert.Empty(t, result.Files[0].HashB)
+	assert.False(t, result.Reproducible())
+}
+
+func TestCompare_Reproducible(t *testing.T) {
+	t.Parallel()
+
+	dirA, filesA := writeBuild(t, map[string][]byte{
+		"Lobby.lpz": []byte("compiled at 2026-03-09T14:05:07Z"),
+	})
+	dirB, filesB := writeBuild(t, map[string][]byte{
+		"Lobby.lpz": []byte("compiled at 2026-03-09T14:08:07Z"),
+	})
+
+	result, err := Compare(dirA, filesA, dirB, filesB)
+	require.NoError(t, err)
+
+	require.Len(t, result.Files, 1)
+	assert.Equal(t, StatusEquivalent, result.Files[0].Status)
+	assert.NotEqual(t, result.Files[0].HashA, result.Files[0].HashB)
+	assert.True(t, result.Reproducible())
+}
@@ -0,0 +1,127 @@
+// Package schedule builds Windows Task Scheduler definitions for recurring compiles.
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Cron is the subset of a five-field cron expression that maps onto a Task Scheduler
+// calendar trigger: a fixed time of day, every day or on selected days of the week
+type Cron struct {
+	Minute   int
+	Hour     int
+	Weekdays []int // 0 = Sunday; empty means every day
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ParseCron parses "minute hour day-of-month month day-of-week", such as "30 2 * * *"
+// or "0 22 * * mon-fri". Minute and hour must be single values, day-of-month and month
+// must be "*", and day-of-week may be "*", a list, a range or a mix of them.
+func ParseCron(expr string) (Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Cron{}, fmt.Errorf("invalid cron %q: expected 5 fields", expr)
+	}
+
+	minute, err := cronNumber(fields[0], 0, 59)
+	if err != nil {
+		return Cron{}, fmt.Errorf("invalid cron minute %q: %w", fields[0], err)
+	}
+
+	hour, err := cronNumber(fields[1], 0, 23)
+	if err != nil {
+		return Cron{}, fmt.Errorf("invalid cron hour %q: %w", fields[1], err)
+	}
+
+	if fields[2] != "*" || fields[3] != "*" {
+		return Cron{}, fmt.Errorf("unsupported cron %q: day-of-month and month must be *", expr)
+	}
+
+	weekdays, err := cronWeekdays(fields[4])
+	if err != nil {
+		return Cron{}, fmt.Errorf("invalid cron day-of-week %q: %w", fields[4], err)
+	}
+
+	return Cron{Minute: minute, Hour: hour, Weekdays: weekdays}, nil
+}
+
+// cronNumber parses a single numeric field within [lo, hi]
+func cronNumber(field string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(field)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number")
+	}
+
+	if n < lo || n > hi {
+		return 0, fmt.Errorf("must be between %d and %d", lo, hi)
+	}
+
+	return n, nil
+}
+
+// cronWeekdays parses a day-of-week field into sorted, distinct days (0 = Sunday)
+func cronWeekdays(field string) ([]int, error) {
+	if field == "*" {
+		return nil, nil
+	}
+
+	seen := make(map[int]bool)
+
+	for _, part := range strings.Split(field, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+
+		start, err := cronWeekday(from)
+		if err != nil {
+			return nil, err
+		}
+
+		end := start
+		if isRange {
+			if end, err = cronWeekday(to); err != nil {
+				return nil, err
+			}
+		}
+
+		if end < start {
+			return nil, fmt.Errorf("range %q runs backwards", part)
+		}
+
+		for d := start; d <= end; d++ {
+			seen[d%7] = true
+		}
+	}
+
+	days := make([]int, 0, len(seen))
+	for d := range seen {
+		days = append(days, d)
+	}
+
+	sort.Ints(days)
+
+	// Every day of the week is the same as *
+	if len(days) == 7 {
+		return nil, nil
+	}
+
+	return days, nil
+}
+
+// cronWeekday parses a day number (0-7, where both 0 and 7 are Sunday) or three-letter name
+func cronWeekday(s string) (int, error) {
+	for i, name := range weekdayNames {
+		if strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+
+	n, err := cronNumber(s, 0, 7)
+	if err != nil {
+		return 0, fmt.Errorf("%q: %w", s, err)
+	}
+
+	return n, nil
+}
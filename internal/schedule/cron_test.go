@@ -0,0 +1,46 @@
+package schedule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expr string
+		want Cron
+	}{
+		{expr: "30 2 * * *", want: Cron{Minute: 30, Hour: 2}},
+		{expr: "0 22 * * mon-fri", want: Cron{Hour: 22, Weekdays: []int{1, 2, 3, 4, 5}}},
+		{expr: "15 6 * * 0,6", want: Cron{Minute: 15, Hour: 6, Weekdays: []int{0, 6}}},
+		{expr: "0 1 * * 5-7", want: Cron{Hour: 1, Weekdays: []int{0, 5, 6}}},
+		{expr: "0 1 * * 0-6", want: Cron{Hour: 1}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCron(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.want, got, tt.expr)
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{
+		"30 2 * *",    // Too few fields
+		"*/5 2 * * *", // Steps are not supported
+		"30 24 * * *", // Hour out of range
+		"30 2 1 * *",  // Day of month
+		"30 2 * 6 *",  // Month
+		"30 2 * * fri-mon",
+		"30 2 * * funday",
+	} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
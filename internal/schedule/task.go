@@ -0,0 +1,164 @@
+package schedule
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+	"unicode/utf16"
+)
+
+// Folder is the Task Scheduler folder that holds smpc tasks
+const Folder = `\smpc\`
+
+// maxActions is the most actions Task Scheduler allows in one task
+const maxActions = 32
+
+// Action is a program the task runs
+type Action struct {
+	Command          string
+	Arguments        string
+	WorkingDirectory string
+}
+
+// Task describes a scheduled compile
+type Task struct {
+	Description string
+	Cron        Cron
+	Start       time.Time // Date the trigger becomes active; only the date is used
+	Actions     []Action  // Run one after another, e.g. one per program
+}
+
+// taskXML mirrors the parts of the Task Scheduler 1.2 schema that smpc uses
+type taskXML struct {
+	XMLName     xml.Name     `xml:"Task"`
+	Version     string       `xml:"version,attr"`
+	Xmlns       string       `xml:"xmlns,attr"`
+	Description string       `xml:"RegistrationInfo>Description"`
+	Trigger     triggerXML   `xml:"Triggers>CalendarTrigger"`
+	Principal   principalXML `xml:"Principals>Principal"`
+	Settings    settingsXML  `xml:"Settings"`
+	Exec        []execXML    `xml:"Actions>Exec"`
+}
+
+type triggerXML struct {
+	StartBoundary  string         `xml:"StartBoundary"`
+	Enabled        bool           `xml:"Enabled"`
+	ScheduleByDay  *scheduleByDay `xml:"ScheduleByDay,omitempty"`
+	ScheduleByWeek *scheduleByWk  `xml:"ScheduleByWeek,omitempty"`
+}
+
+type scheduleByDay struct {
+	DaysInterval int `xml:"DaysInterval"`
+}
+
+type scheduleByWk struct {
+	WeeksInterval int        `xml:"WeeksInterval"`
+	DaysOfWeek    daysOfWeek `xml:"DaysOfWeek"`
+}
+
+type daysOfWeek []string
+
+// MarshalXML writes one empty element per selected day, e.g. <Monday></Monday>
+func (d daysOfWeek) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, day := range d {
+		if err := e.EncodeElement("", xml.StartElement{Name: xml.Name{Local: day}}); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+type principalXML struct {
+	ID        string `xml:"id,attr"`
+	LogonType string `xml:"LogonType"`
+	RunLevel  string `xml:"RunLevel"`
+}
+
+type settingsXML struct {
+	MultipleInstancesPolicy string `xml:"MultipleInstancesPolicy"`
+	StartWhenAvailable      bool   `xml:"StartWhenAvailable"`
+	ExecutionTimeLimit      string `xml:"ExecutionTimeLimit"`
+}
+
+type execXML struct {
+	Command          string `xml:"Command"`
+	Arguments        string `xml:"Arguments,omitempty"`
+	WorkingDirectory string `xml:"WorkingDirectory,omitempty"`
+}
+
+var taskWeekdays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// XML renders the task definition accepted by "schtasks /Create /XML". The task runs
+// only while the user is logged on (SIMPL Windows needs an interactive desktop) and
+// with the highest privileges available, so smpc does not trigger a UAC prompt.
+func (t Task) XML() ([]byte, error) {
+	if len(t.Actions) == 0 {
+		return nil, fmt.Errorf("task has no actions")
+	}
+
+	if len(t.Actions) > maxActions {
+		return nil, fmt.Errorf("task has %d actions; Task Scheduler allows at most %d", len(t.Actions), maxActions)
+	}
+
+	start := time.Date(t.Start.Year(), t.Start.Month(), t.Start.Day(), t.Cron.Hour, t.Cron.Minute, 0, 0, time.Local)
+
+	trigger := triggerXML{
+		StartBoundary: start.Format("2006-01-02T15:04:05"),
+		Enabled:       true,
+	}
+
+	if len(t.Cron.Weekdays) == 0 {
+		trigger.ScheduleByDay = &scheduleByDay{DaysInterval: 1}
+	} else {
+		week := &scheduleByWk{WeeksInterval: 1}
+		for _, d := range t.Cron.Weekdays {
+			week.DaysOfWeek = append(week.DaysOfWeek, taskWeekdays[d])
+		}
+
+		trigger.ScheduleByWeek = week
+	}
+
+	doc := taskXML{
+		Version:     "1.2",
+		Xmlns:       "http://schemas.microsoft.com/windows/2004/02/mit/task",
+		Description: t.Description,
+		Trigger:     trigger,
+		Principal: principalXML{
+			ID:        "Author",
+			LogonType: "InteractiveToken",
+			RunLevel:  "HighestAvailable",
+		},
+		Settings: settingsXML{
+			MultipleInstancesPolicy: "IgnoreNew",
+			StartWhenAvailable:      true,
+			ExecutionTimeLimit:      "PT0S", // No limit; smpc enforces its own timeouts
+		},
+	}
+
+	for _, a := range t.Actions {
+		doc.Exec = append(doc.Exec, execXML(a))
+	}
+
+	return xml.MarshalIndent(doc, "", "  ")
+}
+
+// EncodeUTF16 returns an XML document as UTF-16LE with a byte order mark and a matching
+// declaration, the encoding schtasks expects for /XML files
+func EncodeUTF16(doc []byte) []byte {
+	text := []rune(`<?xml version="1.0" encoding="UTF-16"?>` + "\n" + string(doc))
+	units := utf16.Encode(text)
+
+	out := make([]byte, 0, 2+len(units)*2)
+	out = append(out, 0xFF, 0xFE)
+
+	for _, u := range units {
+		out = append(out, byte(u), byte(u>>8))
+	}
+
+	return out
+}
@@ -0,0 +1,69 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskXML(t *testing.T) {
+	t.Parallel()
+
+	task := Task{
+		Description: "Nightly compile",
+		Cron:        Cron{Minute: 30, Hour: 2, Weekdays: []int{1, 5}},
+		Start:       time.Date(2025, 3, 4, 17, 45, 0, 0, time.Local),
+		Actions: []Action{
+			{Command: `C:\Tools\smpc.exe`, Arguments: `"C:\Programs\Lobby.smw"`, WorkingDirectory: `C:\Programs`},
+			{Command: `C:\Tools\smpc.exe`, Arguments: `C:\Programs\Boardroom.smw`},
+		},
+	}
+
+	doc, err := task.XML()
+	require.NoError(t, err)
+
+	xml := string(doc)
+	assert.Contains(t, xml, `<StartBoundary>2025-03-04T02:30:00</StartBoundary>`)
+	assert.Contains(t, xml, `<Monday></Monday>`)
+	assert.Contains(t, xml, `<Friday></Friday>`)
+	assert.NotContains(t, xml, `<Sunday>`)
+	assert.Contains(t, xml, `<LogonType>InteractiveToken</LogonType>`)
+	assert.Contains(t, xml, `<RunLevel>HighestAvailable</RunLevel>`)
+	assert.Equal(t, 2, strings.Count(xml, "<Exec>"))
+	assert.Contains(t, xml, `<WorkingDirectory>C:\Programs</WorkingDirectory>`)
+}
+
+func TestTaskXML_Daily(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Task{
+		Cron:    Cron{Hour: 23},
+		Actions: []Action{{Command: "smpc.exe"}},
+	}.XML()
+	require.NoError(t, err)
+
+	assert.Contains(t, string(doc), `<DaysInterval>1</DaysInterval>`)
+	assert.NotContains(t, string(doc), `ScheduleByWeek`)
+}
+
+func TestTaskXML_ActionLimits(t *testing.T) {
+	t.Parallel()
+
+	_, err := Task{}.XML()
+	assert.Error(t, err)
+
+	_, err = Task{Actions: make([]Action, maxActions+1)}.XML()
+	assert.ErrorContains(t, err, "at most 32")
+}
+
+func TestEncodeUTF16(t *testing.T) {
+	t.Parallel()
+
+	out := EncodeUTF16([]byte("<Task/>"))
+
+	assert.Equal(t, []byte{0xFF, 0xFE, '<', 0, '?', 0}, out[:6])
+	assert.Equal(t, []byte{'/', 0, '>', 0}, out[len(out)-4:])
+}
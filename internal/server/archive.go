@@ -0,0 +1,261 @@
+package server
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxExtractedSize bounds the total uncompressed size of an uploaded archive
+	maxExtractedSize = 1 << 30
+
+	// sourceAge is how far before extraction uploaded files are backdated at least,
+	// and artifactSlack how far before a job's start an output may be stamped. File
+	// timestamps come from a coarser clock than time.Now, so an output written just
+	// after the job started can appear a few milliseconds older.
+	sourceAge     = time.Minute
+	artifactSlack = time.Second
+)
+
+// WriteArchive zips a program for upload: the program and the other files in its
+// directory (user modules, SIMPL+ sources) at the root, and each include directory
+// recursively under its own name. It returns the program's path within the archive.
+func WriteArchive(w io.Writer, program string, include []string) (string, error) {
+	zw := zip.NewWriter(w)
+
+	entries, err := os.ReadDir(filepath.Dir(program))
+	if err != nil {
+		return "", fmt.Errorf("failed to read program directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		if err := addFile(zw, filepath.Join(filepath.Dir(program), entry.Name()), entry.Name()); err != nil {
+			return "", err
+		}
+	}
+
+	for _, dir := range include {
+		base := filepath.Base(filepath.Clean(dir))
+
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+
+			return addFile(zw, p, path.Join(base, filepath.ToSlash(rel)))
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to add %s: %w", dir, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+
+	return filepath.Base(program), nil
+}
+
+// addFile adds the file at src to zw as name, keeping its modification time
+func addFile(zw *zip.Writer, src, name string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+
+	header.Name = name
+	header.Method = zip.Deflate
+
+	dst, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, f)
+	return err
+}
+
+// extractArchive unpacks zr into dir. Entries that would land outside dir are rejected.
+// Files are dated at least sourceAge in the past, so compile outputs can be told apart
+// from uploaded sources by modification time.
+func extractArchive(zr *zip.Reader, dir string) error {
+	var total uint64
+
+	latest := time.Now().Add(-sourceAge)
+
+	for _, f := range zr.File {
+		name := filepath.FromSlash(f.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry %q is outside the upload", f.Name)
+		}
+
+		dst := filepath.Join(dir, name)
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(dst, 0o755); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		total += f.UncompressedSize64
+		if total > maxExtractedSize {
+			return errors.New("archive is too large")
+		}
+
+		if err := extractFile(f, dst); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+		}
+
+		modified := f.Modified
+		if modified.IsZero() || modified.After(latest) {
+			modified = latest
+		}
+
+		if err := os.Chtimes(dst, modified, modified); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractFile(f *zip.File, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+
+	defer src.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+
+	// The zip reader fails if an entry is larger than its header claims
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// artifacts returns the paths, relative to dir, of files modified by a job started at
+// started
+func artifacts(dir string, started time.Time) ([]string, error) {
+	var files []string
+
+	since := started.Add(-artifactSlack)
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.ModTime().Before(since) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan upload: %w", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// writeArtifacts zips the named files from dir to w
+func writeArtifacts(w io.Writer, dir string, files []string) error {
+	zw := zip.NewWriter(w)
+
+	for _, name := range files {
+		if err := addFile(zw, filepath.Join(dir, filepath.FromSlash(name)), name); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// ExtractArtifacts unpacks a downloaded artifact archive into dir, replacing existing
+// files, and returns the paths written
+func ExtractArtifacts(zr *zip.Reader, dir string) ([]string, error) {
+	var written []string
+
+	for _, f := range zr.File {
+		name := filepath.FromSlash(f.Name)
+		if !filepath.IsLocal(name) || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+
+		dst := filepath.Join(dir, name)
+
+		if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return written, err
+		}
+
+		if err := extractFile(f, dst); err != nil {
+			return written, fmt.Errorf("failed to extract %s: %w", f.Name, err)
+		}
+
+		if !f.Modified.IsZero() {
+			if err := os.Chtimes(dst, f.Modified, f.Modified); err != nil {
+				return written, err
+			}
+		}
+
+		written = append(written, dst)
+	}
+
+	return written, nil
+}
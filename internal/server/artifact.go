@@ -0,0 +1,239 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Kinds of Artifact
+const (
+	ArtifactOutput = "output" // A file the compile created or changed in an uploaded job
+	ArtifactLog    = "log"    // The job's console output
+)
+
+// logArtifactName is the name the job's console output is downloaded as
+const logArtifactName = "output.log"
+
+// DefaultArtifactRetention is how long uploaded jobs' outputs are kept after they finish
+const DefaultArtifactRetention = 7 * 24 * time.Hour
+
+// artifactGCInterval is how often an idle server removes expired artifacts
+const artifactGCInterval = time.Hour
+
+// Artifact is a file produced by a finished job, downloadable from
+// /api/v1/jobs/{id}/artifacts/{name}
+type Artifact struct {
+	Name   string `json:"name"` // Path relative to the program's folder, with forward slashes
+	Kind   string `json:"kind"` // ArtifactOutput or ArtifactLog
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"` // Hex-encoded hash of the content
+}
+
+// SetArtifactRetention sets how long uploaded jobs' outputs are kept after the job
+// finishes; 0 keeps them until the job itself is forgotten
+func (s *Server) SetArtifactRetention(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.retention = d
+}
+
+// jobArtifacts lists and hashes the files a finished job produced: its console output
+// and, for an uploaded job, the files its compile created or changed
+func jobArtifacts(job *Job, output string) ([]Artifact, error) {
+	var list []Artifact
+
+	if job.Upload != "" && job.Started != nil {
+		files, err := artifacts(job.Upload, *job.Started)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range files {
+			a, err := hashFile(filepath.Join(job.Upload, filepath.FromSlash(name)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash %s: %w", name, err)
+			}
+
+			a.Name = name
+			a.Kind = ArtifactOutput
+			list = append(list, a)
+		}
+	}
+
+	if output != "" {
+		sum := sha256.Sum256([]byte(output))
+		list = append(list, Artifact{
+			Name:   logArtifactName,
+			Kind:   ArtifactLog,
+			Size:   int64(len(output)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	return list, nil
+}
+
+func hashFile(path string) (Artifact, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	defer f.Close()
+
+	h := sha256.New()
+
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	return Artifact{Size: size, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// handleArtifact sends one of a finished job's artifacts, with its hash as the ETag and
+// in a Repr-Digest header so the download can be verified
+func (s *Server) handleArtifact(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.Job(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %s not found", r.PathValue("id")))
+		return
+	}
+
+	if job.Finished == nil {
+		writeError(w, http.StatusConflict, fmt.Errorf("job %s has not finished", job.ID))
+		return
+	}
+
+	name := r.PathValue("name")
+
+	var artifact *Artifact
+	for i := range job.Artifacts {
+		if job.Artifacts[i].Name == name {
+			artifact = &job.Artifacts[i]
+			break
+		}
+	}
+
+	if artifact == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %s has no artifact %s", job.ID, name))
+		return
+	}
+
+	if artifact.Kind == ArtifactOutput && job.Expired != nil {
+		writeError(w, http.StatusGone, fmt.Errorf("artifacts of job %s were removed at %s", job.ID, job.Expired.Format(time.RFC3339)))
+		return
+	}
+
+	var content io.ReadSeeker = strings.NewReader(job.Output)
+
+	if artifact.Kind == ArtifactOutput {
+		f, err := os.Open(filepath.Join(job.Upload, filepath.FromSlash(artifact.Name)))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to open artifact: %w", err))
+			return
+		}
+
+		defer f.Close()
+		content = f
+	}
+
+	sum, _ := hex.DecodeString(artifact.SHA256)
+
+	w.Header().Set("ETag", `"`+artifact.SHA256+`"`)
+	w.Header().Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(sum)+":")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filepath.Base(filepath.FromSlash(artifact.Name))))
+
+	if artifact.Kind == ArtifactLog {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+
+	http.ServeContent(w, r, artifact.Name, *job.Finished, content)
+}
+
+// collectGarbage removes the uploads of jobs that finished longer ago than the
+// retention period, and uploads no job refers to, such as those left by a crash
+func (s *Server) collectGarbage() {
+	s.mu.Lock()
+
+	root, retention := s.uploadDir, s.retention
+	if root == "" || retention <= 0 {
+		s.mu.Unlock()
+		return
+	}
+
+	now := s.clock.Now()
+	cutoff := now.Add(-retention)
+
+	var expired []string
+	inUse := make(map[string]bool)
+
+	for _, id := range s.order {
+		job := s.jobs[id]
+		if job.Upload == "" {
+			continue
+		}
+
+		inUse[filepath.Clean(job.Upload)] = true
+
+		if job.Expired == nil && job.Finished != nil && job.Finished.Before(cutoff) {
+			job.Expired = &now
+			expired = append(expired, job.Upload)
+		}
+	}
+
+	if len(expired) > 0 {
+		if err := s.saveLocked(); err != nil {
+			s.log.Error("Failed to persist job queue", slog.Any("error", err))
+		}
+	}
+
+	s.mu.Unlock()
+
+	// Uploads still being received are recent, so are never mistaken for orphans
+	entries, _ := os.ReadDir(root)
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "job-") || inUse[dir] {
+			continue
+		}
+
+		if info, err := entry.Info(); err == nil && info.ModTime().Before(cutoff) {
+			expired = append(expired, dir)
+		}
+	}
+
+	for _, dir := range expired {
+		if err := os.RemoveAll(dir); err != nil {
+			s.log.Warn("Failed to remove expired artifacts", slog.String("dir", dir), slog.Any("error", err))
+			continue
+		}
+
+		s.log.Debug("Removed expired artifacts", slog.String("dir", dir))
+	}
+
+	if len(expired) > 0 {
+		s.log.Info("Removed expired artifacts", slog.Int("count", len(expired)))
+	}
+}
+
+// removeUpload deletes a forgotten job's upload, if it has one
+func (s *Server) removeUpload(job *Job) {
+	if job.Upload == "" || job.Expired != nil {
+		return
+	}
+
+	if err := os.RemoveAll(job.Upload); err != nil {
+		s.log.Warn("Failed to remove upload", slog.String("dir", job.Upload), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+func TestServer_ArtifactDownloadsAndRetention(t *testing.T) {
+	t.Parallel()
+
+	local := t.TempDir()
+	program := filepath.Join(local, "Lobby.smw")
+	require.NoError(t, os.WriteFile(program, []byte("program"), 0o644))
+
+	var archive bytes.Buffer
+	name, err := WriteArchive(&archive, program, nil)
+	require.NoError(t, err)
+
+	s := NewServer(RunnerFunc(func(_ context.Context, req JobRequest) (Result, error) {
+		return Result{Output: "Compile complete\n"}, os.WriteFile(filepath.Join(filepath.Dir(req.File), "Lobby.lpz"), []byte("lpz"), 0o644)
+	}), logger.NewNoOpLogger())
+
+	uploads := t.TempDir()
+	s.SetUploadDir(uploads)
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	job, err := NewClient(ts.URL, "", ts.Client()).Upload(bytes.NewReader(archive.Bytes()), JobRequest{File: name})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Run(ctx)
+
+	job = waitForState(t, s, job.ID, StateSucceeded)
+
+	sha := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	assert.Equal(t, []Artifact{
+		{Name: "Lobby.lpz", Kind: ArtifactOutput, Size: 3, SHA256: sha("lpz")},
+		{Name: "output.log", Kind: ArtifactLog, Size: 17, SHA256: sha("Compile complete\n")},
+	}, job.Artifacts)
+
+	get := func(name string, header http.Header) (*http.Response, string) {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/jobs/"+job.ID+"/artifacts/"+name, nil)
+		require.NoError(t, err)
+
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp, string(body)
+	}
+
+	resp, body := get("Lobby.lpz", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "lpz", body)
+	assert.Equal(t, `"`+sha("lpz")+`"`, resp.Header.Get("ETag"))
+	assert.Contains(t, resp.Header.Get("Repr-Digest"), "sha-256=:")
+
+	resp, _ = get("Lobby.lpz", http.Header{"If-None-Match": {`"` + sha("lpz") + `"`}})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	resp, body = get("output.log", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Compile complete\n", body)
+
+	resp, _ = get("Lobby.smw", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "uploaded sources are not artifacts")
+
+	// An upload left behind by a crash
+	orphan := filepath.Join(uploads, "job-orphan")
+	require.NoError(t, os.Mkdir(orphan, 0o755))
+
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(orphan, old, old))
+
+	s.SetArtifactRetention(time.Nanosecond)
+	s.collectGarbage()
+
+	job, _ = s.Job(job.ID)
+	assert.NotNil(t, job.Expired)
+	assert.NoDirExists(t, job.Upload)
+	assert.NoDirExists(t, orphan)
+
+	resp, _ = get("Lobby.lpz", nil)
+	assert.Equal(t, http.StatusGone, resp.StatusCode)
+
+	resp, _ = get("output.log", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "the log is kept with the job")
+
+	err = NewClient(ts.URL, "", ts.Client()).Artifacts(job.ID, io.Discard)
+	assert.ErrorContains(t, err, "were removed")
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Tokens is the set of API tokens accepted by RequireToken
+type Tokens struct {
+	hashes [][sha256.Size]byte
+}
+
+// NewTokens creates a token set. Empty tokens are ignored.
+func NewTokens(tokens ...string) *Tokens {
+	t := &Tokens{}
+
+	for _, token := range tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			t.hashes = append(t.hashes, sha256.Sum256([]byte(token)))
+		}
+	}
+
+	return t
+}
+
+// LoadTokens reads one token per line from path, skipping blank lines and # comments
+func LoadTokens(path string) (*Tokens, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token file: %w", err)
+	}
+
+	defer f.Close()
+
+	var tokens []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		tokens = append(tokens, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	if len(tokens) == 0 {
+		return nil, errors.New("token file contains no tokens")
+	}
+
+	return NewTokens(tokens...), nil
+}
+
+// Len returns the number of tokens
+func (t *Tokens) Len() int {
+	return len(t.hashes)
+}
+
+// Valid reports whether token is in the set. Tokens are compared by hash in constant
+// time, so neither the contents nor the length of a valid token leak through timing.
+func (t *Tokens) Valid(token string) bool {
+	hash := sha256.Sum256([]byte(token))
+	valid := 0
+
+	for i := range t.hashes {
+		valid |= subtle.ConstantTimeCompare(hash[:], t.hashes[i][:])
+	}
+
+	return valid == 1
+}
+
+// RequireToken rejects requests that do not carry "Authorization: Bearer <token>" with
+// a token in tokens
+func RequireToken(tokens *Tokens, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !tokens.Valid(strings.TrimSpace(token)) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="smpc"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid API token"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTokens(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "tokens")
+	require.NoError(t, os.WriteFile(path, []byte("# CI systems\nci-token\n\n  jenkins-token  \r\n"), 0o600))
+
+	tokens, err := LoadTokens(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, tokens.Len())
+	assert.True(t, tokens.Valid("ci-token"))
+	assert.True(t, tokens.Valid("jenkins-token"))
+	assert.False(t, tokens.Valid("# CI systems"))
+	assert.False(t, tokens.Valid(""))
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("# none yet\n"), 0o600))
+
+	_, err = LoadTokens(empty)
+	assert.ErrorContains(t, err, "no tokens")
+}
+
+func TestRequireToken(t *testing.T) {
+	t.Parallel()
+
+	handler := RequireToken(NewTokens("ci-token"), http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"valid", "Bearer ci-token", http.StatusNoContent},
+		{"missing", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer other", http.StatusUnauthorized},
+		{"wrong scheme", "Basic ci-token", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+
+			if tt.want == http.StatusUnauthorized {
+				assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
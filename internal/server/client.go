@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Client talks to the job API of a remote smpc server
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a Client for the server at baseURL (e.g. https://build01:8765),
+// authenticating with token if it is not empty
+func NewClient(baseURL, token string, httpClient *http.Client) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		http:    httpClient,
+	}
+}
+
+// Upload submits a zip archive made by WriteArchive. req.File is the program's path
+// within the archive.
+func (c *Client) Upload(archive io.Reader, req JobRequest) (Job, error) {
+	query := url.Values{"file": {req.File}}
+
+	if req.RecompileAll {
+		query.Set("recompileAll", strconv.FormatBool(true))
+	}
+
+	if req.WarningsAsErrors {
+		query.Set("warningsAsErrors", strconv.FormatBool(true))
+	}
+
+	if req.Priority != "" {
+		query.Set("priority", req.Priority)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, c.baseURL+"/api/v1/jobs?"+query.Encode(), archive)
+	if err != nil {
+		return Job{}, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/zip")
+
+	if req.IdempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", req.IdempotencyKey)
+	}
+
+	var job Job
+	return job, c.do(httpReq, &job)
+}
+
+// Job fetches the current state of a job
+func (c *Client) Job(id string) (Job, error) {
+	httpReq, err := http.NewRequest(http.MethodGet, c.baseURL+"/api/v1/jobs/"+url.PathEscape(id), nil)
+	if err != nil {
+		return Job{}, err
+	}
+
+	var job Job
+	return job, c.do(httpReq, &job)
+}
+
+// Artifacts downloads the zip of a finished uploaded job's compile outputs to w
+func (c *Client) Artifacts(id string, w io.Writer) error {
+	httpReq, err := http.NewRequest(http.MethodGet, c.baseURL+"/api/v1/jobs/"+url.PathEscape(id)+"/artifacts", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.send(httpReq)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to download artifacts: %w", err)
+	}
+
+	return nil
+}
+
+// do sends req and decodes the JSON response into v
+func (c *Client) do(req *http.Request, v any) error {
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response from server: %w", err)
+	}
+
+	return nil
+}
+
+// send performs req, turning error statuses into errors
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+
+	var body struct {
+		Error string `json:"error"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+		return nil, fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	return nil, fmt.Errorf("server returned %s: %s", resp.Status, body.Error)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Norgate-AV/smpc/internal/eventstream"
+)
+
+// maxJobEvents bounds the events kept for each job; later ones are not recorded
+const maxJobEvents = 10000
+
+// eventsKey is the context key of a job's event recorder
+type eventsKey struct{}
+
+// ReportEvent records ev, read from the --events stream of the running job's compile,
+// so it can be streamed to clients. Runners call it with the ctx they were given;
+// elsewhere it does nothing.
+func ReportEvent(ctx context.Context, ev eventstream.Event) {
+	if record, ok := ctx.Value(eventsKey{}).(func(eventstream.Event)); ok {
+		record(ev)
+	}
+}
+
+// recordEvent adds ev to job's events and wakes the streams following them
+func (s *Server) recordEvent(job *Job, ev eventstream.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[job.ID]; !ok || len(s.events[job.ID]) >= maxJobEvents {
+		return
+	}
+
+	s.events[job.ID] = append(s.events[job.ID], ev)
+	s.broadcastLocked()
+}
+
+// broadcastLocked wakes every stream waiting for a job's events or state to change;
+// s.mu must be held
+func (s *Server) broadcastLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// handleEvents streams a job's compile events as Server-Sent Events: those recorded so
+// far, then each new one as it happens. Each has the event name as its SSE event type,
+// its index as its ID and the eventstream.Event as its data. A reconnecting client's
+// Last-Event-ID resumes after the events it has seen. Once the job has finished, a
+// final "done" event carries the Job and the stream ends.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if _, ok := s.Job(id); !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %s not found", id))
+		return
+	}
+
+	next := 0
+	if last, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil && last >= 0 {
+		next = last + 1
+	}
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for {
+		s.mu.Lock()
+		job, ok := s.jobs[id]
+
+		var (
+			snapshot Job
+			events   []eventstream.Event
+		)
+
+		if ok {
+			snapshot = *job
+			events = append(events, s.events[id][min(next, len(s.events[id])):]...)
+		}
+
+		changed := s.changed
+		s.mu.Unlock()
+
+		// Forgotten while being followed
+		if !ok {
+			return
+		}
+
+		for _, ev := range events {
+			if err := writeEvent(w, strconv.Itoa(next), ev.Event, ev); err != nil {
+				return
+			}
+
+			next++
+		}
+
+		if snapshot.State == StateSucceeded || snapshot.State == StateFailed {
+			_ = writeEvent(w, "", "done", snapshot)
+			_ = rc.Flush()
+			return
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		case <-s.stopped:
+			return
+		}
+	}
+}
+
+// writeEvent writes one Server-Sent Event with v as its JSON data
+func writeEvent(w http.ResponseWriter, id, event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/eventstream"
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+// sseEvent is a Server-Sent Event read by readEvent
+type sseEvent struct {
+	id, event, data string
+}
+
+// readEvent reads the next Server-Sent Event from r
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+
+	var ev sseEvent
+
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return ev
+		}
+
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			ev.id = value
+		case "event":
+			ev.event = value
+		case "data":
+			ev.data = value
+		}
+	}
+}
+
+func TestServer_StreamsEvents(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+
+	s := NewServer(RunnerFunc(func(ctx context.Context, _ JobRequest) (Result, error) {
+		ReportEvent(ctx, eventstream.Event{Type: eventstream.TypeLifecycle, Event: eventstream.EventCompileStarted})
+		<-release
+		ReportEvent(ctx, eventstream.Event{Type: eventstream.TypeLifecycle, Event: eventstream.EventCompileDone})
+
+		return Result{Output: "ok"}, nil
+	}), logger.NewNoOpLogger())
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	job, err := s.Submit(JobRequest{File: `C:\p\Lobby.smw`})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Run(ctx)
+
+	resp, err := http.Get(ts.URL + "/api/v1/jobs/" + job.ID + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	body := bufio.NewReader(resp.Body)
+
+	started := readEvent(t, body)
+	assert.Equal(t, "0", started.id)
+	assert.Equal(t, eventstream.EventCompileStarted, started.event)
+
+	var ev eventstream.Event
+	require.NoError(t, json.Unmarshal([]byte(started.data), &ev))
+	assert.Equal(t, eventstream.TypeLifecycle, ev.Type)
+
+	close(release)
+
+	finished := readEvent(t, body)
+	assert.Equal(t, "1", finished.id)
+	assert.Equal(t, eventstream.EventCompileDone, finished.event)
+
+	done := readEvent(t, body)
+	assert.Equal(t, "done", done.event)
+
+	var final Job
+	require.NoError(t, json.Unmarshal([]byte(done.data), &final))
+	assert.Equal(t, StateSucceeded, final.State)
+
+	// A reconnecting client only gets what it missed
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/jobs/"+job.ID+"/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "0")
+
+	resumed, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resumed.Body.Close()
+
+	body = bufio.NewReader(resumed.Body)
+	assert.Equal(t, "1", readEvent(t, body).id)
+	assert.Equal(t, "done", readEvent(t, body).event)
+
+	missing, err := http.Get(ts.URL + "/api/v1/jobs/42/events")
+	require.NoError(t, err)
+	missing.Body.Close()
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode)
+}
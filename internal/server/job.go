@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Job states
+const (
+	StateQueued    = "queued"
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+)
+
+// Job priorities; higher priority jobs run first and jobs of equal priority run in
+// submission order
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+)
+
+// priorityRank orders priorities; an empty priority is normal
+func priorityRank(priority string) int {
+	switch priority {
+	case PriorityLow:
+		return 0
+	case PriorityHigh:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// JobRequest is the body of a compile request
+type JobRequest struct {
+	File             string `json:"file"`
+	RecompileAll     bool   `json:"recompileAll,omitempty"`
+	WarningsAsErrors bool   `json:"warningsAsErrors,omitempty"`
+
+	// Priority is low, normal (the default) or high
+	Priority string `json:"priority,omitempty"`
+
+	// IdempotencyKey lets a client retry a submission without queuing a duplicate
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+
+	// Callbacks are notified as the job starts, progresses and completes
+	Callbacks *Callbacks `json:"callbacks,omitempty"`
+}
+
+// Validate checks that the request names a SIMPL Windows program and that its
+// callbacks are usable
+func (r JobRequest) Validate() error {
+	if r.File == "" {
+		return errors.New("file is required")
+	}
+
+	if !strings.HasSuffix(strings.ToLower(r.File), ".smw") {
+		return errors.New("file must have .smw extension")
+	}
+
+	switch r.Priority {
+	case "", PriorityLow, PriorityNormal, PriorityHigh:
+	default:
+		return fmt.Errorf("priority must be %s, %s or %s", PriorityLow, PriorityNormal, PriorityHigh)
+	}
+
+	return r.Callbacks.Validate()
+}
+
+// Job is a compile request and its outcome
+type Job struct {
+	ID string `json:"id"`
+	JobRequest
+
+	State    string `json:"state"`
+	ExitCode int    `json:"exitCode"`
+	Output   string `json:"output,omitempty"`
+	Error    string `json:"error,omitempty"`
+
+	// Upload is the server directory holding an uploaded program and its compile
+	// outputs, for jobs submitted as an archive
+	Upload string `json:"upload,omitempty"`
+
+	// Preemptions counts how often the job was aborted to make way for a higher
+	// priority job and requeued
+	Preemptions int `json:"preemptions,omitempty"`
+
+	// Artifacts are the files the job produced, once it has finished
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+
+	// Expired is when the job's outputs were removed under the retention policy
+	Expired *time.Time `json:"expired,omitempty"`
+
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+}
+
+// Result is what a Runner reports for a finished compile
+type Result struct {
+	ExitCode int
+	Output   string // Combined console output of the compile
+}
+
+// Runner performs a compile job, typically by running smpc for the job's file. It should
+// stop the compile and return promptly when ctx is cancelled, with an error wrapping
+// ctx.Err(); a job is only requeued after a preemption when its runner returns one.
+type Runner interface {
+	Run(ctx context.Context, req JobRequest) (Result, error)
+}
+
+// RunnerFunc adapts a function to the Runner interface
+type RunnerFunc func(ctx context.Context, req JobRequest) (Result, error)
+
+// Run calls f(ctx, req)
+func (f RunnerFunc) Run(ctx context.Context, req JobRequest) (Result, error) {
+	return f(ctx, req)
+}
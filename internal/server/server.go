@@ -0,0 +1,535 @@
+// Package server accepts compile jobs over HTTP and runs them one at a time, so a
+// build host can serve compile requests from CI systems.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/clock"
+	"github.com/Norgate-AV/smpc/internal/eventstream"
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+// maxFinishedJobs is how many finished jobs are kept for status queries and
+// idempotency checks; older ones are forgotten
+const maxFinishedJobs = 200
+
+// Server queues compile jobs and runs them sequentially, since only one SIMPL Windows
+// instance can be automated at a time
+type Server struct {
+	log    logger.LoggerInterface
+	runner Runner
+	clock  clock.Clock
+	store  Store
+
+	webhooks *notifier
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	order   []string          // Job IDs in submission order
+	pending []string          // Queued job IDs, next first
+	keys    map[string]string // Idempotency key -> job ID
+	nextID  int
+	wake    chan struct{}
+
+	events  map[string][]eventstream.Event // Compile events of each job that has run
+	changed chan struct{}                  // Closed and replaced when events are recorded or a job finishes
+	stopped chan struct{}                  // Closed when Run returns
+
+	preempt   bool               // Whether higher priority jobs abort the running one
+	current   *Job               // Running job, if any
+	cancel    context.CancelFunc // Cancels the running job
+	preempted bool               // Whether the running job has been cancelled for preemption
+
+	uploadDir string        // Where uploaded programs are extracted; uploads are disabled if empty
+	retention time.Duration // How long finished uploads are kept; 0 keeps them
+}
+
+// NewServer creates a Server that runs jobs with runner and keeps them in memory only
+func NewServer(runner Runner, log logger.LoggerInterface) *Server {
+	return NewServerWithClock(runner, log, clock.New())
+}
+
+// NewServerWithClock creates an in-memory Server using clk for job timestamps
+func NewServerWithClock(runner Runner, log logger.LoggerInterface, clk clock.Clock) *Server {
+	s, _ := NewServerWithDeps(runner, log, clk, memoryStore{})
+	return s
+}
+
+// NewServerWithDeps creates a Server that persists its queue to store. Jobs loaded from
+// store that had not finished are queued again in their original order; a job that was
+// running when the previous server stopped is run again from the start.
+func NewServerWithDeps(runner Runner, log logger.LoggerInterface, clk clock.Clock, store Store) (*Server, error) {
+	s := &Server{
+		log:    log,
+		runner: runner,
+		clock:  clk,
+		store:  store,
+		jobs:   make(map[string]*Job),
+		keys:   make(map[string]string),
+		wake:   make(chan struct{}, 1),
+
+		events:  make(map[string][]eventstream.Event),
+		changed: make(chan struct{}),
+		stopped: make(chan struct{}),
+
+		retention: DefaultArtifactRetention,
+
+		webhooks: newNotifier(log, clk),
+	}
+
+	snapshot, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	s.nextID = snapshot.NextID
+
+	for i := range snapshot.Jobs {
+		job := &snapshot.Jobs[i]
+
+		s.jobs[job.ID] = job
+		s.order = append(s.order, job.ID)
+
+		if job.IdempotencyKey != "" {
+			s.keys[job.IdempotencyKey] = job.ID
+		}
+
+		switch job.State {
+		case StateRunning:
+			log.Warn("Requeuing job interrupted by restart", slog.String("id", job.ID), slog.String("file", job.File))
+			job.State = StateQueued
+			job.Started = nil
+			s.enqueueLocked(job, false)
+		case StateQueued:
+			s.enqueueLocked(job, false)
+		}
+	}
+
+	if len(s.pending) > 0 {
+		log.Info("Resuming queued jobs", slog.Int("count", len(s.pending)))
+	}
+
+	return s, nil
+}
+
+// SetPreemption controls whether submitting a job with a higher priority than the
+// running job aborts the running job and requeues it. It is off by default.
+func (s *Server) SetPreemption(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.preempt = enabled
+}
+
+// Submit queues a job and returns a snapshot of it. If req has an idempotency key that
+// was already submitted, the existing job is returned instead of queuing a new one.
+func (s *Server) Submit(req JobRequest) (Job, error) {
+	job, _, err := s.submit(req, "")
+	return job, err
+}
+
+// submit is Submit for a job whose program may have been uploaded to the upload
+// directory, also reporting whether a new job was queued
+func (s *Server) submit(req JobRequest, upload string) (Job, bool, error) {
+	if err := req.Validate(); err != nil {
+		return Job{}, false, err
+	}
+
+	s.mu.Lock()
+
+	if id, ok := s.keys[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		snapshot := *s.jobs[id]
+		s.mu.Unlock()
+
+		s.log.Info("Job already submitted", slog.String("id", id), slog.String("idempotencyKey", req.IdempotencyKey))
+		return snapshot, false, nil
+	}
+
+	s.nextID++
+
+	job := &Job{
+		ID:         strconv.Itoa(s.nextID),
+		JobRequest: req,
+		State:      StateQueued,
+		Upload:     upload,
+		Created:    s.clock.Now(),
+	}
+
+	s.jobs[job.ID] = job
+	s.order = append(s.order, job.ID)
+	s.enqueueLocked(job, false)
+
+	if req.IdempotencyKey != "" {
+		s.keys[req.IdempotencyKey] = job.ID
+	}
+
+	// Only acknowledge jobs that will survive a restart
+	if err := s.saveLocked(); err != nil {
+		s.dequeueLocked(job.ID)
+		s.forgetLocked(job.ID)
+		s.mu.Unlock()
+
+		return Job{}, false, err
+	}
+
+	if s.preempt && s.current != nil && !s.preempted && priorityRank(job.Priority) > priorityRank(s.current.Priority) {
+		s.log.Info("Preempting running job",
+			slog.String("id", s.current.ID),
+			slog.String("for", job.ID),
+		)
+
+		s.preempted = true
+		s.cancel()
+	}
+
+	snapshot := *job
+	s.mu.Unlock()
+
+	s.log.Info("Job queued", slog.String("id", job.ID), slog.String("file", req.File), slog.String("priority", req.Priority))
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return snapshot, true, nil
+}
+
+// enqueueLocked adds job to the pending queue after the jobs of the same or higher
+// priority, or before those of the same priority when front is set; s.mu must be held
+func (s *Server) enqueueLocked(job *Job, front bool) {
+	rank := priorityRank(job.Priority)
+
+	i := 0
+	for ; i < len(s.pending); i++ {
+		other := priorityRank(s.jobs[s.pending[i]].Priority)
+		if other < rank || (front && other == rank) {
+			break
+		}
+	}
+
+	s.pending = append(s.pending, "")
+	copy(s.pending[i+1:], s.pending[i:])
+	s.pending[i] = job.ID
+}
+
+// dequeueLocked removes id from the pending queue; s.mu must be held
+func (s *Server) dequeueLocked(id string) {
+	for i, pendingID := range s.pending {
+		if pendingID == id {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// saveLocked persists the queue; s.mu must be held
+func (s *Server) saveLocked() error {
+	snapshot := Snapshot{NextID: s.nextID, Jobs: make([]Job, 0, len(s.order))}
+	for _, id := range s.order {
+		snapshot.Jobs = append(snapshot.Jobs, *s.jobs[id])
+	}
+
+	return s.store.Save(snapshot)
+}
+
+// forgetLocked removes a job that is not pending; s.mu must be held
+func (s *Server) forgetLocked(id string) {
+	if key := s.jobs[id].IdempotencyKey; key != "" {
+		delete(s.keys, key)
+	}
+
+	delete(s.jobs, id)
+	delete(s.events, id)
+
+	for i, orderID := range s.order {
+		if orderID == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// pruneLocked forgets the oldest finished jobs beyond maxFinishedJobs; s.mu must be held
+func (s *Server) pruneLocked() {
+	var finished []string
+
+	for _, id := range s.order {
+		if state := s.jobs[id].State; state == StateSucceeded || state == StateFailed {
+			finished = append(finished, id)
+		}
+	}
+
+	for len(finished) > maxFinishedJobs {
+		s.removeUpload(s.jobs[finished[0]])
+		s.forgetLocked(finished[0])
+		finished = finished[1:]
+	}
+}
+
+// Job returns a snapshot of the job with the given ID
+func (s *Server) Job(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+
+	return *job, true
+}
+
+// Jobs returns snapshots of all jobs in submission order
+func (s *Server) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, 0, len(s.order))
+	for _, id := range s.order {
+		jobs = append(jobs, *s.jobs[id])
+	}
+
+	return jobs
+}
+
+// Run processes queued jobs until ctx is cancelled. The job in progress when ctx is
+// cancelled is allowed to finish; jobs still queued stay queued, to be run when the
+// server is next started. Webhooks still waiting are given a little longer to be sent.
+func (s *Server) Run(ctx context.Context) {
+	defer close(s.stopped)
+
+	s.collectGarbage()
+
+	for ctx.Err() == nil {
+		if job, jobCtx := s.next(); job != nil {
+			s.run(jobCtx, job)
+			s.collectGarbage()
+			continue
+		}
+
+		gc := s.clock.NewTimer(artifactGCInterval)
+
+		select {
+		case <-gc.C():
+			s.collectGarbage()
+		case <-ctx.Done():
+		case <-s.wake:
+		}
+
+		gc.Stop()
+	}
+
+	if err := s.webhooks.flush(webhookFlushTimeout); err != nil {
+		s.log.Warn("Some webhooks were not sent", slog.Any("error", err))
+	}
+}
+
+// next removes the next queued job and marks it running. The returned context is
+// cancelled if the job is preempted.
+func (s *Server) next() (*Job, context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) == 0 {
+		return nil, nil
+	}
+
+	job := s.jobs[s.pending[0]]
+	s.pending = s.pending[1:]
+
+	now := s.clock.Now()
+	job.State = StateRunning
+	job.Started = &now
+
+	if err := s.saveLocked(); err != nil {
+		s.log.Error("Failed to persist job queue", slog.Any("error", err))
+	}
+
+	s.notifyLocked(job, EventStarted, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = context.WithValue(ctx, progressKey{}, func(message string) { s.progress(job, message) })
+	ctx = context.WithValue(ctx, eventsKey{}, func(ev eventstream.Event) { s.recordEvent(job, ev) })
+
+	s.current = job
+	s.cancel = cancel
+	s.preempted = false
+
+	return job, ctx
+}
+
+// run performs job and records its outcome, or requeues it if it was stopped by a
+// preemption
+func (s *Server) run(ctx context.Context, job *Job) {
+	s.log.Info("Job started", slog.String("id", job.ID), slog.String("file", job.File))
+
+	result, err := s.runner.Run(ctx, job.JobRequest)
+
+	// Judged by how the runner stopped: a preemption can cancel ctx just after a
+	// compile has finished, and that compile's result stands
+	cancelled := errors.Is(err, context.Canceled)
+
+	// Hashed before locking, as outputs can be large; a preempted job has none
+	var list []Artifact
+	if !cancelled {
+		var listErr error
+		if list, listErr = jobArtifacts(job, result.Output); listErr != nil {
+			s.log.Warn("Failed to list job artifacts", slog.String("id", job.ID), slog.Any("error", listErr))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cancel()
+	s.current = nil
+
+	if s.preempted && cancelled {
+		job.State = StateQueued
+		job.Started = nil
+		job.Preemptions++
+		s.enqueueLocked(job, true)
+
+		s.log.Info("Job requeued after preemption", slog.String("id", job.ID))
+		s.notifyLocked(job, EventProgress, "requeued after preemption")
+
+		if err := s.saveLocked(); err != nil {
+			s.log.Error("Failed to persist job queue", slog.Any("error", err))
+		}
+
+		return
+	}
+
+	now := s.clock.Now()
+	job.Finished = &now
+	job.ExitCode = result.ExitCode
+	job.Output = result.Output
+	job.Artifacts = list
+
+	switch {
+	case err != nil:
+		job.State = StateFailed
+		job.Error = err.Error()
+	case result.ExitCode != 0:
+		job.State = StateFailed
+	default:
+		job.State = StateSucceeded
+	}
+
+	s.notifyLocked(job, EventCompleted, "")
+	s.broadcastLocked()
+	s.pruneLocked()
+
+	if err := s.saveLocked(); err != nil {
+		s.log.Error("Failed to persist job queue", slog.Any("error", err))
+	}
+
+	s.log.Info("Job finished",
+		slog.String("id", job.ID),
+		slog.String("state", job.State),
+		slog.Int("exitCode", job.ExitCode),
+	)
+}
+
+// Handler returns the HTTP API:
+//
+//	POST /api/v1/jobs                        queue a compile (JobRequest body, or a zip upload)
+//	GET  /api/v1/jobs                        list jobs
+//	GET  /api/v1/jobs/{id}                   get one job
+//	GET  /api/v1/jobs/{id}/artifacts         download an uploaded job's compile outputs
+//	GET  /api/v1/jobs/{id}/artifacts/{name}  download one of a job's Artifacts
+//	GET  /api/v1/jobs/{id}/events            follow a job's compile events (Server-Sent Events)
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /api/v1/jobs", s.handleSubmit)
+	mux.HandleFunc("GET /api/v1/jobs", s.handleList)
+	mux.HandleFunc("GET /api/v1/jobs/{id}", s.handleGet)
+	mux.HandleFunc("GET /api/v1/jobs/{id}/artifacts", s.handleArtifacts)
+	mux.HandleFunc("GET /api/v1/jobs/{id}/artifacts/{name...}", s.handleArtifact)
+	mux.HandleFunc("GET /api/v1/jobs/{id}/events", s.handleEvents)
+
+	return mux
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/zip" {
+		s.handleUpload(w, r)
+		return
+	}
+
+	var req JobRequest
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		if req.IdempotencyKey != "" && req.IdempotencyKey != key {
+			writeError(w, http.StatusBadRequest, errors.New("idempotency key header does not match the request body"))
+			return
+		}
+
+		req.IdempotencyKey = key
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	job, created, err := s.submit(req, "")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+
+	// A repeated idempotency key returns the original job
+	status := http.StatusAccepted
+	if !created {
+		status = http.StatusOK
+	}
+
+	writeJSON(w, status, job)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.Jobs())
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.Job(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %s not found", r.PathValue("id")))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, job)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
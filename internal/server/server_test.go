@@ -0,0 +1,488 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/clock"
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+// waitForState polls until job id reaches state
+func waitForState(t *testing.T, s *Server, id, state string) Job {
+	t.Helper()
+
+	var job Job
+	require.Eventually(t, func() bool {
+		job, _ = s.Job(id)
+		return job.State == state
+	}, 5*time.Second, time.Millisecond)
+
+	return job
+}
+
+func TestServer_RunsJobsInOrder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	runner := RunnerFunc(func(_ context.Context, req JobRequest) (Result, error) {
+		mu.Lock()
+		ran = append(ran, req.File)
+		mu.Unlock()
+
+		switch req.File {
+		case `C:\p\Broken.smw`:
+			return Result{ExitCode: 1, Output: "1 error"}, nil
+		case `C:\p\Missing.smw`:
+			return Result{}, errors.New("failed to start smpc")
+		default:
+			return Result{Output: "ok"}, nil
+		}
+	})
+
+	s := NewServer(runner, logger.NewNoOpLogger())
+
+	first, err := s.Submit(JobRequest{File: `C:\p\Lobby.smw`, RecompileAll: true})
+	require.NoError(t, err)
+	assert.Equal(t, StateQueued, first.State)
+
+	broken, err := s.Submit(JobRequest{File: `C:\p\Broken.smw`})
+	require.NoError(t, err)
+
+	missing, err := s.Submit(JobRequest{File: `C:\p\Missing.smw`})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Run(ctx)
+
+	job := waitForState(t, s, first.ID, StateSucceeded)
+	assert.Equal(t, "ok", job.Output)
+	assert.NotNil(t, job.Started)
+	assert.NotNil(t, job.Finished)
+
+	job = waitForState(t, s, broken.ID, StateFailed)
+	assert.Equal(t, 1, job.ExitCode)
+
+	job = waitForState(t, s, missing.ID, StateFailed)
+	assert.Equal(t, "failed to start smpc", job.Error)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{`C:\p\Lobby.smw`, `C:\p\Broken.smw`, `C:\p\Missing.smw`}, ran)
+}
+
+func TestServer_HTTP(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(RunnerFunc(func(context.Context, JobRequest) (Result, error) {
+		return Result{}, nil
+	}), logger.NewNoOpLogger())
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/api/v1/jobs", "application/json", strings.NewReader(`{"file":"C:\\p\\Lobby.smw"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, "/api/v1/jobs/1", resp.Header.Get("Location"))
+
+	var job Job
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+	assert.Equal(t, `C:\p\Lobby.smw`, job.File)
+
+	get, err := http.Get(ts.URL + "/api/v1/jobs/1")
+	require.NoError(t, err)
+	get.Body.Close()
+	assert.Equal(t, http.StatusOK, get.StatusCode)
+
+	missing, err := http.Get(ts.URL + "/api/v1/jobs/42")
+	require.NoError(t, err)
+	missing.Body.Close()
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode)
+
+	for _, body := range []string{`{"file":"notes.txt"}`, `{"file":"a.smw","args":["--trace"]}`, `not json`} {
+		bad, err := http.Post(ts.URL+"/api/v1/jobs", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		bad.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, bad.StatusCode, body)
+	}
+
+	list, err := http.Get(ts.URL + "/api/v1/jobs")
+	require.NoError(t, err)
+	defer list.Body.Close()
+
+	var jobs []Job
+	require.NoError(t, json.NewDecoder(list.Body).Decode(&jobs))
+	assert.Len(t, jobs, 1)
+}
+
+func TestServer_IdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(RunnerFunc(func(context.Context, JobRequest) (Result, error) {
+		return Result{}, nil
+	}), logger.NewNoOpLogger())
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	submit := func(key, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/jobs", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Idempotency-Key", key)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp
+	}
+
+	first := submit("build-7", `{"file":"C:\\p\\Lobby.smw"}`)
+	assert.Equal(t, http.StatusAccepted, first.StatusCode)
+
+	retry := submit("build-7", `{"file":"C:\\p\\Lobby.smw"}`)
+	assert.Equal(t, http.StatusOK, retry.StatusCode)
+	assert.Equal(t, first.Header.Get("Location"), retry.Header.Get("Location"))
+
+	mismatch := submit("build-7", `{"file":"C:\\p\\Lobby.smw","idempotencyKey":"build-8"}`)
+	assert.Equal(t, http.StatusBadRequest, mismatch.StatusCode)
+
+	assert.Len(t, s.Jobs(), 1)
+}
+
+// recordingStore is an in-memory Store that keeps the last saved snapshot
+type recordingStore struct {
+	mu       sync.Mutex
+	snapshot Snapshot
+	err      error
+}
+
+func (r *recordingStore) Load() (Snapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.snapshot, nil
+}
+
+func (r *recordingStore) Save(snapshot Snapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	r.snapshot = snapshot
+	return nil
+}
+
+func TestServer_ResumesPersistedJobs(t *testing.T) {
+	t.Parallel()
+
+	store := &recordingStore{snapshot: Snapshot{
+		NextID: 3,
+		Jobs: []Job{
+			{ID: "1", JobRequest: JobRequest{File: `C:\p\Done.smw`}, State: StateSucceeded},
+			{ID: "2", JobRequest: JobRequest{File: `C:\p\Interrupted.smw`, IdempotencyKey: "build-7"}, State: StateRunning},
+			{ID: "3", JobRequest: JobRequest{File: `C:\p\Waiting.smw`}, State: StateQueued},
+		},
+	}}
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	s, err := NewServerWithDeps(RunnerFunc(func(_ context.Context, req JobRequest) (Result, error) {
+		mu.Lock()
+		ran = append(ran, req.File)
+		mu.Unlock()
+
+		return Result{}, nil
+	}), logger.NewNoOpLogger(), clock.New(), store)
+	require.NoError(t, err)
+
+	job, ok := s.Job("2")
+	require.True(t, ok)
+	assert.Equal(t, StateQueued, job.State)
+	assert.Nil(t, job.Started)
+
+	// The key survives the restart
+	dup, err := s.Submit(JobRequest{File: `C:\p\Interrupted.smw`, IdempotencyKey: "build-7"})
+	require.NoError(t, err)
+	assert.Equal(t, "2", dup.ID)
+
+	next, err := s.Submit(JobRequest{File: `C:\p\New.smw`})
+	require.NoError(t, err)
+	assert.Equal(t, "4", next.ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Run(ctx)
+
+	waitForState(t, s, next.ID, StateSucceeded)
+
+	mu.Lock()
+	assert.Equal(t, []string{`C:\p\Interrupted.smw`, `C:\p\Waiting.smw`, `C:\p\New.smw`}, ran)
+	mu.Unlock()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	require.Len(t, store.snapshot.Jobs, 4)
+	assert.Equal(t, 4, store.snapshot.NextID)
+
+	for _, job := range store.snapshot.Jobs {
+		assert.Equal(t, StateSucceeded, job.State, job.ID)
+	}
+}
+
+func TestServer_StopsWithJobsQueued(t *testing.T) {
+	t.Parallel()
+
+	store := &recordingStore{}
+	started := make(chan string, 3)
+	release := make(chan struct{})
+
+	s, err := NewServerWithDeps(RunnerFunc(func(_ context.Context, req JobRequest) (Result, error) {
+		started <- req.File
+		<-release
+		return Result{}, nil
+	}), logger.NewNoOpLogger(), clock.New(), store)
+	require.NoError(t, err)
+
+	first, err := s.Submit(JobRequest{File: `C:\p\Lobby.smw`})
+	require.NoError(t, err)
+
+	second, err := s.Submit(JobRequest{File: `C:\p\Boardroom.smw`})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	assert.Equal(t, `C:\p\Lobby.smw`, <-started)
+
+	// The job in progress finishes, but nothing more is started
+	cancel()
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after its context was cancelled")
+	}
+
+	assert.Empty(t, started)
+
+	job, _ := s.Job(first.ID)
+	assert.Equal(t, StateSucceeded, job.State)
+
+	job, _ = s.Job(second.ID)
+	assert.Equal(t, StateQueued, job.State)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	require.Len(t, store.snapshot.Jobs, 2)
+	assert.Equal(t, StateQueued, store.snapshot.Jobs[1].State, "queued jobs are kept for the next start")
+}
+
+func TestServer_SubmitFailsWhenQueueCannotBeSaved(t *testing.T) {
+	t.Parallel()
+
+	store := &recordingStore{err: errors.New("disk full")}
+
+	s, err := NewServerWithDeps(RunnerFunc(func(context.Context, JobRequest) (Result, error) {
+		return Result{}, nil
+	}), logger.NewNoOpLogger(), clock.New(), store)
+	require.NoError(t, err)
+
+	_, err = s.Submit(JobRequest{File: `C:\p\Lobby.smw`, IdempotencyKey: "build-7"})
+	assert.ErrorContains(t, err, "disk full")
+	assert.Empty(t, s.Jobs())
+
+	store.err = nil
+
+	job, err := s.Submit(JobRequest{File: `C:\p\Lobby.smw`, IdempotencyKey: "build-7"})
+	require.NoError(t, err)
+	assert.Equal(t, StateQueued, job.State)
+}
+
+func TestServer_RunsHigherPriorityFirst(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	s := NewServer(RunnerFunc(func(_ context.Context, req JobRequest) (Result, error) {
+		mu.Lock()
+		ran = append(ran, req.File)
+		mu.Unlock()
+
+		return Result{}, nil
+	}), logger.NewNoOpLogger())
+
+	for _, req := range []JobRequest{
+		{File: `C:\p\Nightly1.smw`, Priority: PriorityLow},
+		{File: `C:\p\Normal1.smw`},
+		{File: `C:\p\Nightly2.smw`, Priority: PriorityLow},
+		{File: `C:\p\Dev.smw`, Priority: PriorityHigh},
+		{File: `C:\p\Normal2.smw`, Priority: PriorityNormal},
+	} {
+		_, err := s.Submit(req)
+		require.NoError(t, err)
+	}
+
+	_, err := s.Submit(JobRequest{File: `C:\p\Bad.smw`, Priority: "urgent"})
+	assert.ErrorContains(t, err, "priority must be")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Run(ctx)
+
+	waitForState(t, s, "3", StateSucceeded)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		`C:\p\Dev.smw`,
+		`C:\p\Normal1.smw`,
+		`C:\p\Normal2.smw`,
+		`C:\p\Nightly1.smw`,
+		`C:\p\Nightly2.smw`,
+	}, ran)
+}
+
+func TestServer_PreemptsLowerPriorityJob(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	started := make(chan string, 4)
+
+	s := NewServer(RunnerFunc(func(ctx context.Context, req JobRequest) (Result, error) {
+		mu.Lock()
+		ran = append(ran, req.File)
+		first := len(ran) == 1
+		mu.Unlock()
+
+		started <- req.File
+
+		if first {
+			<-ctx.Done()
+			return Result{ExitCode: 1}, ctx.Err()
+		}
+
+		return Result{}, nil
+	}), logger.NewNoOpLogger())
+	s.SetPreemption(true)
+
+	nightly, err := s.Submit(JobRequest{File: `C:\p\Nightly.smw`, Priority: PriorityLow})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Run(ctx)
+
+	assert.Equal(t, `C:\p\Nightly.smw`, <-started)
+
+	// Equal priority does not preempt
+	normal, err := s.Submit(JobRequest{File: `C:\p\Low.smw`, Priority: PriorityLow})
+	require.NoError(t, err)
+
+	dev, err := s.Submit(JobRequest{File: `C:\p\Dev.smw`, Priority: PriorityHigh})
+	require.NoError(t, err)
+
+	waitForState(t, s, normal.ID, StateSucceeded)
+
+	job := waitForState(t, s, nightly.ID, StateSucceeded)
+	assert.Equal(t, 1, job.Preemptions)
+	assert.Empty(t, job.Error)
+
+	job = waitForState(t, s, dev.ID, StateSucceeded)
+	assert.Zero(t, job.Preemptions)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{`C:\p\Nightly.smw`, `C:\p\Dev.smw`, `C:\p\Nightly.smw`, `C:\p\Low.smw`}, ran)
+}
+
+func TestServer_KeepsJobFinishedBeforePreemption(t *testing.T) {
+	t.Parallel()
+
+	var (
+		s   *Server
+		mu  sync.Mutex
+		ran []string
+	)
+
+	devID := make(chan string, 1)
+
+	s = NewServer(RunnerFunc(func(ctx context.Context, req JobRequest) (Result, error) {
+		mu.Lock()
+		ran = append(ran, req.File)
+		first := len(ran) == 1
+		mu.Unlock()
+
+		// The preempting job arrives once this compile has already finished
+		if first {
+			dev, err := s.Submit(JobRequest{File: `C:\p\Dev.smw`, Priority: PriorityHigh})
+			require.NoError(t, err)
+
+			devID <- dev.ID
+		}
+
+		return Result{Output: "done"}, nil
+	}), logger.NewNoOpLogger())
+	s.SetPreemption(true)
+
+	nightly, err := s.Submit(JobRequest{File: `C:\p\Nightly.smw`, Priority: PriorityLow})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Run(ctx)
+
+	job := waitForState(t, s, nightly.ID, StateSucceeded)
+	assert.Zero(t, job.Preemptions)
+	assert.Equal(t, "done", job.Output)
+
+	waitForState(t, s, <-devID, StateSucceeded)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{`C:\p\Nightly.smw`, `C:\p\Dev.smw`}, ran, "should not compile the finished job again")
+}
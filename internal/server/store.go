@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Snapshot is the persisted state of a Server
+type Snapshot struct {
+	NextID int   `json:"nextId"`
+	Jobs   []Job `json:"jobs"` // In submission order
+}
+
+// Store persists the job queue so pending jobs survive a restart
+type Store interface {
+	Load() (Snapshot, error)
+	Save(Snapshot) error
+}
+
+// memoryStore keeps nothing, for servers that do not need persistence
+type memoryStore struct{}
+
+func (memoryStore) Load() (Snapshot, error) { return Snapshot{}, nil }
+func (memoryStore) Save(Snapshot) error     { return nil }
+
+// FileStore persists the queue as a JSON file
+type FileStore struct {
+	Path string
+}
+
+// NewFileStore creates a FileStore writing to path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+// Load reads the queue file. A missing file is an empty queue.
+func (f *FileStore) Load() (Snapshot, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, nil
+	}
+
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read job queue: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to parse job queue %s: %w", f.Path, err)
+	}
+
+	return snapshot, nil
+}
+
+// Save replaces the queue file. It writes a temporary file and renames it over the
+// old one, so a crash mid-write leaves the previous queue intact.
+func (f *FileStore) Save(snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(f.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create job queue directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save job queue: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save job queue: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save job queue: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save job queue: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.Path); err != nil {
+		return fmt.Errorf("failed to save job queue: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "queue", "jobs.json")
+	store := NewFileStore(path)
+
+	empty, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, Snapshot{}, empty)
+
+	snapshot := Snapshot{
+		NextID: 2,
+		Jobs: []Job{
+			{ID: "1", JobRequest: JobRequest{File: `C:\p\Lobby.smw`}, State: StateSucceeded, Created: time.Unix(100, 0).UTC()},
+			{ID: "2", JobRequest: JobRequest{File: `C:\p\Boardroom.smw`, IdempotencyKey: "build-7"}, State: StateQueued, Created: time.Unix(200, 0).UTC()},
+		},
+	}
+	require.NoError(t, store.Save(snapshot))
+
+	loaded, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, loaded)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary file should be renamed into place")
+}
+
+func TestFileStore_Corrupt(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+	_, err := NewFileStore(path).Load()
+	assert.ErrorContains(t, err, "failed to parse job queue")
+}
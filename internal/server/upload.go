@@ -0,0 +1,201 @@
+package server
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// maxUploadSize bounds the size of an uploaded archive
+const maxUploadSize = 256 << 20
+
+// SetUploadDir enables zip uploads, extracting each into its own directory under dir
+func (s *Server) SetUploadDir(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.uploadDir = dir
+}
+
+// handleUpload queues a job for a program uploaded as a zip archive. The program's
+// path within the archive and the job options are given as query parameters:
+// file, recompileAll, warningsAsErrors, priority, and the startedCallback,
+// progressCallback and completedCallback URLs.
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	root := s.uploadDir
+	s.mu.Unlock()
+
+	if root == "" {
+		writeError(w, http.StatusUnsupportedMediaType, errors.New("uploads are not enabled on this server"))
+		return
+	}
+
+	req, err := uploadRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	dir, err := s.receiveUpload(w, r, root)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	program := filepath.Join(dir, filepath.FromSlash(req.File))
+	if _, err := os.Stat(program); err != nil {
+		_ = os.RemoveAll(dir)
+		writeError(w, http.StatusBadRequest, fmt.Errorf("archive does not contain %s", req.File))
+		return
+	}
+
+	req.File = program
+
+	job, created, err := s.submit(req, dir)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+
+	if !created {
+		_ = os.RemoveAll(dir)
+		writeJSON(w, http.StatusOK, job)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// uploadRequest builds the JobRequest for an upload from its query parameters. File is
+// left relative to the archive root.
+func uploadRequest(r *http.Request) (JobRequest, error) {
+	query := r.URL.Query()
+
+	req := JobRequest{
+		File:           query.Get("file"),
+		Priority:       query.Get("priority"),
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	}
+
+	callbacks := Callbacks{
+		Started:   query.Get("startedCallback"),
+		Progress:  query.Get("progressCallback"),
+		Completed: query.Get("completedCallback"),
+	}
+
+	if callbacks != (Callbacks{}) {
+		req.Callbacks = &callbacks
+	}
+
+	for name, dst := range map[string]*bool{
+		"recompileAll":     &req.RecompileAll,
+		"warningsAsErrors": &req.WarningsAsErrors,
+	} {
+		if value := query.Get(name); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return JobRequest{}, fmt.Errorf("invalid %s: %q", name, value)
+			}
+
+			*dst = b
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		return JobRequest{}, err
+	}
+
+	if !filepath.IsLocal(filepath.FromSlash(req.File)) {
+		return JobRequest{}, fmt.Errorf("file must be a path within the archive: %s", req.File)
+	}
+
+	return req, nil
+}
+
+// receiveUpload saves the request body and extracts it into a new directory under root
+func (s *Server) receiveUpload(w http.ResponseWriter, r *http.Request, root string) (string, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	// zip needs random access, so buffer the body outside the extraction directory
+	archive, err := os.CreateTemp(root, "upload-*.zip")
+	if err != nil {
+		return "", err
+	}
+
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	size, err := io.Copy(archive, http.MaxBytesReader(w, r.Body, maxUploadSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to receive upload: %w", err)
+	}
+
+	zr, err := zip.NewReader(archive, size)
+	if err != nil {
+		return "", fmt.Errorf("upload is not a zip archive: %w", err)
+	}
+
+	dir, err := os.MkdirTemp(root, "job-*")
+	if err != nil {
+		return "", err
+	}
+
+	if err := extractArchive(zr, dir); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", err
+	}
+
+	s.log.Debug("Extracted upload", slog.String("dir", dir), slog.Int("files", len(zr.File)))
+	return dir, nil
+}
+
+// handleArtifacts sends the files an uploaded job's compile created or changed, as a zip
+func (s *Server) handleArtifacts(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.Job(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %s not found", r.PathValue("id")))
+		return
+	}
+
+	if job.Upload == "" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %s was not uploaded, so its outputs are on the server", job.ID))
+		return
+	}
+
+	if job.Finished == nil || job.Started == nil {
+		writeError(w, http.StatusConflict, fmt.Errorf("job %s has not finished", job.ID))
+		return
+	}
+
+	if job.Expired != nil {
+		writeError(w, http.StatusGone, fmt.Errorf("artifacts of job %s were removed at %s", job.ID, job.Expired.Format(time.RFC3339)))
+		return
+	}
+
+	var files []string
+	for _, a := range job.Artifacts {
+		if a.Kind == ArtifactOutput {
+			files = append(files, a.Name)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="job-%s-artifacts.zip"`, job.ID))
+
+	if err := writeArtifacts(w, job.Upload, files); err != nil {
+		s.log.Warn("Failed to send artifacts", slog.String("id", job.ID), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+func TestUploadAndArtifacts(t *testing.T) {
+	t.Parallel()
+
+	// A local program with a sibling module and an include folder
+	local := t.TempDir()
+	program := filepath.Join(local, "Lobby.smw")
+	require.NoError(t, os.WriteFile(program, []byte("program"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(local, "Helper.usp"), []byte("module"), 0o644))
+
+	modules := filepath.Join(t.TempDir(), "Modules")
+	require.NoError(t, os.MkdirAll(filepath.Join(modules, "Audio"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(modules, "Audio", "Mixer.umc"), []byte("umc"), 0o644))
+
+	var archive bytes.Buffer
+	name, err := WriteArchive(&archive, program, []string{modules})
+	require.NoError(t, err)
+	assert.Equal(t, "Lobby.smw", name)
+
+	var compiled string
+
+	s := NewServer(RunnerFunc(func(_ context.Context, req JobRequest) (Result, error) {
+		compiled = req.File
+
+		// The compile sees the whole upload and writes an output beside the program
+		if _, err := os.Stat(filepath.Join(filepath.Dir(req.File), "Modules", "Audio", "Mixer.umc")); err != nil {
+			return Result{}, err
+		}
+
+		return Result{Output: "done"}, os.WriteFile(filepath.Join(filepath.Dir(req.File), "Lobby.lpz"), []byte("lpz"), 0o644)
+	}), logger.NewNoOpLogger())
+	s.SetUploadDir(t.TempDir())
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	client := NewClient(ts.URL, "", ts.Client())
+
+	job, err := client.Upload(bytes.NewReader(archive.Bytes()), JobRequest{File: name, RecompileAll: true})
+	require.NoError(t, err)
+	assert.True(t, job.RecompileAll)
+	assert.NotEmpty(t, job.Upload)
+
+	err = client.Artifacts(job.ID, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "has not finished")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Run(ctx)
+
+	waitForState(t, s, job.ID, StateSucceeded)
+	assert.Equal(t, filepath.Join(job.Upload, "Lobby.smw"), compiled)
+
+	got, err := client.Job(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "done", got.Output)
+
+	var download bytes.Buffer
+	require.NoError(t, client.Artifacts(job.ID, &download))
+
+	zr, err := zip.NewReader(bytes.NewReader(download.Bytes()), int64(download.Len()))
+	require.NoError(t, err)
+
+	out := t.TempDir()
+	written, err := ExtractArtifacts(zr, out)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(out, "Lobby.lpz")}, written)
+}
+
+func TestUpload_Rejected(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(RunnerFunc(func(context.Context, JobRequest) (Result, error) {
+		return Result{}, nil
+	}), logger.NewNoOpLogger())
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	client := NewClient(ts.URL, "", ts.Client())
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create("../escape.smw")
+	require.NoError(t, err)
+	_, _ = w.Write([]byte("x"))
+	require.NoError(t, zw.Close())
+
+	_, err = client.Upload(bytes.NewReader(archive.Bytes()), JobRequest{File: "Lobby.smw"})
+	assert.ErrorContains(t, err, "not enabled")
+
+	s.SetUploadDir(t.TempDir())
+
+	_, err = client.Upload(bytes.NewReader(archive.Bytes()), JobRequest{File: "Lobby.smw"})
+	assert.ErrorContains(t, err, "outside the upload")
+
+	_, err = client.Upload(bytes.NewReader(archive.Bytes()), JobRequest{File: "../escape.smw"})
+	assert.ErrorContains(t, err, "within the archive")
+
+	_, err = client.Upload(bytes.NewReader([]byte("not a zip")), JobRequest{File: "Lobby.smw"})
+	assert.ErrorContains(t, err, "not a zip archive")
+
+	assert.Empty(t, s.Jobs())
+}
@@ -0,0 +1,260 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/clock"
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+// Webhook events
+const (
+	EventStarted   = "started"   // The job began compiling
+	EventProgress  = "progress"  // The compile reported output, or the job was requeued
+	EventCompleted = "completed" // The job succeeded or failed
+)
+
+const (
+	// webhookAttempts is how many times a webhook is sent before it is given up
+	webhookAttempts = 3
+
+	// webhookRetryDelay is the wait before the first retry; it doubles for each one
+	webhookRetryDelay = time.Second
+
+	// webhookQueueSize bounds the webhooks waiting to be sent; more are dropped
+	webhookQueueSize = 256
+
+	// webhookFlushTimeout bounds how long a stopping server waits for webhooks to be sent
+	webhookFlushTimeout = 10 * time.Second
+)
+
+// Callbacks are URLs the server POSTs a WebhookEvent to as a job progresses, so
+// clients need not poll its status. Each is optional.
+type Callbacks struct {
+	Started   string `json:"started,omitempty"`
+	Progress  string `json:"progress,omitempty"`
+	Completed string `json:"completed,omitempty"`
+}
+
+// url returns the callback for event, or "" if there is none
+func (c *Callbacks) url(event string) string {
+	if c == nil {
+		return ""
+	}
+
+	switch event {
+	case EventStarted:
+		return c.Started
+	case EventProgress:
+		return c.Progress
+	case EventCompleted:
+		return c.Completed
+	default:
+		return ""
+	}
+}
+
+// Validate checks that each callback is an absolute http or https URL
+func (c *Callbacks) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	for event, raw := range map[string]string{
+		EventStarted:   c.Started,
+		EventProgress:  c.Progress,
+		EventCompleted: c.Completed,
+	} {
+		if raw == "" {
+			continue
+		}
+
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s callback must be an http or https URL", event)
+		}
+	}
+
+	return nil
+}
+
+// WebhookEvent is the body of a webhook
+type WebhookEvent struct {
+	Event   string `json:"event"`
+	Message string `json:"message,omitempty"` // For progress events, the new output or what happened
+	Job     Job    `json:"job"`               // The job as of the event
+}
+
+// progressKey is the context key of a job's progress reporter
+type progressKey struct{}
+
+// ReportProgress sends message to the running job's progress callback, if it has one.
+// Runners call it with the ctx they were given; elsewhere it does nothing.
+func ReportProgress(ctx context.Context, message string) {
+	if report, ok := ctx.Value(progressKey{}).(func(string)); ok {
+		report(message)
+	}
+}
+
+// webhook is a WebhookEvent waiting to be sent to url
+type webhook struct {
+	url   string
+	event WebhookEvent
+}
+
+// notifier sends webhooks one at a time, in the order they were queued, so a job's
+// started event always arrives before its completed event
+type notifier struct {
+	log   logger.LoggerInterface
+	clock clock.Clock
+
+	mu     sync.Mutex
+	client *http.Client
+
+	start   sync.Once
+	queue   chan webhook
+	pending sync.WaitGroup
+}
+
+func newNotifier(log logger.LoggerInterface, clk clock.Clock) *notifier {
+	return &notifier{
+		log:    log,
+		clock:  clk,
+		client: &http.Client{Timeout: 10 * time.Second},
+		queue:  make(chan webhook, webhookQueueSize),
+	}
+}
+
+// SetWebhookClient sets the HTTP client used to send webhooks, e.g. to trust a
+// private certificate authority
+func (s *Server) SetWebhookClient(client *http.Client) {
+	s.webhooks.mu.Lock()
+	defer s.webhooks.mu.Unlock()
+
+	s.webhooks.client = client
+}
+
+// notifyLocked queues the webhook for event, if job has a callback for it; s.mu must
+// be held so the snapshot is consistent
+func (s *Server) notifyLocked(job *Job, event, message string) {
+	target := job.Callbacks.url(event)
+	if target == "" {
+		return
+	}
+
+	s.webhooks.send(webhook{url: target, event: WebhookEvent{Event: event, Message: message, Job: *job}})
+}
+
+// progress reports message to job's progress callback
+func (s *Server) progress(job *Job, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notifyLocked(job, EventProgress, message)
+}
+
+// send queues w without blocking, dropping it if too many are waiting
+func (n *notifier) send(w webhook) {
+	n.start.Do(func() { go n.deliver() })
+
+	n.pending.Add(1)
+
+	select {
+	case n.queue <- w:
+	default:
+		n.pending.Done()
+		n.log.Warn("Webhook queue full; dropping webhook",
+			slog.String("event", w.event.Event),
+			slog.String("id", w.event.Job.ID),
+		)
+	}
+}
+
+// deliver sends queued webhooks until the process exits
+func (n *notifier) deliver() {
+	for w := range n.queue {
+		if err := n.post(w); err != nil {
+			n.log.Warn("Failed to send webhook",
+				slog.String("event", w.event.Event),
+				slog.String("id", w.event.Job.ID),
+				slog.Any("error", err),
+			)
+		}
+
+		n.pending.Done()
+	}
+}
+
+// post sends w, retrying with a growing delay on network errors and non-2xx responses
+func (n *notifier) post(w webhook) error {
+	body, err := json.Marshal(w.event)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	client := n.client
+	n.mu.Unlock()
+
+	delay := webhookRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		err = postOnce(client, w.url, body)
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+
+		n.log.Debug("Retrying webhook",
+			slog.String("event", w.event.Event),
+			slog.Int("attempt", attempt),
+			slog.Any("error", err),
+		)
+
+		n.clock.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func postOnce(client *http.Client, target string, body []byte) error {
+	resp, err := client.Post(target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("callback returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// flush waits up to timeout for queued webhooks to be sent
+func (n *notifier) flush(timeout time.Duration) error {
+	done := make(chan struct{})
+
+	go func() {
+		n.pending.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		return errors.New("timed out sending webhooks")
+	}
+}
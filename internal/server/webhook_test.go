@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/testutil"
+)
+
+// webhookReceiver records the webhooks POSTed to it, answering the first failures
+// with 500 Internal Server Error
+type webhookReceiver struct {
+	mu       sync.Mutex
+	failures int
+	events   []WebhookEvent
+	paths    []string
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var event WebhookEvent
+	if err := json.NewDecoder(req.Body).Decode(&event); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.events = append(r.events, event)
+	r.paths = append(r.paths, req.URL.Path)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestServer_Webhooks(t *testing.T) {
+	t.Parallel()
+
+	receiver := &webhookReceiver{failures: 1}
+	hooks := httptest.NewServer(receiver)
+	defer hooks.Close()
+
+	runner := RunnerFunc(func(ctx context.Context, _ JobRequest) (Result, error) {
+		ReportProgress(ctx, "Compiling Lobby.smw\n")
+		return Result{Output: "ok"}, nil
+	})
+
+	s := NewServerWithClock(runner, logger.NewNoOpLogger(), testutil.NewFakeClock())
+
+	job, err := s.Submit(JobRequest{
+		File: `C:\p\Lobby.smw`,
+		Callbacks: &Callbacks{
+			Started:   hooks.URL + "/started",
+			Progress:  hooks.URL + "/progress",
+			Completed: hooks.URL + "/completed",
+		},
+	})
+	require.NoError(t, err)
+
+	// No callbacks, so no webhooks
+	_, err = s.Submit(JobRequest{File: `C:\p\Quiet.smw`})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Run(ctx)
+
+	waitForState(t, s, job.ID, StateSucceeded)
+	require.NoError(t, s.webhooks.flush(5*time.Second))
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+
+	// The failed first delivery of the started event was retried
+	assert.Equal(t, []string{"/started", "/progress", "/completed"}, receiver.paths)
+	require.Len(t, receiver.events, 3)
+
+	assert.Equal(t, EventStarted, receiver.events[0].Event)
+	assert.Equal(t, StateRunning, receiver.events[0].Job.State)
+
+	assert.Equal(t, EventProgress, receiver.events[1].Event)
+	assert.Equal(t, "Compiling Lobby.smw\n", receiver.events[1].Message)
+
+	assert.Equal(t, EventCompleted, receiver.events[2].Event)
+	assert.Equal(t, job.ID, receiver.events[2].Job.ID)
+	assert.Equal(t, StateSucceeded, receiver.events[2].Job.State)
+	assert.Equal(t, "ok", receiver.events[2].Job.Output)
+}
+
+func TestServer_RejectsInvalidCallbacks(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer(RunnerFunc(func(context.Context, JobRequest) (Result, error) {
+		return Result{}, nil
+	}), logger.NewNoOpLogger())
+
+	for _, callback := range []string{"ftp://hooks.example.com/done", "/done", "https://"} {
+		_, err := s.Submit(JobRequest{File: `C:\p\Lobby.smw`, Callbacks: &Callbacks{Completed: callback}})
+		assert.ErrorContains(t, err, "completed callback", callback)
+	}
+
+	api := httptest.NewServer(s.Handler())
+	defer api.Close()
+
+	resp, err := http.Post(api.URL+"/api/v1/jobs", "application/json",
+		strings.NewReader(`{"file":"C:\\p\\Lobby.smw","callbacks":{"started":"mailto:ci@example.com"}}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
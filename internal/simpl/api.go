@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/windows"
 )
 
 // SimplProcessAPI is a concrete implementation of the SIMPL process management interface
@@ -25,3 +26,18 @@ func (s SimplProcessAPI) FindWindow(targetPid uint32, debug bool) (uintptr, stri
 func (s SimplProcessAPI) WaitForReady(hwnd uintptr, timeout time.Duration) bool {
 	return s.client.WaitForReady(hwnd, timeout)
 }
+
+// Process returns the running process with the given PID
+func (s SimplProcessAPI) Process(pid uint32) (windows.ProcessEntry, error) {
+	return windows.LookupProcess(pid)
+}
+
+// Processes lists the running processes for which match returns true
+func (s SimplProcessAPI) Processes(match func(windows.ProcessEntry) bool) ([]windows.ProcessEntry, error) {
+	return windows.FindProcesses(match)
+}
+
+// Terminate terminates p unless its PID now belongs to another process
+func (s SimplProcessAPI) Terminate(p windows.ProcessEntry) error {
+	return windows.TerminateEntry(p)
+}
@@ -0,0 +1,106 @@
+package simpl
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+const dialogConfirmation = "Confirmation"
+
+// orphanDialogTitles are the dialogs SIMPL Windows may leave open when smpc is interrupted mid-compile
+var orphanDialogTitles = map[string]bool{
+	"Compile Complete":                     true,
+	"Program Compilation":                  true,
+	"Compiling...":                         true,
+	"Operation Complete":                   true,
+	"Convert/Compile":                      true,
+	"Commented out Symbols and/or Devices": true,
+	"Incomplete Symbols":                   true,
+	dialogConfirmation:                     true,
+}
+
+// orphanedDialogs returns the known SIMPL Windows dialogs in list that belong to pid
+func orphanedDialogs(list []windows.WindowInfo, pid uint32) []windows.WindowInfo {
+	var dialogs []windows.WindowInfo
+
+	for _, w := range list {
+		if w.Pid == pid && orphanDialogTitles[w.Title] {
+			dialogs = append(dialogs, w)
+		}
+	}
+
+	return dialogs
+}
+
+// closeOrphanedDialogs closes any modal dialogs pid still has open, declining the save
+// prompt, so they neither block a graceful close nor linger on the desktop. It returns
+// how many it closed.
+func (c *Client) closeOrphanedDialogs(pid uint32) int {
+	if pid == 0 {
+		return 0
+	}
+
+	dialogs := orphanedDialogs(windows.EnumerateWindows(), pid)
+
+	for _, d := range dialogs {
+		c.log.Debug("Closing orphaned dialog",
+			slog.String("title", d.Title),
+			slog.Uint64("hwnd", uint64(d.Hwnd)),
+		)
+
+		if d.Title == dialogConfirmation && c.win.Window.FindAndClickButton(d.Hwnd, "&No") {
+			c.clock.Sleep(timeouts.WindowMessageDelay)
+			continue
+		}
+
+		c.win.Window.CloseWindow(d.Hwnd, d.Title)
+		c.clock.Sleep(timeouts.WindowMessageDelay)
+	}
+
+	return len(dialogs)
+}
+
+// track records the process behind pid, so it can later be told apart from another
+// process that has reused its PID
+func (c *Client) track(pid uint32) {
+	p, err := windows.LookupProcess(pid)
+	if err != nil {
+		c.log.Debug("Could not record SIMPL Windows process", slog.Uint64("pid", uint64(pid)), slog.Any("error", err))
+		return
+	}
+
+	c.tracked = p
+}
+
+// terminateProcessTree force terminates pid along with any converters or compilers it
+// spawned, returning the processes killed. If pid is the monitored process and has
+// since exited, a process that reused its PID is left alone.
+func (c *Client) terminateProcessTree(pid uint32) []windows.ProcessEntry {
+	root := windows.ProcessEntry{Pid: pid}
+	if c.tracked.Pid == pid {
+		root = c.tracked
+	}
+
+	killed, err := windows.TerminateTree(root)
+
+	for _, p := range killed {
+		c.log.Info("Terminated process",
+			slog.Uint64("pid", uint64(p.Pid)),
+			slog.String("exe", p.ExeFile),
+		)
+	}
+
+	if errors.Is(err, windows.ErrProcessReused) && len(killed) == 0 {
+		c.log.Warn("SIMPL Windows has already exited and its PID was reused; not terminating", slog.Uint64("pid", uint64(pid)))
+		return nil
+	}
+
+	if err != nil {
+		c.log.Warn("Failed to terminate all processes", slog.Uint64("pid", uint64(pid)), slog.Any("error", err))
+	}
+
+	return killed
+}
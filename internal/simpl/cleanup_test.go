@@ -0,0 +1,29 @@
+package simpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+func TestOrphanedDialogs(t *testing.T) {
+	t.Parallel()
+
+	list := []windows.WindowInfo{
+		{Hwnd: 1, Title: "Compile Complete", Pid: 100},
+		{Hwnd: 2, Title: "SIMPL Windows - [Program.smw]", Pid: 100},
+		{Hwnd: 3, Title: "Confirmation", Pid: 100},
+		{Hwnd: 4, Title: "Confirmation", Pid: 200},
+		{Hwnd: 5, Title: "Untitled - Notepad", Pid: 300},
+	}
+
+	dialogs := orphanedDialogs(list, 100)
+
+	assert.Equal(t, []windows.WindowInfo{
+		{Hwnd: 1, Title: "Compile Complete", Pid: 100},
+		{Hwnd: 3, Title: "Confirmation", Pid: 100},
+	}, dialogs)
+	assert.Empty(t, orphanedDialogs(list, 0))
+}
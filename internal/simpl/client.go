@@ -4,26 +4,67 @@ package simpl
 import (
 	"context"
 	"log/slog"
-	"strings"
 	"time"
 	"unsafe"
 
+	"github.com/Norgate-AV/smpc/internal/clock"
 	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/poll"
 	"github.com/Norgate-AV/smpc/internal/timeouts"
+	"github.com/Norgate-AV/smpc/internal/windowfilter"
 	"github.com/Norgate-AV/smpc/internal/windows"
 )
 
 // Client provides methods for interacting with SIMPL Windows processes
 type Client struct {
-	log logger.LoggerInterface
-	win *windows.Client
+	log   logger.LoggerInterface
+	win   *windows.Client
+	clock clock.Clock
+
+	polling poll.Settings  // Adaptive interval used by the waits and the window monitor
+	monitor *poll.Interval // Interval of the running window monitor, if any
+
+	monitorOpts windows.MonitorOptions // Filtering and rescanning for the window monitor
+
+	tracked windows.ProcessEntry // SIMPL Windows process being monitored, checked for PID reuse before termination
 }
 
 // NewClient creates a new SIMPL Windows client
 func NewClient(log logger.LoggerInterface) *Client {
+	return NewClientWithClock(log, clock.New())
+}
+
+// NewClientWithClock creates a new SIMPL Windows client using the provided clock for all waits
+func NewClientWithClock(log logger.LoggerInterface, clk clock.Clock) *Client {
 	return &Client{
-		log: log,
-		win: windows.NewClient(log),
+		log:     log,
+		win:     windows.NewClient(log),
+		clock:   clk,
+		polling: poll.DefaultSettings(),
+	}
+}
+
+// SetPolling replaces the adaptive polling settings used by subsequent waits and monitors
+func (c *Client) SetPolling(s poll.Settings) {
+	c.polling = s
+}
+
+// SetMonitorFilter limits the windows reported by subsequently started monitors
+func (c *Client) SetMonitorFilter(f *windowfilter.Filter) {
+	c.monitorOpts.Filter = f
+}
+
+// SetDialogUpdates makes subsequently started monitors rescan open dialogs and
+// publish changes to their text on Updates
+func (c *Client) SetDialogUpdates(enabled bool) {
+	c.monitorOpts.RescanDialogs = enabled
+}
+
+// PollFast makes the window monitor poll at its minimum interval again, e.g. right
+// after a compile has been triggered and dialogs are about to appear
+func (c *Client) PollFast() {
+	if c.monitor != nil {
+		c.monitor.Reset()
 	}
 }
 
@@ -61,45 +102,34 @@ func (c *Client) findWindowWithTracking(targetPid uint32, debug bool, seenWindow
 	var mainWindow windows.WindowInfo
 	var splashWindow windows.WindowInfo
 
+search:
 	for _, w := range windowsList {
-		if w.Pid == targetPid {
-			// Only log if debug is enabled AND we haven't seen this window before
-			shouldLog := debug && (seenWindows == nil || !seenWindows[w.Hwnd])
-			if shouldLog {
-				c.log.Debug("Window found",
-					slog.String("title", w.Title),
-					slog.Uint64("hwnd", uint64(w.Hwnd)),
-				)
-				if seenWindows != nil {
-					seenWindows[w.Hwnd] = true
-				}
-			}
-
-			// Skip splash screens and loading dialogs
-			title := strings.ToLower(w.Title)
-
-			// If window title contains .smw, it's definitely the main window with file loaded
-			if strings.Contains(w.Title, ".smw") {
-				mainWindow = w
-				break
-			}
+		if w.Pid != targetPid {
+			continue
+		}
 
-			// Generic "SIMPL Windows" is likely the splash screen - remember it but keep looking
-			if w.Title == "SIMPL Windows" {
-				splashWindow = w
-				continue
+		class := windows.GetClassName(w.Hwnd)
+
+		// Only log if debug is enabled AND we haven't seen this window before
+		shouldLog := debug && (seenWindows == nil || !seenWindows[w.Hwnd])
+		if shouldLog {
+			c.log.Detail("Window found",
+				slog.String("title", w.Title),
+				slog.String("class", class),
+				slog.Uint64("hwnd", uint64(w.Hwnd)),
+			)
+			if seenWindows != nil {
+				seenWindows[w.Hwnd] = true
 			}
+		}
 
-			// Look for other SIMPL-related windows that aren't splash/about
-			if !strings.Contains(title, "splash") &&
-				!strings.Contains(title, "loading") &&
-				!strings.Contains(title, "about") &&
-				len(w.Title) > 5 {
-				if strings.Contains(title, "simpl") {
-					mainWindow = w
-					break
-				}
-			}
+		switch classifyWindow(w.Title, class) {
+		case windowMain:
+			mainWindow = w
+			break search
+		case windowSplash:
+			// Remember it but keep looking for the main window
+			splashWindow = w
 		}
 	}
 
@@ -124,22 +154,28 @@ func (c *Client) findWindowWithTracking(targetPid uint32, debug bool, seenWindow
 
 // WaitForReady waits for a window to become fully responsive
 func (c *Client) WaitForReady(hwnd uintptr, timeout time.Duration) bool {
-	deadline := time.Now().Add(timeout)
-	elapsed := 0
+	deadline := c.clock.Now().Add(timeout)
+	interval := poll.NewInterval(c.polling)
+
+	var lastDebug time.Time
 
 	c.log.Debug("Waiting for window ready state",
 		slog.Uint64("hwnd", uint64(hwnd)),
 		slog.String("timeout", timeout.String()),
 	)
 
-	for time.Now().Before(deadline) {
-		debug := elapsed%30 == 0 // Debug every 3 seconds
+	for c.clock.Now().Before(deadline) {
+		// Debug every 3 seconds
+		debug := c.clock.Since(lastDebug) >= 3*time.Second
+		if debug {
+			lastDebug = c.clock.Now()
+		}
 
 		if c.isWindowResponsive(hwnd, debug) {
 			// Window is responsive, wait a bit more to ensure stability
 			consecutiveResponses := 0
 			for range 3 {
-				time.Sleep(timeouts.StabilityCheckInterval)
+				c.clock.Sleep(timeouts.StabilityCheckInterval)
 				if c.isWindowResponsive(hwnd, false) {
 					consecutiveResponses++
 				}
@@ -151,8 +187,7 @@ func (c *Client) WaitForReady(hwnd uintptr, timeout time.Duration) bool {
 			}
 		}
 
-		time.Sleep(timeouts.StatePollingInterval)
-		elapsed++
+		c.clock.Sleep(interval.Next())
 	}
 
 	c.log.Debug("Timeout waiting for window to be ready")
@@ -162,13 +197,24 @@ func (c *Client) WaitForReady(hwnd uintptr, timeout time.Duration) bool {
 // WaitForAppear waits for the SIMPL Windows main window to appear for a specific process
 // targetPid must be a valid process ID - passing 0 will immediately return failure
 func (c *Client) WaitForAppear(targetPid uint32, timeout time.Duration) (uintptr, bool) {
-	deadline := time.Now().Add(timeout)
+	return c.WaitForAppearContext(context.Background(), targetPid, timeout)
+}
+
+// WaitForAppearContext is WaitForAppear, giving up early when ctx is canceled
+func (c *Client) WaitForAppearContext(ctx context.Context, targetPid uint32, timeout time.Duration) (uintptr, bool) {
+	deadline := c.clock.Now().Add(timeout)
 	seenWindows := make(map[uintptr]bool) // Track windows we've already logged
 	loggedSplashOnly := false             // Track if we've logged "splash screen detected" message
+	interval := poll.NewInterval(c.polling)
 
 	c.log.Debug("Searching for window", slog.Uint64("pid", uint64(targetPid)))
 
-	for time.Now().Before(deadline) {
+	for c.clock.Now().Before(deadline) {
+		if ctx.Err() != nil {
+			c.log.Debug("Stopped waiting for window", slog.Any("reason", context.Cause(ctx)))
+			return 0, false
+		}
+
 		// Check for the main SIMPL Windows window, passing seenWindows for tracking
 		result := c.findWindowWithTracking(targetPid, true, seenWindows)
 
@@ -180,9 +226,12 @@ func (c *Client) WaitForAppear(targetPid uint32, timeout time.Duration) (uintptr
 		if result.foundSplash && !loggedSplashOnly {
 			c.log.Debug("Found splash screen, continuing to wait for main window")
 			loggedSplashOnly = true
+
+			// The main window usually follows the splash screen closely
+			interval.Reset()
 		}
 
-		time.Sleep(timeouts.StatePollingInterval)
+		c.clock.Sleep(interval.Next())
 	}
 
 	c.log.Debug("Timeout reached, performing final detailed check")
@@ -195,88 +244,59 @@ func (c *Client) WaitForAppear(targetPid uint32, timeout time.Duration) (uintptr
 	return 0, false
 }
 
-// Cleanup ensures SIMPL Windows is properly closed, with fallback to force termination
-func (c *Client) Cleanup(hwnd uintptr, pid uint32) {
-	if hwnd == 0 {
-		return
-	}
-
-	// Check if the window still exists before attempting cleanup
-	if !windows.IsWindow(hwnd) {
-		return
+// Cleanup closes SIMPL Windows if its main window is still open, escalating to
+// terminating it (see Close)
+func (c *Client) Cleanup(hwnd uintptr, pid uint32) CloseReport {
+	if hwnd == 0 || !windows.IsWindow(hwnd) {
+		return CloseReport{Closed: true}
 	}
 
 	c.log.Debug("Cleaning up...")
-
-	// Try to close gracefully
-	c.win.Window.CloseWindow(hwnd, "SIMPL Windows")
-
-	// Poll for up to 3 seconds to see if window closes
-	maxWait := 3 * time.Second
-	pollInterval := 200 * time.Millisecond
-	deadline := time.Now().Add(maxWait)
-
-	for time.Now().Before(deadline) {
-		if !windows.IsWindow(hwnd) {
-			c.log.Debug("Window closed successfully")
-			return
-		}
-
-		time.Sleep(pollInterval)
-	}
-
-	// Window still exists after waiting - force terminate
-	c.log.Warn("SIMPL Windows did not close properly after waiting")
-	if pid != 0 {
-		c.log.Debug("Attempting to force terminate process", slog.Uint64("pid", uint64(pid)))
-		_ = windows.TerminateProcess(pid)
-	}
+	return c.Close(hwnd, pid, DefaultCloseTimeouts())
 }
 
-// ForceCleanup attempts to forcefully close SIMPL Windows using the known PID.
-// It tries two approaches in order:
-// 1. Use hwnd if available (graceful close with PID for force termination)
-// 2. Use known PID (forced termination)
-func (c *Client) ForceCleanup(hwnd uintptr, knownPid uint32) {
-	// Strategy 1: Use hwnd if available for graceful close
-	if hwnd != 0 {
-		c.Cleanup(hwnd, knownPid)
-		return
-	}
-
-	// Strategy 2: Use known PID for forced termination
-	if knownPid != 0 {
-		c.log.Debug("Force terminating with known PID", slog.Uint64("pid", uint64(knownPid)))
-		_ = windows.TerminateProcess(knownPid)
-		return
+// ForceCleanup closes SIMPL Windows using whichever of hwnd and knownPid are known,
+// escalating to terminating it (see Close)
+func (c *Client) ForceCleanup(hwnd uintptr, knownPid uint32) CloseReport {
+	if hwnd == 0 && knownPid == 0 {
+		c.log.Warn("Unable to cleanup SIMPL Windows - no hwnd or PID provided")
+		return CloseReport{}
 	}
 
-	c.log.Warn("Unable to cleanup SIMPL Windows - no hwnd or PID provided")
+	return c.Close(hwnd, knownPid, DefaultCloseTimeouts())
 }
 
-// StartMonitoring starts a background goroutine that monitors SIMPL Windows dialogs for a specific PID
-// Returns a function to stop the monitoring
-func (c *Client) StartMonitoring(pid uint32) func() {
-	ctx, cancel := context.WithCancel(context.Background())
+// Events returns the event bus that receives window events from StartMonitoring
+func (c *Client) Events() *windows.EventBus {
+	return c.win.Events
+}
 
-	go func() {
-		// Init channel
-		windows.MonitorCh = make(chan windows.WindowEvent, 64)
+// Updates returns the event bus that receives changes to open dialogs' text, if
+// enabled with SetDialogUpdates
+func (c *Client) Updates() *windows.EventBus {
+	return c.win.Updates
+}
 
-		if pid == 0 {
-			c.log.Warn("Window monitor started with PID=0, monitoring all processes (not recommended)")
-			c.win.Monitor.StartWindowMonitor(ctx, 0, timeouts.MonitorPollingInterval)
-		} else {
-			c.log.Debug("Window monitor targeting SIMPL PID", slog.Uint64("pid", uint64(pid)))
-			c.win.Monitor.StartWindowMonitor(ctx, pid, timeouts.MonitorPollingInterval)
-		}
+// StartMonitoring starts a background goroutine that monitors SIMPL Windows dialogs for a specific PID.
+// Monitoring stops when ctx is canceled or the returned function is called; the function
+// waits for the monitor to exit, so no goroutine outlives it.
+func (c *Client) StartMonitoring(ctx context.Context, pid uint32) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	interval := poll.NewInterval(c.polling)
+	c.monitor = interval
+
+	if pid == 0 {
+		c.log.Warn("Window monitor started with PID=0, monitoring all processes (not recommended; see --monitor-include)")
+	} else {
+		c.log.Debug("Window monitor targeting SIMPL PID", slog.Uint64("pid", uint64(pid)))
+		c.track(pid)
+	}
 
-		// Wait for cancellation
-		<-ctx.Done()
-	}()
+	done := c.win.Monitor.StartWindowMonitor(ctx, pid, interval, c.monitorOpts)
 
 	return func() {
 		cancel()
+		<-done
 	}
 }
 
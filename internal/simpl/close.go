@@ -0,0 +1,236 @@
+package simpl
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/clock"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// CloseStep is a step of the escalation used to close SIMPL Windows
+type CloseStep string
+
+const (
+	CloseStepClose     CloseStep = "close"     // WM_CLOSE to the main window
+	CloseStepConfirm   CloseStep = "confirm"   // Declining the save prompt and closing the dialogs left open
+	CloseStepQuit      CloseStep = "quit"      // WM_QUIT to the main window's thread
+	CloseStepTerminate CloseStep = "terminate" // Terminating the process tree
+)
+
+// closePollInterval is how often a close step checks whether SIMPL Windows has exited
+const closePollInterval = 200 * time.Millisecond
+
+// CloseTimeouts are how long each step waits for SIMPL Windows to exit before the
+// next one is tried
+type CloseTimeouts struct {
+	Close     time.Duration
+	Confirm   time.Duration
+	Quit      time.Duration
+	Terminate time.Duration
+}
+
+// DefaultCloseTimeouts returns the timeouts used by Cleanup and ForceCleanup
+func DefaultCloseTimeouts() CloseTimeouts {
+	return CloseTimeouts{
+		Close:     3 * time.Second,
+		Confirm:   3 * time.Second,
+		Quit:      2 * time.Second,
+		Terminate: timeouts.ProcessExitTimeout,
+	}
+}
+
+// CloseAttempt is a step tried while closing SIMPL Windows
+type CloseAttempt struct {
+	Step    CloseStep
+	Elapsed time.Duration
+	Closed  bool // Whether SIMPL Windows had exited by the end of the step
+}
+
+// CloseReport describes how SIMPL Windows was closed
+type CloseReport struct {
+	Closed     bool                   // Whether SIMPL Windows has exited
+	Step       CloseStep              // Step that closed it; "" if it was not running or never closed
+	Attempts   []CloseAttempt         // Steps tried, in order; steps with nothing to do are skipped
+	Terminated []windows.ProcessEntry // Processes killed by CloseStepTerminate
+}
+
+// closeStep is one step of the escalation. act starts it, returning false when there
+// is nothing for it to do; poll, if set, runs on every check while the step waits.
+type closeStep struct {
+	step    CloseStep
+	timeout time.Duration
+	act     func() bool
+	poll    func()
+}
+
+// escalate runs steps in order until closed reports true, giving each up to its
+// timeout to take effect
+func escalate(clk clock.Clock, closed func() bool, steps []closeStep) CloseReport {
+	var report CloseReport
+
+	if closed() {
+		report.Closed = true
+		return report
+	}
+
+	for _, s := range steps {
+		start := clk.Now()
+		if !s.act() {
+			continue
+		}
+
+		deadline := start.Add(s.timeout)
+		done := closed()
+
+		for !done && clk.Now().Before(deadline) {
+			clk.Sleep(closePollInterval)
+
+			if s.poll != nil {
+				s.poll()
+			}
+
+			done = closed()
+		}
+
+		report.Attempts = append(report.Attempts, CloseAttempt{Step: s.step, Elapsed: clk.Since(start), Closed: done})
+
+		if done {
+			report.Closed = true
+			report.Step = s.step
+			break
+		}
+	}
+
+	return report
+}
+
+// Close closes SIMPL Windows, escalating from a request to close its main window,
+// through declining the save prompt and ending its message loop, to terminating its
+// process tree, until it exits. With pid, SIMPL Windows has closed once the process
+// has exited; without it, once hwnd is gone. A zero hwnd is looked up from pid.
+func (c *Client) Close(hwnd uintptr, pid uint32, t CloseTimeouts) CloseReport {
+	if hwnd == 0 && pid != 0 {
+		hwnd, _ = c.FindWindow(pid, false)
+	}
+
+	// Taken now, as the thread can outlive the window
+	var thread uint32
+	if hwnd != 0 {
+		thread = windows.GetWindowThreadId(hwnd)
+	}
+
+	process := c.process(pid)
+
+	closed := func() bool {
+		if pid != 0 {
+			return !windows.IsRunning(process)
+		}
+
+		return hwnd == 0 || !windows.IsWindow(hwnd)
+	}
+
+	// Answering the save prompt cancels the close, so it is requested again
+	answerDialogs := func() bool {
+		if c.closeOrphanedDialogs(pid) == 0 {
+			return false
+		}
+
+		if hwnd != 0 && windows.IsWindow(hwnd) {
+			c.win.Window.CloseWindow(hwnd, "SIMPL Windows")
+		}
+
+		return true
+	}
+
+	var killed []windows.ProcessEntry
+
+	report := escalate(c.clock, closed, []closeStep{
+		{
+			step:    CloseStepClose,
+			timeout: t.Close,
+			act: func() bool {
+				if hwnd == 0 || !windows.IsWindow(hwnd) {
+					return false
+				}
+
+				c.win.Window.CloseWindow(hwnd, "SIMPL Windows")
+				return true
+			},
+		},
+		{
+			step:    CloseStepConfirm,
+			timeout: t.Confirm,
+			act:     answerDialogs,
+			poll:    func() { answerDialogs() },
+		},
+		{
+			step:    CloseStepQuit,
+			timeout: t.Quit,
+			act: func() bool {
+				if thread == 0 {
+					return false
+				}
+
+				if err := windows.PostQuit(thread); err != nil {
+					c.log.Debug("Could not post WM_QUIT", slog.Any("error", err))
+					return false
+				}
+
+				return true
+			},
+		},
+		{
+			step:    CloseStepTerminate,
+			timeout: t.Terminate,
+			act: func() bool {
+				if pid == 0 {
+					return false
+				}
+
+				killed = c.terminateProcessTree(pid)
+				return true
+			},
+		},
+	})
+
+	report.Terminated = killed
+	c.logCloseReport(report, pid)
+
+	return report
+}
+
+// process returns the process to watch for pid: the monitored one if it is pid, so
+// a reused PID is not mistaken for it
+func (c *Client) process(pid uint32) windows.ProcessEntry {
+	if c.tracked.Pid == pid {
+		return c.tracked
+	}
+
+	if p, err := windows.LookupProcess(pid); err == nil {
+		return p
+	}
+
+	return windows.ProcessEntry{Pid: pid}
+}
+
+// logCloseReport logs the steps it took to close SIMPL Windows
+func (c *Client) logCloseReport(report CloseReport, pid uint32) {
+	for _, a := range report.Attempts {
+		c.log.Debug("Close step finished",
+			slog.String("step", string(a.Step)),
+			slog.Duration("elapsed", a.Elapsed),
+			slog.Bool("closed", a.Closed),
+		)
+	}
+
+	switch {
+	case !report.Closed:
+		c.log.Warn("SIMPL Windows did not close", slog.Uint64("pid", uint64(pid)))
+	case report.Step == CloseStepQuit || report.Step == CloseStepTerminate:
+		c.log.Warn("SIMPL Windows did not close gracefully", slog.String("step", string(report.Step)))
+	case report.Step != "":
+		c.log.Debug("SIMPL Windows closed", slog.String("step", string(report.Step)))
+	}
+}
@@ -0,0 +1,80 @@
+package simpl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/testutil"
+)
+
+func TestEscalate(t *testing.T) {
+	t.Parallel()
+
+	clk := testutil.NewFakeClock()
+
+	var (
+		acted  []CloseStep
+		closed bool
+		polls  int
+	)
+
+	step := func(s CloseStep, timeout time.Duration, applicable bool, closes bool) closeStep {
+		return closeStep{
+			step:    s,
+			timeout: timeout,
+			act: func() bool {
+				if !applicable {
+					return false
+				}
+
+				acted = append(acted, s)
+				closed = closes
+				return true
+			},
+			poll: func() { polls++ },
+		}
+	}
+
+	report := escalate(clk, func() bool { return closed }, []closeStep{
+		step(CloseStepClose, time.Second, true, false),
+		step(CloseStepConfirm, time.Second, false, false),
+		step(CloseStepQuit, time.Second, true, true),
+		step(CloseStepTerminate, time.Second, true, true),
+	})
+
+	assert.Equal(t, []CloseStep{CloseStepClose, CloseStepQuit}, acted, "skips steps with nothing to do and stops once closed")
+	assert.True(t, report.Closed)
+	assert.Equal(t, CloseStepQuit, report.Step)
+	assert.Equal(t, []CloseAttempt{
+		{Step: CloseStepClose, Elapsed: time.Second, Closed: false},
+		{Step: CloseStepQuit, Elapsed: 0, Closed: true},
+	}, report.Attempts)
+	assert.Equal(t, 5, polls, "polled until the close step timed out")
+}
+
+func TestEscalate_AlreadyClosed(t *testing.T) {
+	t.Parallel()
+
+	report := escalate(testutil.NewFakeClock(), func() bool { return true }, []closeStep{
+		{step: CloseStepClose, timeout: time.Second, act: func() bool {
+			t.Fatal("no step should run")
+			return true
+		}},
+	})
+
+	assert.Equal(t, CloseReport{Closed: true}, report)
+}
+
+func TestEscalate_NeverCloses(t *testing.T) {
+	t.Parallel()
+
+	report := escalate(testutil.NewFakeClock(), func() bool { return false }, []closeStep{
+		{step: CloseStepTerminate, timeout: time.Second, act: func() bool { return true }},
+	})
+
+	assert.False(t, report.Closed)
+	assert.Empty(t, report.Step)
+	assert.Equal(t, []CloseAttempt{{Step: CloseStepTerminate, Elapsed: time.Second}}, report.Attempts)
+}
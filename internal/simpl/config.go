@@ -7,11 +7,27 @@ import (
 
 const DefaultSimplWindowsPath = "C:\\Program Files (x86)\\Crestron\\Simpl\\smpwin.exe"
 
+// SimplWindowsPathEnv names the environment variable that overrides DefaultSimplWindowsPath
+const SimplWindowsPathEnv = "SIMPL_WINDOWS_PATH"
+
+// simplWindowsPath is the --simpl-path override, which takes precedence over SIMPL_WINDOWS_PATH
+var simplWindowsPath string
+
+// SetSimplWindowsPath makes GetSimplWindowsPath return path for the rest of the
+// process, so one invocation can use a different SIMPL Windows install. "" removes it.
+func SetSimplWindowsPath(path string) {
+	simplWindowsPath = path
+}
+
 // GetSimplWindowsPath returns the path to the SIMPL Windows executable.
-// It checks the SIMPL_WINDOWS_PATH environment variable first,
-// falling back to the default installation path if not set.
+// It returns the SetSimplWindowsPath override if there is one, then checks the
+// SIMPL_WINDOWS_PATH environment variable, falling back to the default installation path.
 func GetSimplWindowsPath() string {
-	if envPath := os.Getenv("SIMPL_WINDOWS_PATH"); envPath != "" {
+	if simplWindowsPath != "" {
+		return simplWindowsPath
+	}
+
+	if envPath := os.Getenv(SimplWindowsPathEnv); envPath != "" {
 		return envPath
 	}
 
@@ -25,7 +41,12 @@ func ValidateSimplWindowsInstallation() error {
 
 	var err error
 	if _, err = os.Stat(path); os.IsNotExist(err) {
-		if os.Getenv("SIMPL_WINDOWS_PATH") != "" {
+		if simplWindowsPath != "" {
+			return fmt.Errorf("SIMPL Windows not found at custom path: %s\n"+
+				"Please verify the --simpl-path option is correct", path)
+		}
+
+		if os.Getenv(SimplWindowsPathEnv) != "" {
 			return fmt.Errorf("SIMPL Windows not found at custom path: %s\n"+
 				"Please verify the SIMPL_WINDOWS_PATH environment variable is correct", path)
 		}
@@ -71,3 +71,20 @@ func TestValidateSimplWindowsInstallation_CustomPathNotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), nonExistentPath)
 	assert.Contains(t, err.Error(), "SIMPL_WINDOWS_PATH")
 }
+
+func TestSetSimplWindowsPath(t *testing.T) {
+	// Cannot use t.Parallel() - modifies environment variables and the override
+	t.Setenv("SIMPL_WINDOWS_PATH", `D:\Env\smpwin.exe`)
+
+	SetSimplWindowsPath(`E:\Simpl 4.1400\smpwin.exe`)
+	defer SetSimplWindowsPath("")
+
+	assert.Equal(t, `E:\Simpl 4.1400\smpwin.exe`, GetSimplWindowsPath())
+
+	err := ValidateSimplWindowsInstallation()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "--simpl-path")
+
+	SetSimplWindowsPath("")
+	assert.Equal(t, `D:\Env\smpwin.exe`, GetSimplWindowsPath())
+}
@@ -0,0 +1,75 @@
+package simpl
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// Instance is a running SIMPL Windows process
+type Instance struct {
+	Pid      uint32
+	Programs []string // Titles of its windows that show an open program
+
+	// OtherSession is set for instances in another user's logon session, such as
+	// another Remote Desktop user on a shared build server. Their windows cannot be
+	// seen and they cannot be terminated, so Programs is always empty.
+	OtherSession bool
+	Session      uint32 // Logon session the instance runs in
+}
+
+// HasProgram reports whether the instance has the program at path open
+func (i Instance) HasProgram(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+
+	for _, title := range i.Programs {
+		if strings.Contains(strings.ToLower(title), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FindInstances returns the SIMPL Windows processes that are already running
+func FindInstances() ([]Instance, error) {
+	processes, err := windows.SnapshotProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	return instancesFrom(processes, windows.EnumerateWindows(), windows.CurrentSession()), nil
+}
+
+// instancesFrom matches SIMPL Windows processes to the windows they own and marks
+// those running outside session. A process whose session is unknown is assumed to share it.
+func instancesFrom(processes []windows.ProcessEntry, list []windows.WindowInfo, session uint32) []Instance {
+	exe := strings.ToLower(filepath.Base(GetSimplWindowsPath()))
+
+	var instances []Instance
+
+	for _, p := range processes {
+		if strings.ToLower(p.ExeFile) != exe {
+			continue
+		}
+
+		inst := Instance{
+			Pid:          p.Pid,
+			Session:      p.Session,
+			OtherSession: p.Session != session && p.Session != windows.UnknownSession && session != windows.UnknownSession,
+		}
+
+		for _, w := range list {
+			if w.Pid == p.Pid && strings.Contains(strings.ToLower(w.Title), ".smw") {
+				inst.Programs = append(inst.Programs, w.Title)
+			}
+		}
+
+		instances = append(instances, inst)
+	}
+
+	sort.Slice(instances, func(i, j int) bool { return instances[i].Pid < instances[j].Pid })
+	return instances
+}
@@ -0,0 +1,68 @@
+package simpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+func TestInstancesFrom(t *testing.T) {
+	processes := []windows.ProcessEntry{
+		{Pid: 300, ExeFile: "SMPWIN.EXE"},
+		{Pid: 100, ExeFile: "smpwin.exe"},
+		{Pid: 200, ExeFile: "notepad.exe"},
+	}
+
+	list := []windows.WindowInfo{
+		{Hwnd: 1, Pid: 100, Title: "SIMPL Windows - [Lobby.smw]"},
+		{Hwnd: 2, Pid: 100, Title: "Compile Complete"},
+		{Hwnd: 3, Pid: 200, Title: "Boardroom.smw - Notepad"},
+	}
+
+	instances := instancesFrom(processes, list, 0)
+
+	assert.Equal(t, []Instance{
+		{Pid: 100, Programs: []string{"SIMPL Windows - [Lobby.smw]"}},
+		{Pid: 300},
+	}, instances)
+
+	assert.True(t, instances[0].HasProgram(`C:\Programs\lobby.smw`))
+	assert.False(t, instances[0].HasProgram(`C:\Programs\Boardroom.smw`))
+	assert.False(t, instances[1].HasProgram(`C:\Programs\Lobby.smw`))
+}
+
+func TestInstancesFrom_OtherSession(t *testing.T) {
+	processes := []windows.ProcessEntry{
+		{Pid: 100, ExeFile: "smpwin.exe", Session: 2},
+		{Pid: 200, ExeFile: "smpwin.exe", Session: 3},
+		{Pid: 300, ExeFile: "smpwin.exe", Session: windows.UnknownSession},
+	}
+
+	assert.Equal(t, []Instance{
+		{Pid: 100, Session: 2},
+		{Pid: 200, Session: 3, OtherSession: true},
+		{Pid: 300, Session: windows.UnknownSession},
+	}, instancesFrom(processes, nil, 2))
+}
+
+func TestInterferingFrom(t *testing.T) {
+	processes := []windows.ProcessEntry{
+		{Pid: 400, ExeFile: "D3Pro.exe"},
+		{Pid: 100, ExeFile: "smpwin.exe"},
+		{Pid: 200, ExeFile: "VTPro.exe"},
+		{Pid: 300, ExeFile: "notepad.exe"},
+	}
+
+	list := []windows.WindowInfo{
+		{Hwnd: 1, Pid: 200, Title: "VT Pro-e - [Lobby.vtp]"},
+		{Hwnd: 2, Pid: 200, Title: "Compiling Project"},
+		{Hwnd: 3, Pid: 400, Title: ""},
+	}
+
+	assert.Equal(t, []InterferingApp{
+		{Pid: 200, Name: "VT Pro-e", Windows: []string{"VT Pro-e - [Lobby.vtp]", "Compiling Project"}},
+		{Pid: 400, Name: "D3 Pro"},
+	}, interferingFrom(processes, list))
+}
@@ -0,0 +1,60 @@
+package simpl
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// interferingExes maps the executables of other Crestron tools, lower-cased, to their
+// product names. While they run they can hold modal dialogs or locks on the shared
+// device databases that make SIMPL Windows hang at launch.
+var interferingExes = map[string]string{
+	"vtpro.exe":       "VT Pro-e",
+	"vtpro-e.exe":     "VT Pro-e",
+	"toolbox.exe":     "Crestron Toolbox",
+	"ctrntoolbox.exe": "Crestron Toolbox",
+	"d3pro.exe":       "D3 Pro",
+}
+
+// InterferingApp is a running Crestron tool that can stop SIMPL Windows from starting
+type InterferingApp struct {
+	Pid     uint32
+	Name    string   // Product name, e.g. "VT Pro-e"
+	Windows []string // Titles of its visible windows, which may include a modal dialog
+}
+
+// FindInterferingApps returns the other Crestron tools that are running
+func FindInterferingApps() ([]InterferingApp, error) {
+	processes, err := windows.SnapshotProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	return interferingFrom(processes, windows.EnumerateWindows()), nil
+}
+
+// interferingFrom picks the Crestron tools out of a process list, with their windows
+func interferingFrom(processes []windows.ProcessEntry, list []windows.WindowInfo) []InterferingApp {
+	var apps []InterferingApp
+
+	for _, p := range processes {
+		name, ok := interferingExes[strings.ToLower(p.ExeFile)]
+		if !ok {
+			continue
+		}
+
+		app := InterferingApp{Pid: p.Pid, Name: name}
+		for _, w := range list {
+			if w.Pid == p.Pid && w.Title != "" {
+				app.Windows = append(app.Windows, w.Title)
+			}
+		}
+
+		apps = append(apps, app)
+	}
+
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Pid < apps[j].Pid })
+	return apps
+}
@@ -0,0 +1,21 @@
+package simpl
+
+import "strings"
+
+// licenseKeywords identify the licensing, registration and activation prompts SIMPL
+// Windows can show on a fresh machine before its main window
+var licenseKeywords = []string{"licens", "regist", "activat", "trial period", "evaluation", "serial number"}
+
+// IsLicenseDialog reports whether a dialog with this title and control text is a
+// licensing or registration prompt
+func IsLicenseDialog(title string, texts []string) bool {
+	all := strings.ToLower(title + "\n" + strings.Join(texts, "\n"))
+
+	for _, keyword := range licenseKeywords {
+		if strings.Contains(all, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
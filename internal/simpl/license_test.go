@@ -0,0 +1,17 @@
+package simpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLicenseDialog(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsLicenseDialog("Software Registration", nil))
+	assert.True(t, IsLicenseDialog("SIMPL Windows", []string{"Your license could not be validated.", "&OK"}))
+	assert.True(t, IsLicenseDialog("Crestron", []string{"Please ACTIVATE this copy of SIMPL Windows"}))
+	assert.False(t, IsLicenseDialog("Operation Complete", []string{"Operation Complete."}))
+	assert.False(t, IsLicenseDialog("Compile Complete", []string{"Program Compilation Statistics"}))
+}
@@ -0,0 +1,57 @@
+package simpl
+
+import (
+	"strings"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// windowKind is what a top-level window of the SIMPL Windows process appears to be
+type windowKind int
+
+const (
+	windowOther windowKind = iota
+	windowMain
+	windowSplash
+)
+
+// mainFrameClasses are prefixes of the window classes MFC registers for main frame
+// windows, as used by SIMPL Windows. Matching on class finds the main window while
+// its title is still generic.
+var mainFrameClasses = []string{"AfxMDIFrame", "AfxFrameOrView"}
+
+// classifyWindow decides from its title and class whether a window of the SIMPL
+// Windows process is the main window, the splash screen, or something else. The title
+// only counts once the class has ruled out dialogs.
+func classifyWindow(title, class string) windowKind {
+	if class == windows.DialogClass {
+		return windowOther
+	}
+
+	for _, prefix := range mainFrameClasses {
+		if strings.HasPrefix(class, prefix) {
+			return windowMain
+		}
+	}
+
+	lower := strings.ToLower(title)
+
+	// A loaded program's name in the title means the main window, whatever the name contains
+	if strings.Contains(lower, ".smw") {
+		return windowMain
+	}
+
+	// A generic "SIMPL Windows" title is most likely the splash screen
+	if title == "SIMPL Windows" {
+		return windowSplash
+	}
+
+	if len(title) > 5 && strings.Contains(lower, "simpl") &&
+		!strings.Contains(lower, "splash") &&
+		!strings.Contains(lower, "loading") &&
+		!strings.Contains(lower, "about") {
+		return windowMain
+	}
+
+	return windowOther
+}
@@ -0,0 +1,33 @@
+package simpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+func TestClassifyWindow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title string
+		class string
+		want  windowKind
+	}{
+		{"SIMPL Windows - [Lobby.smw]", "Afx:00400000:b:00010003", windowMain},
+		{"SIMPL Windows - [About Splash.smw]", "Afx:00400000:b:00010003", windowMain},
+		{"SIMPL Windows", "AfxMDIFrame140u", windowMain},
+		{"", "AfxFrameOrView140su", windowMain},
+		{"SIMPL Windows", "Afx:00400000:0", windowSplash},
+		{"SIMPL Windows - Loading", "Afx:00400000:0", windowOther},
+		{"About SIMPL Windows", windows.DialogClass, windowOther},
+		{"Save Lobby.smw?", windows.DialogClass, windowOther},
+		{"Untitled - Notepad", "Notepad", windowOther},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, classifyWindow(tt.title, tt.class), "%q (%s)", tt.title, tt.class)
+	}
+}
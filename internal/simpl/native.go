@@ -0,0 +1,105 @@
+package simpl
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// fileArg is replaced with the program path in native compile switches
+const fileArg = "{file}"
+
+// nativeSwitch describes the command-line compile switches of a SIMPL Windows release
+type nativeSwitch struct {
+	MinVersion   string   // First smpwin.exe file version supporting the switches
+	Compile      []string // Arguments for Compile, with fileArg in place of the program path
+	RecompileAll []string // Arguments for Recompile All
+}
+
+// nativeSwitches lists SIMPL Windows releases known to compile from the command line,
+// newest first. No release has been confirmed to do so yet; add entries here as they
+// are verified so --prefer-native can use them.
+var nativeSwitches []nativeSwitch
+
+// NativeCapabilities describes the non-GUI compile paths an installed SIMPL Windows supports
+type NativeCapabilities struct {
+	Version string // smpwin.exe file version ("" if it could not be read)
+
+	compile      []string
+	recompileAll []string
+}
+
+// CommandLine reports whether smpwin.exe can compile from the command line
+func (n NativeCapabilities) CommandLine() bool {
+	return len(n.compile) > 0
+}
+
+// CompileArgs returns the smpwin.exe arguments that compile file without the GUI
+func (n NativeCapabilities) CompileArgs(file string, recompileAll bool) []string {
+	template := n.compile
+	if recompileAll && len(n.recompileAll) > 0 {
+		template = n.recompileAll
+	}
+
+	args := make([]string, len(template))
+	for i, arg := range template {
+		args[i] = strings.ReplaceAll(arg, fileArg, file)
+	}
+
+	return args
+}
+
+// ProbeNativeCapabilities inspects the SIMPL Windows executable at exePath
+func ProbeNativeCapabilities(exePath string) (NativeCapabilities, error) {
+	version, err := windows.GetFileVersion(exePath)
+	if err != nil {
+		return NativeCapabilities{}, err
+	}
+
+	return capabilitiesFor(version, nativeSwitches), nil
+}
+
+// capabilitiesFor matches version against a table of known switches
+func capabilitiesFor(version string, table []nativeSwitch) NativeCapabilities {
+	caps := NativeCapabilities{Version: version}
+
+	for _, sw := range table {
+		if CompareVersions(version, sw.MinVersion) >= 0 {
+			caps.compile = sw.Compile
+			caps.recompileAll = sw.RecompileAll
+			break
+		}
+	}
+
+	return caps
+}
+
+// CompareVersions compares dotted version strings numerically, returning -1, 0 or 1.
+// Missing or non-numeric components count as zero.
+func CompareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+
+	for i := range max(len(as), len(bs)) {
+		x, y := versionPart(as, i), versionPart(bs, i)
+
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+
+	n, _ := strconv.Atoi(strings.TrimSpace(parts[i]))
+	return n
+}
@@ -0,0 +1,37 @@
+package simpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, CompareVersions("4.14.0.0", "4.14"))
+	assert.Equal(t, -1, CompareVersions("4.9.2", "4.14.0"))
+	assert.Equal(t, 1, CompareVersions("4.1400.5.0", "4.1400.4.12"))
+	assert.Equal(t, 0, CompareVersions("", "0.0"))
+}
+
+func TestCapabilitiesFor(t *testing.T) {
+	t.Parallel()
+
+	table := []nativeSwitch{
+		{MinVersion: "5.0", Compile: []string{"/compile", fileArg}, RecompileAll: []string{"/rebuild", fileArg}},
+		{MinVersion: "4.20", Compile: []string{"/c", fileArg}},
+	}
+
+	caps := capabilitiesFor("4.14.0.0", table)
+	assert.False(t, caps.CommandLine(), "older versions have no command-line compile")
+	assert.Equal(t, "4.14.0.0", caps.Version)
+
+	caps = capabilitiesFor("4.25.1.0", table)
+	assert.True(t, caps.CommandLine())
+	assert.Equal(t, []string{"/c", `C:\p.smw`}, caps.CompileArgs(`C:\p.smw`, false))
+	assert.Equal(t, []string{"/c", `C:\p.smw`}, caps.CompileArgs(`C:\p.smw`, true), "falls back to Compile without a Recompile All switch")
+
+	caps = capabilitiesFor("5.1", table)
+	assert.Equal(t, []string{"/rebuild", `C:\p.smw`}, caps.CompileArgs(`C:\p.smw`, true))
+}
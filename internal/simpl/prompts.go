@@ -0,0 +1,24 @@
+package simpl
+
+import "strings"
+
+// updateKeywords identify the "check for updates" and Master Installer download
+// prompts Crestron components can raise when SIMPL Windows starts
+var updateKeywords = []string{
+	"check for update", "update available", "updates available", "updates are available",
+	"version is available", "download", "master installer",
+}
+
+// IsUpdatePrompt reports whether a dialog with this title and control text offers
+// to check for, download or install updates
+func IsUpdatePrompt(title string, texts []string) bool {
+	all := strings.ToLower(title + "\n" + strings.Join(texts, "\n"))
+
+	for _, keyword := range updateKeywords {
+		if strings.Contains(all, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,17 @@
+package simpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsUpdatePrompt(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsUpdatePrompt("Check for Updates", nil))
+	assert.True(t, IsUpdatePrompt("Crestron", []string{"A newer version is available. Download it now?", "&Yes", "&No"}))
+	assert.True(t, IsUpdatePrompt("Crestron Master Installer", nil))
+	assert.False(t, IsUpdatePrompt("SIMPL Windows", []string{"This program was saved with a newer version of SIMPL Windows."}))
+	assert.False(t, IsUpdatePrompt("Operation Complete", []string{"Operation Complete."}))
+}
@@ -0,0 +1,67 @@
+package simpl
+
+import (
+	"log/slog"
+	"path/filepath"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
+
+// crossCompilerExe is the SIMPL+ cross compiler, installed alongside smpwin.exe
+const crossCompilerExe = "SPlusCC.exe"
+
+// databaseKeys are the HKLM keys whose "Version" value holds the installed device
+// database version, newest installer layout first
+var databaseKeys = []string{
+	`SOFTWARE\WOW6432Node\Crestron Electronics Inc.\Crestron Database`,
+	`SOFTWARE\Crestron Electronics Inc.\Crestron Database`,
+	`SOFTWARE\WOW6432Node\Crestron Electronics Inc.\SIMPL Windows\Database`,
+}
+
+// Toolchain holds the versions of the Crestron components a compile depends on.
+// Differences between machines explain many "compiles fine here" discrepancies.
+type Toolchain struct {
+	SimplWindows   string `json:"simplWindows,omitempty"`
+	CrossCompiler  string `json:"crossCompiler,omitempty"`  // SIMPL+ cross compiler
+	DeviceDatabase string `json:"deviceDatabase,omitempty"` // Crestron device database
+}
+
+// DetectToolchain reads the installed versions; components that cannot be found are left empty
+func DetectToolchain() Toolchain {
+	return detectToolchain(GetSimplWindowsPath(), windows.GetFileVersion, func(path string) (string, error) {
+		return windows.RegistryString(windows.HKEY_LOCAL_MACHINE, path, "Version")
+	})
+}
+
+func detectToolchain(exePath string, fileVersion, databaseVersion func(string) (string, error)) Toolchain {
+	var tc Toolchain
+
+	tc.SimplWindows, _ = fileVersion(exePath)
+	tc.CrossCompiler, _ = fileVersion(filepath.Join(filepath.Dir(exePath), crossCompilerExe))
+
+	for _, key := range databaseKeys {
+		if v, err := databaseVersion(key); err == nil && v != "" {
+			tc.DeviceDatabase = v
+			break
+		}
+	}
+
+	return tc
+}
+
+// LogAttrs returns the versions as log attributes, "unknown" for those not found
+func (t Toolchain) LogAttrs() []any {
+	return []any{
+		slog.String("simplWindows", orUnknown(t.SimplWindows)),
+		slog.String("crossCompiler", orUnknown(t.CrossCompiler)),
+		slog.String("deviceDatabase", orUnknown(t.DeviceDatabase)),
+	}
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+
+	return s
+}
@@ -0,0 +1,44 @@
+package simpl
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectToolchain(t *testing.T) {
+	t.Parallel()
+
+	exe := filepath.Join("Crestron", "Simpl", "smpwin.exe")
+	files := map[string]string{
+		exe: "4.1400.5.0",
+		filepath.Join("Crestron", "Simpl", crossCompilerExe): "2.7.4.0",
+	}
+
+	fileVersion := func(path string) (string, error) {
+		if v, ok := files[path]; ok {
+			return v, nil
+		}
+
+		return "", errors.New("not found")
+	}
+
+	database := func(key string) (string, error) {
+		if key == databaseKeys[1] {
+			return "200.05.001.00", nil
+		}
+
+		return "", errors.New("not found")
+	}
+
+	tc := detectToolchain(exe, fileVersion, database)
+	assert.Equal(t, Toolchain{SimplWindows: "4.1400.5.0", CrossCompiler: "2.7.4.0", DeviceDatabase: "200.05.001.00"}, tc)
+
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+	tc = detectToolchain(exe, missing, missing)
+	assert.Equal(t, Toolchain{}, tc)
+	assert.Contains(t, tc.LogAttrs(), slog.String("deviceDatabase", "unknown"))
+}
@@ -0,0 +1,55 @@
+package smw
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Module file extensions: user modules (SIMPL macros) and SIMPL+ modules
+const (
+	ExtUserModule = ".umc"
+	ExtSimplPlus  = ".usp"
+	moduleNameKey = "Nm"
+)
+
+// Modules returns the file names of the user and SIMPL+ modules the program uses,
+// sorted and without duplicates
+func (p *Program) Modules() []string {
+	seen := make(map[string]bool)
+
+	var modules []string
+
+	for _, s := range p.Symbols() {
+		name := s.Get("Nm")
+
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ExtUserModule, ExtSimplPlus:
+		default:
+			continue
+		}
+
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			modules = append(modules, name)
+		}
+	}
+
+	sort.Slice(modules, func(i, j int) bool { return strings.ToLower(modules[i]) < strings.ToLower(modules[j]) })
+	return modules
+}
+
+// FindModule returns the path of module in the first of dirs that contains it.
+// SIMPL Windows looks in the program's directory before its module libraries, so
+// that directory should come first.
+func FindModule(module string, dirs []string) (string, bool) {
+	for _, dir := range dirs {
+		path := filepath.Join(dir, module)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path, true
+		}
+	}
+
+	return "", false
+}
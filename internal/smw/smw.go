@@ -0,0 +1,186 @@
+// Package smw reads SIMPL Windows program files without SIMPL Windows.
+//
+// A program is a sequence of objects, each a block of Key=Value lines between a "["
+// line and a "]" line. The ObjTp key gives the object type.
+package smw
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Object types used by the checks in this package
+const (
+	TypeSignature = "FSgntr" // File signature, always the second object
+	TypeHeader    = "Hd"     // Program header
+	TypeSymbol    = "Sm"     // Symbol (logic, device or module instance)
+
+	signatureProgram = "SimplWindow"
+
+	// Completion flag (CF) of a symbol with unassigned required signals. SIMPL Windows
+	// shows the Incomplete Symbols dialog instead of compiling while any remain.
+	flagIncomplete = "1"
+)
+
+// ErrNotProgram is returned by Parse when the input is not a SIMPL Windows program
+var ErrNotProgram = errors.New("not a SIMPL Windows program")
+
+// Object is one [ ... ] block
+type Object struct {
+	Type   string
+	Line   int // Line of the opening "["
+	Fields map[string]string
+}
+
+// Get returns the value of key, or "" when it is not set
+func (o Object) Get(key string) string {
+	return o.Fields[key]
+}
+
+// Program is a parsed .smw file
+type Program struct {
+	Objects []Object
+}
+
+// Open parses the program at path
+func Open(path string) (*Program, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads a program
+func Parse(r io.Reader) (*Program, error) {
+	var (
+		program Program
+		current *Object
+		line    int
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		switch {
+		case text == "[":
+			if current != nil {
+				return nil, fmt.Errorf("line %d: object started before the object at line %d was closed", line, current.Line)
+			}
+
+			current = &Object{Line: line, Fields: make(map[string]string)}
+
+		case text == "]":
+			if current == nil {
+				return nil, fmt.Errorf("line %d: unexpected ]", line)
+			}
+
+			current.Type = current.Fields["ObjTp"]
+			program.Objects = append(program.Objects, *current)
+			current = nil
+
+		case current != nil:
+			// Values may contain "=", keys never do
+			if key, value, ok := strings.Cut(text, "="); ok {
+				current.Fields[key] = value
+			}
+
+		case strings.TrimSpace(text) != "":
+			if len(program.Objects) == 0 {
+				return nil, ErrNotProgram
+			}
+
+			return nil, fmt.Errorf("line %d: text outside an object", line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("object at line %d is not closed; the file may be truncated", current.Line)
+	}
+
+	sig, ok := program.First(TypeSignature)
+	if !ok || sig.Get("Sgntr") != signatureProgram {
+		return nil, ErrNotProgram
+	}
+
+	return &program, nil
+}
+
+// First returns the first object of type typ
+func (p *Program) First(typ string) (Object, bool) {
+	for _, o := range p.Objects {
+		if o.Type == typ {
+			return o, true
+		}
+	}
+
+	return Object{}, false
+}
+
+// Symbols returns every symbol in the program
+func (p *Program) Symbols() []Object {
+	var symbols []Object
+
+	for _, o := range p.Objects {
+		if o.Type == TypeSymbol {
+			symbols = append(symbols, o)
+		}
+	}
+
+	return symbols
+}
+
+// IncompleteSymbols returns the symbols that make the program incomplete. SIMPL
+// Windows also marks every folder containing an incomplete symbol as incomplete;
+// only the symbols themselves are returned.
+func (p *Program) IncompleteSymbols() []Object {
+	symbols := p.Symbols()
+
+	incomplete := make(map[string]bool)
+	for _, s := range symbols {
+		if s.Get("CF") == flagIncomplete {
+			incomplete[s.Get("H")] = true
+		}
+	}
+
+	var origins []Object
+
+	for _, s := range symbols {
+		if !incomplete[s.Get("H")] || hasIncompleteChild(s, incomplete) {
+			continue
+		}
+
+		origins = append(origins, s)
+	}
+
+	return origins
+}
+
+// hasIncompleteChild reports whether any child (C1..CmC) of s is incomplete
+func hasIncompleteChild(s Object, incomplete map[string]bool) bool {
+	count, _ := strconv.Atoi(s.Get("mC"))
+
+	for i := 1; i <= count; i++ {
+		if incomplete[s.Get("C"+strconv.Itoa(i))] {
+			return true
+		}
+	}
+
+	return false
+}
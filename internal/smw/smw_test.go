@@ -0,0 +1,72 @@
+package smw
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtures are the programs used by the integration tests
+const fixtures = "../../test/integration/fixtures"
+
+func TestOpenFixtures(t *testing.T) {
+	t.Parallel()
+
+	simple, err := Open(filepath.Join(fixtures, "simple.smw"))
+	require.NoError(t, err)
+
+	header, ok := simple.First(TypeHeader)
+	require.True(t, ok)
+	assert.Equal(t, "simple.smw", header.Get("PrNm"))
+	assert.NotEmpty(t, simple.Symbols())
+	assert.Empty(t, simple.IncompleteSymbols())
+	assert.Empty(t, simple.Modules())
+
+	incomplete, err := Open(filepath.Join(fixtures, "incomplete.smw"))
+	require.NoError(t, err)
+
+	symbols := incomplete.IncompleteSymbols()
+	require.Len(t, symbols, 1)
+	assert.Equal(t, "Packet Transmission", symbols[0].Get("Nm"))
+
+	withModule, err := Open(filepath.Join(fixtures, "error.smw"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"error.usp"}, withModule.Modules())
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Parallel()
+
+	for name, input := range map[string]string{
+		"not a program": "hello world\n",
+		"wrong type":    "[\r\nVersion=1\r\n]\r\n[\r\nObjTp=FSgntr\r\nSgntr=Other\r\n]\r\n",
+		"no signature":  "[\nVersion=1\n]\n",
+	} {
+		_, err := Parse(strings.NewReader(input))
+		assert.ErrorIs(t, err, ErrNotProgram, name)
+	}
+
+	_, err := Parse(strings.NewReader("[\nVersion=1\n]\n[\nObjTp=FSgntr\nSgntr=SimplWindow\n"))
+	assert.ErrorContains(t, err, "object at line 4 is not closed")
+
+	_, err = Parse(strings.NewReader("[\nVersion=1\n]\n]\n"))
+	assert.ErrorContains(t, err, "line 4: unexpected ]")
+}
+
+func TestFindModule(t *testing.T) {
+	t.Parallel()
+
+	programDir, libraryDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(libraryDir, "Lights.umc"), nil, 0o644))
+
+	path, ok := FindModule("Lights.umc", []string{programDir, libraryDir})
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(libraryDir, "Lights.umc"), path)
+
+	_, ok = FindModule("Missing.usp", []string{programDir, libraryDir})
+	assert.False(t, ok)
+}
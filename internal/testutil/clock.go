@@ -0,0 +1,129 @@
+package testutil
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/clock"
+)
+
+// FakeClock implements clock.Clock with manually controlled time.
+// Sleep advances the clock immediately; timers fire only when the clock is
+// advanced past their deadline, so timeout paths can be tested without real waits.
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFakeClock creates a FakeClock starting at a fixed point in time
+func NewFakeClock() *FakeClock {
+	f := &FakeClock{
+		now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+func (f *FakeClock) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Sleep advances the fake clock by d without blocking
+func (f *FakeClock) Sleep(d time.Duration) {
+	f.Advance(d)
+}
+
+func (f *FakeClock) NewTimer(d time.Duration) clock.Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := &fakeTimer{
+		clock:    f,
+		deadline: f.now.Add(d),
+		ch:       make(chan time.Time, 1),
+	}
+
+	if d <= 0 {
+		t.ch <- f.now
+		return t
+	}
+
+	f.timers = append(f.timers, t)
+	f.cond.Broadcast()
+
+	return t
+}
+
+// Advance moves the clock forward by d, firing any timers that become due
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+
+	pending := f.timers[:0]
+	for _, t := range f.timers {
+		if !t.deadline.After(f.now) {
+			t.ch <- f.now
+			continue
+		}
+
+		pending = append(pending, t)
+	}
+
+	f.timers = pending
+}
+
+// WaitForTimers blocks until at least n timers are pending on the clock
+func (f *FakeClock) WaitForTimers(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for len(f.timers) < n {
+		f.cond.Wait()
+	}
+}
+
+// PendingTimers returns the number of timers that have not fired or been stopped
+func (f *FakeClock) PendingTimers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.timers)
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	f := t.clock
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, pending := range f.timers {
+		if pending == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,6 +1,13 @@
+//go:build windows
+
 package testutil
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/windows"
+)
 
 // MockProcessManager implements interfaces.ProcessManager for testing
 type MockProcessManager struct {
@@ -9,6 +16,10 @@ type MockProcessManager struct {
 	FindWindowTitle    string
 	WaitForReadyResult bool
 	FindWindowCalls    []FindWindowCall
+
+	ProcessList    []windows.ProcessEntry // Running processes, for Process and Processes
+	TerminateErr   error
+	TerminateCalls []windows.ProcessEntry
 }
 
 type FindWindowCall struct {
@@ -40,6 +51,32 @@ func (m *MockProcessManager) WaitForReady(hwnd uintptr, timeout time.Duration) b
 	return m.WaitForReadyResult
 }
 
+func (m *MockProcessManager) Process(pid uint32) (windows.ProcessEntry, error) {
+	for _, p := range m.ProcessList {
+		if p.Pid == pid {
+			return p, nil
+		}
+	}
+
+	return windows.ProcessEntry{}, fmt.Errorf("process %d is not running", pid)
+}
+
+func (m *MockProcessManager) Processes(match func(windows.ProcessEntry) bool) ([]windows.ProcessEntry, error) {
+	var matched []windows.ProcessEntry
+	for _, p := range m.ProcessList {
+		if match(p) {
+			matched = append(matched, p)
+		}
+	}
+
+	return matched, nil
+}
+
+func (m *MockProcessManager) Terminate(p windows.ProcessEntry) error {
+	m.TerminateCalls = append(m.TerminateCalls, p)
+	return m.TerminateErr
+}
+
 // Helper methods for fluent configuration
 func (m *MockProcessManager) WithFindWindowResult(hwnd uintptr, title string) *MockProcessManager {
 	m.FindWindowResult = hwnd
@@ -51,3 +88,8 @@ func (m *MockProcessManager) WithWaitForReadyResult(result bool) *MockProcessMan
 	m.WaitForReadyResult = result
 	return m
 }
+
+func (m *MockProcessManager) WithProcesses(processes ...windows.ProcessEntry) *MockProcessManager {
+	m.ProcessList = processes
+	return m
+}
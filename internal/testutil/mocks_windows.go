@@ -3,6 +3,7 @@ package testutil
 import (
 	"time"
 
+	"github.com/Norgate-AV/smpc/internal/keychord"
 	"github.com/Norgate-AV/smpc/internal/windows"
 )
 
@@ -16,6 +17,16 @@ type MockWindowManager struct {
 	ChildInfos                   []windows.ChildInfo
 	ChildInfosMap                map[uintptr][]windows.ChildInfo
 	WaitOnMonitorResults         []WaitOnMonitorResult
+	OnCloseWindow                func(hwnd uintptr, title string) // Optional hook, e.g. to publish follow-up dialogs
+	InvokeMenuItemCalls          [][]string
+	InvokeMenuItemResult         bool
+	OnInvokeMenuItem             func(path []string) // Optional hook, e.g. to publish the dialogs a menu command raises
+	SendMessageCalls             []MessageCall
+	SendMessageResult            uintptr
+	PostMessageCalls             []MessageCall
+	MessageResult                bool // Whether sending or posting a message succeeds
+	ClickButtonByIdCalls         []ClickButtonByIdCall
+	ClickButtonByIdResult        bool
 	currentWaitIndex             int
 }
 
@@ -24,6 +35,18 @@ type CloseWindowCall struct {
 	Title string
 }
 
+type MessageCall struct {
+	Hwnd   uintptr
+	Msg    uint32
+	WParam uintptr
+	LParam uintptr
+}
+
+type ClickButtonByIdCall struct {
+	ParentHwnd uintptr
+	ID         int
+}
+
 type WaitOnMonitorResult struct {
 	Event windows.WindowEvent
 	OK    bool
@@ -36,6 +59,9 @@ func NewMockWindowManager() *MockWindowManager {
 		SetForegroundResult:          true,
 		VerifyForegroundWindowResult: true,
 		IsElevatedResult:             true,
+		InvokeMenuItemResult:         true,
+		MessageResult:                true,
+		ClickButtonByIdResult:        true,
 		WaitOnMonitorResults:         []WaitOnMonitorResult{},
 		ChildInfos:                   []windows.ChildInfo{},
 		ChildInfosMap:                make(map[uintptr][]windows.ChildInfo),
@@ -44,6 +70,10 @@ func NewMockWindowManager() *MockWindowManager {
 
 func (m *MockWindowManager) CloseWindow(hwnd uintptr, title string) {
 	m.CloseWindowCalls = append(m.CloseWindowCalls, CloseWindowCall{hwnd, title})
+
+	if m.OnCloseWindow != nil {
+		m.OnCloseWindow(hwnd, title)
+	}
 }
 
 func (m *MockWindowManager) SetForeground(hwnd uintptr) bool {
@@ -79,6 +109,36 @@ func (m *MockWindowManager) WaitOnMonitor(timeout time.Duration, matchers ...fun
 	return result.Event, result.OK
 }
 
+func (m *MockWindowManager) InvokeMenuItem(hwnd uintptr, path ...string) bool {
+	m.InvokeMenuItemCalls = append(m.InvokeMenuItemCalls, path)
+
+	if m.OnInvokeMenuItem != nil {
+		m.OnInvokeMenuItem(path)
+	}
+
+	return m.InvokeMenuItemResult
+}
+
+func (m *MockWindowManager) SendMessage(hwnd uintptr, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	m.SendMessageCalls = append(m.SendMessageCalls, MessageCall{hwnd, msg, wParam, lParam})
+
+	if !m.MessageResult {
+		return 0, false
+	}
+
+	return m.SendMessageResult, true
+}
+
+func (m *MockWindowManager) PostMessage(hwnd uintptr, msg uint32, wParam, lParam uintptr) bool {
+	m.PostMessageCalls = append(m.PostMessageCalls, MessageCall{hwnd, msg, wParam, lParam})
+	return m.MessageResult
+}
+
+func (m *MockWindowManager) ClickButtonById(parentHwnd uintptr, id int) bool {
+	m.ClickButtonByIdCalls = append(m.ClickButtonByIdCalls, ClickButtonByIdCall{parentHwnd, id})
+	return m.ClickButtonByIdResult
+}
+
 // Helper methods for fluent configuration
 func (m *MockWindowManager) WithWaitResult(title string, hwnd uintptr, ok bool) *MockWindowManager {
 	m.WaitOnMonitorResults = append(m.WaitOnMonitorResults, WaitOnMonitorResult{
@@ -123,6 +183,16 @@ func (m *MockWindowManager) WithWaitOnMonitorResults(results ...WaitOnMonitorRes
 	return m
 }
 
+func (m *MockWindowManager) WithOnCloseWindow(fn func(hwnd uintptr, title string)) *MockWindowManager {
+	m.OnCloseWindow = fn
+	return m
+}
+
+func (m *MockWindowManager) WithOnInvokeMenuItem(fn func(path []string)) *MockWindowManager {
+	m.OnInvokeMenuItem = fn
+	return m
+}
+
 func (m *MockWindowManager) WithChildInfos(infos ...windows.ChildInfo) *MockWindowManager {
 	m.ChildInfos = infos
 	return m
@@ -133,31 +203,12 @@ func (m *MockWindowManager) WithChildInfosForHwnd(hwnd uintptr, infos ...windows
 	return m
 }
 
-// SendEventsToMonitor sends a sequence of events to windows.MonitorCh for event-driven testing
+// SendEventsToMonitor publishes a sequence of events to an event bus for event-driven testing
 // This simulates the background window monitor sending events in real-time
-// Events are sent synchronously to ensure they're in the channel before Compile() reads them
-func SendEventsToMonitor(events ...windows.WindowEvent) {
-	// Ensure the channel exists
-	if windows.MonitorCh == nil {
-		windows.MonitorCh = make(chan windows.WindowEvent, 64)
-	}
-
-	// Send events synchronously so they're immediately available
+// Compile subscribes with history, so events published before Compile() are still delivered in order
+func SendEventsToMonitor(bus *windows.EventBus, events ...windows.WindowEvent) {
 	for _, ev := range events {
-		windows.MonitorCh <- ev
-	}
-}
-
-// SetupMonitorChannel initializes the MonitorCh for testing
-func SetupMonitorChannel() {
-	windows.MonitorCh = make(chan windows.WindowEvent, 64)
-}
-
-// CleanupMonitorChannel cleans up the MonitorCh after testing
-func CleanupMonitorChannel() {
-	if windows.MonitorCh != nil {
-		close(windows.MonitorCh)
-		windows.MonitorCh = nil
+		bus.Publish(ev)
 	}
 }
 
@@ -170,8 +221,12 @@ type MockKeyboardInjector struct {
 	SendAltF12ToWindowCalled      bool
 	SendF12WithSendInputCalled    bool
 	SendAltF12WithSendInputCalled bool
+	SendChordWithSendInputCalls   []keychord.Chord
+	SendChordCalls                []keychord.Chord
 	SendToWindowResult            bool
 	SendInputResult               bool
+
+	OnSendChordWithSendInput func(chord keychord.Chord) // Optional hook, e.g. to simulate the effect of a keystroke
 }
 
 func NewMockKeyboardInjector() *MockKeyboardInjector {
@@ -213,6 +268,20 @@ func (m *MockKeyboardInjector) SendAltF12WithSendInput() bool {
 	return m.SendInputResult
 }
 
+func (m *MockKeyboardInjector) SendChordWithSendInput(chord keychord.Chord) bool {
+	m.SendChordWithSendInputCalls = append(m.SendChordWithSendInputCalls, chord)
+
+	if m.OnSendChordWithSendInput != nil {
+		m.OnSendChordWithSendInput(chord)
+	}
+
+	return m.SendInputResult
+}
+
+func (m *MockKeyboardInjector) SendChord(chord keychord.Chord) {
+	m.SendChordCalls = append(m.SendChordCalls, chord)
+}
+
 // MockControlReader
 type MockControlReader struct {
 	ListBoxItems            []string
@@ -271,3 +340,40 @@ func (m *MockControlReader) WithFindAndClickButtonResult(result bool) *MockContr
 	m.FindButtonResult = result
 	return m
 }
+
+// MockDDEClient records DDE execute commands
+type MockDDEClient struct {
+	ExecuteCalls []DDEExecuteCall
+	ExecuteErr   error
+	OnExecute    func(command string) // Optional hook, e.g. to publish the dialogs a command raises
+}
+
+type DDEExecuteCall struct {
+	Service string
+	Topic   string
+	Command string
+}
+
+func NewMockDDEClient() *MockDDEClient {
+	return &MockDDEClient{}
+}
+
+func (m *MockDDEClient) DDEExecute(service, topic, command string) error {
+	m.ExecuteCalls = append(m.ExecuteCalls, DDEExecuteCall{Service: service, Topic: topic, Command: command})
+
+	if m.OnExecute != nil {
+		m.OnExecute(command)
+	}
+
+	return m.ExecuteErr
+}
+
+func (m *MockDDEClient) WithExecuteErr(err error) *MockDDEClient {
+	m.ExecuteErr = err
+	return m
+}
+
+func (m *MockDDEClient) WithOnExecute(fn func(command string)) *MockDDEClient {
+	m.OnExecute = fn
+	return m
+}
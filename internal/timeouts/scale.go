@@ -0,0 +1,81 @@
+package timeouts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SizeCurve scales the compilation timeout with the size of the program file, so
+// large programs get longer while small ones still fail fast
+type SizeCurve struct {
+	Base  time.Duration // Timeout for an empty program
+	PerMB time.Duration // Added for each megabyte of program file
+	Max   time.Duration // Upper limit (0 = none)
+}
+
+// DefaultSizeCurve starts at CompilationCompleteTimeout and reaches 20 minutes at 40 MB
+var DefaultSizeCurve = SizeCurve{
+	Base:  CompilationCompleteTimeout,
+	PerMB: 22500 * time.Millisecond,
+	Max:   time.Hour,
+}
+
+// Timeout returns the compilation timeout for a program of size bytes, to the second
+func (c SizeCurve) Timeout(size int64) time.Duration {
+	timeout := c.Base + time.Duration(float64(c.PerMB)*float64(size)/(1<<20))
+
+	if c.Max > 0 && timeout > c.Max {
+		timeout = c.Max
+	}
+
+	return timeout.Round(time.Second)
+}
+
+// String returns the curve in the form ParseSizeCurve reads
+func (c SizeCurve) String() string {
+	return fmt.Sprintf("base=%s,per-mb=%s,max=%s", c.Base, c.PerMB, c.Max)
+}
+
+// ParseSizeCurve reads a curve such as "base=5m,per-mb=22.5s,max=1h". Keys that are
+// left out keep their DefaultSizeCurve value; "" is the default curve.
+func ParseSizeCurve(spec string) (SizeCurve, error) {
+	curve := DefaultSizeCurve
+
+	if strings.TrimSpace(spec) == "" {
+		return curve, nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return SizeCurve{}, fmt.Errorf("invalid timeout curve %q: expected key=duration", part)
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d < 0 {
+			return SizeCurve{}, fmt.Errorf("invalid timeout curve %s: %q is not a duration", key, value)
+		}
+
+		switch strings.TrimSpace(key) {
+		case "base":
+			curve.Base = d
+		case "per-mb":
+			curve.PerMB = d
+		case "max":
+			curve.Max = d
+		default:
+			return SizeCurve{}, fmt.Errorf("invalid timeout curve key %q (supported: base, per-mb, max)", key)
+		}
+	}
+
+	if curve.Base <= 0 {
+		return SizeCurve{}, fmt.Errorf("invalid timeout curve: base must be positive")
+	}
+
+	if curve.Max > 0 && curve.Max < curve.Base {
+		return SizeCurve{}, fmt.Errorf("invalid timeout curve: max %s is shorter than base %s", curve.Max, curve.Base)
+	}
+
+	return curve, nil
+}
@@ -0,0 +1,49 @@
+package timeouts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeCurve_Timeout(t *testing.T) {
+	t.Parallel()
+
+	const mb = 1 << 20
+
+	assert.Equal(t, 5*time.Minute, DefaultSizeCurve.Timeout(0))
+	assert.Equal(t, 5*time.Minute+23*time.Second, DefaultSizeCurve.Timeout(mb))
+	assert.Equal(t, 20*time.Minute, DefaultSizeCurve.Timeout(40*mb))
+	assert.Equal(t, time.Hour, DefaultSizeCurve.Timeout(1000*mb))
+
+	unlimited := SizeCurve{Base: time.Minute, PerMB: time.Minute}
+	assert.Equal(t, 101*time.Minute, unlimited.Timeout(100*mb))
+}
+
+func TestParseSizeCurve(t *testing.T) {
+	t.Parallel()
+
+	curve, err := ParseSizeCurve("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSizeCurve, curve)
+
+	curve, err = ParseSizeCurve("base=2m, per-mb=30s")
+	require.NoError(t, err)
+	assert.Equal(t, SizeCurve{Base: 2 * time.Minute, PerMB: 30 * time.Second, Max: time.Hour}, curve)
+
+	// String round-trips
+	curve, err = ParseSizeCurve(curve.String())
+	require.NoError(t, err)
+	assert.Equal(t, SizeCurve{Base: 2 * time.Minute, PerMB: 30 * time.Second, Max: time.Hour}, curve)
+
+	curve, err = ParseSizeCurve("per-mb=0s,max=0s")
+	require.NoError(t, err)
+	assert.Equal(t, CompilationCompleteTimeout, curve.Timeout(100<<20))
+
+	for _, spec := range []string{"base", "base=soon", "base=0s", "speed=1s", "base=10m,max=5m", "per-mb=-1s"} {
+		_, err := ParseSizeCurve(spec)
+		assert.Error(t, err, spec)
+	}
+}
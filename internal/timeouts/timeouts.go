@@ -15,6 +15,10 @@ const (
 	// to stabilize and become responsive after the window appears.
 	WindowReadyTimeout = 30 * time.Second
 
+	// InterferingAppsTimeout is how long --if-interfering wait waits for other
+	// Crestron tools (VT Pro-e, Toolbox, D3 Pro) to exit before launching anyway.
+	InterferingAppsTimeout = 5 * time.Minute
+
 	// UISettlingDelay allows time for window animations, focus events, and
 	// UI state to stabilize before interacting with the application.
 	UISettlingDelay = 5 * time.Second
@@ -33,6 +37,10 @@ const (
 	// the target application reliably receives and processes the input.
 	KeystrokeDelay = 50 * time.Millisecond
 
+	// SendMessageTimeout is how long a window is given to process a message sent
+	// to it before it is treated as hung.
+	SendMessageTimeout = 5 * time.Second
+
 	// Compiler Dialog Timeouts
 
 	// CompilationCompleteTimeout is the maximum time to wait for the entire
@@ -41,6 +49,24 @@ const (
 	// may take several minutes to compile.
 	CompilationCompleteTimeout = 5 * time.Minute
 
+	// CompileTriggerTimeout is how long to wait for SIMPL Windows to react to the
+	// compile trigger (save prompt, "Compiling..." etc.) before falling back to
+	// the next trigger strategy.
+	CompileTriggerTimeout = 15 * time.Second
+
+	// ProgramCompilationTimeout is how long to wait for the "Program Compilation"
+	// dialog listing the messages once "Compile Complete" has reported errors,
+	// warnings or notices.
+	ProgramCompilationTimeout = 10 * time.Second
+
+	// SaveTimeout is how long --save-first waits for SIMPL Windows to rewrite the
+	// program file after the save command is sent.
+	SaveTimeout = 10 * time.Second
+
+	// DDETransactionTimeout is the maximum time to wait for a DDE server to
+	// acknowledge an execute command.
+	DDETransactionTimeout = 10 * time.Second
+
 	// DialogResponseDelay is the delay after sending input to dialog boxes to
 	// allow the dialog to process the input and respond.
 	DialogResponseDelay = 300 * time.Millisecond
@@ -49,20 +75,24 @@ const (
 	// confirmation dialog to appear.
 	DialogConfirmationTimeout = 2 * time.Second
 
+	// ProcessExitTimeout is how long cleanup waits for the SIMPL Windows process
+	// to exit once its window has closed or it has been terminated.
+	ProcessExitTimeout = 5 * time.Second
+
 	// Polling and Verification Intervals
 
-	// StatePollingInterval is the delay between checks in tight polling loops
-	// when actively waiting for state changes (window appearance, readiness,
-	// process discovery, etc.).
+	// StatePollingInterval is the delay between checks in polling loops (window
+	// appearance, readiness, the background window monitor) right after a change
+	// or compile trigger. Quiet loops back off from here to MaxPollingInterval.
 	StatePollingInterval = 100 * time.Millisecond
 
 	// StabilityCheckInterval is the delay between consecutive responsiveness
 	// checks to ensure a window is stable and ready for interaction.
 	StabilityCheckInterval = 500 * time.Millisecond
 
-	// MonitorPollingInterval is the interval at which the background window
-	// monitor checks for new windows and dialog events.
-	MonitorPollingInterval = 500 * time.Millisecond
+	// MaxPollingInterval is the longest delay polling loops back off to while
+	// nothing changes, keeping CPU use low during long compiles.
+	MaxPollingInterval = 2 * time.Second
 
 	// CleanupDelay allows time for windows and processes to close gracefully
 	// before performing verification checks or additional cleanup operations.
@@ -0,0 +1,183 @@
+// Package userconfig reads and writes the persistent smpc config file, which holds
+// per-user defaults for command-line flags.
+package userconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// FileName is the name of the config file in the smpc data directory
+const FileName = "config.json"
+
+// PathEnv names the environment variable that points smpc at another config file
+const PathEnv = "SMPC_CONFIG"
+
+// File is a config file: flag defaults keyed by the flag's name in camelCase, e.g.
+// simplPath for --simpl-path. Values are strings, or string slices for repeatable flags.
+type File struct {
+	Path   string
+	Values map[string]any
+}
+
+// Load reads the config file at path. A missing file is an empty config.
+func Load(path string) (*File, error) {
+	f := &File{Path: path, Values: make(map[string]any)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]any
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	for key, value := range raw {
+		values, err := Strings(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %s: %w", path, key, err)
+		}
+
+		if _, many := value.([]any); many {
+			f.Values[key] = values
+		} else {
+			f.Values[key] = values[0]
+		}
+	}
+
+	return f, nil
+}
+
+// Save writes the config file, creating its directory if needed. The file is
+// replaced in one step so a failed write never leaves half a config behind.
+func (f *File) Save() error {
+	data, err := json.MarshalIndent(f.Values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), FileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.Path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// Keys returns the keys that are set, sorted
+func (f *File) Keys() []string {
+	keys := make([]string, 0, len(f.Values))
+	for key := range f.Values {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+	return keys
+}
+
+// Get returns the values of key, one for a single-valued flag
+func (f *File) Get(key string) ([]string, bool) {
+	value, ok := f.Values[key]
+	if !ok {
+		return nil, false
+	}
+
+	values, _ := Strings(value)
+	return values, true
+}
+
+// Strings returns a config value as strings: a scalar as one string and an array
+// as one string per element
+func Strings(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case bool, json.Number, float64:
+		return []string{fmt.Sprint(v)}, nil
+	case []string:
+		return v, nil
+	case []any:
+		values := make([]string, 0, len(v))
+
+		for _, elem := range v {
+			if _, nested := elem.([]any); nested {
+				return nil, fmt.Errorf("arrays may only hold strings, numbers and booleans")
+			}
+
+			s, err := Strings(elem)
+			if err != nil {
+				return nil, fmt.Errorf("arrays may only hold strings, numbers and booleans")
+			}
+
+			values = append(values, s[0])
+		}
+
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+// Key returns the config key for a flag name: simplPath for simpl-path
+func Key(flag string) string {
+	parts := strings.Split(flag, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+
+	return strings.Join(parts, "")
+}
+
+// Flag returns the flag name for a config key: simpl-path for simplPath
+func Flag(key string) string {
+	var b strings.Builder
+
+	for _, r := range key {
+		if unicode.IsUpper(r) {
+			b.WriteByte('-')
+			r = unicode.ToLower(r)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
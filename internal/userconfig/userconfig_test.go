@@ -0,0 +1,87 @@
+package userconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Missing(t *testing.T) {
+	t.Parallel()
+
+	f, err := Load(filepath.Join(t.TempDir(), FileName))
+	require.NoError(t, err)
+	assert.Empty(t, f.Keys())
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", FileName)
+
+	f := &File{Path: path, Values: map[string]any{
+		"simplPath": `D:\Crestron\Simpl\smpwin.exe`,
+		"simplEnv":  []string{"A=1", "B=2"},
+	}}
+	require.NoError(t, f.Save())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"simplEnv", "simplPath"}, loaded.Keys())
+
+	values, ok := loaded.Get("simplEnv")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"A=1", "B=2"}, values)
+
+	values, ok = loaded.Get("simplPath")
+	assert.True(t, ok)
+	assert.Equal(t, []string{`D:\Crestron\Simpl\smpwin.exe`}, values)
+
+	_, ok = loaded.Get("backend")
+	assert.False(t, ok)
+}
+
+func TestLoad_HandEditedValues(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte(`{"crashRetries": 2, "keepTemp": true, "maxRuntime": "30m"}`), 0o644))
+
+	f, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"crashRetries": "2", "keepTemp": "true", "maxRuntime": "30m"}, f.Values)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"not json":     `simplPath=C:\smpwin.exe`,
+		"object value": `{"simplEnv": {"A": "1"}}`,
+		"nested array": `{"simplEnv": [["A=1"]]}`,
+	} {
+		path := filepath.Join(dir, name+".json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+		_, err := Load(path)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestKeyAndFlag(t *testing.T) {
+	t.Parallel()
+
+	for flag, key := range map[string]string{
+		"simpl-path":         "simplPath",
+		"backend":            "backend",
+		"auto-recompile-all": "autoRecompileAll",
+	} {
+		assert.Equal(t, key, Key(flag))
+		assert.Equal(t, flag, Flag(key))
+	}
+}
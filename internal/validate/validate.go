@@ -0,0 +1,192 @@
+// Package validate produces structured diagnostics for SIMPL Windows programs, from
+// the program file and from the dialogs SIMPL Windows shows when opening it.
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/Norgate-AV/smpc/internal/report"
+	"github.com/Norgate-AV/smpc/internal/smw"
+)
+
+// Severity of a diagnostic
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic codes
+const (
+	CodeIncompleteSymbol = "incomplete-symbol"
+	CodeUnresolvedModule = "unresolved-module"
+	CodeDialog           = "dialog" // Any other dialog SIMPL Windows showed
+)
+
+// Where a diagnostic came from
+const (
+	SourceFile  = "file"  // Found by reading the program
+	SourceSimpl = "simpl" // Reported by SIMPL Windows
+)
+
+// Diagnostic is a single problem found in a program
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Source   string `json:"source"`
+	Symbol   string `json:"symbol,omitempty"` // Symbol or module name
+	Line     int    `json:"line,omitempty"`   // Line in the .smw file
+}
+
+// Result is the outcome of validating one program
+type Result struct {
+	File        string       `json:"file"`
+	Valid       bool         `json:"valid"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// NewResult returns the result for file; it is valid unless a diagnostic is an error
+func NewResult(file string, diagnostics []Diagnostic) Result {
+	result := Result{File: file, Valid: true, Diagnostics: diagnostics}
+	if result.Diagnostics == nil {
+		result.Diagnostics = []Diagnostic{}
+	}
+
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			result.Valid = false
+		}
+	}
+
+	return result
+}
+
+// Static checks the program file for incomplete symbols and for modules missing from
+// moduleDirs
+func Static(program *smw.Program, moduleDirs []string) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for _, s := range program.IncompleteSymbols() {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Code:     CodeIncompleteSymbol,
+			Message:  fmt.Sprintf("incomplete symbol %q", s.Get("Nm")),
+			Source:   SourceFile,
+			Symbol:   s.Get("Nm"),
+			Line:     s.Line,
+		})
+	}
+
+	for _, module := range program.Modules() {
+		if _, ok := smw.FindModule(module, moduleDirs); ok {
+			continue
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Code:     CodeUnresolvedModule,
+			Message:  fmt.Sprintf("module %s not found", module),
+			Source:   SourceFile,
+			Symbol:   module,
+		})
+	}
+
+	return diagnostics
+}
+
+// Module file names in dialog text, either quoted (and possibly containing spaces) or bare
+var (
+	quotedModuleFile = regexp.MustCompile(`(?i)["']([^"']+\.(?:umc|usp|ush|clz))["']`)
+	moduleFile       = regexp.MustCompile(`(?i)[^\s"'\\/:]+\.(?:umc|usp|ush|clz)\b`)
+)
+
+// unresolvedPhrases appear in the dialogs SIMPL Windows shows for modules it cannot load
+var unresolvedPhrases = []string{"not found", "cannot find", "could not find", "could not be found", "unable to locate", "unable to find", "missing"}
+
+// Dialog converts a dialog SIMPL Windows showed while opening a program, given its
+// title and the text of its controls, into a diagnostic
+func Dialog(title string, texts []string) Diagnostic {
+	text := strings.Join(nonEmpty(texts), " ")
+
+	message := title
+	if text != "" {
+		message = title + ": " + text
+	}
+
+	d := Diagnostic{Severity: SeverityWarning, Code: CodeDialog, Message: message, Source: SourceSimpl}
+
+	lower := strings.ToLower(text)
+
+	switch {
+	case title == "Incomplete Symbols":
+		d.Severity, d.Code = SeverityError, CodeIncompleteSymbol
+	case moduleFile.MatchString(text) && containsAny(lower, unresolvedPhrases):
+		d.Severity, d.Code = SeverityError, CodeUnresolvedModule
+		d.Symbol = moduleFile.FindString(text)
+
+		if m := quotedModuleFile.FindStringSubmatch(text); m != nil {
+			d.Symbol = report.DisplayName(m[1])
+		}
+	}
+
+	return d
+}
+
+func nonEmpty(texts []string) []string {
+	var out []string
+
+	for _, t := range texts {
+		if t = strings.Join(strings.Fields(t), " "); t != "" {
+			out = append(out, t)
+		}
+	}
+
+	return out
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteText writes one line per diagnostic, prefixed by the program's file name.
+// Warnings are marked as such; errors are not.
+func WriteText(w io.Writer, results []Result) error {
+	for _, r := range results {
+		name := report.DisplayName(r.File)
+
+		for _, d := range r.Diagnostics {
+			line := fmt.Sprintf("%s: %s", name, d.Message)
+			if d.Severity == SeverityWarning {
+				line = fmt.Sprintf("%s: warning: %s", name, d.Message)
+			}
+
+			if d.Line > 0 {
+				line += fmt.Sprintf(" (line %d)", d.Line)
+			}
+
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON writes the results as an indented JSON array
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(results)
+}
@@ -0,0 +1,96 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Norgate-AV/smpc/internal/smw"
+)
+
+const fixtures = "../../test/integration/fixtures"
+
+func TestStatic(t *testing.T) {
+	t.Parallel()
+
+	incomplete, err := smw.Open(filepath.Join(fixtures, "incomplete.smw"))
+	require.NoError(t, err)
+
+	diagnostics := Static(incomplete, []string{fixtures})
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, CodeIncompleteSymbol, diagnostics[0].Code)
+	assert.Equal(t, "Packet Transmission", diagnostics[0].Symbol)
+	assert.Positive(t, diagnostics[0].Line)
+
+	withModule, err := smw.Open(filepath.Join(fixtures, "error.smw"))
+	require.NoError(t, err)
+	assert.Empty(t, Static(withModule, []string{fixtures}))
+
+	diagnostics = Static(withModule, []string{t.TempDir()})
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeUnresolvedModule,
+		Message:  "module error.usp not found",
+		Source:   SourceFile,
+		Symbol:   "error.usp",
+	}, diagnostics[0])
+}
+
+func TestDialog(t *testing.T) {
+	t.Parallel()
+
+	unresolved := Dialog("SIMPL Windows", []string{"", "Unable to locate module\r\n  'Room Control.umc'.", "OK"})
+	assert.Equal(t, CodeUnresolvedModule, unresolved.Code)
+	assert.Equal(t, SeverityError, unresolved.Severity)
+	assert.Equal(t, "Room Control.umc", unresolved.Symbol)
+
+	bare := Dialog("Error", []string{`Module C:\modules\Lights.usp could not be found`})
+	assert.Equal(t, CodeUnresolvedModule, bare.Code)
+	assert.Equal(t, "Lights.usp", bare.Symbol)
+
+	incomplete := Dialog("Incomplete Symbols", nil)
+	assert.Equal(t, CodeIncompleteSymbol, incomplete.Code)
+	assert.Equal(t, "Incomplete Symbols", incomplete.Message)
+
+	other := Dialog("Upgrade", []string{"This program was saved with an older version."})
+	assert.Equal(t, CodeDialog, other.Code)
+	assert.Equal(t, SeverityWarning, other.Severity)
+	assert.Equal(t, "Upgrade: This program was saved with an older version.", other.Message)
+	assert.Equal(t, SourceSimpl, other.Source)
+}
+
+func TestNewResult(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, NewResult("a.smw", []Diagnostic{{Severity: SeverityWarning}}).Valid)
+	assert.False(t, NewResult("a.smw", []Diagnostic{{Severity: SeverityError}}).Valid)
+
+	empty := NewResult("a.smw", nil)
+	assert.True(t, empty.Valid)
+	assert.NotNil(t, empty.Diagnostics)
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	results := []Result{NewResult(`C:\jobs\Lobby.smw`, []Diagnostic{
+		{Severity: SeverityError, Code: CodeIncompleteSymbol, Message: `incomplete symbol "Delay"`, Line: 12},
+		{Severity: SeverityWarning, Code: CodeDialog, Message: "Upgrade: older version"},
+	})}
+
+	var text bytes.Buffer
+	require.NoError(t, WriteText(&text, results))
+	assert.Equal(t, "Lobby.smw: incomplete symbol \"Delay\" (line 12)\nLobby.smw: warning: Upgrade: older version\n", text.String())
+
+	var out bytes.Buffer
+	require.NoError(t, WriteJSON(&out, results))
+
+	var decoded []Result
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, results, decoded)
+}
@@ -0,0 +1,148 @@
+// Package windowfilter decides which windows the window monitor reports, so
+// monitoring every process does not flood the logs and event subscribers with
+// unrelated desktop windows.
+package windowfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Criteria a rule can match on
+const (
+	KeyClass = "class" // Window class, compared ignoring case
+	KeyTitle = "title" // Regular expression matched against the title
+	KeyPid   = "pid"   // Comma-separated process IDs
+)
+
+// DefaultExcludes are shell windows that never belong to SIMPL Windows
+var DefaultExcludes = []string{
+	"class=Progman",
+	"class=WorkerW",
+	"class=Shell_TrayWnd",
+	"class=Shell_SecondaryTrayWnd",
+	"class=Windows.UI.Core.CoreWindow",
+}
+
+// rule matches windows on one criterion
+type rule struct {
+	class string
+	title *regexp.Regexp
+	pids  map[uint32]bool
+}
+
+// Filter reports a window when it matches none of the exclude rules and, if there
+// are include rules, at least one of them. A nil *Filter allows every window.
+type Filter struct {
+	include []rule
+	exclude []rule
+}
+
+// New parses include and exclude rules such as "class=#32770", "title=(?i)^simpl"
+// or "pid=1234,5678". DefaultExcludes are always applied.
+func New(include, exclude []string) (*Filter, error) {
+	f := &Filter{}
+
+	for _, spec := range include {
+		r, err := parseRule(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		f.include = append(f.include, r)
+	}
+
+	for _, spec := range append(append([]string{}, DefaultExcludes...), exclude...) {
+		r, err := parseRule(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		f.exclude = append(f.exclude, r)
+	}
+
+	return f, nil
+}
+
+// Validate returns an error if any of the specs is not a valid rule
+func Validate(specs []string) error {
+	for _, spec := range specs {
+		if _, err := parseRule(spec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseRule(spec string) (rule, error) {
+	key, value, ok := strings.Cut(spec, "=")
+	if !ok || value == "" {
+		return rule{}, fmt.Errorf("invalid window filter %q: expected %s=, %s= or %s=", spec, KeyClass, KeyTitle, KeyPid)
+	}
+
+	switch strings.TrimSpace(key) {
+	case KeyClass:
+		return rule{class: value}, nil
+	case KeyTitle:
+		title, err := regexp.Compile(value)
+		if err != nil {
+			return rule{}, fmt.Errorf("invalid window filter %q: %w", spec, err)
+		}
+
+		return rule{title: title}, nil
+	case KeyPid:
+		pids := make(map[uint32]bool)
+
+		for _, s := range strings.Split(value, ",") {
+			pid, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+			if err != nil {
+				return rule{}, fmt.Errorf("invalid window filter %q: %q is not a process ID", spec, s)
+			}
+
+			pids[uint32(pid)] = true
+		}
+
+		return rule{pids: pids}, nil
+	default:
+		return rule{}, fmt.Errorf("invalid window filter key %q (supported: %s, %s, %s)", key, KeyClass, KeyTitle, KeyPid)
+	}
+}
+
+func (r rule) matches(class, title string, pid uint32) bool {
+	switch {
+	case r.class != "":
+		return strings.EqualFold(r.class, class)
+	case r.title != nil:
+		return r.title.MatchString(title)
+	default:
+		return r.pids[pid]
+	}
+}
+
+// Allows reports whether a window with the given class, title and process is reported
+func (f *Filter) Allows(class, title string, pid uint32) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, r := range f.exclude {
+		if r.matches(class, title, pid) {
+			return false
+		}
+	}
+
+	if len(f.include) == 0 {
+		return true
+	}
+
+	for _, r := range f.include {
+		if r.matches(class, title, pid) {
+			return true
+		}
+	}
+
+	return false
+}
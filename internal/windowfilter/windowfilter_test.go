@@ -0,0 +1,45 @@
+package windowfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Allows(t *testing.T) {
+	t.Parallel()
+
+	f, err := New([]string{"class=#32770", "title=(?i)^simpl windows", "pid=100,200"}, []string{"title=Compile Log"})
+	require.NoError(t, err)
+
+	assert.True(t, f.Allows("#32770", "Confirmation", 1))
+	assert.True(t, f.Allows("Afx:400000:8", "SIMPL Windows - [lobby.smw]", 1))
+	assert.True(t, f.Allows("Chrome_WidgetWin_1", "Inbox", 200))
+	assert.False(t, f.Allows("Chrome_WidgetWin_1", "Inbox", 300), "matches no include")
+	assert.False(t, f.Allows("#32770", "Compile Log", 100), "excludes win")
+	assert.False(t, f.Allows("progman", "Program Manager", 100), "default exclude")
+}
+
+func TestFilter_NoIncludes(t *testing.T) {
+	t.Parallel()
+
+	f, err := New(nil, nil)
+	require.NoError(t, err)
+
+	assert.True(t, f.Allows("Notepad", "Untitled - Notepad", 1))
+	assert.False(t, f.Allows("Shell_TrayWnd", "", 1))
+
+	var none *Filter
+	assert.True(t, none.Allows("Shell_TrayWnd", "", 1))
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, Validate([]string{"class=#32770", "title=.*", "pid=1, 2"}))
+
+	for _, spec := range []string{"class", "class=", "title=(", "pid=abc", "pid=-1", "hwnd=1"} {
+		assert.Error(t, Validate([]string{spec}), spec)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/Norgate-AV/smpc/internal/keychord"
 	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/timeouts"
 )
 
 const (
@@ -36,6 +38,7 @@ var (
 	user32                       = syscall.NewLazyDLL("user32.dll")
 	procEnumWindows              = user32.NewProc("EnumWindows")
 	procGetWindowTextW           = user32.NewProc("GetWindowTextW")
+	procGetWindowTextLengthW     = user32.NewProc("GetWindowTextLengthW")
 	procGetWindowThreadProcessId = user32.NewProc("GetWindowThreadProcessId")
 	procAttachThreadInput        = user32.NewProc("AttachThreadInput")
 	procIsWindow                 = user32.NewProc("IsWindow")
@@ -50,6 +53,7 @@ var (
 	procShowWindow               = user32.NewProc("ShowWindow")
 	procEnumChildWindows         = user32.NewProc("EnumChildWindows")
 	procGetClassNameW            = user32.NewProc("GetClassNameW")
+	procGetDlgItem               = user32.NewProc("GetDlgItem")
 )
 
 const (
@@ -73,9 +77,13 @@ const (
 	VK_F12    = 0x7B
 	VK_RETURN = 0x0D
 
-	SC_F12     = 0x58
-	SW_RESTORE = 9
-	GW_CHILD   = 5
+	SC_F12             = 0x58
+	SW_HIDE            = 0
+	SW_SHOWNORMAL      = 1
+	SW_SHOWNOACTIVATE  = 4
+	SW_SHOWMINNOACTIVE = 7
+	SW_RESTORE         = 9
+	GW_CHILD           = 5
 
 	TOKEN_QUERY    = 0x0008
 	TokenElevation = 20
@@ -116,6 +124,22 @@ func (w *WindowsAPI) WaitOnMonitor(timeout time.Duration, matchers ...func(Windo
 	return w.client.Window.WaitOnMonitor(timeout, matchers...)
 }
 
+func (w *WindowsAPI) InvokeMenuItem(hwnd uintptr, path ...string) bool {
+	return w.client.Window.InvokeMenuItem(hwnd, path...)
+}
+
+func (w *WindowsAPI) SendMessage(hwnd uintptr, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	return w.client.Window.SendMessage(hwnd, msg, wParam, lParam)
+}
+
+func (w *WindowsAPI) PostMessage(hwnd uintptr, msg uint32, wParam, lParam uintptr) bool {
+	return w.client.Window.PostMessage(hwnd, msg, wParam, lParam)
+}
+
+func (w *WindowsAPI) ClickButtonById(parentHwnd uintptr, id int) bool {
+	return w.client.Window.ClickButtonById(parentHwnd, id)
+}
+
 // KeyboardInjector interface implementation
 func (w *WindowsAPI) SendF12()    { w.client.Keyboard.SendF12() }
 func (w *WindowsAPI) SendAltF12() { w.client.Keyboard.SendAltF12() }
@@ -136,6 +160,17 @@ func (w *WindowsAPI) SendAltF12WithSendInput() bool {
 	return w.client.Keyboard.SendAltF12WithSendInput()
 }
 
+func (w *WindowsAPI) SendChordWithSendInput(chord keychord.Chord) bool {
+	return w.client.Keyboard.SendChordWithSendInput(chord)
+}
+
+func (w *WindowsAPI) SendChord(chord keychord.Chord) { w.client.Keyboard.SendChord(chord) }
+
+// DDEClient interface implementation
+func (w *WindowsAPI) DDEExecute(service, topic, command string) error {
+	return DDEExecute(service, topic, command, timeouts.DDETransactionTimeout)
+}
+
 // ControlReader interface implementation
 func (w *WindowsAPI) GetListBoxItems(hwnd uintptr) []string { return GetListBoxItems(hwnd) }
 func (w *WindowsAPI) GetEditText(hwnd uintptr) string       { return GetEditText(hwnd) }
@@ -13,14 +13,22 @@ type Client struct {
 	Window   *windowManager
 	Keyboard *keyboardInjector
 	Monitor  *monitorManager
+	Events   *EventBus
+	Updates  *EventBus // Changes to the text of open dialogs, when the monitor rescans them
 }
 
 // NewClient creates a new Windows API client
+// The client owns the event buses fed by its monitor and consumed via subscriptions
 func NewClient(log logger.LoggerInterface) *Client {
+	events, updates := NewEventBus(), NewEventBus()
+	window := newWindowManager(log, events)
+
 	return &Client{
 		log:      log,
-		Window:   newWindowManager(log),
-		Keyboard: newKeyboardInjector(log),
-		Monitor:  newMonitorManager(log),
+		Window:   window,
+		Keyboard: newKeyboardInjector(log, window.SetForeground),
+		Monitor:  newMonitorManager(log, events, updates),
+		Events:   events,
+		Updates:  updates,
 	}
 }
@@ -49,16 +49,11 @@ func extractControlInfo(hwnd uintptr, className string) ChildInfo {
 func CollectChildInfos(hwnd uintptr) []ChildInfo {
 	infos := []ChildInfo{}
 
-	cb := func(chWnd uintptr, lparam uintptr) uintptr {
+	enumChildWindows(hwnd, func(chWnd uintptr) {
 		className := GetClassName(chWnd)
-		info := extractControlInfo(chWnd, className)
-		infos = append(infos, info)
-		return 1
-	}
+		infos = append(infos, extractControlInfo(chWnd, className))
+	})
 
-	// EnumChildWindows: return value indicates success but errors aren't meaningful here
-	// The callback approach makes individual error logging impractical
-	_, _, _ = procEnumChildWindows.Call(hwnd, syscall.NewCallback(cb), 0)
 	return infos
 }
 
@@ -112,17 +107,11 @@ func GetEditText(hwnd uintptr) string {
 func CollectChildTexts(hwnd uintptr) []string {
 	texts := []string{}
 
-	// inner callback captures texts
-	cb := func(chWnd uintptr, lparam uintptr) uintptr {
-		t := GetWindowText(chWnd)
-		if t != "" {
+	enumChildWindows(hwnd, func(chWnd uintptr) {
+		if t := GetWindowText(chWnd); t != "" {
 			texts = append(texts, t)
 		}
+	})
 
-		// continue enumeration
-		return 1
-	}
-
-	_, _, _ = procEnumChildWindows.Call(hwnd, syscall.NewCallback(cb), 0)
 	return texts
 }
@@ -0,0 +1,114 @@
+//go:build windows
+
+package windows
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	procDdeInitializeW         = user32.NewProc("DdeInitializeW")
+	procDdeUninitialize        = user32.NewProc("DdeUninitialize")
+	procDdeCreateStringHandleW = user32.NewProc("DdeCreateStringHandleW")
+	procDdeFreeStringHandle    = user32.NewProc("DdeFreeStringHandle")
+	procDdeConnect             = user32.NewProc("DdeConnect")
+	procDdeDisconnect          = user32.NewProc("DdeDisconnect")
+	procDdeClientTransaction   = user32.NewProc("DdeClientTransaction")
+	procDdeGetLastError        = user32.NewProc("DdeGetLastError")
+)
+
+const (
+	APPCMD_CLIENTONLY = 0x00000010
+	CP_WINUNICODE     = 1200
+	XTYP_EXECUTE      = 0x4050
+	DMLERR_NO_ERROR   = 0
+)
+
+// ddeCallback is the no-op DDEML callback required for a client-only instance
+var ddeCallback = syscall.NewCallback(func(uType, uFmt, hconv, hsz1, hsz2, hdata, data1, data2 uintptr) uintptr {
+	return 0
+})
+
+// DDEExecute opens a DDE conversation with service/topic and sends a single
+// XTYP_EXECUTE command, waiting up to timeout for the server to acknowledge it.
+func DDEExecute(service, topic, command string, timeout time.Duration) error {
+	// DDEML instances are bound to the thread that created them
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var inst uint32
+
+	ret, _, _ := procDdeInitializeW.Call(uintptr(unsafe.Pointer(&inst)), ddeCallback, APPCMD_CLIENTONLY, 0)
+	if ret != DMLERR_NO_ERROR {
+		return fmt.Errorf("DdeInitialize failed: DMLERR 0x%X", ret)
+	}
+
+	defer procDdeUninitialize.Call(uintptr(inst))
+
+	hszService, err := ddeString(inst, service)
+	if err != nil {
+		return err
+	}
+
+	defer procDdeFreeStringHandle.Call(uintptr(inst), hszService)
+
+	hszTopic, err := ddeString(inst, topic)
+	if err != nil {
+		return err
+	}
+
+	defer procDdeFreeStringHandle.Call(uintptr(inst), hszTopic)
+
+	conv, _, _ := procDdeConnect.Call(uintptr(inst), hszService, hszTopic, 0)
+	if conv == 0 {
+		return fmt.Errorf("no DDE server for %s|%s: %w", service, topic, ddeLastError(inst))
+	}
+
+	defer procDdeDisconnect.Call(conv)
+
+	data, err := syscall.UTF16FromString(command)
+	if err != nil {
+		return err
+	}
+
+	ret, _, _ = procDdeClientTransaction.Call(
+		uintptr(unsafe.Pointer(&data[0])),
+		uintptr(len(data)*2),
+		conv,
+		0,
+		0,
+		XTYP_EXECUTE,
+		uintptr(timeout/time.Millisecond),
+		0,
+	)
+	if ret == 0 {
+		return fmt.Errorf("DDE execute %q failed: %w", command, ddeLastError(inst))
+	}
+
+	return nil
+}
+
+// ddeString creates a DDEML string handle for s
+func ddeString(inst uint32, s string) (uintptr, error) {
+	ptr, err := syscall.UTF16PtrFromString(s)
+	if err != nil {
+		return 0, err
+	}
+
+	hsz, _, _ := procDdeCreateStringHandleW.Call(uintptr(inst), uintptr(unsafe.Pointer(ptr)), CP_WINUNICODE)
+	if hsz == 0 {
+		return 0, fmt.Errorf("DdeCreateStringHandle %q failed: %w", s, ddeLastError(inst))
+	}
+
+	return hsz, nil
+}
+
+// ddeLastError returns the DDEML error code of the last failed call on inst
+func ddeLastError(inst uint32) error {
+	code, _, _ := procDdeGetLastError.Call(uintptr(inst))
+	return fmt.Errorf("DMLERR 0x%X", code)
+}
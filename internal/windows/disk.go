@@ -0,0 +1,29 @@
+//go:build windows
+
+package windows
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var procGetDiskFreeSpaceExW = kernel32.NewProc("GetDiskFreeSpaceExW")
+
+// DiskFreeSpace returns the bytes free to the current user on the volume holding
+// dir, which accounts for disk quotas
+func DiskFreeSpace(dir string) (uint64, error) {
+	p, err := syscall.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var free uint64
+
+	ret, _, callErr := procGetDiskFreeSpaceExW.Call(uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(&free)), 0, 0)
+	if ret == 0 {
+		return 0, fmt.Errorf("GetDiskFreeSpaceEx failed for %s: %w", dir, callErr)
+	}
+
+	return free, nil
+}
@@ -6,9 +6,22 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"syscall"
+	"time"
 	"unsafe"
 )
 
+var (
+	procWaitForSingleObject = kernel32.NewProc("WaitForSingleObject")
+	procGetExitCodeProcess  = kernel32.NewProc("GetExitCodeProcess")
+)
+
+const (
+	WAIT_OBJECT_0 = 0x00000000
+	WAIT_TIMEOUT  = 0x00000102
+)
+
+// IsElevated reports whether the current process token is elevated
 func IsElevated() bool {
 	var token uintptr
 
@@ -49,19 +62,76 @@ func IsElevated() bool {
 	return elevation.TokenIsElevated != 0
 }
 
+// RelaunchAsAdmin starts an elevated copy of the current executable with the same arguments
 func RelaunchAsAdmin() error {
-	exe, err := os.Executable()
+	exe, args, err := relaunchCommand(nil)
 	if err != nil {
 		return err
 	}
 
+	return ShellExecute(0, "runas", exe, args, "", 1)
+}
+
+// RelaunchAsAdminAndWait starts a hidden, elevated copy of the current executable with
+// extraArgs appended and waits for it to exit, calling poll every interval meanwhile and
+// once more at the end. It returns the elevated instance's exit code.
+func RelaunchAsAdminAndWait(extraArgs []string, interval time.Duration, poll func()) (int, error) {
+	exe, args, err := relaunchCommand(extraArgs)
+	if err != nil {
+		return 0, err
+	}
+
+	hProcess, err := shellExecuteExProcess(0, "runas", exe, args, "", SW_HIDE)
+	if err != nil {
+		return 0, err
+	}
+
+	defer ProcCloseHandle.Call(hProcess)
+
+	for {
+		ret, _, callErr := procWaitForSingleObject.Call(hProcess, uintptr(interval/time.Millisecond))
+		poll()
+
+		if ret == WAIT_OBJECT_0 {
+			break
+		}
+
+		if ret != WAIT_TIMEOUT {
+			return 0, fmt.Errorf("failed waiting for elevated instance: %w", callErr)
+		}
+	}
+
+	var code uint32
+
+	ret, _, callErr := procGetExitCodeProcess.Call(hProcess, uintptr(unsafe.Pointer(&code)))
+	if ret == 0 {
+		return 0, fmt.Errorf("failed to get elevated instance exit code: %w", callErr)
+	}
+
+	return int(code), nil
+}
+
+// relaunchCommand returns the executable and quoted argument string to relaunch this process
+func relaunchCommand(extraArgs []string) (string, string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", "", err
+	}
+
 	// Check if running via 'go run' (exe will be in temp dir)
 	if strings.Contains(exe, "go-build") {
-		return fmt.Errorf("cannot relaunch when run via 'go run', please build the executable first with: go build -o smpc.exe")
+		return "", "", fmt.Errorf("cannot relaunch when run via 'go run', please build the executable first with: go build -o smpc.exe")
 	}
 
-	// Build args string (excluding the exe name)
-	args := strings.Join(os.Args[1:], " ")
+	// Build args string (excluding the exe name), quoting paths with spaces or quotes
+	quoted := make([]string, 0, len(os.Args)-1+len(extraArgs))
+	for _, arg := range os.Args[1:] {
+		quoted = append(quoted, syscall.EscapeArg(arg))
+	}
 
-	return ShellExecute(0, "runas", exe, args, "", 1)
+	for _, arg := range extraArgs {
+		quoted = append(quoted, syscall.EscapeArg(arg))
+	}
+
+	return exe, strings.Join(quoted, " "), nil
 }
@@ -0,0 +1,162 @@
+//go:build windows
+
+package windows
+
+import "sync"
+
+const (
+	// recentEventsLimit is the number of events kept for late subscribers and WaitOnMonitor
+	recentEventsLimit = 256
+
+	// DefaultSubscriptionBuffer is the channel buffer size used for ordinary subscriptions
+	DefaultSubscriptionBuffer = 64
+)
+
+// EventBus distributes window events from a monitor to any number of subscribers.
+// Each subscriber has its own buffered channel, so a slow consumer can never
+// swallow events destined for another one.
+type EventBus struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	recent []WindowEvent
+}
+
+// Subscription is a single consumer's view of an EventBus
+type Subscription struct {
+	C   <-chan WindowEvent
+	ch  chan WindowEvent
+	bus *EventBus
+}
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber that receives events published from now on
+func (b *EventBus) Subscribe(buffer int) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.subscribeLocked(buffer)
+}
+
+// SubscribeWithHistory registers a new subscriber and pre-loads its channel with
+// the most recent events (up to buffer), so dialogs that appeared before the
+// subscription was created are not missed.
+func (b *EventBus) SubscribeWithHistory(buffer int) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := b.subscribeLocked(buffer)
+
+	history := b.recent
+	if len(history) > buffer {
+		history = history[len(history)-buffer:]
+	}
+
+	for _, ev := range history {
+		sub.ch <- ev
+	}
+
+	return sub
+}
+
+func (b *EventBus) subscribeLocked(buffer int) *Subscription {
+	ch := make(chan WindowEvent, buffer)
+	sub := &Subscription{C: ch, ch: ch, bus: b}
+	b.subs[sub] = struct{}{}
+
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its channel. It is safe to call more than once.
+func (b *EventBus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+
+	delete(b.subs, sub)
+	close(sub.ch)
+}
+
+// Unsubscribe removes this subscription from its bus
+func (s *Subscription) Unsubscribe() {
+	s.bus.Unsubscribe(s)
+}
+
+// Observe calls fn for every event published from now on, on its own goroutine,
+// until the returned stop function is called. It is intended for passive
+// observers (progress displays, event streams) that must not interfere with
+// the compiler's own subscriptions.
+func (b *EventBus) Observe(fn func(WindowEvent)) (stop func()) {
+	sub := b.Subscribe(DefaultSubscriptionBuffer)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for ev := range sub.C {
+			fn(ev)
+		}
+	}()
+
+	return func() {
+		sub.Unsubscribe()
+		<-done
+	}
+}
+
+// Publish delivers an event to every subscriber without blocking.
+// It returns the number of subscribers whose buffer was full and missed the event.
+func (b *EventBus) Publish(ev WindowEvent) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.recent = append(b.recent, ev)
+	if len(b.recent) > recentEventsLimit {
+		b.recent = b.recent[len(b.recent)-recentEventsLimit:]
+	}
+
+	dropped := 0
+	for sub := range b.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			dropped++
+		}
+	}
+
+	return dropped
+}
+
+// FindRecent returns the most recent already-published event matching any of the matchers
+func (b *EventBus) FindRecent(matchers ...func(WindowEvent) bool) (WindowEvent, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i := len(b.recent) - 1; i >= 0; i-- {
+		ev := b.recent[i]
+
+		for _, m := range matchers {
+			if m(ev) {
+				return ev, true
+			}
+		}
+	}
+
+	return WindowEvent{}, false
+}
+
+// SubscriberCount returns the number of active subscriptions
+func (b *EventBus) SubscriberCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subs)
+}
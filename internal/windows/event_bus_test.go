@@ -0,0 +1,137 @@
+//go:build windows
+
+package windows
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBus_FanOutToAllSubscribers(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus()
+	first := bus.Subscribe(4)
+	second := bus.Subscribe(4)
+
+	dropped := bus.Publish(WindowEvent{Hwnd: 0x1111, Title: "Compiling..."})
+	assert.Equal(t, 0, dropped)
+
+	// Both subscribers receive the same event - neither can swallow it from the other
+	assert.Equal(t, "Compiling...", (<-first.C).Title)
+	assert.Equal(t, "Compiling...", (<-second.C).Title)
+}
+
+func TestEventBus_SubscribeWithHistory(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus()
+	bus.Publish(WindowEvent{Hwnd: 0x1111, Title: "Operation Complete"})
+	bus.Publish(WindowEvent{Hwnd: 0x2222, Title: "SIMPL Windows - [test.smw]"})
+
+	plain := bus.Subscribe(4)
+	replayed := bus.SubscribeWithHistory(4)
+
+	assert.Len(t, plain.C, 0, "Plain subscription should not see earlier events")
+	require.Len(t, replayed.C, 2, "History subscription should be pre-loaded with earlier events")
+	assert.Equal(t, "Operation Complete", (<-replayed.C).Title)
+	assert.Equal(t, uintptr(0x2222), (<-replayed.C).Hwnd)
+}
+
+func TestEventBus_HistoryLimitedToBuffer(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus()
+	for i := range 5 {
+		bus.Publish(WindowEvent{Hwnd: uintptr(i + 1)})
+	}
+
+	sub := bus.SubscribeWithHistory(2)
+	require.Len(t, sub.C, 2)
+	assert.Equal(t, uintptr(4), (<-sub.C).Hwnd, "Only the most recent events should be replayed")
+	assert.Equal(t, uintptr(5), (<-sub.C).Hwnd)
+}
+
+func TestEventBus_SlowSubscriberDropsWithoutAffectingOthers(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus()
+	slow := bus.Subscribe(1)
+	fast := bus.Subscribe(4)
+
+	bus.Publish(WindowEvent{Hwnd: 1})
+	dropped := bus.Publish(WindowEvent{Hwnd: 2})
+
+	assert.Equal(t, 1, dropped, "Only the full subscriber should miss the event")
+	assert.Len(t, slow.C, 1)
+	assert.Len(t, fast.C, 2)
+}
+
+func TestEventBus_Unsubscribe(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus()
+	sub := bus.Subscribe(4)
+	assert.Equal(t, 1, bus.SubscriberCount())
+
+	sub.Unsubscribe()
+	sub.Unsubscribe() // Safe to call twice
+
+	assert.Equal(t, 0, bus.SubscriberCount())
+
+	_, open := <-sub.C
+	assert.False(t, open, "Channel should be closed after unsubscribing")
+
+	// Publishing after unsubscribe must not panic on the closed channel
+	assert.Equal(t, 0, bus.Publish(WindowEvent{Hwnd: 1}))
+}
+
+func TestEventBus_FindRecent(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus()
+	bus.Publish(WindowEvent{Hwnd: 1, Title: "Compile Complete"})
+	bus.Publish(WindowEvent{Hwnd: 2, Title: "Compile Complete"})
+
+	ev, ok := bus.FindRecent(func(ev WindowEvent) bool { return ev.Title == "Compile Complete" })
+	assert.True(t, ok)
+	assert.Equal(t, uintptr(2), ev.Hwnd, "Most recent match should be returned")
+
+	_, ok = bus.FindRecent(func(ev WindowEvent) bool { return ev.Title == "Confirmation" })
+	assert.False(t, ok)
+}
+
+func TestEventBus_ObserveSeesEventsAlongsideSubscribers(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus()
+	sub := bus.Subscribe(4)
+
+	var (
+		mu       sync.Mutex
+		observed []string
+	)
+
+	stop := bus.Observe(func(ev WindowEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		observed = append(observed, ev.Title)
+	})
+
+	bus.Publish(WindowEvent{Hwnd: 1, Title: "Compiling..."})
+	bus.Publish(WindowEvent{Hwnd: 2, Title: "Compile Complete"})
+
+	// stop waits for the observer to drain everything already delivered
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"Compiling...", "Compile Complete"}, observed)
+	assert.Len(t, sub.C, 2, "Observer must not consume events from other subscribers")
+	assert.Equal(t, 1, bus.SubscriberCount())
+}
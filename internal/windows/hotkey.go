@@ -0,0 +1,118 @@
+//go:build windows
+
+package windows
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"github.com/Norgate-AV/smpc/internal/keychord"
+)
+
+var (
+	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
+	procGetMessageW        = user32.NewProc("GetMessageW")
+	procPostThreadMessageW = user32.NewProc("PostThreadMessageW")
+	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
+)
+
+const (
+	WM_QUIT   = 0x0012
+	WM_HOTKEY = 0x0312
+
+	MOD_ALT      = 0x0001
+	MOD_CONTROL  = 0x0002
+	MOD_SHIFT    = 0x0004
+	MOD_WIN      = 0x0008
+	MOD_NOREPEAT = 0x4000
+
+	hotkeyID = 1
+)
+
+// threadMsg mirrors MSG for the hotkey message loop
+type threadMsg struct {
+	Hwnd    uintptr
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	PtX     int32
+	PtY     int32
+}
+
+// hotkeyModifiers converts a chord's modifier keys to RegisterHotKey flags
+func hotkeyModifiers(chord keychord.Chord) uintptr {
+	mods := uintptr(MOD_NOREPEAT)
+
+	for _, vk := range chord.Modifiers {
+		switch vk {
+		case keychord.VKControl:
+			mods |= MOD_CONTROL
+		case keychord.VKMenu:
+			mods |= MOD_ALT
+		case keychord.VKShift:
+			mods |= MOD_SHIFT
+		case keychord.VKLWin:
+			mods |= MOD_WIN
+		}
+	}
+
+	return mods
+}
+
+// RegisterGlobalHotkey calls fn on its own goroutine each time chord is pressed,
+// whichever window has focus, until the returned stop function is called
+func RegisterGlobalHotkey(chord keychord.Chord, fn func()) (stop func(), err error) {
+	type registration struct {
+		threadID uintptr
+		err      error
+	}
+
+	registered := make(chan registration, 1)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		// Hotkey messages are posted to the registering thread's queue
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		threadID, _, _ := procGetCurrentThreadId.Call()
+
+		ret, _, callErr := procRegisterHotKey.Call(0, hotkeyID, hotkeyModifiers(chord), uintptr(chord.Key))
+		if ret == 0 {
+			registered <- registration{err: fmt.Errorf("failed to register hotkey %s: %w", chord, callErr)}
+			return
+		}
+
+		defer procUnregisterHotKey.Call(0, hotkeyID)
+
+		registered <- registration{threadID: threadID}
+
+		var msg threadMsg
+		for {
+			ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+			if int32(ret) <= 0 {
+				return
+			}
+
+			if msg.Message == WM_HOTKEY && msg.WParam == hotkeyID {
+				go fn()
+			}
+		}
+	}()
+
+	reg := <-registered
+	if reg.err != nil {
+		<-done
+		return nil, reg.err
+	}
+
+	return func() {
+		_, _, _ = procPostThreadMessageW.Call(reg.threadID, WM_QUIT, 0, 0)
+		<-done
+	}, nil
+}
@@ -7,72 +7,64 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/Norgate-AV/smpc/internal/keychord"
 	"github.com/Norgate-AV/smpc/internal/logger"
 	"github.com/Norgate-AV/smpc/internal/timeouts"
 )
 
+// maxChordAttempts is how many times a keybd_event chord is started before giving up
+// when focus keeps being stolen part way through
+const maxChordAttempts = 3
+
 // keyboardInjector implements the KeyboardInjector interface
 type keyboardInjector struct {
-	log logger.LoggerInterface
+	log     logger.LoggerInterface
+	refocus func(hwnd uintptr) bool // Brings a window back to the foreground
 }
 
-// newKeyboardInjector creates a new keyboard injector
-func newKeyboardInjector(log logger.LoggerInterface) *keyboardInjector {
-	return &keyboardInjector{log: log}
+// newKeyboardInjector creates a new keyboard injector that uses refocus to win back
+// the foreground when it is lost in the middle of a chord
+func newKeyboardInjector(log logger.LoggerInterface, refocus func(hwnd uintptr) bool) *keyboardInjector {
+	return &keyboardInjector{log: log, refocus: refocus}
 }
 
-// SendF12 sends the F12 key
-func (k *keyboardInjector) SendF12() {
-	// VK_F12 = 0x7B
-	vkCode := uintptr(0x7B)
-
-	// keybd_event(vk, scan, flags, extraInfo)
-	// Note: keybd_event has void return type, no error checking needed
-	k.log.Debug("Sending F12 KEYDOWN")
-	_, _, _ = procKeybd_event.Call(vkCode, 0, 0x1, 0) // KEYEVENTF_EXTENDEDKEY
+// foregroundWindow returns the window that currently receives keyboard input
+func foregroundWindow() uintptr {
+	hwnd, _, _ := procGetForegroundWindow.Call()
+	return hwnd
+}
 
-	time.Sleep(timeouts.KeystrokeDelay)
+// keybdEvent presses or releases vk with keybd_event, passing its scan code so the
+// generated message matches the physical key
+func keybdEvent(vk uint16, up bool) {
+	scan, _ := keychord.ScanCode(vk)
 
-	k.log.Debug("Sending F12 KEYUP")
-	_, _, _ = procKeybd_event.Call(vkCode, 0, 0x1|0x2, 0) // KEYEVENTF_EXTENDEDKEY | KEYEVENTF_KEYUP
-}
+	var flags uintptr
+	if keychord.IsExtended(vk) {
+		flags |= KEYEVENTF_EXTENDEDKEY
+	}
 
-// SendAltF12 sends the Alt+F12 key combination
-func (k *keyboardInjector) SendAltF12() {
-	// VK_MENU (Alt) = 0x12
-	// VK_F12 = 0x7B
-	vkAlt := uintptr(0x12)
-	vkF12 := uintptr(0x7B)
+	if up {
+		flags |= KEYEVENTF_KEYUP
+	}
 
 	// Note: keybd_event has void return type, no error checking needed
-	k.log.Debug("Sending Alt KEYDOWN")
-	_, _, _ = procKeybd_event.Call(vkAlt, 0, 0x1, 0) // KEYEVENTF_EXTENDEDKEY
-	time.Sleep(timeouts.KeystrokeDelay)
-
-	k.log.Debug("Sending F12 KEYDOWN")
-	_, _, _ = procKeybd_event.Call(vkF12, 0, 0x1, 0) // KEYEVENTF_EXTENDEDKEY
-	time.Sleep(timeouts.KeystrokeDelay)
+	_, _, _ = procKeybd_event.Call(uintptr(vk), uintptr(scan), flags, 0)
+}
 
-	k.log.Debug("Sending F12 KEYUP")
-	_, _, _ = procKeybd_event.Call(vkF12, 0, 0x1|0x2, 0) // KEYEVENTF_EXTENDEDKEY | KEYEVENTF_KEYUP
-	time.Sleep(timeouts.KeystrokeDelay)
+// SendF12 sends the F12 key
+func (k *keyboardInjector) SendF12() {
+	k.SendChord(keychord.MustParse("f12"))
+}
 
-	k.log.Debug("Sending Alt KEYUP")
-	_, _, _ = procKeybd_event.Call(vkAlt, 0, 0x1|0x2, 0) // KEYEVENTF_EXTENDEDKEY | KEYEVENTF_KEYUP
+// SendAltF12 sends the Alt+F12 key combination
+func (k *keyboardInjector) SendAltF12() {
+	k.SendChord(keychord.MustParse("alt+f12"))
 }
 
 // SendEnter sends the Enter key
 func (k *keyboardInjector) SendEnter() {
-	// VK_RETURN = 0x0D
-	vkCode := uintptr(0x0D)
-
-	// Note: keybd_event has void return type, no error checking needed
-	k.log.Debug("Sending Enter KEYDOWN")
-	_, _, _ = procKeybd_event.Call(vkCode, 0, 0x1, 0)
-	time.Sleep(timeouts.KeystrokeDelay)
-
-	k.log.Debug("Sending Enter KEYUP")
-	_, _, _ = procKeybd_event.Call(vkCode, 0, 0x1|0x2, 0)
+	k.SendChord(keychord.MustParse("enter"))
 }
 
 // SendF12ToWindow sends F12 key directly to a specific window using SendMessage
@@ -94,11 +86,11 @@ func (k *keyboardInjector) SendF12ToWindow(hwnd uintptr) bool {
 	// Try SendMessage first (synchronous)
 	k.log.Debug("Trying SendMessage for F12")
 	ret, _, _ := procSendMessageW.Call(hwnd, WM_KEYDOWN, VK_F12, lParamDown)
-	k.log.Debug("SendMessage WM_KEYDOWN returned", slog.Uint64("ret", uint64(ret)))
+	k.log.Trace("SendMessage WM_KEYDOWN returned", slog.Uint64("ret", uint64(ret)))
 	time.Sleep(timeouts.KeystrokeDelay)
 
 	ret, _, _ = procSendMessageW.Call(hwnd, WM_KEYUP, VK_F12, lParamUp)
-	k.log.Debug("SendMessage WM_KEYUP returned", slog.Uint64("ret", uint64(ret)))
+	k.log.Trace("SendMessage WM_KEYUP returned", slog.Uint64("ret", uint64(ret)))
 
 	k.log.Debug("F12 sent via SendMessage (synchronous)")
 	return true
@@ -125,34 +117,34 @@ func (k *keyboardInjector) SendAltF12ToWindow(hwnd uintptr) bool {
 	lParamAltUp := uintptr(1 | (scanCodeAlt << 16) | (1 << 24) | (1 << 29) | (1 << 30) | (1 << 31))
 
 	// Send Alt down
-	k.log.Debug("Sending WM_SYSKEYDOWN (Alt)")
+	k.log.Trace("Sending WM_SYSKEYDOWN (Alt)")
 	ret, _, err := procSendMessageW.Call(hwnd, WM_SYSKEYDOWN, VK_MENU, lParamAltDown)
 	if ret == 0 {
-		k.log.Debug("SendMessage WM_SYSKEYDOWN Alt failed", slog.Any("error", err))
+		k.log.Trace("SendMessage WM_SYSKEYDOWN Alt failed", slog.Any("error", err))
 	}
 	time.Sleep(timeouts.KeystrokeDelay)
 
 	// Send F12 down
-	k.log.Debug("Sending WM_SYSKEYDOWN (F12)")
+	k.log.Trace("Sending WM_SYSKEYDOWN (F12)")
 	ret, _, err = procSendMessageW.Call(hwnd, WM_SYSKEYDOWN, VK_F12, lParamF12Down)
 	if ret == 0 {
-		k.log.Debug("SendMessage WM_SYSKEYDOWN F12 failed", slog.Any("error", err))
+		k.log.Trace("SendMessage WM_SYSKEYDOWN F12 failed", slog.Any("error", err))
 	}
 	time.Sleep(timeouts.KeystrokeDelay)
 
 	// Send F12 up
-	k.log.Debug("Sending WM_SYSKEYUP (F12)")
+	k.log.Trace("Sending WM_SYSKEYUP (F12)")
 	ret, _, err = procSendMessageW.Call(hwnd, WM_SYSKEYUP, VK_F12, lParamF12Up)
 	if ret == 0 {
-		k.log.Debug("SendMessage WM_SYSKEYUP F12 failed", slog.Any("error", err))
+		k.log.Trace("SendMessage WM_SYSKEYUP F12 failed", slog.Any("error", err))
 	}
 	time.Sleep(timeouts.KeystrokeDelay)
 
 	// Send Alt up
-	k.log.Debug("Sending WM_SYSKEYUP (Alt)")
+	k.log.Trace("Sending WM_SYSKEYUP (Alt)")
 	ret, _, err = procSendMessageW.Call(hwnd, WM_SYSKEYUP, VK_MENU, lParamAltUp)
 	if ret == 0 {
-		k.log.Debug("SendMessage WM_SYSKEYUP Alt failed", slog.Any("error", err))
+		k.log.Trace("SendMessage WM_SYSKEYUP Alt failed", slog.Any("error", err))
 	}
 
 	k.log.Debug("Alt+F12 sent via SendMessage (synchronous)")
@@ -161,24 +153,64 @@ func (k *keyboardInjector) SendAltF12ToWindow(hwnd uintptr) bool {
 
 // SendF12WithSendInput sends F12 key using SendInput API (more modern than keybd_event)
 func (k *keyboardInjector) SendF12WithSendInput() bool {
-	k.log.Debug("Sending F12 via SendInput")
+	return k.SendChordWithSendInput(keychord.MustParse("f12"))
+}
 
-	// Create INPUT structure for keydown
-	inputs := make([]INPUT, 2)
+// SendAltF12WithSendInput sends Alt+F12 key using SendInput API
+func (k *keyboardInjector) SendAltF12WithSendInput() bool {
+	return k.SendChordWithSendInput(keychord.MustParse("alt+f12"))
+}
 
-	// F12 KEYDOWN
-	inputs[0].Type = INPUT_KEYBOARD
-	kb := (*KEYBDINPUT)(unsafe.Pointer(&inputs[0].Data[0]))
-	kb.WVk = VK_F12
-	kb.DwFlags = KEYEVENTF_EXTENDEDKEY
+// keyInput builds the SendInput event for pressing or releasing vk. Keys with a
+// layout-independent scan code are sent with KEYEVENTF_SCANCODE, so they arrive as the
+// same physical key whichever keyboard layout is active; others are sent by virtual-key code.
+func keyInput(vk uint16, up bool) INPUT {
+	var in INPUT
+	in.Type = INPUT_KEYBOARD
+	kb := (*KEYBDINPUT)(unsafe.Pointer(&in.Data[0]))
+
+	if scan, ok := keychord.ScanCode(vk); ok {
+		kb.WScan = scan
+		kb.DwFlags = KEYEVENTF_SCANCODE
+	} else {
+		kb.WVk = vk
+	}
 
-	// F12 KEYUP
-	inputs[1].Type = INPUT_KEYBOARD
-	kb2 := (*KEYBDINPUT)(unsafe.Pointer(&inputs[1].Data[0]))
-	kb2.WVk = VK_F12
-	kb2.DwFlags = KEYEVENTF_EXTENDEDKEY | KEYEVENTF_KEYUP
+	if keychord.IsExtended(vk) {
+		kb.DwFlags |= KEYEVENTF_EXTENDEDKEY
+	}
+
+	if up {
+		kb.DwFlags |= KEYEVENTF_KEYUP
+	}
+
+	return in
+}
+
+// chordInputs builds the SendInput sequence for a chord: modifiers down in order,
+// key down/up, then modifiers up in reverse order
+func chordInputs(chord keychord.Chord) []INPUT {
+	inputs := make([]INPUT, 0, 2*len(chord.Modifiers)+2)
+
+	for _, mod := range chord.Modifiers {
+		inputs = append(inputs, keyInput(mod, false))
+	}
+
+	inputs = append(inputs, keyInput(chord.Key, false), keyInput(chord.Key, true))
+
+	for i := len(chord.Modifiers) - 1; i >= 0; i-- {
+		inputs = append(inputs, keyInput(chord.Modifiers[i], true))
+	}
+
+	return inputs
+}
+
+// SendChordWithSendInput sends a configured key chord atomically using SendInput
+func (k *keyboardInjector) SendChordWithSendInput(chord keychord.Chord) bool {
+	k.log.Debug("Sending key chord via SendInput", slog.String("chord", chord.String()))
+
+	inputs := chordInputs(chord)
 
-	// Send the input
 	ret, _, _ := procSendInput.Call(
 		uintptr(len(inputs)),
 		uintptr(unsafe.Pointer(&inputs[0])),
@@ -190,53 +222,100 @@ func (k *keyboardInjector) SendF12WithSendInput() bool {
 		return false
 	}
 
-	k.log.Debug("F12 sent via SendInput successfully")
+	k.log.Debug("Key chord sent via SendInput successfully", slog.String("chord", chord.String()))
 	return true
 }
 
-// SendAltF12WithSendInput sends Alt+F12 key using SendInput API
-func (k *keyboardInjector) SendAltF12WithSendInput() bool {
-	k.log.Debug("Sending Alt+F12 via SendInput")
-
-	// Create INPUT structures for Alt down, F12 down, F12 up, Alt up
-	inputs := make([]INPUT, 4)
-
-	// Alt KEYDOWN
-	inputs[0].Type = INPUT_KEYBOARD
-	kb0 := (*KEYBDINPUT)(unsafe.Pointer(&inputs[0].Data[0]))
-	kb0.WVk = VK_MENU
-	kb0.DwFlags = KEYEVENTF_EXTENDEDKEY
-
-	// F12 KEYDOWN
-	inputs[1].Type = INPUT_KEYBOARD
-	kb1 := (*KEYBDINPUT)(unsafe.Pointer(&inputs[1].Data[0]))
-	kb1.WVk = VK_F12
-	kb1.DwFlags = KEYEVENTF_EXTENDEDKEY
-
-	// F12 KEYUP
-	inputs[2].Type = INPUT_KEYBOARD
-	kb2 := (*KEYBDINPUT)(unsafe.Pointer(&inputs[2].Data[0]))
-	kb2.WVk = VK_F12
-	kb2.DwFlags = KEYEVENTF_EXTENDEDKEY | KEYEVENTF_KEYUP
-
-	// Alt KEYUP
-	inputs[3].Type = INPUT_KEYBOARD
-	kb3 := (*KEYBDINPUT)(unsafe.Pointer(&inputs[3].Data[0]))
-	kb3.WVk = VK_MENU
-	kb3.DwFlags = KEYEVENTF_EXTENDEDKEY | KEYEVENTF_KEYUP
-
-	// Send all inputs
-	ret, _, _ := procSendInput.Call(
-		uintptr(len(inputs)),
-		uintptr(unsafe.Pointer(&inputs[0])),
-		uintptr(unsafe.Sizeof(INPUT{})),
+// SendChord sends a configured key chord using keybd_event. Unlike SendInput the keys
+// are pressed one at a time, so a watchdog checks after each modifier that the window
+// that was in the foreground when the chord started still is; if another window took
+// focus, the held keys are released, focus is re-acquired and the chord restarted.
+func (k *keyboardInjector) SendChord(chord keychord.Chord) {
+	w := chordWatchdog{
+		log:        k.log,
+		press:      keybdEvent,
+		foreground: foregroundWindow,
+		refocus:    k.refocus,
+		delay:      timeouts.KeystrokeDelay,
+	}
+
+	w.send(chord)
+}
+
+// chordWatchdog presses a chord key by key, restarting it when focus is stolen
+type chordWatchdog struct {
+	log        logger.LoggerInterface
+	press      func(vk uint16, up bool)
+	foreground func() uintptr
+	refocus    func(hwnd uintptr) bool
+	delay      time.Duration
+}
+
+// send presses the chord and reports whether it went to the window that was in the
+// foreground when it started. The key itself is never pressed while another window
+// has focus.
+func (w chordWatchdog) send(chord keychord.Chord) bool {
+	target := w.foreground()
+
+	for attempt := 1; attempt <= maxChordAttempts; attempt++ {
+		if w.pressChord(chord, target) {
+			return true
+		}
+
+		w.log.Warn("Focus lost during key chord, restarting",
+			slog.String("chord", chord.String()),
+			slog.Uint64("expected_hwnd", uint64(target)),
+			slog.Uint64("actual_hwnd", uint64(w.foreground())),
+			slog.Int("attempt", attempt),
+		)
+
+		if w.refocus == nil || !w.refocus(target) {
+			w.log.Warn("Could not re-acquire focus", slog.Uint64("hwnd", uint64(target)))
+		}
+
+		time.Sleep(w.delay)
+	}
+
+	w.log.Error("Gave up sending key chord, focus kept being stolen",
+		slog.String("chord", chord.String()),
+		slog.Int("attempts", maxChordAttempts),
 	)
 
-	if ret != uintptr(len(inputs)) {
-		k.log.Warn("SendInput failed", slog.Uint64("expected", uint64(len(inputs))), slog.Uint64("sent", uint64(ret)))
-		return false
+	return false
+}
+
+// pressChord presses the modifiers, then the key, then releases the modifiers in
+// reverse order. It returns false, with every held modifier released, if target
+// lost the foreground before the key was pressed.
+func (w chordWatchdog) pressChord(chord keychord.Chord, target uintptr) bool {
+	for i, mod := range chord.Modifiers {
+		w.log.Trace("Sending modifier KEYDOWN", slog.Uint64("vk", uint64(mod)))
+		w.press(mod, false)
+		time.Sleep(w.delay)
+
+		if w.foreground() != target {
+			w.release(chord.Modifiers[:i+1])
+			return false
+		}
 	}
 
-	k.log.Debug("Alt+F12 sent via SendInput successfully")
+	w.log.Trace("Sending key KEYDOWN", slog.Uint64("vk", uint64(chord.Key)))
+	w.press(chord.Key, false)
+	time.Sleep(w.delay)
+
+	w.log.Trace("Sending key KEYUP", slog.Uint64("vk", uint64(chord.Key)))
+	w.press(chord.Key, true)
+
+	w.release(chord.Modifiers)
 	return true
 }
+
+// release lets go of held modifiers in reverse order
+func (w chordWatchdog) release(held []uint16) {
+	for i := len(held) - 1; i >= 0; i-- {
+		time.Sleep(w.delay)
+
+		w.log.Trace("Sending modifier KEYUP", slog.Uint64("vk", uint64(held[i])))
+		w.press(held[i], true)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build windows
+
+package windows
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Norgate-AV/smpc/internal/keychord"
+	"github.com/Norgate-AV/smpc/internal/logger"
+)
+
+type keyPress struct {
+	vk uint16
+	up bool
+}
+
+func TestChordWatchdog_SendsChordWhileFocused(t *testing.T) {
+	t.Parallel()
+
+	var presses []keyPress
+
+	w := chordWatchdog{
+		log:        logger.NewNoOpLogger(),
+		press:      func(vk uint16, up bool) { presses = append(presses, keyPress{vk, up}) },
+		foreground: func() uintptr { return 1 },
+		refocus:    func(uintptr) bool { t.Fatal("refocus should not be called"); return false },
+	}
+
+	assert.True(t, w.send(keychord.MustParse("alt+f12")))
+	assert.Equal(t, []keyPress{
+		{VK_MENU, false}, {VK_F12, false}, {VK_F12, true}, {VK_MENU, true},
+	}, presses)
+}
+
+func TestChordWatchdog_RestartsWhenFocusStolen(t *testing.T) {
+	t.Parallel()
+
+	var (
+		presses   []keyPress
+		refocused []uintptr
+	)
+
+	fg := uintptr(1)
+	w := chordWatchdog{
+		log: logger.NewNoOpLogger(),
+		press: func(vk uint16, up bool) {
+			presses = append(presses, keyPress{vk, up})
+
+			// Another window steals focus as Alt goes down the first time
+			if vk == VK_MENU && !up && len(refocused) == 0 {
+				fg = 2
+			}
+		},
+		foreground: func() uintptr { return fg },
+		refocus: func(hwnd uintptr) bool {
+			refocused = append(refocused, hwnd)
+			fg = hwnd
+			return true
+		},
+	}
+
+	assert.True(t, w.send(keychord.MustParse("alt+f12")))
+	assert.Equal(t, []uintptr{1}, refocused)
+	assert.Equal(t, []keyPress{
+		{VK_MENU, false}, {VK_MENU, true}, // Released without pressing F12
+		{VK_MENU, false}, {VK_F12, false}, {VK_F12, true}, {VK_MENU, true},
+	}, presses)
+}
+
+func TestChordWatchdog_GivesUpWhenFocusKeepsBeingStolen(t *testing.T) {
+	t.Parallel()
+
+	var presses []keyPress
+
+	fg := uintptr(1)
+	w := chordWatchdog{
+		log: logger.NewNoOpLogger(),
+		press: func(vk uint16, up bool) {
+			presses = append(presses, keyPress{vk, up})
+			if !up {
+				fg = 2
+			}
+		},
+		foreground: func() uintptr { return fg },
+		refocus: func(hwnd uintptr) bool {
+			fg = hwnd
+			return true
+		},
+	}
+
+	assert.False(t, w.send(keychord.MustParse("alt+f12")))
+	assert.NotContains(t, presses, keyPress{VK_F12, false})
+	assert.Len(t, presses, 2*maxChordAttempts)
+}
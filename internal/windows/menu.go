@@ -0,0 +1,125 @@
+//go:build windows
+
+package windows
+
+import (
+	"log/slog"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procGetMenu          = user32.NewProc("GetMenu")
+	procGetSubMenu       = user32.NewProc("GetSubMenu")
+	procGetMenuItemCount = user32.NewProc("GetMenuItemCount")
+	procGetMenuItemID    = user32.NewProc("GetMenuItemID")
+	procGetMenuStringW   = user32.NewProc("GetMenuStringW")
+)
+
+const (
+	MF_BYPOSITION = 0x0400
+
+	// menuItemSubmenu is returned by GetMenuItemID for items that open a submenu
+	menuItemSubmenu = 0xFFFFFFFF
+)
+
+// FindMenuCommand walks the menu bar of hwnd along path (e.g. "Project", "Convert/Compile")
+// and returns the command ID of the final item. Matching ignores case, '&' mnemonics and
+// any accelerator text after a tab.
+func FindMenuCommand(hwnd uintptr, path ...string) (uint32, bool) {
+	if len(path) == 0 {
+		return 0, false
+	}
+
+	menu, _, _ := procGetMenu.Call(hwnd)
+	if menu == 0 {
+		return 0, false
+	}
+
+	for i, name := range path {
+		pos, ok := findMenuItem(menu, name)
+		if !ok {
+			return 0, false
+		}
+
+		if i == len(path)-1 {
+			id, _, _ := procGetMenuItemID.Call(menu, uintptr(pos))
+			if uint32(id) == menuItemSubmenu {
+				return 0, false
+			}
+
+			return uint32(id), true
+		}
+
+		menu, _, _ = procGetSubMenu.Call(menu, uintptr(pos))
+		if menu == 0 {
+			return 0, false
+		}
+	}
+
+	return 0, false
+}
+
+// findMenuItem returns the position of the item named name within menu
+func findMenuItem(menu uintptr, name string) (int, bool) {
+	count, _, _ := procGetMenuItemCount.Call(menu)
+
+	for pos := range int(int32(count)) {
+		if menuTextMatches(getMenuString(menu, pos), name) {
+			return pos, true
+		}
+	}
+
+	return 0, false
+}
+
+// getMenuString returns the text of the menu item at pos
+func getMenuString(menu uintptr, pos int) string {
+	buf := make([]uint16, 256)
+
+	n, _, _ := procGetMenuStringW.Call(
+		menu,
+		uintptr(pos),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)),
+		MF_BYPOSITION,
+	)
+
+	return syscall.UTF16ToString(buf[:n])
+}
+
+// menuTextMatches compares a menu item caption such as "Convert/&Compile\tF12" to a plain name
+func menuTextMatches(caption, name string) bool {
+	if i := strings.IndexByte(caption, '\t'); i >= 0 {
+		caption = caption[:i]
+	}
+
+	caption = strings.ReplaceAll(caption, "&", "")
+
+	return strings.EqualFold(strings.TrimSpace(caption), strings.TrimSpace(name))
+}
+
+// InvokeMenuItem posts the WM_COMMAND for the menu item at path on hwnd's menu bar,
+// as if the user had clicked it. It returns false if the item could not be found.
+func (w *windowManager) InvokeMenuItem(hwnd uintptr, path ...string) bool {
+	id, ok := FindMenuCommand(hwnd, path...)
+	if !ok {
+		w.log.Debug("Menu item not found", slog.String("path", strings.Join(path, " > ")))
+		return false
+	}
+
+	ret, _, err := procPostMessageW.Call(hwnd, WM_COMMAND, uintptr(id), 0)
+	if ret == 0 {
+		w.log.Debug("PostMessage WM_COMMAND failed",
+			slog.String("path", strings.Join(path, " > ")),
+			slog.Any("error", err))
+		return false
+	}
+
+	w.log.Debug("Invoked menu item",
+		slog.String("path", strings.Join(path, " > ")),
+		slog.Uint64("id", uint64(id)))
+
+	return true
+}
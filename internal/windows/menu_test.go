@@ -0,0 +1,29 @@
+//go:build windows
+
+package windows
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMenuTextMatches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		caption string
+		name    string
+		want    bool
+	}{
+		{caption: "&Project", name: "Project", want: true},
+		{caption: "Convert/&Compile\tF12", name: "Convert/Compile", want: true},
+		{caption: "&Recompile All\tAlt+F12", name: "recompile all", want: true},
+		{caption: "Convert/Compile Selected", name: "Convert/Compile", want: false},
+		{caption: "", name: "Project", want: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, menuTextMatches(tt.caption, tt.name), "caption %q vs %q", tt.caption, tt.name)
+	}
+}
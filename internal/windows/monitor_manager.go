@@ -5,96 +5,182 @@ package windows
 import (
 	"context"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/Norgate-AV/smpc/internal/logger"
+	"github.com/Norgate-AV/smpc/internal/poll"
+	"github.com/Norgate-AV/smpc/internal/windowfilter"
 )
 
 // monitorManager handles window monitoring functionality
 type monitorManager struct {
-	log logger.LoggerInterface
+	log     logger.LoggerInterface
+	events  *EventBus
+	updates *EventBus
 }
 
-// newMonitorManager creates a new monitor manager
-func newMonitorManager(log logger.LoggerInterface) *monitorManager {
-	return &monitorManager{log: log}
+// newMonitorManager creates a new monitor manager that publishes new windows to events
+// and changes to the text of open dialogs to updates
+func newMonitorManager(log logger.LoggerInterface, events, updates *EventBus) *monitorManager {
+	return &monitorManager{log: log, events: events, updates: updates}
 }
 
-// StartWindowMonitor launches a background goroutine that monitors windows
-// The goroutine will stop when the context is canceled
-func (m *monitorManager) StartWindowMonitor(ctx context.Context, pid uint32, interval time.Duration) {
-	seen := make(map[uintptr]bool)
+// MonitorOptions configures a window monitor
+type MonitorOptions struct {
+	Filter        *windowfilter.Filter // Windows to report (nil = all)
+	RescanDialogs bool                 // Re-read the text of open dialogs on every poll
+}
+
+// StartWindowMonitor launches a background goroutine that monitors windows, polling
+// at interval. The interval is reset whenever a new window appears, so polling stays
+// fast while dialogs are coming and going. Windows opts.Filter does not allow are
+// neither logged nor published. With opts.RescanDialogs, dialogs are read again on
+// every poll and changes to their text published as update events. The goroutine
+// stops when ctx is canceled, releasing the windows it has seen, and then closes the
+// returned channel.
+func (m *monitorManager) StartWindowMonitor(ctx context.Context, pid uint32, interval *poll.Interval, opts MonitorOptions) (done <-chan struct{}) {
+	stopped := make(chan struct{})
 
 	go func() {
-		m.log.Debug("Window monitor started")
+		defer close(stopped)
+
+		// Owned by this goroutine, so it is freed as soon as the monitor stops
+		seen := make(map[uintptr]bool)
+
+		// Filtered windows are checked again on every poll, as their titles can change
+		filtered := make(map[uintptr]bool)
+
+		// Open dialogs being rescanned, as last reported
+		dialogs := make(map[uintptr]WindowEvent)
+
+		m.log.Detail("Window monitor started")
+		defer m.log.Detail("Window monitor stopped")
 
 		for {
 			select {
 			case <-ctx.Done():
-				m.log.Debug("Window monitor stopped")
 				return
 			default:
 			}
 
 			windows := EnumerateWindows()
 
+			// Crash dialogs belong to Windows Error Reporting, not to the process itself
+			var reporters map[uint32]bool
+			if pid != 0 {
+				reporters = CrashReporters(pid)
+			}
+
 			for _, w := range windows {
-				if pid != 0 && w.Pid != pid {
+				if pid != 0 && w.Pid != pid && !reporters[w.Pid] {
 					continue
 				}
-				if !seen[w.Hwnd] {
-					seen[w.Hwnd] = true
-					// Log top-level window info
-					m.log.Debug("Window detected",
-						slog.Uint64("hwnd", uint64(w.Hwnd)),
-						slog.Uint64("pid", uint64(w.Pid)),
-						slog.String("class", GetClassName(w.Hwnd)),
-						slog.String("title", w.Title),
-					)
 
-					// Enumerate child controls and log their text (trace level - file only)
-					childTexts := CollectChildTexts(w.Hwnd)
-					if len(childTexts) > 0 {
-						for _, ct := range childTexts {
-							if ct != "" {
-								m.log.Trace("Child control text", slog.String("text", ct))
-							}
-						}
+				if seen[w.Hwnd] {
+					continue
+				}
+
+				if class := GetClassName(w.Hwnd); !opts.Filter.Allows(class, w.Title, w.Pid) {
+					if !filtered[w.Hwnd] {
+						filtered[w.Hwnd] = true
+						m.log.Trace("Window filtered out",
+							slog.Uint64("hwnd", uint64(w.Hwnd)),
+							slog.String("class", class),
+							slog.String("title", w.Title),
+						)
 					}
 
-					// Broadcast event (non-blocking) and store in recent cache
-					if MonitorCh != nil {
-						ev := WindowEvent{
-							Hwnd:  w.Hwnd,
-							Title: w.Title,
-							Pid:   w.Pid,
-							Class: GetClassName(w.Hwnd),
-						}
-
-						recentMu.Lock()
-						recentEvents = append(recentEvents, ev)
-
-						if len(recentEvents) > 256 {
-							recentEvents = recentEvents[len(recentEvents)-256:]
-						}
-
-						recentMu.Unlock()
-
-						select {
-						case MonitorCh <- ev:
-						default:
-							m.log.Warn("window monitor buffer full, event dropped",
-								slog.String("title", ev.Title),
-								slog.Uint64("hwnd", uint64(ev.Hwnd)),
-								slog.Uint64("pid", uint64(ev.Pid)),
-								slog.String("class", ev.Class),
-							)
-						}
+					continue
+				}
+
+				seen[w.Hwnd] = true
+				interval.Reset()
+
+				// Enumerate child controls once, for their text and the button captions
+				children := CollectChildInfos(w.Hwnd)
+
+				ev := describeWindow(w, children)
+				ev.CrashReport = reporters[w.Pid]
+
+				m.log.Detail("Window detected", ev.LogAttrs()...)
+
+				// The main window is not a dialog, so its many controls are never rescanned
+				if opts.RescanDialogs && ev.Kind() != KindWindow {
+					watched := ev
+					watched.Texts = childTexts(children)
+					dialogs[w.Hwnd] = watched
+				}
+
+				// Log child control text (trace level - console only at -vvv)
+				for _, ci := range children {
+					if ci.Text != "" {
+						m.log.Trace("Child control text", slog.String("text", ci.Text))
 					}
 				}
+
+				// Broadcast event to all subscribers (non-blocking)
+				if dropped := m.events.Publish(ev); dropped > 0 {
+					m.log.Warn("window event subscriber buffer full, event dropped",
+						slog.String("title", ev.Title),
+						slog.Uint64("hwnd", uint64(ev.Hwnd)),
+						slog.Uint64("pid", uint64(ev.Pid)),
+						slog.String("class", ev.Class),
+						slog.Int("subscribers", dropped),
+					)
+				}
 			}
 
-			time.Sleep(interval)
+			if len(dialogs) > 0 {
+				m.rescanDialogs(windows, dialogs)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval.Next()):
+			}
 		}
 	}()
+
+	return stopped
+}
+
+// rescanDialogs reads the title and child text of each dialog again, publishing an
+// update event for those that changed. Dialogs no longer among open are forgotten.
+func (m *monitorManager) rescanDialogs(open []WindowInfo, dialogs map[uintptr]WindowEvent) {
+	present := make(map[uintptr]bool, len(open))
+	for _, w := range open {
+		present[w.Hwnd] = true
+	}
+
+	for hwnd, last := range dialogs {
+		if !present[hwnd] {
+			delete(dialogs, hwnd)
+			continue
+		}
+
+		children := CollectChildInfos(hwnd)
+
+		ev := last
+		ev.Title = GetWindowText(hwnd)
+		ev.Texts = childTexts(children)
+		ev.Buttons = buttonCaptions(children)
+
+		if ev.Title == last.Title && slices.Equal(ev.Texts, last.Texts) {
+			continue
+		}
+
+		dialogs[hwnd] = ev
+
+		m.log.Trace("Dialog updated", slog.Uint64("hwnd", uint64(hwnd)), slog.String("title", ev.Title), slog.Any("texts", ev.Texts))
+
+		if dropped := m.updates.Publish(ev); dropped > 0 {
+			m.log.Debug("window update subscriber buffer full, update dropped",
+				slog.String("title", ev.Title),
+				slog.Uint64("hwnd", uint64(ev.Hwnd)),
+				slog.Int("subscribers", dropped),
+			)
+		}
+	}
 }
@@ -7,46 +7,91 @@ import (
 	"syscall"
 )
 
-var (
-	foundWindows []WindowInfo
-	windowsMu    sync.Mutex
-)
+// enumVisitors holds the visitor of each window enumeration in progress, keyed by
+// the id passed to the shared callback as its lparam
+type enumVisitors struct {
+	mu     sync.Mutex
+	nextID uintptr
+	byID   map[uintptr]func(hwnd uintptr)
+}
+
+// add registers visit and returns the id to pass as lparam
+func (v *enumVisitors) add(visit func(hwnd uintptr)) uintptr {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.byID == nil {
+		v.byID = make(map[uintptr]func(hwnd uintptr))
+	}
+
+	v.nextID++
+	v.byID[v.nextID] = visit
+
+	return v.nextID
+}
+
+func (v *enumVisitors) get(id uintptr) func(hwnd uintptr) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
 
-// Channel to broadcast window events from the monitor
-var MonitorCh chan WindowEvent
+	return v.byID[id]
+}
+
+func (v *enumVisitors) remove(id uintptr) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	delete(v.byID, id)
+}
 
 var (
-	recentEvents []WindowEvent
-	recentMu     sync.Mutex
+	visitors enumVisitors
+
+	// enumCallback is shared by every EnumWindows and EnumChildWindows call. Callbacks
+	// made by syscall.NewCallback are never freed and limited in number, so one must
+	// not be created per enumeration.
+	enumCallback = syscall.NewCallback(func(hwnd uintptr, lparam uintptr) uintptr {
+		if visit := visitors.get(lparam); visit != nil {
+			visit(hwnd)
+		}
+
+		return 1 // Continue enumeration
+	})
 )
 
-func enumWindowsCallback(hwnd uintptr, lparam uintptr) uintptr {
-	if IsWindowVisible(hwnd) {
-		title := GetWindowText(hwnd)
-		pid := GetWindowPid(hwnd)
+// enumWindows calls visit for each top-level window, reporting whether EnumWindows
+// succeeded. Enumerations may run concurrently.
+func enumWindows(visit func(hwnd uintptr)) bool {
+	id := visitors.add(visit)
+	defer visitors.remove(id)
 
-		// Include even if title is empty; we may match by child text later
-		foundWindows = append(foundWindows, WindowInfo{Hwnd: hwnd, Title: title, Pid: pid})
-	}
+	ret, _, _ := procEnumWindows.Call(enumCallback, id)
+	return ret != 0
+}
 
-	return 1 // Continue enumeration
+// enumChildWindows calls visit for each descendant of parent
+func enumChildWindows(parent uintptr, visit func(hwnd uintptr)) {
+	id := visitors.add(visit)
+	defer visitors.remove(id)
+
+	// EnumChildWindows: the return value is not used and has no error information
+	_, _, _ = procEnumChildWindows.Call(parent, enumCallback, id)
 }
 
-// EnumerateWindows performs a thread-safe enumeration of visible top-level windows
+// EnumerateWindows returns the visible top-level windows. It is safe to call from
+// several goroutines at once.
 func EnumerateWindows() []WindowInfo {
-	windowsMu.Lock()
-	defer windowsMu.Unlock()
+	var found []WindowInfo
 
-	foundWindows = nil
-	callback := syscall.NewCallback(enumWindowsCallback)
-	ret, _, _ := procEnumWindows.Call(callback, 0)
-	if ret == 0 {
+	ok := enumWindows(func(hwnd uintptr) {
+		if IsWindowVisible(hwnd) {
+			// Include even if title is empty; we may match by child text later
+			found = append(found, WindowInfo{Hwnd: hwnd, Title: GetWindowText(hwnd), Pid: GetWindowPid(hwnd)})
+		}
+	})
+	if !ok {
 		return nil
 	}
 
-	// Make a copy to avoid races with subsequent enumerations
-	windows := make([]WindowInfo, len(foundWindows))
-	copy(windows, foundWindows)
-
-	return windows
+	return found
 }
@@ -0,0 +1,50 @@
+//go:build windows
+
+package windows
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnumVisitors(t *testing.T) {
+	t.Parallel()
+
+	var v enumVisitors
+
+	var got []uintptr
+	a := v.add(func(hwnd uintptr) { got = append(got, hwnd) })
+	b := v.add(func(hwnd uintptr) { got = append(got, hwnd+100) })
+	assert.NotEqual(t, a, b)
+
+	v.get(a)(1)
+	v.get(b)(2)
+	assert.Equal(t, []uintptr{1, 102}, got)
+
+	v.remove(a)
+	assert.Nil(t, v.get(a))
+	assert.NotNil(t, v.get(b))
+}
+
+func TestEnumerateWindows_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	// More enumerations than syscall.NewCallback allows callbacks, from several goroutines
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range 300 {
+				EnumerateWindows()
+			}
+		}()
+	}
+
+	wg.Wait()
+}
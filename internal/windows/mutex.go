@@ -0,0 +1,140 @@
+//go:build windows
+
+package windows
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	procCreateMutexW = kernel32.NewProc("CreateMutexW")
+	procReleaseMutex = kernel32.NewProc("ReleaseMutex")
+	procLocalFree    = kernel32.NewProc("LocalFree")
+
+	procConvertStringSecurityDescriptorToSecurityDescriptorW = advapi32.NewProc("ConvertStringSecurityDescriptorToSecurityDescriptorW")
+)
+
+const (
+	WAIT_ABANDONED = 0x00000080
+
+	SDDL_REVISION_1 = 1
+
+	// mutexSDDL lets every user wait on the mutex, whatever their integrity level,
+	// so an unelevated instance is not locked out by an elevated one
+	mutexSDDL = "D:(A;;GA;;;WD)S:(ML;;NW;;;LW)"
+)
+
+// ErrMutexTimeout is returned by AcquireMutex when another process kept the mutex
+var ErrMutexTimeout = errors.New("mutex is held by another process")
+
+// securityAttributes is SECURITY_ATTRIBUTES
+type securityAttributes struct {
+	Length             uint32
+	SecurityDescriptor uintptr
+	InheritHandle      int32
+}
+
+// MutexLock is ownership of a named mutex
+type MutexLock struct {
+	Abandoned bool // Whether the previous owner exited without releasing it
+
+	release chan struct{}
+	done    chan struct{}
+}
+
+// AcquireMutex waits up to timeout to own the named mutex, creating it if needed.
+// Names starting Global\ are shared by every session. A mutex is owned by a thread,
+// so it is held by a goroutine locked to its own thread until Release is called.
+func AcquireMutex(name string, timeout time.Duration) (*MutexLock, error) {
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &MutexLock{release: make(chan struct{}), done: make(chan struct{})}
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(lock.done)
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		handle, err := createMutex(namePtr)
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		defer ProcCloseHandle.Call(handle)
+
+		ret, _, callErr := procWaitForSingleObject.Call(handle, uintptr(timeout/time.Millisecond))
+
+		switch uint32(ret) {
+		case WAIT_OBJECT_0:
+		case WAIT_ABANDONED:
+			lock.Abandoned = true
+		case WAIT_TIMEOUT:
+			errs <- ErrMutexTimeout
+			return
+		default:
+			errs <- fmt.Errorf("failed waiting for mutex %s: %w", name, callErr)
+			return
+		}
+
+		errs <- nil
+
+		<-lock.release
+		_, _, _ = procReleaseMutex.Call(handle)
+	}()
+
+	if err := <-errs; err != nil {
+		<-lock.done
+		return nil, err
+	}
+
+	return lock, nil
+}
+
+// Release gives up ownership of the mutex
+func (l *MutexLock) Release() {
+	close(l.release)
+	<-l.done
+}
+
+// createMutex opens the named mutex, creating it, unowned, if it does not exist
+func createMutex(name *uint16) (uintptr, error) {
+	sddl, err := syscall.UTF16PtrFromString(mutexSDDL)
+	if err != nil {
+		return 0, err
+	}
+
+	var descriptor uintptr
+
+	ret, _, callErr := procConvertStringSecurityDescriptorToSecurityDescriptorW.Call(
+		uintptr(unsafe.Pointer(sddl)),
+		SDDL_REVISION_1,
+		uintptr(unsafe.Pointer(&descriptor)),
+		0,
+	)
+	if ret == 0 {
+		return 0, fmt.Errorf("failed to build mutex security descriptor: %w", callErr)
+	}
+
+	defer procLocalFree.Call(descriptor)
+
+	sa := securityAttributes{SecurityDescriptor: descriptor}
+	sa.Length = uint32(unsafe.Sizeof(sa))
+
+	handle, _, callErr := procCreateMutexW.Call(uintptr(unsafe.Pointer(&sa)), 0, uintptr(unsafe.Pointer(name)))
+	if handle == 0 {
+		return 0, fmt.Errorf("failed to open mutex: %w", callErr)
+	}
+
+	return handle, nil
+}
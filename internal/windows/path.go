@@ -0,0 +1,109 @@
+//go:build windows
+
+package windows
+
+import (
+	"strings"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+)
+
+var (
+	procGetShortPathNameW = kernel32.NewProc("GetShortPathNameW")
+	procGetDriveTypeW     = kernel32.NewProc("GetDriveTypeW")
+)
+
+const (
+	DRIVE_REMOTE = 4
+
+	extendedPrefix    = `\\?\`
+	extendedUNCPrefix = `\\?\UNC\`
+)
+
+// ExtendedLengthPath returns p with the \\?\ prefix needed to pass paths longer than
+// MAX_PATH to Win32 file APIs. Relative and already-prefixed paths are returned unchanged.
+func ExtendedLengthPath(p string) string {
+	switch {
+	case strings.HasPrefix(p, extendedPrefix):
+		return p
+	case strings.HasPrefix(p, `\\`):
+		return extendedUNCPrefix + strings.TrimPrefix(p, `\\`)
+	case len(p) >= 3 && p[1] == ':' && (p[2] == '\\' || p[2] == '/'):
+		return extendedPrefix + strings.ReplaceAll(p, "/", `\`)
+	default:
+		return p
+	}
+}
+
+// StripExtendedLengthPrefix reverses ExtendedLengthPath
+func StripExtendedLengthPrefix(p string) string {
+	switch {
+	case strings.HasPrefix(p, extendedUNCPrefix):
+		return `\\` + strings.TrimPrefix(p, extendedUNCPrefix)
+	case strings.HasPrefix(p, extendedPrefix):
+		return strings.TrimPrefix(p, extendedPrefix)
+	default:
+		return p
+	}
+}
+
+// ShortPathName returns the 8.3 form of an existing path, which may be needed to hand
+// long paths to applications that do not understand \\?\ prefixes
+func ShortPathName(p string) (string, error) {
+	long, err := syscall.UTF16PtrFromString(ExtendedLengthPath(p))
+	if err != nil {
+		return "", err
+	}
+
+	n, _, err := procGetShortPathNameW.Call(uintptr(unsafe.Pointer(long)), 0, 0)
+	if n == 0 {
+		return "", err
+	}
+
+	buf := make([]uint16, n)
+
+	n, _, err = procGetShortPathNameW.Call(uintptr(unsafe.Pointer(long)), uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if n == 0 || int(n) > len(buf) {
+		return "", err
+	}
+
+	return StripExtendedLengthPrefix(syscall.UTF16ToString(buf[:n])), nil
+}
+
+// LaunchPath returns a form of p that legacy applications such as SIMPL Windows can open.
+// Paths within MAX_PATH are returned unchanged; longer ones are shortened to their 8.3
+// form when the volume supports it. The second result reports whether p was changed.
+func LaunchPath(p string) (string, bool) {
+	// MAX_PATH includes the terminating NUL
+	if len(utf16.Encode([]rune(p))) < MAX_PATH {
+		return p, false
+	}
+
+	short, err := ShortPathName(p)
+	if err != nil || short == "" {
+		return p, false
+	}
+
+	return short, short != p
+}
+
+// IsNetworkPath reports whether p is a UNC path or lives on a mapped network drive
+func IsNetworkPath(p string) bool {
+	p = StripExtendedLengthPrefix(p)
+	if strings.HasPrefix(p, `\\`) {
+		return true
+	}
+
+	if len(p) < 2 || p[1] != ':' {
+		return false
+	}
+
+	root, err := syscall.UTF16PtrFromString(p[:2] + `\`)
+	if err != nil {
+		return false
+	}
+
+	driveType, _, _ := procGetDriveTypeW.Call(uintptr(unsafe.Pointer(root)))
+	return driveType == DRIVE_REMOTE
+}
@@ -0,0 +1,74 @@
+//go:build windows
+
+package windows
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtendedLengthPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: `C:\Projects\Büro\プログラム.smw`, want: `\\?\C:\Projects\Büro\プログラム.smw`},
+		{in: `C:/Projects/x.smw`, want: `\\?\C:\Projects\x.smw`},
+		{in: `\\server\share\x.smw`, want: `\\?\UNC\server\share\x.smw`},
+		{in: `\\?\C:\already.smw`, want: `\\?\C:\already.smw`},
+		{in: `relative\x.smw`, want: `relative\x.smw`},
+	}
+
+	for _, tt := range tests {
+		got := ExtendedLengthPath(tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+
+		if strings.HasPrefix(tt.in, `\\?\`) || !strings.HasPrefix(got, `\\?\`) {
+			continue
+		}
+
+		assert.Equal(t, filepath.Clean(tt.in), StripExtendedLengthPrefix(got), "round trip of %s", tt.in)
+	}
+}
+
+func TestLaunchPath_ShortPathUnchanged(t *testing.T) {
+	t.Parallel()
+
+	p := filepath.Join(t.TempDir(), "Ünïcødé 日本語.smw")
+	require.NoError(t, os.WriteFile(p, []byte("test"), 0o644))
+
+	got, changed := LaunchPath(p)
+	assert.False(t, changed)
+	assert.Equal(t, p, got)
+}
+
+func TestLaunchPath_LongPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for len(dir) < MAX_PATH {
+		dir = filepath.Join(dir, "a-very-long-directory-name-ñ")
+	}
+
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+
+	p := filepath.Join(dir, "program.smw")
+	require.NoError(t, os.WriteFile(p, []byte("test"), 0o644))
+
+	got, changed := LaunchPath(p)
+	if !changed {
+		t.Skip("8.3 names are disabled on this volume")
+	}
+
+	assert.Less(t, len(got), MAX_PATH)
+
+	_, err := os.Stat(got)
+	assert.NoError(t, err, "short path must refer to the same file")
+}
@@ -0,0 +1,146 @@
+//go:build windows
+
+package windows
+
+import (
+	"fmt"
+	"log/slog"
+	"unsafe"
+)
+
+var (
+	procGetWindowRect    = user32.NewProc("GetWindowRect")
+	procSetWindowPos     = user32.NewProc("SetWindowPos")
+	procGetSystemMetrics = user32.NewProc("GetSystemMetrics")
+	procMonitorFromPoint = user32.NewProc("MonitorFromPoint")
+	procGetMonitorInfoW  = user32.NewProc("GetMonitorInfoW")
+)
+
+const (
+	SM_XVIRTUALSCREEN = 76
+	SM_YVIRTUALSCREEN = 77
+
+	SWP_NOSIZE     = 0x0001
+	SWP_NOZORDER   = 0x0004
+	SWP_NOACTIVATE = 0x0010
+
+	MONITOR_DEFAULTTOPRIMARY = 1
+)
+
+// Rect mirrors RECT: a window rectangle in screen coordinates
+type Rect struct {
+	Left, Top, Right, Bottom int32
+}
+
+func (r Rect) String() string {
+	return fmt.Sprintf("(%d,%d)-(%d,%d)", r.Left, r.Top, r.Right, r.Bottom)
+}
+
+// monitorInfo mirrors MONITORINFO
+type monitorInfo struct {
+	Size    uint32
+	Monitor Rect
+	Work    Rect
+	Flags   uint32
+}
+
+// ShowWindow sets how hwnd is shown (one of the SW_ constants) without reporting
+// whether it was visible before
+func ShowWindow(hwnd uintptr, showCmd int) {
+	_, _, _ = procShowWindow.Call(hwnd, uintptr(showCmd))
+}
+
+// MoveOffScreen moves hwnd just beyond the top-left corner of the virtual screen, so
+// it stays open and visible to the window monitor but cannot be seen on any display
+func MoveOffScreen(hwnd uintptr) error {
+	var r Rect
+
+	if ret, _, err := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&r))); ret == 0 {
+		return fmt.Errorf("GetWindowRect failed: %w", err)
+	}
+
+	// GetSystemMetrics returns a signed int; the virtual screen can start left of or above 0
+	left, _, _ := procGetSystemMetrics.Call(SM_XVIRTUALSCREEN)
+	top, _, _ := procGetSystemMetrics.Call(SM_YVIRTUALSCREEN)
+
+	x := int32(left) - (r.Right - r.Left)
+	y := int32(top) - (r.Bottom - r.Top)
+
+	ret, _, err := procSetWindowPos.Call(hwnd, 0, uintptr(x), uintptr(y), 0, 0, SWP_NOSIZE|SWP_NOZORDER|SWP_NOACTIVATE)
+	if ret == 0 {
+		return fmt.Errorf("SetWindowPos failed: %w", err)
+	}
+
+	return nil
+}
+
+// primaryWorkArea returns the work area of the primary monitor, which excludes the taskbar
+func primaryWorkArea() (Rect, error) {
+	// The primary monitor is the one with the origin at its top-left corner
+	monitor, _, _ := procMonitorFromPoint.Call(0, MONITOR_DEFAULTTOPRIMARY)
+
+	mi := monitorInfo{}
+	mi.Size = uint32(unsafe.Sizeof(mi))
+
+	if ret, _, err := procGetMonitorInfoW.Call(monitor, uintptr(unsafe.Pointer(&mi))); ret == 0 {
+		return Rect{}, fmt.Errorf("GetMonitorInfo failed: %w", err)
+	}
+
+	return mi.Work, nil
+}
+
+// onPrimary returns where a window at r goes so that it lies on the primary monitor's
+// work area: unchanged if it already does, otherwise centred on it. A window larger
+// than the work area keeps its top-left corner on it.
+func onPrimary(r, work Rect) (x, y int32, move bool) {
+	if r.Left >= work.Left && r.Top >= work.Top && r.Right <= work.Right && r.Bottom <= work.Bottom {
+		return r.Left, r.Top, false
+	}
+
+	width, height := r.Right-r.Left, r.Bottom-r.Top
+
+	x = max(work.Left+(work.Right-work.Left-width)/2, work.Left)
+	y = max(work.Top+(work.Bottom-work.Top-height)/2, work.Top)
+
+	return x, y, true
+}
+
+// moveToPrimaryMonitor moves hwnd onto the primary monitor unless it is already wholly
+// on it. Dialogs can otherwise open on a detached or virtual display, where keystrokes
+// and clicks sent to them go astray.
+func (w *windowManager) moveToPrimaryMonitor(hwnd uintptr) {
+	var r Rect
+
+	if ret, _, err := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&r))); ret == 0 {
+		w.log.Debug("GetWindowRect failed", slog.Uint64("hwnd", uint64(hwnd)), slog.Any("error", err))
+		return
+	}
+
+	work, err := primaryWorkArea()
+	if err != nil {
+		w.log.Debug("Could not read the primary monitor's work area", slog.Any("error", err))
+		return
+	}
+
+	x, y, move := onPrimary(r, work)
+	if !move {
+		w.log.Trace("Window is on the primary monitor", slog.Uint64("hwnd", uint64(hwnd)), slog.String("rect", r.String()))
+		return
+	}
+
+	ret, _, callErr := procSetWindowPos.Call(hwnd, 0, uintptr(x), uintptr(y), 0, 0, SWP_NOSIZE|SWP_NOZORDER|SWP_NOACTIVATE)
+	if ret == 0 {
+		w.log.Warn("Failed to move window to the primary monitor",
+			slog.Uint64("hwnd", uint64(hwnd)),
+			slog.String("rect", r.String()),
+			slog.Any("error", callErr),
+		)
+		return
+	}
+
+	w.log.Info("Moved window to the primary monitor",
+		slog.Uint64("hwnd", uint64(hwnd)),
+		slog.String("from", r.String()),
+		slog.String("to", Rect{x, y, x + r.Right - r.Left, y + r.Bottom - r.Top}.String()),
+	)
+}
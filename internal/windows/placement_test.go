@@ -0,0 +1,39 @@
+//go:build windows
+
+package windows
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnPrimary(t *testing.T) {
+	t.Parallel()
+
+	work := Rect{0, 0, 1920, 1040}
+
+	tests := []struct {
+		name         string
+		r            Rect
+		wantX, wantY int32
+		wantMove     bool
+	}{
+		{"already on it", Rect{100, 100, 500, 400}, 100, 100, false},
+		{"on a monitor to the right", Rect{2000, 100, 2400, 400}, 760, 370, true},
+		{"on a monitor to the left", Rect{-1500, -200, -1100, 100}, 760, 370, true},
+		{"straddling the edge", Rect{1800, 100, 2200, 400}, 760, 370, true},
+		{"larger than the work area", Rect{-10, -10, 2500, 1500}, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			x, y, move := onPrimary(tt.r, work)
+			assert.Equal(t, tt.wantMove, move)
+			assert.Equal(t, tt.wantX, x)
+			assert.Equal(t, tt.wantY, y)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+//go:build windows
+
+package windows
+
+import (
+	"fmt"
+	"runtime"
+)
+
+var procSetThreadExecutionState = kernel32.NewProc("SetThreadExecutionState")
+
+const (
+	ES_SYSTEM_REQUIRED = 0x00000001
+	ES_CONTINUOUS      = 0x80000000
+)
+
+// PreventSleep stops the system sleeping until the returned restore function is
+// called. The display may still turn off. The request belongs to the thread that
+// makes it, so it is held by a goroutine locked to its own thread.
+func PreventSleep() (restore func(), err error) {
+	errs := make(chan error, 1)
+	release := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		ret, _, callErr := procSetThreadExecutionState.Call(ES_CONTINUOUS | ES_SYSTEM_REQUIRED)
+		if ret == 0 {
+			errs <- fmt.Errorf("SetThreadExecutionState failed: %w", callErr)
+			return
+		}
+
+		errs <- nil
+
+		<-release
+		_, _, _ = procSetThreadExecutionState.Call(ES_CONTINUOUS)
+	}()
+
+	if err := <-errs; err != nil {
+		<-done
+		return nil, err
+	}
+
+	return func() {
+		close(release)
+		<-done
+	}, nil
+}
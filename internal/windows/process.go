@@ -0,0 +1,109 @@
+//go:build windows
+
+package windows
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+var procWaitForInputIdle = user32.NewProc("WaitForInputIdle")
+
+const INFINITE = 0xFFFFFFFF
+
+// Process is an open handle to a started process
+type Process struct {
+	handle uintptr
+	Pid    uint32
+}
+
+// Wait blocks until the process exits and returns its exit code
+func (p *Process) Wait() (int, error) {
+	ret, _, err := procWaitForSingleObject.Call(p.handle, INFINITE)
+	if ret != WAIT_OBJECT_0 {
+		return 0, fmt.Errorf("failed waiting for process %d: %w", p.Pid, err)
+	}
+
+	return p.exitCode()
+}
+
+// WaitForExit waits up to timeout for the process to exit. It returns the exit code
+// and true once the process has exited, or false if it is still running.
+func (p *Process) WaitForExit(timeout time.Duration) (int, bool, error) {
+	ret, _, err := procWaitForSingleObject.Call(p.handle, uintptr(timeout/time.Millisecond))
+
+	switch uint32(ret) {
+	case WAIT_OBJECT_0:
+		code, err := p.exitCode()
+		return code, err == nil, err
+	case WAIT_TIMEOUT:
+		return 0, false, nil
+	default:
+		return 0, false, fmt.Errorf("failed waiting for process %d: %w", p.Pid, err)
+	}
+}
+
+// Watch reports the exit code on the returned channel if the process exits before
+// ctx is canceled or stop is called, checking every interval. stop waits for the
+// watch to end, so the process can be closed safely afterwards.
+func (p *Process) Watch(ctx context.Context, interval time.Duration) (exited <-chan int, stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	codes := make(chan int, 1)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for ctx.Err() == nil {
+			code, ok, err := p.WaitForExit(interval)
+			if err != nil {
+				return
+			}
+
+			if ok {
+				codes <- code
+				return
+			}
+		}
+	}()
+
+	return codes, func() {
+		cancel()
+		<-done
+	}
+}
+
+// exitCode returns the exit code of the exited process
+func (p *Process) exitCode() (int, error) {
+	var code uint32
+
+	ret, _, err := procGetExitCodeProcess.Call(p.handle, uintptr(unsafe.Pointer(&code)))
+	if ret == 0 {
+		return 0, fmt.Errorf("failed to get exit code of process %d: %w", p.Pid, err)
+	}
+
+	return int(code), nil
+}
+
+// WaitForInputIdle blocks until the process has finished its initial startup and is
+// waiting for user input with no input pending, or until timeout elapses
+func (p *Process) WaitForInputIdle(timeout time.Duration) error {
+	ret, _, err := procWaitForInputIdle.Call(p.handle, uintptr(timeout/time.Millisecond))
+
+	switch uint32(ret) {
+	case WAIT_OBJECT_0:
+		return nil
+	case WAIT_TIMEOUT:
+		return fmt.Errorf("process %d was not idle within %s", p.Pid, timeout)
+	default:
+		// WAIT_FAILED, e.g. for a process without a message queue
+		return fmt.Errorf("WaitForInputIdle failed for process %d: %w", p.Pid, err)
+	}
+}
+
+// Close releases the process handle
+func (p *Process) Close() {
+	ProcCloseHandle.Call(p.handle)
+}
@@ -0,0 +1,351 @@
+//go:build windows
+
+package windows
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	procGetProcessTimes      = kernel32.NewProc("GetProcessTimes")
+	procProcessIdToSessionId = kernel32.NewProc("ProcessIdToSessionId")
+	procGetCurrentProcessId  = kernel32.NewProc("GetCurrentProcessId")
+
+	procQueryFullProcessImageNameW = kernel32.NewProc("QueryFullProcessImageNameW")
+)
+
+const (
+	PROCESS_TERMINATE                 = 0x0001
+	PROCESS_QUERY_LIMITED_INFORMATION = 0x1000
+
+	// STILL_ACTIVE is the exit code reported for a process that has not exited
+	STILL_ACTIVE = 259
+
+	// UnknownSession is ProcessEntry.Session when the session could not be queried
+	UnknownSession = 0xFFFFFFFF
+
+	invalidHandleValue = ^uintptr(0)
+)
+
+// ErrProcessReused is returned when a PID no longer refers to the process it was recorded for
+var ErrProcessReused = errors.New("pid has been reused by another process")
+
+// ProcessEntry describes a running process from a Toolhelp snapshot
+type ProcessEntry struct {
+	Pid       uint32
+	ParentPid uint32
+	ExeFile   string
+	ExePath   string    // Full path of the executable; empty if the process could not be queried
+	Created   time.Time // Zero if the process could not be queried
+	Session   uint32    // Logon session (each Remote Desktop user has their own), or UnknownSession
+}
+
+// SnapshotProcesses lists all running processes
+func SnapshotProcesses() ([]ProcessEntry, error) {
+	var processes []ProcessEntry
+
+	err := walkProcesses(func(entry *PROCESSENTRY32) {
+		processes = append(processes, newProcessEntry(entry))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return processes, nil
+}
+
+// FindProcesses lists the running processes for which match returns true
+func FindProcesses(match func(ProcessEntry) bool) ([]ProcessEntry, error) {
+	processes, err := SnapshotProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []ProcessEntry
+	for _, p := range processes {
+		if match(p) {
+			matched = append(matched, p)
+		}
+	}
+
+	return matched, nil
+}
+
+// LookupProcess returns the running process with the given PID
+func LookupProcess(pid uint32) (ProcessEntry, error) {
+	var (
+		found ProcessEntry
+		ok    bool
+	)
+
+	err := walkProcesses(func(entry *PROCESSENTRY32) {
+		if !ok && entry.Th32ProcessID == pid {
+			found, ok = newProcessEntry(entry), true
+		}
+	})
+	if err != nil {
+		return ProcessEntry{}, err
+	}
+
+	if !ok {
+		return ProcessEntry{}, fmt.Errorf("process %d is not running", pid)
+	}
+
+	return found, nil
+}
+
+// newProcessEntry describes the process of a snapshot entry, querying what the
+// snapshot does not include
+func newProcessEntry(entry *PROCESSENTRY32) ProcessEntry {
+	p := ProcessEntry{
+		Pid:       entry.Th32ProcessID,
+		ParentPid: entry.Th32ParentProcessID,
+		ExeFile:   syscall.UTF16ToString(entry.SzExeFile[:]),
+		Session:   ProcessSession(entry.Th32ProcessID),
+	}
+
+	hProcess, _, _ := procOpenProcess.Call(PROCESS_QUERY_LIMITED_INFORMATION, 0, uintptr(p.Pid))
+	if hProcess == 0 {
+		return p
+	}
+
+	defer ProcCloseHandle.Call(hProcess)
+
+	p.Created = handleCreationTime(hProcess)
+	p.ExePath = handleImagePath(hProcess)
+
+	return p
+}
+
+// CrashReporters returns the Windows Error Reporting processes (WerFault.exe) started
+// by pid to report its crash. It is cheap enough to call on every monitor poll.
+func CrashReporters(pid uint32) map[uint32]bool {
+	reporters := make(map[uint32]bool)
+
+	_ = walkProcesses(func(entry *PROCESSENTRY32) {
+		if entry.Th32ParentProcessID == pid && strings.EqualFold(syscall.UTF16ToString(entry.SzExeFile[:]), "WerFault.exe") {
+			reporters[entry.Th32ProcessID] = true
+		}
+	})
+
+	return reporters
+}
+
+// walkProcesses calls fn with each entry of a Toolhelp process snapshot
+func walkProcesses(fn func(entry *PROCESSENTRY32)) error {
+	snap, _, err := ProcCreateToolhelp32Snapshot.Call(TH32CS_SNAPPROCESS, 0)
+	if snap == invalidHandleValue {
+		return fmt.Errorf("failed to snapshot processes: %w", err)
+	}
+
+	defer ProcCloseHandle.Call(snap)
+
+	var entry PROCESSENTRY32
+	entry.DwSize = uint32(unsafe.Sizeof(entry))
+
+	ret, _, _ := ProcProcess32First.Call(snap, uintptr(unsafe.Pointer(&entry)))
+	for ret != 0 {
+		fn(&entry)
+		ret, _, _ = ProcProcess32Next.Call(snap, uintptr(unsafe.Pointer(&entry)))
+	}
+
+	return nil
+}
+
+// ProcessSession returns the logon session pid runs in, or UnknownSession
+func ProcessSession(pid uint32) uint32 {
+	var session uint32
+
+	ret, _, _ := procProcessIdToSessionId.Call(uintptr(pid), uintptr(unsafe.Pointer(&session)))
+	if ret == 0 {
+		return UnknownSession
+	}
+
+	return session
+}
+
+// CurrentSession returns the logon session smpc is running in
+func CurrentSession() uint32 {
+	pid, _, _ := procGetCurrentProcessId.Call()
+	return ProcessSession(uint32(pid))
+}
+
+// handleCreationTime returns when the process behind hProcess started, or the zero
+// time if it cannot be queried
+func handleCreationTime(hProcess uintptr) time.Time {
+	var created, exited, kernel, user syscall.Filetime
+
+	ret, _, _ := procGetProcessTimes.Call(
+		hProcess,
+		uintptr(unsafe.Pointer(&created)),
+		uintptr(unsafe.Pointer(&exited)),
+		uintptr(unsafe.Pointer(&kernel)),
+		uintptr(unsafe.Pointer(&user)),
+	)
+	if ret == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, created.Nanoseconds())
+}
+
+// handleImagePath returns the executable path of the process behind hProcess, or ""
+func handleImagePath(hProcess uintptr) string {
+	buf := make([]uint16, syscall.MAX_LONG_PATH)
+	size := uint32(len(buf))
+
+	ret, _, _ := procQueryFullProcessImageNameW.Call(hProcess, 0, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if ret == 0 {
+		return ""
+	}
+
+	return syscall.UTF16ToString(buf[:size])
+}
+
+// IsRunning reports whether p has not exited, and its PID has not been reused by
+// another process since. A process that cannot be opened for lack of access is
+// assumed to be running.
+func IsRunning(p ProcessEntry) bool {
+	hProcess, _, err := procOpenProcess.Call(PROCESS_QUERY_LIMITED_INFORMATION, 0, uintptr(p.Pid))
+	if hProcess == 0 {
+		return errors.Is(err, syscall.ERROR_ACCESS_DENIED)
+	}
+
+	defer ProcCloseHandle.Call(hProcess)
+
+	if !sameStart(p.Created, handleCreationTime(hProcess)) {
+		return false
+	}
+
+	var code uint32
+
+	ret, _, _ := procGetExitCodeProcess.Call(hProcess, uintptr(unsafe.Pointer(&code)))
+	return ret != 0 && code == STILL_ACTIVE
+}
+
+// sameStart reports whether a process that started at current can be the one recorded
+// as starting at recorded. A zero time means unknown and is never taken as reuse.
+func sameStart(recorded, current time.Time) bool {
+	return recorded.IsZero() || current.IsZero() || recorded.Equal(current)
+}
+
+// Descendants returns every process below root in the parent-PID tree, deepest first.
+// Parent PIDs are not updated when a parent exits, so a process created before its
+// supposed parent is a stale link to a reused PID and is not followed.
+func Descendants(processes []ProcessEntry, root uint32) []ProcessEntry {
+	children := make(map[uint32][]ProcessEntry)
+	created := make(map[uint32]time.Time)
+
+	for _, p := range processes {
+		created[p.Pid] = p.Created
+
+		// PID 0 is the idle process, which is its own parent
+		if p.Pid != p.ParentPid {
+			children[p.ParentPid] = append(children[p.ParentPid], p)
+		}
+	}
+
+	var (
+		result  []ProcessEntry
+		visited = map[uint32]bool{root: true}
+		walk    func(pid uint32)
+	)
+
+	walk = func(pid uint32) {
+		for _, child := range children[pid] {
+			if visited[child.Pid] {
+				continue
+			}
+
+			parentCreated := created[pid]
+			if !child.Created.IsZero() && !parentCreated.IsZero() && child.Created.Before(parentCreated) {
+				continue
+			}
+
+			visited[child.Pid] = true
+			walk(child.Pid)
+			result = append(result, child)
+		}
+	}
+
+	walk(root)
+	return result
+}
+
+// TerminateEntry terminates p, first checking on the same handle that its PID still
+// refers to a process started at p.Created, so a reused PID is never terminated
+func TerminateEntry(p ProcessEntry) error {
+	hProcess, _, err := procOpenProcess.Call(PROCESS_TERMINATE|PROCESS_QUERY_LIMITED_INFORMATION, 0, uintptr(p.Pid))
+	if hProcess == 0 {
+		return fmt.Errorf("failed to open process: %w", err)
+	}
+
+	defer ProcCloseHandle.Call(hProcess)
+
+	if !sameStart(p.Created, handleCreationTime(hProcess)) {
+		return ErrProcessReused
+	}
+
+	ret, _, err := procTerminateProcess.Call(hProcess, uintptr(1))
+	if ret == 0 {
+		return fmt.Errorf("failed to terminate process: %w", err)
+	}
+
+	return nil
+}
+
+// TerminateProcessTree terminates pid and all of its descendants, children first.
+// It returns the processes that were terminated; failures are joined into the error.
+func TerminateProcessTree(pid uint32) ([]ProcessEntry, error) {
+	return TerminateTree(ProcessEntry{Pid: pid})
+}
+
+// TerminateTree terminates root and all of its descendants, children first. If root's
+// PID now belongs to a process started at another time, nothing is terminated and the
+// error wraps ErrProcessReused. It returns the processes that were terminated;
+// failures are joined into the error.
+func TerminateTree(root ProcessEntry) ([]ProcessEntry, error) {
+	processes, err := SnapshotProcesses()
+	if err != nil {
+		// Still try the root so cleanup is never worse than TerminateProcess
+		if termErr := TerminateEntry(root); termErr != nil {
+			return nil, errors.Join(err, termErr)
+		}
+
+		return []ProcessEntry{root}, err
+	}
+
+	for _, p := range processes {
+		if p.Pid != root.Pid {
+			continue
+		}
+
+		if !sameStart(root.Created, p.Created) {
+			return nil, fmt.Errorf("pid %d (%s): %w", root.Pid, p.ExeFile, ErrProcessReused)
+		}
+
+		root = p
+		break
+	}
+
+	var (
+		killed []ProcessEntry
+		errs   []error
+	)
+
+	for _, p := range append(Descendants(processes, root.Pid), root) {
+		if err := TerminateEntry(p); err != nil {
+			errs = append(errs, fmt.Errorf("pid %d (%s): %w", p.Pid, p.ExeFile, err))
+			continue
+		}
+
+		killed = append(killed, p)
+	}
+
+	return killed, errors.Join(errs...)
+}
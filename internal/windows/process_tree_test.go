@@ -0,0 +1,49 @@
+//go:build windows
+
+package windows
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescendants(t *testing.T) {
+	t.Parallel()
+
+	at := func(s int) time.Time { return time.Date(2025, 1, 1, 0, 0, s, 0, time.UTC) }
+
+	processes := []ProcessEntry{
+		{Pid: 0, ParentPid: 0, ExeFile: "[System Process]"},
+		{Pid: 100, ParentPid: 1, ExeFile: "smpwin.exe", Created: at(10)},
+		{Pid: 200, ParentPid: 100, ExeFile: "SPlusCC.exe", Created: at(20)},
+		{Pid: 300, ParentPid: 200, ExeFile: "gcc.exe", Created: at(30)},
+		{Pid: 400, ParentPid: 100, ExeFile: "conhost.exe"},                  // Creation time unknown
+		{Pid: 500, ParentPid: 100, ExeFile: "explorer.exe", Created: at(5)}, // Parent was an earlier PID 100
+		{Pid: 1, ParentPid: 300, ExeFile: "cmd.exe", Created: at(40)},       // Reused the launcher's PID
+	}
+
+	got := Descendants(processes, 100)
+
+	pids := make([]uint32, len(got))
+	for i, p := range got {
+		pids[i] = p.Pid
+	}
+
+	// Deepest first
+	assert.Equal(t, []uint32{1, 300, 200, 400}, pids)
+	assert.Empty(t, Descendants(processes, 500))
+}
+
+func TestSameStart(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	assert.True(t, sameStart(started, started))
+	assert.True(t, sameStart(started, started.Local()), "the same instant in another zone")
+	assert.False(t, sameStart(started, started.Add(time.Second)), "a later process reusing the PID")
+	assert.True(t, sameStart(time.Time{}, started), "unknown recorded start")
+	assert.True(t, sameStart(started, time.Time{}), "unknown current start")
+}
@@ -0,0 +1,57 @@
+//go:build windows
+
+package windows
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var procRegGetValueW = advapi32.NewProc("RegGetValueW")
+
+const (
+	HKEY_LOCAL_MACHINE = 0x80000002
+
+	RRF_RT_REG_SZ = 0x00000002
+
+	ERROR_MORE_DATA = 234
+)
+
+// RegistryString reads a REG_SZ value. root is a predefined key such as HKEY_LOCAL_MACHINE.
+func RegistryString(root uintptr, path, name string) (string, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return "", err
+	}
+
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]uint16, 256)
+
+	for {
+		size := uint32(len(buf) * 2)
+
+		ret, _, _ := procRegGetValueW.Call(
+			root,
+			uintptr(unsafe.Pointer(pathPtr)),
+			uintptr(unsafe.Pointer(namePtr)),
+			RRF_RT_REG_SZ,
+			0,
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&size)),
+		)
+
+		switch ret {
+		case 0:
+			return syscall.UTF16ToString(buf), nil
+		case ERROR_MORE_DATA:
+			buf = make([]uint16, size/2+1)
+		default:
+			return "", fmt.Errorf("failed to read %s\\%s: %w", path, name, syscall.Errno(ret))
+		}
+	}
+}
@@ -0,0 +1,158 @@
+//go:build windows
+
+package windows
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procCreateProcessWithLogonW = advapi32.NewProc("CreateProcessWithLogonW")
+	procCredReadW               = advapi32.NewProc("CredReadW")
+	procCredFree                = advapi32.NewProc("CredFree")
+)
+
+const (
+	LOGON_WITH_PROFILE = 0x00000001
+	CRED_TYPE_GENERIC  = 1
+)
+
+// startupInfo mirrors STARTUPINFOW
+type startupInfo struct {
+	Cb            uint32
+	Reserved      *uint16
+	Desktop       *uint16
+	Title         *uint16
+	X             uint32
+	Y             uint32
+	XSize         uint32
+	YSize         uint32
+	XCountChars   uint32
+	YCountChars   uint32
+	FillAttribute uint32
+	Flags         uint32
+	ShowWindow    uint16
+	CbReserved2   uint16
+	LpReserved2   *byte
+	StdInput      uintptr
+	StdOutput     uintptr
+	StdError      uintptr
+}
+
+// processInformation mirrors PROCESS_INFORMATION
+type processInformation struct {
+	Process   uintptr
+	Thread    uintptr
+	ProcessID uint32
+	ThreadID  uint32
+}
+
+// credential mirrors CREDENTIALW
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// CreateProcessWithLogon starts file with args as another user, loading their profile so
+// per-user settings (such as the Crestron configuration) apply, and returns its PID.
+// The process shares the caller's interactive desktop, starts in cwd ("" = the caller's)
+// and shows its first window as showCmd (one of the SW_ constants).
+func CreateProcessWithLogon(domain, user, password, file, args, cwd string, showCmd int) (uint32, error) {
+	userPtr, err := syscall.UTF16PtrFromString(user)
+	if err != nil {
+		return 0, err
+	}
+
+	domainPtr, err := syscall.UTF16PtrFromString(domain)
+	if err != nil {
+		return 0, err
+	}
+
+	passwordPtr, err := syscall.UTF16PtrFromString(password)
+	if err != nil {
+		return 0, err
+	}
+
+	filePtr, err := syscall.UTF16PtrFromString(file)
+	if err != nil {
+		return 0, err
+	}
+
+	// The command line buffer must be writable and include the program name
+	cmdLine, err := syscall.UTF16FromString(syscall.EscapeArg(file) + " " + args)
+	if err != nil {
+		return 0, err
+	}
+
+	desktop, err := syscall.UTF16PtrFromString(`winsta0\default`)
+	if err != nil {
+		return 0, err
+	}
+
+	var cwdPtr *uint16
+	if cwd != "" {
+		if cwdPtr, err = syscall.UTF16PtrFromString(cwd); err != nil {
+			return 0, err
+		}
+	}
+
+	si := startupInfo{Desktop: desktop, Flags: STARTF_USESHOWWINDOW, ShowWindow: uint16(showCmd)}
+	si.Cb = uint32(unsafe.Sizeof(si))
+
+	var pi processInformation
+
+	ret, _, callErr := procCreateProcessWithLogonW.Call(
+		uintptr(unsafe.Pointer(userPtr)),
+		uintptr(unsafe.Pointer(domainPtr)),
+		uintptr(unsafe.Pointer(passwordPtr)),
+		LOGON_WITH_PROFILE,
+		uintptr(unsafe.Pointer(filePtr)),
+		uintptr(unsafe.Pointer(&cmdLine[0])),
+		0,
+		0,
+		uintptr(unsafe.Pointer(cwdPtr)),
+		uintptr(unsafe.Pointer(&si)),
+		uintptr(unsafe.Pointer(&pi)),
+	)
+	if ret == 0 {
+		return 0, fmt.Errorf("CreateProcessWithLogonW failed: %w", callErr)
+	}
+
+	ProcCloseHandle.Call(pi.Thread)
+	ProcCloseHandle.Call(pi.Process)
+
+	return pi.ProcessID, nil
+}
+
+// ReadGenericCredential reads the secret of a generic credential from Windows Credential Manager
+func ReadGenericCredential(target string) (string, error) {
+	targetPtr, err := syscall.UTF16PtrFromString(target)
+	if err != nil {
+		return "", err
+	}
+
+	var cred *credential
+
+	ret, _, callErr := procCredReadW.Call(uintptr(unsafe.Pointer(targetPtr)), CRED_TYPE_GENERIC, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		return "", fmt.Errorf("credential %q not found: %w", target, callErr)
+	}
+
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	// Secrets stored by cmdkey and the Credential Manager UI are UTF-16
+	blob := unsafe.Slice((*uint16)(unsafe.Pointer(cred.CredentialBlob)), cred.CredentialBlobSize/2)
+	return syscall.UTF16ToString(blob), nil
+}
@@ -0,0 +1,139 @@
+//go:build windows
+
+package windows
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procStartServiceCtrlDispatcherW   = advapi32.NewProc("StartServiceCtrlDispatcherW")
+	procRegisterServiceCtrlHandlerExW = advapi32.NewProc("RegisterServiceCtrlHandlerExW")
+	procSetServiceStatus              = advapi32.NewProc("SetServiceStatus")
+)
+
+const (
+	SERVICE_WIN32_OWN_PROCESS = 0x00000010
+
+	SERVICE_STOPPED       = 1
+	SERVICE_START_PENDING = 2
+	SERVICE_STOP_PENDING  = 3
+	SERVICE_RUNNING       = 4
+
+	SERVICE_ACCEPT_STOP     = 0x00000001
+	SERVICE_ACCEPT_SHUTDOWN = 0x00000004
+
+	SERVICE_CONTROL_STOP        = 1
+	SERVICE_CONTROL_INTERROGATE = 4
+	SERVICE_CONTROL_SHUTDOWN    = 5
+
+	ERROR_CALL_NOT_IMPLEMENTED              = 120
+	ERROR_SERVICE_SPECIFIC_ERROR            = 1066
+	ERROR_FAILED_SERVICE_CONTROLLER_CONNECT = 1063
+)
+
+// serviceTableEntry mirrors SERVICE_TABLE_ENTRYW
+type serviceTableEntry struct {
+	ServiceName *uint16
+	ServiceProc uintptr
+}
+
+// serviceStatus mirrors SERVICE_STATUS
+type serviceStatus struct {
+	ServiceType             uint32
+	CurrentState            uint32
+	ControlsAccepted        uint32
+	Win32ExitCode           uint32
+	ServiceSpecificExitCode uint32
+	CheckPoint              uint32
+	WaitHint                uint32
+}
+
+// ErrNotService is returned by RunService when the process was not started by the
+// Service Control Manager
+var ErrNotService = errors.New("not started by the Service Control Manager")
+
+// RunService connects to the Service Control Manager and calls run as the service's
+// main function. The stop channel is closed when the service is asked to stop or the
+// machine shuts down; run should then return. RunService blocks until run returns.
+func RunService(name string, run func(stop <-chan struct{}) error) error {
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+
+	var (
+		handle   uintptr
+		runErr   error
+		stop     = make(chan struct{})
+		stopOnce sync.Once
+	)
+
+	setStatus := func(state, accepted, exitCode uint32) {
+		status := serviceStatus{
+			ServiceType:      SERVICE_WIN32_OWN_PROCESS,
+			CurrentState:     state,
+			ControlsAccepted: accepted,
+			Win32ExitCode:    exitCode,
+		}
+		if exitCode == ERROR_SERVICE_SPECIFIC_ERROR {
+			status.ServiceSpecificExitCode = 1
+		}
+
+		procSetServiceStatus.Call(handle, uintptr(unsafe.Pointer(&status)))
+	}
+
+	handler := syscall.NewCallback(func(control, eventType, eventData, context uintptr) uintptr {
+		switch control {
+		case SERVICE_CONTROL_STOP, SERVICE_CONTROL_SHUTDOWN:
+			setStatus(SERVICE_STOP_PENDING, 0, 0)
+			stopOnce.Do(func() { close(stop) })
+		case SERVICE_CONTROL_INTERROGATE:
+		default:
+			return ERROR_CALL_NOT_IMPLEMENTED
+		}
+
+		return 0
+	})
+
+	serviceMain := syscall.NewCallback(func(argc uintptr, argv uintptr) uintptr {
+		handle, _, err = procRegisterServiceCtrlHandlerExW.Call(uintptr(unsafe.Pointer(namePtr)), handler, 0)
+		if handle == 0 {
+			runErr = fmt.Errorf("failed to register service control handler: %w", err)
+			return 0
+		}
+
+		setStatus(SERVICE_START_PENDING, 0, 0)
+		setStatus(SERVICE_RUNNING, SERVICE_ACCEPT_STOP|SERVICE_ACCEPT_SHUTDOWN, 0)
+
+		runErr = run(stop)
+
+		var exitCode uint32
+		if runErr != nil {
+			exitCode = ERROR_SERVICE_SPECIFIC_ERROR
+		}
+
+		setStatus(SERVICE_STOPPED, 0, exitCode)
+		return 0
+	})
+
+	table := []serviceTableEntry{
+		{ServiceName: namePtr, ServiceProc: serviceMain},
+		{},
+	}
+
+	ret, _, err := procStartServiceCtrlDispatcherW.Call(uintptr(unsafe.Pointer(&table[0])))
+	if ret == 0 {
+		if errno, ok := err.(syscall.Errno); ok && errno == ERROR_FAILED_SERVICE_CONTROLLER_CONNECT {
+			return ErrNotService
+		}
+
+		return fmt.Errorf("failed to start service dispatcher: %w", err)
+	}
+
+	return runErr
+}
@@ -0,0 +1,175 @@
+//go:build windows
+
+package windows
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	wtsapi32                         = syscall.NewLazyDLL("wtsapi32.dll")
+	procWTSQueryUserToken            = wtsapi32.NewProc("WTSQueryUserToken")
+	procWTSGetActiveConsoleSessionId = kernel32.NewProc("WTSGetActiveConsoleSessionId")
+	userenv                          = syscall.NewLazyDLL("userenv.dll")
+	procCreateEnvironmentBlock       = userenv.NewProc("CreateEnvironmentBlock")
+	procDestroyEnvironmentBlock      = userenv.NewProc("DestroyEnvironmentBlock")
+	procDuplicateTokenEx             = advapi32.NewProc("DuplicateTokenEx")
+	procCreateProcessAsUserW         = advapi32.NewProc("CreateProcessAsUserW")
+)
+
+const (
+	TokenLinkedToken = 19
+
+	MAXIMUM_ALLOWED        = 0x02000000
+	SecurityImpersonation  = 2
+	TokenPrimary           = 1
+	STARTF_USESHOWWINDOW   = 0x00000001
+	STARTF_USESTDHANDLES   = 0x00000100
+	CREATE_NO_WINDOW       = 0x08000000
+	CREATE_UNICODE_ENV     = 0x00000400
+	noActiveConsoleSession = 0xFFFFFFFF
+)
+
+// StartInActiveSession starts cmdLine on the interactive desktop of the user logged on
+// at the console, with stdout and stderr going to the given handles. This lets a service
+// running in session 0 hand GUI automation to a process that can see SIMPL Windows.
+// The user's elevated token is used when they are an administrator. The caller must be
+// running as LocalSystem.
+func StartInActiveSession(cmdLine, dir string, stdout, stderr syscall.Handle) (*Process, error) {
+	session, _, _ := procWTSGetActiveConsoleSessionId.Call()
+	if session == noActiveConsoleSession {
+		return nil, fmt.Errorf("no user is logged on at the console")
+	}
+
+	var userToken syscall.Token
+
+	ret, _, err := procWTSQueryUserToken.Call(session, uintptr(unsafe.Pointer(&userToken)))
+	if ret == 0 {
+		return nil, fmt.Errorf("failed to get token for session %d: %w", session, err)
+	}
+
+	defer userToken.Close()
+
+	linked := sessionToken(userToken)
+	if linked != userToken {
+		defer linked.Close()
+	}
+
+	token, err := primaryToken(linked)
+	if err != nil {
+		return nil, err
+	}
+
+	defer token.Close()
+
+	var env uintptr
+
+	if ret, _, err := procCreateEnvironmentBlock.Call(uintptr(unsafe.Pointer(&env)), uintptr(token), 0); ret == 0 {
+		return nil, fmt.Errorf("failed to create user environment: %w", err)
+	}
+
+	defer procDestroyEnvironmentBlock.Call(env)
+
+	cmdLinePtr, err := syscall.UTF16FromString(cmdLine)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirPtr *uint16
+	if dir != "" {
+		if dirPtr, err = syscall.UTF16PtrFromString(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	desktop, err := syscall.UTF16PtrFromString(`winsta0\default`)
+	if err != nil {
+		return nil, err
+	}
+
+	si := startupInfo{
+		Desktop:   desktop,
+		Flags:     STARTF_USESTDHANDLES,
+		StdOutput: uintptr(stdout),
+		StdError:  uintptr(stderr),
+	}
+	si.Cb = uint32(unsafe.Sizeof(si))
+
+	var pi processInformation
+
+	// Only the output handles may be inherited; hold ForkLock so no other process
+	// creation picks them up while they are marked inheritable
+	syscall.ForkLock.Lock()
+	defer syscall.ForkLock.Unlock()
+
+	for _, h := range []syscall.Handle{stdout, stderr} {
+		if err := syscall.SetHandleInformation(h, syscall.HANDLE_FLAG_INHERIT, syscall.HANDLE_FLAG_INHERIT); err != nil {
+			return nil, fmt.Errorf("failed to make output handle inheritable: %w", err)
+		}
+
+		defer syscall.SetHandleInformation(h, syscall.HANDLE_FLAG_INHERIT, 0)
+	}
+
+	ret, _, err = procCreateProcessAsUserW.Call(
+		uintptr(token),
+		0,
+		uintptr(unsafe.Pointer(&cmdLinePtr[0])),
+		0,
+		0,
+		1, // Inherit handles
+		CREATE_NO_WINDOW|CREATE_UNICODE_ENV,
+		env,
+		uintptr(unsafe.Pointer(dirPtr)),
+		uintptr(unsafe.Pointer(&si)),
+		uintptr(unsafe.Pointer(&pi)),
+	)
+	if ret == 0 {
+		return nil, fmt.Errorf("CreateProcessAsUser failed: %w", err)
+	}
+
+	ProcCloseHandle.Call(pi.Thread)
+
+	return &Process{handle: pi.Process, Pid: pi.ProcessID}, nil
+}
+
+// sessionToken returns the elevated token linked to an administrator's filtered token,
+// or the token itself for standard users. A linked token is a new handle the caller
+// must close.
+func sessionToken(token syscall.Token) syscall.Token {
+	var linked syscall.Token
+	var n uint32
+
+	ret, _, _ := procGetTokenInformation.Call(
+		uintptr(token),
+		TokenLinkedToken,
+		uintptr(unsafe.Pointer(&linked)),
+		unsafe.Sizeof(linked),
+		uintptr(unsafe.Pointer(&n)),
+	)
+	if ret == 0 || linked == 0 {
+		return token
+	}
+
+	return linked
+}
+
+// primaryToken duplicates token as a primary token usable with CreateProcessAsUser
+func primaryToken(token syscall.Token) (syscall.Token, error) {
+	var primary syscall.Token
+
+	ret, _, err := procDuplicateTokenEx.Call(
+		uintptr(token),
+		MAXIMUM_ALLOWED,
+		0,
+		SecurityImpersonation,
+		TokenPrimary,
+		uintptr(unsafe.Pointer(&primary)),
+	)
+	if ret == 0 {
+		return 0, fmt.Errorf("failed to duplicate session token: %w", err)
+	}
+
+	return primary, nil
+}
@@ -67,6 +67,18 @@ type WindowEvent struct {
 	Title string
 	Pid   uint32
 	Class string
+
+	Parent  uintptr  // Owner of the window (0 for an unowned top-level window)
+	Rect    Rect     // Position when first seen, in screen coordinates
+	Style   uint32   // Window styles (WS_*)
+	ExStyle uint32   // Extended window styles (WS_EX_*)
+	Modal   bool     // Its owner is disabled while it is shown, as for a modal dialog
+	Buttons []string // Button captions when first seen, as written (e.g. "&OK")
+	Texts   []string // Non-empty child control text; set only on update events
+
+	// CrashReport is set for windows of Windows Error Reporting reporting a crash of
+	// the monitored process, such as "SIMPL Windows has stopped working"
+	CrashReport bool
 }
 
 // SHELLEXECUTEINFO for ShellExecuteEx API
@@ -0,0 +1,79 @@
+//go:build windows
+
+package windows
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	versionDLL                  = syscall.NewLazyDLL("version.dll")
+	procGetFileVersionInfoSizeW = versionDLL.NewProc("GetFileVersionInfoSizeW")
+	procGetFileVersionInfoW     = versionDLL.NewProc("GetFileVersionInfoW")
+	procVerQueryValueW          = versionDLL.NewProc("VerQueryValueW")
+)
+
+// vsFixedFileInfo mirrors VS_FIXEDFILEINFO
+type vsFixedFileInfo struct {
+	Signature        uint32
+	StrucVersion     uint32
+	FileVersionMS    uint32
+	FileVersionLS    uint32
+	ProductVersionMS uint32
+	ProductVersionLS uint32
+	FileFlagsMask    uint32
+	FileFlags        uint32
+	FileOS           uint32
+	FileType         uint32
+	FileSubtype      uint32
+	FileDateMS       uint32
+	FileDateLS       uint32
+}
+
+// GetFileVersion returns the file version resource of an executable as "major.minor.build.revision"
+func GetFileVersion(path string) (string, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return "", err
+	}
+
+	size, _, err := procGetFileVersionInfoSizeW.Call(uintptr(unsafe.Pointer(pathPtr)), 0)
+	if size == 0 {
+		return "", fmt.Errorf("no version information in %s: %w", path, err)
+	}
+
+	buf := make([]byte, size)
+	ret, _, err := procGetFileVersionInfoW.Call(
+		uintptr(unsafe.Pointer(pathPtr)),
+		0,
+		size,
+		uintptr(unsafe.Pointer(&buf[0])),
+	)
+	if ret == 0 {
+		return "", fmt.Errorf("failed to read version information from %s: %w", path, err)
+	}
+
+	root, _ := syscall.UTF16PtrFromString(`\`)
+
+	var (
+		info    *vsFixedFileInfo
+		infoLen uint32
+	)
+
+	ret, _, _ = procVerQueryValueW.Call(
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(unsafe.Pointer(root)),
+		uintptr(unsafe.Pointer(&info)),
+		uintptr(unsafe.Pointer(&infoLen)),
+	)
+	if ret == 0 || info == nil || infoLen < uint32(unsafe.Sizeof(vsFixedFileInfo{})) {
+		return "", fmt.Errorf("no fixed version information in %s", path)
+	}
+
+	return fmt.Sprintf("%d.%d.%d.%d",
+		info.FileVersionMS>>16, info.FileVersionMS&0xFFFF,
+		info.FileVersionLS>>16, info.FileVersionLS&0xFFFF,
+	), nil
+}
@@ -0,0 +1,125 @@
+//go:build windows
+
+package windows
+
+import (
+	"log/slog"
+	"unsafe"
+)
+
+var (
+	procGetWindow       = user32.NewProc("GetWindow")
+	procGetWindowLongW  = user32.NewProc("GetWindowLongW")
+	procIsWindowEnabled = user32.NewProc("IsWindowEnabled")
+)
+
+const (
+	GW_OWNER    = 4
+	GWL_STYLE   = -16
+	GWL_EXSTYLE = -20
+
+	WS_POPUP = 0x80000000
+
+	// DialogClass is the window class of standard dialog boxes
+	DialogClass = "#32770"
+)
+
+// Window kinds, as logged
+const (
+	KindModal  = "modal"  // A dialog blocking its owner
+	KindDialog = "dialog" // A dialog box that does not block its owner
+	KindWindow = "window" // Any other top-level window
+)
+
+// Kind describes what the window is: a modal dialog, another dialog, or an incidental
+// top-level window such as a splash screen or tool window
+func (ev WindowEvent) Kind() string {
+	switch {
+	case ev.Modal:
+		return KindModal
+	case ev.Class == DialogClass || (ev.Style&WS_POPUP != 0 && ev.Parent != 0):
+		return KindDialog
+	default:
+		return KindWindow
+	}
+}
+
+// LogAttrs returns the attributes windows are logged with
+func (ev WindowEvent) LogAttrs() []any {
+	attrs := []any{
+		slog.Uint64("hwnd", uint64(ev.Hwnd)),
+		slog.Uint64("pid", uint64(ev.Pid)),
+		slog.String("class", ev.Class),
+		slog.String("title", ev.Title),
+		slog.String("kind", ev.Kind()),
+		slog.String("rect", ev.Rect.String()),
+	}
+
+	if ev.Parent != 0 {
+		attrs = append(attrs, slog.Uint64("parent", uint64(ev.Parent)))
+	}
+
+	if len(ev.Buttons) > 0 {
+		attrs = append(attrs, slog.Any("buttons", ev.Buttons))
+	}
+
+	return attrs
+}
+
+// describeWindow builds the event for a newly seen top-level window from its
+// owner, position, styles and child controls
+func describeWindow(w WindowInfo, children []ChildInfo) WindowEvent {
+	ev := WindowEvent{
+		Hwnd:    w.Hwnd,
+		Title:   w.Title,
+		Pid:     w.Pid,
+		Class:   GetClassName(w.Hwnd),
+		Style:   getWindowLong(w.Hwnd, GWL_STYLE),
+		ExStyle: getWindowLong(w.Hwnd, GWL_EXSTYLE),
+		Buttons: buttonCaptions(children),
+	}
+
+	ev.Parent, _, _ = procGetWindow.Call(w.Hwnd, GW_OWNER)
+
+	if ev.Parent != 0 {
+		enabled, _, _ := procIsWindowEnabled.Call(ev.Parent)
+		ev.Modal = enabled == 0
+	}
+
+	// Left zero if the window has already gone
+	_, _, _ = procGetWindowRect.Call(w.Hwnd, uintptr(unsafe.Pointer(&ev.Rect)))
+
+	return ev
+}
+
+// getWindowLong returns the window's 32-bit value at index, such as GWL_STYLE
+func getWindowLong(hwnd uintptr, index int) uint32 {
+	ret, _, _ := procGetWindowLongW.Call(hwnd, uintptr(index))
+	return uint32(ret)
+}
+
+// childTexts returns the non-empty text of children, in order
+func childTexts(children []ChildInfo) []string {
+	var texts []string
+
+	for _, ci := range children {
+		if ci.Text != "" {
+			texts = append(texts, ci.Text)
+		}
+	}
+
+	return texts
+}
+
+// buttonCaptions returns the non-empty captions of the Button controls among children
+func buttonCaptions(children []ChildInfo) []string {
+	var captions []string
+
+	for _, ci := range children {
+		if ci.ClassName == "Button" && ci.Text != "" {
+			captions = append(captions, ci.Text)
+		}
+	}
+
+	return captions
+}
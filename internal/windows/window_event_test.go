@@ -0,0 +1,45 @@
+//go:build windows
+
+package windows
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowEvent_Kind(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, KindModal, WindowEvent{Class: DialogClass, Parent: 1, Modal: true}.Kind())
+	assert.Equal(t, KindDialog, WindowEvent{Class: DialogClass}.Kind())
+	assert.Equal(t, KindDialog, WindowEvent{Class: "TMessageForm", Parent: 1, Style: WS_POPUP}.Kind())
+	assert.Equal(t, KindWindow, WindowEvent{Class: "TSplash", Style: WS_POPUP}.Kind())
+	assert.Equal(t, KindWindow, WindowEvent{Class: "Afx:400000:8"}.Kind())
+}
+
+func TestButtonCaptions(t *testing.T) {
+	t.Parallel()
+
+	captions := buttonCaptions([]ChildInfo{
+		{ClassName: "Static", Text: "Save changes?"},
+		{ClassName: "Button", Text: "&Yes"},
+		{ClassName: "Button", Text: ""},
+		{ClassName: "Button", Text: "&No"},
+	})
+
+	assert.Equal(t, []string{"&Yes", "&No"}, captions)
+	assert.Nil(t, buttonCaptions(nil))
+}
+
+func TestChildTexts(t *testing.T) {
+	t.Parallel()
+
+	texts := childTexts([]ChildInfo{
+		{ClassName: "Static", Text: "Compiling program, please wait..."},
+		{ClassName: "msctls_progress32"},
+		{ClassName: "Button", Text: "Cancel"},
+	})
+
+	assert.Equal(t, []string{"Compiling program, please wait...", "Cancel"}, texts)
+}
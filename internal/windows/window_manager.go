@@ -3,6 +3,7 @@
 package windows
 
 import (
+	"fmt"
 	"log/slog"
 	"strings"
 	"time"
@@ -14,12 +15,13 @@ import (
 
 // windowManager implements the WindowManager interface
 type windowManager struct {
-	log logger.LoggerInterface
+	log    logger.LoggerInterface
+	events *EventBus
 }
 
-// newWindowManager creates a new window manager
-func newWindowManager(log logger.LoggerInterface) *windowManager {
-	return &windowManager{log: log}
+// newWindowManager creates a new window manager that waits on the given event bus
+func newWindowManager(log logger.LoggerInterface, events *EventBus) *windowManager {
+	return &windowManager{log: log, events: events}
 }
 
 // CloseWindow sends a WM_CLOSE message to the specified window
@@ -37,11 +39,14 @@ func (w *windowManager) CloseWindow(hwnd uintptr, title string) {
 	time.Sleep(timeouts.WindowMessageDelay)
 }
 
-// SetForeground brings a window to the foreground using AttachThreadInput technique
+// SetForeground brings a window to the foreground using AttachThreadInput technique,
+// first moving it to the primary monitor if it is elsewhere
 func (w *windowManager) SetForeground(hwnd uintptr) bool {
 	// Restore window if minimized
 	ret, _, _ := procShowWindow.Call(hwnd, uintptr(SW_RESTORE))
-	w.log.Debug("ShowWindow(SW_RESTORE)", slog.Uint64("ret", uint64(ret)))
+	w.log.Trace("ShowWindow(SW_RESTORE)", slog.Uint64("ret", uint64(ret)))
+
+	w.moveToPrimaryMonitor(hwnd)
 
 	// Try standard SetForegroundWindow first
 	ret, _, _ = procSetForegroundWindow.Call(hwnd)
@@ -70,7 +75,7 @@ func (w *windowManager) SetForeground(hwnd uintptr) bool {
 		return false
 	}
 
-	w.log.Debug("Attaching threads",
+	w.log.Trace("Attaching threads",
 		slog.Uint64("fgThreadID", uint64(fgThreadID)),
 		slog.Uint64("targetThreadID", uint64(targetThreadID)))
 
@@ -168,31 +173,21 @@ func (w *windowManager) CollectChildInfos(hwnd uintptr) []ChildInfo {
 
 // WaitOnMonitor waits for a window event matching any of the provided predicates
 func (w *windowManager) WaitOnMonitor(timeout time.Duration, matchers ...func(WindowEvent) bool) (WindowEvent, bool) {
-	if MonitorCh == nil {
-		return WindowEvent{}, false
-	}
+	// Subscribe before checking the cache so nothing published in between is missed
+	sub := w.events.Subscribe(DefaultSubscriptionBuffer)
+	defer sub.Unsubscribe()
 
 	// First, check recent cache to avoid missing already-seen dialogs
-	recentMu.Lock()
-	for i := len(recentEvents) - 1; i >= 0; i-- {
-		ev := recentEvents[i]
-
-		for _, m := range matchers {
-			if m(ev) {
-				recentMu.Unlock()
-				return ev, true
-			}
-		}
+	if ev, ok := w.events.FindRecent(matchers...); ok {
+		return ev, true
 	}
 
-	recentMu.Unlock()
-
 	timer := time.NewTimer(timeout)
 	defer timer.Stop()
 
 	for {
 		select {
-		case ev := <-MonitorCh:
+		case ev := <-sub.C:
 			for _, m := range matchers {
 				if m(ev) {
 					return ev, true
@@ -231,3 +226,62 @@ func (w *windowManager) FindAndClickButton(parentHwnd uintptr, buttonText string
 	w.log.Debug("Button not found", slog.String("text", buttonText))
 	return false
 }
+
+// SendMessage sends msg to hwnd and waits for it to be processed, returning the
+// window procedure's result. It returns false if the window is hung or does not
+// process the message within SendMessageTimeout.
+func (w *windowManager) SendMessage(hwnd uintptr, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	var result uintptr
+
+	ret, _, err := ProcSendMessageTimeoutW.Call(
+		hwnd,
+		uintptr(msg),
+		wParam,
+		lParam,
+		SMTO_ABORTIFHUNG,
+		uintptr(timeouts.SendMessageTimeout/time.Millisecond),
+		uintptr(unsafe.Pointer(&result)),
+	)
+	if ret == 0 {
+		w.log.Debug("SendMessage failed",
+			slog.Uint64("hwnd", uint64(hwnd)),
+			slog.String("msg", fmt.Sprintf("0x%04X", msg)),
+			slog.Any("error", err))
+		return 0, false
+	}
+
+	return result, true
+}
+
+// PostMessage queues msg for hwnd without waiting for it to be processed
+func (w *windowManager) PostMessage(hwnd uintptr, msg uint32, wParam, lParam uintptr) bool {
+	ret, _, err := procPostMessageW.Call(hwnd, uintptr(msg), wParam, lParam)
+	if ret == 0 {
+		w.log.Debug("PostMessage failed",
+			slog.Uint64("hwnd", uint64(hwnd)),
+			slog.String("msg", fmt.Sprintf("0x%04X", msg)),
+			slog.Any("error", err))
+		return false
+	}
+
+	return true
+}
+
+// ClickButtonById clicks the button with control ID id in the dialog parentHwnd, for
+// buttons without a caption or whose caption is localised. The click is posted, so a
+// dialog the button opens does not block the caller.
+func (w *windowManager) ClickButtonById(parentHwnd uintptr, id int) bool {
+	button, _, _ := procGetDlgItem.Call(parentHwnd, uintptr(id))
+	if button == 0 {
+		w.log.Debug("Button not found", slog.Int("id", id))
+		return false
+	}
+
+	w.log.Debug("Found button, sending click",
+		slog.Int("id", id),
+		slog.Uint64("hwnd", uint64(button)),
+	)
+
+	// WM_COMMAND: wParam = MAKEWPARAM(controlID, BN_CLICKED), lParam = hwnd
+	return w.PostMessage(parentHwnd, WM_COMMAND, uintptr(id&0xFFFF)|BN_CLICKED<<16, button)
+}
@@ -62,6 +62,49 @@ func ShellExecute(hwnd uintptr, verb, file, args, cwd string, showCmd int) error
 // ShellExecuteEx executes a file using the Windows shell and returns the process ID
 // This is more reliable than ShellExecute when you need to track the launched process
 func ShellExecuteEx(hwnd uintptr, verb, file, args, cwd string, showCmd int, log logger.LoggerInterface) (uint32, error) {
+	hProcess, err := shellExecuteExProcess(hwnd, verb, file, args, cwd, showCmd)
+	if err != nil {
+		return 0, err
+	}
+
+	pid, _, _ := procGetProcessId.Call(hProcess)
+	if pid == 0 {
+		// Clean up the process handle before returning error
+		if ret, _, err := ProcCloseHandle.Call(hProcess); ret == 0 {
+			log.Debug("Failed to close process handle in error path", slog.Any("error", err))
+		}
+
+		return 0, fmt.Errorf("failed to get process ID from handle")
+	}
+
+	// Close the process handle - we only need the PID
+	if ret, _, err := ProcCloseHandle.Call(hProcess); ret == 0 {
+		log.Debug("Failed to close process handle after getting PID", slog.Any("error", err))
+	}
+
+	return uint32(pid), nil
+}
+
+// ShellExecuteExProcess executes a file using the Windows shell and returns the launched
+// process, keeping its handle open for waiting on it. The caller must Close it.
+func ShellExecuteExProcess(hwnd uintptr, verb, file, args, cwd string, showCmd int) (*Process, error) {
+	hProcess, err := shellExecuteExProcess(hwnd, verb, file, args, cwd, showCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	pid, _, _ := procGetProcessId.Call(hProcess)
+	if pid == 0 {
+		ProcCloseHandle.Call(hProcess)
+		return nil, fmt.Errorf("failed to get process ID from handle")
+	}
+
+	return &Process{handle: hProcess, Pid: uint32(pid)}, nil
+}
+
+// shellExecuteExProcess runs ShellExecuteEx and returns the launched process handle,
+// which the caller must close
+func shellExecuteExProcess(hwnd uintptr, verb, file, args, cwd string, showCmd int) (uintptr, error) {
 	const SEE_MASK_NOCLOSEPROCESS = 0x00000040
 
 	var verbPtr, filePtr, argsPtr, cwdPtr *uint16
@@ -111,32 +154,18 @@ func ShellExecuteEx(hwnd uintptr, verb, file, args, cwd string, showCmd int, log
 		return 0, fmt.Errorf("shell execute ex failed")
 	}
 
-	// Get process ID from the process handle
 	if sei.HProcess == 0 {
 		return 0, fmt.Errorf("shell execute ex did not return a process handle")
 	}
 
-	pid, _, _ := procGetProcessId.Call(sei.HProcess)
-	if pid == 0 {
-		// Clean up the process handle before returning error
-		if ret, _, err := ProcCloseHandle.Call(sei.HProcess); ret == 0 {
-			log.Debug("Failed to close process handle in error path", slog.Any("error", err))
-		}
-
-		return 0, fmt.Errorf("failed to get process ID from handle")
-	}
-
-	// Close the process handle - we only need the PID
-	if ret, _, err := ProcCloseHandle.Call(sei.HProcess); ret == 0 {
-		log.Debug("Failed to close process handle after getting PID", slog.Any("error", err))
-	}
-
-	return uint32(pid), nil
+	return sei.HProcess, nil
 }
 
 // GetWindowText retrieves the text of a window
+// The buffer is sized from GetWindowTextLength so titles with long paths are not truncated
 func GetWindowText(hwnd uintptr) string {
-	buf := make([]uint16, 256)
+	n, _, _ := procGetWindowTextLengthW.Call(hwnd)
+	buf := make([]uint16, max(int(n)+1, 256))
 
 	ret, _, _ := procGetWindowTextW.Call(hwnd, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
 	if ret == 0 {
@@ -182,6 +211,22 @@ func GetWindowPid(hwnd uintptr) uint32 {
 	return pid
 }
 
+// GetWindowThreadId returns the ID of the thread that created hwnd, or 0
+func GetWindowThreadId(hwnd uintptr) uint32 {
+	ret, _, _ := procGetWindowThreadProcessId.Call(hwnd, 0)
+	return uint32(ret)
+}
+
+// PostQuit posts WM_QUIT to a thread, ending its message loop
+func PostQuit(threadID uint32) error {
+	ret, _, err := procPostThreadMessageW.Call(uintptr(threadID), WM_QUIT, 0, 0)
+	if ret == 0 {
+		return fmt.Errorf("failed to post WM_QUIT to thread %d: %w", threadID, err)
+	}
+
+	return nil
+}
+
 // TerminateProcess forcefully terminates a process by its PID
 func TerminateProcess(pid uint32) error {
 	const PROCESS_TERMINATE = 0x0001
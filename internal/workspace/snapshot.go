@@ -0,0 +1,80 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Snapshot records the files under a directory, so the files a compile creates or
+// changes there can be found afterwards
+type Snapshot struct {
+	Dir    string
+	stamps map[string]fileStamp // Path relative to Dir -> stamp when the snapshot was taken
+}
+
+// TakeSnapshot records every file under dir, including subdirectories
+func TakeSnapshot(dir string) (*Snapshot, error) {
+	s := &Snapshot{Dir: dir, stamps: make(map[string]fileStamp)}
+
+	err := s.walk(func(rel string, stamp fileStamp) {
+		s.stamps[rel] = stamp
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Changed returns the paths (inside Dir) of files created or modified since the
+// snapshot was taken
+func (s *Snapshot) Changed() ([]string, error) {
+	var changed []string
+
+	err := s.walk(func(rel string, stamp fileStamp) {
+		if original, ok := s.stamps[rel]; ok && original == stamp {
+			return
+		}
+
+		changed = append(changed, filepath.Join(s.Dir, rel))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// walk calls fn with the relative path and stamp of each regular file under Dir
+func (s *Snapshot) walk(fn func(rel string, stamp fileStamp)) error {
+	err := filepath.WalkDir(s.Dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.Dir, path)
+		if err != nil {
+			return err
+		}
+
+		stamp, err := stat(path)
+		if err != nil {
+			return err
+		}
+
+		fn(rel, stamp)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", s.Dir, err)
+	}
+
+	return nil
+}
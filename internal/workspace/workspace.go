@@ -0,0 +1,124 @@
+// Package workspace provides scratch copies of SIMPL Windows program directories,
+// so a program can be compiled away from its original location.
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Norgate-AV/smpc/internal/fileutil"
+)
+
+// fileStamp identifies a version of a file for change detection
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// Workspace is a temporary copy of a program and the files alongside it
+type Workspace struct {
+	Dir     string // Scratch directory holding the copies
+	Program string // Path of the copied program inside Dir
+
+	sourceDir string
+	copied    *Snapshot // Dir as populated, before compiling
+}
+
+// New copies programPath and the other files in its directory (user modules, SIMPL+
+// sources, libraries) into a new temporary directory. Subdirectories are not copied.
+func New(programPath string) (*Workspace, error) {
+	sourceDir := filepath.Dir(programPath)
+
+	dir, err := os.MkdirTemp("", "smpc-workspace-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	ws := &Workspace{
+		Dir:       dir,
+		Program:   filepath.Join(dir, filepath.Base(programPath)),
+		sourceDir: sourceDir,
+	}
+
+	entries, err := os.ReadDir(sourceDir)
+	if err != nil {
+		_ = ws.Remove()
+		return nil, fmt.Errorf("failed to read program directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		if err := fileutil.CopyFile(filepath.Join(sourceDir, entry.Name()), filepath.Join(dir, entry.Name())); err != nil {
+			_ = ws.Remove()
+			return nil, fmt.Errorf("failed to copy %s into workspace: %w", entry.Name(), err)
+		}
+	}
+
+	if ws.copied, err = TakeSnapshot(dir); err != nil {
+		_ = ws.Remove()
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// Artifacts returns the paths (inside Dir) of files created or modified since the
+// workspace was populated, walking subdirectories the compiler may have created
+func (w *Workspace) Artifacts() ([]string, error) {
+	artifacts, err := w.copied.Changed()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan workspace: %w", err)
+	}
+
+	return artifacts, nil
+}
+
+// CopyBack copies every artifact to the same relative location in the original
+// program directory and returns the destination paths
+func (w *Workspace) CopyBack() ([]string, error) {
+	artifacts, err := w.Artifacts()
+	if err != nil {
+		return nil, err
+	}
+
+	copied := make([]string, 0, len(artifacts))
+
+	for _, src := range artifacts {
+		rel, err := filepath.Rel(w.Dir, src)
+		if err != nil {
+			return copied, err
+		}
+
+		dst := filepath.Join(w.sourceDir, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return copied, fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+		}
+
+		if err := fileutil.CopyFile(src, dst); err != nil {
+			return copied, fmt.Errorf("failed to copy %s back: %w", rel, err)
+		}
+
+		copied = append(copied, dst)
+	}
+
+	return copied, nil
+}
+
+// Remove deletes the workspace directory and everything in it
+func (w *Workspace) Remove() error {
+	return os.RemoveAll(w.Dir)
+}
+
+func stat(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+
+	return fileStamp{size: info.Size(), modTime: info.ModTime()}, nil
+}
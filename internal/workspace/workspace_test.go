@@ -0,0 +1,100 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newProgramDir creates a program with a sibling user module and a subdirectory
+func newProgramDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "program.smw"), []byte("program"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "module.umc"), []byte("module"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "backup"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "backup", "old.smw"), []byte("old"), 0o644))
+
+	return filepath.Join(dir, "program.smw")
+}
+
+func TestNew_CopiesProgramAndSiblings(t *testing.T) {
+	program := newProgramDir(t)
+
+	ws, err := New(program)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ws.Remove() })
+
+	assert.Equal(t, filepath.Join(ws.Dir, "program.smw"), ws.Program)
+	assert.FileExists(t, ws.Program)
+	assert.FileExists(t, filepath.Join(ws.Dir, "module.umc"))
+	assert.NoDirExists(t, filepath.Join(ws.Dir, "backup"), "subdirectories are not copied")
+
+	artifacts, err := ws.Artifacts()
+	require.NoError(t, err)
+	assert.Empty(t, artifacts, "untouched copies are not artifacts")
+}
+
+func TestArtifacts_NewAndModifiedFiles(t *testing.T) {
+	ws, err := New(newProgramDir(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ws.Remove() })
+
+	require.NoError(t, os.WriteFile(filepath.Join(ws.Dir, "program.lpz"), []byte("lpz"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(ws.Dir, "SPlsWork"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(ws.Dir, "SPlsWork", "module.dll"), []byte("dll"), 0o644))
+
+	// Simulate SIMPL Windows saving the program during compile
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.WriteFile(ws.Program, []byte("program (saved)"), 0o644))
+	require.NoError(t, os.Chtimes(ws.Program, later, later))
+
+	artifacts, err := ws.Artifacts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(ws.Dir, "SPlsWork", "module.dll"),
+		filepath.Join(ws.Dir, "program.lpz"),
+		filepath.Join(ws.Dir, "program.smw"),
+	}, artifacts)
+}
+
+func TestCopyBack(t *testing.T) {
+	program := newProgramDir(t)
+	sourceDir := filepath.Dir(program)
+
+	ws, err := New(program)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ws.Remove() })
+
+	require.NoError(t, os.WriteFile(filepath.Join(ws.Dir, "program.lpz"), []byte("lpz"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(ws.Dir, "SPlsWork"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(ws.Dir, "SPlsWork", "module.dll"), []byte("dll"), 0o644))
+
+	copied, err := ws.CopyBack()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(sourceDir, "SPlsWork", "module.dll"),
+		filepath.Join(sourceDir, "program.lpz"),
+	}, copied)
+
+	data, err := os.ReadFile(filepath.Join(sourceDir, "program.lpz"))
+	require.NoError(t, err)
+	assert.Equal(t, "lpz", string(data))
+
+	data, err = os.ReadFile(program)
+	require.NoError(t, err)
+	assert.Equal(t, "program", string(data), "unchanged sources are not copied back")
+}
+
+func TestRemove(t *testing.T) {
+	ws, err := New(newProgramDir(t))
+	require.NoError(t, err)
+
+	require.NoError(t, ws.Remove())
+	assert.NoDirExists(t, ws.Dir)
+}
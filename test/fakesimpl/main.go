@@ -0,0 +1,351 @@
+//go:build windows
+
+// Package main implements fakesimpl, a test-only stand-in for smpwin.exe.
+//
+// It creates top-level windows and dialogs with the same titles, child
+// controls (Edit/ListBox/Button) and approximate timing as SIMPL Windows so
+// that the integration suite can exercise the full automation path on any
+// Windows machine without a Crestron installation.
+//
+// Usage: fakesimpl.exe <program.smw>
+//
+// The behavior is selected from the program file name (see scenarios) or the
+// FAKESIMPL_SCENARIO environment variable. FAKESIMPL_TIME_SCALE scales all
+// delays (e.g. 0.1 for a run ten times faster than the defaults).
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	classHost      = "FakeSimplHost"
+	classMainFrame = "AfxMDIFrame140u" // The class MFC gives MDI frames, so smpc can match it by class
+	classDialog    = "FakeSimplDialog"
+
+	titleSplash            = "SIMPL Windows"
+	titleIncompleteSymbols = "Incomplete Symbols"
+	titleConvertCompile    = "Convert/Compile"
+	titleCompiling         = "Compiling..."
+	titleCompileComplete   = "Compile Complete"
+	titleProgramCompile    = "Program Compilation"
+	titleOperationComplete = "Operation Complete"
+	titleConfirmation      = "Confirmation"
+
+	idYes    = 6
+	idNo     = 7
+	idCancel = 2
+
+	idMenuCompile      = 32001
+	idMenuRecompileAll = 32002
+)
+
+// dialog tracks the behavior attached to a simulated dialog window
+type dialog struct {
+	onEnter func()
+	onClose func()
+	buttons map[uintptr]func() // button hwnd -> action
+}
+
+// app holds all simulator state; it is only touched from the UI thread
+type app struct {
+	program  string
+	scenario scenario
+	timing   timing
+
+	host      uintptr
+	mainFrame uintptr
+	dialogs   map[uintptr]*dialog
+	timers    map[uintptr]func()
+	nextTimer uintptr
+	compiling bool
+}
+
+var sim *app
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: fakesimpl <program.smw>")
+		os.Exit(2)
+	}
+
+	// All windows must be created and pumped on the same OS thread
+	runtime.LockOSThread()
+
+	sim = &app{
+		program:  os.Args[1],
+		scenario: selectScenario(os.Args[1]),
+		timing:   scaledTiming(),
+		dialogs:  make(map[uintptr]*dialog),
+		timers:   make(map[uintptr]func()),
+	}
+
+	if err := sim.run(); err != nil {
+		fmt.Fprintf(os.Stderr, "fakesimpl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run registers the window classes, shows the splash screen and pumps messages until exit
+func (a *app) run() error {
+	for name, proc := range map[string]wndProc{
+		classHost:      a.hostProc,
+		classMainFrame: a.mainFrameProc,
+		classDialog:    a.dialogProc,
+	} {
+		if err := registerClass(name, proc); err != nil {
+			return fmt.Errorf("register class %s: %w", name, err)
+		}
+	}
+
+	// Hidden window owning all timers; never visible so the monitor ignores it
+	a.host = createWindow(classHost, "", 0, 0, 0, 0, 0, 0, 0)
+	if a.host == 0 {
+		return fmt.Errorf("failed to create host window")
+	}
+
+	splash := a.showDialog(titleSplash, 420, 240, &dialog{})
+	a.after(a.timing.Splash, func() {
+		a.closeDialog(splash)
+		a.showMainFrame()
+	})
+
+	runMessageLoop()
+	return nil
+}
+
+// after schedules fn to run on the UI thread once d has elapsed
+func (a *app) after(d time.Duration, fn func()) {
+	a.nextTimer++
+	id := a.nextTimer
+	a.timers[id] = fn
+
+	ms := uint32(d / time.Millisecond)
+	if ms == 0 {
+		ms = 1
+	}
+
+	setTimer(a.host, id, ms)
+}
+
+func (a *app) hostProc(hwnd uintptr, msg uint32, wParam, lParam uintptr) uintptr {
+	if msg == WM_TIMER {
+		killTimer(hwnd, wParam)
+
+		if fn, ok := a.timers[wParam]; ok {
+			delete(a.timers, wParam)
+			fn()
+		}
+
+		return 0
+	}
+
+	return defWindowProc(hwnd, msg, wParam, lParam)
+}
+
+// showMainFrame creates the main window with the program path in the title, like smpwin.exe
+func (a *app) showMainFrame() {
+	title := fmt.Sprintf("SIMPL Windows - [%s]", a.program)
+	a.mainFrame = createTopLevel(classMainFrame, title, WS_OVERLAPPEDWINDOW, 900, 600)
+	setMenuBar(a.mainFrame, "&Project",
+		menuItem{caption: "Convert/&Compile\tF12", id: idMenuCompile},
+		menuItem{caption: "&Recompile All\tAlt+F12", id: idMenuRecompileAll},
+	)
+
+	if a.scenario.OperationComplete {
+		a.after(a.timing.Startup, func() {
+			a.showMessageDialog(titleOperationComplete, "Operation Complete.")
+		})
+	}
+}
+
+func (a *app) mainFrameProc(hwnd uintptr, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_KEYDOWN, WM_SYSKEYDOWN:
+		if wParam == VK_F12 {
+			// Alt+F12 arrives as WM_SYSKEYDOWN (Recompile All), F12 as WM_KEYDOWN (Compile);
+			// both show the same dialog sequence
+			a.startCompile()
+			return 0
+		}
+
+	case WM_COMMAND:
+		// Project menu items, as invoked by smpc's menu fallback
+		if id := wParam & 0xFFFF; id == idMenuCompile || id == idMenuRecompileAll {
+			a.startCompile()
+			return 0
+		}
+
+	case WM_CLOSE:
+		a.confirmClose()
+		return 0
+	}
+
+	return defWindowProc(hwnd, msg, wParam, lParam)
+}
+
+func (a *app) dialogProc(hwnd uintptr, msg uint32, wParam, lParam uintptr) uintptr {
+	d := a.dialogs[hwnd]
+
+	switch msg {
+	case WM_KEYDOWN:
+		if wParam == VK_RETURN && d != nil && d.onEnter != nil {
+			d.onEnter()
+			return 0
+		}
+
+	case WM_COMMAND:
+		// smpc clicks buttons by sending WM_COMMAND with the button hwnd in lParam
+		if d != nil {
+			if action, ok := d.buttons[lParam]; ok {
+				action()
+				return 0
+			}
+		}
+
+	case WM_CLOSE:
+		if d != nil && d.onClose != nil {
+			d.onClose()
+			return 0
+		}
+
+		a.closeDialog(hwnd)
+		return 0
+	}
+
+	return defWindowProc(hwnd, msg, wParam, lParam)
+}
+
+// showDialog creates a dialog-like top-level window and registers its behavior
+func (a *app) showDialog(title string, w, h int32, d *dialog) uintptr {
+	hwnd := createTopLevel(classDialog, title, WS_POPUP|WS_CAPTION|WS_SYSMENU, w, h)
+	if hwnd != 0 {
+		if d.buttons == nil {
+			d.buttons = make(map[uintptr]func())
+		}
+
+		a.dialogs[hwnd] = d
+	}
+
+	return hwnd
+}
+
+// showMessageDialog shows a dialog containing a single Edit control with text
+func (a *app) showMessageDialog(title, text string) uintptr {
+	hwnd := a.showDialog(title, 500, 260, &dialog{})
+	if hwnd != 0 {
+		addEdit(hwnd, text)
+	}
+
+	return hwnd
+}
+
+func (a *app) closeDialog(hwnd uintptr) {
+	delete(a.dialogs, hwnd)
+	destroyWindow(hwnd)
+}
+
+// startCompile runs the dialog sequence smpwin.exe shows after F12/Alt+F12
+func (a *app) startCompile() {
+	if a.compiling {
+		return
+	}
+
+	a.compiling = true
+
+	if a.scenario.IncompleteSymbols {
+		a.after(a.timing.DialogFollowUp, func() {
+			a.showMessageDialog(titleIncompleteSymbols, strings.Join([]string{
+				"The program contains incomplete symbols and cannot be compiled.",
+				"Please complete all symbols before converting/compiling.",
+			}, "\r\n"))
+			a.compiling = false
+		})
+
+		return
+	}
+
+	if a.scenario.SavePrompt {
+		a.after(a.timing.DialogFollowUp, func() {
+			var prompt uintptr
+			prompt = a.showDialog(titleConvertCompile, 420, 180, &dialog{
+				onEnter: func() {
+					a.closeDialog(prompt)
+					a.after(a.timing.DialogFollowUp, a.showCompiling)
+				},
+			})
+		})
+
+		return
+	}
+
+	a.after(a.timing.DialogFollowUp, a.showCompiling)
+}
+
+// showCompiling shows the progress dialog, then the results dialogs
+func (a *app) showCompiling() {
+	progress := a.showMessageDialog(titleCompiling, "Compiling program, please wait...")
+
+	a.after(a.timing.Compiling, func() {
+		a.closeDialog(progress)
+		a.writeOutput()
+		a.showMessageDialog(titleCompileComplete, a.scenario.statisticsText())
+
+		if lines := a.scenario.messages(); len(lines) > 0 {
+			a.after(a.timing.DetailsDelay, func() {
+				hwnd := a.showDialog(titleProgramCompile, 600, 320, &dialog{})
+				if hwnd != 0 {
+					addListBox(hwnd, lines)
+				}
+			})
+		}
+
+		a.compiling = false
+	})
+}
+
+// writeOutput writes a placeholder compiled program next to the program when the
+// compile succeeds. Like smpwin.exe, it still reports success if the write fails.
+func (a *app) writeOutput() {
+	if len(a.scenario.Errors) > 0 {
+		return
+	}
+
+	output := strings.TrimSuffix(a.program, filepath.Ext(a.program)) + ".lpz"
+	_ = os.WriteFile(output, []byte("fakesimpl"), 0o644)
+}
+
+// confirmClose shows the "save changes?" Confirmation dialog raised when closing the main window
+func (a *app) confirmClose() {
+	for _, d := range a.dialogs {
+		if len(d.buttons) > 0 {
+			return // Confirmation already showing
+		}
+	}
+
+	quit := func() {
+		for hwnd := range a.dialogs {
+			a.closeDialog(hwnd)
+		}
+
+		destroyWindow(a.mainFrame)
+		postQuit(0)
+	}
+
+	d := &dialog{buttons: make(map[uintptr]func())}
+	hwnd := a.showDialog(titleConfirmation, 360, 200, d)
+	if hwnd == 0 {
+		quit()
+		return
+	}
+
+	d.buttons[addButton(hwnd, "&Yes", 20, idYes)] = quit
+	d.buttons[addButton(hwnd, "&No", 130, idNo)] = quit
+	d.buttons[addButton(hwnd, "Cancel", 240, idCancel)] = func() { a.closeDialog(hwnd) }
+	d.onClose = func() { a.closeDialog(hwnd) }
+}
@@ -0,0 +1,153 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scenario describes how the simulator behaves for a given program
+type scenario struct {
+	Name              string
+	SavePrompt        bool // Show "Convert/Compile" before compiling
+	OperationComplete bool // Show "Operation Complete" right after the main window appears
+	IncompleteSymbols bool // Abort with "Incomplete Symbols" instead of compiling
+	Errors            []string
+	Warnings          []string
+	Notices           []string
+	CompileTime       float64
+}
+
+// scenarios is keyed by fixture file name (without extension)
+var scenarios = map[string]scenario{
+	"simple": {
+		Name:        "simple",
+		CompileTime: 0.42,
+	},
+	"warnings": {
+		Name: "warnings",
+		Warnings: []string{
+			"WARNING    (LGCMCVT102) ** Signal foo has no driving source",
+			"WARNING    (LGCMCVT102) ** Signal bar has no driving source",
+		},
+		CompileTime: 0.51,
+	},
+	"notices": {
+		Name: "notices",
+		Notices: []string{
+			"NOTICE     (LGCMCVT103) ** Signal baz has no destination",
+		},
+		CompileTime: 0.47,
+	},
+	"warnings_and_notices": {
+		Name: "warnings_and_notices",
+		Warnings: []string{
+			"WARNING    (LGCMCVT102) ** Signal foo has no driving source",
+			"WARNING    (LGCMCVT102) ** Signal bar has no driving source",
+		},
+		Notices: []string{
+			"NOTICE     (LGCMCVT103) ** Signal baz has no destination",
+		},
+		CompileTime: 0.55,
+	},
+	"error": {
+		Name: "error",
+		Errors: []string{
+			"ERROR      (LGSPLS1700) Line 5: Undefined symbol 'foo'",
+			"ERROR      (LGCMCVT247) Line 15: Type mismatch",
+			"ERROR      (LGCMCVT101) Line 25: Missing semicolon",
+		},
+		CompileTime: 0.61,
+	},
+	"incomplete": {
+		Name:              "incomplete",
+		IncompleteSymbols: true,
+	},
+	"save_prompt": {
+		Name:        "save_prompt",
+		SavePrompt:  true,
+		CompileTime: 0.44,
+	},
+	"operation_complete": {
+		Name:              "operation_complete",
+		OperationComplete: true,
+		CompileTime:       0.40,
+	},
+}
+
+// selectScenario picks the scenario from FAKESIMPL_SCENARIO or the program file name
+func selectScenario(programPath string) scenario {
+	name := os.Getenv("FAKESIMPL_SCENARIO")
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(programPath), filepath.Ext(programPath))
+	}
+
+	if s, ok := scenarios[strings.ToLower(name)]; ok {
+		return s
+	}
+
+	return scenarios["simple"]
+}
+
+// statisticsText renders the text shown in the "Compile Complete" dialog
+func (s scenario) statisticsText() string {
+	return fmt.Sprintf(
+		"Program Errors: %d\r\nProgram Warnings: %d\r\nProgram Notices: %d\r\nCompile Time: %.2f seconds\r\n",
+		len(s.Errors), len(s.Warnings), len(s.Notices), s.CompileTime,
+	)
+}
+
+// messages returns the lines shown in the "Program Compilation" ListBox
+func (s scenario) messages() []string {
+	var lines []string
+	lines = append(lines, s.Errors...)
+	lines = append(lines, s.Warnings...)
+	lines = append(lines, s.Notices...)
+
+	return lines
+}
+
+// timing holds the delays the simulator uses between UI transitions
+type timing struct {
+	Splash         time.Duration
+	Startup        time.Duration
+	Compiling      time.Duration
+	DetailsDelay   time.Duration
+	DialogFollowUp time.Duration
+}
+
+// defaultTiming approximates the delays observed with a real smpwin.exe on a small program
+var defaultTiming = timing{
+	Splash:         2 * time.Second,
+	Startup:        1 * time.Second,
+	Compiling:      3 * time.Second,
+	DetailsDelay:   300 * time.Millisecond,
+	DialogFollowUp: 200 * time.Millisecond,
+}
+
+// scaledTiming applies the FAKESIMPL_TIME_SCALE multiplier (e.g. 0.1 for fast runs)
+func scaledTiming() timing {
+	t := defaultTiming
+
+	factor, err := strconv.ParseFloat(os.Getenv("FAKESIMPL_TIME_SCALE"), 64)
+	if err != nil || factor <= 0 {
+		return t
+	}
+
+	scale := func(d time.Duration) time.Duration {
+		return time.Duration(float64(d) * factor)
+	}
+
+	return timing{
+		Splash:         scale(t.Splash),
+		Startup:        scale(t.Startup),
+		Compiling:      scale(t.Compiling),
+		DetailsDelay:   scale(t.DetailsDelay),
+		DialogFollowUp: scale(t.DialogFollowUp),
+	}
+}
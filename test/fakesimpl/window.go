@@ -0,0 +1,256 @@
+//go:build windows
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32               = syscall.NewLazyDLL("user32.dll")
+	procRegisterClassExW = user32.NewProc("RegisterClassExW")
+	procCreateWindowExW  = user32.NewProc("CreateWindowExW")
+	procDefWindowProcW   = user32.NewProc("DefWindowProcW")
+	procDestroyWindow    = user32.NewProc("DestroyWindow")
+	procShowWindow       = user32.NewProc("ShowWindow")
+	procUpdateWindow     = user32.NewProc("UpdateWindow")
+	procGetMessageW      = user32.NewProc("GetMessageW")
+	procTranslateMessage = user32.NewProc("TranslateMessage")
+	procDispatchMessageW = user32.NewProc("DispatchMessageW")
+	procPostQuitMessage  = user32.NewProc("PostQuitMessage")
+	procSetTimer         = user32.NewProc("SetTimer")
+	procKillTimer        = user32.NewProc("KillTimer")
+	procSendMessageW     = user32.NewProc("SendMessageW")
+	procSetForeground    = user32.NewProc("SetForegroundWindow")
+	procLoadCursorW      = user32.NewProc("LoadCursorW")
+	procCreateMenu       = user32.NewProc("CreateMenu")
+	procCreatePopupMenu  = user32.NewProc("CreatePopupMenu")
+	procAppendMenuW      = user32.NewProc("AppendMenuW")
+	procSetMenu          = user32.NewProc("SetMenu")
+	kernel32             = syscall.NewLazyDLL("kernel32.dll")
+	procGetModuleHandleW = kernel32.NewProc("GetModuleHandleW")
+)
+
+const (
+	WM_DESTROY    = 0x0002
+	WM_CLOSE      = 0x0010
+	WM_KEYDOWN    = 0x0100
+	WM_SYSKEYDOWN = 0x0104
+	WM_COMMAND    = 0x0111
+	WM_TIMER      = 0x0113
+
+	WS_OVERLAPPEDWINDOW = 0x00CF0000
+	WS_POPUP            = 0x80000000
+	WS_CAPTION          = 0x00C00000
+	WS_SYSMENU          = 0x00080000
+	WS_CHILD            = 0x40000000
+	WS_VISIBLE          = 0x10000000
+	WS_VSCROLL          = 0x00200000
+	WS_BORDER           = 0x00800000
+
+	ES_MULTILINE = 0x0004
+	ES_READONLY  = 0x0800
+
+	LBS_NOINTEGRALHEIGHT = 0x0100
+	LB_ADDSTRING         = 0x0180
+
+	BS_PUSHBUTTON = 0x00000000
+
+	MF_STRING = 0x0000
+	MF_POPUP  = 0x0010
+
+	SW_SHOW      = 5
+	VK_RETURN    = 0x0D
+	VK_F12       = 0x7B
+	COLOR_WINDOW = 5
+	IDC_ARROW    = 32512
+)
+
+type wndClassEx struct {
+	CbSize        uint32
+	Style         uint32
+	LpfnWndProc   uintptr
+	CbClsExtra    int32
+	CbWndExtra    int32
+	HInstance     uintptr
+	HIcon         uintptr
+	HCursor       uintptr
+	HbrBackground uintptr
+	LpszMenuName  *uint16
+	LpszClassName *uint16
+	HIconSm       uintptr
+}
+
+type point struct {
+	X, Y int32
+}
+
+type msg struct {
+	Hwnd     uintptr
+	Message  uint32
+	WParam   uintptr
+	LParam   uintptr
+	Time     uint32
+	Pt       point
+	LPrivate uint32
+}
+
+// wndProc is the signature of a window procedure handled in Go
+type wndProc func(hwnd uintptr, msg uint32, wParam, lParam uintptr) uintptr
+
+// moduleHandle returns the instance handle of the running executable
+func moduleHandle() uintptr {
+	h, _, _ := procGetModuleHandleW.Call(0)
+	return h
+}
+
+// registerClass registers a top-level window class backed by a Go window procedure
+func registerClass(name string, proc wndProc) error {
+	className, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+
+	cursor, _, _ := procLoadCursorW.Call(0, IDC_ARROW)
+
+	wc := wndClassEx{
+		CbSize:        uint32(unsafe.Sizeof(wndClassEx{})),
+		LpfnWndProc:   syscall.NewCallback(proc),
+		HInstance:     moduleHandle(),
+		HCursor:       cursor,
+		HbrBackground: COLOR_WINDOW + 1,
+		LpszClassName: className,
+	}
+
+	ret, _, err := procRegisterClassExW.Call(uintptr(unsafe.Pointer(&wc)))
+	if ret == 0 {
+		return err
+	}
+
+	return nil
+}
+
+// createWindow creates a window of the given class and returns its handle (0 on failure)
+func createWindow(class, title string, style uintptr, x, y, w, h int32, parent, id uintptr) uintptr {
+	classPtr, _ := syscall.UTF16PtrFromString(class)
+	titlePtr, _ := syscall.UTF16PtrFromString(title)
+
+	hwnd, _, _ := procCreateWindowExW.Call(
+		0,
+		uintptr(unsafe.Pointer(classPtr)),
+		uintptr(unsafe.Pointer(titlePtr)),
+		style,
+		uintptr(x), uintptr(y), uintptr(w), uintptr(h),
+		parent,
+		id,
+		moduleHandle(),
+		0,
+	)
+
+	return hwnd
+}
+
+// createTopLevel creates and shows a top-level window, bringing it to the foreground
+func createTopLevel(class, title string, style uintptr, w, h int32) uintptr {
+	hwnd := createWindow(class, title, style|WS_VISIBLE, 200, 200, w, h, 0, 0)
+	if hwnd == 0 {
+		return 0
+	}
+
+	_, _, _ = procShowWindow.Call(hwnd, SW_SHOW)
+	_, _, _ = procUpdateWindow.Call(hwnd)
+	_, _, _ = procSetForeground.Call(hwnd)
+
+	return hwnd
+}
+
+// addEdit adds a read-only multi-line Edit control to parent
+func addEdit(parent uintptr, text string) uintptr {
+	return createWindow("Edit", text,
+		WS_CHILD|WS_VISIBLE|WS_BORDER|WS_VSCROLL|ES_MULTILINE|ES_READONLY,
+		10, 10, 460, 180, parent, 1001)
+}
+
+// addListBox adds a ListBox control populated with items to parent
+func addListBox(parent uintptr, items []string) uintptr {
+	hwnd := createWindow("ListBox", "",
+		WS_CHILD|WS_VISIBLE|WS_BORDER|WS_VSCROLL|LBS_NOINTEGRALHEIGHT,
+		10, 10, 560, 260, parent, 1002)
+
+	for _, item := range items {
+		ptr, _ := syscall.UTF16PtrFromString(item)
+		_, _, _ = procSendMessageW.Call(hwnd, LB_ADDSTRING, 0, uintptr(unsafe.Pointer(ptr)))
+	}
+
+	return hwnd
+}
+
+// addButton adds a push button with the given caption to parent
+func addButton(parent uintptr, caption string, x int32, id uintptr) uintptr {
+	return createWindow("Button", caption, WS_CHILD|WS_VISIBLE|BS_PUSHBUTTON, x, 120, 80, 26, parent, id)
+}
+
+// menuItem is a single command in a popup menu
+type menuItem struct {
+	caption string
+	id      uintptr
+}
+
+// setMenuBar attaches a menu bar with a single popup menu to hwnd
+func setMenuBar(hwnd uintptr, popupCaption string, items ...menuItem) {
+	bar, _, _ := procCreateMenu.Call()
+	popup, _, _ := procCreatePopupMenu.Call()
+
+	for _, item := range items {
+		ptr, _ := syscall.UTF16PtrFromString(item.caption)
+		_, _, _ = procAppendMenuW.Call(popup, MF_STRING, item.id, uintptr(unsafe.Pointer(ptr)))
+	}
+
+	ptr, _ := syscall.UTF16PtrFromString(popupCaption)
+	_, _, _ = procAppendMenuW.Call(bar, MF_POPUP, popup, uintptr(unsafe.Pointer(ptr)))
+	_, _, _ = procSetMenu.Call(hwnd, bar)
+}
+
+// destroyWindow destroys a window if the handle is non-zero
+func destroyWindow(hwnd uintptr) {
+	if hwnd != 0 {
+		_, _, _ = procDestroyWindow.Call(hwnd)
+	}
+}
+
+// defWindowProc forwards a message to the default window procedure
+func defWindowProc(hwnd uintptr, msg uint32, wParam, lParam uintptr) uintptr {
+	ret, _, _ := procDefWindowProcW.Call(hwnd, uintptr(msg), wParam, lParam)
+	return ret
+}
+
+// setTimer starts a one-shot-capable timer on hwnd
+func setTimer(hwnd, id uintptr, ms uint32) {
+	_, _, _ = procSetTimer.Call(hwnd, id, uintptr(ms), 0)
+}
+
+// killTimer stops a timer on hwnd
+func killTimer(hwnd, id uintptr) {
+	_, _, _ = procKillTimer.Call(hwnd, id)
+}
+
+// postQuit ends the message loop
+func postQuit(code int) {
+	_, _, _ = procPostQuitMessage.Call(uintptr(code))
+}
+
+// runMessageLoop pumps messages until WM_QUIT is received
+func runMessageLoop() {
+	var m msg
+
+	for {
+		ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+		if int32(ret) <= 0 {
+			return
+		}
+
+		_, _, _ = procTranslateMessage.Call(uintptr(unsafe.Pointer(&m)))
+		_, _, _ = procDispatchMessageW.Call(uintptr(unsafe.Pointer(&m)))
+	}
+}
@@ -4,6 +4,7 @@
 package integration
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -256,7 +257,7 @@ func compileFile(t *testing.T, filePath string, recompileAll bool) (*compiler.Co
 	t.Logf("SIMPL Windows process started with PID: %d", pid)
 
 	// Start background window monitor with the exact PID we just launched
-	stopMonitor := simplClient.StartMonitoring(pid)
+	stopMonitor := simplClient.StartMonitoring(context.Background(), pid)
 
 	// Wait for process to start
 	time.Sleep(timeouts.WindowMessageDelay)
@@ -301,6 +302,7 @@ func compileFile(t *testing.T, filePath string, recompileAll bool) (*compiler.Co
 		Hwnd:         hwnd,
 		SimplPid:     simplPid,
 		SimplPidPtr:  &simplPid,
+		Events:       simplClient.Events(),
 	})
 	// Note: We don't require NoError here because some tests expect compilation to fail
 	if err != nil {
@@ -0,0 +1,62 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Norgate-AV/smpc/internal/simpl"
+)
+
+// fakeSimplPackage is the test-only SIMPL Windows simulator
+const fakeSimplPackage = "github.com/Norgate-AV/smpc/test/fakesimpl"
+
+// TestMain builds and selects the fake SIMPL Windows simulator when requested
+// (SMPC_FAKE_SIMPL=1) or when no real installation is available, so the suite
+// can run on Windows machines without Crestron software.
+func TestMain(m *testing.M) {
+	os.Exit(runWithSimplWindows(m))
+}
+
+func runWithSimplWindows(m *testing.M) int {
+	if !useFakeSimpl() {
+		return m.Run()
+	}
+
+	dir, err := os.MkdirTemp("", "smpc-fakesimpl-*")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create temp dir for fake SIMPL Windows: %v\n", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	exe := filepath.Join(dir, "smpwin.exe")
+
+	build := exec.Command("go", "build", "-o", exe, fakeSimplPackage)
+	build.Stdout = os.Stdout
+	build.Stderr = os.Stderr
+
+	if err := build.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build fake SIMPL Windows: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Using fake SIMPL Windows simulator: %s\n", exe)
+	os.Setenv("SIMPL_WINDOWS_PATH", exe)
+
+	return m.Run()
+}
+
+// useFakeSimpl reports whether the simulator should stand in for smpwin.exe
+func useFakeSimpl() bool {
+	if os.Getenv("SMPC_FAKE_SIMPL") == "1" {
+		return true
+	}
+
+	return simpl.ValidateSimplWindowsInstallation() != nil
+}